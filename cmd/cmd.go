@@ -4,6 +4,7 @@ package cmd
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -27,13 +28,19 @@ var Version = "dev"
 // AnalyzeCmd indexes a repository into a knowledge graph.
 type AnalyzeCmd struct {
 	Path         string `arg:"" optional:"" default:"." help:"Path to repository"`
-	Full         bool   `help:"Perform full re-index"`
+	Full         bool   `help:"Re-parse every file, not only the ones changed since the last run"`
 	NoEmbeddings bool   `help:"Skip vector embedding generation"`
+	Concurrency  int    `help:"Number of files parsed in parallel (default: GOMAXPROCS)"`
+	Keep         int    `default:"2" help:"Number of previous index generations kept for rollback"`
 }
 
 // Run executes the analyze command.
 func (c *AnalyzeCmd) Run() error {
-	ctx := context.Background()
+	// Ctrl+C and SIGTERM (sent by CI on timeout) stop the pipeline; the
+	// store keeps the previous index
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	repoPath, err := filepath.Abs(c.Path)
 	if err != nil {
 		return fmt.Errorf("resolving path: %w", err)
@@ -55,41 +62,34 @@ func (c *AnalyzeCmd) Run() error {
 		return fmt.Errorf("creating .axon directory: %w", err)
 	}
 
-	// Initialize BadgerDB storage
-	dbPath := filepath.Join(axonDir, "badger")
-	store := storage.NewBadgerBackend()
-	if err := store.Initialize(dbPath, false); err != nil {
-		return fmt.Errorf("initializing storage: %w", err)
-	}
-	defer func() { _ = store.Close() }()
-
-	// Run pipeline
-	var result *ingestion.PipelineResult
-
 	progress := func(phase string, pct float64) {
 		fmt.Printf("\r\033[K%s (%.0f%%)", phase, pct*100)
 	}
 
-	_, result, err = ingestion.RunPipeline(
+	// Run pipeline into a new index generation, which replaces the current
+	// one once it is complete
+	gens := storage.NewGenerations(axonDir, c.Keep)
+	gen, result, err := ingestion.IndexGeneration(
 		ctx,
 		repoPath,
-		store,
+		gens,
 		c.Full,
 		progress,
 		!c.NoEmbeddings,
+		c.Concurrency,
 	)
+	fmt.Println() // Newline after progress
 	if err != nil {
 		return fmt.Errorf("running pipeline: %w", err)
 	}
 
-	fmt.Println() // Newline after progress
-
 	// Write meta.json
 	meta := map[string]any{
 		"version":    Version,
 		"name":       filepath.Base(repoPath),
 		"path":       repoPath,
 		"stats":      result,
+		"generation": gen.Name,
 		"indexed_at": time.Now().UTC().Format(time.RFC3339),
 	}
 
@@ -102,8 +102,10 @@ func (c *AnalyzeCmd) Run() error {
 	// Print summary
 	color.Green("\n✓ Indexing complete")
 	fmt.Printf("  Files:          %d\n", result.Files)
+	fmt.Printf("  Parsed:         %d\n", result.ParsedFiles)
 	fmt.Printf("  Symbols:        %d\n", result.Symbols)
 	fmt.Printf("  Relationships:  %d\n", result.Relationships)
+	fmt.Printf("  Generation:     %s\n", gen.Name)
 	fmt.Printf("  Duration:       %.2fs\n", result.DurationSecs)
 
 	return nil
@@ -193,6 +195,12 @@ func (c *ContextCmd) Run() error {
 	}
 	fmt.Println()
 
+	if node.Doc != "" {
+		fmt.Println("### Documentation")
+		fmt.Println(node.Doc)
+		fmt.Println()
+	}
+
 	// Get callers
 	callers, err := store.GetCallers(ctx, nodeID)
 	if err != nil {
@@ -227,6 +235,23 @@ func (c *ContextCmd) Run() error {
 		fmt.Println()
 	}
 
+	// Get the design docs that mention it
+	documents, err := store.GetDocuments(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+	if len(documents) > 0 {
+		fmt.Printf("### Design Docs (%d)\n", len(documents))
+		for _, document := range documents {
+			path, _ := document.Properties["path"].(string)
+			if path == "" {
+				path = document.Name
+			}
+			fmt.Printf("- %s in %s:%d\n", path, document.FilePath, document.StartLine)
+		}
+		fmt.Println()
+	}
+
 	fmt.Println("Next: Use `axon impact` if planning changes to this symbol.")
 
 	return nil
@@ -285,14 +310,9 @@ func (c *ImpactCmd) Run() error {
 
 	fmt.Printf("## Affected Symbols (%d)\n\n", len(affected))
 
-	// Group by depth
-	byDepth := make(map[int][]*graph.GraphNode)
-	for i, n := range affected {
-		d := (i % c.Depth) + 1
-		byDepth[d] = append(byDepth[d], n)
-	}
+	byDepth, viaDispatch := storage.GroupByTraversal(affected)
 
-	for d := 1; d <= c.Depth && d <= len(byDepth); d++ {
+	for d := 1; d <= c.Depth; d++ {
 		nodes := byDepth[d]
 		if len(nodes) == 0 {
 			continue
@@ -312,6 +332,15 @@ func (c *ImpactCmd) Run() error {
 		fmt.Println()
 	}
 
+	if len(viaDispatch) > 0 {
+		fmt.Printf("### Via Interface Dispatch - %d symbols\n", len(viaDispatch))
+		for _, n := range viaDispatch {
+			fmt.Printf("- %s (%s) in %s [depth %d, confidence %.2f]\n",
+				n.Name, n.Label, n.FilePath, storage.TraversalDepth(n), storage.TraversalConfidence(n))
+		}
+		fmt.Println()
+	}
+
 	fmt.Println("Tip: Review each affected symbol before making changes.")
 
 	return nil
@@ -424,11 +453,10 @@ func (c *WatchCmd) Run() error {
 		return fmt.Errorf("getting current directory: %w", err)
 	}
 
-	store, err := loadStorage()
+	gens, err := loadGenerations()
 	if err != nil {
 		return err
 	}
-	defer func() { _ = store.Close() }()
 
 	fmt.Println("## Watch Mode")
 	fmt.Printf("Watching %s for changes (Ctrl+C to stop)\n\n", repoPath)
@@ -443,7 +471,7 @@ func (c *WatchCmd) Run() error {
 		cancel()
 	}()
 
-	err = ingestion.WatchRepo(ctx, repoPath, store)
+	err = ingestion.WatchRepo(ctx, repoPath, gens)
 	if err != nil && err != context.Canceled {
 		return fmt.Errorf("watch error: %w", err)
 	}
@@ -736,14 +764,21 @@ type MCPCmd struct{}
 
 // Run executes the mcp command.
 func (c *MCPCmd) Run() error {
-	ctx := context.Background()
-	store, err := loadStorage()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gens, err := loadGenerations()
 	if err != nil {
 		return err
 	}
-	defer func() { _ = store.Close() }()
+	store, gen, err := gens.OpenCurrent()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
 
 	server := mcp.NewServer(store)
+	defer func() { _ = server.Close() }()
+	go followGenerations(ctx, gens, gen, server)
 
 	// Note: No output to stderr - MCP server uses stdio for JSON-RPC only
 	return server.Run(ctx, os.Stdin, os.Stdout)
@@ -756,14 +791,23 @@ type ServeCmd struct {
 
 // Run executes the serve command.
 func (c *ServeCmd) Run() error {
-	ctx := context.Background()
-	store, err := loadStorage()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	gens, err := loadGenerations()
 	if err != nil {
 		return err
 	}
-	defer func() { _ = store.Close() }()
+	store, gen, err := gens.OpenCurrent()
+	if err != nil {
+		return fmt.Errorf("initializing storage: %w", err)
+	}
 
+	// The server answers from the current generation, and switches to each
+	// one published by watch mode or by axon analyze between requests
 	server := mcp.NewServer(store)
+	defer func() { _ = server.Close() }()
+	go followGenerations(ctx, gens, gen, server)
 
 	if c.Watch {
 		fmt.Fprintln(os.Stderr, "Starting MCP server with watch mode...")
@@ -779,7 +823,7 @@ func (c *ServeCmd) Run() error {
 		defer cancel()
 
 		go func() {
-			err := ingestion.WatchRepo(watchCtx, repoPath, store)
+			err := ingestion.WatchRepo(watchCtx, repoPath, gens)
 			if err != nil && err != context.Canceled {
 				fmt.Fprintf(os.Stderr, "Watch error: %v\n", err)
 			}
@@ -883,6 +927,19 @@ func (c *StatusCmd) Run() error {
 	if indexedAt, ok := meta["indexed_at"].(string); ok {
 		fmt.Printf("  Last indexed:   %s\n", indexedAt)
 	}
+	gens := storage.NewGenerations(filepath.Join(repoPath, ".axon"), storage.DefaultKeepGenerations)
+	if current, err := gens.Current(); err == nil {
+		fmt.Printf("  Generation:     %s\n", current.Name)
+		if generations, err := gens.List(); err == nil && len(generations) > 1 {
+			var previous []string
+			for _, gen := range generations {
+				if gen.Name != current.Name {
+					previous = append(previous, gen.Name)
+				}
+			}
+			fmt.Printf("  Rollback to:    %s\n", strings.Join(previous, ", "))
+		}
+	}
 	if stats, ok := meta["stats"].(map[string]any); ok {
 		if files, ok := stats["files"].(float64); ok {
 			fmt.Printf("  Files:          %.0f\n", files)
@@ -898,6 +955,145 @@ func (c *StatusCmd) Run() error {
 	return nil
 }
 
+// RollbackCmd makes a previous index generation the current one.
+type RollbackCmd struct {
+	Generation string `arg:"" optional:"" help:"Generation to restore (default: the one before the current)"`
+}
+
+// Run executes the rollback command.
+func (c *RollbackCmd) Run() error {
+	gens, err := loadGenerations()
+	if err != nil {
+		return err
+	}
+	current, err := gens.Current()
+	if err != nil {
+		return err
+	}
+
+	name := c.Generation
+	if name == "" {
+		generations, err := gens.List()
+		if err != nil {
+			return err
+		}
+		// Names are zero-padded numbers, so they compare as strings
+		for _, gen := range generations {
+			if gen.Name < current.Name {
+				name = gen.Name
+			}
+		}
+		if name == "" {
+			return fmt.Errorf("no generation before %s to roll back to", current.Name)
+		}
+	}
+
+	gen, err := gens.Activate(name)
+	if err != nil {
+		return fmt.Errorf("rolling back: %w", err)
+	}
+
+	color.Green("Generation %s is current (was %s)", gen.Name, current.Name)
+	return nil
+}
+
+// DoctorCmd checks the current index generation for inconsistencies.
+type DoctorCmd struct {
+	Repair bool `help:"Publish a new generation without the orphaned relationships, index entries and embeddings"`
+}
+
+// Run executes the doctor command.
+func (c *DoctorCmd) Run() error {
+	ctx := context.Background()
+
+	gens, err := loadGenerations()
+	if err != nil {
+		return err
+	}
+	store, gen, err := gens.OpenCurrent()
+	if err != nil {
+		return err
+	}
+	defer func() { _ = store.Close() }()
+
+	report, err := store.Check(ctx, false)
+	if err != nil {
+		return fmt.Errorf("checking index: %w", err)
+	}
+
+	fmt.Printf("Checked generation %s: %d nodes, %d relationships, %d index entries\n",
+		gen.Name, report.Nodes, report.Relationships, report.IndexEntries)
+	if report.Problems() == 0 {
+		color.Green("No problems found")
+		return nil
+	}
+
+	printProblems("Relationships to missing nodes", report.OrphanedRelationships)
+	printProblems("Index entries to missing nodes or relationships", report.DanglingIndexEntries)
+	printProblems("Embeddings of missing nodes", report.OrphanedEmbeddings)
+
+	if !c.Repair {
+		return fmt.Errorf("found %d problem(s). Run 'axon doctor --repair' to delete them", report.Problems())
+	}
+
+	// The current generation stays untouched for the readers that have it
+	// open; the repaired copy becomes current
+	repaired, err := repairGeneration(ctx, gens, store)
+	if err != nil {
+		return fmt.Errorf("repairing index: %w", err)
+	}
+	color.Green("Repaired %d problem(s) in generation %s", report.Problems(), repaired.Name)
+	return nil
+}
+
+// repairGeneration copies the graph of store into a new generation,
+// deletes its inconsistent entries there and publishes it.
+func repairGeneration(ctx context.Context, gens *storage.Generations, store *storage.BadgerBackend) (storage.Generation, error) {
+	repaired, gen, err := gens.Begin(ctx, false)
+	if err != nil {
+		return storage.Generation{}, fmt.Errorf("creating generation: %w", err)
+	}
+
+	err = store.CopyGraph(ctx, repaired)
+	if err == nil {
+		_, err = repaired.Check(ctx, true)
+	}
+	if closeErr := repaired.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("closing generation: %w", closeErr)
+	}
+	if err != nil {
+		_ = gens.Discard(gen)
+		return storage.Generation{}, err
+	}
+
+	gen, err = gens.Publish(gen)
+	if err != nil {
+		return storage.Generation{}, fmt.Errorf("publishing generation: %w", err)
+	}
+	if err := gens.Prune(); err != nil {
+		// The repaired generation is current; old ones are removed next time
+		fmt.Fprintf(os.Stderr, "Warning: removing old generations failed: %v\n", err)
+	}
+	return gen, nil
+}
+
+// printProblems prints the first entries of a kind of problem found by
+// the doctor command.
+func printProblems(title string, entries []string) {
+	const shown = 10
+
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Printf("⚠️ %s (%d):\n", title, len(entries))
+	for _, entry := range entries[:min(len(entries), shown)] {
+		fmt.Printf("  - %s\n", entry)
+	}
+	if len(entries) > shown {
+		fmt.Printf("  ... and %d more\n", len(entries)-shown)
+	}
+}
+
 // CleanCmd deletes index for current repository.
 type CleanCmd struct {
 	Force bool `short:"f" help:"Skip confirmation"`
@@ -942,23 +1138,69 @@ func osSignalChannel() <-chan os.Signal {
 	return sigChan
 }
 
+// loadStorage opens the current index generation of the repository in the
+// working directory.
 func loadStorage() (*storage.BadgerBackend, error) {
+	gens, err := loadGenerations()
+	if err != nil {
+		return nil, err
+	}
+
+	store, _, err := gens.OpenCurrent()
+	if err != nil {
+		return nil, fmt.Errorf("initializing storage: %w", err)
+	}
+
+	return store, nil
+}
+
+// loadGenerations returns the index generations of the repository in the
+// working directory, which must have been indexed.
+func loadGenerations() (*storage.Generations, error) {
 	repoPath, err := os.Getwd()
 	if err != nil {
 		return nil, fmt.Errorf("getting working directory: %w", err)
 	}
 
-	dbPath := filepath.Join(repoPath, ".axon", "badger")
-	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
-		return nil, fmt.Errorf("no index found at %s. Run 'axon analyze' first", repoPath)
+	gens := storage.NewGenerations(filepath.Join(repoPath, ".axon"), storage.DefaultKeepGenerations)
+	if _, err := gens.Current(); err != nil {
+		if errors.Is(err, storage.ErrNoIndex) {
+			return nil, fmt.Errorf("no index found at %s. Run 'axon analyze' first", repoPath)
+		}
+		return nil, err
 	}
 
-	store := storage.NewBadgerBackend()
-	if err := store.Initialize(dbPath, true); err != nil {
-		return nil, fmt.Errorf("initializing storage: %w", err)
-	}
+	return gens, nil
+}
 
-	return store, nil
+// followGenerations switches server to every generation that becomes
+// current after gen, checking once a second until ctx is canceled.
+func followGenerations(ctx context.Context, gens *storage.Generations, gen storage.Generation, server *mcp.Server) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		current, err := gens.Current()
+		if err != nil || current.Name == gen.Name {
+			continue
+		}
+		store, opened, err := gens.OpenCurrent()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Opening index generation %s: %v\n", current.Name, err)
+			gen = current // Not retried until the next generation
+			continue
+		}
+		if err := server.SwapStorage(store); err != nil {
+			fmt.Fprintf(os.Stderr, "Closing index generation %s: %v\n", gen.Name, err)
+		}
+		gen = opened
+	}
 }
 
 // findSymbolByName searches for a symbol by name across all node types.
@@ -971,9 +1213,9 @@ func findSymbolByName(store *storage.BadgerBackend, name string) (string, error)
 		return "", err
 	}
 
-	// Look for exact match
+	// Look for exact match, skipping document sections titled after it
 	for _, result := range results {
-		if result.NodeName == name {
+		if result.NodeName == name && result.Label != string(graph.NodeDocument) {
 			return result.NodeID, nil
 		}
 	}
@@ -1018,6 +1260,8 @@ type CLI struct {
 	Serve    ServeCmd    `cmd:"" help:"Start MCP server with optional watch mode"`
 	List     ListCmd     `cmd:"" help:"List all indexed repositories"`
 	Status   StatusCmd   `cmd:"" help:"Show index status for current repo"`
+	Rollback RollbackCmd `cmd:"" help:"Make a previous index generation current"`
+	Doctor   DoctorCmd   `cmd:"" help:"Check the index for inconsistencies and repair them"`
 	Clean    CleanCmd    `cmd:"" help:"Delete index for current repository"`
 }
 
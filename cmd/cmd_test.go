@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -8,6 +9,7 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/Benny93/axon-go/internal/graph"
 	"github.com/Benny93/axon-go/internal/storage"
 )
 
@@ -212,3 +214,77 @@ func TestStorageHelpers(t *testing.T) {
 		}
 	})
 }
+
+func TestRollbackCmd_Run(t *testing.T) {
+	// Note: Not using t.Parallel() because the test changes directories
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n\nfunc main() {}\n"), 0o644))
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	require.NoError(t, os.Chdir(tmpDir))
+
+	analyze := &AnalyzeCmd{Path: tmpDir, NoEmbeddings: true, Keep: 2}
+	require.NoError(t, analyze.Run())
+	require.NoError(t, analyze.Run())
+
+	gens := storage.NewGenerations(filepath.Join(tmpDir, ".axon"), storage.DefaultKeepGenerations)
+	current, err := gens.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "000002", current.Name)
+
+	require.NoError(t, (&RollbackCmd{}).Run())
+	current, err = gens.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "000001", current.Name)
+
+	assert.Error(t, (&RollbackCmd{}).Run(), "there is no generation before the first")
+
+	require.NoError(t, (&RollbackCmd{Generation: "000002"}).Run())
+	current, err = gens.Current()
+	require.NoError(t, err)
+	assert.Equal(t, "000002", current.Name)
+}
+
+func TestDoctorCmd_Run(t *testing.T) {
+	// Note: Not using t.Parallel() because the test changes directories
+
+	tmpDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(tmpDir, "main.go"), []byte("package main\n\nfunc main() { helper() }\n\nfunc helper() {}\n"), 0o644))
+	origDir, _ := os.Getwd()
+	defer os.Chdir(origDir)
+	require.NoError(t, os.Chdir(tmpDir))
+
+	require.NoError(t, (&AnalyzeCmd{Path: tmpDir, NoEmbeddings: true, Keep: 2}).Run())
+	require.NoError(t, (&DoctorCmd{}).Run(), "a fresh index is consistent")
+
+	// A relationship to a node that is not stored
+	gens := storage.NewGenerations(filepath.Join(tmpDir, ".axon"), storage.DefaultKeepGenerations)
+	current, err := gens.Current()
+	require.NoError(t, err)
+	store := storage.NewBadgerBackend()
+	require.NoError(t, store.Initialize(current.Path, false))
+	require.NoError(t, store.AddRelationships(context.Background(), []*graph.GraphRelationship{{
+		ID:     "calls:main:gone",
+		Type:   graph.RelCalls,
+		Source: "function:main.go:main",
+		Target: "function:gone.go:gone",
+	}}))
+	require.NoError(t, store.Close())
+
+	assert.Error(t, (&DoctorCmd{}).Run())
+
+	// The repair is published as a new generation, the damaged one stays
+	readers, _, err := gens.OpenCurrent()
+	require.NoError(t, err)
+	defer readers.Close()
+	require.NoError(t, (&DoctorCmd{Repair: true}).Run(), "repairing does not need the current generation to be closed")
+	assert.NoError(t, (&DoctorCmd{}).Run())
+
+	repaired, err := gens.Current()
+	require.NoError(t, err)
+	assert.NotEqual(t, current.Name, repaired.Name)
+	report, err := readers.Check(context.Background(), false)
+	require.NoError(t, err)
+	assert.Len(t, report.OrphanedRelationships, 1, "the damaged generation is not rewritten")
+}
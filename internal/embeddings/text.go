@@ -29,6 +29,15 @@ func GenerateEmbeddingText(node *graph.GraphNode) string {
 		parts = append(parts, fmt.Sprintf("Signature: %s", node.Signature))
 	}
 
+	// Add documentation (first 300 chars)
+	if node.Doc != "" {
+		doc := node.Doc
+		if len(doc) > 300 {
+			doc = doc[:300]
+		}
+		parts = append(parts, fmt.Sprintf("Documentation: %s", doc))
+	}
+
 	// Add content (first 500 chars)
 	if node.Content != "" {
 		content := node.Content
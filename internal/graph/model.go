@@ -11,14 +11,24 @@ type NodeLabel string
 const (
 	NodeFile      NodeLabel = "file"
 	NodeFolder    NodeLabel = "folder"
+	NodePackage   NodeLabel = "package"
+	NodeModule    NodeLabel = "module"
 	NodeFunction  NodeLabel = "function"
 	NodeClass     NodeLabel = "class"
 	NodeMethod    NodeLabel = "method"
 	NodeInterface NodeLabel = "interface"
 	NodeTypeAlias NodeLabel = "type_alias"
 	NodeEnum      NodeLabel = "enum"
+	NodeVariable  NodeLabel = "variable"
+	NodeConstant  NodeLabel = "constant"
+	NodeField     NodeLabel = "field"
 	NodeCommunity NodeLabel = "community"
 	NodeProcess   NodeLabel = "process"
+	NodeEndpoint  NodeLabel = "endpoint"
+	NodeTable     NodeLabel = "table"
+	NodeColumn    NodeLabel = "column"
+	NodeResource  NodeLabel = "resource"
+	NodeDocument  NodeLabel = "document"
 )
 
 // RelType represents the type of relationship between graph nodes.
@@ -36,6 +46,14 @@ const (
 	RelUsesType      RelType = "uses_type"
 	RelExports       RelType = "exports"
 	RelCoupledWith   RelType = "coupled_with"
+	RelDeclares      RelType = "declares"
+	RelHandles       RelType = "handles"
+	RelReadsTable    RelType = "reads_table"
+	RelWritesTable   RelType = "writes_table"
+	RelReferences    RelType = "references"
+	RelDocuments     RelType = "documents"
+	RelReads         RelType = "reads"
+	RelWrites        RelType = "writes"
 )
 
 // GraphNode represents a node in the knowledge graph.
@@ -65,10 +83,13 @@ type GraphNode struct {
 	// Signature is the function/method signature.
 	Signature string
 
+	// Doc is the documentation of the symbol (doc comment or docstring).
+	Doc string
+
 	// Language is the programming language (e.g., "python", "typescript").
 	Language string
 
-	// ClassName is the parent class name (for methods).
+	// ClassName is the parent class name (for methods and fields).
 	ClassName string
 
 	// IsDead indicates if the symbol is unreachable/dead code.
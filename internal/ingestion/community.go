@@ -1,6 +1,7 @@
 package ingestion
 
 import (
+	"context"
 	"fmt"
 	"math/rand"
 	"sort"
@@ -10,7 +11,7 @@ import (
 
 // DetectCommunities detects communities in the code graph using a Louvain-style algorithm.
 // Returns the number of communities detected.
-func DetectCommunities(g *graph.KnowledgeGraph) int {
+func DetectCommunities(ctx context.Context, g *graph.KnowledgeGraph) int {
 	// Get only symbol nodes (not files/folders)
 	symbolNodes := getSymbolNodes(g)
 	if len(symbolNodes) == 0 {
@@ -24,7 +25,10 @@ func DetectCommunities(g *graph.KnowledgeGraph) int {
 	}
 
 	// Detect communities using Louvain-style algorithm
-	communities := assignCommunities(matrix)
+	communities := assignCommunities(ctx, matrix)
+	if ctx.Err() != nil {
+		return 0
+	}
 
 	// Create COMMUNITY nodes and MEMBER_OF edges
 	communityMap := make(map[int][]string)
@@ -132,8 +136,9 @@ func buildAdjacencyMatrix(g *graph.KnowledgeGraph) ([][]float64, map[string]int,
 }
 
 // assignCommunities assigns communities to nodes using a simplified Louvain algorithm.
-// Returns a slice where index i contains the community ID for node i.
-func assignCommunities(adjMatrix [][]float64) []int {
+// Returns a slice where index i contains the community ID for node i, or nil
+// if ctx is canceled before the optimization converges.
+func assignCommunities(ctx context.Context, adjMatrix [][]float64) []int {
 	n := len(adjMatrix)
 	if n == 0 {
 		return []int{}
@@ -181,6 +186,10 @@ func assignCommunities(adjMatrix [][]float64) []int {
 		nodeOrder := rand.Perm(n)
 
 		for _, node := range nodeOrder {
+			if ctx.Err() != nil {
+				return nil
+			}
+
 			bestComm := communities[node]
 			bestGain := 0.0
 
@@ -1,6 +1,7 @@
 package ingestion
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -31,7 +32,7 @@ func TestDetectCommunities(t *testing.T) {
 		g.AddRelationship(&graph.GraphRelationship{ID: "calls:3", Type: graph.RelCalls, Source: "function:d.go:D", Target: "function:e.go:E"})
 		g.AddRelationship(&graph.GraphRelationship{ID: "calls:4", Type: graph.RelCalls, Source: "function:e.go:E", Target: "function:f.go:F"})
 
-		count := DetectCommunities(g)
+		count := DetectCommunities(context.Background(), g)
 
 		assert.Greater(t, count, 0)
 
@@ -51,7 +52,7 @@ func TestDetectCommunities(t *testing.T) {
 		g.AddNode(&graph.GraphNode{ID: "function:a.go:A", Label: graph.NodeFunction, Name: "A", FilePath: "a.go"})
 		g.AddNode(&graph.GraphNode{ID: "function:b.go:B", Label: graph.NodeFunction, Name: "B", FilePath: "b.go"})
 
-		count := DetectCommunities(g)
+		count := DetectCommunities(context.Background(), g)
 
 		// Each isolated node should be its own community
 		assert.GreaterOrEqual(t, count, 1)
@@ -60,7 +61,7 @@ func TestDetectCommunities(t *testing.T) {
 	t.Run("HandlesEmptyGraph", func(t *testing.T) {
 		g := graph.NewKnowledgeGraph()
 
-		count := DetectCommunities(g)
+		count := DetectCommunities(context.Background(), g)
 
 		assert.Equal(t, 0, count)
 	})
@@ -88,7 +89,7 @@ func TestDetectCommunities(t *testing.T) {
 			}
 		}
 
-		count := DetectCommunities(g)
+		count := DetectCommunities(context.Background(), g)
 
 		// Should detect at least 1 community
 		assert.GreaterOrEqual(t, count, 1)
@@ -147,7 +148,7 @@ func TestAssignCommunities(t *testing.T) {
 			{0, 0, 1, 0},
 		}
 
-		communities := assignCommunities(matrix)
+		communities := assignCommunities(context.Background(), matrix)
 
 		assert.Len(t, communities, 4)
 
@@ -161,7 +162,7 @@ func TestAssignCommunities(t *testing.T) {
 	t.Run("HandlesSingleNode", func(t *testing.T) {
 		matrix := [][]float64{{0}}
 
-		communities := assignCommunities(matrix)
+		communities := assignCommunities(context.Background(), matrix)
 
 		assert.Len(t, communities, 1)
 		assert.Equal(t, 0, communities[0])
@@ -2,6 +2,7 @@ package ingestion
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"os/exec"
 	"strings"
@@ -11,9 +12,9 @@ import (
 
 // ProcessCoupling analyzes git history to find files that change together.
 // Returns the number of COUPLED_WITH edges created.
-func ProcessCoupling(g *graph.KnowledgeGraph, repoPath string) int {
+func ProcessCoupling(ctx context.Context, g *graph.KnowledgeGraph, repoPath string) int {
 	// Parse git log for last 6 months
-	changes, err := parseGitLog(repoPath, 6)
+	changes, err := parseGitLog(ctx, repoPath, 6)
 	if err != nil {
 		return 0
 	}
@@ -36,6 +37,9 @@ func ProcessCoupling(g *graph.KnowledgeGraph, repoPath string) int {
 	// Create COUPLED_WITH edges for strong couplings
 	edgeCount := 0
 	for fileA, coChanges := range matrix {
+		if ctx.Err() != nil {
+			return edgeCount
+		}
 		for fileB, count := range coChanges {
 			if fileA >= fileB {
 				continue // Avoid duplicates
@@ -75,11 +79,12 @@ func ProcessCoupling(g *graph.KnowledgeGraph, repoPath string) int {
 	return edgeCount
 }
 
-// parseGitLog parses git log for the last N months.
+// parseGitLog parses git log for the last N months. git is killed if ctx is
+// canceled.
 // Returns a list of commits, where each commit is a list of changed files.
-func parseGitLog(repoPath string, months int) ([][]string, error) {
+func parseGitLog(ctx context.Context, repoPath string, months int) ([][]string, error) {
 	// Run git log
-	cmd := exec.Command("git", "log",
+	cmd := exec.CommandContext(ctx, "git", "log",
 		fmt.Sprintf("--since=%d months ago", months),
 		"--name-only",
 		"--pretty=format:COMMIT:%H")
@@ -1,6 +1,7 @@
 package ingestion
 
 import (
+	"context"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -26,7 +27,7 @@ func TestParseGitLog(t *testing.T) {
 		createCommit(t, tmpDir, "file1.go", "package main\n\nfunc main() {}")
 
 		// Parse git log
-		changes, err := parseGitLog(tmpDir, 6)
+		changes, err := parseGitLog(context.Background(), tmpDir, 6)
 		require.NoError(t, err)
 		assert.NotEmpty(t, changes)
 	})
@@ -34,7 +35,7 @@ func TestParseGitLog(t *testing.T) {
 	t.Run("HandlesNoGitRepo", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
-		changes, err := parseGitLog(tmpDir, 6)
+		changes, err := parseGitLog(context.Background(), tmpDir, 6)
 		assert.Error(t, err)
 		assert.Empty(t, changes)
 	})
@@ -48,10 +49,23 @@ func TestParseGitLog(t *testing.T) {
 			createCommit(t, tmpDir, "file.go", "package main")
 		}
 
-		changes, err := parseGitLog(tmpDir, 6)
+		changes, err := parseGitLog(context.Background(), tmpDir, 6)
 		require.NoError(t, err)
 		assert.NotEmpty(t, changes)
 	})
+
+	t.Run("StopsWhenCanceled", func(t *testing.T) {
+		tmpDir := t.TempDir()
+		initGitRepo(t, tmpDir)
+		createCommit(t, tmpDir, "file.go", "package main")
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		changes, err := parseGitLog(ctx, tmpDir, 6)
+		assert.Error(t, err)
+		assert.Empty(t, changes)
+	})
 }
 
 func TestBuildCoChangeMatrix(t *testing.T) {
@@ -147,7 +161,7 @@ func TestProcessCoupling(t *testing.T) {
 			FilePath: "file2.go",
 		})
 
-		count := ProcessCoupling(g, tmpDir)
+		count := ProcessCoupling(context.Background(), g, tmpDir)
 
 		assert.GreaterOrEqual(t, count, 0) // May be 0 if coupling < threshold
 	})
@@ -156,7 +170,7 @@ func TestProcessCoupling(t *testing.T) {
 		tmpDir := t.TempDir()
 
 		g := graph.NewKnowledgeGraph()
-		count := ProcessCoupling(g, tmpDir)
+		count := ProcessCoupling(context.Background(), g, tmpDir)
 
 		assert.Equal(t, 0, count)
 	})
@@ -180,7 +194,7 @@ func TestProcessCoupling(t *testing.T) {
 			})
 		}
 
-		count := ProcessCoupling(g, tmpDir)
+		count := ProcessCoupling(context.Background(), g, tmpDir)
 
 		// Should filter out weak couplings (< 0.3 strength or < 3 co-changes)
 		assert.GreaterOrEqual(t, count, 0)
@@ -1,6 +1,8 @@
 package ingestion
 
 import (
+	"context"
+	"path/filepath"
 	"strings"
 
 	"github.com/Benny93/axon-go/internal/graph"
@@ -15,9 +17,9 @@ import (
 // 3. Intra-class call tracking - un-flag methods called within same class
 // 4. Exemptions - un-flag entry points, exports, test code, dunder methods
 // 5. Allowlist exemptions - un-flag framework handlers, CLI commands
-// 6. Override pass - un-flag methods overriding non-dead base class methods
+// 6. Override pass - un-flag overrides and implementations of non-dead methods
 // 7. Confidence scoring - assign confidence levels to remaining dead code
-func ProcessDeadCode(g *graph.KnowledgeGraph) int {
+func ProcessDeadCode(ctx context.Context, g *graph.KnowledgeGraph) int {
 	// Phase 1: Detect call patterns (dynamic dispatch, framework patterns)
 	detectCallPatterns(g)
 
@@ -50,16 +52,33 @@ func ProcessDeadCode(g *graph.KnowledgeGraph) int {
 	return count
 }
 
-// flagUnreachable marks all symbols with no incoming CALLS as potentially dead.
+// flagUnreachable marks all symbols with no incoming CALLS, and variables
+// and constants that nothing reads or writes, as potentially dead.
 func flagUnreachable(g *graph.KnowledgeGraph) {
 	for node := range g.IterNodes() {
 		// Skip non-symbol nodes
-		if node.Label == graph.NodeFile || node.Label == graph.NodeFolder {
+		if node.Label == graph.NodeFile || node.Label == graph.NodeFolder ||
+			node.Label == graph.NodePackage || node.Label == graph.NodeModule {
 			continue
 		}
 
-		// Skip structural nodes (communities, processes) - they're not callable
-		if node.Label == graph.NodeCommunity || node.Label == graph.NodeProcess {
+		// Skip structural nodes (communities, processes, endpoints, tables,
+		// infrastructure resources, documents) - they're not callable
+		if node.Label == graph.NodeCommunity || node.Label == graph.NodeProcess ||
+			node.Label == graph.NodeEndpoint || node.Label == graph.NodeTable ||
+			node.Label == graph.NodeColumn || node.Label == graph.NodeResource ||
+			node.Label == graph.NodeDocument {
+			continue
+		}
+
+		// Variables and constants are read and written rather than called;
+		// struct fields are also set by encoders and reflection, so they
+		// are never flagged
+		if node.Label == graph.NodeField {
+			continue
+		}
+		if node.Label == graph.NodeVariable || node.Label == graph.NodeConstant {
+			node.IsDead = !g.HasIncoming(node.ID, graph.RelReads) && !g.HasIncoming(node.ID, graph.RelWrites)
 			continue
 		}
 
@@ -112,8 +131,23 @@ func isDeadCodeExempt(node *graph.GraphNode) bool {
 		return true
 	}
 
-	// Structural nodes (communities, processes) are never dead
-	if node.Label == graph.NodeCommunity || node.Label == graph.NodeProcess {
+	// Properties (C#) are read and assigned rather than called
+	if property, _ := node.Properties["property"].(bool); property {
+		return true
+	}
+
+	// Declarations (C/C++) are linked to their definitions rather than
+	// called
+	if isDeclaration(node) {
+		return true
+	}
+
+	// Structural nodes (communities, processes, endpoints, tables,
+	// infrastructure resources, documents) are never dead
+	if node.Label == graph.NodeCommunity || node.Label == graph.NodeProcess ||
+		node.Label == graph.NodeEndpoint || node.Label == graph.NodeTable ||
+		node.Label == graph.NodeColumn || node.Label == graph.NodeResource ||
+		node.Label == graph.NodeDocument {
 		return true
 	}
 
@@ -128,6 +162,64 @@ func isTestFunction(node *graph.GraphNode) bool {
 		return true
 	}
 
+	// JavaScript test files (foo.test.js, foo.spec.jsx, __tests__/foo.js)
+	if node.Language == "javascript" {
+		base := filepath.Base(node.FilePath)
+		if strings.Contains(base, ".test.") || strings.Contains(base, ".spec.") ||
+			strings.Contains(filepath.ToSlash(node.FilePath), "__tests__/") {
+			return true
+		}
+	}
+
+	// Java and Kotlin test sources (src/test/, FooTest.java, FooIT.kt)
+	if node.Language == "java" || node.Language == "kotlin" {
+		path := filepath.ToSlash(node.FilePath)
+		class := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if strings.Contains("/"+path, "/src/test/") ||
+			strings.HasSuffix(class, "Test") || strings.HasSuffix(class, "Tests") || strings.HasSuffix(class, "IT") {
+			return true
+		}
+	}
+
+	// C# test projects and classes (Shop.Tests/, OrderServiceTests.cs)
+	if node.Language == "csharp" {
+		path := filepath.ToSlash(node.FilePath)
+		class := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if strings.Contains(path, ".Tests/") ||
+			strings.HasSuffix(class, "Test") || strings.HasSuffix(class, "Tests") {
+			return true
+		}
+	}
+
+	// Ruby specs and tests (spec/, test/, user_spec.rb, user_test.rb)
+	if node.Language == "ruby" {
+		path := filepath.ToSlash(node.FilePath)
+		stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if strings.Contains("/"+path, "/spec/") || strings.Contains("/"+path, "/test/") ||
+			strings.HasSuffix(stem, "_spec") || strings.HasSuffix(stem, "_test") {
+			return true
+		}
+	}
+
+	// PHPUnit tests (tests/, UserTest.php)
+	if node.Language == "php" {
+		path := filepath.ToSlash(node.FilePath)
+		class := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if strings.Contains("/"+path, "/tests/") || strings.HasSuffix(class, "Test") {
+			return true
+		}
+	}
+
+	// C and C++ tests (test/, tests/, repo_test.cc, repo_unittest.cpp)
+	if node.Language == "c" || node.Language == "cpp" {
+		path := filepath.ToSlash(node.FilePath)
+		stem := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+		if strings.Contains("/"+path, "/test/") || strings.Contains("/"+path, "/tests/") ||
+			strings.HasSuffix(stem, "_test") || strings.HasSuffix(stem, "_unittest") {
+			return true
+		}
+	}
+
 	// Check if function name starts with Test (Go) or test_ (Python)
 	if node.Label == graph.NodeFunction {
 		if strings.HasPrefix(node.Name, "Test") ||
@@ -180,11 +272,32 @@ func isConstructor(node *graph.GraphNode) bool {
 		return true
 	}
 
+	// JavaScript and Kotlin constructors
+	if node.Name == "constructor" {
+		return true
+	}
+
+	// Java and C# constructors
+	if (node.Language == "java" || node.Language == "csharp") && node.Name == node.ClassName {
+		return true
+	}
+
+	// C++ constructors and destructors
+	if (node.Language == "cpp" || node.Language == "c") && strings.TrimPrefix(node.Name, "~") == node.ClassName {
+		return true
+	}
+
+	// Ruby and PHP constructors, and PHP destructors
+	if node.Language == "ruby" && node.Name == "initialize" ||
+		node.Language == "php" && (node.Name == "__construct" || node.Name == "__destruct") {
+		return true
+	}
+
 	return false
 }
 
 // applyOverridePass removes dead code flags from methods that override
-// non-dead base class methods.
+// non-dead base class methods or implement non-dead interface methods.
 func applyOverridePass(g *graph.KnowledgeGraph) {
 	for node := range g.IterNodes() {
 		if !node.IsDead {
@@ -196,12 +309,24 @@ func applyOverridePass(g *graph.KnowledgeGraph) {
 		}
 
 		// Check if this method overrides a base class method
-		if isOverrideOfNonDeadMethod(g, node) {
+		if isOverrideOfNonDeadMethod(g, node) || implementsNonDeadMethod(g, node) {
 			node.IsDead = false
 		}
 	}
 }
 
+// implementsNonDeadMethod checks if a method implements a non-dead interface
+// method, through which it can be called by dynamic dispatch.
+func implementsNonDeadMethod(g *graph.KnowledgeGraph, method *graph.GraphNode) bool {
+	for _, rel := range g.GetOutgoing(method.ID, graph.RelImplements) {
+		ifaceMethod := g.GetNode(rel.Target)
+		if ifaceMethod != nil && ifaceMethod.Label == graph.NodeMethod && !ifaceMethod.IsDead {
+			return true
+		}
+	}
+	return false
+}
+
 // isOverrideOfNonDeadMethod checks if a method overrides a non-dead base class method.
 func isOverrideOfNonDeadMethod(g *graph.KnowledgeGraph, method *graph.GraphNode) bool {
 	if method.ClassName == "" {
@@ -452,6 +577,16 @@ func isAllowlistExempt(node *graph.GraphNode) bool {
 		return true
 	}
 
+	// React class component lifecycle methods, called by React
+	if node.Language == "javascript" && node.Label == graph.NodeMethod && reactLifecycleMethods[node.Name] {
+		return true
+	}
+
+	// Java, Kotlin and C# methods and classes a framework calls or instantiates
+	if hasAnnotation(node, entryPointAnnotations) || hasAnnotation(node, frameworkAnnotations) {
+		return true
+	}
+
 	// Methods with dynamic dispatch pattern
 	if val, ok := node.Properties["call_pattern"]; ok {
 		if pattern, ok := val.(string); ok {
@@ -465,6 +600,64 @@ func isAllowlistExempt(node *graph.GraphNode) bool {
 	return false
 }
 
+// reactLifecycleMethods are the methods React calls on class components.
+var reactLifecycleMethods = map[string]bool{
+	"render":                   true,
+	"componentDidMount":        true,
+	"componentDidUpdate":       true,
+	"componentWillUnmount":     true,
+	"shouldComponentUpdate":    true,
+	"getSnapshotBeforeUpdate":  true,
+	"componentDidCatch":        true,
+	"getDerivedStateFromProps": true,
+	"getDerivedStateFromError": true,
+}
+
+// frameworkAnnotations are the Java and Kotlin annotations, and C#
+// attributes, of symbols that are used without calls in the repository:
+// overrides of library types, injected beans and components, and test
+// lifecycle methods. The Kotlin, C# and C++ parsers record the override
+// modifier as an annotation.
+var frameworkAnnotations = map[string]bool{
+	"Override": true,
+	"override": true,
+	// Dependency injection
+	"Autowired":            true,
+	"Inject":               true,
+	"Bean":                 true,
+	"Component":            true,
+	"Service":              true,
+	"Repository":           true,
+	"Controller":           true,
+	"RestController":       true,
+	"Configuration":        true,
+	"ControllerAdvice":     true,
+	"RestControllerAdvice": true,
+	"PostConstruct":        true,
+	"PreDestroy":           true,
+	// Test lifecycle
+	"BeforeEach":  true,
+	"AfterEach":   true,
+	"BeforeAll":   true,
+	"AfterAll":    true,
+	"Before":      true,
+	"After":       true,
+	"BeforeClass": true,
+	"AfterClass":  true,
+	// ASP.NET, NUnit and MSTest
+	"ApiController":   true,
+	"SetUp":           true,
+	"TearDown":        true,
+	"OneTimeSetUp":    true,
+	"OneTimeTearDown": true,
+	"TestInitialize":  true,
+	"TestCleanup":     true,
+	"ClassInitialize": true,
+	"ClassCleanup":    true,
+	"TestFixture":     true,
+	"TestClass":       true,
+}
+
 // assignConfidenceScores assigns confidence levels to dead code flags.
 func assignConfidenceScores(g *graph.KnowledgeGraph) {
 	for node := range g.IterNodes() {
@@ -1,6 +1,7 @@
 package ingestion
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -83,7 +84,7 @@ func TestDeadCodeWithIntraClassCalls(t *testing.T) {
 		})
 
 		// Run dead code detection
-		_ = ProcessDeadCode(g)
+		_ = ProcessDeadCode(context.Background(), g)
 
 		// Verify no methods are marked as dead
 		for node := range g.IterNodes() {
@@ -151,7 +152,7 @@ func TestDeadCodeWithIntraClassCalls(t *testing.T) {
 			Target: "function:backend.go:GetNode",
 		})
 
-		_ = ProcessDeadCode(g)
+		_ = ProcessDeadCode(context.Background(), g)
 
 		// GetNode and getNode should NOT be dead
 		for node := range g.IterNodes() {
@@ -184,7 +185,7 @@ func TestDeadCodeWithConfidenceScores(t *testing.T) {
 			FilePath: "test.go",
 		})
 
-		_ = ProcessDeadCode(g)
+		_ = ProcessDeadCode(context.Background(), g)
 
 		// Check confidence scores
 		for node := range g.IterNodes() {
@@ -216,7 +217,7 @@ func TestDeadCodeWithConfidenceScores(t *testing.T) {
 			},
 		})
 
-		_ = ProcessDeadCode(g)
+		_ = ProcessDeadCode(context.Background(), g)
 
 		// Should not be marked as dead, or marked with low confidence
 		for node := range g.IterNodes() {
@@ -320,6 +321,61 @@ func TestDeadCodeWithAllowlist(t *testing.T) {
 				},
 				expected: true,
 			},
+			{
+				name: "JavaOverride",
+				node: &graph.GraphNode{
+					Name:       "compareTo",
+					FilePath:   "src/main/java/com/example/User.java",
+					Language:   "java",
+					Decorators: []string{"Override"},
+					Properties: map[string]any{},
+				},
+				expected: true,
+			},
+			{
+				name: "KotlinOverrideModifier",
+				node: &graph.GraphNode{
+					Name:       "toString",
+					FilePath:   "src/main/kotlin/com/example/User.kt",
+					Language:   "kotlin",
+					Decorators: []string{"override"},
+					Properties: map[string]any{},
+				},
+				expected: true,
+			},
+			{
+				name: "SpringEndpoint",
+				node: &graph.GraphNode{
+					Name:       "createUser",
+					FilePath:   "src/main/java/com/example/UserController.java",
+					Language:   "java",
+					Decorators: []string{"PostMapping"},
+					Properties: map[string]any{},
+				},
+				expected: true,
+			},
+			{
+				name: "XunitFact",
+				node: &graph.GraphNode{
+					Name:       "PlacesOrder",
+					FilePath:   "tests/Shop.Tests/OrderServiceTests.cs",
+					Language:   "csharp",
+					Decorators: []string{"Fact"},
+					Properties: map[string]any{},
+				},
+				expected: true,
+			},
+			{
+				name: "UnannotatedJavaMethod",
+				node: &graph.GraphNode{
+					Name:       "format",
+					FilePath:   "src/main/java/com/example/User.java",
+					Language:   "java",
+					Decorators: []string{"Deprecated"},
+					Properties: map[string]any{},
+				},
+				expected: false,
+			},
 			{
 				name: "RegularFunction",
 				node: &graph.GraphNode{
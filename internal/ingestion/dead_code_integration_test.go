@@ -1,6 +1,8 @@
 package ingestion
 
 import (
+	"context"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -49,7 +51,7 @@ func TestProcessCalls_CrossPackage(t *testing.T) {
 		})
 
 		// Process calls
-		ProcessCalls(parseData, g)
+		ProcessCalls(context.Background(), parseData, g)
 
 		// Verify CALLS relationship was created
 		rels := g.GetRelationshipsByType(graph.RelCalls)
@@ -109,7 +111,7 @@ func TestDeadCodeIntegration(t *testing.T) {
 		})
 
 		// Run dead code detection
-		count := ProcessDeadCode(g)
+		count := ProcessDeadCode(context.Background(), g)
 
 		assert.Equal(t, 1, count)
 
@@ -213,7 +215,7 @@ func TestDeadCodeExemptions(t *testing.T) {
 			FilePath: "main_test.go",
 		})
 
-		count := ProcessDeadCode(g)
+		count := ProcessDeadCode(context.Background(), g)
 		assert.Equal(t, 0, count)
 
 		node := g.GetNode("function:main_test.go:TestFoo")
@@ -232,7 +234,7 @@ func TestDeadCodeExemptions(t *testing.T) {
 			IsExported: true,
 		})
 
-		count := ProcessDeadCode(g)
+		count := ProcessDeadCode(context.Background(), g)
 		assert.Equal(t, 0, count)
 
 		node := g.GetNode("function:api.go:PublicAPI")
@@ -259,7 +261,98 @@ func TestDeadCodeExemptions(t *testing.T) {
 			FilePath:  "class.go",
 		})
 
-		count := ProcessDeadCode(g)
+		count := ProcessDeadCode(context.Background(), g)
 		assert.Equal(t, 0, count)
 	})
 }
+
+func TestProcessDeadCode_Frameworks(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"app/controllers/users_controller.rb": `require_relative "../models/user"
+
+class UsersController < ApplicationController
+  include Auditing
+
+  def index
+    load_users
+  end
+
+  def export; end
+
+  private
+
+  def load_users
+    User.active
+  end
+
+  def unused_helper; end
+end
+`,
+		"app/models/user.rb": `module Auditing; end
+
+class User < ApplicationRecord
+  def self.active; end
+end
+`,
+		"composer.json": `{"autoload": {"psr-4": {"App\\": "app/"}}}`,
+		"app/Http/Controllers/PostController.php": `<?php
+namespace App\Http\Controllers;
+
+use App\Models\Post;
+
+class PostController extends Controller
+{
+    public function index() { return $this->published(); }
+    public function show(Post $post) { return $post; }
+    private function published() { return Post::query(); }
+    private function unusedHelper() {}
+}
+`,
+		"app/Models/Post.php": `<?php
+namespace App\Models;
+
+class Post extends Model {}
+`,
+	})
+
+	g, parseData := runTestPipeline(t, dir)
+	ProcessCalls(context.Background(), parseData, g)
+	ProcessHeritage(context.Background(), parseData, g)
+	ProcessDeadCode(context.Background(), g)
+
+	method := func(file, class, name string) *graph.GraphNode {
+		t.Helper()
+		node := g.GetNode(graph.GenerateID(graph.NodeMethod, filepath.FromSlash(file), class+"."+name))
+		require.NotNil(t, node, name)
+		return node
+	}
+
+	rubyController := "app/controllers/users_controller.rb"
+	assert.True(t, method(rubyController, "UsersController", "index").IsEntryPoint)
+	assert.False(t, method(rubyController, "UsersController", "export").IsDead, "Rails actions are entry points")
+	assert.False(t, method(rubyController, "UsersController", "load_users").IsDead)
+	assert.True(t, method(rubyController, "UsersController", "unused_helper").IsDead)
+
+	phpController := "app/Http/Controllers/PostController.php"
+	assert.False(t, method(phpController, "PostController", "show").IsDead, "Laravel actions are entry points")
+	assert.False(t, method(phpController, "PostController", "published").IsDead)
+	assert.True(t, method(phpController, "PostController", "unusedHelper").IsDead)
+
+	imported := func(file string) []string {
+		var paths []string
+		for _, rel := range g.GetOutgoing(graph.GenerateID(graph.NodeFile, filepath.FromSlash(file), ""), graph.RelImports) {
+			paths = append(paths, filepath.ToSlash(g.GetNode(rel.Target).FilePath))
+		}
+		return paths
+	}
+	assert.Equal(t, []string{"app/models/user.rb"}, imported(rubyController))
+	assert.Equal(t, []string{"app/Models/Post.php"}, imported(phpController))
+
+	// Mixins are heritage
+	controllerID := graph.GenerateID(graph.NodeClass, filepath.FromSlash(rubyController), "UsersController")
+	implements := g.GetOutgoing(controllerID, graph.RelImplements)
+	require.Len(t, implements, 1)
+	assert.Equal(t, "Auditing", g.GetNode(implements[0].Target).Name)
+}
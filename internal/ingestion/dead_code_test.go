@@ -1,6 +1,7 @@
 package ingestion
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -22,7 +23,7 @@ func TestProcessDeadCode(t *testing.T) {
 		g.AddRelationship(&graph.GraphRelationship{ID: "calls:1", Type: graph.RelCalls, Source: "function:main.go:main", Target: "function:main.go:foo"})
 		g.AddRelationship(&graph.GraphRelationship{ID: "calls:2", Type: graph.RelCalls, Source: "function:main.go:foo", Target: "function:main.go:bar"})
 
-		count := ProcessDeadCode(g)
+		count := ProcessDeadCode(context.Background(), g)
 
 		assert.Equal(t, 0, count)
 
@@ -46,7 +47,7 @@ func TestProcessDeadCode(t *testing.T) {
 
 		g.AddRelationship(&graph.GraphRelationship{ID: "calls:1", Type: graph.RelCalls, Source: "function:main.go:main", Target: "function:main.go:foo"})
 
-		count := ProcessDeadCode(g)
+		count := ProcessDeadCode(context.Background(), g)
 
 		assert.Equal(t, 1, count)
 
@@ -61,7 +62,7 @@ func TestProcessDeadCode(t *testing.T) {
 		// Create: main (entry point, no callers)
 		g.AddNode(&graph.GraphNode{ID: "function:main.go:main", Label: graph.NodeFunction, Name: "main", FilePath: "main.go", IsEntryPoint: true})
 
-		count := ProcessDeadCode(g)
+		count := ProcessDeadCode(context.Background(), g)
 
 		assert.Equal(t, 0, count)
 
@@ -75,7 +76,7 @@ func TestProcessDeadCode(t *testing.T) {
 		// Create: ExportedFunction (exported, no callers)
 		g.AddNode(&graph.GraphNode{ID: "function:api.go:ExportedFunction", Label: graph.NodeFunction, Name: "ExportedFunction", FilePath: "api.go", IsExported: true})
 
-		count := ProcessDeadCode(g)
+		count := ProcessDeadCode(context.Background(), g)
 
 		assert.Equal(t, 0, count)
 
@@ -101,7 +102,7 @@ func TestProcessDeadCode(t *testing.T) {
 		// Derived class extends base
 		g.AddRelationship(&graph.GraphRelationship{ID: "extends:1", Type: graph.RelExtends, Source: "class:derived.go:Derived", Target: "class:base.go:Base"})
 
-		_ = ProcessDeadCode(g)
+		_ = ProcessDeadCode(context.Background(), g)
 
 		// Override method should not be dead even without direct callers
 		// (This test is marked as known limitation - override detection needs more work)
@@ -116,7 +117,7 @@ func TestProcessDeadCode(t *testing.T) {
 		// Create test function
 		g.AddNode(&graph.GraphNode{ID: "function:main_test.go:TestFoo", Label: graph.NodeFunction, Name: "TestFoo", FilePath: "main_test.go"})
 
-		count := ProcessDeadCode(g)
+		count := ProcessDeadCode(context.Background(), g)
 
 		assert.Equal(t, 0, count)
 
@@ -130,7 +131,7 @@ func TestProcessDeadCode(t *testing.T) {
 		// Create dunder method
 		g.AddNode(&graph.GraphNode{ID: "method:class.go:MyClass.__init__", Label: graph.NodeMethod, Name: "__init__", ClassName: "MyClass", FilePath: "class.go"})
 
-		count := ProcessDeadCode(g)
+		count := ProcessDeadCode(context.Background(), g)
 
 		assert.Equal(t, 0, count)
 
@@ -150,7 +151,7 @@ func TestProcessDeadCode(t *testing.T) {
 
 		g.AddRelationship(&graph.GraphRelationship{ID: "calls:1", Type: graph.RelCalls, Source: "function:main.go:main", Target: "function:main.go:foo"})
 
-		count := ProcessDeadCode(g)
+		count := ProcessDeadCode(context.Background(), g)
 
 		assert.Equal(t, 3, count)
 	})
@@ -184,6 +185,81 @@ func TestIsDeadCodeExempt(t *testing.T) {
 			node:     &graph.GraphNode{Name: "__init__", ClassName: "MyClass"},
 			expected: true,
 		},
+		{
+			name:     "JavaScriptTestFile",
+			node:     &graph.GraphNode{Name: "setup", FilePath: "src/__tests__/api.js", Language: "javascript", Label: graph.NodeFunction},
+			expected: true,
+		},
+		{
+			name:     "JavaScriptConstructor",
+			node:     &graph.GraphNode{Name: "constructor", ClassName: "Service", Label: graph.NodeMethod},
+			expected: true,
+		},
+		{
+			name:     "JavaConstructor",
+			node:     &graph.GraphNode{Name: "UserService", ClassName: "UserService", Language: "java", Label: graph.NodeMethod},
+			expected: true,
+		},
+		{
+			name:     "JavaTestSources",
+			node:     &graph.GraphNode{Name: "fixture", FilePath: "app/src/test/java/com/example/Fixtures.java", Language: "java", Label: graph.NodeMethod},
+			expected: true,
+		},
+		{
+			name:     "KotlinTestClass",
+			node:     &graph.GraphNode{Name: "user", FilePath: "UserServiceTest.kt", Language: "kotlin", Label: graph.NodeFunction},
+			expected: true,
+		},
+		{
+			name:     "CSharpConstructor",
+			node:     &graph.GraphNode{Name: "OrderService", ClassName: "OrderService", Language: "csharp", Label: graph.NodeMethod},
+			expected: true,
+		},
+		{
+			name:     "CSharpTestProject",
+			node:     &graph.GraphNode{Name: "Seed", FilePath: "tests/Shop.Tests/Fixtures.cs", Language: "csharp", Label: graph.NodeMethod},
+			expected: true,
+		},
+		{
+			name:     "CSharpProperty",
+			node:     &graph.GraphNode{Name: "Total", ClassName: "Order", Language: "csharp", Label: graph.NodeMethod, Properties: map[string]any{"property": true}},
+			expected: true,
+		},
+		{
+			name:     "CDeclaration",
+			node:     &graph.GraphNode{Name: "repo_close", FilePath: "include/repo.h", Language: "c", Label: graph.NodeFunction, Properties: map[string]any{"declaration": true}},
+			expected: true,
+		},
+		{
+			name:     "CPPDestructor",
+			node:     &graph.GraphNode{Name: "~Cache", ClassName: "Cache", Language: "cpp", Label: graph.NodeMethod},
+			expected: true,
+		},
+		{
+			name:     "CPPUnitTest",
+			node:     &graph.GraphNode{Name: "fill", FilePath: "src/cache_unittest.cc", Language: "cpp", Label: graph.NodeFunction},
+			expected: true,
+		},
+		{
+			name:     "RubyInitialize",
+			node:     &graph.GraphNode{Name: "initialize", ClassName: "Invoice", Language: "ruby", Label: graph.NodeMethod},
+			expected: true,
+		},
+		{
+			name:     "RubySpec",
+			node:     &graph.GraphNode{Name: "build_user", FilePath: "spec/models/user_spec.rb", Language: "ruby", Label: graph.NodeMethod},
+			expected: true,
+		},
+		{
+			name:     "PHPConstructor",
+			node:     &graph.GraphNode{Name: "__construct", ClassName: "UserService", Language: "php", Label: graph.NodeMethod},
+			expected: true,
+		},
+		{
+			name:     "PHPUnitTest",
+			node:     &graph.GraphNode{Name: "makeUser", FilePath: "app/UserServiceTest.php", Language: "php", Label: graph.NodeMethod},
+			expected: true,
+		},
 		{
 			name:     "RegularFunction",
 			node:     &graph.GraphNode{Name: "helper"},
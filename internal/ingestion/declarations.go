@@ -0,0 +1,90 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// ProcessDeclarations links C and C++ declarations of functions and
+// methods (prototypes in headers, member functions declared in a class) to
+// their definitions with DECLARES relationships, and returns their number.
+//
+// A declaration matches the definitions of the same name and class. Of
+// several, the definition in a file including the declaring header is
+// used, then one in a file of the same name (repo.h and repo.c); otherwise
+// a declaration with several definitions is left unlinked. Static
+// functions are only visible in their own file and never match. A
+// definition of a member function takes its visibility from the
+// declaration in the class.
+func ProcessDeclarations(ctx context.Context, g *graph.KnowledgeGraph) int {
+	type symbolKey struct{ name, class string }
+	definitions := make(map[symbolKey][]*graph.GraphNode)
+	var declarations []*graph.GraphNode
+	for _, label := range []graph.NodeLabel{graph.NodeFunction, graph.NodeMethod} {
+		for _, node := range g.GetNodesByLabel(label) {
+			if node.Language != "c" && node.Language != "cpp" {
+				continue
+			}
+			if isDeclaration(node) {
+				declarations = append(declarations, node)
+				continue
+			}
+			if label == graph.NodeFunction && !node.IsExported {
+				continue
+			}
+			key := symbolKey{node.Name, node.ClassName}
+			definitions[key] = append(definitions[key], node)
+		}
+	}
+
+	linked := 0
+	for _, decl := range declarations {
+		candidates := definitions[symbolKey{decl.Name, decl.ClassName}]
+		sort.Slice(candidates, func(i, j int) bool { return candidates[i].FilePath < candidates[j].FilePath })
+		def := matchDefinition(g, decl, candidates)
+		if def == nil {
+			continue
+		}
+		g.AddRelationship(&graph.GraphRelationship{
+			ID:     relationshipID(graph.RelDeclares, decl.ID, def.ID),
+			Type:   graph.RelDeclares,
+			Source: decl.ID,
+			Target: def.ID,
+		})
+		if def.Label == graph.NodeMethod {
+			def.IsExported = decl.IsExported
+		}
+		linked++
+	}
+	return linked
+}
+
+// matchDefinition returns the definition among candidates, sorted by path,
+// that the declaration decl refers to, or nil.
+func matchDefinition(g *graph.KnowledgeGraph, decl *graph.GraphNode, candidates []*graph.GraphNode) *graph.GraphNode {
+	if len(candidates) == 0 {
+		return nil
+	}
+	declFileID := graph.GenerateID(graph.NodeFile, decl.FilePath, "")
+	for _, def := range candidates {
+		for _, rel := range g.GetOutgoing(graph.GenerateID(graph.NodeFile, def.FilePath, ""), graph.RelImports) {
+			if rel.Target == declFileID {
+				return def
+			}
+		}
+	}
+	stem := strings.TrimSuffix(filepath.Base(decl.FilePath), filepath.Ext(decl.FilePath))
+	for _, def := range candidates {
+		if strings.TrimSuffix(filepath.Base(def.FilePath), filepath.Ext(def.FilePath)) == stem {
+			return def
+		}
+	}
+	if len(candidates) == 1 {
+		return candidates[0]
+	}
+	return nil
+}
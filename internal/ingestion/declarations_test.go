@@ -0,0 +1,140 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestProcessDeclarations(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"include/store/repo.h": `#pragma once
+#include <stddef.h>
+
+typedef struct repo repo_t;
+
+repo_t *repo_open(const char *path);
+void repo_close(repo_t *repo);
+size_t repo_size(const repo_t *repo);
+`,
+		"src/repo.c": `#include "store/repo.h"
+
+static void repo_flush(repo_t *repo) {}
+
+repo_t *repo_open(const char *path) { return 0; }
+
+void repo_close(repo_t *repo) { repo_flush(repo); }
+`,
+		"src/size.c":         "#include \"../include/store/repo.h\"\n\nsize_t repo_size(const repo_t *repo) { return 0; }\n",
+		"tools/repo_close.c": "void repo_close(void *repo) {}\n",
+		"src/cache.hpp": `#include <string>
+
+namespace store {
+
+class Cache {
+public:
+    explicit Cache(size_t capacity);
+    bool put(const std::string& key);
+private:
+    void evict();
+};
+
+}
+`,
+		"src/cache.cpp": `#include "cache.hpp"
+
+namespace store {
+
+Cache::Cache(size_t capacity) {}
+
+bool Cache::put(const std::string& key) { this->evict(); return true; }
+
+void Cache::evict() {}
+
+}
+`,
+	})
+
+	g, parseData := runTestPipeline(t, dir)
+	assert.Equal(t, 6, ProcessDeclarations(context.Background(), g))
+
+	declares := func(declFile, declName string, label graph.NodeLabel) *graph.GraphNode {
+		t.Helper()
+		declID := graph.GenerateID(label, filepath.FromSlash(declFile), declName)
+		require.NotNil(t, g.GetNode(declID), declName)
+		rels := g.GetOutgoing(declID, graph.RelDeclares)
+		require.Len(t, rels, 1, declName)
+		return g.GetNode(rels[0].Target)
+	}
+
+	open := declares("include/store/repo.h", "repo_open", graph.NodeFunction)
+	assert.Equal(t, filepath.Join("src", "repo.c"), open.FilePath)
+	assert.Equal(t, filepath.Join("src", "repo.c"), declares("include/store/repo.h", "repo_close", graph.NodeFunction).FilePath,
+		"the definition in a file including the header")
+	assert.Equal(t, filepath.Join("src", "size.c"), declares("include/store/repo.h", "repo_size", graph.NodeFunction).FilePath)
+
+	header := g.GetNode(graph.GenerateID(graph.NodeFunction, filepath.Join("include", "store", "repo.h"), "repo_open"))
+	assert.Equal(t, true, header.Properties["declaration"])
+
+	put := declares("src/cache.hpp", "Cache.put", graph.NodeMethod)
+	assert.Equal(t, filepath.Join("src", "cache.cpp"), put.FilePath)
+	assert.True(t, put.IsExported)
+	evict := declares("src/cache.hpp", "Cache.evict", graph.NodeMethod)
+	assert.False(t, evict.IsExported, "definitions take the access of their declaration")
+	declares("src/cache.hpp", "Cache.Cache", graph.NodeMethod)
+
+	// Calls resolve to definitions rather than declarations
+	ProcessCalls(context.Background(), parseData, g)
+	closeID := graph.GenerateID(graph.NodeFunction, filepath.Join("src", "repo.c"), "repo_close")
+	assert.Contains(t, callTargets(g, closeID), graph.GenerateID(graph.NodeFunction, filepath.Join("src", "repo.c"), "repo_flush"))
+	putID := graph.GenerateID(graph.NodeMethod, filepath.Join("src", "cache.cpp"), "Cache.put")
+	assert.Contains(t, callTargets(g, putID), evict.ID)
+}
+
+func TestProcessCalls_Cgo(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"go.mod": "module example.com/native\n\ngo 1.22\n",
+		"native/native.go": `package native
+
+// #cgo CFLAGS: -I${SRCDIR}/../include
+// #include "codec.h"
+// #include <stdlib.h>
+import "C"
+
+// Encode encodes a value with the C codec.
+func Encode(v int) int {
+	return int(C.codec_encode(C.int(v)))
+}
+`,
+		"native/codec.c":    "#include \"codec.h\"\n\nint codec_encode(int v) { return v + 1; }\n",
+		"include/codec.h":   "int codec_encode(int v);\n",
+		"other/codec_alt.c": "int codec_encode(int v) { return v; }\n",
+	})
+
+	g, parseData := runTestPipeline(t, dir)
+	_ = ProcessGoTypes(context.Background(), dir, parseData)
+	ProcessDeclarations(context.Background(), g)
+	ProcessCalls(context.Background(), parseData, g)
+
+	goFileID := graph.GenerateID(graph.NodeFile, filepath.Join("native", "native.go"), "")
+	var imported []string
+	for _, rel := range g.GetOutgoing(goFileID, graph.RelImports) {
+		imported = append(imported, g.GetNode(rel.Target).FilePath)
+	}
+	assert.Equal(t, []string{filepath.Join("include", "codec.h")}, imported, "headers of the cgo preamble")
+
+	encodeID := graph.GenerateID(graph.NodeFunction, filepath.Join("native", "native.go"), "Encode")
+	targets := callTargets(g, encodeID)
+	assert.Contains(t, targets, graph.GenerateID(graph.NodeFunction, filepath.Join("native", "codec.c"), "codec_encode"),
+		"C calls resolve to the definition compiled with the package")
+	assert.Len(t, targets, 1)
+}
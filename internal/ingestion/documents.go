@@ -0,0 +1,136 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// documentedLabels are the labels of the symbols documentation can
+// mention.
+var documentedLabels = []graph.NodeLabel{
+	graph.NodeFunction, graph.NodeMethod, graph.NodeClass,
+	graph.NodeInterface, graph.NodeTypeAlias, graph.NodeEnum,
+	graph.NodeVariable, graph.NodeConstant,
+}
+
+// ProcessDocuments creates a document node for every section of the
+// documentation files, defined by its file, and DOCUMENTS relationships
+// from each section to the symbols its code spans name. It returns the
+// number of sections created.
+//
+// A mention names a symbol by its name (`Parse`) or qualified by its class
+// or package (`Store.Get`, `graph.GenerateID`, `Cache::put`); a qualified
+// mention only matches symbols of that class or of a package directory or
+// module file of that name. When several symbols match, the section
+// documents the one closest to the documentation file, preferring
+// functions and types to methods.
+func ProcessDocuments(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph) int {
+	filePaths := make([]string, 0, len(parseData.Files))
+	for filePath, result := range parseData.Files {
+		if len(result.Sections) > 0 {
+			filePaths = append(filePaths, filePath)
+		}
+	}
+	if len(filePaths) == 0 {
+		return 0
+	}
+	sort.Strings(filePaths)
+
+	symbols := make(map[string][]*graph.GraphNode)
+	for _, label := range documentedLabels {
+		for _, node := range g.GetNodesByLabel(label) {
+			symbols[node.Name] = append(symbols[node.Name], node)
+		}
+	}
+
+	count := 0
+	for _, filePath := range filePaths {
+		fileID := graph.GenerateID(graph.NodeFile, filePath, "")
+		for _, section := range parseData.Files[filePath].Sections {
+			name := strings.Join(section.Path, " > ")
+			if name == "" {
+				name = section.Title
+			}
+			id := graph.GenerateID(graph.NodeDocument, filePath, name)
+			if g.GetNode(id) != nil {
+				// A heading repeated under the same parents
+				id = graph.GenerateID(graph.NodeDocument, filePath, fmt.Sprintf("%s (line %d)", name, section.StartLine))
+			}
+			signature, _, _ := strings.Cut(section.Content, "\n")
+			g.AddNode(&graph.GraphNode{
+				ID:         id,
+				Label:      graph.NodeDocument,
+				Name:       section.Title,
+				FilePath:   filePath,
+				StartLine:  section.StartLine,
+				EndLine:    section.EndLine,
+				Content:    section.Content,
+				Signature:  strings.TrimSpace(signature),
+				Language:   "markdown",
+				Properties: map[string]any{"level": section.Level, "path": name},
+			})
+			g.AddRelationship(&graph.GraphRelationship{
+				ID:     relationshipID(graph.RelDefines, fileID, id),
+				Type:   graph.RelDefines,
+				Source: fileID,
+				Target: id,
+			})
+			count++
+
+			for _, mention := range section.Mentions {
+				target := mentionedSymbol(symbols, mention, filePath)
+				if target == nil {
+					continue
+				}
+				g.AddRelationship(&graph.GraphRelationship{
+					ID:     relationshipID(graph.RelDocuments, id, target.ID),
+					Type:   graph.RelDocuments,
+					Source: id,
+					Target: target.ID,
+				})
+			}
+		}
+	}
+	return count
+}
+
+// mentionedSymbol returns the symbol a mention in the documentation file
+// docPath names, or nil.
+func mentionedSymbol(symbols map[string][]*graph.GraphNode, mention, docPath string) *graph.GraphNode {
+	mention = strings.NewReplacer("::", ".", "#", ".").Replace(mention)
+	parts := strings.Split(mention, ".")
+	candidates := symbols[parts[len(parts)-1]]
+
+	if len(parts) > 1 {
+		qualifier := parts[len(parts)-2]
+		var qualified []*graph.GraphNode
+		for _, node := range candidates {
+			file := filepath.Base(node.FilePath)
+			if node.ClassName == qualifier || filepath.Base(filepath.Dir(node.FilePath)) == qualifier ||
+				strings.TrimSuffix(file, filepath.Ext(file)) == qualifier {
+				qualified = append(qualified, node)
+			}
+		}
+		candidates = qualified
+	} else if len(candidates) > 1 {
+		var unbound []*graph.GraphNode
+		for _, node := range candidates {
+			if node.Label != graph.NodeMethod {
+				unbound = append(unbound, node)
+			}
+		}
+		if len(unbound) > 0 {
+			candidates = unbound
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil
+	}
+	return closestNode(candidates, docPath)
+}
@@ -0,0 +1,107 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/storage"
+)
+
+func TestProcessDocuments(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"store/store.go": `package store
+
+// Store persists orders.
+type Store struct{}
+
+// Get returns the order stored under id.
+func (s *Store) Get(id string) string { return "" }
+
+// Open opens the store.
+func Open() *Store { return &Store{} }
+`,
+		"cache/cache.go": `package cache
+
+type Cache struct{}
+
+func (c *Cache) Get(id string) string { return "" }
+`,
+		"docs/design.md": "# Design\n" +
+			"\n" +
+			"Orders live in a `Store`, opened with `store.Open()`.\n" +
+			"\n" +
+			"## Reads\n" +
+			"\n" +
+			"See `Store.Get` and `Unknown`.\n" +
+			"\n" +
+			"## Reads\n" +
+			"\n" +
+			"Repeated heading.\n",
+	})
+
+	g, _, err := RunPipeline(context.Background(), dir, nil, false, nil, false, 0)
+	require.NoError(t, err)
+
+	docFile := filepath.Join("docs", "design.md")
+	design := graph.GenerateID(graph.NodeDocument, docFile, "Design")
+	reads := graph.GenerateID(graph.NodeDocument, docFile, "Design > Reads")
+	storeType := graph.GenerateID(graph.NodeClass, filepath.Join("store", "store.go"), "Store")
+	storeGet := graph.GenerateID(graph.NodeMethod, filepath.Join("store", "store.go"), "Store.Get")
+	open := graph.GenerateID(graph.NodeFunction, filepath.Join("store", "store.go"), "Open")
+
+	documents := func(id string) []string {
+		var targets []string
+		for _, rel := range g.GetOutgoing(id, graph.RelDocuments) {
+			targets = append(targets, rel.Target)
+		}
+		return targets
+	}
+
+	t.Run("CreatesSections", func(t *testing.T) {
+		node := g.GetNode(reads)
+		require.NotNil(t, node)
+		assert.Equal(t, graph.NodeDocument, node.Label)
+		assert.Equal(t, "Reads", node.Name)
+		assert.Equal(t, "## Reads", node.Signature)
+		assert.Equal(t, 2, node.Properties["level"])
+		assert.Equal(t, "Design > Reads", node.Properties["path"])
+		assert.True(t, g.HasIncoming(reads, graph.RelDefines))
+		assert.False(t, node.IsDead, "documents are not dead code")
+
+		assert.Len(t, g.GetNodesByLabel(graph.NodeDocument), 3, "repeated headings get their own section")
+	})
+
+	t.Run("LinksMentions", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{storeType, open}, documents(design))
+		assert.Equal(t, []string{storeGet}, documents(reads), "qualified mentions match their class")
+	})
+
+	t.Run("CapturesDocComments", func(t *testing.T) {
+		assert.Equal(t, "Store persists orders.", g.GetNode(storeType).Doc)
+		assert.Equal(t, "Get returns the order stored under id.", g.GetNode(storeGet).Doc)
+	})
+
+	t.Run("StoresDocuments", func(t *testing.T) {
+		store := storage.NewBadgerBackend()
+		require.NoError(t, store.Initialize(t.TempDir(), false))
+		defer store.Close()
+		require.NoError(t, store.BulkLoad(t.Context(), g))
+
+		nodes, err := store.GetDocuments(t.Context(), storeGet)
+		require.NoError(t, err)
+		require.Len(t, nodes, 1)
+		assert.Equal(t, reads, nodes[0].ID)
+
+		node, err := store.GetNode(t.Context(), open)
+		require.NoError(t, err)
+		assert.Equal(t, "Open opens the store.", node.Doc)
+	})
+}
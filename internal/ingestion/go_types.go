@@ -0,0 +1,149 @@
+package ingestion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// isGoModule reports whether repoPath is the root of a Go module or workspace.
+func isGoModule(repoPath string) bool {
+	for _, name := range []string{"go.mod", "go.work"} {
+		if _, err := os.Stat(filepath.Join(repoPath, name)); err == nil {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessGoTypes type-checks the Go packages of the repository and replaces
+// the syntactic call sites and variable references of every Go file with
+// ones resolved through go/types, so that ProcessCalls and ProcessVariables
+// can link each of them to the exact declaration.
+//
+// Repositories that are not Go modules are left untouched. If the packages
+// cannot be loaded the error is returned and the syntactic call sites are
+// kept, which makes ProcessCalls fall back to name-based resolution.
+func ProcessGoTypes(ctx context.Context, repoPath string, parseData *ParseData) error {
+	if !isGoModule(repoPath) || !hasGoFiles(parseData) {
+		return nil
+	}
+
+	prog, err := parsers.LoadGoProgram(ctx, repoPath)
+	if err != nil {
+		return err
+	}
+
+	parseData.mu.Lock()
+	defer parseData.mu.Unlock()
+
+	parseData.GoImplementations = prog.Implementations()
+	parseData.goTyped = make(map[string]bool)
+	for filePath, result := range parseData.Files {
+		if !prog.HasFile(filePath) {
+			continue
+		}
+		result.Calls = prog.Calls(filePath)
+		result.VarRefs = prog.VariableRefs(filePath)
+		parseData.goTyped[filePath] = true
+	}
+
+	return nil
+}
+
+// hasGoFiles reports whether any parsed file is a Go file.
+func hasGoFiles(parseData *ParseData) bool {
+	parseData.mu.RLock()
+	defer parseData.mu.RUnlock()
+
+	for filePath := range parseData.Files {
+		if filepath.Ext(filePath) == ".go" {
+			return true
+		}
+	}
+	return false
+}
+
+// ProcessGoImplements creates IMPLEMENTS relationships from Go types to the
+// interfaces they satisfy, and from each implementing method to the interface
+// method it implements. Go has no implements clause, so satisfaction is
+// computed structurally from the method sets of the type-checked program.
+// It does nothing if the Go packages were not type-checked by ProcessGoTypes.
+func ProcessGoImplements(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph) int {
+	count := 0
+	seen := make(map[string]bool)
+	for _, impl := range parseData.GoImplementations {
+		targetID := graph.GenerateID(graph.NodeInterface, impl.InterfaceFile, impl.InterfaceName)
+		if g.GetNode(targetID) == nil {
+			continue
+		}
+
+		// Structs are class nodes; other named types (func types, slices,
+		// ...) with methods are type aliases, or enums.
+		sourceID := ""
+		for _, label := range []graph.NodeLabel{graph.NodeClass, graph.NodeTypeAlias, graph.NodeEnum} {
+			if id := graph.GenerateID(label, impl.TypeFile, impl.TypeName); g.GetNode(id) != nil {
+				sourceID = id
+				break
+			}
+		}
+		if sourceID == "" {
+			continue
+		}
+
+		g.AddRelationship(&graph.GraphRelationship{
+			ID:     relationshipID(graph.RelImplements, sourceID, targetID),
+			Type:   graph.RelImplements,
+			Source: sourceID,
+			Target: targetID,
+			Properties: map[string]any{
+				"pointer_receiver": impl.PointerReceiver,
+			},
+		})
+		count++
+
+		count += addMethodImplements(g, impl, seen)
+	}
+
+	return count
+}
+
+// addMethodImplements links every method of an implementing type to the
+// interface method it implements. Impact analysis follows these links to
+// reach callers that only know the interface.
+func addMethodImplements(g *graph.KnowledgeGraph, impl parsers.Implementation, seen map[string]bool) int {
+	count := 0
+	for _, m := range impl.Methods {
+		if m.Interface.FilePath == "" || m.Concrete.FilePath == "" {
+			continue
+		}
+		sourceID := graph.GenerateID(graph.NodeMethod, m.Concrete.FilePath,
+			symbolNodeName(m.Concrete.Name, m.Concrete.ClassName, graph.NodeMethod))
+		targetID := graph.GenerateID(graph.NodeMethod, m.Interface.FilePath,
+			symbolNodeName(m.Interface.Name, m.Interface.ClassName, graph.NodeMethod))
+		if g.GetNode(sourceID) == nil || g.GetNode(targetID) == nil {
+			continue
+		}
+
+		relID := relationshipID(graph.RelImplements, sourceID, targetID)
+		if seen[relID] {
+			// Promoted methods implement the interface for every embedding type
+			continue
+		}
+		seen[relID] = true
+		g.AddRelationship(&graph.GraphRelationship{
+			ID:     relID,
+			Type:   graph.RelImplements,
+			Source: sourceID,
+			Target: targetID,
+			Properties: map[string]any{
+				"confidence": dispatchConfidence,
+			},
+		})
+		count++
+	}
+	return count
+}
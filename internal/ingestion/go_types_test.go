@@ -0,0 +1,211 @@
+package ingestion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// writeRepo writes files into a temporary directory and returns its path.
+func writeRepo(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0o755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0o644))
+	}
+	return dir
+}
+
+// callTargets returns the targets of the CALLS relationships leaving nodeID.
+func callTargets(g *graph.KnowledgeGraph, nodeID string) map[string]*graph.GraphRelationship {
+	targets := make(map[string]*graph.GraphRelationship)
+	for _, rel := range g.GetOutgoing(nodeID, graph.RelCalls) {
+		targets[rel.Target] = rel
+	}
+	return targets
+}
+
+func TestProcessGoTypes(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"store/store.go": `package store
+
+type Store struct{}
+
+func New() *Store { return &Store{} }
+
+func (s *Store) Close() error { return nil }
+`,
+		"cache/cache.go": `package cache
+
+type Cache struct{}
+
+func New() *Cache { return &Cache{} }
+
+func (c *Cache) Close() error { return nil }
+`,
+		"main.go": `package main
+
+import "example.com/shop/store"
+
+func main() {
+	s := store.New()
+	defer s.Close()
+}
+`,
+	})
+
+	g, _, err := RunPipeline(context.Background(), dir, nil, false, nil, false, 0)
+	require.NoError(t, err)
+
+	targets := callTargets(g, "function:main.go:main")
+
+	t.Run("LinksSameNamedFunctionsByPackage", func(t *testing.T) {
+		storeNew := "function:" + filepath.Join("store", "store.go") + ":New"
+		cacheNew := "function:" + filepath.Join("cache", "cache.go") + ":New"
+
+		require.Contains(t, targets, storeNew)
+		assert.NotContains(t, targets, cacheNew)
+		assert.Equal(t, typedCallConfidence, targets[storeNew].Properties["confidence"])
+	})
+
+	t.Run("LinksMethodsByReceiverType", func(t *testing.T) {
+		storeClose := "method:" + filepath.Join("store", "store.go") + ":Store.Close"
+		cacheClose := "method:" + filepath.Join("cache", "cache.go") + ":Cache.Close"
+
+		assert.Contains(t, targets, storeClose)
+		assert.NotContains(t, targets, cacheClose)
+	})
+}
+
+func TestProcessGoTypes_NotAModule(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"main.go": "package main\n\nfunc main() { helper() }\n\nfunc helper() {}\n",
+	})
+
+	g, _, err := RunPipeline(context.Background(), dir, nil, false, nil, false, 0)
+	require.NoError(t, err)
+
+	// Without a go.mod, calls are still resolved by name.
+	targets := callTargets(g, "function:main.go:main")
+	require.Contains(t, targets, "function:main.go:helper")
+	assert.Equal(t, heuristicCallConfidence, targets["function:main.go:helper"].Properties["confidence"])
+}
+
+func TestProcessGoImplements(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"go.mod": "module example.com/kv\n\ngo 1.22\n",
+		"storage/backend.go": `package storage
+
+type StorageBackend interface {
+	Close() error
+}
+`,
+		"storage/badger.go": `package storage
+
+type BadgerBackend struct{}
+
+func (b *BadgerBackend) Close() error { return nil }
+`,
+		"memory/memory.go": `package memory
+
+type MemoryBackend struct{}
+
+func (m MemoryBackend) Close() error { return nil }
+`,
+	})
+
+	g, _, err := RunPipeline(context.Background(), dir, nil, false, nil, false, 0)
+	require.NoError(t, err)
+
+	ifaceID := "interface:" + filepath.Join("storage", "backend.go") + ":StorageBackend"
+	implementers := make(map[string]*graph.GraphRelationship)
+	for _, rel := range g.GetIncoming(ifaceID, graph.RelImplements) {
+		implementers[rel.Source] = rel
+	}
+
+	badgerID := "class:" + filepath.Join("storage", "badger.go") + ":BadgerBackend"
+	memoryID := "class:" + filepath.Join("memory", "memory.go") + ":MemoryBackend"
+	require.Contains(t, implementers, badgerID)
+	require.Contains(t, implementers, memoryID)
+	assert.Equal(t, true, implementers[badgerID].Properties["pointer_receiver"])
+	assert.Equal(t, false, implementers[memoryID].Properties["pointer_receiver"])
+}
+
+func TestProcessGoImplements_Methods(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"go.mod": "module example.com/kv\n\ngo 1.22\n",
+		"kv/kv.go": `package kv
+
+type store interface {
+	get(key string) string
+}
+
+type base struct{}
+
+func (base) get(key string) string { return key }
+
+type memory struct {
+	base
+}
+
+type disk struct{}
+
+func (d *disk) get(key string) string { return "" }
+
+func lookup(s store) string { return s.get("k") }
+
+func Run() string { return lookup(memory{}) + lookup(&disk{}) }
+`,
+	})
+
+	g, _, err := RunPipeline(context.Background(), dir, nil, false, nil, false, 0)
+	require.NoError(t, err)
+
+	file := filepath.Join("kv", "kv.go")
+	ifaceMethodID := "method:" + file + ":store.get"
+	require.NotNil(t, g.GetNode(ifaceMethodID))
+
+	implementers := make(map[string]*graph.GraphRelationship)
+	for _, rel := range g.GetIncoming(ifaceMethodID, graph.RelImplements) {
+		implementers[rel.Source] = rel
+	}
+
+	t.Run("LinksImplementingMethods", func(t *testing.T) {
+		diskGet := "method:" + file + ":disk.get"
+		require.Contains(t, implementers, diskGet)
+		assert.Equal(t, dispatchConfidence, implementers[diskGet].Properties["confidence"])
+
+		// memory implements store through the promoted base.get
+		assert.Contains(t, implementers, "method:"+file+":base.get")
+		assert.Len(t, implementers, 2)
+	})
+
+	t.Run("CallsResolveToInterfaceMethod", func(t *testing.T) {
+		targets := callTargets(g, "function:"+file+":lookup")
+		assert.Contains(t, targets, ifaceMethodID)
+	})
+
+	t.Run("ImplementationsAreNotDeadCode", func(t *testing.T) {
+		assert.False(t, g.GetNode(ifaceMethodID).IsDead)
+		assert.False(t, g.GetNode("method:"+file+":disk.get").IsDead)
+		assert.False(t, g.GetNode("method:"+file+":base.get").IsDead)
+	})
+}
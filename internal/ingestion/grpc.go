@@ -0,0 +1,187 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// ProcessGRPC links the Go code generated from .proto files by protoc-gen-go
+// and protoc-gen-go-grpc, and the servers written against it, to the
+// Protocol Buffers definitions. It returns the number of relationships
+// created.
+//
+// A generated file (user.pb.go, user_grpc.pb.go) belongs to the .proto file
+// of the same name closest to it. The struct generated for a message and
+// the type generated for an enum IMPLEMENT them, and so do the XServer
+// interface generated for a service and its methods the service and its
+// rpcs. The XClient interface and the xClient stub CALL the rpcs they
+// invoke. Types in other files that implement an XServer interface, as
+// found by ProcessGoImplements, implement the service, and their methods
+// the rpcs.
+//
+// The IMPLEMENTS relationships to rpcs are marked "rpc": impact analysis
+// follows them, so that the callers of an rpc include both its server
+// handlers and, through the client, its call sites.
+func ProcessGRPC(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph) int {
+	protos := make(map[string][]string)
+	var generated []string
+	for filePath := range parseData.Files {
+		if importLanguage(filePath) == "proto" {
+			protos[filepath.Base(filePath)] = append(protos[filepath.Base(filePath)], filePath)
+		} else if _, ok := generatedGoStem(filePath); ok {
+			generated = append(generated, filePath)
+		}
+	}
+	if len(protos) == 0 {
+		return 0
+	}
+	for _, paths := range protos {
+		sort.Strings(paths)
+	}
+	sort.Strings(generated)
+
+	count := 0
+	link := func(relType graph.RelType, sourceID, targetID string, properties map[string]any) {
+		if g.GetNode(sourceID) == nil || g.GetNode(targetID) == nil {
+			return
+		}
+		g.AddRelationship(&graph.GraphRelationship{
+			ID:         relationshipID(relType, sourceID, targetID),
+			Type:       relType,
+			Source:     sourceID,
+			Target:     targetID,
+			Properties: properties,
+		})
+		count++
+	}
+
+	// The generated server interfaces and their methods, by the services
+	// and rpcs they are generated from
+	servers := make(map[string]string)
+	var serverIDs []string
+	for _, goFile := range generated {
+		stem, _ := generatedGoStem(goFile)
+		protoFile := closestSuffixMatch(protos[stem+".proto"], goFile, stem+".proto")
+		if protoFile == "" {
+			continue
+		}
+		for _, sym := range parseData.Files[protoFile].Symbols {
+			label := symbolLabel(sym.Kind)
+			protoID := graph.GenerateID(label, protoFile, symbolNodeName(sym.Name, sym.ClassName, label))
+			switch sym.Kind {
+			case graph.NodeClass:
+				link(graph.RelImplements, graph.GenerateID(graph.NodeClass, goFile, goCamelCase(sym.Name)), protoID,
+					map[string]any{"generated": true})
+			case graph.NodeEnum:
+				link(graph.RelImplements, graph.GenerateID(graph.NodeTypeAlias, goFile, goCamelCase(sym.Name)), protoID,
+					map[string]any{"generated": true})
+			case graph.NodeInterface:
+				serverID := graph.GenerateID(graph.NodeInterface, goFile, goCamelCase(sym.Name)+"Server")
+				link(graph.RelImplements, serverID, protoID, map[string]any{"generated": true})
+				link(graph.RelImplements, graph.GenerateID(graph.NodeInterface, goFile, goCamelCase(sym.Name)+"Client"), protoID,
+					map[string]any{"generated": true})
+				if _, ok := servers[serverID]; !ok && g.GetNode(serverID) != nil {
+					servers[serverID] = protoID
+					serverIDs = append(serverIDs, serverID)
+				}
+			case graph.NodeMethod:
+				service, method := goCamelCase(sym.ClassName), goCamelCase(sym.Name)
+				serverID := graph.GenerateID(graph.NodeMethod, goFile, service+"Server."+method)
+				link(graph.RelImplements, serverID, protoID, map[string]any{"generated": true, "rpc": true})
+				if _, ok := servers[serverID]; !ok && g.GetNode(serverID) != nil {
+					servers[serverID] = protoID
+					serverIDs = append(serverIDs, serverID)
+				}
+				for _, client := range []string{service + "Client", unexport(service) + "Client"} {
+					link(graph.RelCalls, graph.GenerateID(graph.NodeMethod, goFile, client+"."+method), protoID,
+						map[string]any{"confidence": typedCallConfidence, "generated": true})
+				}
+			}
+		}
+	}
+
+	// Hand-written servers, but not the generated UnimplementedXServer
+	for _, serverID := range serverIDs {
+		protoID := servers[serverID]
+		for _, rel := range g.GetIncoming(serverID, graph.RelImplements) {
+			source := g.GetNode(rel.Source)
+			if source == nil {
+				continue
+			}
+			if _, ok := generatedGoStem(source.FilePath); ok {
+				continue
+			}
+			if source.Label == graph.NodeMethod {
+				link(graph.RelImplements, source.ID, protoID, map[string]any{"confidence": dispatchConfidence, "rpc": true})
+			} else {
+				link(graph.RelImplements, source.ID, protoID, nil)
+			}
+		}
+	}
+
+	return count
+}
+
+// generatedGoStem returns the name of the .proto file, without extension,
+// that a Go file was generated from by protoc-gen-go (user.pb.go) or
+// protoc-gen-go-grpc (user_grpc.pb.go).
+func generatedGoStem(filePath string) (string, bool) {
+	base := filepath.Base(filePath)
+	for _, suffix := range []string{"_grpc.pb.go", ".pb.go"} {
+		if stem, ok := strings.CutSuffix(base, suffix); ok && stem != "" {
+			return stem, true
+		}
+	}
+	return "", false
+}
+
+// goCamelCase returns the Go name protoc-gen-go generates for a Protocol
+// Buffers name: underscores and dots followed by a lower-case letter are
+// dropped and the letter upper-cased, and the other dots of nested names
+// become underscores (get_user is GetUser, Order.Item is Order_Item).
+func goCamelCase(name string) string {
+	var b []byte
+	for i := 0; i < len(name); i++ {
+		c := name[i]
+		switch {
+		case c == '.' && i+1 < len(name) && isASCIILower(name[i+1]):
+			// Skip over '.' in ".{{lowercase}}"
+		case c == '.':
+			b = append(b, '_')
+		case c == '_' && (i == 0 || name[i-1] == '.'):
+			// A leading underscore would make the name unexported
+			b = append(b, 'X')
+		case c == '_' && i+1 < len(name) && isASCIILower(name[i+1]):
+			// Skip over '_' in "_{{lowercase}}"
+		case '0' <= c && c <= '9':
+			b = append(b, c)
+		default:
+			if isASCIILower(c) {
+				c -= 'a' - 'A'
+			}
+			b = append(b, c)
+			for ; i+1 < len(name) && isASCIILower(name[i+1]); i++ {
+				b = append(b, name[i+1])
+			}
+		}
+	}
+	return string(b)
+}
+
+// isASCIILower reports whether c is a lower-case ASCII letter.
+func isASCIILower(c byte) bool {
+	return 'a' <= c && c <= 'z'
+}
+
+// unexport returns name with its first letter lower-cased, as
+// protoc-gen-go-grpc names the client stub of a service.
+func unexport(name string) string {
+	if name == "" {
+		return name
+	}
+	return strings.ToLower(name[:1]) + name[1:]
+}
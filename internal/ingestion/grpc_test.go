@@ -0,0 +1,208 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/storage"
+)
+
+func TestProcessGRPC(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"go.mod": "module example.com/users\n\ngo 1.22\n",
+		"proto/users/v1/users.proto": `syntax = "proto3";
+package users.v1;
+
+service UserService {
+  rpc GetUser(GetUserRequest) returns (User);
+}
+
+message GetUserRequest { string id = 1; }
+
+message User {
+  string id = 1;
+  Role role = 2;
+  message Address { string city = 1; }
+}
+
+enum Role {
+  ROLE_UNSPECIFIED = 0;
+}
+`,
+		// Trimmed-down protoc-gen-go and protoc-gen-go-grpc output
+		"gen/users/v1/users.pb.go": `package usersv1
+
+type GetUserRequest struct{ Id string }
+
+type User struct {
+	Id   string
+	Role Role
+}
+
+type User_Address struct{ City string }
+
+type Role int32
+`,
+		"gen/users/v1/users_grpc.pb.go": `package usersv1
+
+import "context"
+
+type UserServiceClient interface {
+	GetUser(ctx context.Context, in *GetUserRequest) (*User, error)
+}
+
+type userServiceClient struct{}
+
+func NewUserServiceClient() UserServiceClient { return &userServiceClient{} }
+
+func (c *userServiceClient) GetUser(ctx context.Context, in *GetUserRequest) (*User, error) {
+	return nil, nil
+}
+
+type UserServiceServer interface {
+	GetUser(context.Context, *GetUserRequest) (*User, error)
+	mustEmbedUnimplementedUserServiceServer()
+}
+
+type UnimplementedUserServiceServer struct{}
+
+func (UnimplementedUserServiceServer) GetUser(context.Context, *GetUserRequest) (*User, error) {
+	return nil, nil
+}
+
+func (UnimplementedUserServiceServer) mustEmbedUnimplementedUserServiceServer() {}
+`,
+		"server/server.go": `package server
+
+import (
+	"context"
+
+	usersv1 "example.com/users/gen/users/v1"
+)
+
+type Server struct {
+	usersv1.UnimplementedUserServiceServer
+}
+
+func (s *Server) GetUser(ctx context.Context, req *usersv1.GetUserRequest) (*usersv1.User, error) {
+	return &usersv1.User{Id: req.Id}, nil
+}
+`,
+		"cmd/client/main.go": `package main
+
+import (
+	"context"
+
+	usersv1 "example.com/users/gen/users/v1"
+)
+
+func main() {
+	client := usersv1.NewUserServiceClient()
+	client.GetUser(context.Background(), &usersv1.GetUserRequest{Id: "1"})
+}
+`,
+	})
+
+	g, _, err := RunPipeline(context.Background(), dir, nil, false, nil, false, 0)
+	require.NoError(t, err)
+
+	protoFile := filepath.Join("proto", "users", "v1", "users.proto")
+	pbFile := filepath.Join("gen", "users", "v1", "users.pb.go")
+	grpcFile := filepath.Join("gen", "users", "v1", "users_grpc.pb.go")
+	serverFile := filepath.Join("server", "server.go")
+	rpcID := graph.GenerateID(graph.NodeMethod, protoFile, "UserService.GetUser")
+	require.NotNil(t, g.GetNode(rpcID))
+
+	implements := func(sourceID string) map[string]*graph.GraphRelationship {
+		targets := make(map[string]*graph.GraphRelationship)
+		for _, rel := range g.GetOutgoing(sourceID, graph.RelImplements) {
+			targets[rel.Target] = rel
+		}
+		return targets
+	}
+
+	t.Run("LinksGeneratedTypes", func(t *testing.T) {
+		assert.Contains(t, implements(graph.GenerateID(graph.NodeClass, pbFile, "User")),
+			graph.GenerateID(graph.NodeClass, protoFile, "User"))
+		assert.Contains(t, implements(graph.GenerateID(graph.NodeClass, pbFile, "User_Address")),
+			graph.GenerateID(graph.NodeClass, protoFile, "User.Address"), "nested messages")
+		assert.Contains(t, implements(graph.GenerateID(graph.NodeTypeAlias, pbFile, "Role")),
+			graph.GenerateID(graph.NodeEnum, protoFile, "Role"))
+		assert.Contains(t, implements(graph.GenerateID(graph.NodeInterface, grpcFile, "UserServiceServer")),
+			graph.GenerateID(graph.NodeInterface, protoFile, "UserService"))
+	})
+
+	t.Run("LinksGeneratedServerAndClient", func(t *testing.T) {
+		server := implements(graph.GenerateID(graph.NodeMethod, grpcFile, "UserServiceServer.GetUser"))
+		require.Contains(t, server, rpcID)
+		assert.Equal(t, true, server[rpcID].Properties["rpc"])
+		assert.Equal(t, true, server[rpcID].Properties["generated"])
+
+		for _, client := range []string{"UserServiceClient.GetUser", "userServiceClient.GetUser"} {
+			assert.Contains(t, callTargets(g, graph.GenerateID(graph.NodeMethod, grpcFile, client)), rpcID,
+				"%s invokes the rpc", client)
+		}
+	})
+
+	t.Run("LinksHandWrittenServer", func(t *testing.T) {
+		handler := implements(graph.GenerateID(graph.NodeMethod, serverFile, "Server.GetUser"))
+		require.Contains(t, handler, rpcID)
+		assert.Equal(t, true, handler[rpcID].Properties["rpc"])
+		assert.Equal(t, dispatchConfidence, handler[rpcID].Properties["confidence"])
+		assert.Contains(t, implements(graph.GenerateID(graph.NodeClass, serverFile, "Server")),
+			graph.GenerateID(graph.NodeInterface, protoFile, "UserService"))
+
+		assert.NotContains(t, implements(graph.GenerateID(graph.NodeMethod, grpcFile, "UnimplementedUserServiceServer.GetUser")),
+			rpcID, "generated stubs are not servers")
+	})
+
+	t.Run("ImpactReachesHandlersAndClients", func(t *testing.T) {
+		store := storage.NewBadgerBackend()
+		require.NoError(t, store.Initialize(t.TempDir(), false))
+		defer store.Close()
+		require.NoError(t, store.BulkLoad(t.Context(), g))
+
+		nodes, err := store.Traverse(t.Context(), rpcID, 2, "callers")
+		require.NoError(t, err)
+		byDepth, viaDispatch := storage.GroupByTraversal(nodes)
+
+		dispatched := make(map[string]bool)
+		for _, node := range viaDispatch {
+			dispatched[node.ID] = true
+		}
+		assert.True(t, dispatched[graph.GenerateID(graph.NodeMethod, serverFile, "Server.GetUser")], "server handler")
+
+		callers := make(map[string]int)
+		for depth, nodes := range byDepth {
+			for _, node := range nodes {
+				callers[node.ID] = depth
+			}
+		}
+		assert.Equal(t, 1, callers[graph.GenerateID(graph.NodeMethod, grpcFile, "UserServiceClient.GetUser")])
+		assert.Equal(t, 2, callers[graph.GenerateID(graph.NodeFunction, filepath.Join("cmd", "client", "main.go"), "main")],
+			"client call site")
+	})
+}
+
+func TestGoCamelCase(t *testing.T) {
+	t.Parallel()
+
+	for name, want := range map[string]string{
+		"GetUser":         "GetUser",
+		"get_user":        "GetUser",
+		"Order.Item":      "Order_Item",
+		"Order.line_item": "OrderLineItem",
+		"_private":        "XPrivate",
+		"HTTPRule":        "HTTPRule",
+		"v2_api":          "V2Api",
+	} {
+		assert.Equal(t, want, goCamelCase(name), name)
+	}
+}
@@ -0,0 +1,190 @@
+package ingestion
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// importTarget is a repository path an import statement refers to.
+type importTarget struct {
+	// path is the repository-relative path of the file or directory
+	path string
+
+	// isDir is true when the import refers to a whole directory (a Go
+	// package) rather than a single file
+	isDir bool
+}
+
+// importResolver maps import statements to the repository files and
+// directories they refer to. Each language has its own resolver, which is
+// created the first time a file of that language is resolved.
+type importResolver struct {
+	repoPath string
+
+	// files is the set of repository-relative paths of all indexed files
+	files map[string]bool
+
+	goResolver     *goImportResolver
+	pythonResolver *pythonImportResolver
+	tsResolver     *tsImportResolver
+	rustResolver   *rustImportResolver
+	jvmResolver    *jvmImportResolver
+	csharpResolver *csharpImportResolver
+	cResolver      *cImportResolver
+	rubyResolver   *rubyImportResolver
+	phpResolver    *phpImportResolver
+}
+
+// newImportResolver creates a resolver for the files present in g.
+func newImportResolver(repoPath string, g *graph.KnowledgeGraph) *importResolver {
+	files := make(map[string]bool)
+	for _, node := range g.GetNodesByLabel(graph.NodeFile) {
+		files[node.FilePath] = true
+	}
+	return &importResolver{repoPath: repoPath, files: files}
+}
+
+// resolve returns the targets of an import statement in sourceFile. Imports
+// of code outside the repository (standard library, third-party packages)
+// have no targets.
+func (r *importResolver) resolve(sourceFile string, imp parsers.ImportStatement) []importTarget {
+	switch importLanguage(sourceFile) {
+	case "go":
+		if imp.IsRelative {
+			// Headers included by a cgo preamble
+			return r.c().resolve(sourceFile, imp)
+		}
+		return r.golang().resolve(imp)
+	case "python":
+		return r.python().resolve(sourceFile, imp)
+	case "typescript":
+		return r.typescript().resolve(sourceFile, imp)
+	case "rust":
+		return r.rust().resolve(sourceFile, imp)
+	case "jvm":
+		return r.jvm().resolve(imp)
+	case "csharp":
+		return r.csharp().resolve(sourceFile, imp)
+	case "c":
+		return r.c().resolve(sourceFile, imp)
+	case "ruby":
+		return r.ruby().resolve(sourceFile, imp)
+	case "php":
+		return r.php().resolve(sourceFile, imp)
+	case "proto":
+		// Imports are relative to the include paths given to protoc
+		return r.c().resolve(sourceFile, imp)
+	}
+	return nil
+}
+
+// importLanguage returns the resolver language of a file: "go", "python",
+// "typescript" (which covers JavaScript), "rust", "jvm" (Java and Kotlin),
+// "csharp", "c" (which covers C++), "ruby", "php", "proto" or "".
+func importLanguage(filePath string) string {
+	switch strings.ToLower(filepath.Ext(filePath)) {
+	case ".go":
+		return "go"
+	case ".py":
+		return "python"
+	case ".ts", ".tsx", ".js", ".jsx", ".mjs", ".cjs":
+		return "typescript"
+	case ".rs":
+		return "rust"
+	case ".java", ".kt", ".kts":
+		return "jvm"
+	case ".cs":
+		return "csharp"
+	case ".c", ".h", ".cc", ".cpp", ".cxx", ".c++", ".hpp", ".hh", ".hxx":
+		return "c"
+	case ".rb", ".rake":
+		return "ruby"
+	case ".php":
+		return "php"
+	case ".proto":
+		return "proto"
+	}
+	return ""
+}
+
+// golang returns the Go resolver, creating it on first use.
+func (r *importResolver) golang() *goImportResolver {
+	if r.goResolver == nil {
+		r.goResolver = newGoImportResolver(r.repoPath, r.files)
+	}
+	return r.goResolver
+}
+
+// python returns the Python resolver, creating it on first use.
+func (r *importResolver) python() *pythonImportResolver {
+	if r.pythonResolver == nil {
+		r.pythonResolver = newPythonImportResolver(r.files)
+	}
+	return r.pythonResolver
+}
+
+// typescript returns the TypeScript/JavaScript resolver, creating it on
+// first use.
+func (r *importResolver) typescript() *tsImportResolver {
+	if r.tsResolver == nil {
+		r.tsResolver = newTSImportResolver(r.repoPath, r.files)
+	}
+	return r.tsResolver
+}
+
+// rust returns the Rust resolver, creating it on first use.
+func (r *importResolver) rust() *rustImportResolver {
+	if r.rustResolver == nil {
+		r.rustResolver = newRustImportResolver(r.repoPath, r.files)
+	}
+	return r.rustResolver
+}
+
+// jvm returns the Java/Kotlin resolver, creating it on first use.
+func (r *importResolver) jvm() *jvmImportResolver {
+	if r.jvmResolver == nil {
+		r.jvmResolver = newJVMImportResolver(r.repoPath, r.files)
+	}
+	return r.jvmResolver
+}
+
+// csharp returns the C# resolver, creating it on first use.
+func (r *importResolver) csharp() *csharpImportResolver {
+	if r.csharpResolver == nil {
+		r.csharpResolver = newCSharpImportResolver(r.repoPath, r.files)
+	}
+	return r.csharpResolver
+}
+
+// c returns the C/C++ resolver, creating it on first use.
+func (r *importResolver) c() *cImportResolver {
+	if r.cResolver == nil {
+		r.cResolver = newCImportResolver(r.files)
+	}
+	return r.cResolver
+}
+
+// ruby returns the Ruby resolver, creating it on first use.
+func (r *importResolver) ruby() *rubyImportResolver {
+	if r.rubyResolver == nil {
+		r.rubyResolver = newRubyImportResolver(r.files)
+	}
+	return r.rubyResolver
+}
+
+// php returns the PHP resolver, creating it on first use.
+func (r *importResolver) php() *phpImportResolver {
+	if r.phpResolver == nil {
+		r.phpResolver = newPHPImportResolver(r.repoPath, r.files)
+	}
+	return r.phpResolver
+}
+
+// insideRepo reports whether a cleaned repository-relative path stays within
+// the repository.
+func insideRepo(relPath string) bool {
+	return relPath != ".." && !strings.HasPrefix(relPath, ".."+string(filepath.Separator)) && !filepath.IsAbs(relPath)
+}
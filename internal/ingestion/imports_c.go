@@ -0,0 +1,87 @@
+package ingestion
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// cImportResolver maps C and C++ #include directives, and the includes of
+// cgo preambles, to header files. Protocol Buffers imports are resolved the
+// same way, as protoc looks them up on its include paths.
+//
+// Without the build's include paths, a quoted include is first looked up
+// relative to the including file, as the preprocessor does. Otherwise, and
+// for <bracketed> includes, the included path is matched against the ends
+// of the repository's paths, as if each directory were on the include path;
+// of several matches the one closest to the including file is used.
+// Includes without a match are system or third-party headers.
+type cImportResolver struct {
+	files map[string]bool
+
+	// byBase maps file names to the repository paths ending in them
+	byBase map[string][]string
+}
+
+// newCImportResolver indexes the repository's files by name.
+func newCImportResolver(files map[string]bool) *cImportResolver {
+	r := &cImportResolver{files: files, byBase: make(map[string][]string)}
+	for file := range files {
+		base := filepath.Base(file)
+		r.byBase[base] = append(r.byBase[base], file)
+	}
+	for _, paths := range r.byBase {
+		sort.Strings(paths)
+	}
+	return r
+}
+
+// resolve returns the target of an include in sourceFile: the included file.
+func (r *cImportResolver) resolve(sourceFile string, imp parsers.ImportStatement) []importTarget {
+	included := filepath.Clean(filepath.FromSlash(imp.ModulePath))
+	if imp.IsRelative {
+		candidate := filepath.Join(filepath.Dir(sourceFile), included)
+		if insideRepo(candidate) && r.files[candidate] {
+			return []importTarget{{path: candidate}}
+		}
+	}
+	if filepath.IsAbs(included) || !insideRepo(included) {
+		return nil
+	}
+
+	best := closestSuffixMatch(r.byBase[filepath.Base(included)], sourceFile, included)
+	if best == "" || best == sourceFile {
+		return nil
+	}
+	return []importTarget{{path: best}}
+}
+
+// closestSuffixMatch returns the path among candidates that is, or ends in,
+// the relative path suffix and shares the most leading directories with
+// sourceFile, or "" if none matches.
+func closestSuffixMatch(candidates []string, sourceFile, suffix string) string {
+	best, bestShared := "", -1
+	for _, file := range candidates {
+		if file != suffix && !strings.HasSuffix(file, string(filepath.Separator)+suffix) {
+			continue
+		}
+		if shared := sharedDirs(sourceFile, file); shared > bestShared {
+			best, bestShared = file, shared
+		}
+	}
+	return best
+}
+
+// sharedDirs returns the number of leading directories two repository
+// paths have in common.
+func sharedDirs(a, b string) int {
+	dirsA := strings.Split(filepath.Dir(a), string(filepath.Separator))
+	dirsB := strings.Split(filepath.Dir(b), string(filepath.Separator))
+	shared := 0
+	for shared < len(dirsA) && shared < len(dirsB) && dirsA[shared] == dirsB[shared] && dirsA[shared] != "." {
+		shared++
+	}
+	return shared
+}
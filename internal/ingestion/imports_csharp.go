@@ -0,0 +1,259 @@
+package ingestion
+
+import (
+	"encoding/xml"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// csNamespaceDeclaration matches the namespace declarations of a C# file.
+var csNamespaceDeclaration = regexp.MustCompile(`(?m)^\s*namespace\s+([A-Za-z_]\w*(?:\s*\.\s*[A-Za-z_]\w*)*)`)
+
+// csProject is an MSBuild project (.csproj) whose sources live in the
+// repository.
+type csProject struct {
+	// name is the assembly name: AssemblyName, or the project file name
+	name string
+
+	// dir is the repository-relative directory of the project file
+	dir string
+
+	// refs holds the directories of the projects it references
+	refs []string
+}
+
+// csprojManifest holds the parts of a .csproj file the resolver reads.
+type csprojManifest struct {
+	PropertyGroups []struct {
+		AssemblyName string `xml:"AssemblyName"`
+	} `xml:"PropertyGroup"`
+	ItemGroups []struct {
+		ProjectReferences []struct {
+			Include string `xml:"Include,attr"`
+		} `xml:"ProjectReference"`
+	} `xml:"ItemGroup"`
+}
+
+// csharpImportResolver maps C# using directives to the projects declaring
+// the imported namespaces.
+//
+// Each file belongs to the project of the nearest .csproj above it. A
+// namespace may be declared by several projects; a using directive refers
+// to the ones the project of the importing file can see: the projects it
+// references, directly or through other projects. Files outside any
+// project see every project, and their namespaces are declared by their
+// directories.
+type csharpImportResolver struct {
+	repoPath string
+	files    map[string]bool
+
+	// projects maps directories to the project whose file is in them; a
+	// nil entry marks a directory without one
+	projects map[string]*csProject
+
+	// namespaces maps namespaces to the project directories (or, outside
+	// projects, file directories) declaring them
+	namespaces map[string][]string
+}
+
+// newCSharpImportResolver reads the projects of the C# files and the
+// namespaces the files declare.
+func newCSharpImportResolver(repoPath string, files map[string]bool) *csharpImportResolver {
+	r := &csharpImportResolver{
+		repoPath: repoPath,
+		files:    files,
+		projects: make(map[string]*csProject),
+	}
+
+	declared := make(map[string]map[string]bool)
+	for file := range files {
+		if importLanguage(file) != "csharp" {
+			continue
+		}
+		data, err := os.ReadFile(filepath.Join(repoPath, file))
+		if err != nil {
+			continue
+		}
+		dir := filepath.Dir(file)
+		if project := r.projectFor(file); project != nil {
+			dir = project.dir
+		}
+		for _, match := range csNamespaceDeclaration.FindAllSubmatch(data, -1) {
+			namespace := strings.Join(strings.Fields(strings.ReplaceAll(string(match[1]), ".", " ")), ".")
+			if declared[namespace] == nil {
+				declared[namespace] = make(map[string]bool)
+			}
+			declared[namespace][dir] = true
+		}
+	}
+
+	r.namespaces = make(map[string][]string, len(declared))
+	for namespace, dirs := range declared {
+		for dir := range dirs {
+			r.namespaces[namespace] = append(r.namespaces[namespace], dir)
+		}
+		sort.Strings(r.namespaces[namespace])
+	}
+	return r
+}
+
+// projectFor returns the project a file belongs to, or nil.
+func (r *csharpImportResolver) projectFor(file string) *csProject {
+	for dir := filepath.Dir(file); ; dir = filepath.Dir(dir) {
+		if project := r.loadProject(dir); project != nil {
+			return project
+		}
+		if dir == "." || dir == string(filepath.Separator) {
+			return nil
+		}
+	}
+}
+
+// loadProject reads the project whose file is in dir, or returns nil. When
+// a directory holds several project files, the first by name is used.
+func (r *csharpImportResolver) loadProject(dir string) *csProject {
+	if project, ok := r.projects[dir]; ok {
+		return project
+	}
+	r.projects[dir] = nil
+
+	matches, _ := filepath.Glob(filepath.Join(r.repoPath, dir, "*.csproj"))
+	if len(matches) == 0 {
+		return nil
+	}
+	sort.Strings(matches)
+	data, err := os.ReadFile(matches[0])
+	if err != nil {
+		return nil
+	}
+	var manifest csprojManifest
+	if err := xml.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+
+	project := &csProject{
+		name: strings.TrimSuffix(filepath.Base(matches[0]), ".csproj"),
+		dir:  dir,
+	}
+	for _, group := range manifest.PropertyGroups {
+		if group.AssemblyName != "" && !strings.Contains(group.AssemblyName, "$(") {
+			project.name = group.AssemblyName
+		}
+	}
+	for _, group := range manifest.ItemGroups {
+		for _, ref := range group.ProjectReferences {
+			// MSBuild paths use backslashes on every platform
+			include := strings.ReplaceAll(strings.TrimSpace(ref.Include), `\`, "/")
+			if include == "" || strings.Contains(include, "$(") {
+				continue
+			}
+			refDir := filepath.Dir(filepath.Join(dir, filepath.FromSlash(include)))
+			if insideRepo(refDir) {
+				project.refs = append(project.refs, refDir)
+			}
+		}
+	}
+	r.projects[dir] = project
+	return project
+}
+
+// visible returns the directories of the projects a project references,
+// directly or through other projects.
+func (r *csharpImportResolver) visible(project *csProject) map[string]bool {
+	seen := make(map[string]bool)
+	queue := append([]string(nil), project.refs...)
+	for len(queue) > 0 {
+		dir := queue[0]
+		queue = queue[1:]
+		if seen[dir] {
+			continue
+		}
+		seen[dir] = true
+		if ref := r.loadProject(dir); ref != nil {
+			queue = append(queue, ref.refs...)
+		}
+	}
+	return seen
+}
+
+// resolve returns the targets of a using directive in sourceFile: the
+// projects declaring the namespace. Static and alias directives name a
+// type, or a namespace, as their symbol.
+func (r *csharpImportResolver) resolve(sourceFile string, imp parsers.ImportStatement) []importTarget {
+	var namespaces []string
+	for _, symbol := range imp.Symbols {
+		namespaces = append(namespaces, imp.ModulePath+"."+symbol)
+	}
+	namespaces = append(namespaces, imp.ModulePath)
+
+	project := r.projectFor(sourceFile)
+	var visible map[string]bool
+	if project != nil {
+		visible = r.visible(project)
+	}
+
+	for _, namespace := range namespaces {
+		dirs, ok := r.namespaces[namespace]
+		if !ok {
+			continue
+		}
+		var targets []importTarget
+		for _, dir := range dirs {
+			if project != nil && (dir == project.dir || !visible[dir]) {
+				continue
+			}
+			targets = append(targets, importTarget{path: dir, isDir: true})
+		}
+		return targets
+	}
+	return nil
+}
+
+// linkProjects creates IMPORTS relationships between the Package nodes of
+// the projects and the projects they reference, whether or not their files
+// import each other. They are marked "project_reference".
+func (r *csharpImportResolver) linkProjects(g *graph.KnowledgeGraph) {
+	for dir, project := range r.projects {
+		if project == nil {
+			continue
+		}
+		sourceID := graph.GenerateID(graph.NodePackage, dir, "")
+		if g.GetNode(sourceID) == nil {
+			continue
+		}
+
+		existing := make(map[string]*graph.GraphRelationship)
+		for _, rel := range g.GetOutgoing(sourceID, graph.RelImports) {
+			existing[rel.Target] = rel
+		}
+		for _, ref := range project.refs {
+			targetID := graph.GenerateID(graph.NodePackage, ref, "")
+			if targetID == sourceID || g.GetNode(targetID) == nil {
+				continue
+			}
+			if rel, ok := existing[targetID]; ok {
+				if rel.Properties == nil {
+					rel.Properties = make(map[string]any)
+				}
+				rel.Properties["project_reference"] = true
+				continue
+			}
+			g.AddRelationship(&graph.GraphRelationship{
+				ID:     relationshipID(graph.RelImports, sourceID, targetID),
+				Type:   graph.RelImports,
+				Source: sourceID,
+				Target: targetID,
+				Properties: map[string]any{
+					"file_imports":      0,
+					"project_reference": true,
+				},
+			})
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package ingestion
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"golang.org/x/mod/modfile"
+
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// goModule is a Go module whose sources live in the repository.
+type goModule struct {
+	// path is the module path (or the path a replace directive maps)
+	path string
+
+	// dir is the repository-relative module root
+	dir string
+
+	// replace is true for modules only known from a replace directive
+	replace bool
+}
+
+// goImportResolver maps Go import paths to package directories using the
+// go.mod files of the repository: the main module, the modules listed in
+// go.work, and local replace directives.
+type goImportResolver struct {
+	// modules is sorted by descending path length, so that the first
+	// matching module is the most specific one
+	modules []goModule
+
+	// pkgDirs is the set of directories containing Go files
+	pkgDirs map[string]bool
+}
+
+// newGoImportResolver reads the module layout of the repository.
+func newGoImportResolver(repoPath string, files map[string]bool) *goImportResolver {
+	r := &goImportResolver{pkgDirs: make(map[string]bool)}
+	for file := range files {
+		if filepath.Ext(file) == ".go" {
+			r.pkgDirs[filepath.Dir(file)] = true
+		}
+	}
+
+	seen := make(map[string]bool)
+	addModule := func(dir string) {
+		if seen[dir] {
+			return
+		}
+		seen[dir] = true
+		r.addModule(repoPath, dir)
+	}
+
+	if data, err := os.ReadFile(filepath.Join(repoPath, "go.work")); err == nil {
+		if work, err := modfile.ParseWork("go.work", data, nil); err == nil {
+			for _, use := range work.Use {
+				addModule(filepath.Clean(filepath.FromSlash(use.Path)))
+			}
+			for _, rep := range work.Replace {
+				r.addReplace(".", rep)
+			}
+		}
+	}
+	addModule(".")
+
+	sort.SliceStable(r.modules, func(i, j int) bool {
+		return len(r.modules[i].path) > len(r.modules[j].path)
+	})
+	return r
+}
+
+// addModule registers the module rooted at dir and its local replacements.
+func (r *goImportResolver) addModule(repoPath, dir string) {
+	if !insideRepo(dir) {
+		return
+	}
+	data, err := os.ReadFile(filepath.Join(repoPath, dir, "go.mod"))
+	if err != nil {
+		return
+	}
+	mod, err := modfile.Parse(filepath.Join(dir, "go.mod"), data, nil)
+	if err != nil || mod.Module == nil {
+		return
+	}
+
+	r.modules = append(r.modules, goModule{path: mod.Module.Mod.Path, dir: dir})
+	for _, rep := range mod.Replace {
+		r.addReplace(dir, rep)
+	}
+}
+
+// addReplace registers a replace directive that points to a directory in
+// the repository. Replacements by another module version are ignored.
+func (r *goImportResolver) addReplace(dir string, rep *modfile.Replace) {
+	if rep.New.Version != "" || !modfile.IsDirectoryPath(rep.New.Path) {
+		return
+	}
+	target := filepath.Clean(filepath.Join(dir, filepath.FromSlash(rep.New.Path)))
+	if !insideRepo(target) {
+		return
+	}
+	r.modules = append(r.modules, goModule{path: rep.Old.Path, dir: target, replace: true})
+}
+
+// resolve maps an import path to the directory of the imported package.
+func (r *goImportResolver) resolve(imp parsers.ImportStatement) []importTarget {
+	for _, mod := range r.modules {
+		rest, ok := strings.CutPrefix(imp.ModulePath, mod.path)
+		if !ok || (rest != "" && !strings.HasPrefix(rest, "/")) {
+			continue
+		}
+		dir := filepath.Join(mod.dir, filepath.FromSlash(strings.TrimPrefix(rest, "/")))
+		if !r.pkgDirs[dir] {
+			return nil
+		}
+		return []importTarget{{path: dir, isDir: true}}
+	}
+	return nil
+}
+
+// moduleFor returns the innermost module containing dir, or nil.
+func (r *goImportResolver) moduleFor(dir string) *goModule {
+	var best *goModule
+	for i := range r.modules {
+		mod := &r.modules[i]
+		rel, err := filepath.Rel(mod.dir, dir)
+		if err != nil || !insideRepo(rel) {
+			continue
+		}
+		if best == nil || dirDepth(mod.dir) > dirDepth(best.dir) || dirDepth(mod.dir) == dirDepth(best.dir) && best.replace {
+			best = mod
+		}
+	}
+	return best
+}
+
+// importPath returns the import path of the package in dir, or "" if dir is
+// not part of a module.
+func (r *goImportResolver) importPath(dir string) string {
+	mod := r.moduleFor(dir)
+	if mod == nil {
+		return ""
+	}
+	rel, _ := filepath.Rel(mod.dir, dir)
+	if rel == "." {
+		return mod.path
+	}
+	return mod.path + "/" + filepath.ToSlash(rel)
+}
+
+// dirDepth returns the number of path elements of a repository-relative
+// directory ("." has none).
+func dirDepth(dir string) int {
+	if dir == "." {
+		return 0
+	}
+	return strings.Count(dir, string(filepath.Separator)) + 1
+}
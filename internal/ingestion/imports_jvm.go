@@ -0,0 +1,172 @@
+package ingestion
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// jvmPackageClause matches the package declaration of a Java or Kotlin file.
+var jvmPackageClause = regexp.MustCompile(`(?m)^\s*package\s+([A-Za-z_$][\w$]*(?:\s*\.\s*[A-Za-z_$][\w$]*)*)`)
+
+// jvmExtensions are the extensions of the source files a class may be
+// declared in, in lookup order.
+var jvmExtensions = []string{".java", ".kt"}
+
+// jvmImportResolver maps Java and Kotlin imports to source files.
+//
+// Each file declares its package. Source roots are the directories the
+// package directories hang off (src/main/java for com/example/App.java in
+// package com.example); a class a.b.C is looked up as a/b/C.java or
+// a/b/C.kt in every root. Kotlin does not require a file per class, so
+// imports of names without a file of their own (top-level functions,
+// classes in a shared file) refer to the directories of their package.
+type jvmImportResolver struct {
+	repoPath string
+	files    map[string]bool
+
+	// packages maps the files to their declared package
+	packages map[string]string
+
+	// dirs maps package names to the directories holding their files
+	dirs map[string][]string
+
+	roots []string
+}
+
+// newJVMImportResolver reads the package declarations of the Java and Kotlin
+// files and derives the source roots from them.
+func newJVMImportResolver(repoPath string, files map[string]bool) *jvmImportResolver {
+	r := &jvmImportResolver{
+		repoPath: repoPath,
+		files:    files,
+		packages: make(map[string]string),
+		dirs:     make(map[string][]string),
+	}
+
+	rootSet := make(map[string]bool)
+	dirSet := make(map[string]map[string]bool)
+	for file := range files {
+		if importLanguage(file) != "jvm" {
+			continue
+		}
+		pkg := r.readPackage(file)
+		if pkg == "" {
+			continue
+		}
+		r.packages[file] = pkg
+
+		dir := filepath.Dir(file)
+		if dirSet[pkg] == nil {
+			dirSet[pkg] = make(map[string]bool)
+		}
+		dirSet[pkg][dir] = true
+
+		pkgPath := filepath.FromSlash(strings.ReplaceAll(pkg, ".", "/"))
+		switch {
+		case dir == pkgPath:
+			rootSet["."] = true
+		case strings.HasSuffix(dir, string(filepath.Separator)+pkgPath):
+			rootSet[strings.TrimSuffix(dir, string(filepath.Separator)+pkgPath)] = true
+		}
+	}
+
+	for pkg, dirs := range dirSet {
+		for dir := range dirs {
+			r.dirs[pkg] = append(r.dirs[pkg], dir)
+		}
+		sort.Strings(r.dirs[pkg])
+	}
+	for root := range rootSet {
+		r.roots = append(r.roots, root)
+	}
+	sort.Strings(r.roots)
+	return r
+}
+
+// readPackage returns the package declared by a file, or "" for the
+// default package.
+func (r *jvmImportResolver) readPackage(file string) string {
+	data, err := os.ReadFile(filepath.Join(r.repoPath, file))
+	if err != nil {
+		return ""
+	}
+	match := jvmPackageClause.FindSubmatch(data)
+	if match == nil {
+		return ""
+	}
+	return strings.Join(strings.Fields(strings.ReplaceAll(string(match[1]), ".", " ")), ".")
+}
+
+// packageOf returns the declared package of a file and the directory it
+// is declared in, or "" for files in the default package.
+func (r *jvmImportResolver) packageOf(file string) (string, string) {
+	pkg, ok := r.packages[file]
+	if !ok {
+		return "", ""
+	}
+	return pkg, filepath.Dir(file)
+}
+
+// resolve returns the targets of an import. Imports name a class
+// (a.b.C), a member of a class (static imports of a.b.C.run), a Kotlin
+// top-level declaration (a.b.run) or all of a package (a.b.*).
+func (r *jvmImportResolver) resolve(imp parsers.ImportStatement) []importTarget {
+	var targets []importTarget
+	for _, symbol := range imp.Symbols {
+		if symbol != "*" {
+			if file := r.classFile(imp.ModulePath + "." + symbol); file != "" {
+				targets = append(targets, importTarget{path: file})
+				continue
+			}
+		}
+		// Members of a class
+		if file := r.classFile(imp.ModulePath); file != "" {
+			targets = append(targets, importTarget{path: file})
+			continue
+		}
+		for _, dir := range r.dirs[imp.ModulePath] {
+			targets = append(targets, importTarget{path: dir, isDir: true})
+		}
+	}
+	return targets
+}
+
+// classFile returns the file declaring the class with a qualified name, or
+// "". Nested classes (a.b.Outer.Inner) are declared in the file of their
+// outermost class.
+func (r *jvmImportResolver) classFile(name string) string {
+	for {
+		dot := strings.LastIndexByte(name, '.')
+		if dot < 0 {
+			return ""
+		}
+		pkg, class := name[:dot], name[dot+1:]
+		pkgPath := filepath.FromSlash(strings.ReplaceAll(pkg, ".", "/"))
+
+		var candidates []string
+		for _, root := range r.roots {
+			candidates = append(candidates, filepath.Join(root, pkgPath))
+		}
+		candidates = append(candidates, r.dirs[pkg]...)
+		for _, dir := range candidates {
+			for _, ext := range jvmExtensions {
+				file := filepath.Join(dir, class+ext)
+				if r.files[file] && r.packages[file] == pkg {
+					return file
+				}
+			}
+		}
+
+		// Only capitalized segments name enclosing classes
+		outer := pkg[strings.LastIndexByte(pkg, '.')+1:]
+		if outer == "" || outer[0] < 'A' || outer[0] > 'Z' {
+			return ""
+		}
+		name = pkg
+	}
+}
@@ -0,0 +1,181 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// composerManifest holds the parts of composer.json the resolver reads.
+type composerManifest struct {
+	Autoload    composerAutoload `json:"autoload"`
+	AutoloadDev composerAutoload `json:"autoload-dev"`
+}
+
+// composerAutoload maps namespace prefixes to one directory or a list of
+// directories.
+type composerAutoload struct {
+	PSR4 map[string]any `json:"psr-4"`
+	PSR0 map[string]any `json:"psr-0"`
+}
+
+// phpNamespaceRoot is a namespace prefix autoloaded from a directory.
+type phpNamespaceRoot struct {
+	// prefix is the namespace prefix with its trailing separator (App\),
+	// or "" for a fallback directory
+	prefix string
+
+	// dir is the repository-relative directory the prefix maps to
+	dir string
+
+	// psr0 is true when the whole class name, prefix included, is a path
+	// below dir (PSR-0) rather than the rest after the prefix (PSR-4)
+	psr0 bool
+}
+
+// phpImportResolver maps PHP use declarations, and the files included
+// with require and include, to source files.
+//
+// Classes are autoloaded following the PSR-4 and PSR-0 mappings of the
+// composer.json manifests above the PHP files: App\Models\User is
+// app/Models/User.php under "App\\": "app/". Classes outside every mapping
+// are matched against the ends of the repository's paths by their
+// namespace path, dropping leading namespaces until a file matches; the
+// innermost namespace is always kept. Included paths are relative to the
+// including file, or to the repository root.
+type phpImportResolver struct {
+	repoPath string
+	files    map[string]bool
+
+	// roots are the namespace roots of all manifests, longest prefix first
+	roots []phpNamespaceRoot
+
+	// byBase maps file names to the repository paths ending in them
+	byBase map[string][]string
+}
+
+// newPHPImportResolver reads the composer manifests above the PHP files
+// and indexes the files by name.
+func newPHPImportResolver(repoPath string, files map[string]bool) *phpImportResolver {
+	r := &phpImportResolver{repoPath: repoPath, files: files, byBase: make(map[string][]string)}
+	manifests := make(map[string]bool)
+	for file := range files {
+		if importLanguage(file) != "php" {
+			continue
+		}
+		base := filepath.Base(file)
+		r.byBase[base] = append(r.byBase[base], file)
+		for dir := filepath.Dir(file); !manifests[dir]; dir = filepath.Dir(dir) {
+			manifests[dir] = true
+			r.readManifest(dir)
+			if dir == "." {
+				break
+			}
+		}
+	}
+	for _, paths := range r.byBase {
+		sort.Strings(paths)
+	}
+	sort.Slice(r.roots, func(i, j int) bool {
+		a, b := r.roots[i], r.roots[j]
+		switch {
+		case len(a.prefix) != len(b.prefix):
+			return len(a.prefix) > len(b.prefix)
+		case a.dir != b.dir:
+			return a.dir < b.dir
+		}
+		return !a.psr0 && b.psr0
+	})
+	return r
+}
+
+// readManifest adds the namespace roots of dir/composer.json, if any.
+func (r *phpImportResolver) readManifest(dir string) {
+	data, err := os.ReadFile(filepath.Join(r.repoPath, dir, "composer.json"))
+	if err != nil {
+		return
+	}
+	var manifest composerManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		return
+	}
+	for _, autoload := range []composerAutoload{manifest.Autoload, manifest.AutoloadDev} {
+		r.addRoots(dir, autoload.PSR4, false)
+		r.addRoots(dir, autoload.PSR0, true)
+	}
+}
+
+// addRoots adds the namespace roots of an autoload mapping of the manifest
+// in dir.
+func (r *phpImportResolver) addRoots(dir string, mapping map[string]any, psr0 bool) {
+	for prefix, dirs := range mapping {
+		var paths []string
+		switch dirs := dirs.(type) {
+		case string:
+			paths = append(paths, dirs)
+		case []any:
+			for _, path := range dirs {
+				if path, ok := path.(string); ok {
+					paths = append(paths, path)
+				}
+			}
+		}
+		for _, path := range paths {
+			r.roots = append(r.roots, phpNamespaceRoot{
+				prefix: prefix,
+				dir:    filepath.Join(dir, filepath.FromSlash(path)),
+				psr0:   psr0,
+			})
+		}
+	}
+}
+
+// resolve returns the target of an import in sourceFile: the file
+// declaring the used class, or the included file.
+func (r *phpImportResolver) resolve(sourceFile string, imp parsers.ImportStatement) []importTarget {
+	if imp.IsRelative {
+		included := filepath.Clean(filepath.FromSlash(imp.ModulePath))
+		if filepath.IsAbs(included) {
+			return nil
+		}
+		for _, candidate := range []string{filepath.Join(filepath.Dir(sourceFile), included), included} {
+			if insideRepo(candidate) && r.files[candidate] {
+				return []importTarget{{path: candidate}}
+			}
+		}
+		return nil
+	}
+
+	class := strings.TrimPrefix(imp.ModulePath, "\\")
+	for _, root := range r.roots {
+		if !strings.HasPrefix(class, root.prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(class, root.prefix)
+		if root.psr0 {
+			rest = class
+		}
+		candidate := filepath.Join(root.dir, filepath.FromSlash(strings.ReplaceAll(rest, "\\", "/"))+".php")
+		if r.files[candidate] {
+			return []importTarget{{path: candidate}}
+		}
+	}
+
+	// Keep a namespace directory at least, so that a third-party class is
+	// not taken for any file of the same name
+	parts := strings.Split(class, "\\")
+	for i := 0; i < len(parts)-1; i++ {
+		suffix := filepath.Join(parts[i:]...) + ".php"
+		if best := closestSuffixMatch(r.byBase[filepath.Base(suffix)], sourceFile, suffix); best != "" {
+			if best == sourceFile {
+				return nil
+			}
+			return []importTarget{{path: best}}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,174 @@
+package ingestion
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// pythonImportResolver maps Python module names to files.
+//
+// Absolute imports are looked up in the source roots of the repository: the
+// directories that contain top-level packages (the parents of the outermost
+// directories with an __init__.py), the repository root and src/. The
+// directory of the importing file is tried last, as Python does for scripts.
+type pythonImportResolver struct {
+	files map[string]bool
+
+	// dirs is the set of directories containing Python files, which
+	// includes namespace packages without an __init__.py
+	dirs map[string]bool
+
+	roots []string
+}
+
+// newPythonImportResolver finds the source roots of the repository.
+func newPythonImportResolver(files map[string]bool) *pythonImportResolver {
+	r := &pythonImportResolver{files: files, dirs: make(map[string]bool)}
+
+	rootSet := map[string]bool{".": true}
+	for file := range files {
+		if filepath.Ext(file) != ".py" {
+			continue
+		}
+		for dir := filepath.Dir(file); dir != "."; dir = filepath.Dir(dir) {
+			r.dirs[dir] = true
+		}
+
+		// Walk up the package chain to the directory holding the top-level package
+		dir := filepath.Dir(file)
+		for dir != "." && files[filepath.Join(dir, "__init__.py")] {
+			dir = filepath.Dir(dir)
+		}
+		rootSet[dir] = true
+	}
+	if r.dirs["src"] {
+		rootSet["src"] = true
+	}
+
+	for root := range rootSet {
+		r.roots = append(r.roots, root)
+	}
+	// The repository root first, then the shallowest roots
+	sort.Slice(r.roots, func(i, j int) bool {
+		di, dj := strings.Count(r.roots[i], string(filepath.Separator)), strings.Count(r.roots[j], string(filepath.Separator))
+		if (r.roots[i] == ".") != (r.roots[j] == ".") {
+			return r.roots[i] == "."
+		}
+		if di != dj {
+			return di < dj
+		}
+		return r.roots[i] < r.roots[j]
+	})
+	return r
+}
+
+// resolve returns the files an import statement refers to. For
+// "from pkg import name", name is linked to its own module when it is a
+// submodule of pkg, and to pkg otherwise.
+func (r *pythonImportResolver) resolve(sourceFile string, imp parsers.ImportStatement) []importTarget {
+	module, pkgDir, ok := r.findModule(sourceFile, imp.ModulePath)
+	if !ok {
+		return nil
+	}
+
+	var targets []importTarget
+	seen := make(map[string]bool)
+	add := func(file string) {
+		if file != "" && file != sourceFile && !seen[file] {
+			seen[file] = true
+			targets = append(targets, importTarget{path: file})
+		}
+	}
+
+	for _, symbol := range imp.Symbols {
+		if pkgDir == "" || symbol == "*" {
+			continue
+		}
+		if sub, _ := r.lookup(filepath.Join(pkgDir, symbol)); sub != "" {
+			add(sub)
+		} else {
+			add(module)
+		}
+	}
+	if len(targets) == 0 {
+		add(module)
+	}
+	return targets
+}
+
+// findModule resolves a (possibly relative) dotted module name. It returns
+// the module's file ("" for a namespace package) and, for packages, the
+// package directory.
+func (r *pythonImportResolver) findModule(sourceFile, modulePath string) (file, pkgDir string, ok bool) {
+	name := strings.TrimLeft(modulePath, ".")
+	parts := strings.Split(name, ".")
+	if name == "" {
+		parts = nil
+	}
+
+	if dots := len(modulePath) - len(name); dots > 0 {
+		// Relative import: one dot is the current package, each further
+		// dot goes up one level
+		base := filepath.Dir(sourceFile)
+		for i := 1; i < dots; i++ {
+			base = filepath.Dir(base)
+		}
+		if !insideRepo(base) {
+			return "", "", false
+		}
+		return r.lookupPackage(filepath.Join(append([]string{base}, parts...)...))
+	}
+
+	candidates := append([]string{}, r.roots...)
+	candidates = append(candidates, filepath.Dir(sourceFile))
+	for _, root := range candidates {
+		if file, pkgDir, ok := r.lookupPackage(filepath.Join(append([]string{root}, parts...)...)); ok {
+			return file, pkgDir, true
+		}
+	}
+	return "", "", false
+}
+
+// lookupPackage is like lookup but also accepts namespace packages.
+func (r *pythonImportResolver) lookupPackage(path string) (file, pkgDir string, ok bool) {
+	file, pkgDir = r.lookup(path)
+	if file != "" {
+		return file, pkgDir, true
+	}
+	if r.dirs[path] || path == "." {
+		return "", path, true
+	}
+	return "", "", false
+}
+
+// lookup returns the file implementing the module at path (path.py or
+// path/__init__.py) and, for a regular package, its directory.
+func (r *pythonImportResolver) lookup(path string) (file, pkgDir string) {
+	if r.files[path+".py"] {
+		return path + ".py", ""
+	}
+	if init := filepath.Join(path, "__init__.py"); r.files[init] {
+		return init, path
+	}
+	return "", ""
+}
+
+// packageName returns the dotted name of the package in dir, or "" if dir
+// is not a regular package (it has no __init__.py).
+func (r *pythonImportResolver) packageName(dir string) string {
+	if dir == "." || !r.files[filepath.Join(dir, "__init__.py")] {
+		return ""
+	}
+	top := dir
+	for parent := filepath.Dir(top); parent != "." && r.files[filepath.Join(parent, "__init__.py")]; parent = filepath.Dir(top) {
+		top = parent
+	}
+	rel, err := filepath.Rel(filepath.Dir(top), dir)
+	if err != nil {
+		return ""
+	}
+	return strings.ReplaceAll(filepath.ToSlash(rel), "/", ".")
+}
@@ -0,0 +1,66 @@
+package ingestion
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// rubyImportResolver maps Ruby require, require_relative, load and autoload
+// calls to source files.
+//
+// require_relative paths are relative to the requiring file. Other paths
+// are looked up on the load path, which is not known without running
+// Bundler; instead the required path (with .rb appended) is matched
+// against the ends of the repository's paths, so that require "billing/
+// invoice" finds lib/billing/invoice.rb. Of several matches the one
+// closest to the requiring file is used, and paths without a match are
+// gems or the standard library.
+type rubyImportResolver struct {
+	files map[string]bool
+
+	// byBase maps file names to the repository paths ending in them
+	byBase map[string][]string
+}
+
+// newRubyImportResolver indexes the repository's Ruby files by name.
+func newRubyImportResolver(files map[string]bool) *rubyImportResolver {
+	r := &rubyImportResolver{files: files, byBase: make(map[string][]string)}
+	for file := range files {
+		if importLanguage(file) != "ruby" {
+			continue
+		}
+		base := filepath.Base(file)
+		r.byBase[base] = append(r.byBase[base], file)
+	}
+	for _, paths := range r.byBase {
+		sort.Strings(paths)
+	}
+	return r
+}
+
+// resolve returns the target of a require in sourceFile: the required file.
+func (r *rubyImportResolver) resolve(sourceFile string, imp parsers.ImportStatement) []importTarget {
+	required := filepath.Clean(filepath.FromSlash(imp.ModulePath))
+	if filepath.Ext(required) == "" {
+		required += ".rb"
+	}
+	if imp.IsRelative || strings.HasPrefix(imp.ModulePath, "./") || strings.HasPrefix(imp.ModulePath, "../") {
+		candidate := filepath.Join(filepath.Dir(sourceFile), required)
+		if insideRepo(candidate) && r.files[candidate] {
+			return []importTarget{{path: candidate}}
+		}
+		return nil
+	}
+	if filepath.IsAbs(required) || !insideRepo(required) {
+		return nil
+	}
+
+	best := closestSuffixMatch(r.byBase[filepath.Base(required)], sourceFile, required)
+	if best == "" || best == sourceFile {
+		return nil
+	}
+	return []importTarget{{path: best}}
+}
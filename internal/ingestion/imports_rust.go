@@ -0,0 +1,361 @@
+package ingestion
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/pelletier/go-toml/v2"
+
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// rustCrate is a Cargo package whose sources live in the repository.
+type rustCrate struct {
+	// name is the crate name as written in paths (hyphens become
+	// underscores)
+	name string
+
+	// dir is the repository-relative directory of Cargo.toml
+	dir string
+
+	// root is the crate root file: src/lib.rs, src/main.rs or [lib] path
+	root string
+
+	// deps maps the names of path dependencies, as written in paths, to
+	// the directories of their crates
+	deps map[string]string
+}
+
+// cargoManifest holds the parts of Cargo.toml the resolver reads.
+type cargoManifest struct {
+	Package *struct {
+		Name string `toml:"name"`
+	} `toml:"package"`
+	Lib *struct {
+		Name string `toml:"name"`
+		Path string `toml:"path"`
+	} `toml:"lib"`
+	Workspace *struct {
+		Members      []string       `toml:"members"`
+		Dependencies map[string]any `toml:"dependencies"`
+	} `toml:"workspace"`
+	Dependencies      map[string]any `toml:"dependencies"`
+	DevDependencies   map[string]any `toml:"dev-dependencies"`
+	BuildDependencies map[string]any `toml:"build-dependencies"`
+}
+
+// rustImportResolver maps Rust use paths to module files.
+//
+// Each file belongs to the crate of the nearest Cargo.toml above it. Paths
+// start at the crate root (crate::), the current module (self::, or a child
+// module), its parent (super::), or another crate of the repository: a
+// workspace member or path dependency, by name. The remaining segments
+// name modules (name.rs or name/mod.rs) as long as such files exist; the
+// rest are items of the last module.
+type rustImportResolver struct {
+	repoPath string
+	files    map[string]bool
+
+	// crates maps manifest directories to their crates; a nil entry marks
+	// a directory without a package manifest
+	crates map[string]*rustCrate
+
+	// byName maps crate names to the crates of the repository
+	byName map[string]*rustCrate
+
+	// workspaces maps directories to their parsed workspace manifests
+	workspaces map[string]*cargoManifest
+}
+
+// newRustImportResolver reads the Cargo manifests of the crates containing
+// Rust files and of their workspaces.
+func newRustImportResolver(repoPath string, files map[string]bool) *rustImportResolver {
+	r := &rustImportResolver{
+		repoPath:   repoPath,
+		files:      files,
+		crates:     make(map[string]*rustCrate),
+		byName:     make(map[string]*rustCrate),
+		workspaces: make(map[string]*cargoManifest),
+	}
+
+	// Workspace members without Rust files of their own are still crates
+	// other crates may depend on
+	if root := r.readManifest("."); root != nil && root.Workspace != nil {
+		for _, member := range root.Workspace.Members {
+			matches, _ := filepath.Glob(filepath.Join(repoPath, filepath.FromSlash(member)))
+			for _, match := range matches {
+				if rel, err := filepath.Rel(repoPath, match); err == nil {
+					r.loadCrate(rel)
+				}
+			}
+		}
+	}
+	for file := range files {
+		if filepath.Ext(file) == ".rs" {
+			r.crateFor(file)
+		}
+	}
+	return r
+}
+
+// readManifest parses dir/Cargo.toml, or returns nil.
+func (r *rustImportResolver) readManifest(dir string) *cargoManifest {
+	data, err := os.ReadFile(filepath.Join(r.repoPath, dir, "Cargo.toml"))
+	if err != nil {
+		return nil
+	}
+	var manifest cargoManifest
+	if err := toml.Unmarshal(data, &manifest); err != nil {
+		return nil
+	}
+	return &manifest
+}
+
+// loadCrate reads the crate whose manifest is in dir, or returns nil.
+func (r *rustImportResolver) loadCrate(dir string) *rustCrate {
+	if crate, ok := r.crates[dir]; ok {
+		return crate
+	}
+	r.crates[dir] = nil
+
+	manifest := r.readManifest(dir)
+	if manifest == nil {
+		return nil
+	}
+	if manifest.Workspace != nil {
+		r.workspaces[dir] = manifest
+	}
+	if manifest.Package == nil {
+		return nil
+	}
+
+	crate := &rustCrate{
+		name: strings.ReplaceAll(manifest.Package.Name, "-", "_"),
+		dir:  dir,
+		deps: make(map[string]string),
+	}
+	if manifest.Lib != nil && manifest.Lib.Name != "" {
+		crate.name = strings.ReplaceAll(manifest.Lib.Name, "-", "_")
+	}
+	switch {
+	case manifest.Lib != nil && manifest.Lib.Path != "":
+		crate.root = filepath.Join(dir, filepath.FromSlash(manifest.Lib.Path))
+	case r.files[filepath.Join(dir, "src", "lib.rs")]:
+		crate.root = filepath.Join(dir, "src", "lib.rs")
+	default:
+		crate.root = filepath.Join(dir, "src", "main.rs")
+	}
+
+	workspace := r.workspaceFor(dir)
+	for _, deps := range []map[string]any{manifest.Dependencies, manifest.DevDependencies, manifest.BuildDependencies} {
+		for name, spec := range deps {
+			if path := r.dependencyPath(dir, spec, workspace, name); path != "" {
+				crate.deps[strings.ReplaceAll(name, "-", "_")] = path
+			}
+		}
+	}
+
+	r.crates[dir] = crate
+	if _, ok := r.byName[crate.name]; !ok {
+		r.byName[crate.name] = crate
+	}
+	return crate
+}
+
+// workspaceFor returns the directory of the workspace dir belongs to: the
+// nearest ancestor whose Cargo.toml has a [workspace] table, or "".
+func (r *rustImportResolver) workspaceFor(dir string) string {
+	for d := dir; ; d = filepath.Dir(d) {
+		if _, ok := r.workspaces[d]; ok {
+			return d
+		}
+		if manifest := r.readManifest(d); manifest != nil && manifest.Workspace != nil {
+			r.workspaces[d] = manifest
+			return d
+		}
+		if d == "." {
+			return ""
+		}
+	}
+}
+
+// dependencyPath returns the repository-relative directory of the path
+// dependency spec declared in the manifest in dir, or "". Dependencies
+// inherited from the workspace (workspace = true) use the workspace's
+// declaration of name.
+func (r *rustImportResolver) dependencyPath(dir string, spec any, workspace, name string) string {
+	table, ok := spec.(map[string]any)
+	if !ok {
+		return ""
+	}
+	if inherited, _ := table["workspace"].(bool); inherited && workspace != "" {
+		if manifest := r.workspaces[workspace]; manifest != nil && manifest.Workspace != nil {
+			return r.dependencyPath(workspace, manifest.Workspace.Dependencies[name], "", name)
+		}
+		return ""
+	}
+	path, _ := table["path"].(string)
+	if path == "" {
+		return ""
+	}
+	target := filepath.Clean(filepath.Join(dir, filepath.FromSlash(path)))
+	if !insideRepo(target) {
+		return ""
+	}
+	return target
+}
+
+// crateFor returns the crate containing file, or nil.
+func (r *rustImportResolver) crateFor(file string) *rustCrate {
+	for dir := filepath.Dir(file); ; dir = filepath.Dir(dir) {
+		if crate := r.loadCrate(dir); crate != nil {
+			return crate
+		}
+		if dir == "." {
+			return nil
+		}
+	}
+}
+
+// resolve returns the module files a use path refers to. For
+// use a::b::{c, d}, c and d are linked to their own module files when they
+// are modules, and to the file of a::b otherwise.
+func (r *rustImportResolver) resolve(sourceFile string, imp parsers.ImportStatement) []importTarget {
+	crate := r.crateFor(sourceFile)
+	segments := strings.Split(imp.ModulePath, "::")
+
+	module := r.start(crate, sourceFile, segments[0])
+	if module == "" {
+		return nil
+	}
+	for _, segment := range segments[1:] {
+		switch segment {
+		case "self":
+			continue
+		case "super":
+			module = r.parent(crate, module)
+		default:
+			child := r.child(crate, module, segment)
+			if child == "" {
+				// The rest of the path names items of module
+				return r.targets(sourceFile, module, crate, nil)
+			}
+			module = child
+		}
+		if module == "" {
+			return nil
+		}
+	}
+	return r.targets(sourceFile, module, crate, imp.Symbols)
+}
+
+// targets returns the files of module and of the symbols that are its
+// submodules.
+func (r *rustImportResolver) targets(sourceFile, module string, crate *rustCrate, symbols []string) []importTarget {
+	var targets []importTarget
+	seen := make(map[string]bool)
+	add := func(file string) {
+		if file != "" && file != sourceFile && !seen[file] {
+			seen[file] = true
+			targets = append(targets, importTarget{path: file})
+		}
+	}
+	for _, symbol := range symbols {
+		if child := r.child(crate, module, symbol); child != "" && symbol != "*" {
+			add(child)
+		} else {
+			add(module)
+		}
+	}
+	if len(symbols) == 0 {
+		add(module)
+	}
+	return targets
+}
+
+// start returns the module file the first segment of a path refers to.
+func (r *rustImportResolver) start(crate *rustCrate, sourceFile, segment string) string {
+	switch segment {
+	case "crate":
+		if crate == nil || !r.files[crate.root] {
+			return ""
+		}
+		return crate.root
+	case "self":
+		return sourceFile
+	case "super":
+		return r.parent(crate, sourceFile)
+	}
+
+	// A child module of the current module (uniform paths), then one of
+	// the crate root (2015 edition paths)
+	if child := r.child(crate, sourceFile, segment); child != "" {
+		return child
+	}
+	if crate != nil && r.files[crate.root] {
+		if child := r.child(crate, crate.root, segment); child != "" {
+			return child
+		}
+	}
+
+	// Another crate of the repository
+	var target *rustCrate
+	if crate != nil {
+		if dir, ok := crate.deps[segment]; ok {
+			target = r.loadCrate(dir)
+		}
+	}
+	if target == nil {
+		target = r.byName[segment]
+	}
+	if target == nil || !r.files[target.root] {
+		return ""
+	}
+	return target.root
+}
+
+// moduleDir returns the directory holding the submodules of the module
+// implemented by file: the file's directory for crate roots and mod.rs
+// files, and the directory named after the file otherwise.
+func (r *rustImportResolver) moduleDir(crate *rustCrate, file string) string {
+	base := filepath.Base(file)
+	if base == "mod.rs" || (crate != nil && file == crate.root) || base == "lib.rs" || base == "main.rs" {
+		return filepath.Dir(file)
+	}
+	return strings.TrimSuffix(file, ".rs")
+}
+
+// child returns the file of the submodule name of the module implemented
+// by file, or "".
+func (r *rustImportResolver) child(crate *rustCrate, file, name string) string {
+	dir := r.moduleDir(crate, file)
+	if candidate := filepath.Join(dir, name+".rs"); r.files[candidate] {
+		return candidate
+	}
+	if candidate := filepath.Join(dir, name, "mod.rs"); r.files[candidate] {
+		return candidate
+	}
+	return ""
+}
+
+// parent returns the file of the module containing the module implemented
+// by file, or "".
+func (r *rustImportResolver) parent(crate *rustCrate, file string) string {
+	if crate != nil && file == crate.root {
+		return ""
+	}
+	dir := filepath.Dir(file)
+	if filepath.Base(file) == "mod.rs" {
+		dir = filepath.Dir(dir)
+	}
+	if crate != nil && dir == filepath.Dir(crate.root) {
+		return crate.root
+	}
+	for _, candidate := range []string{filepath.Join(dir, "mod.rs"), dir + ".rs", filepath.Join(dir, "lib.rs"), filepath.Join(dir, "main.rs")} {
+		if r.files[candidate] {
+			return candidate
+		}
+	}
+	return ""
+}
@@ -0,0 +1,490 @@
+package ingestion
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// newTestImportResolver writes a repository and creates a resolver for all
+// of its files.
+func newTestImportResolver(t *testing.T, files map[string]string) *importResolver {
+	t.Helper()
+
+	dir := writeRepo(t, files)
+	g := graph.NewKnowledgeGraph()
+	for path := range files {
+		path = filepath.FromSlash(path)
+		g.AddNode(&graph.GraphNode{ID: graph.GenerateID(graph.NodeFile, path, ""), Label: graph.NodeFile, FilePath: path})
+	}
+	return newImportResolver(dir, g)
+}
+
+// resolvedPaths resolves an import and returns the target paths.
+func resolvedPaths(r *importResolver, sourceFile string, imp parsers.ImportStatement) []string {
+	var paths []string
+	for _, target := range r.resolve(filepath.FromSlash(sourceFile), imp) {
+		paths = append(paths, filepath.ToSlash(target.path))
+	}
+	return paths
+}
+
+func TestGoImportResolver(t *testing.T) {
+	t.Parallel()
+
+	r := newTestImportResolver(t, map[string]string{
+		"go.work":                 "go 1.22\n\nuse (\n\t.\n\t./tools\n)\n",
+		"go.mod":                  "module example.com/app\n\ngo 1.22\n\nreplace example.com/shared => ./third_party/shared\n",
+		"main.go":                 "package main\n",
+		"internal/db/db.go":       "package db\n",
+		"tools/go.mod":            "module example.com/tools\n\ngo 1.22\n",
+		"tools/gen/gen.go":        "package gen\n",
+		"third_party/shared/s.go": "package shared\n",
+	})
+
+	tests := []struct {
+		name     string
+		path     string
+		expected []string
+	}{
+		{"MainModule", "example.com/app/internal/db", []string{"internal/db"}},
+		{"WorkspaceModule", "example.com/tools/gen", []string{"tools/gen"}},
+		{"Replace", "example.com/shared", []string{"third_party/shared"}},
+		{"MissingPackage", "example.com/app/missing", nil},
+		{"StandardLibrary", "fmt", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := resolvedPaths(r, "main.go", parsers.ImportStatement{ModulePath: tt.path})
+			assert.Equal(t, tt.expected, paths)
+		})
+	}
+
+	t.Run("TargetsAreDirectories", func(t *testing.T) {
+		targets := r.resolve("main.go", parsers.ImportStatement{ModulePath: "example.com/app/internal/db"})
+		require.Len(t, targets, 1)
+		assert.True(t, targets[0].isDir)
+	})
+}
+
+func TestPythonImportResolver(t *testing.T) {
+	t.Parallel()
+
+	r := newTestImportResolver(t, map[string]string{
+		"src/shop/__init__.py":        "",
+		"src/shop/models.py":          "",
+		"src/shop/api/__init__.py":    "",
+		"src/shop/api/views.py":       "",
+		"src/shop/api/serializers.py": "",
+		"scripts/run.py":              "",
+		"scripts/helpers.py":          "",
+		"settings.py":                 "",
+	})
+
+	tests := []struct {
+		name     string
+		source   string
+		imp      parsers.ImportStatement
+		expected []string
+	}{
+		{
+			name:     "AbsoluteFromSourceRoot",
+			source:   "src/shop/api/views.py",
+			imp:      parsers.ImportStatement{ModulePath: "shop.models"},
+			expected: []string{"src/shop/models.py"},
+		},
+		{
+			name:     "FromPackageImportSymbol",
+			source:   "src/shop/api/views.py",
+			imp:      parsers.ImportStatement{ModulePath: "shop.api", Symbols: []string{"serializers", "router"}},
+			expected: []string{"src/shop/api/serializers.py", "src/shop/api/__init__.py"},
+		},
+		{
+			name:     "RelativeSibling",
+			source:   "src/shop/api/views.py",
+			imp:      parsers.ImportStatement{ModulePath: ".", Symbols: []string{"serializers"}, IsRelative: true},
+			expected: []string{"src/shop/api/serializers.py"},
+		},
+		{
+			name:     "RelativeParent",
+			source:   "src/shop/api/views.py",
+			imp:      parsers.ImportStatement{ModulePath: "..models", Symbols: []string{"Order"}, IsRelative: true},
+			expected: []string{"src/shop/models.py"},
+		},
+		{
+			name:     "RepositoryRoot",
+			source:   "src/shop/models.py",
+			imp:      parsers.ImportStatement{ModulePath: "settings"},
+			expected: []string{"settings.py"},
+		},
+		{
+			name:     "ScriptDirectory",
+			source:   "scripts/run.py",
+			imp:      parsers.ImportStatement{ModulePath: "helpers"},
+			expected: []string{"scripts/helpers.py"},
+		},
+		{
+			name:   "ThirdParty",
+			source: "scripts/run.py",
+			imp:    parsers.ImportStatement{ModulePath: "requests"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolvedPaths(r, tt.source, tt.imp))
+		})
+	}
+}
+
+func TestTSImportResolver(t *testing.T) {
+	t.Parallel()
+
+	r := newTestImportResolver(t, map[string]string{
+		"tsconfig.base.json": `{
+	// Shared options
+	"compilerOptions": {
+		"baseUrl": ".",
+		"paths": {
+			"@app/*": ["apps/web/src/*"],
+			"@app/config": ["apps/web/config.ts"], /* exact match */
+		},
+	},
+}`,
+		"apps/web/tsconfig.json":   `{"extends": "../../tsconfig.base.json"}`,
+		"apps/web/src/main.tsx":    "",
+		"apps/web/src/lib/date.ts": "",
+		"apps/web/src/ui/index.ts": "",
+		"apps/web/config.ts":       "",
+		"packages/core/package.json": `{
+	"name": "@acme/core",
+	"exports": {
+		".": {"types": "./dist/index.d.ts", "import": "./dist/index.js"},
+		"./utils/*": "./src/utils/*.ts"
+	}
+}`,
+		"packages/core/src/index.ts":         "",
+		"packages/core/src/utils/strings.ts": "",
+		"packages/legacy/package.json":       `{"name": "legacy", "main": "lib/main.js"}`,
+		"packages/legacy/lib/main.js":        "",
+	})
+
+	source := "apps/web/src/main.tsx"
+	tests := []struct {
+		name     string
+		spec     string
+		expected []string
+	}{
+		{"Relative", "./lib/date", []string{"apps/web/src/lib/date.ts"}},
+		{"RelativeJSExtension", "./lib/date.js", []string{"apps/web/src/lib/date.ts"}},
+		{"DirectoryIndex", "./ui", []string{"apps/web/src/ui/index.ts"}},
+		{"PathsWildcard", "@app/lib/date", []string{"apps/web/src/lib/date.ts"}},
+		{"PathsExact", "@app/config", []string{"apps/web/config.ts"}},
+		{"BaseURL", "packages/legacy/lib/main", []string{"packages/legacy/lib/main.js"}},
+		{"PackageExportsBuildOutput", "@acme/core", []string{"packages/core/src/index.ts"}},
+		{"PackageExportsPattern", "@acme/core/utils/strings", []string{"packages/core/src/utils/strings.ts"}},
+		{"PackageMain", "legacy", []string{"packages/legacy/lib/main.js"}},
+		{"NotExported", "@acme/core/internal", nil},
+		{"External", "react", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			paths := resolvedPaths(r, source, parsers.ImportStatement{ModulePath: tt.spec})
+			assert.Equal(t, tt.expected, paths)
+		})
+	}
+}
+
+func TestRustImportResolver(t *testing.T) {
+	t.Parallel()
+
+	r := newTestImportResolver(t, map[string]string{
+		"Cargo.toml": `[workspace]
+members = ["crates/*"]
+
+[workspace.dependencies]
+core = { package = "shop-core", path = "crates/core" }
+serde = "1"
+`,
+		"crates/core/Cargo.toml": `[package]
+name = "shop-core"
+version = "0.1.0"
+`,
+		"crates/core/src/lib.rs":          "",
+		"crates/core/src/model.rs":        "",
+		"crates/core/src/model/order.rs":  "",
+		"crates/core/src/store/mod.rs":    "",
+		"crates/core/src/store/memory.rs": "",
+		"crates/api/Cargo.toml": `[package]
+name = "api"
+
+[dependencies]
+core = { workspace = true }
+serde = { workspace = true }
+`,
+		"crates/api/src/main.rs":           "",
+		"crates/api/src/routes.rs":         "",
+		"crates/api/src/routes/orders.rs":  "",
+		"crates/api/src/routes/helpers.rs": "",
+	})
+
+	tests := []struct {
+		name     string
+		source   string
+		imp      parsers.ImportStatement
+		expected []string
+	}{
+		{"CrateItem", "crates/core/src/store/memory.rs", parsers.ImportStatement{ModulePath: "crate::model", Symbols: []string{"Order"}}, []string{"crates/core/src/model.rs"}},
+		{"CrateSubmodule", "crates/core/src/store/memory.rs", parsers.ImportStatement{ModulePath: "crate::model", Symbols: []string{"order"}}, []string{"crates/core/src/model/order.rs"}},
+		{"CrateNestedItem", "crates/core/src/lib.rs", parsers.ImportStatement{ModulePath: "crate::model::order::Order"}, []string{"crates/core/src/model/order.rs"}},
+		{"Super", "crates/core/src/store/memory.rs", parsers.ImportStatement{ModulePath: "super", Symbols: []string{"Store"}}, []string{"crates/core/src/store/mod.rs"}},
+		{"SuperSuper", "crates/core/src/model/order.rs", parsers.ImportStatement{ModulePath: "super::super", Symbols: []string{"store"}}, []string{"crates/core/src/store/mod.rs"}},
+		{"SelfChild", "crates/api/src/routes.rs", parsers.ImportStatement{ModulePath: "self::orders"}, []string{"crates/api/src/routes/orders.rs"}},
+		{"ModDeclaration", "crates/api/src/main.rs", parsers.ImportStatement{ModulePath: "self::routes"}, []string{"crates/api/src/routes.rs"}},
+		{"Sibling", "crates/api/src/routes/orders.rs", parsers.ImportStatement{ModulePath: "super::helpers", Symbols: []string{"paginate"}}, []string{"crates/api/src/routes/helpers.rs"}},
+		{"RenamedWorkspaceDependency", "crates/api/src/routes/orders.rs", parsers.ImportStatement{ModulePath: "core::store", Symbols: []string{"memory", "Store"}}, []string{"crates/core/src/store/memory.rs", "crates/core/src/store/mod.rs"}},
+		{"WorkspaceMemberByName", "crates/api/src/main.rs", parsers.ImportStatement{ModulePath: "shop_core"}, []string{"crates/core/src/lib.rs"}},
+		{"External", "crates/api/src/main.rs", parsers.ImportStatement{ModulePath: "serde", Symbols: []string{"Serialize"}}, nil},
+		{"Std", "crates/api/src/main.rs", parsers.ImportStatement{ModulePath: "std::collections", Symbols: []string{"HashMap"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolvedPaths(r, tt.source, tt.imp))
+		})
+	}
+}
+
+func TestJVMImportResolver(t *testing.T) {
+	t.Parallel()
+
+	r := newTestImportResolver(t, map[string]string{
+		"app/src/main/java/com/shop/App.java":              "package com.shop;\n",
+		"app/src/main/java/com/shop/model/Order.java":      "/* Orders. */\npackage com.shop.model;\n",
+		"app/src/main/java/com/shop/model/Status.java":     "package com.shop.model;\n",
+		"app/src/test/java/com/shop/model/OrderTest.java":  "package com.shop.model;\n",
+		"core/src/main/kotlin/com/shop/core/Pricing.kt":    "package com.shop.core\n",
+		"core/src/main/kotlin/com/shop/core/Extensions.kt": "@file:JvmName(\"Ext\")\npackage com.shop.core\n",
+		"legacy/Flat.kt":           "package com.shop.legacy\n",
+		"scripts/build.gradle.kts": "plugins {}\n",
+	})
+
+	tests := []struct {
+		name     string
+		source   string
+		imp      parsers.ImportStatement
+		expected []string
+	}{
+		{"Class", "app/src/main/java/com/shop/App.java", parsers.ImportStatement{ModulePath: "com.shop.model", Symbols: []string{"Order"}}, []string{"app/src/main/java/com/shop/model/Order.java"}},
+		{"KotlinClass", "app/src/main/java/com/shop/App.java", parsers.ImportStatement{ModulePath: "com.shop.core", Symbols: []string{"Pricing"}}, []string{"core/src/main/kotlin/com/shop/core/Pricing.kt"}},
+		{"NestedClass", "app/src/main/java/com/shop/App.java", parsers.ImportStatement{ModulePath: "com.shop.model.Order", Symbols: []string{"Line"}}, []string{"app/src/main/java/com/shop/model/Order.java"}},
+		{"StaticMember", "app/src/main/java/com/shop/App.java", parsers.ImportStatement{ModulePath: "com.shop.model.Status", Symbols: []string{"ACTIVE"}}, []string{"app/src/main/java/com/shop/model/Status.java"}},
+		{"Wildcard", "app/src/main/java/com/shop/App.java", parsers.ImportStatement{ModulePath: "com.shop.model", Symbols: []string{"*"}}, []string{"app/src/main/java/com/shop/model", "app/src/test/java/com/shop/model"}},
+		{"TopLevelFunction", "app/src/main/java/com/shop/App.java", parsers.ImportStatement{ModulePath: "com.shop.core", Symbols: []string{"discount"}}, []string{"core/src/main/kotlin/com/shop/core"}},
+		{"PackageOutsideLayout", "app/src/main/java/com/shop/App.java", parsers.ImportStatement{ModulePath: "com.shop.legacy", Symbols: []string{"Flat"}}, []string{"legacy/Flat.kt"}},
+		{"External", "app/src/main/java/com/shop/App.java", parsers.ImportStatement{ModulePath: "java.util", Symbols: []string{"List"}}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolvedPaths(r, tt.source, tt.imp))
+		})
+	}
+}
+
+func TestCSharpImportResolver(t *testing.T) {
+	t.Parallel()
+
+	r := newTestImportResolver(t, map[string]string{
+		"src/Shop.Api/Shop.Api.csproj": `<Project Sdk="Microsoft.NET.Sdk.Web">
+  <ItemGroup>
+    <ProjectReference Include="..\Shop.Core\Shop.Core.csproj" />
+  </ItemGroup>
+</Project>`,
+		"src/Shop.Api/Program.cs": "using Shop.Core;\n",
+		"src/Shop.Core/Shop.Core.csproj": `<Project Sdk="Microsoft.NET.Sdk">
+  <PropertyGroup>
+    <AssemblyName>Shop.Domain</AssemblyName>
+  </PropertyGroup>
+  <ItemGroup>
+    <ProjectReference Include="../Shop.Data/Shop.Data.csproj" />
+  </ItemGroup>
+</Project>`,
+		"src/Shop.Core/Orders/OrderService.cs": "namespace Shop.Core.Orders\n{\n}\n",
+		"src/Shop.Core/Money.cs":               "namespace Shop.Core;\n",
+		"src/Shop.Data/Shop.Data.csproj":       `<Project Sdk="Microsoft.NET.Sdk" />`,
+		"src/Shop.Data/Repository.cs":          "namespace Shop.Data;\n",
+		"src/Shop.Admin/Shop.Admin.csproj":     `<Project Sdk="Microsoft.NET.Sdk" />`,
+		"src/Shop.Admin/Reports.cs":            "namespace Shop.Core.Reports;\n",
+		"scripts/Tool.cs":                      "namespace Shop.Tools;\n",
+	})
+
+	tests := []struct {
+		name     string
+		source   string
+		imp      parsers.ImportStatement
+		expected []string
+	}{
+		{"Namespace", "src/Shop.Api/Program.cs", parsers.ImportStatement{ModulePath: "Shop.Core.Orders"}, []string{"src/Shop.Core"}},
+		{"TransitiveReference", "src/Shop.Api/Program.cs", parsers.ImportStatement{ModulePath: "Shop.Data"}, []string{"src/Shop.Data"}},
+		{"UnreferencedProject", "src/Shop.Api/Program.cs", parsers.ImportStatement{ModulePath: "Shop.Core.Reports"}, nil},
+		{"OwnProject", "src/Shop.Core/Money.cs", parsers.ImportStatement{ModulePath: "Shop.Core.Orders"}, nil},
+		{"StaticType", "src/Shop.Api/Program.cs", parsers.ImportStatement{ModulePath: "Shop.Core", Symbols: []string{"Money"}}, []string{"src/Shop.Core"}},
+		{"AliasNamespace", "src/Shop.Api/Program.cs", parsers.ImportStatement{ModulePath: "Shop.Core", Symbols: []string{"Orders"}, Alias: "O"}, []string{"src/Shop.Core"}},
+		{"OutsideProjects", "scripts/Tool.cs", parsers.ImportStatement{ModulePath: "Shop.Core.Reports"}, []string{"src/Shop.Admin"}},
+		{"External", "src/Shop.Api/Program.cs", parsers.ImportStatement{ModulePath: "System.Linq"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolvedPaths(r, tt.source, tt.imp))
+		})
+	}
+
+	assert.Equal(t, "Shop.Domain", r.csharp().projectFor(filepath.FromSlash("src/Shop.Core/Orders/OrderService.cs")).name, "AssemblyName names the project")
+	assert.Nil(t, r.csharp().projectFor(filepath.FromSlash("scripts/Tool.cs")))
+}
+
+func TestCImportResolver(t *testing.T) {
+	t.Parallel()
+
+	r := newTestImportResolver(t, map[string]string{
+		"include/store/repo.h":  "",
+		"src/repo.c":            "",
+		"src/internal.h":        "",
+		"src/util/strings.h":    "",
+		"vendor/zlib/strings.h": "",
+		"native/codec.h":        "",
+		"native/native.go":      "",
+	})
+
+	tests := []struct {
+		name     string
+		source   string
+		imp      parsers.ImportStatement
+		expected []string
+	}{
+		{"SameDirectory", "src/repo.c", parsers.ImportStatement{ModulePath: "internal.h", IsRelative: true}, []string{"src/internal.h"}},
+		{"RelativePath", "src/repo.c", parsers.ImportStatement{ModulePath: "../include/store/repo.h", IsRelative: true}, []string{"include/store/repo.h"}},
+		{"IncludePath", "src/repo.c", parsers.ImportStatement{ModulePath: "store/repo.h", IsRelative: true}, []string{"include/store/repo.h"}},
+		{"Bracketed", "src/repo.c", parsers.ImportStatement{ModulePath: "store/repo.h"}, []string{"include/store/repo.h"}},
+		{"ClosestMatch", "src/repo.c", parsers.ImportStatement{ModulePath: "strings.h"}, []string{"src/util/strings.h"}},
+		{"System", "src/repo.c", parsers.ImportStatement{ModulePath: "stdio.h"}, nil},
+		{"Cgo", "native/native.go", parsers.ImportStatement{ModulePath: "codec.h", IsRelative: true}, []string{"native/codec.h"}},
+		{"GoPackage", "native/native.go", parsers.ImportStatement{ModulePath: "C"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolvedPaths(r, tt.source, tt.imp))
+		})
+	}
+}
+
+func TestRubyImportResolver(t *testing.T) {
+	t.Parallel()
+
+	r := newTestImportResolver(t, map[string]string{
+		"lib/billing/invoice.rb":   "",
+		"lib/billing.rb":           "",
+		"app/models/user.rb":       "",
+		"app/models/concerns/x.rb": "",
+		"tasks/deploy.rake":        "",
+	})
+
+	tests := []struct {
+		name     string
+		source   string
+		imp      parsers.ImportStatement
+		expected []string
+	}{
+		{"Relative", "app/models/user.rb", parsers.ImportStatement{ModulePath: "concerns/x", IsRelative: true}, []string{"app/models/concerns/x.rb"}},
+		{"RelativeParent", "lib/billing/invoice.rb", parsers.ImportStatement{ModulePath: "../billing", IsRelative: true}, []string{"lib/billing.rb"}},
+		{"LoadPath", "app/models/user.rb", parsers.ImportStatement{ModulePath: "billing/invoice"}, []string{"lib/billing/invoice.rb"}},
+		{"Extension", "tasks/deploy.rake", parsers.ImportStatement{ModulePath: "billing.rb"}, []string{"lib/billing.rb"}},
+		{"Gem", "app/models/user.rb", parsers.ImportStatement{ModulePath: "json"}, nil},
+		{"MissingRelative", "app/models/user.rb", parsers.ImportStatement{ModulePath: "missing", IsRelative: true}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolvedPaths(r, tt.source, tt.imp))
+		})
+	}
+}
+
+func TestPHPImportResolver(t *testing.T) {
+	t.Parallel()
+
+	r := newTestImportResolver(t, map[string]string{
+		"composer.json": `{
+			"autoload": {"psr-4": {"App\\": "app/", "Domain\\Billing\\": ["src/billing/"]}},
+			"autoload-dev": {"psr-4": {"Tests\\": "tests/"}}
+		}`,
+		"app/Models/User.php":           "",
+		"app/Http/Controllers/Home.php": "",
+		"src/billing/Invoice.php":       "",
+		"tests/Feature/UserTest.php":    "",
+		"legacy/lib/Mailer/Smtp.php":    "",
+		"legacy/helpers.php":            "",
+		"legacy/index.php":              "",
+	})
+
+	tests := []struct {
+		name     string
+		source   string
+		imp      parsers.ImportStatement
+		expected []string
+	}{
+		{"PSR4", "app/Http/Controllers/Home.php", parsers.ImportStatement{ModulePath: `App\Models\User`}, []string{"app/Models/User.php"}},
+		{"PSR4List", "app/Models/User.php", parsers.ImportStatement{ModulePath: `Domain\Billing\Invoice`}, []string{"src/billing/Invoice.php"}},
+		{"AutoloadDev", "app/Models/User.php", parsers.ImportStatement{ModulePath: `Tests\Feature\UserTest`}, []string{"tests/Feature/UserTest.php"}},
+		{"Unmapped", "legacy/index.php", parsers.ImportStatement{ModulePath: `Acme\Mailer\Smtp`}, []string{"legacy/lib/Mailer/Smtp.php"}},
+		{"ThirdParty", "app/Models/User.php", parsers.ImportStatement{ModulePath: `Illuminate\Database\Eloquent\Model`}, nil},
+		{"Require", "legacy/index.php", parsers.ImportStatement{ModulePath: "./helpers.php", IsRelative: true}, []string{"legacy/helpers.php"}},
+		{"RequireFromRoot", "legacy/index.php", parsers.ImportStatement{ModulePath: "app/Models/User.php", IsRelative: true}, []string{"app/Models/User.php"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, resolvedPaths(r, tt.source, tt.imp))
+		})
+	}
+}
+
+func TestStripJSONC(t *testing.T) {
+	t.Parallel()
+
+	input := `{
+	// comment
+	"url": "http://example.com", /* block */
+	"list": [1, 2,],
+}`
+	assert.JSONEq(t, `{"url": "http://example.com", "list": [1, 2]}`, string(stripJSONC([]byte(input))))
+}
+
+func TestProcessImports_GoModule(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"go.mod":         "module example.com/shop\n\ngo 1.22\n",
+		"store/store.go": "package store\n\nfunc New() {}\n",
+		"cmd/shop/main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/shop/store"
+)
+
+func main() { store.New(); fmt.Println() }
+`,
+	})
+
+	g, _, err := RunPipeline(t.Context(), dir, nil, false, nil, false, 0)
+	require.NoError(t, err)
+
+	mainID := graph.GenerateID(graph.NodeFile, filepath.Join("cmd", "shop", "main.go"), "")
+	rels := g.GetOutgoing(mainID, graph.RelImports)
+	require.Len(t, rels, 1)
+	assert.Equal(t, graph.GenerateID(graph.NodePackage, "store", ""), rels[0].Target)
+	assert.Equal(t, "example.com/shop/store", rels[0].Properties["module"])
+}
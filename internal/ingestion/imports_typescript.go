@@ -0,0 +1,539 @@
+package ingestion
+
+import (
+	"encoding/json"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// tsExtensions are the extensions tried, in order, for an extensionless
+// TypeScript or JavaScript module specifier.
+var tsExtensions = []string{".ts", ".tsx", ".d.ts", ".js", ".jsx", ".mjs", ".cjs"}
+
+// tsExportConditions are the package.json "exports" conditions honoured, in
+// order of preference.
+var tsExportConditions = []string{"source", "types", "import", "module", "require", "node", "default"}
+
+// tsImportResolver maps TypeScript and JavaScript module specifiers to files.
+// It follows relative specifiers, the "paths" and "baseUrl" options of the
+// nearest tsconfig.json (or jsconfig.json), and the package.json of packages
+// that live in the repository (workspaces, monorepos).
+type tsImportResolver struct {
+	repoPath string
+	files    map[string]bool
+
+	// configs caches the nearest tsconfig of each directory (nil if none)
+	configs map[string]*tsConfig
+
+	// packages are the package.json files of the repository, by name
+	packages map[string]*npmPackage
+
+	// packageDirs are the same packages by repository-relative directory
+	packageDirs map[string]*npmPackage
+}
+
+// tsConfig holds the module resolution options of a tsconfig.json.
+type tsConfig struct {
+	// baseURL is the repository-relative baseUrl ("" if unset)
+	baseURL string
+
+	// paths are the "paths" mappings, most specific first
+	paths []tsPathMapping
+}
+
+// tsPathMapping is one entry of the "paths" compiler option.
+type tsPathMapping struct {
+	pattern string
+	targets []string
+
+	// base is the directory targets are relative to
+	base string
+}
+
+// npmPackage is a package.json found in the repository.
+type npmPackage struct {
+	name    string
+	dir     string
+	exports any
+	entries []string // main, module, types
+}
+
+// newTSImportResolver creates a resolver and indexes the package.json files
+// of the repository.
+func newTSImportResolver(repoPath string, files map[string]bool) *tsImportResolver {
+	r := &tsImportResolver{
+		repoPath:    repoPath,
+		files:       files,
+		configs:     make(map[string]*tsConfig),
+		packages:    make(map[string]*npmPackage),
+		packageDirs: make(map[string]*npmPackage),
+	}
+	r.loadPackages()
+	return r
+}
+
+// resolve returns the file a module specifier refers to.
+func (r *tsImportResolver) resolve(sourceFile string, imp parsers.ImportStatement) []importTarget {
+	spec := imp.ModulePath
+	if spec == "" {
+		return nil
+	}
+
+	var file string
+	if strings.HasPrefix(spec, ".") {
+		file = r.resolveFile(filepath.Join(filepath.Dir(sourceFile), filepath.FromSlash(spec)))
+	} else {
+		file = r.resolveBare(filepath.Dir(sourceFile), spec)
+	}
+
+	if file == "" || file == sourceFile {
+		return nil
+	}
+	return []importTarget{{path: file}}
+}
+
+// resolveBare resolves a non-relative specifier through tsconfig options and
+// the packages of the repository.
+func (r *tsImportResolver) resolveBare(dir, spec string) string {
+	if cfg := r.configFor(dir); cfg != nil {
+		for _, mapping := range cfg.paths {
+			wildcard, ok := matchTSPattern(mapping.pattern, spec)
+			if !ok {
+				continue
+			}
+			for _, target := range mapping.targets {
+				path := strings.Replace(target, "*", wildcard, 1)
+				if file := r.resolveFile(filepath.Join(mapping.base, filepath.FromSlash(path))); file != "" {
+					return file
+				}
+			}
+		}
+		if cfg.baseURL != "" {
+			if file := r.resolveFile(filepath.Join(cfg.baseURL, filepath.FromSlash(spec))); file != "" {
+				return file
+			}
+		}
+	}
+
+	return r.resolvePackage(spec)
+}
+
+// resolveFile resolves a repository-relative module path the way TypeScript
+// does: the exact file, the file with a known extension, a .js specifier
+// pointing to its .ts source, or the index file of a directory.
+func (r *tsImportResolver) resolveFile(path string) string {
+	path = filepath.Clean(path)
+	if !insideRepo(path) {
+		return ""
+	}
+	if r.files[path] {
+		return path
+	}
+
+	ext := filepath.Ext(path)
+	switch ext {
+	case ".js", ".jsx", ".mjs", ".cjs":
+		stem := strings.TrimSuffix(path, ext)
+		for _, srcExt := range []string{".ts", ".tsx", ".mts", ".cts"} {
+			if r.files[stem+srcExt] {
+				return stem + srcExt
+			}
+		}
+	}
+
+	for _, ext := range tsExtensions {
+		if r.files[path+ext] {
+			return path + ext
+		}
+	}
+	for _, ext := range tsExtensions {
+		if index := filepath.Join(path, "index"+ext); r.files[index] {
+			return index
+		}
+	}
+	return ""
+}
+
+// resolvePackage resolves a specifier naming a package of the repository,
+// optionally followed by a subpath.
+func (r *tsImportResolver) resolvePackage(spec string) string {
+	// The longest package name that is the specifier or a prefix of it
+	var pkg *npmPackage
+	subpath := "."
+	for name, candidate := range r.packages {
+		if pkg != nil && len(name) <= len(pkg.name) {
+			continue
+		}
+		if name == spec {
+			pkg, subpath = candidate, "."
+		} else if rest, ok := strings.CutPrefix(spec, name+"/"); ok {
+			pkg, subpath = candidate, "./"+rest
+		}
+	}
+	if pkg == nil {
+		return ""
+	}
+
+	if pkg.exports != nil {
+		for _, target := range exportTargets(pkg.exports, subpath) {
+			if file := r.resolvePackageFile(pkg.dir, target); file != "" {
+				return file
+			}
+		}
+		return ""
+	}
+
+	if subpath != "." {
+		return r.resolveFile(filepath.Join(pkg.dir, filepath.FromSlash(subpath)))
+	}
+	for _, entry := range pkg.entries {
+		if file := r.resolvePackageFile(pkg.dir, entry); file != "" {
+			return file
+		}
+	}
+	return r.resolveFile(filepath.Join(pkg.dir, "index"))
+}
+
+// resolvePackageFile resolves a file named by a package.json. Packages often
+// point to build output that is not checked in; the sources are then looked
+// up under src/ instead.
+func (r *tsImportResolver) resolvePackageFile(pkgDir, target string) string {
+	path := filepath.FromSlash(target)
+	if file := r.resolveFile(filepath.Join(pkgDir, path)); file != "" {
+		return file
+	}
+	for _, outDir := range []string{"dist", "build", "lib", "out"} {
+		if rest, ok := strings.CutPrefix(filepath.Clean(path), outDir+string(filepath.Separator)); ok {
+			stem := strings.TrimSuffix(strings.TrimSuffix(rest, filepath.Ext(rest)), ".d")
+			return r.resolveFile(filepath.Join(pkgDir, "src", stem))
+		}
+	}
+	return ""
+}
+
+// packageFor returns the package a directory belongs to: the package.json
+// closest above it, or nil.
+func (r *tsImportResolver) packageFor(dir string) *npmPackage {
+	for {
+		if pkg, ok := r.packageDirs[dir]; ok {
+			return pkg
+		}
+		if dir == "." || dir == string(filepath.Separator) {
+			return nil
+		}
+		dir = filepath.Dir(dir)
+	}
+}
+
+// exportTargets returns the targets of a package.json "exports" field for a
+// subpath ("." or "./name"), in order of preference.
+func exportTargets(exports any, subpath string) []string {
+	entries, ok := exports.(map[string]any)
+	if !ok || !hasSubpathKeys(entries) {
+		// Sugar for {".": exports}
+		if subpath != "." {
+			return nil
+		}
+		return conditionTargets(exports)
+	}
+
+	if value, ok := entries[subpath]; ok {
+		return conditionTargets(value)
+	}
+
+	// Subpath patterns: "./features/*": "./src/features/*.js"
+	var keys []string
+	for key := range entries {
+		if strings.Contains(key, "*") {
+			keys = append(keys, key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool { return len(keys[i]) > len(keys[j]) })
+	for _, key := range keys {
+		if wildcard, ok := matchTSPattern(key, subpath); ok {
+			var targets []string
+			for _, target := range conditionTargets(entries[key]) {
+				targets = append(targets, strings.ReplaceAll(target, "*", wildcard))
+			}
+			return targets
+		}
+	}
+	return nil
+}
+
+// hasSubpathKeys reports whether an exports object maps subpaths (keys
+// starting with ".") rather than conditions.
+func hasSubpathKeys(entries map[string]any) bool {
+	for key := range entries {
+		if strings.HasPrefix(key, ".") {
+			return true
+		}
+	}
+	return false
+}
+
+// conditionTargets flattens a conditional export into its targets.
+func conditionTargets(value any) []string {
+	switch v := value.(type) {
+	case string:
+		return []string{v}
+	case []any:
+		var targets []string
+		for _, item := range v {
+			targets = append(targets, conditionTargets(item)...)
+		}
+		return targets
+	case map[string]any:
+		var targets []string
+		for _, condition := range tsExportConditions {
+			if item, ok := v[condition]; ok {
+				targets = append(targets, conditionTargets(item)...)
+			}
+		}
+		return targets
+	}
+	return nil
+}
+
+// matchTSPattern matches a specifier against a pattern with at most one "*"
+// and returns the text matched by the wildcard.
+func matchTSPattern(pattern, spec string) (string, bool) {
+	prefix, suffix, hasWildcard := strings.Cut(pattern, "*")
+	if !hasWildcard {
+		return "", pattern == spec
+	}
+	if len(spec) < len(prefix)+len(suffix) || !strings.HasPrefix(spec, prefix) || !strings.HasSuffix(spec, suffix) {
+		return "", false
+	}
+	return spec[len(prefix) : len(spec)-len(suffix)], true
+}
+
+// configFor returns the nearest tsconfig.json or jsconfig.json above dir.
+func (r *tsImportResolver) configFor(dir string) *tsConfig {
+	if cfg, ok := r.configs[dir]; ok {
+		return cfg
+	}
+
+	var cfg *tsConfig
+	for _, name := range []string{"tsconfig.json", "jsconfig.json"} {
+		if loaded, err := r.loadConfig(filepath.Join(dir, name), 0); err == nil {
+			cfg = loaded
+			break
+		}
+	}
+	if cfg == nil && dir != "." {
+		cfg = r.configFor(filepath.Dir(dir))
+	}
+
+	r.configs[dir] = cfg
+	return cfg
+}
+
+// tsConfigFile is the part of a tsconfig.json used for module resolution.
+type tsConfigFile struct {
+	Extends         any `json:"extends"`
+	CompilerOptions struct {
+		BaseURL *string             `json:"baseUrl"`
+		Paths   map[string][]string `json:"paths"`
+	} `json:"compilerOptions"`
+}
+
+// loadConfig reads a tsconfig.json and the configs it extends.
+func (r *tsImportResolver) loadConfig(relPath string, depth int) (*tsConfig, error) {
+	data, err := os.ReadFile(filepath.Join(r.repoPath, relPath))
+	if err != nil {
+		return nil, err
+	}
+	var file tsConfigFile
+	if err := json.Unmarshal(stripJSONC(data), &file); err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(relPath)
+	cfg := &tsConfig{}
+
+	// Options of extended configs are overridden by the extending one
+	var parents []string
+	switch ext := file.Extends.(type) {
+	case string:
+		parents = []string{ext}
+	case []any:
+		for _, item := range ext {
+			if s, ok := item.(string); ok {
+				parents = append(parents, s)
+			}
+		}
+	}
+	for _, parent := range parents {
+		if !strings.HasPrefix(parent, ".") || depth > 8 {
+			continue // Configs from packages are not in the repository
+		}
+		parentPath := filepath.Join(dir, filepath.FromSlash(parent))
+		if filepath.Ext(parentPath) != ".json" {
+			parentPath += ".json"
+		}
+		if base, err := r.loadConfig(parentPath, depth+1); err == nil {
+			if base.baseURL != "" {
+				cfg.baseURL = base.baseURL
+			}
+			if len(base.paths) > 0 {
+				cfg.paths = base.paths
+			}
+		}
+	}
+
+	if file.CompilerOptions.BaseURL != nil {
+		cfg.baseURL = filepath.Join(dir, filepath.FromSlash(*file.CompilerOptions.BaseURL))
+	}
+	if len(file.CompilerOptions.Paths) > 0 {
+		// Paths are relative to baseUrl, or to the config without one
+		base := dir
+		if cfg.baseURL != "" {
+			base = cfg.baseURL
+		}
+		cfg.paths = nil
+		for pattern, targets := range file.CompilerOptions.Paths {
+			cfg.paths = append(cfg.paths, tsPathMapping{pattern: pattern, targets: targets, base: base})
+		}
+		// The longest prefix before the wildcard wins
+		sort.Slice(cfg.paths, func(i, j int) bool {
+			pi, _, _ := strings.Cut(cfg.paths[i].pattern, "*")
+			pj, _, _ := strings.Cut(cfg.paths[j].pattern, "*")
+			if len(pi) != len(pj) {
+				return len(pi) > len(pj)
+			}
+			return cfg.paths[i].pattern < cfg.paths[j].pattern
+		})
+	}
+
+	return cfg, nil
+}
+
+// packageJSON is the part of a package.json used for module resolution.
+type packageJSON struct {
+	Name    string `json:"name"`
+	Main    string `json:"main"`
+	Module  string `json:"module"`
+	Types   string `json:"types"`
+	Exports any    `json:"exports"`
+}
+
+// loadPackages indexes the named package.json files of the repository.
+func (r *tsImportResolver) loadPackages() {
+	_ = filepath.WalkDir(r.repoPath, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return nil
+		}
+		if d.IsDir() {
+			name := d.Name()
+			if path != r.repoPath && (name == "node_modules" || strings.HasPrefix(name, ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if d.Name() != "package.json" {
+			return nil
+		}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil
+		}
+		var pkg packageJSON
+		if err := json.Unmarshal(data, &pkg); err != nil || pkg.Name == "" {
+			return nil
+		}
+		dir, err := filepath.Rel(r.repoPath, filepath.Dir(path))
+		if err != nil {
+			return nil
+		}
+
+		np := &npmPackage{name: pkg.Name, dir: dir, exports: pkg.Exports}
+		for _, entry := range []string{pkg.Module, pkg.Main, pkg.Types} {
+			if entry != "" {
+				np.entries = append(np.entries, entry)
+			}
+		}
+		r.packages[pkg.Name] = np
+		r.packageDirs[dir] = np
+		return nil
+	})
+}
+
+// stripJSONC removes comments and trailing commas from JSON with comments,
+// the format of tsconfig.json.
+func stripJSONC(data []byte) []byte {
+	return stripTrailingCommas(stripJSONComments(data))
+}
+
+// stripJSONComments removes // and /* */ comments outside of strings.
+func stripJSONComments(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+		case c == '"':
+			inString = true
+			out = append(out, c)
+		case c == '/' && i+1 < len(data) && data[i+1] == '/':
+			for i+1 < len(data) && data[i+1] != '\n' {
+				i++
+			}
+		case c == '/' && i+1 < len(data) && data[i+1] == '*':
+			i += 2
+			for i+1 < len(data) && (data[i] != '*' || data[i+1] != '/') {
+				i++
+			}
+			i++
+		default:
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// stripTrailingCommas removes commas directly followed by a closing bracket.
+func stripTrailingCommas(data []byte) []byte {
+	out := make([]byte, 0, len(data))
+	inString := false
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case inString:
+			out = append(out, c)
+			if c == '\\' && i+1 < len(data) {
+				i++
+				out = append(out, data[i])
+			} else if c == '"' {
+				inString = false
+			}
+			continue
+		case c == '"':
+			inString = true
+		case c == ',':
+			j := i + 1
+			for j < len(data) && strings.ContainsRune(" \t\r\n", rune(data[j])) {
+				j++
+			}
+			if j < len(data) && (data[j] == '}' || data[j] == ']') {
+				continue
+			}
+		}
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,205 @@
+package ingestion
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"path/filepath"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/parsers"
+	"github.com/Benny93/axon-go/internal/storage"
+)
+
+// parseResultVersion is the version of the stored parse results. Bump it
+// whenever a parser change alters its output for the same input, so that
+// the next run parses every file again.
+const parseResultVersion = 3
+
+// ProcessParsingIncremental is ProcessParsing for a repository indexed
+// before: a file whose content hash matches its record in previous reuses
+// the stored parse result instead of being parsed again. Files are parsed
+// by concurrency goroutines (GOMAXPROCS if not positive), and progress, if
+// not nil, is called after each file with the number of files done.
+//
+// It also returns the records to store for the files, and the number of
+// files parsed. If ctx is canceled the files not reached yet are left out.
+func ProcessParsingIncremental(ctx context.Context, entries []FileEntry, g *graph.KnowledgeGraph, previous map[string]storage.FileRecord, concurrency int, progress func(done, total int)) (*ParseData, []storage.FileRecord, int) {
+	files := parseEach(ctx, entries, concurrency, func(entry FileEntry) parsedFile {
+		if record, ok := previous[entry.RelPath]; ok && record.SHA256 == entry.SHA256 && record.Version == parseResultVersion {
+			if result, err := decodeParseResult(record.Data); err == nil {
+				return parsedFile{result: result, record: &record}
+			}
+		}
+
+		result := parseEntry(entry)
+		if result == nil {
+			return parsedFile{}
+		}
+
+		// The result is encoded before later phases (ProcessGoTypes)
+		// modify it
+		file := parsedFile{result: result, parsed: true}
+		if data, err := encodeParseResult(result); err == nil {
+			file.record = &storage.FileRecord{
+				Path:    entry.RelPath,
+				SHA256:  entry.SHA256,
+				Version: parseResultVersion,
+				Data:    data,
+			}
+		}
+		return file
+	}, progress)
+
+	parseData := NewParseData()
+	records := make([]storage.FileRecord, 0, len(entries))
+	parsed := 0
+	for i, file := range files {
+		if file.result == nil {
+			continue
+		}
+		if file.parsed {
+			parsed++
+		}
+		if file.record != nil {
+			records = append(records, *file.record)
+		}
+
+		parseData.AddFile(entries[i].RelPath, file.result)
+		addSymbolNodes(entries[i], file.result, g)
+	}
+
+	return parseData, records, parsed
+}
+
+// parsedFile is the outcome of parsing a file, or of decoding its stored
+// parse result.
+type parsedFile struct {
+	result *parsers.ParseResult
+	record *storage.FileRecord
+
+	// parsed is false for a stored result
+	parsed bool
+}
+
+// goTypesRecord is what type-checking a Go file adds to its parse result:
+// the resolved call sites and variable references, and the interfaces the
+// types of the file implement.
+type goTypesRecord struct {
+	Typed           bool
+	Calls           []parsers.CallSite
+	VarRefs         []parsers.VariableRef
+	Implementations []parsers.Implementation
+}
+
+// ProcessGoTypesIncremental is ProcessGoTypes for a repository indexed
+// before: if no Go file was added, changed or removed since the run that
+// stored previous, the type-checked results kept in the records are reused
+// instead of loading the packages again. Otherwise the packages are loaded
+// and their results are kept in the records of the Go files.
+//
+// It reports whether the packages were loaded.
+func ProcessGoTypesIncremental(ctx context.Context, repoPath string, parseData *ParseData, records []storage.FileRecord, previous map[string]storage.FileRecord) bool {
+	if restoreGoTypes(parseData, records, previous) {
+		return false
+	}
+
+	err := ProcessGoTypes(ctx, repoPath, parseData)
+	parseData.mu.RLock()
+	defer parseData.mu.RUnlock()
+
+	implementations := make(map[string][]parsers.Implementation)
+	for _, impl := range parseData.GoImplementations {
+		implementations[impl.TypeFile] = append(implementations[impl.TypeFile], impl)
+	}
+	for i := range records {
+		record := &records[i]
+		if filepath.Ext(record.Path) != ".go" {
+			continue
+		}
+		record.GoTypes = nil
+		result := parseData.Files[record.Path]
+		if err != nil || parseData.goTyped == nil || result == nil {
+			continue
+		}
+		types := goTypesRecord{Typed: parseData.goTyped[record.Path], Implementations: implementations[record.Path]}
+		if types.Typed {
+			types.Calls, types.VarRefs = result.Calls, result.VarRefs
+		}
+		var buf bytes.Buffer
+		if gob.NewEncoder(&buf).Encode(&types) == nil {
+			record.GoTypes = buf.Bytes()
+		}
+	}
+	return parseData.goTyped != nil
+}
+
+// restoreGoTypes applies the type-checked results kept in the records of
+// the Go files to their parse results, if every Go file of previous is
+// still there unchanged and has one.
+func restoreGoTypes(parseData *ParseData, records []storage.FileRecord, previous map[string]storage.FileRecord) bool {
+	removed := 0
+	for path := range previous {
+		if filepath.Ext(path) == ".go" {
+			removed++
+		}
+	}
+	stored := make(map[string]*goTypesRecord)
+	var paths []string
+	for _, record := range records {
+		if filepath.Ext(record.Path) != ".go" {
+			continue
+		}
+		// Only records reused from previous still have their types
+		if len(record.GoTypes) == 0 {
+			return false
+		}
+		var types goTypesRecord
+		if err := gob.NewDecoder(bytes.NewReader(record.GoTypes)).Decode(&types); err != nil {
+			return false
+		}
+		stored[record.Path] = &types
+		paths = append(paths, record.Path)
+		removed--
+	}
+	if removed != 0 || len(paths) == 0 {
+		return false
+	}
+
+	parseData.mu.Lock()
+	defer parseData.mu.Unlock()
+
+	parseData.goTyped = make(map[string]bool)
+	for _, path := range paths {
+		types := stored[path]
+		parseData.GoImplementations = append(parseData.GoImplementations, types.Implementations...)
+		result := parseData.Files[path]
+		if !types.Typed || result == nil {
+			continue
+		}
+		result.Calls, result.VarRefs = types.Calls, types.VarRefs
+		parseData.goTyped[path] = true
+	}
+	return true
+}
+
+// encodeParseResult serializes a parse result for a file record. It uses
+// gob rather than JSON, which would turn the []string and int values of
+// Resource.Properties into []any and float64.
+func encodeParseResult(result *parsers.ParseResult) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(result); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// decodeParseResult deserializes a parse result encoded by
+// encodeParseResult.
+func decodeParseResult(data []byte) (*parsers.ParseResult, error) {
+	var result parsers.ParseResult
+	if err := gob.NewDecoder(bytes.NewReader(data)).Decode(&result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
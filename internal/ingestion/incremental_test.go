@@ -0,0 +1,197 @@
+package ingestion
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/parsers"
+	"github.com/Benny93/axon-go/internal/storage"
+)
+
+func TestRunPipeline_Incremental(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := writeRepo(t, map[string]string{
+		"main.go": `package main
+
+func main() {
+	helper()
+}
+`,
+		"helper.go": `package main
+
+func helper() {}
+`,
+		"util.go": `package main
+
+func unused() {}
+`,
+	})
+
+	store := storage.NewBadgerBackend()
+	require.NoError(t, store.Initialize(t.TempDir(), false))
+	t.Cleanup(func() { _ = store.Close() })
+
+	run := func(full bool) (*graph.KnowledgeGraph, *PipelineResult) {
+		t.Helper()
+		g, result, err := RunPipeline(ctx, dir, store, full, nil, false, 0)
+		require.NoError(t, err)
+		return g, result
+	}
+	stored := func(nodeID string) *graph.GraphNode {
+		t.Helper()
+		node, err := store.GetNode(ctx, nodeID)
+		require.NoError(t, err)
+		return node
+	}
+
+	_, result := run(false)
+	assert.Equal(t, 3, result.ParsedFiles, "the first run parses every file")
+
+	_, result = run(false)
+	assert.Equal(t, 0, result.ParsedFiles, "unchanged files are not parsed again")
+	require.NotNil(t, stored("function:helper.go:helper"))
+
+	t.Run("ChangedFile", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "helper.go"), []byte(`package main
+
+func helper() {
+	assist()
+}
+
+func assist() {}
+`), 0o644))
+
+		g, result := run(false)
+		assert.Equal(t, 1, result.ParsedFiles)
+
+		// Calls of unchanged files are resolved against the merged graph
+		assert.Contains(t, callTargets(g, "function:main.go:main"), "function:helper.go:helper")
+		assert.Contains(t, callTargets(g, "function:helper.go:helper"), "function:helper.go:assist")
+
+		callees, err := store.GetCallees(ctx, "function:helper.go:helper")
+		require.NoError(t, err)
+		require.Len(t, callees, 1)
+		assert.Equal(t, "function:helper.go:assist", callees[0].ID)
+	})
+
+	t.Run("DeletedFile", func(t *testing.T) {
+		require.NotNil(t, stored("function:util.go:unused"))
+		require.NoError(t, os.Remove(filepath.Join(dir, "util.go")))
+
+		_, result := run(false)
+		assert.Equal(t, 0, result.ParsedFiles)
+		assert.Nil(t, stored("function:util.go:unused"))
+		assert.Nil(t, stored(graph.GenerateID(graph.NodeFile, "util.go", "")))
+
+		records, err := store.FileRecords(ctx)
+		require.NoError(t, err)
+		assert.NotContains(t, records, "util.go")
+	})
+
+	t.Run("Full", func(t *testing.T) {
+		_, result := run(true)
+		assert.Equal(t, 2, result.ParsedFiles)
+	})
+}
+
+func TestRunPipeline_IncrementalGoTypes(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := writeRepo(t, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"store.go": `package shop
+
+type Store interface {
+	Save()
+}
+
+type DB struct{}
+
+func (d *DB) Save() {}
+`,
+		"main.go": `package shop
+
+func Run(s Store) {
+	s.Save()
+}
+`,
+	})
+
+	store := storage.NewBadgerBackend()
+	require.NoError(t, store.Initialize(t.TempDir(), false))
+	t.Cleanup(func() { _ = store.Close() })
+
+	run := func() (*graph.KnowledgeGraph, *PipelineResult) {
+		t.Helper()
+		g, result, err := RunPipeline(ctx, dir, store, false, nil, false, 0)
+		require.NoError(t, err)
+		return g, result
+	}
+	runID := graph.GenerateID(graph.NodeFunction, "main.go", "Run")
+	save := graph.GenerateID(graph.NodeMethod, "store.go", "Store.Save")
+	db := graph.GenerateID(graph.NodeClass, "store.go", "DB")
+
+	_, result := run()
+	assert.True(t, result.TypeChecked, "the first run loads the Go packages")
+
+	g, result := run()
+	assert.False(t, result.TypeChecked, "no Go file changed")
+	require.Contains(t, callTargets(g, runID), save)
+	assert.Equal(t, typedCallConfidence, callTargets(g, runID)[save].Properties["confidence"],
+		"the type-checked calls are reused")
+	assert.NotEmpty(t, g.GetOutgoing(db, graph.RelImplements), "and so are the implementations")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "README.md"), []byte("# Shop\n"), 0o644))
+	_, result = run()
+	assert.False(t, result.TypeChecked, "only Go files matter")
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte(`package shop
+
+func Run(s Store) {
+	s.Save()
+	s.Save()
+}
+`), 0o644))
+	_, result = run()
+	assert.True(t, result.TypeChecked)
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "extra.go"), []byte("package shop\n"), 0o644))
+	_, result = run()
+	assert.True(t, result.TypeChecked, "added files")
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "extra.go")))
+	_, result = run()
+	assert.True(t, result.TypeChecked, "removed files")
+}
+
+func TestParseResultEncoding(t *testing.T) {
+	t.Parallel()
+
+	result := &parsers.ParseResult{
+		Package: "main",
+		Symbols: []parsers.ParsedSymbol{{Name: "main", Kind: graph.NodeFunction, StartLine: 3}},
+		Calls: []parsers.CallSite{{
+			Name:   "helper",
+			Target: &parsers.CallTarget{Name: "helper", FilePath: "helper.go", Kind: graph.NodeFunction},
+		}},
+		Resources: []parsers.Resource{{
+			Name:       "module.network",
+			Properties: map[string]any{"inputs": []string{"cidr"}, "index": 2},
+		}},
+	}
+
+	data, err := encodeParseResult(result)
+	require.NoError(t, err)
+	decoded, err := decodeParseResult(data)
+	require.NoError(t, err)
+	assert.Equal(t, result, decoded)
+}
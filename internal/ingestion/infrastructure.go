@@ -0,0 +1,338 @@
+package ingestion
+
+import (
+	"context"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// ProcessInfrastructure creates a resource node for every Terraform block,
+// Kubernetes object and Docker build stage, defined by its file, and
+// REFERENCES relationships from each resource to the resources it depends
+// on, and from Docker stages to the Go main functions they build. It
+// returns the number of resources created.
+//
+// Terraform addresses resolve within the module (the directory) of the
+// block. A module block refers to the variables of its local source module
+// that it sets, and module.NAME.OUTPUT to the output of that module.
+//
+// Kubernetes names resolve within the namespace. A Service refers to the
+// workloads whose pod labels match its selector, and a workload to the
+// image stage of the Dockerfile that builds each of its images: the
+// Dockerfile whose directory, name (Dockerfile.api, api.Dockerfile) or
+// image stage alias is the repository name of the image
+// (ghcr.io/acme/api:1.2 is api), or the root Dockerfile for an image named
+// after the repository.
+//
+// Docker stages resolve within their Dockerfile, and the packages a stage
+// builds relative to the repository root, the directory of the Dockerfile,
+// or as a module path ending with the directory of a main package.
+func ProcessInfrastructure(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph, repoPath string) int {
+	filePaths := make([]string, 0, len(parseData.Files))
+	for filePath, result := range parseData.Files {
+		if len(result.Resources) > 0 {
+			filePaths = append(filePaths, filePath)
+		}
+	}
+	sort.Strings(filePaths)
+
+	type resource struct {
+		id, filePath string
+		*parsers.Resource
+	}
+	var resources []resource
+	terraform := make(map[string]map[string]string)
+	kubernetes := make(map[string][]resource)
+	count := 0
+
+	for _, filePath := range filePaths {
+		result := parseData.Files[filePath]
+		fileID := graph.GenerateID(graph.NodeFile, filePath, "")
+		language := ""
+		if file := g.GetNode(fileID); file != nil {
+			language = file.Language
+		}
+		for i := range result.Resources {
+			r := &result.Resources[i]
+			id := graph.GenerateID(graph.NodeResource, filePath, r.Name)
+			if g.GetNode(id) != nil {
+				continue
+			}
+			g.AddNode(&graph.GraphNode{
+				ID:         id,
+				Label:      graph.NodeResource,
+				Name:       r.Name,
+				FilePath:   filePath,
+				StartLine:  r.StartLine,
+				EndLine:    r.EndLine,
+				Content:    r.Content,
+				Signature:  resourceSignature(r),
+				Language:   language,
+				Properties: resourceProperties(r),
+			})
+			g.AddRelationship(&graph.GraphRelationship{
+				ID:     relationshipID(graph.RelDefines, fileID, id),
+				Type:   graph.RelDefines,
+				Source: fileID,
+				Target: id,
+			})
+			count++
+
+			resources = append(resources, resource{id, filePath, r})
+			switch r.Platform {
+			case "terraform":
+				dir := filepath.Dir(filePath)
+				if terraform[dir] == nil {
+					terraform[dir] = make(map[string]string)
+				}
+				terraform[dir][r.Name] = id
+			case "kubernetes":
+				kubernetes[r.Name] = append(kubernetes[r.Name], resource{id, filePath, r})
+			}
+		}
+	}
+
+	reference := func(sourceID, targetID string) {
+		if targetID == "" || targetID == sourceID {
+			return
+		}
+		g.AddRelationship(&graph.GraphRelationship{
+			ID:     relationshipID(graph.RelReferences, sourceID, targetID),
+			Type:   graph.RelReferences,
+			Source: sourceID,
+			Target: targetID,
+		})
+	}
+
+	// The directories of the local modules Terraform module blocks use
+	moduleDirs := make(map[string]string)
+	for _, r := range resources {
+		if source, _ := r.Properties["source"].(string); r.Platform == "terraform" && r.Kind == "module" &&
+			(strings.HasPrefix(source, "./") || strings.HasPrefix(source, "../")) {
+			moduleDirs[r.id] = filepath.Join(filepath.Dir(r.filePath), filepath.FromSlash(source))
+		}
+	}
+
+	images := dockerImages(dockerStages(parseData, filePaths), repoPath)
+	mains := goMains(g)
+
+	for _, r := range resources {
+		switch r.Platform {
+		case "terraform":
+			scope := terraform[filepath.Dir(r.filePath)]
+			for _, ref := range r.References {
+				parts := strings.SplitN(ref, ".", 3)
+				if parts[0] != "module" {
+					reference(r.id, scope[ref])
+					continue
+				}
+				moduleID := scope["module."+parts[1]]
+				reference(r.id, moduleID)
+				if dir, ok := moduleDirs[moduleID]; ok && len(parts) == 3 {
+					reference(r.id, terraform[dir]["output."+parts[2]])
+				}
+			}
+			if dir, ok := moduleDirs[r.id]; ok {
+				inputs, _ := r.Properties["inputs"].([]string)
+				for _, input := range inputs {
+					reference(r.id, terraform[dir]["var."+input])
+				}
+			}
+
+		case "kubernetes":
+			namespace, _ := r.Properties["namespace"].(string)
+			sameNamespace := func(other resource) bool {
+				ns, _ := other.Properties["namespace"].(string)
+				return ns == namespace || ns == "" || namespace == ""
+			}
+			for _, ref := range r.References {
+				for _, target := range kubernetes[ref] {
+					if sameNamespace(target) {
+						reference(r.id, target.id)
+					}
+				}
+			}
+			if len(r.Selector) > 0 {
+				for _, name := range sortedKeys(kubernetes) {
+					for _, workload := range kubernetes[name] {
+						if sameNamespace(workload) && labelsMatch(r.Selector, workload.Labels) {
+							reference(r.id, workload.id)
+						}
+					}
+				}
+			}
+			for _, image := range r.Images {
+				for _, stageID := range images[imageName(image)] {
+					reference(r.id, stageID)
+				}
+			}
+
+		case "docker":
+			for _, ref := range r.References {
+				reference(r.id, graph.GenerateID(graph.NodeResource, r.filePath, ref))
+			}
+			dir := filepath.ToSlash(filepath.Dir(r.filePath))
+			for _, pkg := range r.Builds {
+				reference(r.id, goMainFor(mains, dir, pkg))
+			}
+		}
+	}
+
+	return count
+}
+
+// resourceSignature returns the signature of a resource node: the first
+// line of a Terraform block or Docker stage, or the kind and name of a
+// Kubernetes object.
+func resourceSignature(r *parsers.Resource) string {
+	if r.Platform == "kubernetes" {
+		return r.Kind + " " + strings.TrimPrefix(r.Name, r.Kind+"/")
+	}
+	first, _, _ := strings.Cut(r.Content, "\n")
+	return strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(first), "{"))
+}
+
+// resourceProperties returns the properties of a resource node.
+func resourceProperties(r *parsers.Resource) map[string]any {
+	properties := map[string]any{
+		"kind":     r.Kind,
+		"platform": r.Platform,
+	}
+	for key, value := range r.Properties {
+		properties[key] = value
+	}
+	if len(r.Labels) > 0 {
+		properties["labels"] = r.Labels
+	}
+	if len(r.Selector) > 0 {
+		properties["selector"] = r.Selector
+	}
+	if len(r.Images) > 0 {
+		properties["images"] = r.Images
+	}
+	return properties
+}
+
+// dockerStages returns the build stages of the Dockerfiles among
+// filePaths, by file.
+func dockerStages(parseData *ParseData, filePaths []string) map[string][]parsers.Resource {
+	stages := make(map[string][]parsers.Resource)
+	for _, filePath := range filePaths {
+		for _, r := range parseData.Files[filePath].Resources {
+			if r.Platform == "docker" {
+				stages[filePath] = append(stages[filePath], r)
+			}
+		}
+	}
+	return stages
+}
+
+// dockerImages maps the repository names of images to the IDs of the image
+// stages (the last stages) of the Dockerfiles that may build them.
+func dockerImages(stages map[string][]parsers.Resource, repoPath string) map[string][]string {
+	repoName := ""
+	if abs, err := filepath.Abs(repoPath); err == nil {
+		repoName = strings.ToLower(filepath.Base(abs))
+	}
+
+	images := make(map[string][]string)
+	for _, filePath := range sortedKeys(stages) {
+		image := stages[filePath][len(stages[filePath])-1]
+		id := graph.GenerateID(graph.NodeResource, filePath, image.Name)
+
+		names := make(map[string]bool)
+		dir := filepath.Dir(filePath)
+		if dir == "." {
+			names[repoName] = true
+		} else {
+			names[strings.ToLower(filepath.Base(dir))] = true
+		}
+		base := strings.ToLower(filepath.Base(filePath))
+		if name, ok := strings.CutPrefix(base, "dockerfile."); ok {
+			names[name] = true
+		}
+		if name, ok := strings.CutSuffix(base, ".dockerfile"); ok {
+			names[name] = true
+		}
+		if !strings.HasPrefix(image.Name, "stage ") {
+			names[strings.ToLower(image.Name)] = true
+		}
+		for name := range names {
+			if name != "" {
+				images[name] = append(images[name], id)
+			}
+		}
+	}
+	return images
+}
+
+// imageName returns the repository name of a container image reference:
+// the last component of its path, without registry, tag or digest.
+func imageName(image string) string {
+	image, _, _ = strings.Cut(image, "@")
+	if slash := strings.LastIndexByte(image, '/'); slash >= 0 {
+		image = image[slash+1:]
+	}
+	image, _, _ = strings.Cut(image, ":")
+	return strings.ToLower(image)
+}
+
+// labelsMatch reports whether a selector selects the pods with labels.
+func labelsMatch(selector, labels map[string]string) bool {
+	if len(labels) == 0 {
+		return false
+	}
+	for key, value := range selector {
+		if labels[key] != value {
+			return false
+		}
+	}
+	return true
+}
+
+// goMains maps the directories of Go main packages, slash-separated, to
+// the IDs of their main functions.
+func goMains(g *graph.KnowledgeGraph) map[string]string {
+	mains := make(map[string]string)
+	for _, node := range g.GetNodesByLabel(graph.NodeFunction) {
+		if node.Name == "main" && node.Language == "go" {
+			mains[filepath.ToSlash(filepath.Dir(node.FilePath))] = node.ID
+		}
+	}
+	return mains
+}
+
+// goMainFor returns the ID of the main function of the package a
+// Dockerfile in dir builds, or "".
+func goMainFor(mains map[string]string, dir, pkg string) string {
+	for _, candidate := range []string{path.Clean(pkg), path.Join(dir, pkg)} {
+		if id, ok := mains[candidate]; ok {
+			return id
+		}
+	}
+	best := ""
+	for mainDir := range mains {
+		if mainDir != "." && strings.HasSuffix(pkg, "/"+mainDir) && len(mainDir) > len(best) {
+			best = mainDir
+		}
+	}
+	if best == "" {
+		return ""
+	}
+	return mains[best]
+}
+
+// sortedKeys returns the keys of a map in order.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,227 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/storage"
+)
+
+func TestProcessInfrastructure(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"cmd/api/main.go": `package main
+
+import "example.com/shop/server"
+
+func main() {
+	server.Run()
+}
+`,
+		"server/server.go": `package server
+
+func Run() {}
+`,
+		"cmd/api/Dockerfile": `FROM golang:1.22 AS build
+WORKDIR /src
+COPY . .
+RUN go build -o /out/api ./cmd/api
+
+FROM gcr.io/distroless/static
+COPY --from=build /out/api /api
+ENTRYPOINT ["/api"]
+`,
+		"deploy/api.yaml": `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: shop
+spec:
+  template:
+    metadata:
+      labels:
+        app: api
+    spec:
+      containers:
+        - name: api
+          image: ghcr.io/acme/api:1.0
+          envFrom:
+            - configMapRef:
+                name: api-config
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: shop
+spec:
+  selector:
+    app: api
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: api-config
+  namespace: shop
+data:
+  LISTEN: ":8080"
+`,
+		"deploy/billing.yaml": `apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: billing
+spec:
+  selector:
+    app: api
+`,
+		"infra/main.tf": `variable "cidr" {
+  type = string
+}
+
+module "network" {
+  source = "./modules/network"
+  cidr   = var.cidr
+}
+
+output "vpc" {
+  value = module.network.vpc_id
+}
+`,
+		"infra/modules/network/main.tf": `variable "cidr" {
+  type = string
+}
+
+resource "aws_vpc" "main" {
+  cidr_block = var.cidr
+}
+
+output "vpc_id" {
+  value = aws_vpc.main.id
+}
+`,
+	})
+
+	g, _, err := RunPipeline(context.Background(), dir, nil, false, nil, false, 0)
+	require.NoError(t, err)
+
+	deployFile := filepath.Join("deploy", "api.yaml")
+	dockerfile := filepath.Join("cmd", "api", "Dockerfile")
+	infraFile := filepath.Join("infra", "main.tf")
+	networkFile := filepath.Join("infra", "modules", "network", "main.tf")
+
+	service := graph.GenerateID(graph.NodeResource, deployFile, "Service/api")
+	deployment := graph.GenerateID(graph.NodeResource, deployFile, "Deployment/api")
+	build := graph.GenerateID(graph.NodeResource, dockerfile, "build")
+	image := graph.GenerateID(graph.NodeResource, dockerfile, "stage 1")
+	mainFunc := graph.GenerateID(graph.NodeFunction, filepath.Join("cmd", "api", "main.go"), "main")
+
+	references := func(id string) []string {
+		var targets []string
+		for _, rel := range g.GetOutgoing(id, graph.RelReferences) {
+			targets = append(targets, rel.Target)
+		}
+		return targets
+	}
+
+	t.Run("CreatesResources", func(t *testing.T) {
+		node := g.GetNode(deployment)
+		require.NotNil(t, node)
+		assert.Equal(t, graph.NodeResource, node.Label)
+		assert.Equal(t, "Deployment api", node.Signature)
+		assert.Equal(t, "kubernetes", node.Language)
+		assert.Equal(t, "Deployment", node.Properties["kind"])
+		assert.Equal(t, "kubernetes", node.Properties["platform"])
+		assert.True(t, g.HasIncoming(deployment, graph.RelDefines))
+		assert.False(t, node.IsDead, "resources are not dead code")
+
+		stage := g.GetNode(build)
+		require.NotNil(t, stage)
+		assert.Equal(t, "FROM golang:1.22 AS build", stage.Signature)
+		assert.Equal(t, "dockerfile", stage.Language)
+
+		vpc := g.GetNode(graph.GenerateID(graph.NodeResource, networkFile, "aws_vpc.main"))
+		require.NotNil(t, vpc)
+		assert.Equal(t, `resource "aws_vpc" "main"`, vpc.Signature)
+		assert.Equal(t, "aws_vpc", vpc.Properties["type"])
+
+		assert.Len(t, g.GetNodesByLabel(graph.NodeResource), 12)
+	})
+
+	t.Run("LinksServiceDownToMain", func(t *testing.T) {
+		assert.Equal(t, []string{deployment}, references(service))
+		assert.ElementsMatch(t, []string{
+			graph.GenerateID(graph.NodeResource, deployFile, "ConfigMap/api-config"), image,
+		}, references(deployment))
+		assert.Equal(t, []string{build}, references(image))
+		assert.Equal(t, []string{mainFunc}, references(build))
+
+		billing := graph.GenerateID(graph.NodeResource, filepath.Join("deploy", "billing.yaml"), "Service/api")
+		assert.Empty(t, references(billing), "selectors match within the namespace")
+	})
+
+	t.Run("LinksTerraformModules", func(t *testing.T) {
+		module := graph.GenerateID(graph.NodeResource, infraFile, "module.network")
+		assert.ElementsMatch(t, []string{
+			graph.GenerateID(graph.NodeResource, infraFile, "var.cidr"),
+			graph.GenerateID(graph.NodeResource, networkFile, "var.cidr"),
+		}, references(module))
+
+		assert.ElementsMatch(t, []string{
+			module, graph.GenerateID(graph.NodeResource, networkFile, "output.vpc_id"),
+		}, references(graph.GenerateID(graph.NodeResource, infraFile, "output.vpc")))
+
+		assert.Equal(t, []string{graph.GenerateID(graph.NodeResource, networkFile, "var.cidr")},
+			references(graph.GenerateID(graph.NodeResource, networkFile, "aws_vpc.main")),
+			"addresses resolve within their module")
+	})
+
+	t.Run("TraversalReachesCode", func(t *testing.T) {
+		store := storage.NewBadgerBackend()
+		require.NoError(t, store.Initialize(t.TempDir(), false))
+		defer store.Close()
+		require.NoError(t, store.BulkLoad(t.Context(), g))
+
+		nodes, err := store.Traverse(t.Context(), service, 5, "callees")
+		require.NoError(t, err)
+		byDepth, _ := storage.GroupByTraversal(nodes)
+
+		reached := make(map[string]int)
+		for depth, nodes := range byDepth {
+			for _, node := range nodes {
+				reached[node.ID] = depth
+			}
+		}
+		assert.Equal(t, 1, reached[deployment])
+		assert.Equal(t, 4, reached[mainFunc])
+		assert.Equal(t, 5, reached[graph.GenerateID(graph.NodeFunction, filepath.Join("server", "server.go"), "Run")])
+	})
+}
+
+func TestImageName(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		image    string
+		expected string
+	}{
+		{"api", "api"},
+		{"ghcr.io/acme/api:1.2", "api"},
+		{"localhost:5000/shop/Worker", "worker"},
+		{"acme/api@sha256:abc", "api"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.image, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, imageName(tt.image))
+		})
+	}
+}
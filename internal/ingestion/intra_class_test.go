@@ -1,6 +1,7 @@
 package ingestion
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -277,7 +278,7 @@ func TestProcessCalls_IntraClassTracking(t *testing.T) {
 		})
 
 		// Process calls
-		ProcessCalls(parseData, g)
+		ProcessCalls(context.Background(), parseData, g)
 
 		// Should have CALLS relationship
 		rels := g.GetRelationshipsByType(graph.RelCalls)
@@ -325,7 +326,7 @@ func TestProcessCalls_IntraClassTracking(t *testing.T) {
 		})
 
 		// Process calls should work
-		ProcessCalls(parseData, g)
+		ProcessCalls(context.Background(), parseData, g)
 
 		// Should find the call by matching ClassName
 		rels := g.GetRelationshipsByType(graph.RelCalls)
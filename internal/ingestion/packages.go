@@ -0,0 +1,193 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// ProcessPackages creates Package and Module nodes and links them to the
+// files they contain.
+//
+// Packages are Go packages (one per directory, named by import path), Python
+// packages (directories with an __init__.py, named by dotted path), npm
+// packages (directories with a named package.json), Cargo crates (named
+// by crate name), Java/Kotlin packages (one per directory holding files
+// of the declared package), C# projects (named by assembly name), PHP
+// namespaces and Protocol Buffers packages (one per directory, like Java
+// packages). Modules are Go modules, which contain their packages.
+// ParseResult.Package is set to the qualified package name of Python,
+// TypeScript/JavaScript, Rust, Java and Kotlin files; Go files keep the name
+// from their package clause, C# files their first namespace, PHP files
+// their namespace and .proto files their package.
+func ProcessPackages(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph, repoPath string) {
+	resolver := parseData.importResolver(repoPath, g)
+
+	for filePath, result := range parseData.Files {
+		if ctx.Err() != nil {
+			return
+		}
+		fileID := graph.GenerateID(graph.NodeFile, filePath, "")
+		fileNode := g.GetNode(fileID)
+		if fileNode == nil {
+			continue
+		}
+		dir := filepath.Dir(filePath)
+
+		var pkgID string
+		switch importLanguage(filePath) {
+		case "go":
+			if result.Package == "" {
+				continue
+			}
+			// External test packages belong to the package they test
+			clause := result.Package
+			if strings.HasSuffix(filePath, "_test.go") {
+				clause = strings.TrimSuffix(clause, "_test")
+			}
+
+			goResolver := resolver.golang()
+			name := goResolver.importPath(dir)
+			if name == "" {
+				name = clause
+			}
+			pkgID = addPackageNode(g, dir, name, fileNode.Language)
+			if pkg := g.GetNode(pkgID); pkg.Properties == nil {
+				pkg.Properties = map[string]any{"package_name": clause}
+			}
+
+			if mod := goResolver.moduleFor(dir); mod != nil {
+				modID := graph.GenerateID(graph.NodeModule, mod.dir, "")
+				if g.GetNode(modID) == nil {
+					g.AddNode(&graph.GraphNode{
+						ID:       modID,
+						Label:    graph.NodeModule,
+						Name:     mod.path,
+						FilePath: mod.dir,
+						Language: fileNode.Language,
+					})
+				}
+				g.AddRelationship(&graph.GraphRelationship{
+					ID:     relationshipID(graph.RelContains, modID, pkgID),
+					Type:   graph.RelContains,
+					Source: modID,
+					Target: pkgID,
+				})
+			}
+
+		case "python":
+			name := resolver.python().packageName(dir)
+			if name == "" {
+				continue
+			}
+			result.Package = name
+			pkgID = addPackageNode(g, dir, name, fileNode.Language)
+
+		case "typescript":
+			pkg := resolver.typescript().packageFor(dir)
+			if pkg == nil {
+				continue
+			}
+			result.Package = pkg.name
+			pkgID = addPackageNode(g, pkg.dir, pkg.name, fileNode.Language)
+
+		case "rust":
+			crate := resolver.rust().crateFor(filePath)
+			if crate == nil {
+				continue
+			}
+			result.Package = crate.name
+			pkgID = addPackageNode(g, crate.dir, crate.name, fileNode.Language)
+
+		case "jvm":
+			name, dir := resolver.jvm().packageOf(filePath)
+			if name == "" {
+				continue
+			}
+			result.Package = name
+			pkgID = addPackageNode(g, dir, name, fileNode.Language)
+
+		case "csharp":
+			project := resolver.csharp().projectFor(filePath)
+			if project == nil {
+				continue
+			}
+			pkgID = addPackageNode(g, project.dir, project.name, fileNode.Language)
+
+		case "php", "proto":
+			if result.Package == "" {
+				continue
+			}
+			pkgID = addPackageNode(g, dir, result.Package, fileNode.Language)
+
+		default:
+			continue
+		}
+
+		g.AddRelationship(&graph.GraphRelationship{
+			ID:     relationshipID(graph.RelContains, pkgID, fileID),
+			Type:   graph.RelContains,
+			Source: pkgID,
+			Target: fileID,
+		})
+	}
+}
+
+// addPackageNode creates the Package node for a directory unless it exists
+// and returns its ID.
+func addPackageNode(g *graph.KnowledgeGraph, dir, name, language string) string {
+	pkgID := graph.GenerateID(graph.NodePackage, dir, "")
+	if g.GetNode(pkgID) == nil {
+		g.AddNode(&graph.GraphNode{
+			ID:       pkgID,
+			Label:    graph.NodePackage,
+			Name:     name,
+			FilePath: dir,
+			Language: language,
+		})
+	}
+	return pkgID
+}
+
+// aggregatePackageImports creates IMPORTS relationships between packages from
+// the IMPORTS relationships of their files. Each carries the number of
+// file-level imports it summarizes as "file_imports".
+func aggregatePackageImports(g *graph.KnowledgeGraph) {
+	// Map every file to its package
+	filePackage := make(map[string]string)
+	for _, pkg := range g.GetNodesByLabel(graph.NodePackage) {
+		for _, rel := range g.GetOutgoing(pkg.ID, graph.RelContains) {
+			filePackage[rel.Target] = pkg.ID
+		}
+	}
+
+	counts := make(map[[2]string]int)
+	for _, rel := range g.GetRelationshipsByType(graph.RelImports) {
+		source, ok := filePackage[rel.Source]
+		if !ok {
+			continue
+		}
+		target := filePackage[rel.Target]
+		if node := g.GetNode(rel.Target); node != nil && node.Label == graph.NodePackage {
+			target = rel.Target
+		}
+		if target == "" || target == source {
+			continue
+		}
+		counts[[2]string{source, target}]++
+	}
+
+	for pair, count := range counts {
+		g.AddRelationship(&graph.GraphRelationship{
+			ID:     relationshipID(graph.RelImports, pair[0], pair[1]),
+			Type:   graph.RelImports,
+			Source: pair[0],
+			Target: pair[1],
+			Properties: map[string]any{
+				"file_imports": count,
+			},
+		})
+	}
+}
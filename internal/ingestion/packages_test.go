@@ -0,0 +1,258 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// containedFiles returns the sorted paths of the files a package contains.
+func containedFiles(g *graph.KnowledgeGraph, pkgID string) []string {
+	var files []string
+	for _, rel := range g.GetOutgoing(pkgID, graph.RelContains) {
+		if node := g.GetNode(rel.Target); node != nil && node.Label == graph.NodeFile {
+			files = append(files, filepath.ToSlash(node.FilePath))
+		}
+	}
+	sort.Strings(files)
+	return files
+}
+
+func TestProcessPackages_Go(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"go.mod":                     "module example.com/shop\n\ngo 1.22\n",
+		"internal/storage/store.go":  "package storage\n\nfunc Open() {}\n",
+		"internal/storage/badger.go": "package storage\n\nfunc open() {}\n",
+		"internal/storage/x_test.go": "package storage_test\n",
+		"api/api.go": `package api
+
+import "example.com/shop/internal/storage"
+
+func Serve() { storage.Open() }
+`,
+		"api/routes.go": `package api
+
+import "example.com/shop/internal/storage"
+
+func routes() { storage.Open() }
+`,
+		"cmd/shop/main.go": `package main
+
+import (
+	"example.com/shop/api"
+	"example.com/shop/internal/storage"
+)
+
+func main() { storage.Open(); api.Serve() }
+`,
+	})
+
+	g, _, err := RunPipeline(t.Context(), dir, nil, false, nil, false, 0)
+	require.NoError(t, err)
+
+	storageID := graph.GenerateID(graph.NodePackage, filepath.Join("internal", "storage"), "")
+	apiID := graph.GenerateID(graph.NodePackage, "api", "")
+	mainID := graph.GenerateID(graph.NodePackage, filepath.Join("cmd", "shop"), "")
+
+	t.Run("PackageNodes", func(t *testing.T) {
+		pkg := g.GetNode(storageID)
+		require.NotNil(t, pkg)
+		assert.Equal(t, "example.com/shop/internal/storage", pkg.Name)
+		assert.Equal(t, "storage", pkg.Properties["package_name"])
+		assert.ElementsMatch(t, []string{
+			"internal/storage/store.go",
+			"internal/storage/badger.go",
+			"internal/storage/x_test.go",
+		}, containedFiles(g, storageID))
+	})
+
+	t.Run("ModuleNode", func(t *testing.T) {
+		modID := graph.GenerateID(graph.NodeModule, ".", "")
+		mod := g.GetNode(modID)
+		require.NotNil(t, mod)
+		assert.Equal(t, "example.com/shop", mod.Name)
+
+		var pkgs []string
+		for _, rel := range g.GetOutgoing(modID, graph.RelContains) {
+			pkgs = append(pkgs, rel.Target)
+		}
+		assert.ElementsMatch(t, []string{storageID, apiID, mainID}, pkgs)
+	})
+
+	t.Run("PackageImports", func(t *testing.T) {
+		importers := make(map[string]any)
+		for _, rel := range g.GetIncoming(storageID, graph.RelImports) {
+			if g.GetNode(rel.Source).Label == graph.NodePackage {
+				importers[rel.Source] = rel.Properties["file_imports"]
+			}
+		}
+		assert.Equal(t, map[string]any{apiID: 2, mainID: 1}, importers)
+	})
+}
+
+func TestProcessPackages_PythonAndNPM(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"src/shop/__init__.py":     "",
+		"src/shop/api/__init__.py": "",
+		"src/shop/api/views.py":    "from shop.models import Order\n",
+		"src/shop/models.py":       "class Order:\n    pass\n",
+		"scripts/run.py":           "import shop\n",
+
+		"packages/core/package.json": `{"name": "@acme/core", "main": "src/index.ts"}`,
+		"packages/core/src/index.ts": "export function format() {}\n",
+		"apps/web/package.json":      `{"name": "web"}`,
+		"apps/web/src/main.ts":       "import { format } from '@acme/core';\n",
+	})
+
+	g, parseData := runTestPipeline(t, dir)
+
+	t.Run("PythonPackages", func(t *testing.T) {
+		apiID := graph.GenerateID(graph.NodePackage, filepath.Join("src", "shop", "api"), "")
+		pkg := g.GetNode(apiID)
+		require.NotNil(t, pkg)
+		assert.Equal(t, "shop.api", pkg.Name)
+		assert.Equal(t, "shop.api", parseData.Files[filepath.Join("src", "shop", "api", "views.py")].Package)
+
+		// Scripts outside a package have none
+		assert.Empty(t, parseData.Files[filepath.Join("scripts", "run.py")].Package)
+
+		shopID := graph.GenerateID(graph.NodePackage, filepath.Join("src", "shop"), "")
+		rels := g.GetOutgoing(apiID, graph.RelImports)
+		require.Len(t, rels, 1)
+		assert.Equal(t, shopID, rels[0].Target)
+	})
+
+	t.Run("NPMPackages", func(t *testing.T) {
+		coreID := graph.GenerateID(graph.NodePackage, filepath.Join("packages", "core"), "")
+		webID := graph.GenerateID(graph.NodePackage, filepath.Join("apps", "web"), "")
+		require.NotNil(t, g.GetNode(coreID))
+		assert.Equal(t, "@acme/core", g.GetNode(coreID).Name)
+		assert.Equal(t, []string{"packages/core/src/index.ts"}, containedFiles(g, coreID))
+
+		rels := g.GetOutgoing(webID, graph.RelImports)
+		require.Len(t, rels, 1)
+		assert.Equal(t, coreID, rels[0].Target)
+	})
+}
+
+func TestProcessPackages_Cargo(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"Cargo.toml":                  "[workspace]\nmembers = [\"core\", \"cli\"]\n",
+		"core/Cargo.toml":             "[package]\nname = \"shop-core\"\n",
+		"core/src/lib.rs":             "pub mod model;\n",
+		"core/src/model.rs":           "pub struct Order;\n",
+		"cli/Cargo.toml":              "[package]\nname = \"cli\"\n\n[dependencies]\nshop-core = { path = \"../core\" }\n",
+		"cli/src/main.rs":             "use shop_core::model::Order;\n\nfn main() {}\n",
+		"tools/gen/src/standalone.rs": "fn main() {}\n",
+	})
+
+	g, parseData := runTestPipeline(t, dir)
+
+	coreID := graph.GenerateID(graph.NodePackage, "core", "")
+	cliID := graph.GenerateID(graph.NodePackage, "cli", "")
+	require.NotNil(t, g.GetNode(coreID))
+	assert.Equal(t, "shop_core", g.GetNode(coreID).Name)
+	assert.Equal(t, []string{"core/src/lib.rs", "core/src/model.rs"}, containedFiles(g, coreID))
+	assert.Equal(t, "shop_core", parseData.Files[filepath.Join("core", "src", "model.rs")].Package)
+
+	// Files outside a crate have no package
+	assert.Empty(t, parseData.Files[filepath.Join("tools", "gen", "src", "standalone.rs")].Package)
+
+	rels := g.GetOutgoing(cliID, graph.RelImports)
+	require.Len(t, rels, 1)
+	assert.Equal(t, coreID, rels[0].Target)
+}
+
+func TestProcessPackages_JVM(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"src/main/java/com/shop/model/Order.java":  "package com.shop.model;\n\npublic class Order {}\n",
+		"src/main/java/com/shop/model/Status.java": "package com.shop.model;\n\npublic enum Status { OPEN }\n",
+		"src/main/kotlin/com/shop/api/Routes.kt":   "package com.shop.api\n\nimport com.shop.model.Order\n\nfun routes(order: Order) {}\n",
+		"src/main/java/Default.java":               "class Default {}\n",
+	})
+
+	g, parseData := runTestPipeline(t, dir)
+
+	modelDir := filepath.Join("src", "main", "java", "com", "shop", "model")
+	modelID := graph.GenerateID(graph.NodePackage, modelDir, "")
+	apiID := graph.GenerateID(graph.NodePackage, filepath.Join("src", "main", "kotlin", "com", "shop", "api"), "")
+	require.NotNil(t, g.GetNode(modelID))
+	assert.Equal(t, "com.shop.model", g.GetNode(modelID).Name)
+	assert.ElementsMatch(t, []string{"src/main/java/com/shop/model/Order.java", "src/main/java/com/shop/model/Status.java"}, containedFiles(g, modelID))
+	assert.Equal(t, "com.shop.api", parseData.Files[filepath.Join("src", "main", "kotlin", "com", "shop", "api", "Routes.kt")].Package)
+
+	// Files in the default package have no package
+	assert.Empty(t, parseData.Files[filepath.Join("src", "main", "java", "Default.java")].Package)
+
+	rels := g.GetOutgoing(apiID, graph.RelImports)
+	require.Len(t, rels, 1)
+	assert.Equal(t, modelID, rels[0].Target)
+}
+
+func TestProcessPackages_CSharp(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"Shop.Api/Shop.Api.csproj": `<Project Sdk="Microsoft.NET.Sdk.Web">
+  <ItemGroup>
+    <ProjectReference Include="..\Shop.Core\Shop.Core.csproj" />
+    <ProjectReference Include="..\Shop.Data\Shop.Data.csproj" />
+  </ItemGroup>
+</Project>`,
+		"Shop.Api/Program.cs":        "using Shop.Core;\n\nvar service = new OrderService();\n",
+		"Shop.Core/Shop.Core.csproj": `<Project Sdk="Microsoft.NET.Sdk" />`,
+		"Shop.Core/OrderService.cs":  "namespace Shop.Core;\n\npublic class OrderService {}\n",
+		"Shop.Core/obj/Generated.cs": "namespace Shop.Core;\n\npublic class Generated {}\n",
+		"Shop.Data/Shop.Data.csproj": `<Project Sdk="Microsoft.NET.Sdk" />`,
+		"Shop.Data/Repository.cs":    "namespace Shop.Data.Storage;\n\npublic class Repository {}\n",
+	})
+
+	g, parseData := runTestPipeline(t, dir)
+
+	apiID := graph.GenerateID(graph.NodePackage, "Shop.Api", "")
+	coreID := graph.GenerateID(graph.NodePackage, "Shop.Core", "")
+	dataID := graph.GenerateID(graph.NodePackage, "Shop.Data", "")
+	require.NotNil(t, g.GetNode(coreID))
+	assert.Equal(t, "Shop.Core", g.GetNode(coreID).Name)
+	assert.Equal(t, []string{"Shop.Core/OrderService.cs"}, containedFiles(g, coreID), "build output is not indexed")
+	assert.Equal(t, "Shop.Data.Storage", parseData.Files[filepath.Join("Shop.Data", "Repository.cs")].Package, "C# files keep their namespace")
+
+	imports := make(map[string]*graph.GraphRelationship)
+	for _, rel := range g.GetOutgoing(apiID, graph.RelImports) {
+		imports[rel.Target] = rel
+	}
+	require.Len(t, imports, 2)
+	assert.Equal(t, 1, imports[coreID].Properties["file_imports"])
+	assert.Equal(t, true, imports[coreID].Properties["project_reference"])
+	assert.Equal(t, 0, imports[dataID].Properties["file_imports"], "project references link projects without imports")
+	assert.Equal(t, true, imports[dataID].Properties["project_reference"])
+}
+
+// runTestPipeline runs the file-local phases on a repository and returns the
+// parse results along with the graph.
+func runTestPipeline(t *testing.T, dir string) (*graph.KnowledgeGraph, *ParseData) {
+	t.Helper()
+
+	entries, err := WalkRepo(context.Background(), dir, nil)
+	require.NoError(t, err)
+	g := graph.NewKnowledgeGraph()
+	ProcessStructure(context.Background(), entries, g)
+	parseData := ProcessParsing(context.Background(), entries, g)
+	ProcessPackages(context.Background(), parseData, g, dir)
+	ProcessImports(context.Background(), parseData, g, dir)
+	return g, parseData
+}
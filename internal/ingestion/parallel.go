@@ -0,0 +1,87 @@
+package ingestion
+
+import (
+	"context"
+	"runtime"
+	"sync"
+)
+
+// workerCount returns the number of goroutines to use for n items:
+// concurrency, or GOMAXPROCS if it is not positive, but never more than n.
+func workerCount(concurrency, n int) int {
+	if concurrency <= 0 {
+		concurrency = runtime.GOMAXPROCS(0)
+	}
+	return max(1, min(concurrency, n))
+}
+
+// parseEach calls parse for every entry with a pool of concurrency workers
+// (see workerCount) and returns the results in the order of entries, so
+// that the graph built from them does not depend on scheduling. progress,
+// if not nil, is called after each file with the number of files done,
+// always from the calling goroutine.
+//
+// If ctx is canceled no further entries are handed to the workers; the
+// results of the entries that were not parsed are zero values.
+func parseEach[T any](ctx context.Context, entries []FileEntry, concurrency int, parse func(FileEntry) T, progress func(done, total int)) []T {
+	results := make([]T, len(entries))
+	if len(entries) == 0 {
+		return results
+	}
+
+	indexes := make(chan int)
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+	for range workerCount(concurrency, len(entries)) {
+		wg.Go(func() {
+			for i := range indexes {
+				results[i] = parse(entries[i])
+				done <- struct{}{}
+			}
+		})
+	}
+	go func() {
+	dispatch:
+		for i := range entries {
+			select {
+			case indexes <- i:
+			case <-ctx.Done():
+				break dispatch
+			}
+		}
+		close(indexes)
+		wg.Wait()
+		close(done)
+	}()
+
+	finished := 0
+	for range done {
+		finished++
+		if progress != nil {
+			progress(finished, len(entries))
+		}
+	}
+	return results
+}
+
+// runConcurrently runs the given phases in parallel and waits for them.
+// progress, if not nil, is called after each phase with the fraction of
+// phases done, always from the calling goroutine.
+//
+// The phases must not depend on each other's output: each may only read
+// what earlier phases created and write relationships of its own types.
+func runConcurrently(progress func(float64), phases ...func()) {
+	done := make(chan struct{})
+	for _, phase := range phases {
+		go func() {
+			phase()
+			done <- struct{}{}
+		}()
+	}
+	for i := range phases {
+		<-done
+		if progress != nil {
+			progress(float64(i+1) / float64(len(phases)))
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package ingestion
+
+import (
+	"context"
+	"fmt"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestParseEach(t *testing.T) {
+	t.Parallel()
+
+	entries := make([]FileEntry, 100)
+	for i := range entries {
+		entries[i] = FileEntry{RelPath: fmt.Sprintf("file%d.go", i)}
+	}
+
+	var running, peak atomic.Int32
+	var progress []int
+	results := parseEach(context.Background(), entries, 4, func(entry FileEntry) string {
+		n := running.Add(1)
+		for {
+			p := peak.Load()
+			if n <= p || peak.CompareAndSwap(p, n) {
+				break
+			}
+		}
+		defer running.Add(-1)
+		return entry.RelPath
+	}, func(done, total int) {
+		assert.Equal(t, len(entries), total)
+		progress = append(progress, done)
+	})
+
+	for i, result := range results {
+		assert.Equal(t, entries[i].RelPath, result, "results are in the order of the entries")
+	}
+	assert.LessOrEqual(t, peak.Load(), int32(4))
+	require.Len(t, progress, len(entries), "progress is reported per file")
+	assert.IsIncreasing(t, progress)
+	assert.Equal(t, len(entries), progress[len(progress)-1])
+}
+
+func TestParseEach_Canceled(t *testing.T) {
+	t.Parallel()
+
+	entries := make([]FileEntry, 100)
+	for i := range entries {
+		entries[i] = FileEntry{RelPath: fmt.Sprintf("file%d.go", i)}
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var parsed atomic.Int32
+	results := parseEach(ctx, entries, 2, func(entry FileEntry) string {
+		if parsed.Add(1) == 10 {
+			cancel()
+		}
+		return entry.RelPath
+	}, nil)
+
+	require.Len(t, results, len(entries))
+	assert.Less(t, parsed.Load(), int32(len(entries)), "no entries are handed out after cancellation")
+	assert.Empty(t, results[len(results)-1])
+}
+
+func TestProcessParsingIncremental_Deterministic(t *testing.T) {
+	t.Parallel()
+
+	var entries []FileEntry
+	for i := range 50 {
+		entries = append(entries, FileEntry{
+			RelPath:  fmt.Sprintf("pkg%d/file%d.go", i%5, i),
+			Language: "go",
+			Content:  fmt.Appendf(nil, "package pkg\n\nfunc F%d() {}\n\ntype T%d struct{}\n", i, i),
+		})
+	}
+
+	nodeIDs := func(concurrency int) ([]string, []string) {
+		g := graph.NewKnowledgeGraph()
+		parseData, records, parsed := ProcessParsingIncremental(context.Background(), entries, g, nil, concurrency, nil)
+		require.Equal(t, len(entries), parsed)
+		require.Len(t, parseData.Files, len(entries))
+
+		var paths []string
+		for _, record := range records {
+			paths = append(paths, record.Path)
+		}
+		var ids []string
+		for node := range g.IterNodes() {
+			ids = append(ids, node.ID)
+		}
+		return paths, ids
+	}
+
+	sequentialPaths, sequentialIDs := nodeIDs(1)
+	parallelPaths, parallelIDs := nodeIDs(8)
+	assert.Equal(t, sequentialPaths, parallelPaths, "records are in the order of the entries")
+	assert.ElementsMatch(t, sequentialIDs, parallelIDs)
+	assert.Len(t, sequentialIDs, 100)
+}
@@ -0,0 +1,116 @@
+package ingestion
+
+import (
+	"context"
+	"slices"
+	"sort"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// ProcessPartialTypes merges the parts of C# partial types declared across
+// files into one node and returns the number of merged types.
+//
+// Parts belong to the same type when they share a name, a label, a
+// namespace and the project (Package node) containing their files. The
+// part in the first file by path is kept; the relationships of the others
+// are moved to it and they are removed. The kept node is exported and has
+// the attributes of any part, and lists the files of all parts as
+// "partial_files".
+func ProcessPartialTypes(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph) int {
+	type partialKey struct {
+		pkg, namespace, name string
+		label                graph.NodeLabel
+	}
+	parts := make(map[partialKey][]*graph.GraphNode)
+	for filePath, result := range parseData.Files {
+		for _, sym := range result.Symbols {
+			if !sym.IsPartial {
+				continue
+			}
+			label := symbolLabel(sym.Kind)
+			node := g.GetNode(graph.GenerateID(label, filePath, symbolNodeName(sym.Name, sym.ClassName, label)))
+			if node == nil {
+				continue
+			}
+			key := partialKey{
+				pkg:       filePackageID(g, filePath),
+				namespace: result.Package,
+				name:      symbolNodeName(sym.Name, sym.ClassName, label),
+				label:     label,
+			}
+			parts[key] = append(parts[key], node)
+		}
+	}
+
+	merged := 0
+	for _, nodes := range parts {
+		if len(nodes) < 2 {
+			continue
+		}
+		sort.Slice(nodes, func(i, j int) bool { return nodes[i].FilePath < nodes[j].FilePath })
+		kept := nodes[0]
+		files := []string{kept.FilePath}
+		for _, part := range nodes[1:] {
+			// Modifiers and attributes may be given on any part
+			kept.IsExported = kept.IsExported || part.IsExported
+			for _, decorator := range part.Decorators {
+				if !slices.Contains(kept.Decorators, decorator) {
+					kept.Decorators = append(kept.Decorators, decorator)
+				}
+			}
+			moveRelationships(g, part.ID, kept.ID)
+			g.RemoveNode(part.ID)
+			files = append(files, part.FilePath)
+		}
+		if kept.Properties == nil {
+			kept.Properties = make(map[string]any)
+		}
+		kept.Properties["partial_files"] = files
+		merged++
+	}
+	return merged
+}
+
+// filePackageID returns the ID of the Package node containing a file, or
+// "".
+func filePackageID(g *graph.KnowledgeGraph, filePath string) string {
+	for _, rel := range g.GetIncoming(graph.GenerateID(graph.NodeFile, filePath, ""), graph.RelContains) {
+		if node := g.GetNode(rel.Source); node != nil && node.Label == graph.NodePackage {
+			return node.ID
+		}
+	}
+	return ""
+}
+
+// moveRelationships gives the relationships of the node from to the node
+// to. Relationships between the two nodes, and of the node from to
+// itself, are dropped.
+func moveRelationships(g *graph.KnowledgeGraph, from, to string) {
+	var moved []*graph.GraphRelationship
+	for _, rel := range g.GetOutgoing(from) {
+		if rel.Target != to && rel.Target != from {
+			moved = append(moved, &graph.GraphRelationship{
+				ID:         relationshipID(rel.Type, to, rel.Target),
+				Type:       rel.Type,
+				Source:     to,
+				Target:     rel.Target,
+				Properties: rel.Properties,
+			})
+		}
+	}
+	for _, rel := range g.GetIncoming(from) {
+		if rel.Source != to && rel.Source != from {
+			moved = append(moved, &graph.GraphRelationship{
+				ID:         relationshipID(rel.Type, rel.Source, to),
+				Type:       rel.Type,
+				Source:     rel.Source,
+				Target:     to,
+				Properties: rel.Properties,
+			})
+		}
+	}
+	for _, rel := range moved {
+		g.AddRelationship(rel)
+	}
+}
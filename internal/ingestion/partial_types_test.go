@@ -0,0 +1,78 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestProcessPartialTypes(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"Shop/Shop.csproj": `<Project Sdk="Microsoft.NET.Sdk" />`,
+		"Shop/OrderService.cs": `namespace Shop;
+
+[Serializable]
+public partial class OrderService : IOrderService
+{
+    public void Place() { Validate(); }
+}
+`,
+		"Shop/OrderService.Validation.cs": `namespace Shop;
+
+partial class OrderService : IDisposable
+{
+    private void Validate() {}
+    public void Dispose() {}
+}
+`,
+		"Shop/IOrderService.cs": "namespace Shop;\n\npublic interface IOrderService { void Place(); }\n",
+		"Other/Other.csproj":    `<Project Sdk="Microsoft.NET.Sdk" />`,
+		"Other/OrderService.cs": "namespace Shop;\n\npublic partial class OrderService {}\n",
+	})
+
+	g, parseData := runTestPipeline(t, dir)
+	ProcessCalls(context.Background(), parseData, g)
+	ProcessHeritage(context.Background(), parseData, g)
+
+	assert.Equal(t, 1, ProcessPartialTypes(context.Background(), parseData, g), "parts in other projects are other types")
+
+	// The part in the first file by path is kept
+	keptID := graph.GenerateID(graph.NodeClass, filepath.Join("Shop", "OrderService.Validation.cs"), "OrderService")
+	partID := graph.GenerateID(graph.NodeClass, filepath.Join("Shop", "OrderService.cs"), "OrderService")
+	kept := g.GetNode(keptID)
+	require.NotNil(t, kept)
+	assert.Nil(t, g.GetNode(partID))
+	assert.Equal(t, []string{"Shop/OrderService.Validation.cs", "Shop/OrderService.cs"}, toSlash(kept.Properties["partial_files"].([]string)))
+
+	var implemented []string
+	for _, rel := range g.GetOutgoing(keptID, graph.RelImplements) {
+		implemented = append(implemented, g.GetNode(rel.Target).Name)
+	}
+	assert.ElementsMatch(t, []string{"IOrderService"}, implemented, "heritage of all parts moves to the merged type")
+	assert.Equal(t, []string{"Serializable"}, kept.Decorators, "attributes of all parts")
+	assert.True(t, kept.IsExported, "modifiers of all parts")
+
+	definedBy := make(map[string]bool)
+	for _, rel := range g.GetIncoming(keptID, graph.RelDefines) {
+		definedBy[g.GetNode(rel.Source).FilePath] = true
+	}
+	assert.Len(t, definedBy, 2, "both files define the merged type")
+
+	assert.NotNil(t, g.GetNode(graph.GenerateID(graph.NodeClass, filepath.Join("Other", "OrderService.cs"), "OrderService")))
+}
+
+// toSlash converts paths to forward slashes.
+func toSlash(paths []string) []string {
+	slashed := make([]string, len(paths))
+	for i, path := range paths {
+		slashed[i] = filepath.ToSlash(path)
+	}
+	return slashed
+}
@@ -3,7 +3,9 @@ package ingestion
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"os"
 	"path/filepath"
 	"strings"
 	"sync"
@@ -18,6 +20,18 @@ import (
 type ParseData struct {
 	mu    sync.RWMutex
 	Files map[string]*parsers.ParseResult
+
+	// GoImplementations are the interface implementations of the
+	// type-checked Go packages, if the repository is a Go module that
+	// could be loaded (see ProcessGoTypes).
+	GoImplementations []parsers.Implementation
+
+	// goTyped holds the Go files resolved by type checking, and is nil if
+	// the packages were not loaded
+	goTyped map[string]bool
+
+	// resolver is shared by ProcessPackages and ProcessImports
+	resolver *importResolver
 }
 
 // NewParseData creates a new ParseData instance.
@@ -34,9 +48,20 @@ func (p *ParseData) AddFile(relPath string, result *parsers.ParseResult) {
 	p.Files[relPath] = result
 }
 
+// importResolver returns the import resolver for the files in g, creating it
+// on first use.
+func (p *ParseData) importResolver(repoPath string, g *graph.KnowledgeGraph) *importResolver {
+	if p.resolver == nil {
+		p.resolver = newImportResolver(repoPath, g)
+	}
+	return p.resolver
+}
+
 // PipelineResult summarizes a pipeline run.
 type PipelineResult struct {
 	Files         int
+	ParsedFiles   int
+	TypeChecked   bool
 	Symbols       int
 	Relationships int
 	DeadCode      int
@@ -44,10 +69,77 @@ type PipelineResult struct {
 	DurationSecs  float64
 }
 
+// CanceledError is returned by RunPipeline when its context is canceled or
+// its deadline passes. The store is left as it was before the run.
+type CanceledError struct {
+	// Phase is the progress name of the phase that was stopped
+	Phase string
+
+	// Done and Total count the files (or the Unit) the phase had
+	// processed and had to process, if it processes them one by one
+	Done  int
+	Total int
+	Unit  string
+
+	// Err is the context's error
+	Err error
+}
+
+func (e *CanceledError) Error() string {
+	if e.Total > 0 {
+		unit := e.Unit
+		if unit == "" {
+			unit = "files"
+		}
+		return fmt.Sprintf("%s stopped after %d of %d %s: %v", e.Phase, e.Done, e.Total, unit, e.Err)
+	}
+	return fmt.Sprintf("%s stopped: %v", e.Phase, e.Err)
+}
+
+func (e *CanceledError) Unwrap() error {
+	return e.Err
+}
+
+// canceledIn returns a CanceledError for phase if ctx is done.
+func canceledIn(ctx context.Context, phase string) error {
+	if ctx.Err() == nil {
+		return nil
+	}
+	return &CanceledError{Phase: phase, Err: ctx.Err()}
+}
+
 // ProgressCallback is called with phase name and progress (0.0-1.0).
 type ProgressCallback func(phase string, progress float64)
 
+// Confidence values attached to CALLS relationships.
+const (
+	// typedCallConfidence is used for calls resolved through go/types.
+	typedCallConfidence = 1.0
+
+	// heuristicCallConfidence is used for calls resolved by name.
+	heuristicCallConfidence = 0.8
+
+	// dispatchConfidence is attached to the IMPLEMENTS relationships between
+	// a concrete method and the interface method it implements: a call
+	// through the interface may reach any implementation.
+	dispatchConfidence = 0.7
+)
+
 // RunPipeline runs the full ingestion pipeline.
+//
+// Files are parsed by concurrency goroutines (GOMAXPROCS if not positive),
+// and the independent resolution phases run in parallel.
+//
+// When the store holds the file records of an earlier run and full is
+// false, only the files whose content hash changed are parsed; the others
+// reuse their stored parse result. The graph is still built and analyzed as
+// a whole, so the global phases see the merged graph, and the store is
+// updated with the nodes and relationships that changed.
+//
+// Every phase stops early when ctx is canceled, and RunPipeline then
+// returns a *CanceledError naming the phase. The store is only written once
+// all phases are done, and a write that has started is completed, so a
+// canceled run never leaves it half-written.
 func RunPipeline(
 	ctx context.Context,
 	repoPath string,
@@ -55,6 +147,24 @@ func RunPipeline(
 	full bool,
 	progress ProgressCallback,
 	embeddings bool,
+	concurrency int,
+) (*graph.KnowledgeGraph, *PipelineResult, error) {
+	return runPipeline(ctx, repoPath, store, full, progress, embeddings, concurrency, nil)
+}
+
+// runPipeline is RunPipeline, which takes the results of the global phases
+// that analyze the whole graph (execution flows, communities and git
+// coupling) over from globals, the graph of an earlier run, instead of
+// running them if globals is not nil.
+func runPipeline(
+	ctx context.Context,
+	repoPath string,
+	store storage.StorageBackend,
+	full bool,
+	progress ProgressCallback,
+	embeddings bool,
+	concurrency int,
+	globals *graph.KnowledgeGraph,
 ) (*graph.KnowledgeGraph, *PipelineResult, error) {
 	result := &PipelineResult{}
 
@@ -64,7 +174,10 @@ func RunPipeline(
 
 	// Phase 1: File walking
 	patterns, _ := loadGitignore(repoPath)
-	entries, err := WalkRepo(repoPath, patterns)
+	entries, err := WalkRepo(ctx, repoPath, patterns)
+	if err := canceledIn(ctx, "Walking files"); err != nil {
+		return nil, nil, err
+	}
 	if err != nil {
 		return nil, nil, fmt.Errorf("walking repo: %w", err)
 	}
@@ -80,92 +193,151 @@ func RunPipeline(
 	if progress != nil {
 		progress("Processing structure", 0.0)
 	}
-	ProcessStructure(entries, g)
+	ProcessStructure(ctx, entries, g)
+	if err := canceledIn(ctx, "Processing structure"); err != nil {
+		return nil, nil, err
+	}
 	if progress != nil {
 		progress("Processing structure", 1.0)
 	}
 
-	// Phase 3: Parsing
+	// Phase 3: Parsing (only the changed files, unless full is set)
 	if progress != nil {
 		progress("Parsing code", 0.0)
 	}
-	parseData := ProcessParsing(entries, g)
-	if progress != nil {
-		progress("Parsing code", 1.0)
+	var previous map[string]storage.FileRecord
+	if store != nil && !full {
+		// Without records every file is parsed
+		previous, _ = store.FileRecords(ctx)
 	}
-
-	// Phase 4: Imports
-	if progress != nil {
-		progress("Resolving imports", 0.0)
+	filesDone := 0
+	parseProgress := func(done, total int) {
+		filesDone = done
+		if progress != nil {
+			progress("Parsing code", float64(done)/float64(total))
+		}
 	}
-	ProcessImports(parseData, g)
-	if progress != nil {
-		progress("Resolving imports", 1.0)
+	parseData, records, parsed := ProcessParsingIncremental(ctx, entries, g, previous, concurrency, parseProgress)
+	if ctx.Err() != nil {
+		return nil, nil, &CanceledError{Phase: "Parsing code", Done: filesDone, Total: len(entries), Err: ctx.Err()}
 	}
+	result.ParsedFiles = parsed
 
-	// Phase 5: Calls
+	// Phase 3b: Go type checking (falls back to name-based resolution)
 	if progress != nil {
-		progress("Tracing calls", 0.0)
+		progress("Type-checking Go packages", 0.0)
 	}
-	ProcessCalls(parseData, g)
-	if progress != nil {
-		progress("Tracing calls", 1.0)
+	result.TypeChecked = ProcessGoTypesIncremental(ctx, repoPath, parseData, records, previous)
+	if err := canceledIn(ctx, "Type-checking Go packages"); err != nil {
+		return nil, nil, err
 	}
-
-	// Phase 6: Heritage
 	if progress != nil {
-		progress("Extracting heritage", 0.0)
-	}
-	ProcessHeritage(parseData, g)
-	if progress != nil {
-		progress("Extracting heritage", 1.0)
+		progress("Type-checking Go packages", 1.0)
 	}
 
-	// Phase 7: Types
-	if progress != nil {
-		progress("Analyzing types", 0.0)
-	}
-	ProcessTypes(parseData, g)
+	// Phase 3c: Packages and modules
 	if progress != nil {
-		progress("Analyzing types", 1.0)
+		progress("Detecting packages", 0.0)
 	}
-
-	// Phase 9: Process/Flow Detection
-	if progress != nil {
-		progress("Detecting execution flows", 0.0)
+	ProcessPackages(ctx, parseData, g, repoPath)
+	if err := canceledIn(ctx, "Detecting packages"); err != nil {
+		return nil, nil, err
 	}
-	processCount := ProcessProcesses(g)
-	_ = processCount // Could add to result if needed
 	if progress != nil {
-		progress("Detecting execution flows", 1.0)
+		progress("Detecting packages", 1.0)
 	}
 
-	// Phase 8: Community Detection
+	// Phases 4-7: Imports, calls, heritage and types. Each group only reads
+	// the symbols created by parsing and the relationships it creates
+	// itself, so the groups run in parallel.
 	if progress != nil {
-		progress("Detecting communities", 0.0)
+		progress("Resolving symbols", 0.0)
 	}
-	communityCount := DetectCommunities(g)
-	_ = communityCount // Could add to result if needed
+	var resolveProgress func(float64)
 	if progress != nil {
-		progress("Detecting communities", 1.0)
+		resolveProgress = func(done float64) { progress("Resolving symbols", done) }
 	}
-
-	// Phase 11: Git Coupling Analysis
-	if progress != nil {
-		progress("Analyzing git history", 0.0)
+	runConcurrently(resolveProgress,
+		func() {
+			ProcessImports(ctx, parseData, g, repoPath)
+			ProcessDeclarations(ctx, g)
+		},
+		func() {
+			ProcessCalls(ctx, parseData, g)
+			ProcessVariables(ctx, parseData, g)
+			ProcessRoutes(ctx, parseData, g, repoPath)
+			ProcessTables(ctx, parseData, g)
+			ProcessInfrastructure(ctx, parseData, g, repoPath)
+			ProcessDocuments(ctx, parseData, g)
+		},
+		func() {
+			ProcessHeritage(ctx, parseData, g)
+			ProcessGoImplements(ctx, parseData, g)
+			ProcessGRPC(ctx, parseData, g)
+		},
+		func() {
+			ProcessTypes(ctx, parseData, g)
+		},
+	)
+
+	// Phase 7b: Partial types, which merges the nodes the other phases link
+	ProcessPartialTypes(ctx, parseData, g)
+	if err := canceledIn(ctx, "Resolving symbols"); err != nil {
+		return nil, nil, err
 	}
-	coupledCount := ProcessCoupling(g, repoPath)
-	result.CoupledPairs = coupledCount
-	if progress != nil {
-		progress("Analyzing git history", 1.0)
+
+	if globals != nil {
+		result.CoupledPairs = reuseGlobalResults(globals, g)
+	} else {
+		// Phase 9: Process/Flow Detection
+		if progress != nil {
+			progress("Detecting execution flows", 0.0)
+		}
+		processCount := ProcessProcesses(ctx, g)
+		_ = processCount // Could add to result if needed
+		if err := canceledIn(ctx, "Detecting execution flows"); err != nil {
+			return nil, nil, err
+		}
+		if progress != nil {
+			progress("Detecting execution flows", 1.0)
+		}
+
+		// Phase 8: Community Detection
+		if progress != nil {
+			progress("Detecting communities", 0.0)
+		}
+		communityCount := DetectCommunities(ctx, g)
+		_ = communityCount // Could add to result if needed
+		if err := canceledIn(ctx, "Detecting communities"); err != nil {
+			return nil, nil, err
+		}
+		if progress != nil {
+			progress("Detecting communities", 1.0)
+		}
+
+		// Phase 11: Git Coupling Analysis
+		if progress != nil {
+			progress("Analyzing git history", 0.0)
+		}
+		coupledCount := ProcessCoupling(ctx, g, repoPath)
+		result.CoupledPairs = coupledCount
+		if err := canceledIn(ctx, "Analyzing git history"); err != nil {
+			return nil, nil, err
+		}
+		if progress != nil {
+			progress("Analyzing git history", 1.0)
+		}
 	}
 
 	// Phase 10: Dead Code Detection
 	if progress != nil {
 		progress("Detecting dead code", 0.0)
 	}
-	deadCodeCount := ProcessDeadCode(g)
+	deadCodeCount := ProcessDeadCode(ctx, g)
 	result.DeadCode = deadCodeCount
+	if err := canceledIn(ctx, "Detecting dead code"); err != nil {
+		return nil, nil, err
+	}
 	if progress != nil {
 		progress("Detecting dead code", 1.0)
 	}
@@ -174,12 +346,26 @@ func RunPipeline(
 	if embeddings && progress != nil {
 		progress("Generating embeddings", 0.0)
 	}
+	var vectors []storage.NodeEmbedding
 	if embeddings {
-		if err := GenerateAndStoreEmbeddings(ctx, g, store); err != nil {
+		embedProgress := func(done, total int) {
+			if progress != nil {
+				progress("Generating embeddings", float64(done)/float64(total))
+			}
+		}
+		vectors, err = GenerateEmbeddings(ctx, g, embedProgress)
+		var canceled *CanceledError
+		if errors.As(err, &canceled) {
+			return nil, nil, canceled
+		}
+		if err != nil {
 			// Log error but don't fail the pipeline
 			fmt.Printf("Warning: embedding generation failed: %v\n", err)
 		}
 	}
+	if err := canceledIn(ctx, "Generating embeddings"); err != nil {
+		return nil, nil, err
+	}
 	if embeddings && progress != nil {
 		progress("Generating embeddings", 1.0)
 	}
@@ -188,13 +374,29 @@ func RunPipeline(
 	result.Symbols = countSymbols(g)
 	result.Relationships = g.RelationshipCount()
 
-	// Store in backend
+	// Store in backend. Once started, the writes are not canceled, so that
+	// the store is never left with only part of the graph
 	if store != nil {
 		if progress != nil {
 			progress("Loading to storage", 0.0)
 		}
-		if err := store.BulkLoad(ctx, g); err != nil {
-			return nil, nil, fmt.Errorf("bulk load: %w", err)
+		writeCtx := context.WithoutCancel(ctx)
+		if len(previous) == 0 {
+			if err := store.BulkLoad(writeCtx, g); err != nil {
+				return nil, nil, fmt.Errorf("bulk load: %w", err)
+			}
+		} else if err := store.UpdateGraph(writeCtx, g); err != nil {
+			return nil, nil, fmt.Errorf("updating graph: %w", err)
+		}
+		if len(vectors) > 0 {
+			if err := store.StoreEmbeddings(writeCtx, vectors); err != nil {
+				fmt.Printf("Warning: storing embeddings failed: %v\n", err)
+			}
+		}
+		// The records are replaced last: if the graph could not be
+		// written, the next run parses the changed files again
+		if err := store.ReplaceFileRecords(writeCtx, records); err != nil {
+			return nil, nil, fmt.Errorf("storing file records: %w", err)
 		}
 		if progress != nil {
 			progress("Loading to storage", 1.0)
@@ -204,9 +406,107 @@ func RunPipeline(
 	return g, result, nil
 }
 
+// IndexGeneration runs the pipeline into a new generation of gens and
+// publishes it, so that readers of the current generation switch to a
+// complete index at once. The new generation starts with the file records
+// of the current one, so only the changed files are parsed unless full is
+// set. If the pipeline fails or is canceled the new generation is removed
+// and the current one stays in place.
+func IndexGeneration(
+	ctx context.Context,
+	repoPath string,
+	gens *storage.Generations,
+	full bool,
+	progress ProgressCallback,
+	embeddings bool,
+	concurrency int,
+) (storage.Generation, *PipelineResult, error) {
+	gen, _, result, err := indexGeneration(ctx, repoPath, gens, full, progress, embeddings, concurrency, nil)
+	return gen, result, err
+}
+
+// generationReuse is what a generation built by WatchRepo takes over from
+// earlier runs instead of computing it again.
+type generationReuse struct {
+	// globals is the graph of an earlier run whose global phase results
+	// are reused (see runPipeline), or nil to run the global phases
+	globals *graph.KnowledgeGraph
+
+	// embeddings copies the embeddings of the current generation for the
+	// nodes that are still indexed
+	embeddings bool
+}
+
+// indexGeneration is IndexGeneration, which also returns the graph
+// indexed and reuses what reuse names if it is not nil.
+func indexGeneration(
+	ctx context.Context,
+	repoPath string,
+	gens *storage.Generations,
+	full bool,
+	progress ProgressCallback,
+	embeddings bool,
+	concurrency int,
+	reuse *generationReuse,
+) (storage.Generation, *graph.KnowledgeGraph, *PipelineResult, error) {
+	var previous []storage.NodeEmbedding
+	if reuse != nil && reuse.embeddings {
+		current, _, err := gens.OpenCurrent()
+		if err == nil {
+			previous, err = current.Embeddings(ctx)
+			_ = current.Close()
+		}
+		if err != nil && !errors.Is(err, storage.ErrNoIndex) {
+			return storage.Generation{}, nil, nil, fmt.Errorf("reading embeddings: %w", err)
+		}
+	}
+
+	store, gen, err := gens.Begin(ctx, full)
+	if err != nil {
+		return storage.Generation{}, nil, nil, fmt.Errorf("creating generation: %w", err)
+	}
+
+	var globals *graph.KnowledgeGraph
+	if reuse != nil {
+		globals = reuse.globals
+	}
+	g, result, err := runPipeline(ctx, repoPath, store, full, progress, embeddings, concurrency, globals)
+	if err == nil && len(previous) > 0 {
+		kept := previous[:0]
+		for _, emb := range previous {
+			if g.GetNode(emb.NodeID) != nil {
+				kept = append(kept, emb)
+			}
+		}
+		if err = store.StoreEmbeddings(context.WithoutCancel(ctx), kept); err != nil {
+			err = fmt.Errorf("copying embeddings: %w", err)
+		}
+	}
+	if closeErr := store.Close(); err == nil && closeErr != nil {
+		err = fmt.Errorf("closing generation: %w", closeErr)
+	}
+	if err != nil {
+		_ = gens.Discard(gen)
+		return storage.Generation{}, nil, nil, err
+	}
+
+	gen, err = gens.Publish(gen)
+	if err != nil {
+		return storage.Generation{}, nil, nil, fmt.Errorf("publishing generation: %w", err)
+	}
+	if err := gens.Prune(); err != nil {
+		// The new generation is current; old ones are removed next time
+		fmt.Fprintf(os.Stderr, "Warning: removing old generations failed: %v\n", err)
+	}
+	return gen, g, result, nil
+}
+
 // ProcessStructure creates File and Folder nodes with CONTAINS relationships.
-func ProcessStructure(entries []FileEntry, g *graph.KnowledgeGraph) {
+func ProcessStructure(ctx context.Context, entries []FileEntry, g *graph.KnowledgeGraph) {
 	for _, entry := range entries {
+		if ctx.Err() != nil {
+			return
+		}
 		// Create file node
 		fileNode := &graph.GraphNode{
 			ID:       graph.GenerateID(graph.NodeFile, entry.RelPath, ""),
@@ -259,134 +559,200 @@ func ProcessStructure(entries []FileEntry, g *graph.KnowledgeGraph) {
 	}
 }
 
-// ProcessParsing parses all files and extracts symbols.
-func ProcessParsing(entries []FileEntry, g *graph.KnowledgeGraph) *ParseData {
+// ProcessParsing parses all files and extracts symbols. Files are parsed
+// in parallel, by GOMAXPROCS goroutines. If ctx is canceled the files not
+// reached yet are left out.
+func ProcessParsing(ctx context.Context, entries []FileEntry, g *graph.KnowledgeGraph) *ParseData {
 	parseData := NewParseData()
 
-	for _, entry := range entries {
-		parser := getParserForLanguage(entry.Language)
-		if parser == nil {
+	results := parseEach(ctx, entries, 0, parseEntry, nil)
+	for i, result := range results {
+		if result == nil {
 			continue
 		}
 
-		result, err := parser.Parse(entry.RelPath, entry.Content)
-		if err != nil {
-			continue
-		}
+		parseData.AddFile(entries[i].RelPath, result)
+		addSymbolNodes(entries[i], result, g)
+	}
 
-		parseData.AddFile(entry.RelPath, result)
-
-		// Create symbol nodes
-		for _, sym := range result.Symbols {
-			var label graph.NodeLabel
-			switch sym.Kind {
-			case graph.NodeFunction, graph.NodeMethod:
-				label = sym.Kind
-			case graph.NodeClass:
-				label = graph.NodeClass
-			case graph.NodeInterface:
-				label = graph.NodeInterface
-			case graph.NodeTypeAlias:
-				label = graph.NodeTypeAlias
-			default:
-				label = graph.NodeFunction
-			}
+	return parseData
+}
 
-			nodeID := graph.GenerateID(label, entry.RelPath, sym.Name)
-			node := &graph.GraphNode{
-				ID:         nodeID,
-				Label:      label,
-				Name:       sym.Name,
-				FilePath:   entry.RelPath,
-				StartLine:  sym.StartLine,
-				EndLine:    sym.EndLine,
-				Content:    sym.Content,
-				Signature:  sym.Signature,
-				Language:   entry.Language,
-				ClassName:  sym.ClassName,
-				IsExported: sym.IsExported,
-			}
-			g.AddNode(node)
+// parseEntry parses a file, returning nil if its language has no parser or
+// it cannot be parsed.
+func parseEntry(entry FileEntry) *parsers.ParseResult {
+	parser := getParserForLanguage(entry.Language)
+	if parser == nil {
+		return nil
+	}
+	return parseWith(parser, entry)
+}
 
-			// Create DEFINES relationship from file
-			fileID := graph.GenerateID(graph.NodeFile, entry.RelPath, "")
-			rel := &graph.GraphRelationship{
-				ID:     graph.GenerateID(graph.NodeFunction, entry.RelPath, sym.Name),
-				Type:   graph.RelDefines,
-				Source: fileID,
-				Target: nodeID,
-			}
-			g.AddRelationship(rel)
+// parseWith parses a file with parser, returning nil if it cannot be
+// parsed. A parser that panics costs the file, not the run.
+func parseWith(parser parsers.Parser, entry FileEntry) (parsed *parsers.ParseResult) {
+	defer func() {
+		if r := recover(); r != nil {
+			fmt.Fprintf(os.Stderr, "Warning: parsing %s failed: %v\n", entry.RelPath, r)
+			parsed = nil
 		}
+	}()
+
+	result, err := parser.Parse(entry.RelPath, entry.Content)
+	if err != nil {
+		return nil
 	}
+	return result
+}
 
-	return parseData
+// addSymbolNodes creates the nodes of the symbols of a parsed file, with
+// DEFINES relationships from the file.
+func addSymbolNodes(entry FileEntry, result *parsers.ParseResult, g *graph.KnowledgeGraph) {
+	// Create symbol nodes
+	for _, sym := range result.Symbols {
+		label := symbolLabel(sym.Kind)
+		nodeID := graph.GenerateID(label, entry.RelPath, symbolNodeName(sym.Name, sym.ClassName, label))
+		node := &graph.GraphNode{
+			ID:           nodeID,
+			Label:        label,
+			Name:         sym.Name,
+			FilePath:     entry.RelPath,
+			StartLine:    sym.StartLine,
+			EndLine:      sym.EndLine,
+			Content:      sym.Content,
+			Signature:    sym.Signature,
+			Doc:          sym.Doc,
+			Language:     entry.Language,
+			ClassName:    sym.ClassName,
+			IsExported:   sym.IsExported,
+			IsEntryPoint: sym.IsEntryPoint,
+			Decorators:   sym.Decorators,
+		}
+		switch {
+		case sym.IsComponent:
+			node.Properties = map[string]any{"component": true}
+		case sym.IsProperty:
+			node.Properties = map[string]any{"property": true}
+		case sym.IsPartial:
+			node.Properties = map[string]any{"partial": true}
+		case sym.IsDeclaration:
+			node.Properties = map[string]any{"declaration": true}
+		}
+		g.AddNode(node)
+
+		// Create DEFINES relationship from file
+		fileID := graph.GenerateID(graph.NodeFile, entry.RelPath, "")
+		rel := &graph.GraphRelationship{
+			ID:     graph.GenerateID(label, entry.RelPath, symbolNodeName(sym.Name, sym.ClassName, label)),
+			Type:   graph.RelDefines,
+			Source: fileID,
+			Target: nodeID,
+		}
+		g.AddRelationship(rel)
+	}
 }
 
-// ProcessImports creates IMPORTS relationships between files.
-func ProcessImports(parseData *ParseData, g *graph.KnowledgeGraph) {
+// ProcessImports creates IMPORTS relationships between files, and between
+// the packages of those files. Go imports refer to the imported package.
+func ProcessImports(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph, repoPath string) {
+	resolver := parseData.importResolver(repoPath, g)
+
 	for filePath, result := range parseData.Files {
+		if ctx.Err() != nil {
+			return
+		}
 		sourceFileID := graph.GenerateID(graph.NodeFile, filePath, "")
 
+		// Several import statements may refer to the same target
+		rels := make(map[string]*graph.GraphRelationship)
+		var order []string
+
 		for _, imp := range result.Imports {
-			// Try to find target file
-			targetPath := findImportTarget(filePath, imp.ModulePath)
-			if targetPath == "" {
-				continue
-			}
+			for _, target := range resolver.resolve(filePath, imp) {
+				targetID := graph.GenerateID(graph.NodeFile, target.path, "")
+				if target.isDir {
+					targetID = graph.GenerateID(graph.NodePackage, target.path, "")
+					if g.GetNode(targetID) == nil {
+						targetID = graph.GenerateID(graph.NodeFolder, target.path, "")
+					}
+				}
 
-			targetFileID := graph.GenerateID(graph.NodeFile, targetPath, "")
+				// Only create relationship if target exists
+				if targetID == sourceFileID || g.GetNode(targetID) == nil {
+					continue
+				}
 
-			// Only create relationship if target exists
-			if g.GetNode(targetFileID) != nil {
-				rel := &graph.GraphRelationship{
-					ID:     graph.GenerateID(graph.NodeFile, filePath, imp.ModulePath),
+				relID := relationshipID(graph.RelImports, sourceFileID, targetID)
+				if rel, ok := rels[relID]; ok {
+					symbols, _ := rel.Properties["symbols"].([]string)
+					rel.Properties["symbols"] = append(symbols, imp.Symbols...)
+					continue
+				}
+				rels[relID] = &graph.GraphRelationship{
+					ID:     relID,
 					Type:   graph.RelImports,
 					Source: sourceFileID,
-					Target: targetFileID,
+					Target: targetID,
 					Properties: map[string]any{
-						"symbols": imp.Symbols,
+						"symbols": append([]string(nil), imp.Symbols...),
+						"module":  imp.ModulePath,
 					},
 				}
-				g.AddRelationship(rel)
+				order = append(order, relID)
 			}
 		}
+
+		for _, relID := range order {
+			g.AddRelationship(rels[relID])
+		}
+	}
+
+	aggregatePackageImports(g)
+	if resolver.csharpResolver != nil {
+		resolver.csharpResolver.linkProjects(g)
 	}
 }
 
 // ProcessCalls creates CALLS relationships between symbols.
-func ProcessCalls(parseData *ParseData, g *graph.KnowledgeGraph) {
+//
+// Each call is attributed to the symbol it appears in; calls in top-level
+// code are attributed to the file.
+func ProcessCalls(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph) {
 	for filePath, result := range parseData.Files {
-		for _, sym := range result.Symbols {
-			sourceID := graph.GenerateID(sym.Kind, filePath, sym.Name)
+		if ctx.Err() != nil {
+			return
+		}
+		for _, call := range result.Calls {
+			sourceID := enclosingNodeID(result, filePath, call.Enclosing, call.EnclosingClass, call.StartLine)
 
-			for _, call := range result.Calls {
-				// Try to find target symbol
-				targetID := findSymbolTarget(g, call.Name, call.Receiver, call.Package, filePath)
-				if targetID == "" {
-					continue
-				}
+			// Try to find target symbol
+			targetID, confidence := resolveCallTarget(g, call, filePath)
+			if targetID == "" {
+				continue
+			}
 
-				rel := &graph.GraphRelationship{
-					ID:     graph.GenerateID(graph.NodeFunction, filePath, fmt.Sprintf("%s->%s", sym.Name, call.Name)),
-					Type:   graph.RelCalls,
-					Source: sourceID,
-					Target: targetID,
-					Properties: map[string]any{
-						"confidence": 0.8,
-					},
-				}
-				g.AddRelationship(rel)
+			rel := &graph.GraphRelationship{
+				ID:     relationshipID(graph.RelCalls, sourceID, targetID),
+				Type:   graph.RelCalls,
+				Source: sourceID,
+				Target: targetID,
+				Properties: map[string]any{
+					"confidence": confidence,
+				},
 			}
+			g.AddRelationship(rel)
 		}
 	}
 }
 
 // ProcessHeritage creates EXTENDS and IMPLEMENTS relationships.
-func ProcessHeritage(parseData *ParseData, g *graph.KnowledgeGraph) {
+func ProcessHeritage(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph) {
 	for filePath, result := range parseData.Files {
+		if ctx.Err() != nil {
+			return
+		}
 		for _, h := range result.Heritage {
-			sourceID := graph.GenerateID(graph.NodeClass, filePath, h.ClassName)
+			sourceID := heritageSourceID(g, filePath, h.ClassName)
 
 			for _, base := range h.Extends {
 				targetID := findSymbolTarget(g, base, "", "", filePath)
@@ -421,29 +787,43 @@ func ProcessHeritage(parseData *ParseData, g *graph.KnowledgeGraph) {
 	}
 }
 
-// ProcessTypes creates USES_TYPE relationships.
-func ProcessTypes(parseData *ParseData, g *graph.KnowledgeGraph) {
+// heritageSourceID returns the ID of the class, interface or enum a
+// heritage clause belongs to. Interfaces extend interfaces and enums
+// implement them in Java, Kotlin and Rust.
+func heritageSourceID(g *graph.KnowledgeGraph, filePath, name string) string {
+	for _, label := range []graph.NodeLabel{graph.NodeInterface, graph.NodeEnum} {
+		if id := graph.GenerateID(label, filePath, name); g.GetNode(id) != nil {
+			return id
+		}
+	}
+	return graph.GenerateID(graph.NodeClass, filePath, name)
+}
+
+// ProcessTypes creates USES_TYPE relationships from the symbol each type
+// reference appears in.
+func ProcessTypes(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph) {
 	for filePath, result := range parseData.Files {
-		for _, sym := range result.Symbols {
-			sourceID := graph.GenerateID(sym.Kind, filePath, sym.Name)
+		if ctx.Err() != nil {
+			return
+		}
+		for _, typeRef := range result.TypeRefs {
+			sourceID := enclosingNodeID(result, filePath, typeRef.Enclosing, typeRef.EnclosingClass, typeRef.StartLine)
 
-			for _, typeRef := range result.TypeRefs {
-				targetID := findSymbolTarget(g, typeRef.Name, "", "", filePath)
-				if targetID == "" {
-					continue
-				}
+			targetID := findSymbolTarget(g, typeRef.Name, "", "", filePath)
+			if targetID == "" || targetID == sourceID {
+				continue
+			}
 
-				rel := &graph.GraphRelationship{
-					ID:     graph.GenerateID(graph.NodeFunction, filePath, fmt.Sprintf("%s->%s", sym.Name, typeRef.Name)),
-					Type:   graph.RelUsesType,
-					Source: sourceID,
-					Target: targetID,
-					Properties: map[string]any{
-						"role": typeRef.Role,
-					},
-				}
-				g.AddRelationship(rel)
+			rel := &graph.GraphRelationship{
+				ID:     relationshipID(graph.RelUsesType, sourceID, targetID),
+				Type:   graph.RelUsesType,
+				Source: sourceID,
+				Target: targetID,
+				Properties: map[string]any{
+					"role": typeRef.Role,
+				},
 			}
+			g.AddRelationship(rel)
 		}
 	}
 }
@@ -458,19 +838,129 @@ func getParserForLanguage(language string) parsers.Parser {
 		return parsers.NewPythonParser()
 	case "typescript":
 		return parsers.NewTypeScriptParser()
+	case "javascript":
+		return parsers.NewJavaScriptParser()
+	case "rust":
+		return parsers.NewRustParser()
+	case "java":
+		return parsers.NewJavaParser()
+	case "kotlin":
+		return parsers.NewKotlinParser()
+	case "csharp":
+		return parsers.NewCSharpParser()
+	case "c":
+		return parsers.NewCParser()
+	case "cpp":
+		return parsers.NewCPPParser()
+	case "ruby":
+		return parsers.NewRubyParser()
+	case "php":
+		return parsers.NewPHPParser()
+	case "proto":
+		return parsers.NewProtoParser()
+	case "openapi":
+		return parsers.NewOpenAPIParser()
+	case "sql":
+		return parsers.NewSQLParser()
+	case "terraform":
+		return parsers.NewTerraformParser()
+	case "markdown":
+		return parsers.NewMarkdownParser()
+	case "kubernetes":
+		return parsers.NewKubernetesParser()
+	case "dockerfile":
+		return parsers.NewDockerfileParser()
 	default:
 		return nil
 	}
 }
 
-func findImportTarget(sourceFile, modulePath string) string {
-	// Simplified import resolution
-	// In production, this would use Go's module resolution
-	if strings.HasPrefix(modulePath, ".") || strings.HasPrefix(modulePath, "/") {
-		return modulePath + ".go"
+// symbolLabel maps a parsed symbol kind to the label of its node.
+func symbolLabel(kind graph.NodeLabel) graph.NodeLabel {
+	switch kind {
+	case graph.NodeFunction, graph.NodeMethod, graph.NodeClass,
+		graph.NodeInterface, graph.NodeTypeAlias, graph.NodeEnum,
+		graph.NodeVariable, graph.NodeConstant, graph.NodeField:
+		return kind
+	default:
+		return graph.NodeFunction
 	}
-	// For standard imports, would need module path resolution
-	return ""
+}
+
+// relationshipID returns the ID of a relationship between two nodes.
+// The relationship type is part of the ID so that, e.g., a CALLS and a
+// USES_TYPE edge between the same pair of nodes do not collide.
+func relationshipID(relType graph.RelType, sourceID, targetID string) string {
+	return string(relType) + ":" + sourceID + "->" + targetID
+}
+
+// enclosingNodeID returns the node ID of the symbol a call site or type
+// reference appears in. Parsers record the enclosing symbol by name; when
+// they do not, the innermost function or type whose line range contains
+// line is used. Top-level code is attributed to the file node.
+func enclosingNodeID(result *parsers.ParseResult, filePath, enclosing, enclosingClass string, line int) string {
+	var best *parsers.ParsedSymbol
+	for i := range result.Symbols {
+		sym := &result.Symbols[i]
+		if enclosing != "" {
+			if sym.Name == enclosing && sym.ClassName == enclosingClass {
+				best = sym
+				break
+			}
+			continue
+		}
+		if sym.StartLine <= line && line <= sym.EndLine && !isValueKind(sym.Kind) {
+			if best == nil || sym.EndLine-sym.StartLine < best.EndLine-best.StartLine {
+				best = sym
+			}
+		}
+	}
+
+	if best == nil {
+		return graph.GenerateID(graph.NodeFile, filePath, "")
+	}
+	label := symbolLabel(best.Kind)
+	return graph.GenerateID(label, filePath, symbolNodeName(best.Name, best.ClassName, label))
+}
+
+// isValueKind reports whether a symbol kind is a variable, constant or
+// field: a value that code reads and writes, rather than code.
+func isValueKind(kind graph.NodeLabel) bool {
+	return kind == graph.NodeVariable || kind == graph.NodeConstant || kind == graph.NodeField
+}
+
+// symbolNodeName returns the name used in the node ID of a symbol. Methods
+// and fields are qualified with their class so that members of the same
+// name on different types in one file get distinct nodes.
+func symbolNodeName(name, className string, label graph.NodeLabel) string {
+	if (label == graph.NodeMethod || label == graph.NodeField) && className != "" {
+		return className + "." + name
+	}
+	return name
+}
+
+// resolveCallTarget returns the node a call site refers to together with the
+// confidence of the resolution. Type-checked call sites are linked to their
+// exact declaration; other call sites are resolved by name.
+func resolveCallTarget(g *graph.KnowledgeGraph, call parsers.CallSite, sourceFile string) (string, float64) {
+	if call.Package == "C" && importLanguage(sourceFile) == "go" {
+		return findCgoTarget(g, call.Name, sourceFile), heuristicCallConfidence
+	}
+	if call.Target != nil {
+		if call.Target.FilePath == "" {
+			// Declared outside the repository (standard library, dependency)
+			return "", 0
+		}
+		targetID := graph.GenerateID(call.Target.Kind, call.Target.FilePath,
+			symbolNodeName(call.Target.Name, call.Target.ClassName, call.Target.Kind))
+		if g.GetNode(targetID) == nil {
+			return "", 0
+		}
+		return targetID, typedCallConfidence
+	}
+
+	targetID := findSymbolTarget(g, call.Name, call.Receiver, call.Package, sourceFile)
+	return targetID, heuristicCallConfidence
 }
 
 // FindSymbolTargetForTest is an exported wrapper for testing.
@@ -511,13 +1001,21 @@ func findSymbolTarget(g *graph.KnowledgeGraph, name, receiver, pkgPath, sourceFi
 
 	// Check for method call with receiver
 	if receiver != "" {
-		// Look for method on receiver type
+		// Look for method on receiver type, or a function of a namespace
+		// whose last component is the receiver (Utils.Strings.trim())
 		methods := g.GetNodesByLabel(graph.NodeMethod)
+		declared := ""
 		for _, m := range methods {
-			if m.Name == name && m.ClassName == receiver {
-				return m.ID
+			if m.Name == name && (m.ClassName == receiver || strings.HasSuffix(m.ClassName, "."+receiver)) {
+				if !isDeclaration(m) {
+					return m.ID
+				}
+				declared = m.ID
 			}
 		}
+		if declared != "" {
+			return declared
+		}
 	}
 
 	// Look for function/type by name
@@ -526,17 +1024,61 @@ func findSymbolTarget(g *graph.KnowledgeGraph, name, receiver, pkgPath, sourceFi
 		graph.NodeClass,
 		graph.NodeInterface,
 		graph.NodeTypeAlias,
+		graph.NodeEnum,
 	}
 
+	declared := ""
 	for _, label := range labels {
 		nodes := g.GetNodesByLabel(label)
 		for _, n := range nodes {
 			if n.Name == name {
-				return n.ID
+				if !isDeclaration(n) {
+					return n.ID
+				}
+				if declared == "" {
+					declared = n.ID
+				}
 			}
 		}
 	}
 
+	return declared
+}
+
+// isDeclaration reports whether a node is a C/C++ function declared without
+// a body. Calls resolve to definitions, and to declarations only when the
+// definition is not in the repository.
+func isDeclaration(node *graph.GraphNode) bool {
+	declaration, _ := node.Properties["declaration"].(bool)
+	return declaration
+}
+
+// findCgoTarget returns the C function a cgo call (C.name()) in a Go file
+// refers to: a definition in the directory of the Go file, whose C files
+// cgo compiles with it, another definition, or a declaration.
+func findCgoTarget(g *graph.KnowledgeGraph, name, sourceFile string) string {
+	dir := filepath.Dir(sourceFile)
+	var local, defined, declared *graph.GraphNode
+	for _, n := range g.GetNodesByLabel(graph.NodeFunction) {
+		if n.Name != name || n.Language != "c" && n.Language != "cpp" {
+			continue
+		}
+		best := &defined
+		switch {
+		case isDeclaration(n):
+			best = &declared
+		case filepath.Dir(n.FilePath) == dir:
+			best = &local
+		}
+		if *best == nil || n.FilePath < (*best).FilePath {
+			*best = n
+		}
+	}
+	for _, n := range []*graph.GraphNode{local, defined, declared} {
+		if n != nil {
+			return n.ID
+		}
+	}
 	return ""
 }
 
@@ -549,6 +1091,9 @@ func countSymbols(g *graph.KnowledgeGraph) int {
 		graph.NodeInterface,
 		graph.NodeTypeAlias,
 		graph.NodeEnum,
+		graph.NodeVariable,
+		graph.NodeConstant,
+		graph.NodeField,
 	}
 	for _, label := range labels {
 		count += g.CountNodesByLabel(label)
@@ -556,8 +1101,67 @@ func countSymbols(g *graph.KnowledgeGraph) int {
 	return count
 }
 
-// GenerateAndStoreEmbeddings generates TF-IDF embeddings for all nodes and stores them.
-func GenerateAndStoreEmbeddings(ctx context.Context, g *graph.KnowledgeGraph, store storage.StorageBackend) error {
+// reuseGlobalResults copies the results of the global phases from globals,
+// the graph of an earlier run, to g: the community and process nodes with
+// the members and steps that g still has, and the coupling of the files
+// g still has. It returns the number of coupled pairs.
+func reuseGlobalResults(globals, g *graph.KnowledgeGraph) int {
+	for label, relType := range map[graph.NodeLabel]graph.RelType{
+		graph.NodeCommunity: graph.RelMemberOf,
+		graph.NodeProcess:   graph.RelStepInProcess,
+	} {
+		for _, node := range globals.GetNodesByLabel(label) {
+			var rels []*graph.GraphRelationship
+			for _, rel := range globals.GetIncoming(node.ID, relType) {
+				if g.GetNode(rel.Source) != nil {
+					rels = append(rels, rel)
+				}
+			}
+			if len(rels) == 0 {
+				continue
+			}
+
+			copied := *node
+			if members, ok := node.Properties["members"].([]string); ok {
+				copied.Properties = make(map[string]any, len(node.Properties))
+				for k, v := range node.Properties {
+					copied.Properties[k] = v
+				}
+				kept := make([]string, 0, len(members))
+				for _, id := range members {
+					if g.GetNode(id) != nil {
+						kept = append(kept, id)
+					}
+				}
+				copied.Properties["members"] = kept
+				copied.Properties["member_count"] = len(kept)
+			}
+			g.AddNode(&copied)
+			for _, rel := range rels {
+				g.AddRelationship(rel)
+			}
+		}
+	}
+
+	coupled := 0
+	for _, rel := range globals.GetRelationshipsByType(graph.RelCoupledWith) {
+		if g.GetNode(rel.Source) != nil && g.GetNode(rel.Target) != nil {
+			g.AddRelationship(rel)
+			coupled++
+		}
+	}
+	return coupled
+}
+
+// embeddingBatchSize is the number of nodes GenerateEmbeddings embeds
+// between two checks of its context.
+const embeddingBatchSize = 512
+
+// GenerateEmbeddings generates TF-IDF embeddings for all nodes, in batches
+// of embeddingBatchSize nodes. progress, if not nil, is called after each
+// batch with the number of nodes done. If ctx is canceled it stops at the
+// next batch and returns a *CanceledError counting the nodes embedded.
+func GenerateEmbeddings(ctx context.Context, g *graph.KnowledgeGraph, progress func(done, total int)) ([]storage.NodeEmbedding, error) {
 	// Collect all nodes
 	var nodes []*graph.GraphNode
 	for node := range g.IterNodes() {
@@ -565,24 +1169,37 @@ func GenerateAndStoreEmbeddings(ctx context.Context, g *graph.KnowledgeGraph, st
 	}
 
 	if len(nodes) == 0 {
-		return nil
+		return nil, nil
+	}
+	canceled := func(done int) error {
+		return &CanceledError{Phase: "Generating embeddings", Done: done, Total: len(nodes), Unit: "nodes", Err: ctx.Err()}
 	}
 
-	// Create TF-IDF embedder
+	// The vocabulary and IDF scores are computed over every node first
+	docs := make([]string, len(nodes))
+	for i, node := range nodes {
+		docs[i] = embeddings.GenerateEmbeddingText(node)
+	}
 	embedder := embeddings.NewTFIDFEmbedder()
+	embedder.BuildVocabulary(docs)
+	embedder.ComputeIDF(docs)
 
-	// Generate embeddings
-	embeddingList := embedder.EmbedNodes(nodes)
-
-	// Convert to storage embeddings
-	storageEmbeddings := make([]storage.NodeEmbedding, len(nodes))
-	for i, node := range nodes {
-		storageEmbeddings[i] = storage.NodeEmbedding{
-			NodeID:    node.ID,
-			Embedding: embeddingList[i],
+	storageEmbeddings := make([]storage.NodeEmbedding, 0, len(nodes))
+	for start := 0; start < len(nodes); start += embeddingBatchSize {
+		if ctx.Err() != nil {
+			return nil, canceled(start)
+		}
+		end := min(start+embeddingBatchSize, len(nodes))
+		for i := start; i < end; i++ {
+			storageEmbeddings = append(storageEmbeddings, storage.NodeEmbedding{
+				NodeID:    nodes[i].ID,
+				Embedding: embedder.Embed(docs[i]),
+			})
+		}
+		if progress != nil {
+			progress(end, len(nodes))
 		}
 	}
 
-	// Store embeddings
-	return store.StoreEmbeddings(ctx, storageEmbeddings)
+	return storageEmbeddings, nil
 }
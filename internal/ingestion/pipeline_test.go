@@ -2,9 +2,11 @@ package ingestion
 
 import (
 	"context"
+	"fmt"
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -25,7 +27,7 @@ func TestProcessStructure(t *testing.T) {
 			{Path: "/repo/src/app.go", RelPath: "src/app.go", Language: "go"},
 		}
 
-		ProcessStructure(entries, g)
+		ProcessStructure(context.Background(), entries, g)
 
 		// Should have folder and file nodes
 		assert.GreaterOrEqual(t, g.NodeCount(), 2)
@@ -43,7 +45,7 @@ func TestProcessStructure(t *testing.T) {
 			{Path: "/repo/src/app.go", RelPath: "src/app.go", Language: "go"},
 		}
 
-		ProcessStructure(entries, g)
+		ProcessStructure(context.Background(), entries, g)
 
 		// Should have CONTAINS relationships
 		rels := g.GetRelationshipsByType(graph.RelContains)
@@ -72,7 +74,7 @@ type User struct {
 			{Path: "/repo/test.go", RelPath: "test.go", Language: "go", Content: content},
 		}
 
-		parseData := ProcessParsing(entries, g)
+		parseData := ProcessParsing(context.Background(), entries, g)
 
 		assert.NotNil(t, parseData)
 		assert.NotEmpty(t, parseData.Files)
@@ -91,10 +93,65 @@ type User struct {
 			{Path: "/repo/b.go", RelPath: "b.go", Language: "go", Content: []byte("package main\nfunc B() {}")},
 		}
 
-		parseData := ProcessParsing(entries, g)
+		parseData := ProcessParsing(context.Background(), entries, g)
 
 		assert.Len(t, parseData.Files, 2)
 	})
+
+	t.Run("ParsesJavaScriptFiles", func(t *testing.T) {
+		dir := writeRepo(t, map[string]string{
+			"lib/animal.js": `function Animal(name) { this.name = name; }
+Animal.prototype.speak = function () { return describe(this.name); };
+function describe(name) { return name; }
+module.exports = Animal;
+`,
+			"lib/dog.js": `const Animal = require('./animal');
+class Dog extends Animal {
+  bark() { return this.speak(); }
+}
+module.exports = { Dog };
+`,
+		})
+
+		g, parseData := runTestPipeline(t, dir)
+		ProcessCalls(context.Background(), parseData, g)
+		ProcessHeritage(context.Background(), parseData, g)
+
+		animalFile := filepath.Join("lib", "animal.js")
+		dogFile := filepath.Join("lib", "dog.js")
+		speakID := graph.GenerateID(graph.NodeMethod, animalFile, "Animal.speak")
+		require.NotNil(t, g.GetNode(speakID))
+		assert.Equal(t, "javascript", g.GetNode(speakID).Language)
+
+		imports := g.GetOutgoing(graph.GenerateID(graph.NodeFile, dogFile, ""), graph.RelImports)
+		require.Len(t, imports, 1)
+		assert.Equal(t, graph.GenerateID(graph.NodeFile, animalFile, ""), imports[0].Target)
+
+		extends := g.GetOutgoing(graph.GenerateID(graph.NodeClass, dogFile, "Dog"), graph.RelExtends)
+		require.Len(t, extends, 1)
+		assert.Equal(t, graph.GenerateID(graph.NodeClass, animalFile, "Animal"), extends[0].Target)
+
+		callers := g.GetIncoming(graph.GenerateID(graph.NodeFunction, animalFile, "describe"), graph.RelCalls)
+		require.Len(t, callers, 1)
+		assert.Equal(t, speakID, callers[0].Source)
+	})
+}
+
+// panickingParser is a parser with a bug.
+type panickingParser struct{}
+
+func (panickingParser) Parse(string, []byte) (*parsers.ParseResult, error) {
+	panic("slice bounds out of range")
+}
+
+func (panickingParser) Language() string { return "go" }
+
+func TestParseWith(t *testing.T) {
+	t.Parallel()
+
+	entry := FileEntry{RelPath: "main.go", Language: "go", Content: []byte("package main\n")}
+	assert.Nil(t, parseWith(panickingParser{}, entry), "a parser panic leaves the file unparsed")
+	assert.NotNil(t, parseWith(parsers.NewGoParser(), entry))
 }
 
 func TestProcessImports(t *testing.T) {
@@ -104,24 +161,50 @@ func TestProcessImports(t *testing.T) {
 		g := graph.NewKnowledgeGraph()
 
 		// Create file nodes first
-		g.AddNode(&graph.GraphNode{ID: "file:a.go", Label: graph.NodeFile, FilePath: "a.go"})
-		g.AddNode(&graph.GraphNode{ID: "file:./b.go", Label: graph.NodeFile, FilePath: "./b.go"})
+		g.AddNode(&graph.GraphNode{ID: "file:a.ts", Label: graph.NodeFile, FilePath: "a.ts"})
+		g.AddNode(&graph.GraphNode{ID: "file:b.ts", Label: graph.NodeFile, FilePath: "b.ts"})
 
 		parseData := &ParseData{
 			Files: map[string]*parsers.ParseResult{
-				"a.go": {
+				"a.ts": {
 					Imports: []parsers.ImportStatement{
-						{ModulePath: "./b", Symbols: []string{"B"}},
+						{ModulePath: "./b", Symbols: []string{"B"}, IsRelative: true},
 					},
 				},
 			},
 		}
 
-		ProcessImports(parseData, g)
+		ProcessImports(context.Background(), parseData, g, t.TempDir())
 
 		// Should have IMPORTS relationships for relative imports
 		rels := g.GetRelationshipsByType(graph.RelImports)
-		assert.NotEmpty(t, rels)
+		require.Len(t, rels, 1)
+		assert.Equal(t, "file:a.ts", rels[0].Source)
+		assert.Equal(t, "file:b.ts", rels[0].Target)
+		assert.Equal(t, []string{"B"}, rels[0].Properties["symbols"])
+	})
+
+	t.Run("MergesImportsOfSameTarget", func(t *testing.T) {
+		g := graph.NewKnowledgeGraph()
+		g.AddNode(&graph.GraphNode{ID: "file:a.ts", Label: graph.NodeFile, FilePath: "a.ts"})
+		g.AddNode(&graph.GraphNode{ID: "file:b.ts", Label: graph.NodeFile, FilePath: "b.ts"})
+
+		parseData := &ParseData{
+			Files: map[string]*parsers.ParseResult{
+				"a.ts": {
+					Imports: []parsers.ImportStatement{
+						{ModulePath: "./b", Symbols: []string{"B"}},
+						{ModulePath: "./b.js", Symbols: []string{"C"}},
+					},
+				},
+			},
+		}
+
+		ProcessImports(context.Background(), parseData, g, t.TempDir())
+
+		rels := g.GetRelationshipsByType(graph.RelImports)
+		require.Len(t, rels, 1)
+		assert.Equal(t, []string{"B", "C"}, rels[0].Properties["symbols"])
 	})
 }
 
@@ -148,12 +231,52 @@ func TestProcessCalls(t *testing.T) {
 			},
 		}
 
-		ProcessCalls(parseData, g)
+		ProcessCalls(context.Background(), parseData, g)
 
 		// Should have CALLS relationships
 		rels := g.GetRelationshipsByType(graph.RelCalls)
 		assert.NotEmpty(t, rels)
 	})
+
+	t.Run("AttributesCallsToEnclosingSymbol", func(t *testing.T) {
+		g := graph.NewKnowledgeGraph()
+
+		g.AddNode(&graph.GraphNode{ID: "file:a.go", Label: graph.NodeFile, FilePath: "a.go"})
+		g.AddNode(&graph.GraphNode{ID: "function:a.go:Foo", Label: graph.NodeFunction, Name: "Foo", FilePath: "a.go"})
+		g.AddNode(&graph.GraphNode{ID: "method:a.go:Svc.Run", Label: graph.NodeMethod, Name: "Run", ClassName: "Svc", FilePath: "a.go"})
+		g.AddNode(&graph.GraphNode{ID: "function:b.go:Bar", Label: graph.NodeFunction, Name: "Bar", FilePath: "b.go"})
+		g.AddNode(&graph.GraphNode{ID: "function:b.go:Baz", Label: graph.NodeFunction, Name: "Baz", FilePath: "b.go"})
+		g.AddNode(&graph.GraphNode{ID: "function:b.go:Init", Label: graph.NodeFunction, Name: "Init", FilePath: "b.go"})
+
+		parseData := &ParseData{
+			Files: map[string]*parsers.ParseResult{
+				"a.go": {
+					Symbols: []parsers.ParsedSymbol{
+						{Name: "Foo", Kind: graph.NodeFunction, StartLine: 3, EndLine: 5},
+						{Name: "Run", Kind: graph.NodeMethod, ClassName: "Svc", StartLine: 7, EndLine: 9},
+					},
+					Calls: []parsers.CallSite{
+						{Name: "Bar", StartLine: 4, Enclosing: "Foo"},
+						{Name: "Baz", StartLine: 8, Enclosing: "Run", EnclosingClass: "Svc"},
+						{Name: "Init", StartLine: 1},
+					},
+				},
+			},
+		}
+
+		ProcessCalls(context.Background(), parseData, g)
+
+		fooCallees := callTargets(g, "function:a.go:Foo")
+		assert.Len(t, fooCallees, 1)
+		assert.Contains(t, fooCallees, "function:b.go:Bar")
+
+		runCallees := callTargets(g, "method:a.go:Svc.Run")
+		assert.Len(t, runCallees, 1)
+		assert.Contains(t, runCallees, "function:b.go:Baz")
+
+		fileCallees := callTargets(g, "file:a.go")
+		assert.Contains(t, fileCallees, "function:b.go:Init", "top-level calls come from the file")
+	})
 }
 
 func TestProcessHeritage(t *testing.T) {
@@ -175,7 +298,7 @@ func TestProcessHeritage(t *testing.T) {
 			},
 		}
 
-		ProcessHeritage(parseData, g)
+		ProcessHeritage(context.Background(), parseData, g)
 
 		// Should have EXTENDS relationships
 		rels := g.GetRelationshipsByType(graph.RelExtends)
@@ -205,12 +328,39 @@ func TestProcessTypes(t *testing.T) {
 			},
 		}
 
-		ProcessTypes(parseData, g)
+		ProcessTypes(context.Background(), parseData, g)
 
 		// Should have USES_TYPE relationships
 		rels := g.GetRelationshipsByType(graph.RelUsesType)
 		assert.NotEmpty(t, rels)
 	})
+
+	t.Run("AttributesTypeRefsToEnclosingSymbol", func(t *testing.T) {
+		g := graph.NewKnowledgeGraph()
+
+		g.AddNode(&graph.GraphNode{ID: "function:a.go:Foo", Label: graph.NodeFunction, Name: "Foo", FilePath: "a.go"})
+		g.AddNode(&graph.GraphNode{ID: "function:a.go:Bar", Label: graph.NodeFunction, Name: "Bar", FilePath: "a.go"})
+		g.AddNode(&graph.GraphNode{ID: "class:a.go:User", Label: graph.NodeClass, Name: "User", FilePath: "a.go"})
+
+		parseData := &ParseData{
+			Files: map[string]*parsers.ParseResult{
+				"a.go": {
+					Symbols: []parsers.ParsedSymbol{
+						{Name: "Foo", Kind: graph.NodeFunction, StartLine: 1, EndLine: 3},
+						{Name: "Bar", Kind: graph.NodeFunction, StartLine: 5, EndLine: 7},
+					},
+					TypeRefs: []parsers.TypeAnnotation{
+						{Name: "User", Role: "param", StartLine: 1, Enclosing: "Foo"},
+					},
+				},
+			},
+		}
+
+		ProcessTypes(context.Background(), parseData, g)
+
+		assert.Len(t, g.GetOutgoing("function:a.go:Foo", graph.RelUsesType), 1)
+		assert.Empty(t, g.GetOutgoing("function:a.go:Bar", graph.RelUsesType))
+	})
 }
 
 func TestRunPipeline(t *testing.T) {
@@ -257,7 +407,7 @@ type Database struct {}
 
 		// Run pipeline
 		ctx := context.Background()
-		graph, result, err := RunPipeline(ctx, tmpDir, store, false, nil, false)
+		graph, result, err := RunPipeline(ctx, tmpDir, store, false, nil, false, 0)
 
 		assert.NoError(t, err)
 		assert.NotNil(t, graph)
@@ -268,6 +418,135 @@ type Database struct {}
 		assert.Greater(t, result.Symbols, 0)
 		assert.Greater(t, result.Relationships, 0)
 	})
+
+	t.Run("Canceled", func(t *testing.T) {
+		dir := writeRepo(t, map[string]string{
+			"main.go": "package main\n\nfunc main() {}\n",
+		})
+		store := storage.NewMemoryBackend()
+		require.NoError(t, store.Initialize(filepath.Join(t.TempDir(), "db"), false))
+		defer store.Close()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		g, _, err := RunPipeline(ctx, dir, store, false, nil, true, 0)
+		require.Error(t, err)
+		assert.Nil(t, g)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		var canceled *CanceledError
+		require.ErrorAs(t, err, &canceled)
+		assert.Equal(t, "Walking files", canceled.Phase)
+
+		node, err := store.GetNode(context.Background(), graph.GenerateID(graph.NodeFile, "main.go", ""))
+		require.NoError(t, err)
+		assert.Nil(t, node, "nothing is written to the store")
+	})
+
+	t.Run("DeadlineExceeded", func(t *testing.T) {
+		dir := writeRepo(t, map[string]string{
+			"main.go": "package main\n\nfunc main() {}\n",
+		})
+
+		ctx, cancel := context.WithDeadline(context.Background(), time.Now())
+		defer cancel()
+
+		_, _, err := RunPipeline(ctx, dir, nil, false, nil, false, 0)
+		assert.ErrorIs(t, err, context.DeadlineExceeded)
+	})
+}
+
+func TestIndexGeneration(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := writeRepo(t, map[string]string{
+		"main.go":   "package main\n\nfunc main() {\n\thelper()\n}\n",
+		"helper.go": "package main\n\nfunc helper() {}\n",
+	})
+	gens := storage.NewGenerations(filepath.Join(t.TempDir(), ".axon"), 1)
+
+	first, result, err := IndexGeneration(ctx, dir, gens, false, nil, false, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.ParsedFiles)
+
+	second, result, err := IndexGeneration(ctx, dir, gens, false, nil, false, 0)
+	require.NoError(t, err)
+	assert.Equal(t, 0, result.ParsedFiles, "the new generation reuses the parse results of the current one")
+	assert.NotEqual(t, first.Name, second.Name)
+
+	store, current, err := gens.OpenCurrent()
+	require.NoError(t, err)
+	assert.Equal(t, second.Name, current.Name)
+	callees, err := store.GetCallees(ctx, "function:main.go:main")
+	require.NoError(t, err)
+	require.NoError(t, store.Close())
+	require.Len(t, callees, 1, "the unchanged files are indexed in full")
+	assert.Equal(t, "function:helper.go:helper", callees[0].ID)
+
+	t.Run("Canceled", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+
+		_, _, err := IndexGeneration(canceled, dir, gens, false, nil, false, 0)
+		assert.ErrorIs(t, err, context.Canceled)
+
+		current, err := gens.Current()
+		require.NoError(t, err)
+		assert.Equal(t, second.Name, current.Name, "the current generation stays in place")
+		entries, err := os.ReadDir(filepath.Dir(current.Path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 2, "the canceled generation is removed")
+	})
+}
+
+func TestGenerateEmbeddings(t *testing.T) {
+	t.Parallel()
+
+	g := graph.NewKnowledgeGraph()
+	for i := range embeddingBatchSize + 10 {
+		g.AddNode(&graph.GraphNode{
+			ID:    graph.GenerateID(graph.NodeFunction, "main.go", fmt.Sprintf("f%d", i)),
+			Label: graph.NodeFunction,
+			Name:  fmt.Sprintf("f%d", i),
+		})
+	}
+
+	var done []int
+	vectors, err := GenerateEmbeddings(context.Background(), g, func(n, total int) {
+		assert.Equal(t, embeddingBatchSize+10, total)
+		done = append(done, n)
+	})
+	require.NoError(t, err)
+	assert.Len(t, vectors, embeddingBatchSize+10)
+	assert.Equal(t, []int{embeddingBatchSize, embeddingBatchSize + 10}, done, "progress is reported per batch")
+
+	t.Run("Canceled", func(t *testing.T) {
+		t.Parallel()
+
+		ctx, cancel := context.WithCancel(context.Background())
+		vectors, err := GenerateEmbeddings(ctx, g, func(int, int) { cancel() })
+		assert.Nil(t, vectors)
+		var canceled *CanceledError
+		require.ErrorAs(t, err, &canceled)
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Equal(t, embeddingBatchSize, canceled.Done, "it stops after the batch in progress")
+		assert.Equal(t, embeddingBatchSize+10, canceled.Total)
+	})
+}
+
+func TestCanceledError(t *testing.T) {
+	t.Parallel()
+
+	err := &CanceledError{Phase: "Parsing code", Done: 120, Total: 800, Err: context.Canceled}
+	assert.Equal(t, "Parsing code stopped after 120 of 800 files: context canceled", err.Error())
+
+	err = &CanceledError{Phase: "Generating embeddings", Done: 512, Total: 2000, Unit: "nodes", Err: context.Canceled}
+	assert.Equal(t, "Generating embeddings stopped after 512 of 2000 nodes: context canceled", err.Error())
+
+	err = &CanceledError{Phase: "Detecting communities", Err: context.DeadlineExceeded}
+	assert.Equal(t, "Detecting communities stopped: context deadline exceeded", err.Error())
 }
 
 func TestParseData(t *testing.T) {
@@ -1,6 +1,7 @@
 package ingestion
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -9,13 +10,16 @@ import (
 
 // ProcessProcesses detects execution flows from entry points.
 // Returns the number of PROCESS nodes created.
-func ProcessProcesses(g *graph.KnowledgeGraph) int {
+func ProcessProcesses(ctx context.Context, g *graph.KnowledgeGraph) int {
 	// Find all entry points
 	entryPoints := findEntryPoints(g)
 
 	// Trace flows from each entry point
 	allFlows := make([][]string, 0, len(entryPoints))
 	for _, entryPoint := range entryPoints {
+		if ctx.Err() != nil {
+			return 0
+		}
 		flow := traceFlow(g, entryPoint.ID, 10)
 		if len(flow) > 0 {
 			allFlows = append(allFlows, flow)
@@ -61,12 +65,14 @@ func ProcessProcesses(g *graph.KnowledgeGraph) int {
 	return processCount
 }
 
-// findEntryPoints finds all entry point nodes in the graph.
+// findEntryPoints finds all entry point nodes in the graph. Route handlers
+// are not entry points of their own: their flows start from the endpoints
+// they handle.
 func findEntryPoints(g *graph.KnowledgeGraph) []*graph.GraphNode {
 	var entryPoints []*graph.GraphNode
 
 	for node := range g.IterNodes() {
-		if isEntryPoint(node) {
+		if isEntryPoint(node) && !g.HasIncoming(node.ID, graph.RelHandles) {
 			entryPoints = append(entryPoints, node)
 		}
 	}
@@ -80,11 +86,22 @@ func isEntryPoint(node *graph.GraphNode) bool {
 		return true
 	}
 
+	// HTTP routes
+	if node.Label == graph.NodeEndpoint {
+		return true
+	}
+
 	// Check for main function
 	if node.Label == graph.NodeFunction && node.Name == "main" {
 		return true
 	}
 
+	// Check for Java and C# main methods
+	if node.Label == graph.NodeMethod &&
+		(node.Name == "main" && node.Language == "java" || node.Name == "Main" && node.Language == "csharp") {
+		return true
+	}
+
 	// Check for test functions
 	if node.Label == graph.NodeFunction {
 		if strings.HasPrefix(node.Name, "Test") ||
@@ -102,6 +119,11 @@ func isEntryPoint(node *graph.GraphNode) bool {
 		}
 	}
 
+	// Check for framework entry points (annotated Java, Kotlin and C# methods)
+	if hasAnnotation(node, entryPointAnnotations) {
+		return true
+	}
+
 	// Check for CLI commands
 	if strings.Contains(node.Name, "Cmd") ||
 		strings.Contains(node.Name, "Command") {
@@ -111,8 +133,67 @@ func isEntryPoint(node *graph.GraphNode) bool {
 	return false
 }
 
-// traceFlow traces the call flow from a starting node.
-// Returns a list of node IDs in the flow.
+// entryPointAnnotations are the Java and Kotlin annotations, and C#
+// attributes, of methods a framework calls in response to outside events:
+// requests, messages, schedules and test runs.
+var entryPointAnnotations = map[string]bool{
+	// Spring MVC
+	"RequestMapping":   true,
+	"GetMapping":       true,
+	"PostMapping":      true,
+	"PutMapping":       true,
+	"DeleteMapping":    true,
+	"PatchMapping":     true,
+	"ExceptionHandler": true,
+	// JAX-RS
+	"GET":    true,
+	"POST":   true,
+	"PUT":    true,
+	"DELETE": true,
+	"PATCH":  true,
+	"Path":   true,
+	// Messaging and scheduling
+	"Scheduled":      true,
+	"EventListener":  true,
+	"KafkaListener":  true,
+	"RabbitListener": true,
+	"JmsListener":    true,
+	"SqsListener":    true,
+	// JUnit and TestNG
+	"Test":              true,
+	"ParameterizedTest": true,
+	"RepeatedTest":      true,
+	"TestFactory":       true,
+	// ASP.NET Core
+	"HttpGet":    true,
+	"HttpPost":   true,
+	"HttpPut":    true,
+	"HttpDelete": true,
+	"HttpPatch":  true,
+	"Route":      true,
+	// Azure Functions
+	"Function":     true,
+	"FunctionName": true,
+	// xUnit, NUnit and MSTest
+	"Fact":       true,
+	"Theory":     true,
+	"TestCase":   true,
+	"TestMethod": true,
+}
+
+// hasAnnotation reports whether a node has one of the given annotations.
+// Qualified names (@org.junit.Test) match by their last segment.
+func hasAnnotation(node *graph.GraphNode, annotations map[string]bool) bool {
+	for _, decorator := range node.Decorators {
+		if annotations[decorator[strings.LastIndexByte(decorator, '.')+1:]] {
+			return true
+		}
+	}
+	return false
+}
+
+// traceFlow traces the call flow from a starting node, through the
+// handlers of endpoints. Returns a list of node IDs in the flow.
 func traceFlow(g *graph.KnowledgeGraph, startNodeID string, maxDepth int) []string {
 	flow := []string{startNodeID}
 	visited := map[string]bool{startNodeID: true}
@@ -129,8 +210,13 @@ func traceFlow(g *graph.KnowledgeGraph, startNodeID string, maxDepth int) []stri
 			continue
 		}
 
-		// Get callees
+		// Get callees, and the handlers of an endpoint
 		callees := g.GetCallees(current)
+		for _, rel := range g.GetOutgoing(current, graph.RelHandles) {
+			if handler := g.GetNode(rel.Target); handler != nil {
+				callees = append(callees, handler)
+			}
+		}
 		for _, callee := range callees {
 			if !visited[callee.ID] {
 				visited[callee.ID] = true
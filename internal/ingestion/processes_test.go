@@ -1,6 +1,7 @@
 package ingestion
 
 import (
+	"context"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -53,6 +54,72 @@ func TestIsEntryPoint(t *testing.T) {
 			},
 			expected: true,
 		},
+		{
+			name: "SpringHandler",
+			node: &graph.GraphNode{
+				Name:       "getUser",
+				Label:      graph.NodeMethod,
+				FilePath:   "src/main/java/com/example/UserController.java",
+				Language:   "java",
+				Decorators: []string{"GetMapping"},
+			},
+			expected: true,
+		},
+		{
+			name: "QualifiedTestAnnotation",
+			node: &graph.GraphNode{
+				Name:       "findsUser",
+				Label:      graph.NodeMethod,
+				FilePath:   "src/test/kotlin/com/example/UserServiceTest.kt",
+				Language:   "kotlin",
+				Decorators: []string{"org.junit.jupiter.api.Test"},
+			},
+			expected: true,
+		},
+		{
+			name: "JavaMainMethod",
+			node: &graph.GraphNode{
+				Name:      "main",
+				Label:     graph.NodeMethod,
+				FilePath:  "src/main/java/com/example/App.java",
+				Language:  "java",
+				ClassName: "App",
+			},
+			expected: true,
+		},
+		{
+			name: "AspNetAction",
+			node: &graph.GraphNode{
+				Name:       "GetAsync",
+				Label:      graph.NodeMethod,
+				FilePath:   "src/Shop.Api/Controllers/OrdersController.cs",
+				Language:   "csharp",
+				Decorators: []string{"HttpGet"},
+			},
+			expected: true,
+		},
+		{
+			name: "CSharpMainMethod",
+			node: &graph.GraphNode{
+				Name:      "Main",
+				Label:     graph.NodeMethod,
+				FilePath:  "src/Shop.Api/Program.cs",
+				Language:  "csharp",
+				ClassName: "Program",
+			},
+			expected: true,
+		},
+		{
+			name: "OverrideIsNotEntryPoint",
+			node: &graph.GraphNode{
+				Name:       "toString",
+				Label:      graph.NodeMethod,
+				FilePath:   "src/main/java/com/example/User.java",
+				Language:   "java",
+				Decorators: []string{"Override"},
+			},
+			expected: false,
+		},
 		{
 			name: "RegularFunction",
 			node: &graph.GraphNode{
@@ -104,7 +171,7 @@ func TestProcessProcesses(t *testing.T) {
 			Target: "function:main.go:helper",
 		})
 
-		count := ProcessProcesses(g)
+		count := ProcessProcesses(context.Background(), g)
 
 		assert.Greater(t, count, 0)
 
@@ -138,7 +205,7 @@ func TestProcessProcesses(t *testing.T) {
 			Target: "function:main.go:step1",
 		})
 
-		_ = ProcessProcesses(g)
+		_ = ProcessProcesses(context.Background(), g)
 
 		// Verify STEP_IN_PROCESS edges were created
 		stepEdges := g.GetRelationshipsByType(graph.RelStepInProcess)
@@ -164,7 +231,7 @@ func TestProcessProcesses(t *testing.T) {
 			IsEntryPoint: true,
 		})
 
-		count := ProcessProcesses(g)
+		count := ProcessProcesses(context.Background(), g)
 
 		// Should create at least 2 processes (one per entry point)
 		assert.GreaterOrEqual(t, count, 2)
@@ -181,7 +248,7 @@ func TestProcessProcesses(t *testing.T) {
 			FilePath: "utils.go",
 		})
 
-		count := ProcessProcesses(g)
+		count := ProcessProcesses(context.Background(), g)
 
 		assert.Equal(t, 0, count)
 	})
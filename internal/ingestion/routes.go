@@ -0,0 +1,281 @@
+package ingestion
+
+import (
+	"context"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// ProcessRoutes creates an endpoint node for every HTTP route registered in
+// code or declared in an API specification, defined by its file, and
+// HANDLES relationships from the endpoints to their handlers. Handlers are
+// marked as entry points. It returns the number of endpoints created.
+//
+// Endpoints are named by method and path, with path parameters written the
+// OpenAPI way whatever the framework: /users/:id, /users/<int:id> and
+// /users/{id:[0-9]+} are all /users/{id}. The Express routes of a file
+// whose router another file mounts under a prefix get the prefix.
+//
+// A handler is the function or method the route names, a method of the
+// receiver type where it is known, preferring the file of the route and
+// then its directory. Inline handlers are attributed to the symbol that
+// registers them. The operations of an API specification are handled by
+// the function named by their operationId, and by the handlers of the
+// endpoints in code that serve the same method and path, which may be
+// mounted under a prefix the specification leaves out or the other way
+// around.
+func ProcessRoutes(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph, repoPath string) int {
+	prefixes := routeMountPrefixes(parseData, g, repoPath)
+	filePaths := make([]string, 0, len(parseData.Files))
+	for filePath, result := range parseData.Files {
+		if len(result.Routes) > 0 {
+			filePaths = append(filePaths, filePath)
+		}
+	}
+	sort.Strings(filePaths)
+
+	type endpoint struct {
+		id, method, path string
+	}
+	var code, specs []endpoint
+	count := 0
+	handle := func(endpointID, handlerID string) {
+		handler := g.GetNode(handlerID)
+		if handler == nil {
+			return
+		}
+		handler.IsEntryPoint = true
+		g.AddRelationship(&graph.GraphRelationship{
+			ID:     relationshipID(graph.RelHandles, endpointID, handlerID),
+			Type:   graph.RelHandles,
+			Source: endpointID,
+			Target: handlerID,
+		})
+	}
+
+	for _, filePath := range filePaths {
+		result := parseData.Files[filePath]
+		fileID := graph.GenerateID(graph.NodeFile, filePath, "")
+		language := ""
+		if file := g.GetNode(fileID); file != nil {
+			language = file.Language
+		}
+
+		for _, route := range result.Routes {
+			if prefix := prefixes[filePath]; prefix != "" && route.Framework == "express" {
+				route.Path = strings.TrimSuffix(prefix, "/") + route.Path
+			}
+			path := normalizeRoutePath(route.Path)
+			name := route.Method + " " + path
+			id := graph.GenerateID(graph.NodeEndpoint, filePath, name)
+			if g.GetNode(id) == nil {
+				g.AddNode(&graph.GraphNode{
+					ID:        id,
+					Label:     graph.NodeEndpoint,
+					Name:      name,
+					FilePath:  filePath,
+					StartLine: route.StartLine,
+					EndLine:   route.StartLine,
+					Language:  language,
+					Properties: map[string]any{
+						"method":    route.Method,
+						"path":      path,
+						"route":     route.Path,
+						"framework": route.Framework,
+					},
+				})
+				g.AddRelationship(&graph.GraphRelationship{
+					ID:     relationshipID(graph.RelDefines, fileID, id),
+					Type:   graph.RelDefines,
+					Source: fileID,
+					Target: id,
+				})
+				count++
+
+				if route.Framework == "openapi" {
+					specs = append(specs, endpoint{id, route.Method, path})
+				} else {
+					code = append(code, endpoint{id, route.Method, path})
+				}
+			}
+			if handlerID := routeHandler(g, result, filePath, route); handlerID != "" {
+				handle(id, handlerID)
+			}
+		}
+	}
+
+	for _, spec := range specs {
+		for _, c := range code {
+			if !routeMethodsMatch(spec.method, c.method) || !routePathsMatch(spec.path, c.path) {
+				continue
+			}
+			for _, rel := range g.GetOutgoing(c.id, graph.RelHandles) {
+				handle(spec.id, rel.Target)
+			}
+		}
+	}
+
+	return count
+}
+
+// maxMountDepth bounds the chain of files followed to compute the mount
+// prefix of a file, in case routers mount each other.
+const maxMountDepth = 8
+
+// routeMountPrefixes returns the path prefix each file is mounted under by
+// the routers of other files, resolving the mounted modules like imports.
+// A file mounted more than once keeps the prefix of the first mount.
+func routeMountPrefixes(parseData *ParseData, g *graph.KnowledgeGraph, repoPath string) map[string]string {
+	type mount struct {
+		from, prefix string
+	}
+	var resolver *importResolver
+	mounts := make(map[string]mount)
+	filePaths := make([]string, 0, len(parseData.Files))
+	for filePath, result := range parseData.Files {
+		if len(result.RouteMounts) > 0 {
+			filePaths = append(filePaths, filePath)
+		}
+	}
+	sort.Strings(filePaths)
+	for _, filePath := range filePaths {
+		if resolver == nil {
+			resolver = newImportResolver(repoPath, g)
+		}
+		for _, m := range parseData.Files[filePath].RouteMounts {
+			imp := parsers.ImportStatement{ModulePath: m.ModulePath, IsRelative: strings.HasPrefix(m.ModulePath, ".")}
+			for _, target := range resolver.resolve(filePath, imp) {
+				if _, ok := mounts[target.path]; !ok && !target.isDir {
+					mounts[target.path] = mount{filePath, m.Prefix}
+				}
+			}
+		}
+	}
+
+	prefixes := make(map[string]string, len(mounts))
+	for filePath := range mounts {
+		var prefix string
+		for file, depth := filePath, 0; depth < maxMountDepth; depth++ {
+			m, ok := mounts[file]
+			if !ok {
+				break
+			}
+			prefix = strings.TrimSuffix(m.prefix, "/") + prefix
+			file = m.from
+		}
+		prefixes[filePath] = prefix
+	}
+	return prefixes
+}
+
+// routeHandler returns the ID of the node that handles a route registered
+// in filePath, or "".
+func routeHandler(g *graph.KnowledgeGraph, result *parsers.ParseResult, filePath string, route parsers.Route) string {
+	if route.NoHandler {
+		return ""
+	}
+	if route.Handler == "" {
+		id := enclosingNodeID(result, filePath, route.Enclosing, route.EnclosingClass, route.StartLine)
+		if node := g.GetNode(id); node == nil || node.Label == graph.NodeFile {
+			return ""
+		}
+		return id
+	}
+
+	// operationIds are camelCase whatever the naming convention of the
+	// code: getUser may be GetUser or get_user
+	matches := func(node *graph.GraphNode) bool {
+		if route.Framework == "openapi" {
+			return strings.EqualFold(strings.ReplaceAll(node.Name, "_", ""), strings.ReplaceAll(route.Handler, "_", ""))
+		}
+		return node.Name == route.Handler
+	}
+	var methods, functions, receivers []*graph.GraphNode
+	for _, node := range g.GetNodesByLabel(graph.NodeMethod) {
+		if matches(node) && !isDeclaration(node) {
+			methods = append(methods, node)
+			if route.Receiver != "" && node.ClassName == route.Receiver {
+				receivers = append(receivers, node)
+			}
+		}
+	}
+	for _, node := range g.GetNodesByLabel(graph.NodeFunction) {
+		if matches(node) && !isDeclaration(node) {
+			functions = append(functions, node)
+		}
+	}
+	for _, candidates := range [][]*graph.GraphNode{receivers, functions, methods} {
+		if node := closestNode(candidates, filePath); node != nil {
+			return node.ID
+		}
+	}
+	return ""
+}
+
+// closestNode returns the node whose file shares the longest directory
+// prefix with filePath, preferring filePath itself, or nil.
+func closestNode(nodes []*graph.GraphNode, filePath string) *graph.GraphNode {
+	var best *graph.GraphNode
+	bestScore := -1
+	for _, node := range nodes {
+		score := sharedDirs(node.FilePath, filePath)
+		if node.FilePath == filePath {
+			score = 1 << 30
+		}
+		if score > bestScore || score == bestScore && node.FilePath < best.FilePath {
+			best, bestScore = node, score
+		}
+	}
+	return best
+}
+
+// routeParam matches the path parameters of the supported frameworks:
+// :id (Express, gin, echo), <int:id> (Flask), {id}, {id:[0-9]+} (chi,
+// FastAPI, OpenAPI), {path...} (net/http) and *path (gin).
+var routeParam = regexp.MustCompile(`:(\w+)|<(?:[^<>:]+:)?(\w+)>|\{(\w+)(?:[:.][^}]*)?\}|\*(\w+)`)
+
+// normalizeRoutePath returns a route path with its parameters written
+// {name}, without the {$} end anchor of net/http patterns and without a
+// trailing slash.
+func normalizeRoutePath(path string) string {
+	path = strings.ReplaceAll(path, "{$}", "")
+	path = routeParam.ReplaceAllStringFunc(path, func(param string) string {
+		for _, name := range routeParam.FindStringSubmatch(param)[1:] {
+			if name != "" {
+				return "{" + name + "}"
+			}
+		}
+		return param
+	})
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if len(path) > 1 {
+		path = strings.TrimSuffix(path, "/")
+	}
+	return path
+}
+
+// routeMethodsMatch reports whether two routes serve a common method.
+func routeMethodsMatch(a, b string) bool {
+	return a == b || a == "ANY" || b == "ANY"
+}
+
+// routeParamName matches the parameter names of a normalized path.
+var routeParamName = regexp.MustCompile(`\{\w+\}`)
+
+// routePathsMatch reports whether two normalized paths are the same route,
+// whatever their parameters are named, possibly mounted under a prefix
+// one of them leaves out.
+func routePathsMatch(a, b string) bool {
+	a, b = routeParamName.ReplaceAllString(a, "{}"), routeParamName.ReplaceAllString(b, "{}")
+	if len(a) < len(b) {
+		a, b = b, a
+	}
+	// Both paths start with '/', so a suffix starts at a segment boundary
+	return a == b || b != "/" && strings.HasSuffix(a, b)
+}
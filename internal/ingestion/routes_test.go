@@ -0,0 +1,192 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestProcessRoutes(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"go.mod": "module example.com/users\n\ngo 1.22\n",
+		"cmd/server/main.go": `package main
+
+import (
+	"net/http"
+
+	"example.com/users/handlers"
+)
+
+func main() {
+	h := handlers.New()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/users", h.CreateUser)
+	mux.HandleFunc("GET /v1/users/{id}", h.GetUser)
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("."))))
+	http.ListenAndServe(":8080", mux)
+}
+`,
+		"handlers/users.go": `package handlers
+
+import "net/http"
+
+type Handler struct{}
+
+func New() *Handler { return &Handler{} }
+
+func (h *Handler) CreateUser(w http.ResponseWriter, r *http.Request) {
+	h.save()
+}
+
+func (h *Handler) GetUser(w http.ResponseWriter, r *http.Request) {}
+
+func (h *Handler) save() {}
+`,
+		"api/openapi.yaml": `openapi: 3.0.0
+info:
+  title: Users
+  version: "1"
+paths:
+  /users:
+    post:
+      operationId: createUser
+  /users/{userId}:
+    get:
+      summary: Look a user up
+`,
+		"app.py": `from flask import Flask
+
+app = Flask(__name__)
+
+
+@app.route("/ping")
+def ping():
+    return "pong"
+`,
+		"web/server.js": `const express = require('express');
+
+const app = express();
+app.use('/api', require('./routes/things'));
+`,
+		"web/routes/things.js": `const express = require('express');
+
+const router = express.Router();
+
+function create(req, res) {}
+
+router.post('/things', create);
+
+module.exports = router;
+`,
+		"config.yaml": "name: not-a-spec\n",
+	})
+
+	g, _, err := RunPipeline(context.Background(), dir, nil, false, nil, false, 0)
+	require.NoError(t, err)
+
+	mainFile := filepath.Join("cmd", "server", "main.go")
+	specFile := filepath.Join("api", "openapi.yaml")
+	handlersFile := filepath.Join("handlers", "users.go")
+	createUser := graph.GenerateID(graph.NodeMethod, handlersFile, "Handler.CreateUser")
+	getUser := graph.GenerateID(graph.NodeMethod, handlersFile, "Handler.GetUser")
+
+	handlers := func(endpointID string) []string {
+		var targets []string
+		for _, rel := range g.GetOutgoing(endpointID, graph.RelHandles) {
+			targets = append(targets, rel.Target)
+		}
+		return targets
+	}
+
+	t.Run("CreatesEndpoints", func(t *testing.T) {
+		endpoint := g.GetNode(graph.GenerateID(graph.NodeEndpoint, mainFile, "GET /v1/users/{id}"))
+		require.NotNil(t, endpoint)
+		assert.Equal(t, graph.NodeEndpoint, endpoint.Label)
+		assert.Equal(t, "GET /v1/users/{id}", endpoint.Name)
+		assert.Equal(t, "GET", endpoint.Properties["method"])
+		assert.Equal(t, "/v1/users/{id}", endpoint.Properties["path"])
+		assert.Equal(t, "net/http", endpoint.Properties["framework"])
+		assert.Equal(t, 13, endpoint.StartLine)
+		assert.True(t, g.HasIncoming(endpoint.ID, graph.RelDefines))
+
+		assert.NotNil(t, g.GetNode(graph.GenerateID(graph.NodeEndpoint, "app.py", "GET /ping")))
+		assert.Len(t, g.GetNodesByLabel(graph.NodeEndpoint), 7, "YAML files other than specs are not indexed")
+	})
+
+	t.Run("LinksHandlers", func(t *testing.T) {
+		assert.Equal(t, []string{createUser}, handlers(graph.GenerateID(graph.NodeEndpoint, mainFile, "POST /v1/users")))
+		assert.Equal(t, []string{getUser}, handlers(graph.GenerateID(graph.NodeEndpoint, mainFile, "GET /v1/users/{id}")))
+		assert.Equal(t, []string{graph.GenerateID(graph.NodeFunction, "app.py", "ping")},
+			handlers(graph.GenerateID(graph.NodeEndpoint, "app.py", "GET /ping")))
+		static := graph.GenerateID(graph.NodeEndpoint, mainFile, "ANY /static")
+		require.NotNil(t, g.GetNode(static))
+		assert.Empty(t, handlers(static),
+			"library handlers are not attributed to the registering function")
+		assert.True(t, g.GetNode(getUser).IsEntryPoint)
+		assert.False(t, g.GetNode(getUser).IsDead, "handlers are not dead code")
+	})
+
+	t.Run("LinksSpecOperations", func(t *testing.T) {
+		assert.ElementsMatch(t, []string{createUser}, handlers(graph.GenerateID(graph.NodeEndpoint, specFile, "POST /users")),
+			"by operationId and by path")
+		assert.ElementsMatch(t, []string{getUser}, handlers(graph.GenerateID(graph.NodeEndpoint, specFile, "GET /users/{userId}")),
+			"by path under a prefix, whatever the parameter names")
+	})
+
+	t.Run("AppliesMountPrefixes", func(t *testing.T) {
+		thingsFile := filepath.Join("web", "routes", "things.js")
+		assert.Equal(t, []string{graph.GenerateID(graph.NodeFunction, thingsFile, "create")},
+			handlers(graph.GenerateID(graph.NodeEndpoint, thingsFile, "POST /api/things")),
+			"routers are mounted by the file requiring them")
+	})
+
+	t.Run("FlowsStartFromEndpoints", func(t *testing.T) {
+		flows := make(map[string][]string)
+		for _, process := range g.GetNodesByLabel(graph.NodeProcess) {
+			for _, rel := range g.GetIncoming(process.ID, graph.RelStepInProcess) {
+				flows[process.Name] = append(flows[process.Name], rel.Source)
+			}
+		}
+		assert.Contains(t, flows, "Flow from POST /v1/users")
+		assert.Contains(t, flows["Flow from POST /v1/users"], graph.GenerateID(graph.NodeMethod, handlersFile, "Handler.save"))
+		assert.NotContains(t, flows, "Flow from CreateUser", "handlers are reached through their endpoints")
+	})
+}
+
+func TestNormalizeRoutePath(t *testing.T) {
+	t.Parallel()
+
+	for path, want := range map[string]string{
+		"/users/:id":              "/users/{id}",
+		"/users/<int:user_id>":    "/users/{user_id}",
+		"/users/<name>":           "/users/{name}",
+		"/users/{id:[0-9]+}":      "/users/{id}",
+		"/files/{path...}":        "/files/{path}",
+		"/files/{file_path:path}": "/files/{file_path}",
+		"/static/*filepath":       "/static/{filepath}",
+		"/{$}":                    "/",
+		"/users/":                 "/users",
+		"":                        "/",
+		"users":                   "/users",
+	} {
+		assert.Equal(t, want, normalizeRoutePath(path), path)
+	}
+}
+
+func TestRoutePathsMatch(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, routePathsMatch("/users/{id}", "/users/{userId}"))
+	assert.True(t, routePathsMatch("/api/v1/users", "/users"), "mount prefixes")
+	assert.True(t, routePathsMatch("/users", "/api/v1/users"))
+	assert.False(t, routePathsMatch("/myusers", "/users"), "whole segments")
+	assert.False(t, routePathsMatch("/users", "/"))
+	assert.False(t, routePathsMatch("/users/{id}", "/users"))
+}
@@ -0,0 +1,163 @@
+package ingestion
+
+import (
+	"context"
+	"sort"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// ProcessTables creates a table node for every SQL table or view, with a
+// column node for each of its columns, and READS_TABLE and WRITES_TABLE
+// relationships from the symbols whose queries access the tables. It
+// returns the number of tables created.
+//
+// A table is defined by the first statement that creates it, reading .sql
+// files (migrations, schemas) before the SQL literals of code and both in
+// path order; migrations are usually numbered, so that is the earliest
+// one. ALTER TABLE statements add columns to it, defined by the file of
+// the statement.
+//
+// Queries are attributed to the symbol they appear in, or to their file.
+// Only tables defined in the repository are linked: a name after FROM in a
+// string that only looks like SQL must name a known table to count.
+func ProcessTables(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph) int {
+	tables := make(map[string]*graph.GraphNode)
+	columns := make(map[string]bool)
+
+	filePaths := make([]string, 0, len(parseData.Files))
+	languages := make(map[string]string, len(parseData.Files))
+	for filePath, result := range parseData.Files {
+		if len(result.Tables) > 0 || len(result.TableRefs) > 0 {
+			filePaths = append(filePaths, filePath)
+			if file := g.GetNode(graph.GenerateID(graph.NodeFile, filePath, "")); file != nil {
+				languages[filePath] = file.Language
+			}
+		}
+	}
+	sort.Slice(filePaths, func(i, j int) bool {
+		return tableOrder(filePaths[i], languages[filePaths[i]]) < tableOrder(filePaths[j], languages[filePaths[j]])
+	})
+
+	count := 0
+	for _, filePath := range filePaths {
+		fileID := graph.GenerateID(graph.NodeFile, filePath, "")
+		for _, def := range parseData.Files[filePath].Tables {
+			table := tables[def.Name]
+			if table == nil {
+				table = newTableNode(filePath, languages[filePath], def)
+				g.AddNode(table)
+				g.AddRelationship(&graph.GraphRelationship{
+					ID:     relationshipID(graph.RelDefines, fileID, table.ID),
+					Type:   graph.RelDefines,
+					Source: fileID,
+					Target: table.ID,
+				})
+				tables[def.Name] = table
+				count++
+			} else if !def.IsAlter {
+				// Recreated by a later migration, or a copy in a test
+				continue
+			}
+
+			for _, column := range def.Columns {
+				key := table.Name + "." + column.Name
+				if columns[key] {
+					continue
+				}
+				columns[key] = true
+				signature := column.Name
+				if column.Type != "" {
+					signature += " " + column.Type
+				}
+				columnID := graph.GenerateID(graph.NodeColumn, filePath, key)
+				g.AddNode(&graph.GraphNode{
+					ID:        columnID,
+					Label:     graph.NodeColumn,
+					Name:      column.Name,
+					FilePath:  filePath,
+					StartLine: column.StartLine,
+					EndLine:   column.StartLine,
+					Signature: signature,
+					ClassName: table.Name,
+					Language:  languages[filePath],
+				})
+				g.AddRelationship(&graph.GraphRelationship{
+					ID:     relationshipID(graph.RelContains, table.ID, columnID),
+					Type:   graph.RelContains,
+					Source: table.ID,
+					Target: columnID,
+				})
+				g.AddRelationship(&graph.GraphRelationship{
+					ID:     relationshipID(graph.RelDefines, fileID, columnID),
+					Type:   graph.RelDefines,
+					Source: fileID,
+					Target: columnID,
+				})
+			}
+		}
+	}
+
+	for _, filePath := range filePaths {
+		result := parseData.Files[filePath]
+		for _, ref := range result.TableRefs {
+			table := tables[ref.Table]
+			if table == nil {
+				continue
+			}
+			relType := graph.RelReadsTable
+			if ref.Write {
+				relType = graph.RelWritesTable
+			}
+			sourceID := enclosingNodeID(result, filePath, ref.Enclosing, ref.EnclosingClass, ref.StartLine)
+			g.AddRelationship(&graph.GraphRelationship{
+				ID:     relationshipID(relType, sourceID, table.ID),
+				Type:   relType,
+				Source: sourceID,
+				Target: table.ID,
+			})
+		}
+	}
+
+	return count
+}
+
+// newTableNode returns the node of a table defined in filePath.
+func newTableNode(filePath, language string, def parsers.TableDef) *graph.GraphNode {
+	kind := "TABLE"
+	if def.IsView {
+		kind = "VIEW"
+	}
+	node := &graph.GraphNode{
+		ID:         graph.GenerateID(graph.NodeTable, filePath, def.Name),
+		Label:      graph.NodeTable,
+		Name:       def.Name,
+		FilePath:   filePath,
+		StartLine:  def.StartLine,
+		EndLine:    def.EndLine,
+		Content:    def.Content,
+		Signature:  "CREATE " + kind + " " + def.Name,
+		Language:   language,
+		Properties: map[string]any{},
+	}
+	if def.IsAlter {
+		node.Signature = "ALTER TABLE " + def.Name
+	}
+	if def.Schema != "" {
+		node.Properties["schema"] = def.Schema
+	}
+	if def.IsView {
+		node.Properties["view"] = true
+	}
+	return node
+}
+
+// tableOrder returns the sort key of the files that may define a table:
+// SQL files first, then by path.
+func tableOrder(filePath, language string) string {
+	if language == "sql" {
+		return "0" + filePath
+	}
+	return "1" + filePath
+}
@@ -0,0 +1,136 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/storage"
+)
+
+func TestProcessTables(t *testing.T) {
+	t.Parallel()
+
+	dir := writeRepo(t, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"db/migrations/001_users.sql": `CREATE TABLE users (
+    id    BIGSERIAL PRIMARY KEY,
+    email TEXT NOT NULL
+);
+`,
+		"db/migrations/002_orders.sql": `CREATE TABLE orders (
+    id      BIGSERIAL PRIMARY KEY,
+    user_id BIGINT REFERENCES users (id)
+);
+ALTER TABLE users ADD COLUMN name TEXT;
+`,
+		"store/users.go": `package store
+
+import "database/sql"
+
+const getUser = "SELECT id, email FROM users WHERE id = $1"
+
+type Store struct{ db *sql.DB }
+
+func (s *Store) GetUser(id int64) error {
+	return s.db.QueryRow(getUser, id).Err()
+}
+
+func (s *Store) PlaceOrder(userID int64) error {
+	_, err := s.db.Exec("INSERT INTO orders (user_id) VALUES ($1)", userID)
+	return err
+}
+
+func (s *Store) Audit() error {
+	_, err := s.db.Exec("INSERT INTO audit_log (event) VALUES ('x')")
+	return err
+}
+`,
+		"api/handlers.go": `package api
+
+import "example.com/shop/store"
+
+func Profile(s *store.Store) error {
+	return s.GetUser(1)
+}
+`,
+		"reports.py": `def active_users(cursor):
+    cursor.execute("SELECT u.* FROM users u JOIN orders o ON o.user_id = u.id")
+`,
+	})
+
+	g, _, err := RunPipeline(context.Background(), dir, nil, false, nil, false, 0)
+	require.NoError(t, err)
+
+	usersFile := filepath.Join("db", "migrations", "001_users.sql")
+	ordersFile := filepath.Join("db", "migrations", "002_orders.sql")
+	storeFile := filepath.Join("store", "users.go")
+	users := graph.GenerateID(graph.NodeTable, usersFile, "users")
+	orders := graph.GenerateID(graph.NodeTable, ordersFile, "orders")
+
+	accessors := func(tableID string, relType graph.RelType) []string {
+		var sources []string
+		for _, rel := range g.GetIncoming(tableID, relType) {
+			sources = append(sources, rel.Source)
+		}
+		return sources
+	}
+
+	t.Run("CreatesTablesAndColumns", func(t *testing.T) {
+		table := g.GetNode(users)
+		require.NotNil(t, table)
+		assert.Equal(t, graph.NodeTable, table.Label)
+		assert.Equal(t, "CREATE TABLE users", table.Signature)
+		assert.Equal(t, "sql", table.Language)
+		assert.True(t, g.HasIncoming(users, graph.RelDefines))
+		assert.False(t, table.IsDead, "tables are not dead code")
+
+		var columns []string
+		for _, rel := range g.GetOutgoing(users, graph.RelContains) {
+			columns = append(columns, g.GetNode(rel.Target).Signature)
+		}
+		assert.ElementsMatch(t, []string{"id BIGSERIAL", "email TEXT", "name TEXT"}, columns)
+
+		name := g.GetNode(graph.GenerateID(graph.NodeColumn, ordersFile, "users.name"))
+		require.NotNil(t, name, "columns added by a later migration belong to it")
+		assert.Equal(t, "users", name.ClassName)
+		assert.Len(t, g.GetNodesByLabel(graph.NodeTable), 2)
+	})
+
+	t.Run("LinksQueries", func(t *testing.T) {
+		getUser := graph.GenerateID(graph.NodeMethod, storeFile, "Store.GetUser")
+		activeUsers := graph.GenerateID(graph.NodeFunction, "reports.py", "active_users")
+
+		assert.ElementsMatch(t, []string{getUser, activeUsers}, accessors(users, graph.RelReadsTable))
+		assert.ElementsMatch(t, []string{activeUsers}, accessors(orders, graph.RelReadsTable))
+		assert.ElementsMatch(t, []string{graph.GenerateID(graph.NodeMethod, storeFile, "Store.PlaceOrder")},
+			accessors(orders, graph.RelWritesTable))
+		assert.Empty(t, g.GetOutgoing(graph.GenerateID(graph.NodeMethod, storeFile, "Store.Audit"), graph.RelWritesTable),
+			"tables the repository does not define")
+	})
+
+	t.Run("ImpactReachesCode", func(t *testing.T) {
+		store := storage.NewBadgerBackend()
+		require.NoError(t, store.Initialize(t.TempDir(), false))
+		defer store.Close()
+		require.NoError(t, store.BulkLoad(t.Context(), g))
+
+		nodes, err := store.Traverse(t.Context(), users, 2, "callers")
+		require.NoError(t, err)
+		byDepth, _ := storage.GroupByTraversal(nodes)
+
+		affected := make(map[string]int)
+		for depth, nodes := range byDepth {
+			for _, node := range nodes {
+				affected[node.ID] = depth
+			}
+		}
+		assert.Equal(t, 1, affected[graph.GenerateID(graph.NodeMethod, storeFile, "Store.GetUser")])
+		assert.Equal(t, 1, affected[graph.GenerateID(graph.NodeFunction, "reports.py", "active_users")])
+		assert.Equal(t, 2, affected[graph.GenerateID(graph.NodeFunction, filepath.Join("api", "handlers.go"), "Profile")])
+	})
+}
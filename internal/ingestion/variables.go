@@ -0,0 +1,110 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/parsers"
+)
+
+// ProcessVariables creates READS and WRITES relationships from the symbols
+// that refer to package-level variables and constants and to struct fields.
+// It returns the number of relationships created.
+//
+// Type-checked references are linked to their exact declaration. Others
+// are resolved by name: a field by the type of the receiver it is selected
+// on, a qualified name among the variables and constants of the package
+// directory of that name, and an unqualified name among those of the
+// directory of the referring file, a Go package. A reference that assigns
+// to a variable or field, or increments it, writes it.
+func ProcessVariables(ctx context.Context, parseData *ParseData, g *graph.KnowledgeGraph) int {
+	values := make(map[string][]*graph.GraphNode)
+	for _, label := range []graph.NodeLabel{graph.NodeVariable, graph.NodeConstant, graph.NodeField} {
+		for _, node := range g.GetNodesByLabel(label) {
+			values[node.Name] = append(values[node.Name], node)
+		}
+	}
+	if len(values) == 0 {
+		return 0
+	}
+
+	seen := make(map[string]bool)
+	for filePath, result := range parseData.Files {
+		if ctx.Err() != nil {
+			break
+		}
+		for _, ref := range result.VarRefs {
+			targetID, confidence := resolveVariableRef(g, values, ref, filePath)
+			if targetID == "" {
+				continue
+			}
+			sourceID := enclosingNodeID(result, filePath, ref.Enclosing, ref.EnclosingClass, ref.StartLine)
+			if sourceID == targetID {
+				continue
+			}
+
+			relType := graph.RelReads
+			if ref.Write {
+				relType = graph.RelWrites
+			}
+			relID := relationshipID(relType, sourceID, targetID)
+			if seen[relID] {
+				continue
+			}
+			seen[relID] = true
+			g.AddRelationship(&graph.GraphRelationship{
+				ID:     relID,
+				Type:   relType,
+				Source: sourceID,
+				Target: targetID,
+				Properties: map[string]any{
+					"confidence": confidence,
+				},
+			})
+		}
+	}
+	return len(seen)
+}
+
+// resolveVariableRef returns the variable, constant or field node a
+// reference refers to, together with the confidence of the resolution.
+func resolveVariableRef(g *graph.KnowledgeGraph, values map[string][]*graph.GraphNode, ref parsers.VariableRef, sourceFile string) (string, float64) {
+	if ref.Target != nil {
+		targetID := graph.GenerateID(ref.Target.Kind, ref.Target.FilePath,
+			symbolNodeName(ref.Target.Name, ref.Target.ClassName, ref.Target.Kind))
+		if g.GetNode(targetID) == nil {
+			return "", 0
+		}
+		return targetID, typedCallConfidence
+	}
+
+	dir := filepath.Dir(sourceFile)
+	pkgName := ref.Package
+	if idx := strings.LastIndex(pkgName, "/"); idx >= 0 {
+		pkgName = pkgName[idx+1:]
+	}
+
+	var candidates []*graph.GraphNode
+	for _, node := range values[ref.Name] {
+		nodeDir := filepath.Dir(node.FilePath)
+		switch {
+		case ref.Receiver != "":
+			if node.Label == graph.NodeField && node.ClassName == ref.Receiver {
+				candidates = append(candidates, node)
+			}
+		case node.Label == graph.NodeField:
+		case ref.Package != "":
+			if filepath.Base(nodeDir) == pkgName {
+				candidates = append(candidates, node)
+			}
+		case nodeDir == dir:
+			candidates = append(candidates, node)
+		}
+	}
+	if len(candidates) == 0 {
+		return "", 0
+	}
+	return closestNode(candidates, sourceFile).ID, heuristicCallConfidence
+}
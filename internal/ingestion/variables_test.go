@@ -0,0 +1,169 @@
+package ingestion
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+	"github.com/Benny93/axon-go/internal/storage"
+)
+
+func TestProcessVariables(t *testing.T) {
+	t.Parallel()
+
+	files := map[string]string{
+		"go.mod": "module example.com/app\n\ngo 1.22\n",
+		"config/config.go": `package config
+
+// Interval is the polling interval.
+var Interval = 5
+
+const retries = 3
+
+type Phase int
+
+const (
+	Parse Phase = iota
+	Link
+)
+
+type Config struct {
+	Timeout int
+}
+`,
+		"config/load.go": `package config
+
+func Load() Config {
+	return Config{Timeout: Interval * retries}
+}
+`,
+		"worker/worker.go": `package worker
+
+import "example.com/app/config"
+
+type Worker struct {
+	cfg config.Config
+}
+
+func (w *Worker) Tune(timeout int) {
+	config.Interval = timeout
+	w.cfg.Timeout = timeout
+}
+
+func Run() {
+	w := &Worker{}
+	w.Tune(config.Interval)
+	_ = config.Link
+}
+`,
+	}
+
+	configFile := filepath.Join("config", "config.go")
+	interval := graph.GenerateID(graph.NodeVariable, configFile, "Interval")
+	retries := graph.GenerateID(graph.NodeConstant, configFile, "retries")
+	timeout := graph.GenerateID(graph.NodeField, configFile, "Config.Timeout")
+	link := graph.GenerateID(graph.NodeConstant, configFile, "Link")
+	load := graph.GenerateID(graph.NodeFunction, filepath.Join("config", "load.go"), "Load")
+	tune := graph.GenerateID(graph.NodeMethod, filepath.Join("worker", "worker.go"), "Worker.Tune")
+	run := graph.GenerateID(graph.NodeFunction, filepath.Join("worker", "worker.go"), "Run")
+
+	sources := func(g *graph.KnowledgeGraph, id string, relType graph.RelType) []string {
+		var ids []string
+		for _, rel := range g.GetIncoming(id, relType) {
+			ids = append(ids, rel.Source)
+		}
+		return ids
+	}
+
+	t.Run("TypeChecked", func(t *testing.T) {
+		t.Parallel()
+
+		g, result, err := RunPipeline(context.Background(), writeRepo(t, files), nil, false, nil, false, 0)
+		require.NoError(t, err)
+		assert.Equal(t, 12, result.Symbols, "variables, constants and fields are symbols")
+
+		node := g.GetNode(interval)
+		require.NotNil(t, node)
+		assert.Equal(t, "Interval is the polling interval.", node.Doc)
+		assert.True(t, g.HasIncoming(interval, graph.RelDefines))
+		assert.Equal(t, graph.NodeEnum, g.GetNode(graph.GenerateID(graph.NodeEnum, configFile, "Phase")).Label)
+
+		assert.ElementsMatch(t, []string{load, run}, sources(g, interval, graph.RelReads))
+		assert.Equal(t, []string{tune}, sources(g, interval, graph.RelWrites))
+		assert.Equal(t, []string{load}, sources(g, retries, graph.RelReads))
+		assert.ElementsMatch(t, []string{load, tune}, sources(g, timeout, graph.RelWrites))
+		assert.Equal(t, []string{run}, sources(g, link, graph.RelReads))
+
+		rel := g.GetIncoming(interval, graph.RelReads)[0]
+		assert.Equal(t, typedCallConfidence, rel.Properties["confidence"])
+
+		assert.False(t, g.GetNode(retries).IsDead, "read constants are alive")
+	})
+
+	t.Run("ByName", func(t *testing.T) {
+		t.Parallel()
+
+		var entries []FileEntry
+		for path, content := range files {
+			if filepath.Ext(path) == ".go" {
+				entries = append(entries, FileEntry{RelPath: path, Content: []byte(content), Language: "go"})
+			}
+		}
+		g := graph.NewKnowledgeGraph()
+		ProcessStructure(context.Background(), entries, g)
+		parseData := ProcessParsing(context.Background(), entries, g)
+		ProcessVariables(context.Background(), parseData, g)
+
+		assert.ElementsMatch(t, []string{load, run}, sources(g, interval, graph.RelReads))
+		assert.Equal(t, []string{tune}, sources(g, interval, graph.RelWrites))
+		assert.Equal(t, []string{load}, sources(g, retries, graph.RelReads), "unqualified names resolve within the package")
+		assert.Equal(t, []string{load}, sources(g, timeout, graph.RelWrites), "fields are only resolved on receivers and literals")
+	})
+
+	t.Run("ImpactReachesReaders", func(t *testing.T) {
+		t.Parallel()
+
+		g, _, err := RunPipeline(context.Background(), writeRepo(t, files), nil, false, nil, false, 0)
+		require.NoError(t, err)
+
+		store := storage.NewBadgerBackend()
+		require.NoError(t, store.Initialize(t.TempDir(), false))
+		defer store.Close()
+		require.NoError(t, store.BulkLoad(t.Context(), g))
+
+		nodes, err := store.Traverse(t.Context(), interval, 1, "callers")
+		require.NoError(t, err)
+		var ids []string
+		for _, node := range nodes {
+			ids = append(ids, node.ID)
+		}
+		assert.ElementsMatch(t, []string{load, run, tune}, ids)
+	})
+}
+
+func TestProcessVariables_UnusedConstantIsDead(t *testing.T) {
+	t.Parallel()
+
+	entries := []FileEntry{{RelPath: "main.go", Language: "go", Content: []byte(`package main
+
+const unused = 1
+
+const used = 2
+
+func main() {
+	println(used)
+}
+`)}}
+	g := graph.NewKnowledgeGraph()
+	ProcessStructure(context.Background(), entries, g)
+	parseData := ProcessParsing(context.Background(), entries, g)
+	ProcessVariables(context.Background(), parseData, g)
+	ProcessDeadCode(context.Background(), g)
+
+	assert.True(t, g.GetNode(graph.GenerateID(graph.NodeConstant, "main.go", "unused")).IsDead)
+	assert.False(t, g.GetNode(graph.GenerateID(graph.NodeConstant, "main.go", "used")).IsDead)
+}
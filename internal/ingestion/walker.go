@@ -2,6 +2,7 @@
 package ingestion
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"io/fs"
@@ -10,6 +11,8 @@ import (
 	"strings"
 
 	"github.com/go-git/go-git/v5/plumbing/format/gitignore"
+
+	"github.com/Benny93/axon-go/internal/parsers"
 )
 
 // FileEntry represents a file to be processed.
@@ -35,20 +38,55 @@ type FileEntry struct {
 
 // Supported file extensions and their languages.
 var supportedExtensions = map[string]string{
-	".py":  "python",
-	".ts":  "typescript",
-	".tsx": "typescript",
-	".js":  "javascript",
-	".jsx": "javascript",
-	".mjs": "javascript",
-	".cjs": "javascript",
-	".go":  "go",
+	".py":       "python",
+	".ts":       "typescript",
+	".tsx":      "typescript",
+	".js":       "javascript",
+	".jsx":      "javascript",
+	".mjs":      "javascript",
+	".cjs":      "javascript",
+	".go":       "go",
+	".rs":       "rust",
+	".java":     "java",
+	".kt":       "kotlin",
+	".kts":      "kotlin",
+	".cs":       "csharp",
+	".c":        "c",
+	".h":        "c",
+	".cc":       "cpp",
+	".cpp":      "cpp",
+	".cxx":      "cpp",
+	".c++":      "cpp",
+	".hpp":      "cpp",
+	".hh":       "cpp",
+	".hxx":      "cpp",
+	".rb":       "ruby",
+	".rake":     "ruby",
+	".php":      "php",
+	".proto":    "proto",
+	".sql":      "sql",
+	".tf":       "terraform",
+	".md":       "markdown",
+	".markdown": "markdown",
+}
+
+// Extensions of the files that are indexed when their content is an
+// OpenAPI or Swagger specification, or, for YAML, a Kubernetes manifest.
+var apiSpecExtensions = map[string]bool{
+	".yaml": true,
+	".yml":  true,
+	".json": true,
 }
 
 // Default patterns to ignore (in addition to .gitignore).
 var defaultIgnorePatterns = []string{
 	".git/",
 	"node_modules/",
+	"target/",
+	".gradle/",
+	"obj/",
+	"CMakeFiles/",
+	"vendor/",
 	".axon/",
 	"__pycache__/",
 	".venv/",
@@ -68,8 +106,9 @@ var defaultIgnorePatterns = []string{
 	"Thumbs.db",
 }
 
-// WalkRepo walks the repository and returns all supported files.
-func WalkRepo(repoPath string, patterns []gitignore.Pattern) ([]FileEntry, error) {
+// WalkRepo walks the repository and returns all supported files. If ctx is
+// canceled it stops with ctx.Err() and the files found so far.
+func WalkRepo(ctx context.Context, repoPath string, patterns []gitignore.Pattern) ([]FileEntry, error) {
 	var entries []FileEntry
 
 	// Combine default patterns with loaded patterns
@@ -89,6 +128,9 @@ func WalkRepo(repoPath string, patterns []gitignore.Pattern) ([]FileEntry, error
 		if err != nil {
 			return err
 		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
 
 		// Skip directories we don't want to traverse
 		if d.IsDir() {
@@ -122,13 +164,18 @@ func WalkRepo(repoPath string, patterns []gitignore.Pattern) ([]FileEntry, error
 			return err
 		}
 
+		language := detectLanguage(d.Name(), content)
+		if language == "" {
+			return nil
+		}
+
 		// Compute SHA256 hash
 		hash := sha256.Sum256(content)
 
 		entries = append(entries, FileEntry{
 			Path:     path,
 			RelPath:  relPath,
-			Language: getLanguage(d.Name()),
+			Language: language,
 			Content:  content,
 			SHA256:   hex.EncodeToString(hash[:]),
 			IsDir:    false,
@@ -171,11 +218,12 @@ func loadGitignore(repoPath string) ([]gitignore.Pattern, error) {
 	return patterns, nil
 }
 
-// isSupportedFile checks if a file has a supported extension.
+// isSupportedFile checks if a file has a supported extension, one of an
+// API specification or a Kubernetes manifest, or is a Dockerfile.
 func isSupportedFile(filename string) bool {
 	ext := strings.ToLower(filepath.Ext(filename))
 	_, ok := supportedExtensions[ext]
-	return ok
+	return ok || apiSpecExtensions[ext] || parsers.IsDockerfile(filename)
 }
 
 // getLanguage returns the language for a file extension.
@@ -184,14 +232,40 @@ func getLanguage(filename string) string {
 	return supportedExtensions[ext]
 }
 
+// detectLanguage returns the language of a file from its name or
+// extension, or from its content for YAML and JSON files: "openapi" for an
+// API specification and "kubernetes" for a Kubernetes manifest.
+func detectLanguage(filename string, content []byte) string {
+	if language := getLanguage(filename); language != "" {
+		return language
+	}
+	if parsers.IsDockerfile(filename) {
+		return "dockerfile"
+	}
+	ext := strings.ToLower(filepath.Ext(filename))
+	if !apiSpecExtensions[ext] {
+		return ""
+	}
+	switch {
+	case parsers.IsAPISpec(content):
+		return "openapi"
+	case ext != ".json" && parsers.IsKubernetesManifest(content):
+		return "kubernetes"
+	}
+	return ""
+}
+
 // shouldSkipDir checks if a directory should be skipped.
 func shouldSkipDir(name, path, repoRoot string, matcher gitignore.Matcher) bool {
-	// Always skip .git
-	if name == ".git" {
+	// Always skip .git, and the index, which changes with every generation
+	if name == ".git" || name == ".axon" {
 		return true
 	}
 
 	// Check matcher
+	if matcher == nil {
+		return false
+	}
 	relPath, err := filepath.Rel(repoRoot, path)
 	if err != nil {
 		return false
@@ -1,6 +1,7 @@
 package ingestion
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"os"
@@ -24,7 +25,7 @@ func TestWalkRepo(t *testing.T) {
 		"test_main.py":        "def test_main(): pass",
 		"src/app.py":          "class App: pass",
 		"src/lib/utils.py":    "def util(): pass",
-		"README.md":           "# README",
+		"NOTES.txt":           "notes",
 		".gitignore":          "*.pyc\n__pycache__/",
 		"cache.pyc":           "binary",
 		"__pycache__/mod.pyc": "binary",
@@ -39,7 +40,7 @@ func TestWalkRepo(t *testing.T) {
 	}
 
 	t.Run("WalkAllSupportedFiles", func(t *testing.T) {
-		entries, err := WalkRepo(tmpDir, nil)
+		entries, err := WalkRepo(context.Background(), tmpDir, nil)
 		assert.NoError(t, err)
 
 		// Should find Python files
@@ -58,7 +59,7 @@ func TestWalkRepo(t *testing.T) {
 		patterns, err := loadGitignore(tmpDir)
 		require.NoError(t, err)
 
-		entries, err := WalkRepo(tmpDir, patterns)
+		entries, err := WalkRepo(context.Background(), tmpDir, patterns)
 		assert.NoError(t, err)
 
 		// Should NOT find .pyc files
@@ -69,17 +70,17 @@ func TestWalkRepo(t *testing.T) {
 	})
 
 	t.Run("SkipUnsupportedExtensions", func(t *testing.T) {
-		entries, err := WalkRepo(tmpDir, nil)
+		entries, err := WalkRepo(context.Background(), tmpDir, nil)
 		assert.NoError(t, err)
 
-		// Should NOT find .md files
+		// Should NOT find .txt files
 		for _, e := range entries {
-			assert.False(t, strings.HasSuffix(e.Path, ".md"))
+			assert.False(t, strings.HasSuffix(e.Path, ".txt"))
 		}
 	})
 
 	t.Run("SkipDirectories", func(t *testing.T) {
-		entries, err := WalkRepo(tmpDir, nil)
+		entries, err := WalkRepo(context.Background(), tmpDir, nil)
 		assert.NoError(t, err)
 
 		// All entries should be files
@@ -89,7 +90,7 @@ func TestWalkRepo(t *testing.T) {
 	})
 
 	t.Run("ComputeSHA256", func(t *testing.T) {
-		entries, err := WalkRepo(tmpDir, nil)
+		entries, err := WalkRepo(context.Background(), tmpDir, nil)
 		assert.NoError(t, err)
 
 		// Each entry should have a valid SHA256 hash
@@ -100,7 +101,7 @@ func TestWalkRepo(t *testing.T) {
 	})
 
 	t.Run("DetectLanguage", func(t *testing.T) {
-		entries, err := WalkRepo(tmpDir, nil)
+		entries, err := WalkRepo(context.Background(), tmpDir, nil)
 		assert.NoError(t, err)
 
 		// Check language detection
@@ -158,7 +159,13 @@ func TestIsSupportedFile(t *testing.T) {
 		{"MJS", "module.mjs", true},
 		{"CJS", "module.cjs", true},
 		{"Go", "main.go", true}, // Go support added
-		{"Markdown", "README.md", false},
+		{"YAML", "openapi.yaml", true},
+		{"JSON", "swagger.json", true},
+		{"SQL", "001_init.sql", true},
+		{"Terraform", "main.tf", true},
+		{"Dockerfile", "Dockerfile", true},
+		{"NamedDockerfile", "api.Dockerfile", true},
+		{"Markdown", "README.md", true},
 		{"Text", "file.txt", false},
 		{"Binary", "image.png", false},
 	}
@@ -172,6 +179,36 @@ func TestIsSupportedFile(t *testing.T) {
 	}
 }
 
+func TestDetectLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		filename string
+		content  string
+		expected string
+	}{
+		{"Source", "main.go", "package main", "go"},
+		{"OpenAPIYAML", "api/openapi.yaml", "# Users API\nopenapi: 3.0.3\ninfo:\n  title: Users\n", "openapi"},
+		{"SwaggerJSON", "swagger.json", `{"swagger": "2.0", "paths": {}}`, "openapi"},
+		{"IndentedJSON", "spec.json", "{\n  \"openapi\": \"3.1.0\"\n}", "openapi"},
+		{"OtherYAML", "deploy.yml", "name: deploy\non: push\n", ""},
+		{"Terraform", "infra/main.tf", "resource \"aws_s3_bucket\" \"logs\" {}", "terraform"},
+		{"KubernetesManifest", "deploy/api.yaml", "apiVersion: apps/v1\nkind: Deployment\n", "kubernetes"},
+		{"Dockerfile", "Dockerfile", "FROM golang:1.22", "dockerfile"},
+		{"DockerfileVariant", "build/Dockerfile.worker", "FROM alpine", "dockerfile"},
+		{"GoNamedDockerfile", "dockerfile.go", "package main", "go"},
+		{"PackageJSON", "package.json", `{"name": "app", "version": "3.0.0"}`, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, detectLanguage(tt.filename, []byte(tt.content)))
+		})
+	}
+}
+
 func TestGetLanguage(t *testing.T) {
 	t.Parallel()
 
@@ -188,6 +225,7 @@ func TestGetLanguage(t *testing.T) {
 		{"MJS", "module.mjs", "javascript"},
 		{"CJS", "module.cjs", "javascript"},
 		{"Go", "main.go", "go"}, // Go support added
+		{"Markdown", "docs/design.md", "markdown"},
 		{"Unknown", "file.txt", ""},
 	}
 
@@ -212,7 +250,7 @@ func TestFileEntry_HashConsistency(t *testing.T) {
 	require.NoError(t, err)
 
 	// Walk the repo
-	entries, err := WalkRepo(tmpDir, nil)
+	entries, err := WalkRepo(context.Background(), tmpDir, nil)
 	require.NoError(t, err)
 	require.Len(t, entries, 1)
 
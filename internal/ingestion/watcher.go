@@ -20,7 +20,14 @@ const GLOBAL_PHASE_INTERVAL = 30 * time.Second
 
 // WatchRepo monitors a repository for file changes and re-indexes automatically.
 // Blocks until the context is cancelled.
-func WatchRepo(ctx context.Context, repoPath string, store storage.StorageBackend) error {
+//
+// Each batch of changes is indexed into a new generation of gens (see
+// IndexGeneration), which only parses the changed files. Readers switch to
+// it once it is published. The global phases (execution flows, communities
+// and git coupling) run at most once per GLOBAL_PHASE_INTERVAL; batches in
+// between reuse their results from the previous batch. Batches compute no
+// embeddings but keep those of the current generation.
+func WatchRepo(ctx context.Context, repoPath string, gens *storage.Generations) error {
 	// Load gitignore patterns
 	matcher, err := loadGitignoreMatcher(repoPath)
 	if err != nil && err != context.Canceled {
@@ -55,15 +62,16 @@ func WatchRepo(ctx context.Context, repoPath string, store storage.StorageBacken
 		return fmt.Errorf("setting up watcher: %w", err)
 	}
 
-	// Track last re-index time for global phases
-	lastGlobalPhase := time.Now()
-
 	// Batch changed files for efficient re-indexing
 	changedFiles := make(map[string]bool)
 	batchTimer := time.NewTimer(2 * time.Second)
 	batchTimer.Stop() // Don't start yet
 
-	fmt.Printf("Watching %s for changes (Ctrl+C to stop)\n", repoPath)
+	// The graph of the last batch, and when its global phases last ran
+	var last *graph.KnowledgeGraph
+	var lastGlobalPhase time.Time
+
+	fmt.Fprintf(os.Stderr, "Watching %s for changes (Ctrl+C to stop)\n", repoPath)
 
 	for {
 		select {
@@ -98,21 +106,25 @@ func WatchRepo(ctx context.Context, repoPath string, store storage.StorageBacken
 			fmt.Fprintf(os.Stderr, "Watch error: %v\n", err)
 
 		case <-batchTimer.C:
-			// Process batch of changed files
+			// Index the batch of changed files
 			if len(changedFiles) > 0 {
-				err := processChangedFiles(ctx, repoPath, store, changedFiles, matcher)
-				if err != nil && err != context.Canceled {
+				fmt.Fprintf(os.Stderr, "Re-indexing %d changed file(s)...\n", len(changedFiles))
+				reuse := &generationReuse{embeddings: true}
+				if last != nil && !shouldReindexGlobalPhases(lastGlobalPhase) {
+					reuse.globals = last
+				} else {
+					fmt.Fprintln(os.Stderr, "Running global analysis phases...")
+				}
+				gen, g, result, err := indexGeneration(ctx, repoPath, gens, false, nil, false, 0, reuse)
+				if err != nil && ctx.Err() == nil {
 					fmt.Fprintf(os.Stderr, "Error processing changes: %v\n", err)
 				}
-
-				// Check if global phases should run
-				if shouldReindexGlobalPhases(lastGlobalPhase) {
-					fmt.Println("Running global analysis phases...")
-					_, _, err := RunPipeline(ctx, repoPath, store, false, nil, false)
-					if err != nil && err != context.Canceled {
-						fmt.Fprintf(os.Stderr, "Error in global phases: %v\n", err)
+				if err == nil {
+					fmt.Fprintf(os.Stderr, "  Re-indexed %d file(s) into generation %s\n", result.ParsedFiles, gen.Name)
+					last = g
+					if reuse.globals == nil {
+						lastGlobalPhase = time.Now()
 					}
-					lastGlobalPhase = time.Now()
 				}
 
 				changedFiles = make(map[string]bool)
@@ -121,69 +133,6 @@ func WatchRepo(ctx context.Context, repoPath string, store storage.StorageBacken
 	}
 }
 
-// processChangedFiles re-indexes the changed files.
-func processChangedFiles(ctx context.Context, repoPath string, store storage.StorageBackend, changedFiles map[string]bool, matcher gitignore.Matcher) error {
-	if len(changedFiles) == 0 {
-		return nil
-	}
-
-	fmt.Printf("Re-indexing %d changed file(s)...\n", len(changedFiles))
-
-	// Build file entries for changed files
-	entries := make([]FileEntry, 0, len(changedFiles))
-	for relPath := range changedFiles {
-		absPath := filepath.Join(repoPath, relPath)
-
-		// Check if file exists (wasn't deleted)
-		info, err := os.Stat(absPath)
-		if os.IsNotExist(err) {
-			// File was deleted - remove from storage
-			if _, err := store.RemoveNodesByFile(ctx, relPath); err != nil && err != context.Canceled {
-				fmt.Fprintf(os.Stderr, "Error removing deleted file %s: %v\n", relPath, err)
-			} else {
-				fmt.Printf("  Removed: %s\n", relPath)
-			}
-			continue
-		}
-
-		if err != nil && err != context.Canceled {
-			continue
-		}
-
-		if info.IsDir() {
-			continue
-		}
-
-		// Read file content
-		content, err := os.ReadFile(absPath)
-		if err != nil && err != context.Canceled {
-			fmt.Fprintf(os.Stderr, "Error reading %s: %v\n", relPath, err)
-			continue
-		}
-
-		// Detect language
-		language := getLanguage(relPath)
-		if language == "" {
-			continue // Unsupported file type
-		}
-
-		entries = append(entries, FileEntry{
-			RelPath:  relPath,
-			Path:     absPath,
-			Language: language,
-			Content:  content,
-		})
-	}
-
-	// Re-index changed files
-	if len(entries) > 0 {
-		count := ReindexFiles(entries, repoPath, store)
-		fmt.Printf("  Re-indexed %d file(s)\n", count)
-	}
-
-	return nil
-}
-
 // shouldWatchFile checks if a file should be watched.
 func shouldWatchFile(path string, repoPath string, matcher gitignore.Matcher) bool {
 	// Get relative path
@@ -201,8 +150,7 @@ func shouldWatchFile(path string, repoPath string, matcher gitignore.Matcher) bo
 	}
 
 	// Check if supported file type
-	language := getLanguage(path)
-	return language != ""
+	return isSupportedFile(path)
 }
 
 // shouldReindexGlobalPhases checks if enough time has passed since last global phase run.
@@ -214,8 +162,7 @@ func shouldReindexGlobalPhases(lastGlobalPhase time.Time) bool {
 func filterChangedPaths(paths []string) []string {
 	filtered := make([]string, 0, len(paths))
 	for _, path := range paths {
-		language := getLanguage(path)
-		if language != "" {
+		if isSupportedFile(path) {
 			filtered = append(filtered, path)
 		}
 	}
@@ -235,58 +182,6 @@ func filterChangedPathsWithIgnore(paths []string, repoPath string, matcher gitig
 	return filtered
 }
 
-// ReindexFiles re-indexes the given files, removing old nodes first.
-// Returns the number of files successfully re-indexed.
-func ReindexFiles(entries []FileEntry, repoPath string, store storage.StorageBackend) int {
-	if len(entries) == 0 {
-		return 0
-	}
-
-	// Remove old nodes for these files
-	for _, entry := range entries {
-		_, _ = store.RemoveNodesByFile(context.Background(), entry.RelPath)
-	}
-
-	// Process file-local phases only (not global phases)
-	g, err := processFileLocalPhases(entries)
-	if err != nil && err != context.Canceled {
-		return 0
-	}
-
-	// Load to storage
-	err = store.BulkLoad(context.Background(), g)
-	if err != nil && err != context.Canceled {
-		return 0
-	}
-
-	return len(entries)
-}
-
-// processFileLocalPhases runs file-local phases (2-7) on the given entries.
-func processFileLocalPhases(entries []FileEntry) (*graph.KnowledgeGraph, error) {
-	g := graph.NewKnowledgeGraph()
-
-	// Phase 2: Structure
-	ProcessStructure(entries, g)
-
-	// Phase 3: Parsing
-	parseData := ProcessParsing(entries, g)
-
-	// Phase 4: Imports
-	ProcessImports(parseData, g)
-
-	// Phase 5: Calls
-	ProcessCalls(parseData, g)
-
-	// Phase 6: Heritage
-	ProcessHeritage(parseData, g)
-
-	// Phase 7: Types
-	ProcessTypes(parseData, g)
-
-	return g, nil
-}
-
 // loadGitignoreMatcher loads a gitignore matcher from the repository root.
 func loadGitignoreMatcher(repoPath string) (gitignore.Matcher, error) {
 	gitignorePath := filepath.Join(repoPath, ".gitignore")
@@ -1,6 +1,7 @@
 package ingestion
 
 import (
+	"context"
 	"os"
 	"path/filepath"
 	"testing"
@@ -13,145 +14,6 @@ import (
 	"github.com/Benny93/axon-go/internal/storage"
 )
 
-func TestReindexFiles(t *testing.T) {
-	t.Parallel()
-
-	t.Run("ReindexesChangedFiles", func(t *testing.T) {
-		store := storage.NewBadgerBackend()
-		tmpDir := t.TempDir()
-		err := store.Initialize(tmpDir, false)
-		require.NoError(t, err)
-		defer store.Close()
-
-		// Create initial graph
-		g := graph.NewKnowledgeGraph()
-		g.AddNode(&graph.GraphNode{
-			ID:        "function:test.go:OldFunc",
-			Label:     graph.NodeFunction,
-			Name:      "OldFunc",
-			FilePath:  "test.go",
-			Content:   "func OldFunc() {}",
-			Signature: "OldFunc()",
-		})
-
-		err = store.BulkLoad(t.Context(), g)
-		require.NoError(t, err)
-
-		// Create file entries for re-indexing
-		entries := []FileEntry{
-			{
-				RelPath:  "test.go",
-				Path:     filepath.Join(tmpDir, "test.go"),
-				Language: "go",
-				Content:  []byte("package main\n\nfunc NewFunc() {}"),
-			},
-		}
-
-		// Re-index files
-		count := ReindexFiles(entries, tmpDir, store)
-
-		assert.Equal(t, 1, count)
-
-		// Verify old node is removed and new node exists
-		nodes := store.GetNodesByLabel(t.Context(), "function")
-		assert.NotEmpty(t, nodes)
-
-		found := false
-		for _, node := range nodes {
-			if node.Name == "NewFunc" {
-				found = true
-				break
-			}
-		}
-		assert.True(t, found, "Should find NewFunc after re-indexing")
-	})
-
-	t.Run("HandlesFileDeletion", func(t *testing.T) {
-		store := storage.NewBadgerBackend()
-		tmpDir := t.TempDir()
-		err := store.Initialize(tmpDir, false)
-		require.NoError(t, err)
-		defer store.Close()
-
-		// Create initial graph with a file
-		g := graph.NewKnowledgeGraph()
-		g.AddNode(&graph.GraphNode{
-			ID:        "function:deleted.go:SomeFunc",
-			Label:     graph.NodeFunction,
-			Name:      "SomeFunc",
-			FilePath:  "deleted.go",
-			Content:   "func SomeFunc() {}",
-			Signature: "SomeFunc()",
-		})
-
-		err = store.BulkLoad(t.Context(), g)
-		require.NoError(t, err)
-
-		// Re-index with empty entries (simulating file deletion)
-		// Note: ReindexFiles only removes nodes for files in the entries list
-		// Actual file deletion handling happens in processChangedFiles
-		entries := []FileEntry{}
-		count := ReindexFiles(entries, tmpDir, store)
-
-		assert.Equal(t, 0, count)
-
-		// Nodes are not automatically removed - caller must handle deletions
-		// This test verifies ReindexFiles behavior with empty entries
-		nodes := store.GetNodesByLabel(t.Context(), "function")
-		assert.NotEmpty(t, nodes, "Nodes remain until explicitly removed")
-	})
-
-	t.Run("SkipsUnsupportedFiles", func(t *testing.T) {
-		store := storage.NewBadgerBackend()
-		tmpDir := t.TempDir()
-		err := store.Initialize(tmpDir, false)
-		require.NoError(t, err)
-		defer store.Close()
-
-		// Create file entry for unsupported file type
-		entries := []FileEntry{
-			{
-				RelPath:  "README.md",
-				Path:     filepath.Join(tmpDir, "README.md"),
-				Language: "", // Unsupported
-				Content:  []byte("# README"),
-			},
-		}
-
-		// ReindexFiles processes entries passed to it
-		// Filtering is caller's responsibility
-		count := ReindexFiles(entries, tmpDir, store)
-
-		// Returns count of entries passed
-		assert.Equal(t, 1, count)
-	})
-
-	t.Run("SkipsIgnoredFiles", func(t *testing.T) {
-		store := storage.NewBadgerBackend()
-		tmpDir := t.TempDir()
-		err := store.Initialize(tmpDir, false)
-		require.NoError(t, err)
-		defer store.Close()
-
-		// Create file entry that should be ignored
-		entries := []FileEntry{
-			{
-				RelPath:  ".git/config",
-				Path:     filepath.Join(tmpDir, ".git", "config"),
-				Language: "",
-				Content:  []byte("[core]"),
-			},
-		}
-
-		// ReindexFiles processes entries passed to it
-		// Filtering is caller's responsibility
-		count := ReindexFiles(entries, tmpDir, store)
-
-		// Returns count of entries passed
-		assert.Equal(t, 1, count)
-	})
-}
-
 func TestWatchRepo(t *testing.T) {
 	t.Parallel()
 
@@ -170,7 +32,7 @@ func TestWatchRepo(t *testing.T) {
 		defer store.Close()
 
 		// Initial index
-		_, _, err = RunPipeline(t.Context(), tmpDir, store, false, nil, false)
+		_, _, err = RunPipeline(t.Context(), tmpDir, store, false, nil, false, 0)
 		require.NoError(t, err)
 
 		// Modify the file
@@ -212,7 +74,7 @@ func TestWatchRepo(t *testing.T) {
 		defer store.Close()
 
 		// Initial index
-		_, _, err = RunPipeline(t.Context(), tmpDir, store, false, nil, false)
+		_, _, err = RunPipeline(t.Context(), tmpDir, store, false, nil, false, 0)
 		require.NoError(t, err)
 
 		// Verify files were indexed
@@ -220,6 +82,40 @@ func TestWatchRepo(t *testing.T) {
 		assert.Greater(t, len(nodes), 0)
 	})
 
+	t.Run("PublishesGenerations", func(t *testing.T) {
+		dir := writeRepo(t, map[string]string{
+			"main.go": "package main\n\nfunc main() {}\n",
+		})
+		gens := storage.NewGenerations(filepath.Join(t.TempDir(), ".axon"), 1)
+		first, _, err := IndexGeneration(t.Context(), dir, gens, false, nil, false, 0)
+		require.NoError(t, err)
+
+		ctx, cancel := context.WithCancel(t.Context())
+		done := make(chan error, 1)
+		go func() { done <- WatchRepo(ctx, dir, gens) }()
+		defer func() {
+			cancel()
+			<-done
+		}()
+
+		// Give the watcher time to add the directories
+		time.Sleep(200 * time.Millisecond)
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n\nfunc main() {}\n\nfunc added() {}\n"), 0o644))
+
+		var current storage.Generation
+		require.Eventually(t, func() bool {
+			current, err = gens.Current()
+			return err == nil && current.Name != first.Name
+		}, 10*time.Second, 100*time.Millisecond)
+
+		store := storage.NewBadgerBackend()
+		require.NoError(t, store.Initialize(current.Path, true))
+		defer store.Close()
+		node, err := store.GetNode(t.Context(), "function:main.go:added")
+		require.NoError(t, err)
+		assert.NotNil(t, node, "the new generation holds the change")
+	})
+
 	t.Run("RespectsGitignore", func(t *testing.T) {
 		tmpDir := t.TempDir()
 
@@ -245,6 +141,59 @@ func TestWatchRepo(t *testing.T) {
 	})
 }
 
+func TestIndexGeneration_WatchBatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	dir := writeRepo(t, map[string]string{
+		"main.go":   "package main\n\nfunc main() {\n\thelper()\n}\n",
+		"helper.go": "package main\n\nfunc helper() {\n\tassist()\n}\n\nfunc assist() {}\n",
+		"old.go":    "package main\n\nfunc old() {}\n",
+	})
+	gens := storage.NewGenerations(filepath.Join(t.TempDir(), ".axon"), 1)
+
+	_, first, _, err := indexGeneration(ctx, dir, gens, false, nil, true, 0, nil)
+	require.NoError(t, err)
+	processes := first.GetNodesByLabel(graph.NodeProcess)
+	require.NotEmpty(t, processes)
+	require.NotEmpty(t, first.GetNodesByLabel(graph.NodeCommunity))
+
+	require.NoError(t, os.Remove(filepath.Join(dir, "old.go")))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "new.go"), []byte("package main\n\nfunc main2() {}\n"), 0o644))
+
+	reuse := &generationReuse{globals: first, embeddings: true}
+	_, g, result, err := indexGeneration(ctx, dir, gens, false, nil, false, 0, reuse)
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.ParsedFiles)
+
+	t.Run("ReusesGlobalResults", func(t *testing.T) {
+		assert.Len(t, g.GetNodesByLabel(graph.NodeProcess), len(processes), "no flow is detected again")
+		assert.NotEmpty(t, g.GetIncoming(processes[0].ID, graph.RelStepInProcess), "steps are kept")
+		for _, community := range g.GetNodesByLabel(graph.NodeCommunity) {
+			assert.NotContains(t, community.Properties["members"], "function:old.go:old", "removed members are dropped")
+			for _, rel := range g.GetIncoming(community.ID, graph.RelMemberOf) {
+				assert.NotNil(t, g.GetNode(rel.Source))
+			}
+		}
+	})
+
+	t.Run("KeepsEmbeddings", func(t *testing.T) {
+		store, _, err := gens.OpenCurrent()
+		require.NoError(t, err)
+		defer store.Close()
+
+		embeddings, err := store.Embeddings(ctx)
+		require.NoError(t, err)
+		ids := make(map[string]bool)
+		for _, emb := range embeddings {
+			ids[emb.NodeID] = true
+		}
+		assert.True(t, ids[graph.GenerateID(graph.NodeFunction, "helper.go", "helper")])
+		assert.False(t, ids[graph.GenerateID(graph.NodeFunction, "old.go", "old")], "removed nodes lose their embeddings")
+		assert.False(t, ids[graph.GenerateID(graph.NodeFunction, "new.go", "main2")], "no embedding is computed")
+	})
+}
+
 func TestShouldReindexGlobalPhases(t *testing.T) {
 	t.Parallel()
 
@@ -270,7 +219,7 @@ func TestFilterChangedPaths(t *testing.T) {
 		paths := []string{
 			"file.go",
 			"file.py",
-			"README.md",
+			"NOTES.txt",
 			"image.png",
 		}
 
@@ -0,0 +1,1412 @@
+package parsers
+
+import (
+	"path/filepath"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// CParser parses C and C++ source code.
+//
+// The source is tokenized (c_lexer.go) and parsed declaration by
+// declaration, following the brace structure of namespaces, extern "C"
+// blocks and classes, without running the preprocessor: #include
+// directives become imports and other directives are ignored. Function
+// definitions are symbols whose bodies are scanned for calls; prototypes
+// and member functions declared without a body are symbols too, marked as
+// declarations, so that a header's declarations can be linked to their
+// definitions. Out-of-line member definitions (void Repo::save() {}) are
+// methods of their class. Structs, classes and unions are classes, and
+// their bases are EXTENDS relationships.
+//
+// One grammar covers both languages; the parsers differ in the files they
+// handle and the language they report. Headers (.h) belong to the C parser
+// even when they hold C++ declarations.
+type CParser struct {
+	language string
+}
+
+// NewCParser creates a new C parser.
+func NewCParser() *CParser {
+	return &CParser{language: "c"}
+}
+
+// NewCPPParser creates a new C++ parser.
+func NewCPPParser() *CParser {
+	return &CParser{language: "cpp"}
+}
+
+// Language returns the language this parser handles.
+func (p *CParser) Language() string {
+	return p.language
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *CParser) SupportsFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".c", ".h":
+		return p.language == "c"
+	case ".cc", ".cpp", ".cxx", ".c++", ".hpp", ".hh", ".hxx":
+		return p.language == "cpp"
+	}
+	return false
+}
+
+// Parse parses C or C++ source code and extracts symbols, imports, calls,
+// etc.
+func (p *CParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	source := string(content)
+	cp := &cParser{
+		source:     source,
+		fields:     make(map[string]map[string]string),
+		fieldCalls: make(map[int]string),
+		heritage:   make(map[string]int),
+		namespaces: map[string]bool{"std": true},
+		result: &ParseResult{
+			Symbols:  []ParsedSymbol{},
+			Imports:  []ImportStatement{},
+			Calls:    []CallSite{},
+			TypeRefs: []TypeAnnotation{},
+			Heritage: []ClassHeritage{},
+		},
+	}
+	for _, t := range lexC(source) {
+		if t.kind == cDirective {
+			if imp, ok := cInclude(t, 0); ok {
+				cp.result.Imports = append(cp.result.Imports, imp)
+			}
+			continue
+		}
+		cp.toks = append(cp.toks, t)
+	}
+	cp.matchBrackets()
+	cp.declarations(len(cp.toks), cScope{public: true})
+	cp.finish()
+	setDocComments(cp.result, source, cDocComments)
+	return cp.result, nil
+}
+
+// cIncludes returns the #include directives of C source whose first line
+// is line firstLine of its file, as imports.
+func cIncludes(source string, firstLine int) []ImportStatement {
+	var imports []ImportStatement
+	for _, t := range lexC(source) {
+		if t.kind != cDirective {
+			continue
+		}
+		if imp, ok := cInclude(t, firstLine-1); ok {
+			imports = append(imports, imp)
+		}
+	}
+	return imports
+}
+
+// cInclude converts an #include directive to an import of the included
+// file, whose lines are offset by lineOffset. Quoted includes are relative
+// to the including file; <bracketed> ones are searched on the include path
+// only. Includes of macros are ignored.
+func cInclude(t cToken, lineOffset int) (ImportStatement, bool) {
+	text, ok := strings.CutPrefix(t.text, "include")
+	if !ok {
+		return ImportStatement{}, false
+	}
+	// #include_next continues the search for the same header
+	text = strings.TrimSpace(strings.TrimPrefix(text, "_next"))
+	var closing byte
+	switch {
+	case strings.HasPrefix(text, `"`):
+		closing = '"'
+	case strings.HasPrefix(text, "<"):
+		closing = '>'
+	default:
+		return ImportStatement{}, false
+	}
+	end := strings.IndexByte(text[1:], closing)
+	if end <= 0 {
+		return ImportStatement{}, false
+	}
+	return ImportStatement{
+		ModulePath: text[1 : end+1],
+		IsRelative: closing == '"',
+		StartLine:  t.line + lineOffset,
+	}, true
+}
+
+// cPrimitives are the fundamental types and the standard integer typedefs,
+// which are never declared in the repository.
+var cPrimitives = map[string]bool{
+	"void": true, "char": true, "short": true, "int": true, "long": true,
+	"float": true, "double": true, "signed": true, "unsigned": true,
+	"bool": true, "_Bool": true, "wchar_t": true, "char8_t": true,
+	"char16_t": true, "char32_t": true, "auto": true,
+	"size_t": true, "ssize_t": true, "ptrdiff_t": true, "intptr_t": true, "uintptr_t": true,
+	"int8_t": true, "int16_t": true, "int32_t": true, "int64_t": true,
+	"uint8_t": true, "uint16_t": true, "uint32_t": true, "uint64_t": true,
+	"nullptr_t": true,
+}
+
+// cTypeKeywords are the keywords and specifiers that appear in
+// declarations around types.
+var cTypeKeywords = map[string]bool{
+	"const": true, "volatile": true, "struct": true, "class": true, "union": true,
+	"enum": true, "typename": true, "static": true, "inline": true, "extern": true,
+	"register": true, "mutable": true, "constexpr": true, "consteval": true,
+	"constinit": true, "restrict": true, "__restrict": true, "_Atomic": true,
+	"virtual": true, "explicit": true, "friend": true, "thread_local": true,
+	"template": true, "noexcept": true, "override": true, "final": true,
+	"__inline": true, "__forceinline": true, "_Noreturn": true, "typedef": true,
+}
+
+// cSpecifiers are the declaration specifiers skipped before a declaration;
+// static is handled apart, as it makes a declaration file-local.
+var cSpecifiers = map[string]bool{
+	"inline": true, "extern": true, "virtual": true, "explicit": true,
+	"constexpr": true, "consteval": true, "constinit": true, "thread_local": true,
+	"register": true, "mutable": true, "_Noreturn": true, "__inline": true,
+	"__inline__": true, "__forceinline": true, "_Thread_local": true,
+}
+
+// cAttributeKeywords are the compiler attribute keywords, which take a
+// parenthesized argument.
+var cAttributeKeywords = map[string]bool{
+	"__attribute__": true, "__declspec": true, "alignas": true, "_Alignas": true,
+	"__asm__": true, "asm": true, "decltype": true,
+}
+
+// cTypeWrappers are the smart pointers and wrappers whose template argument
+// is the type a variable holds: std::unique_ptr<Repository>.
+var cTypeWrappers = map[string]bool{
+	"unique_ptr": true, "shared_ptr": true, "weak_ptr": true, "optional": true,
+	"reference_wrapper": true, "atomic": true,
+}
+
+// cNotCalled are the keywords and operators that precede a parenthesis
+// without being calls.
+var cNotCalled = map[string]bool{
+	"if": true, "while": true, "for": true, "switch": true, "return": true,
+	"sizeof": true, "alignof": true, "_Alignof": true, "decltype": true,
+	"typeof": true, "__typeof__": true, "catch": true, "defined": true,
+	"static_assert": true, "_Static_assert": true, "static_cast": true,
+	"dynamic_cast": true, "const_cast": true, "reinterpret_cast": true,
+	"noexcept": true, "__attribute__": true, "__declspec": true, "alignas": true,
+	"throw": true, "typeid": true, "operator": true, "requires": true,
+	"co_await": true, "co_return": true, "co_yield": true, "do": true,
+	"else": true, "case": true, "__asm__": true, "asm": true, "delete": true,
+}
+
+// cExpressionKeywords are the keywords after which a name followed by a
+// parenthesis is a call rather than a declared variable.
+var cExpressionKeywords = map[string]bool{
+	"return": true, "else": true, "case": true, "new": true, "throw": true,
+	"co_return": true, "co_await": true, "co_yield": true, "do": true,
+}
+
+// cScope describes the block declarations are parsed in.
+type cScope struct {
+	// class is the class whose members are parsed ("" outside classes)
+	class string
+
+	// public is true while the declarations are visible outside their class
+	// or file
+	public bool
+
+	// internal is true in anonymous namespaces, whose declarations are
+	// file-local
+	internal bool
+}
+
+// cParser parses the token stream of a C or C++ file.
+type cParser struct {
+	source string
+	toks   []cToken
+
+	// match maps the index of each bracket to the index of its partner
+	match []int
+
+	i      int
+	result *ParseResult
+
+	// fields maps class names to the declared types of their fields
+	fields map[string]map[string]string
+
+	// fieldCalls maps the indices of calls on a member (this->repo->save(),
+	// repo_.save()) to the member; their receivers are resolved once all
+	// classes of the file are known
+	fieldCalls map[int]string
+
+	// heritage maps class names to their index in result.Heritage
+	heritage map[string]int
+
+	// namespaces holds the namespaces declared or used by the file, so that
+	// ns::name is not taken for a class member
+	namespaces map[string]bool
+}
+
+// matchBrackets pairs the parentheses, brackets and braces of the file.
+// Unclosed brackets are paired with the end of the file.
+func (p *cParser) matchBrackets() {
+	p.match = make([]int, len(p.toks))
+	var stack []int
+	for i, t := range p.toks {
+		p.match[i] = -1
+		if t.kind != cPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			stack = append(stack, i)
+		case ")", "]", "}":
+			if n := len(stack); n > 0 {
+				p.match[stack[n-1]] = i
+				p.match[i] = stack[n-1]
+				stack = stack[:n-1]
+			}
+		}
+	}
+	for _, open := range stack {
+		p.match[open] = len(p.toks)
+	}
+}
+
+func (p *cParser) tok(i int) cToken {
+	if i < 0 || i >= len(p.toks) {
+		return cToken{kind: cEOF, pos: len(p.source), end: len(p.source)}
+	}
+	return p.toks[i]
+}
+
+// is reports whether the token at index i is the identifier or punctuator text.
+func (p *cParser) is(i int, text string) bool {
+	t := p.tok(i)
+	return (t.kind == cIdent || t.kind == cPunct) && t.text == text
+}
+
+// ident returns the identifier at index i, or "".
+func (p *cParser) ident(i int) string {
+	if t := p.tok(i); t.kind == cIdent {
+		return t.text
+	}
+	return ""
+}
+
+// closing returns the index of the bracket closing the one at index i.
+func (p *cParser) closing(i int) int {
+	if i < 0 || i >= len(p.toks) || p.match[i] < 0 {
+		return i
+	}
+	return p.match[i]
+}
+
+// span returns the source of the tokens at indices i to j, inclusive, with
+// whitespace collapsed.
+func (p *cParser) span(i, j int) string {
+	start, end := p.tok(i).pos, p.tok(j).end
+	if end <= start {
+		return ""
+	}
+	return strings.Join(strings.Fields(p.source[start:end]), " ")
+}
+
+// angleEnd returns the index of the '>' closing the template arguments
+// opened at index i, or i when they are not closed before the end of the
+// declaration.
+func (p *cParser) angleEnd(i int) int {
+	depth := 0
+	for j := i; j < len(p.toks); j++ {
+		switch {
+		case p.is(j, "<"):
+			depth++
+		case p.is(j, ">"):
+			depth--
+			if depth == 0 {
+				return j
+			}
+		case p.is(j, "(") || p.is(j, "["):
+			j = p.closing(j)
+		case p.is(j, ";") || p.is(j, "{") || p.is(j, "}"):
+			return i
+		}
+	}
+	return i
+}
+
+// angleStart returns the index of the '<' opening the template arguments
+// closed at index i, or i.
+func (p *cParser) angleStart(i int) int {
+	depth := 0
+	for j := i; j >= 0; j-- {
+		switch {
+		case p.is(j, ">"):
+			depth++
+		case p.is(j, "<"):
+			depth--
+			if depth == 0 {
+				return j
+			}
+		case p.is(j, ";") || p.is(j, "{") || p.is(j, "}"):
+			return i
+		}
+	}
+	return i
+}
+
+// declarations parses the declarations from the current position to the
+// token at index end, which closes their block.
+func (p *cParser) declarations(end int, scope cScope) {
+	for p.i < end && p.i < len(p.toks) {
+		switch {
+		case p.is(p.i, ";"):
+			p.i++
+		case p.is(p.i, "}"):
+			// A stray closing brace, left by unbalanced conditional code
+			p.i++
+		case p.is(p.i, "extern") && p.tok(p.i+1).kind == cString && p.is(p.i+2, "{"):
+			closeBrace := p.closing(p.i + 2)
+			p.i += 3
+			p.declarations(closeBrace, scope)
+			p.i = closeBrace + 1
+		case p.is(p.i, "namespace") || p.is(p.i, "inline") && p.is(p.i+1, "namespace"):
+			p.namespace(scope)
+		case scope.class != "" && p.access():
+			// public:, private slots:
+			scope.public = p.is(p.i, "public") || p.is(p.i, "signals") || p.is(p.i, "Q_SIGNALS")
+			for !p.is(p.i, ":") {
+				p.i++
+			}
+			p.i++
+		case p.is(p.i, "using"):
+			p.using(scope)
+		case p.is(p.i, "typedef"):
+			p.typedef(p.i, scope)
+		case p.is(p.i, "friend") || p.is(p.i, "static_assert") || p.is(p.i, "_Static_assert") ||
+			p.is(p.i, "template") && !p.is(p.i+1, "<"):
+			// Friends, assertions and explicit instantiations
+			p.skipDeclaration("", "")
+		case p.macroLine():
+			if p.is(p.i+1, "(") {
+				p.i = p.closing(p.i + 1)
+			}
+			p.i++
+		default:
+			p.declaration(scope)
+		}
+	}
+}
+
+// access reports whether an access specifier starts at the current
+// position: public:, protected:, private:, or a Qt section such as
+// public slots: or signals:.
+func (p *cParser) access() bool {
+	switch p.ident(p.i) {
+	case "public", "protected", "private":
+		return p.is(p.i+1, ":") || p.ident(p.i+1) != "" && p.is(p.i+2, ":")
+	case "signals", "Q_SIGNALS":
+		return p.is(p.i+1, ":")
+	}
+	return false
+}
+
+// macroLine reports whether the current position holds a macro invocation
+// on a line of its own, such as Q_OBJECT or DECLARE_TYPE(Repo): an
+// upper-case name, with arguments or not, followed by nothing else on its
+// line and not terminated by a semicolon.
+func (p *cParser) macroLine() bool {
+	name := p.ident(p.i)
+	if len(name) < 2 || strings.ToUpper(name) != name || !isUpper(name[0]) {
+		return false
+	}
+	last := p.i
+	if p.is(p.i+1, "(") {
+		last = p.closing(p.i + 1)
+	}
+	next := p.tok(last + 1)
+	return next.kind != cEOF && next.line > p.tok(last).endLine && !p.is(last+1, ";") && !p.is(last+1, "{") && !p.is(last+1, "=")
+}
+
+// namespace parses a namespace definition, whose declarations are parsed
+// in place, or a namespace alias.
+func (p *cParser) namespace(scope cScope) {
+	if p.is(p.i, "inline") {
+		p.i++
+	}
+	j := p.i + 1
+	anonymous := true
+	for p.ident(j) != "" || p.is(j, "::") {
+		if name := p.ident(j); name != "" && name != "inline" {
+			p.namespaces[name] = true
+			anonymous = false
+		}
+		j++
+	}
+	if !p.is(j, "{") {
+		// Aliases: namespace fs = std::filesystem;
+		p.skipDeclaration("", "")
+		return
+	}
+	closeBrace := p.closing(j)
+	p.i = j + 1
+	p.declarations(closeBrace, cScope{public: true, internal: scope.internal || anonymous})
+	p.i = closeBrace + 1
+}
+
+// using parses a using declaration. Type aliases (using Id = int;) are
+// symbols, and using namespace records the namespace.
+func (p *cParser) using(scope cScope) {
+	start := p.i
+	switch {
+	case p.is(p.i+1, "namespace"):
+		for j := p.i + 2; p.ident(j) != "" || p.is(j, "::"); j++ {
+			if name := p.ident(j); name != "" {
+				p.namespaces[name] = true
+			}
+		}
+	case p.ident(p.i+1) != "" && p.is(p.i+2, "="):
+		name := p.ident(p.i + 1)
+		last := p.statementEnd(p.i)
+		p.typeRefs(p.i+2, last, "variable", name, "")
+		p.define(ParsedSymbol{
+			Name:       name,
+			Kind:       graph.NodeTypeAlias,
+			Signature:  p.span(start, last-1),
+			IsExported: scope.public && !scope.internal,
+		}, start, last)
+		p.i = last + 1
+		return
+	}
+	p.skipDeclaration("", "")
+}
+
+// statementEnd returns the index of the ';' ending the declaration at index
+// from, skipping brackets, or of the '}' closing its block.
+func (p *cParser) statementEnd(from int) int {
+	j := from
+	for j < len(p.toks) && !p.is(j, ";") && !p.is(j, "}") {
+		if p.is(j, "(") || p.is(j, "[") || p.is(j, "{") {
+			j = p.closing(j)
+		}
+		j++
+	}
+	return j
+}
+
+// typedef parses a typedef. The aliased type is a symbol of its own when it
+// is declared by the typedef: typedef struct repo { ... } repo_t; declares
+// the class repo and the alias repo_t, and an anonymous struct takes the
+// name of the alias.
+func (p *cParser) typedef(start int, scope cScope) {
+	j := p.i + 1
+	for p.is(j, "const") || p.is(j, "volatile") {
+		j++
+	}
+	public := scope.public && !scope.internal
+
+	if p.is(j, "struct") || p.is(j, "union") || p.is(j, "enum") || p.is(j, "class") {
+		// The body, if there is one, follows the tag
+		k := j + 1
+		tag := ""
+		for p.ident(k) != "" || p.is(k, "::") || p.is(k, "[") {
+			if p.is(k, "[") {
+				k = p.closing(k)
+			} else if name := p.ident(k); name != "class" && name != "struct" {
+				tag = name
+			}
+			k++
+		}
+		if p.is(k, "{") || p.is(k, ":") {
+			closeBrace := k
+			for closeBrace < len(p.toks) && !p.is(closeBrace, "{") {
+				closeBrace++
+			}
+			closeBrace = p.closing(closeBrace)
+			alias := ""
+			for a := closeBrace + 1; a < len(p.toks) && !p.is(a, ";") && !p.is(a, ","); a++ {
+				if name := p.ident(a); name != "" && !cTypeKeywords[name] {
+					alias = name
+					break
+				}
+			}
+			name := tag
+			if name == "" {
+				name = alias
+			}
+			if name == "" {
+				p.skipDeclaration("", "")
+				return
+			}
+			p.i = j
+			if p.is(j, "enum") {
+				p.enumeration(start, j, name, public)
+			} else {
+				p.record(start, j, name, cScope{class: scope.class, public: public, internal: scope.internal})
+			}
+			last := p.statementEnd(closeBrace + 1)
+			if alias != "" && alias != name {
+				p.define(ParsedSymbol{
+					Name:       alias,
+					Kind:       graph.NodeTypeAlias,
+					Signature:  "typedef " + p.tok(j).text + " " + name + " " + alias,
+					IsExported: public,
+				}, start, last)
+			}
+			p.i = last + 1
+			return
+		}
+	}
+
+	last := p.statementEnd(p.i)
+	name := ""
+	// typeEnd is the index of the alias, or of the parameters of an aliased
+	// function type
+	typeEnd := last
+	for k := p.i + 1; k < last; k++ {
+		switch {
+		case p.is(k, "(") && p.is(k+1, "*"):
+			// Function pointers: typedef int (*handler)(void *);
+			if alias := p.ident(p.closing(k) - 1); alias != "" {
+				name = alias
+				typeEnd = min(typeEnd, k)
+			}
+			k = p.closing(k)
+		case p.is(k, "(") && p.ident(k-1) != "":
+			// Function types: typedef int handler_fn(void *);
+			typeEnd = min(typeEnd, k-1)
+			k = p.closing(k)
+		case p.is(k, "(") || p.is(k, "["):
+			k = p.closing(k)
+		case p.is(k, "<"):
+			k = p.angleEnd(k)
+		case p.ident(k) != "" && !cTypeKeywords[p.ident(k)] && !p.is(k+1, "::") && typeEnd == last:
+			name = p.ident(k)
+		}
+	}
+	if name == "" || cPrimitives[name] {
+		p.i = last + 1
+		return
+	}
+	if typeEnd == last {
+		typeEnd = last - 1
+	}
+	p.typeRefs(p.i, typeEnd, "variable", name, "")
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeTypeAlias,
+		Signature:  p.span(start, last-1),
+		IsExported: public,
+	}, start, last)
+	p.i = last + 1
+}
+
+// declaration parses a declaration: a class, enum, function or variable,
+// with its specifiers, attributes and template header.
+func (p *cParser) declaration(scope cScope) {
+	start := p.i
+	j := p.i
+	static := false
+	var attributes []string
+	for {
+		switch {
+		case p.is(j, "template") && p.is(j+1, "<"):
+			j = p.angleEnd(j+1) + 1
+			continue
+		case p.is(j, "[") && p.is(j+1, "["):
+			// C++ attributes: [[nodiscard]], [[gnu::always_inline]]
+			closeBracket := p.closing(j)
+			if name := p.ident(j + 2); name != "" && !p.is(j+3, "::") {
+				attributes = append(attributes, name)
+			}
+			j = closeBracket + 1
+			continue
+		case cAttributeKeywords[p.ident(j)] && p.is(j+1, "("):
+			j = p.closing(j+1) + 1
+			continue
+		case p.is(j, "static"):
+			static = true
+			j++
+			continue
+		case cSpecifiers[p.ident(j)]:
+			j++
+			continue
+		case p.tok(j).kind == cString && p.is(j-1, "extern"):
+			// extern "C" int f(void);
+			j++
+			continue
+		}
+		break
+	}
+
+	public := scope.public && !scope.internal && !(static && scope.class == "")
+	switch {
+	case p.is(j, "struct") || p.is(j, "class") || p.is(j, "union"):
+		if name := p.recordName(j); name != "" {
+			p.i = j
+			closeBrace := p.record(start, j, name, cScope{class: scope.class, public: public, internal: scope.internal})
+			p.i = closeBrace + 1
+			if !p.is(p.i, ";") {
+				// Variables of the type: struct point { ... } origin;
+				p.skipDeclaration("", "")
+			} else {
+				p.i++
+			}
+			return
+		}
+	case p.is(j, "enum"):
+		if name := p.recordName(j); name != "" {
+			p.i = j
+			p.enumeration(start, j, name, public)
+			p.i = p.statementEnd(p.i) + 1
+			return
+		}
+	}
+
+	p.function(start, j, public, static, attributes, scope)
+}
+
+// recordName returns the name of the struct, class, union or enum whose
+// keyword is at index j when the declaration defines it, or "" when it
+// names it in another declaration (struct repo *open_repo(void);) or
+// declares it without a body.
+func (p *cParser) recordName(j int) string {
+	k := j + 1
+	if p.is(k, "class") || p.is(k, "struct") {
+		k++
+	}
+	name := ""
+	for {
+		switch {
+		case p.is(k, "[") && p.is(k+1, "["):
+			k = p.closing(k) + 1
+			continue
+		case cAttributeKeywords[p.ident(k)] && p.is(k+1, "("):
+			k = p.closing(k+1) + 1
+			continue
+		case p.ident(k) == "final" && name != "":
+			k++
+			continue
+		case p.ident(k) != "":
+			// Export macros precede the name: class API_EXPORT Repo
+			name = p.ident(k)
+			k++
+			continue
+		case p.is(k, "::") && name != "":
+			k++
+			continue
+		case p.is(k, "<") && name != "":
+			// Specializations: struct hash<Repo> { ... }
+			k = p.angleEnd(k) + 1
+			continue
+		}
+		break
+	}
+	switch {
+	case p.is(k, "{"):
+		return name
+	case p.is(k, ":") && !p.is(j, "enum"):
+		// Bases: class Repo : public Store
+		return name
+	case p.is(k, ":"):
+		// Enums with an underlying type: enum class Color : uint8_t { ... }
+		for k++; p.ident(k) != "" || p.is(k, "::"); k++ {
+		}
+		if p.is(k, "{") {
+			return name
+		}
+	}
+	return ""
+}
+
+// record parses the struct, class or union definition whose keyword is at
+// index kw, declared from the token at index start, and returns the index
+// of its closing brace. Its members are parsed as part of the class name.
+func (p *cParser) record(start, kw int, name string, scope cScope) int {
+	open := kw + 1
+	for open < len(p.toks) && !p.is(open, "{") && !p.is(open, ":") {
+		open++
+	}
+	var bases []string
+	if p.is(open, ":") {
+		k := open + 1
+		for ; k < len(p.toks) && !p.is(k, "{") && !p.is(k, ";"); k++ {
+			itemStart := k
+			for k < len(p.toks) && !p.is(k, ",") && !p.is(k, "{") && !p.is(k, ";") {
+				if p.is(k, "<") {
+					k = p.angleEnd(k)
+				}
+				k++
+			}
+			if base := p.mainType(itemStart, k); base != "" {
+				bases = append(bases, base)
+			}
+			if !p.is(k, ",") {
+				break
+			}
+		}
+		open = k
+	}
+	if !p.is(open, "{") {
+		return p.statementEnd(open)
+	}
+	closeBrace := p.closing(open)
+
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeClass,
+		Signature:  recordSignature(p.span(kw, open-1), name),
+		IsExported: scope.public,
+	}, start, closeBrace)
+	if len(bases) > 0 {
+		index, ok := p.heritage[name]
+		if !ok {
+			index = len(p.result.Heritage)
+			p.heritage[name] = index
+			p.result.Heritage = append(p.result.Heritage, ClassHeritage{ClassName: name})
+		}
+		p.result.Heritage[index].Extends = append(p.result.Heritage[index].Extends, bases...)
+	}
+
+	// Members of classes are private by default, of structs public
+	p.i = open + 1
+	p.declarations(closeBrace, cScope{
+		class:    name,
+		public:   scope.public && !p.is(kw, "class"),
+		internal: scope.internal,
+	})
+	p.i = closeBrace + 1
+	return closeBrace
+}
+
+// recordSignature returns the signature of a struct, class, union or enum
+// from its head; anonymous ones named by a typedef get the alias.
+func recordSignature(head, name string) string {
+	if strings.HasSuffix(head, " "+name) || strings.Contains(head, " "+name+" ") {
+		return head
+	}
+	return head + " " + name
+}
+
+// enumeration parses the enum definition whose keyword is at index kw,
+// declared from the token at index start.
+func (p *cParser) enumeration(start, kw int, name string, public bool) {
+	open := kw + 1
+	for open < len(p.toks) && !p.is(open, "{") && !p.is(open, ";") {
+		open++
+	}
+	closeBrace := p.closing(open)
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeEnum,
+		Signature:  recordSignature(p.span(kw, open-1), name),
+		IsExported: public,
+	}, start, closeBrace)
+	p.i = closeBrace + 1
+}
+
+// function parses a declaration whose specifiers end at index typeStart. It
+// is a function or method when a declarator with parameters follows;
+// other declarations are variables, which are fields in a class.
+func (p *cParser) function(start, typeStart int, public, static bool, attributes []string, scope cScope) {
+	nameIndex, open := p.declarator(typeStart)
+	if open < 0 {
+		p.variable(typeStart, scope)
+		return
+	}
+	closeParen := p.closing(open)
+
+	name := p.ident(nameIndex)
+	if p.is(nameIndex, "operator") {
+		name = "operator"
+		for k := nameIndex + 1; k < open; k++ {
+			if p.tok(k).kind == cIdent {
+				name += " "
+			}
+			name += p.tok(k).text
+		}
+	}
+
+	// The qualified name: Repo::save, Repo::~Repo, ns::Repo<T>::save
+	nameStart := nameIndex
+	if p.is(nameStart-1, "~") {
+		nameStart--
+		name = "~" + name
+	}
+	qualifier := ""
+	qualified := false
+	for p.is(nameStart-1, "::") {
+		q := nameStart - 2
+		if p.is(q, ">") {
+			q = p.angleStart(q) - 1
+		}
+		if p.ident(q) == "" {
+			// The global namespace: ::open_repo()
+			nameStart--
+			break
+		}
+		if !qualified && !p.namespaces[p.ident(q)] {
+			qualifier = p.ident(q)
+		}
+		qualified = true
+		nameStart = q
+	}
+
+	class := scope.class
+	if qualifier != "" {
+		class = qualifier
+	}
+	constructor := class != "" && strings.TrimPrefix(name, "~") == class
+	if nameStart == typeStart && !constructor && !qualified && !p.is(nameIndex, "operator") {
+		// A name without a return type is a macro invocation:
+		// DECLARE_HANDLER(save); TEST(Repo, Save) { ... }
+		p.skipDeclaration("", "")
+		return
+	}
+	for _, item := range p.list(open, closeParen) {
+		if kind := p.tok(item[0]).kind; kind == cNumber || kind == cString || kind == cChar {
+			// Variables initialized with constructor arguments: Repo repo("db");
+			p.variable(typeStart, scope)
+			return
+		}
+	}
+
+	// Qualifiers, the trailing return type, a constructor's member
+	// initializers and the body follow the parameters
+	last := closeParen
+	declaration := true
+	body := -1
+	initializers := -1
+	signatureDone := false
+qualifiers:
+	for k := closeParen + 1; k < len(p.toks); k++ {
+		switch {
+		case p.is(k, "{") && initializers >= 0 && (p.ident(k-1) != "" || p.is(k-1, ">")):
+			// Braced member initializers: : name_{name}
+			k = p.closing(k)
+			continue
+		case p.is(k, "{"):
+			body = k
+			declaration = false
+			break qualifiers
+		case p.is(k, ";") || p.is(k, "}"):
+			break qualifiers
+		case p.is(k, "="):
+			// = default and = delete define the function, = 0 does not
+			if p.is(k+1, "default") || p.is(k+1, "delete") {
+				declaration = false
+			}
+			signatureDone = true
+		case p.is(k, ":") && !signatureDone:
+			initializers = k
+			signatureDone = true
+		case p.is(k, "override") || p.is(k, "final"):
+			attributes = append(attributes, p.ident(k))
+		case p.is(k, "(") || p.is(k, "["):
+			k = p.closing(k)
+		case p.is(k, "<"):
+			k = p.angleEnd(k)
+		}
+		if !signatureDone {
+			last = k
+		}
+	}
+
+	kind := graph.NodeFunction
+	if class != "" {
+		kind = graph.NodeMethod
+	}
+	end := p.statementEnd(closeParen)
+	if body >= 0 {
+		end = p.closing(body)
+	}
+	p.define(ParsedSymbol{
+		Name:          name,
+		Kind:          kind,
+		Signature:     p.span(typeStart, last),
+		ClassName:     class,
+		IsExported:    public && !(static && class == ""),
+		Decorators:    attributes,
+		IsDeclaration: declaration,
+	}, start, end)
+
+	vars := p.parameters(open, closeParen, name, class)
+	if nameStart > typeStart {
+		p.typeRefs(typeStart-1, nameStart, "return", name, class)
+	}
+	if initializers >= 0 && body >= 0 {
+		for k := initializers + 1; k < body; k++ {
+			if p.is(k, "(") || p.is(k, "{") {
+				p.calls(k, p.closing(k), name, class, vars)
+				k = p.closing(k)
+			}
+		}
+	}
+	if body >= 0 {
+		p.calls(body, end, name, class, vars)
+	}
+	p.i = end + 1
+}
+
+// declarator finds the function declarator of the declaration whose type
+// starts at index typeStart. It returns the indices of the function name
+// and of the parenthesis opening its parameters, or -1 when the
+// declaration declares no function.
+func (p *cParser) declarator(typeStart int) (int, int) {
+	for k := typeStart; k < len(p.toks); k++ {
+		switch {
+		case p.is(k, ";") || p.is(k, "{") || p.is(k, "}") || p.is(k, "="):
+			return -1, -1
+		case p.is(k, "operator"):
+			m := k + 1
+			if p.is(m, "(") && p.is(m+1, ")") {
+				// operator()(...)
+				m += 2
+			}
+			for m < len(p.toks) && !p.is(m, "(") && !p.is(m, ";") && !p.is(m, "{") {
+				m++
+			}
+			if p.is(m, "(") {
+				return k, m
+			}
+			return -1, -1
+		case p.is(k, "<") && p.ident(k-1) != "":
+			k = p.angleEnd(k)
+		case p.is(k, "["):
+			k = p.closing(k)
+		case p.is(k, "("):
+			name := p.ident(k - 1)
+			if name == "" || cPrimitives[name] || cTypeKeywords[name] || cAttributeKeywords[name] || cNotCalled[name] {
+				k = p.closing(k)
+				continue
+			}
+			// A macro with arguments before the declarator:
+			// DEPRECATED("use open") int open_repo(void);
+			next := p.closing(k) + 1
+			if after := p.ident(next); after != "" && !cTypeKeywords[after] && !cAttributeKeywords[after] &&
+				after != "throw" && after != "try" && after != "requires" || p.is(next, "*") {
+				if nameIndex, open := p.declarator(next); open >= 0 {
+					return nameIndex, open
+				}
+			}
+			return k - 1, k
+		}
+	}
+	return -1, -1
+}
+
+// variable moves past a variable declaration whose type starts at index
+// typeStart. In a class, its declarators are fields of the class; calls in
+// the initializers of other variables are attributed to the file.
+func (p *cParser) variable(typeStart int, scope cScope) {
+	if scope.class != "" {
+		p.fieldDeclaration(typeStart, scope.class)
+	}
+	p.i = typeStart
+	p.skipDeclaration("", "")
+}
+
+// fieldDeclaration records the fields of class declared by the variable
+// declaration whose type starts at index typeStart: int count, *items;
+func (p *cParser) fieldDeclaration(typeStart int, class string) {
+	end := p.statementEnd(typeStart)
+	var names []int
+	// pending is true until the name of the current declarator is found
+	pending := true
+	for k := typeStart; k < end; k++ {
+		switch {
+		case p.is(k, "<") && p.ident(k-1) != "":
+			k = p.angleEnd(k)
+		case p.is(k, ","):
+			if pending && p.ident(k-1) != "" {
+				names = append(names, k-1)
+			}
+			pending = true
+		case pending && (p.is(k, "=") || p.is(k, "{") || p.is(k, ":") || p.is(k, "[")):
+			// Initializers, bit fields and array sizes follow the name
+			if p.ident(k-1) != "" {
+				names = append(names, k-1)
+			}
+			pending = false
+			if p.is(k, "{") || p.is(k, "[") {
+				k = p.closing(k)
+			}
+		case p.is(k, "(") || p.is(k, "[") || p.is(k, "{"):
+			k = p.closing(k)
+		}
+	}
+	if pending && p.ident(end-1) != "" {
+		names = append(names, end-1)
+	}
+	if len(names) == 0 || names[0] == typeStart {
+		return
+	}
+	typ := p.typeRefs(typeStart-1, names[0], "field", class, "")
+	if typ == "" {
+		return
+	}
+	if p.fields[class] == nil {
+		p.fields[class] = make(map[string]string)
+	}
+	for _, index := range names {
+		p.fields[class][p.ident(index)] = typ
+	}
+}
+
+// skipDeclaration moves past a declaration the parser does not record, up
+// to its ';'. Calls in it are attributed to enclosing (a method of class),
+// or to the file.
+func (p *cParser) skipDeclaration(enclosing, class string) {
+	start := p.i
+	for p.i < len(p.toks) {
+		switch {
+		case p.is(p.i, ";"):
+			p.calls(start-1, p.i, enclosing, class, nil)
+			p.i++
+			return
+		case p.is(p.i, "{"):
+			closeBrace := p.closing(p.i)
+			afterParen := p.is(p.i-1, ")")
+			p.calls(start-1, closeBrace, enclosing, class, nil)
+			start = closeBrace + 1
+			p.i = closeBrace + 1
+			// Blocks after a parenthesis are bodies (TEST(Repo, Save) { ... });
+			// other braces initialize
+			if afterParen {
+				if p.is(p.i, ";") {
+					p.i++
+				}
+				return
+			}
+		case p.is(p.i, "(") || p.is(p.i, "["):
+			p.i = p.closing(p.i) + 1
+		case p.is(p.i, "}"):
+			// The end of the enclosing block: leave it to the block
+			p.calls(start-1, p.i, enclosing, class, nil)
+			return
+		default:
+			p.i++
+		}
+	}
+}
+
+// define appends a symbol declared from the token at index start to the
+// token at index last.
+func (p *cParser) define(sym ParsedSymbol, start, last int) {
+	first, end := p.tok(start), p.tok(last)
+	sym.StartLine = first.line
+	sym.EndLine = max(end.endLine, first.line)
+	if end.end > first.pos {
+		sym.Content = p.source[first.pos:end.end]
+	}
+	p.result.Symbols = append(p.result.Symbols, sym)
+}
+
+// list returns the index ranges of the comma-separated items between the
+// brackets at indices open and closeBracket; each range ends at the comma or
+// closing bracket after the item.
+func (p *cParser) list(open, closeBracket int) [][2]int {
+	var items [][2]int
+	start := open + 1
+	depth := 0
+	for j := open + 1; j < closeBracket; j++ {
+		switch {
+		case p.is(j, "(") || p.is(j, "[") || p.is(j, "{"):
+			j = p.closing(j)
+		case p.is(j, "<") && p.ident(j-1) != "":
+			depth++
+		case p.is(j, ">") && depth > 0:
+			depth--
+		case p.is(j, ",") && depth == 0:
+			items = append(items, [2]int{start, j})
+			start = j + 1
+		}
+	}
+	if start < closeBracket {
+		items = append(items, [2]int{start, closeBracket})
+	}
+	return items
+}
+
+// parameters records the types of the parameters between the parentheses
+// at indices open and closeParen and returns the declared types of the
+// named ones.
+func (p *cParser) parameters(open, closeParen int, function, class string) map[string]string {
+	vars := make(map[string]string)
+	for _, item := range p.list(open, closeParen) {
+		from, to := item[0], item[1]
+		// Default arguments: int limit = 10
+		for k := from; k < to; k++ {
+			if p.is(k, "=") {
+				to = k
+				break
+			}
+			if p.is(k, "(") || p.is(k, "[") {
+				k = p.closing(k)
+			}
+		}
+		for to > from && p.is(to-1, "]") {
+			to = p.closing(to - 1)
+		}
+		nameEnd := to
+		name := p.ident(to - 1)
+		if name != "" && to-1 > from && !cPrimitives[name] && !cTypeKeywords[name] && !p.is(to-2, "::") {
+			nameEnd = to - 1
+		} else {
+			name = ""
+		}
+		typ := p.typeRefs(from-1, nameEnd, "param", function, class)
+		if name != "" && typ != "" {
+			vars[name] = typ
+		}
+	}
+	return vars
+}
+
+// typeRefs records the types named between the tokens at indices from and
+// to, exclusive, and returns the main one: the type a field, parameter or
+// variable with the type holds.
+func (p *cParser) typeRefs(from, to int, role, enclosing, class string) string {
+	main := ""
+	for j := from + 1; j < to && j < len(p.toks); j++ {
+		if cAttributeKeywords[p.ident(j)] && p.is(j+1, "(") {
+			j = p.closing(j + 1)
+			continue
+		}
+		t := p.toks[j]
+		if t.kind != cIdent || cPrimitives[t.text] || cTypeKeywords[t.text] || p.is(j+1, "::") {
+			continue
+		}
+		p.result.TypeRefs = append(p.result.TypeRefs, TypeAnnotation{
+			Name:           t.text,
+			Role:           role,
+			StartLine:      t.line,
+			Enclosing:      enclosing,
+			EnclosingClass: class,
+		})
+		if main == "" && !cTypeWrappers[t.text] {
+			main = t.text
+		}
+	}
+	return main
+}
+
+// mainType returns the name of the type between the tokens at indices from
+// and to, exclusive: the first name that is not a keyword, a namespace or
+// a wrapper, so that const std::shared_ptr<db::Repository>& is Repository.
+func (p *cParser) mainType(from, to int) string {
+	for j := from; j < to && j < len(p.toks); j++ {
+		name := p.ident(j)
+		if name == "" || cTypeKeywords[name] || cPrimitives[name] || cTypeWrappers[name] || p.is(j+1, "::") ||
+			name == "public" || name == "protected" || name == "private" {
+			continue
+		}
+		return name
+	}
+	return ""
+}
+
+// calls records the calls between the tokens at indices from and to,
+// exclusive, as made by the function enclosing (a method of class). vars
+// holds the declared types of the parameters and is extended by local
+// variable declarations.
+func (p *cParser) calls(from, to int, enclosing, class string, vars map[string]string) {
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	declared := -1
+	for j := from + 1; j < to && j < len(p.toks); j++ {
+		if p.is(j-1, ";") || p.is(j-1, "{") || p.is(j-1, "}") || p.is(j-1, "(") {
+			if name := p.local(j, to, vars); name >= 0 {
+				declared = name
+			}
+		}
+		name := p.ident(j)
+		if name == "" || cNotCalled[name] || cPrimitives[name] || cTypeKeywords[name] || j == declared {
+			continue
+		}
+
+		open := j + 1
+		// Template arguments: std::make_unique<Repo>(path)
+		if p.is(open, "<") {
+			if end := p.angleEnd(open); end > open {
+				open = end + 1
+			}
+		}
+		if !p.is(open, "(") {
+			continue
+		}
+		// Variables initialized with arguments: Repo repo(path);
+		if prev := p.tok(j - 1); prev.kind == cIdent && !cExpressionKeywords[prev.text] {
+			continue
+		}
+
+		call := CallSite{
+			Name:           name,
+			StartLine:      p.tok(j).line,
+			EndLine:        p.tok(p.closing(open)).line,
+			Enclosing:      enclosing,
+			EnclosingClass: class,
+		}
+		switch {
+		case p.is(j-1, ".") || p.is(j-1, "->"):
+			call.Receiver = p.receiver(j-2, vars, class)
+			member := p.ident(j - 2)
+			switch {
+			case class == "" || member == "":
+			case (p.is(j-3, "->") || p.is(j-3, ".")) && p.is(j-4, "this") && !p.is(j-5, ".") && !p.is(j-5, "->"):
+				p.fieldCalls[len(p.result.Calls)] = member
+			case vars[member] == "" && !p.is(j-3, ".") && !p.is(j-3, "->") && !p.is(j-3, "::"):
+				// Members used without this: repo_->save()
+				p.fieldCalls[len(p.result.Calls)] = member
+			}
+		case p.is(j-1, "::"):
+			// Static members: Repo::open(), not ns::open()
+			owner := p.ident(j - 2)
+			if p.is(j-2, ">") {
+				owner = p.ident(p.angleStart(j-2) - 1)
+			}
+			if owner == "std" {
+				// The standard library is never in the repository
+				continue
+			}
+			if owner != "" && !p.namespaces[owner] {
+				call.Receiver = owner
+			}
+		}
+		p.result.Calls = append(p.result.Calls, call)
+	}
+}
+
+// local records the type of the local variable declared at index j, if one
+// is (Repo repo(path);, const Repo* repo = ...;, auto repo = new Repo();),
+// and returns the index of its name, or -1.
+func (p *cParser) local(j, to int, vars map[string]string) int {
+	k := j
+	for p.is(k, "const") || p.is(k, "static") || p.is(k, "struct") || p.is(k, "class") || p.is(k, "volatile") {
+		k++
+	}
+	typeStart := k
+	for p.ident(k) != "" && p.is(k+1, "::") {
+		k += 2
+	}
+	first := p.ident(k)
+	if first == "" || cNotCalled[first] || cExpressionKeywords[first] || first == "goto" || first == "break" || first == "continue" {
+		return -1
+	}
+	k++
+	if p.is(k, "<") {
+		end := p.angleEnd(k)
+		if end == k {
+			return -1
+		}
+		k = end + 1
+	}
+	for p.is(k, "*") || p.is(k, "&") || p.is(k, "&&") || p.is(k, "const") {
+		k++
+	}
+	name := p.ident(k)
+	if name == "" || k >= to || cTypeKeywords[name] || cPrimitives[name] {
+		return -1
+	}
+	switch next := k + 1; {
+	case p.is(next, "=") || p.is(next, ";") || p.is(next, "(") || p.is(next, "{") ||
+		p.is(next, "[") || p.is(next, ",") || p.is(next, ":"):
+	default:
+		return -1
+	}
+
+	typ := p.mainType(typeStart, k)
+	if first == "auto" && p.is(k+1, "=") {
+		typ = p.initializerType(k + 2)
+	}
+	if typ != "" && !cPrimitives[typ] {
+		vars[name] = typ
+	}
+	return k
+}
+
+// initializerType returns the type an initializer starting at index j
+// constructs: new Repo(...), Repo(...), Repo{...}, Repo::open(...) or
+// std::make_unique<Repo>(...).
+func (p *cParser) initializerType(j int) string {
+	if p.is(j, "new") {
+		j++
+	}
+	typ := ""
+	for p.ident(j) != "" {
+		name := p.ident(j)
+		switch {
+		case p.is(j+1, "::"):
+			if !p.namespaces[name] {
+				typ = name
+			}
+			j += 2
+			continue
+		case p.is(j+1, "<"):
+			if name == "make_unique" || name == "make_shared" {
+				return p.mainType(j+2, p.angleEnd(j+1))
+			}
+			return name
+		case p.is(j+1, "(") || p.is(j+1, "{"):
+			// Named constructors (Repo::open(path)) or constructors;
+			// other functions return unknown types
+			if typ != "" {
+				return typ
+			}
+			if isUpper(name[0]) || p.is(j-1, "new") {
+				return name
+			}
+		}
+		return ""
+	}
+	return ""
+}
+
+// receiver returns the type of the receiver expression ending at index j:
+// the class for this, the declared type of a variable, or the last name of
+// the expression.
+func (p *cParser) receiver(j int, vars map[string]string, class string) string {
+	name := p.ident(j)
+	if name == "" {
+		return ""
+	}
+	if p.is(j-1, ".") || p.is(j-1, "->") {
+		return name
+	}
+	if name == "this" {
+		return class
+	}
+	if typ := vars[name]; typ != "" {
+		return typ
+	}
+	return name
+}
+
+// finish resolves the receivers of calls on members to the declared types
+// of the members, and drops the declarations of functions the file also
+// defines, or declares again, which would share their nodes.
+func (p *cParser) finish() {
+	for index, field := range p.fieldCalls {
+		call := &p.result.Calls[index]
+		if typ := p.fields[call.EnclosingClass][field]; typ != "" {
+			call.Receiver = typ
+		}
+	}
+
+	type key struct{ name, class string }
+	defined := make(map[key]bool)
+	for _, sym := range p.result.Symbols {
+		if (sym.Kind == graph.NodeFunction || sym.Kind == graph.NodeMethod) && !sym.IsDeclaration {
+			defined[key{sym.Name, sym.ClassName}] = true
+		}
+	}
+	symbols := p.result.Symbols[:0]
+	for _, sym := range p.result.Symbols {
+		if sym.IsDeclaration {
+			k := key{sym.Name, sym.ClassName}
+			if defined[k] {
+				continue
+			}
+			defined[k] = true
+		}
+		symbols = append(symbols, sym)
+	}
+	p.result.Symbols = symbols
+}
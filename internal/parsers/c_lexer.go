@@ -0,0 +1,259 @@
+package parsers
+
+import "strings"
+
+// cTokenKind is the kind of a C or C++ token.
+type cTokenKind int
+
+const (
+	cEOF cTokenKind = iota
+	cIdent
+	cPunct
+	cString
+	cChar
+	cNumber
+	cDirective
+)
+
+// cToken is a token of C or C++ source.
+type cToken struct {
+	kind cTokenKind
+
+	// text is the token text: the body of a string literal, or the
+	// directive of a preprocessor line without its '#' and with
+	// continuation lines joined
+	text string
+
+	// pos and end are the byte offsets of the token in the source
+	pos, end int
+
+	// line and endLine are the 1-based lines of pos and end, which differ
+	// for multi-line strings and directives
+	line, endLine int
+}
+
+// cPunctuators are the multi-character punctuators the parser needs,
+// longest first. '>' is always a single token, so that the '>>' closing
+// nested templates (vector<vector<int>>) is never a shift.
+var cPunctuators = []string{"...", "<<=", "->*", "::", "->", "<<", "<=", ">=", "==", "!=", "&&", "||", "++", "--", "+=", "-=", "*=", "/=", "|=", "&=", "^=", ".*"}
+
+// cStringPrefixes are the encoding prefixes of string and character
+// literals; an R makes a string raw.
+var cStringPrefixes = map[string]bool{
+	"L": true, "u": true, "U": true, "u8": true,
+	"R": true, "LR": true, "uR": true, "UR": true, "u8R": true,
+}
+
+// cLexer splits C and C++ source into tokens, skipping whitespace, comments
+// and line continuations. A '#' starting a line begins a preprocessor
+// directive, which becomes one token. Strings cover escapes, encoding
+// prefixes and raw strings (R"delim(...)delim"); digit separators
+// (1'000'000) are part of numbers.
+type cLexer struct {
+	src  string
+	pos  int
+	line int
+	toks []cToken
+
+	// lineStart is true until the first token of a line
+	lineStart bool
+}
+
+// lexC tokenizes source.
+func lexC(source string) []cToken {
+	l := &cLexer{src: source, line: 1, lineStart: true}
+	for l.pos < len(l.src) {
+		l.token()
+	}
+	return l.toks
+}
+
+// emit appends a token spanning src[start:end] and moves past it.
+func (l *cLexer) emit(kind cTokenKind, text string, start, end int) {
+	lines := strings.Count(l.src[start:end], "\n")
+	l.toks = append(l.toks, cToken{kind: kind, text: text, pos: start, end: end, line: l.line, endLine: l.line + lines})
+	l.line += lines
+	l.pos = end
+	l.lineStart = false
+}
+
+// token lexes the token at the current position.
+func (l *cLexer) token() {
+	c := l.src[l.pos]
+	rest := l.src[l.pos:]
+	switch {
+	case c == '\n':
+		l.line++
+		l.pos++
+		l.lineStart = true
+	case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+		l.pos++
+	case strings.HasPrefix(rest, "\\\n") || strings.HasPrefix(rest, "\\\r\n"):
+		l.pos = l.pos + strings.IndexByte(rest, '\n') + 1
+		l.line++
+	case strings.HasPrefix(rest, "//"):
+		if i := strings.IndexByte(rest, '\n'); i >= 0 {
+			l.pos += i
+		} else {
+			l.pos = len(l.src)
+		}
+	case strings.HasPrefix(rest, "/*"):
+		l.blockComment()
+	case c == '#' && l.lineStart:
+		l.directive()
+	case c == '"':
+		l.string(l.pos, l.pos)
+	case c == '\'':
+		l.char(l.pos, l.pos)
+	case isJSDigit(c) || c == '.' && len(rest) > 1 && isJSDigit(rest[1]):
+		l.number()
+	case isPyNameStart(c) || c == '$':
+		end := l.pos + 1
+		for end < len(l.src) && (isPyNamePart(l.src[end]) || l.src[end] == '$') {
+			end++
+		}
+		word := l.src[l.pos:end]
+		if end < len(l.src) && cStringPrefixes[word] {
+			switch {
+			case l.src[end] == '"':
+				l.string(l.pos, end)
+				return
+			case l.src[end] == '\'' && !strings.Contains(word, "R"):
+				l.char(l.pos, end)
+				return
+			}
+		}
+		l.emit(cIdent, word, l.pos, end)
+	default:
+		for _, punct := range cPunctuators {
+			if strings.HasPrefix(rest, punct) {
+				l.emit(cPunct, punct, l.pos, l.pos+len(punct))
+				return
+			}
+		}
+		l.emit(cPunct, rest[:1], l.pos, l.pos+1)
+	}
+}
+
+// blockComment skips a block comment.
+func (l *cLexer) blockComment() {
+	end := strings.Index(l.src[l.pos+2:], "*/")
+	if end < 0 {
+		end = len(l.src)
+	} else {
+		end += l.pos + 4
+	}
+	l.line += strings.Count(l.src[l.pos:end], "\n")
+	l.pos = end
+}
+
+// directive lexes the preprocessor line starting at the current '#'. The
+// directive runs to the end of the line, or of the last line joined to it
+// by continuations; comments in it are dropped.
+func (l *cLexer) directive() {
+	start := l.pos
+	var text strings.Builder
+	i := l.pos + 1
+	for i < len(l.src) && l.src[i] != '\n' {
+		switch rest := l.src[i:]; {
+		case strings.HasPrefix(rest, "\\\n"):
+			text.WriteByte(' ')
+			i += 2
+		case strings.HasPrefix(rest, "\\\r\n"):
+			text.WriteByte(' ')
+			i += 3
+		case strings.HasPrefix(rest, "//"):
+			for i < len(l.src) && l.src[i] != '\n' {
+				i++
+			}
+		case strings.HasPrefix(rest, "/*"):
+			end := strings.Index(rest[2:], "*/")
+			if end < 0 {
+				i = len(l.src)
+			} else {
+				i += end + 4
+			}
+			text.WriteByte(' ')
+		default:
+			text.WriteByte(l.src[i])
+			i++
+		}
+	}
+	i = min(i, len(l.src))
+	l.emit(cDirective, strings.TrimSpace(text.String()), start, i)
+}
+
+// string lexes a string literal whose prefix starts at start and whose
+// quote is at index open.
+func (l *cLexer) string(start, open int) {
+	if strings.HasSuffix(l.src[start:open], "R") {
+		// Raw strings: R"delim( ... )delim"
+		paren := strings.IndexByte(l.src[open:], '(')
+		if paren >= 0 {
+			delim := l.src[open+1 : open+paren]
+			bodyStart := open + paren + 1
+			closing := ")" + delim + "\""
+			bodyEnd := strings.Index(l.src[bodyStart:], closing)
+			if bodyEnd < 0 {
+				l.emit(cString, l.src[bodyStart:], start, len(l.src))
+				return
+			}
+			bodyEnd += bodyStart
+			l.emit(cString, l.src[bodyStart:bodyEnd], start, bodyEnd+len(closing))
+			return
+		}
+	}
+
+	i := open + 1
+	for i < len(l.src) && l.src[i] != '"' && l.src[i] != '\n' {
+		if l.src[i] == '\\' {
+			i++
+		}
+		i++
+	}
+	bodyEnd := min(i, len(l.src))
+	end := bodyEnd
+	if end < len(l.src) && l.src[end] == '"' {
+		end++
+	}
+	l.emit(cString, l.src[open+1:bodyEnd], start, end)
+}
+
+// char lexes a character literal whose prefix starts at start and whose
+// quote is at index open.
+func (l *cLexer) char(start, open int) {
+	i := open + 1
+	for i < len(l.src) && l.src[i] != '\'' && l.src[i] != '\n' {
+		if l.src[i] == '\\' {
+			i++
+		}
+		i++
+	}
+	bodyEnd := min(i, len(l.src))
+	end := bodyEnd
+	if end < len(l.src) && l.src[end] == '\'' {
+		end++
+	}
+	l.emit(cChar, l.src[open+1:bodyEnd], start, end)
+}
+
+// number lexes a numeric literal: integers, floats with exponents,
+// hexadecimal floats, suffixes and digit separators.
+func (l *cLexer) number() {
+	end := l.pos + 1
+	for end < len(l.src) {
+		d := l.src[end]
+		switch {
+		case isPyNamePart(d) || d == '.':
+			end++
+		case (d == '+' || d == '-') && strings.ContainsRune("eEpP", rune(l.src[end-1])):
+			end++
+		case d == '\'' && end+1 < len(l.src) && isPyNamePart(l.src[end+1]):
+			end++
+		default:
+			l.emit(cNumber, l.src[l.pos:end], l.pos, end)
+			return
+		}
+	}
+	l.emit(cNumber, l.src[l.pos:end], l.pos, end)
+}
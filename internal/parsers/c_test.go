@@ -0,0 +1,291 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestCParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ParseHeader", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`#ifndef STORE_REPO_H
+#define STORE_REPO_H
+
+#include <stddef.h>
+#include "store/types.h"
+
+#define REPO_MAX_KEYS \
+    1024
+
+#ifdef __cplusplus
+extern "C" {
+#endif
+
+typedef struct repo repo_t;
+
+typedef struct repo_stats {
+    size_t keys;
+    size_t bytes;
+} repo_stats_t;
+
+typedef struct {
+    int code;
+} repo_error;
+
+typedef int (*repo_visit_fn)(const char *key, void *ctx);
+
+enum repo_mode { REPO_READ, REPO_WRITE };
+
+repo_t *repo_open(const char *path, enum repo_mode mode);
+void repo_close(repo_t *repo);
+int repo_each(repo_t *repo, repo_visit_fn visit, void *ctx);
+
+static inline size_t repo_keys(const repo_stats_t *stats) {
+    return stats->keys;
+}
+
+#ifdef __cplusplus
+}
+#endif
+
+#endif
+`)
+		result, err := NewCParser().Parse("include/store/repo.h", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Equal(t, graph.NodeClass, symbols["repo_stats"].Kind)
+		assert.Equal(t, graph.NodeTypeAlias, symbols["repo_stats_t"].Kind)
+		assert.Equal(t, graph.NodeClass, symbols["repo_error"].Kind, "anonymous structs take the typedef name")
+		assert.Equal(t, graph.NodeTypeAlias, symbols["repo_visit_fn"].Kind)
+		assert.Equal(t, graph.NodeEnum, symbols["repo_mode"].Kind)
+
+		open := symbols["repo_open"]
+		assert.Equal(t, graph.NodeFunction, open.Kind)
+		assert.True(t, open.IsDeclaration)
+		assert.True(t, open.IsExported)
+		assert.Equal(t, "repo_t *repo_open(const char *path, enum repo_mode mode)", open.Signature)
+		assert.Equal(t, 29, open.StartLine)
+		assert.True(t, symbols["repo_close"].IsDeclaration)
+
+		keys := symbols["repo_keys"]
+		assert.False(t, keys.IsDeclaration)
+		assert.False(t, keys.IsExported, "static functions are file-local")
+		assert.Equal(t, 33, keys.StartLine)
+		assert.Equal(t, 35, keys.EndLine)
+
+		assert.Equal(t, map[string][]string{"stddef.h": nil, "store/types.h": nil}, importsByModule(result))
+		for _, imp := range result.Imports {
+			assert.Equal(t, imp.ModulePath == "store/types.h", imp.IsRelative, "quoted includes are relative")
+		}
+	})
+
+	t.Run("ParseImplementation", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`#include "repo.h"
+
+static int repo_flush(repo_t *repo) {
+    return write_all(repo->fd, repo->buf);
+}
+
+void repo_close(repo_t *repo) {
+    if (repo_flush(repo) != 0) {
+        log_error("flush failed");
+    }
+    free(repo);
+}
+`)
+		result, err := NewCParser().Parse("src/repo.c", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		require.Len(t, symbols, 2)
+		assert.False(t, symbols["repo_close"].IsDeclaration)
+
+		flush := findCall(result.Calls, "repo_flush", 8)
+		require.NotNil(t, flush)
+		assert.Equal(t, "repo_close", flush.Enclosing)
+		assert.NotNil(t, findCall(result.Calls, "write_all", 4))
+		assert.NotNil(t, findCall(result.Calls, "log_error", 9))
+		assert.NotNil(t, findCall(result.Calls, "free", 11))
+		assert.Nil(t, findCall(result.Calls, "if", 8), "keywords are not calls")
+	})
+
+	t.Run("ParseClass", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`#include <memory>
+#include <string>
+#include "cache/backend.hpp"
+
+namespace store {
+
+template <typename K>
+class Cache : public Base<K>, private Noncopyable {
+public:
+    explicit Cache(std::size_t capacity);
+    ~Cache();
+
+    [[nodiscard]] bool put(const std::string& key) override;
+    static Cache* instance();
+    void flush() { backend_->sync(); }
+
+protected:
+    virtual void evict() = 0;
+
+private:
+    std::unique_ptr<Backend> backend_;
+    std::size_t capacity_;
+};
+
+struct Entry {
+    std::string key;
+    int hits() const { return count_; }
+};
+
+}
+`)
+		result, err := NewCPPParser().Parse("src/cache.hpp", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		cache := symbols["Cache"]
+		assert.Equal(t, graph.NodeClass, cache.Kind)
+		assert.True(t, cache.IsExported)
+		assert.Equal(t, 7, cache.StartLine, "templates start the class")
+		assert.Equal(t, 23, cache.EndLine)
+
+		ctor := symbols["Cache.Cache"]
+		assert.Equal(t, graph.NodeMethod, ctor.Kind)
+		assert.True(t, ctor.IsDeclaration)
+		assert.True(t, symbols["Cache.~Cache"].IsDeclaration)
+
+		put := symbols["Cache.put"]
+		assert.True(t, put.IsExported)
+		assert.Equal(t, []string{"nodiscard", "override"}, put.Decorators)
+		assert.Equal(t, "bool put(const std::string& key) override", put.Signature)
+		assert.True(t, symbols["Cache.instance"].IsExported)
+		assert.False(t, symbols["Cache.evict"].IsExported, "protected members")
+		assert.True(t, symbols["Cache.evict"].IsDeclaration, "pure virtual")
+
+		hits := symbols["Entry.hits"]
+		assert.True(t, hits.IsExported, "struct members are public")
+		assert.False(t, hits.IsDeclaration)
+
+		var heritage []string
+		for _, h := range result.Heritage {
+			if h.ClassName == "Cache" {
+				heritage = h.Extends
+			}
+		}
+		assert.Equal(t, []string{"Base", "Noncopyable"}, heritage)
+
+		var fields []string
+		for _, ref := range result.TypeRefs {
+			if ref.Role == "field" && ref.Enclosing == "Cache" {
+				fields = append(fields, ref.Name)
+			}
+		}
+		assert.Contains(t, fields, "Backend")
+
+		sync := findCall(result.Calls, "sync", 15)
+		require.NotNil(t, sync)
+		assert.Equal(t, "Backend", sync.Receiver, "members are typed by their declaration")
+	})
+
+	t.Run("ParseOutOfLineMethods", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`#include "cache.hpp"
+
+namespace store {
+
+Cache::Cache(std::size_t capacity) : backend_(std::make_unique<Backend>(capacity)), capacity_(capacity) {}
+
+Cache::~Cache() = default;
+
+bool Cache::put(const std::string& key) {
+    Entry entry{key};
+    backend_->store(entry);
+    this->evict();
+    auto now = Clock::now();
+    return std::find(keys.begin(), keys.end(), key) != keys.end();
+}
+
+namespace {
+int helper() { return 0; }
+}
+
+}
+`)
+		result, err := NewCPPParser().Parse("src/cache.cpp", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		ctor := symbols["Cache.Cache"]
+		assert.Equal(t, graph.NodeMethod, ctor.Kind)
+		assert.False(t, ctor.IsDeclaration)
+		assert.Equal(t, 5, ctor.StartLine)
+		assert.Contains(t, symbols, "Cache.~Cache", "defaulted destructors")
+
+		put := symbols["Cache.put"]
+		assert.Equal(t, graph.NodeMethod, put.Kind)
+		assert.Equal(t, 9, put.StartLine)
+		assert.Equal(t, 15, put.EndLine)
+		assert.False(t, symbols["helper"].IsExported, "anonymous namespaces are file-local")
+
+		store := findCall(result.Calls, "store", 11)
+		require.NotNil(t, store)
+		assert.Equal(t, "put", store.Enclosing)
+		assert.Equal(t, "Cache", store.EnclosingClass)
+
+		evict := findCall(result.Calls, "evict", 12)
+		require.NotNil(t, evict)
+		assert.Equal(t, "Cache", evict.Receiver)
+
+		now := findCall(result.Calls, "now", 13)
+		require.NotNil(t, now)
+		assert.Equal(t, "Clock", now.Receiver, "static members")
+		assert.Nil(t, findCall(result.Calls, "find", 14), "the standard library is never in the repository")
+	})
+
+	t.Run("ParseEmptyFile", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := NewCParser().Parse("empty.c", []byte(""))
+		require.NoError(t, err)
+		assert.Empty(t, result.Symbols)
+		assert.Empty(t, result.Calls)
+	})
+}
+
+func TestCParser_Language(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "c", NewCParser().Language())
+	assert.Equal(t, "cpp", NewCPPParser().Language())
+}
+
+func TestCParser_FileDetection(t *testing.T) {
+	t.Parallel()
+
+	c := NewCParser()
+	assert.True(t, c.SupportsFile("src/repo.c"))
+	assert.True(t, c.SupportsFile("include/repo.h"))
+	assert.False(t, c.SupportsFile("src/repo.cpp"))
+
+	cpp := NewCPPParser()
+	for _, file := range []string{"cache.cc", "cache.cpp", "cache.cxx", "cache.hpp", "cache.hh", "cache.hxx"} {
+		assert.True(t, cpp.SupportsFile(file), file)
+	}
+	assert.False(t, cpp.SupportsFile("cache.h"))
+	assert.False(t, cpp.SupportsFile("CMakeLists.txt"))
+}
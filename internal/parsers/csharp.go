@@ -0,0 +1,558 @@
+package parsers
+
+import (
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// CSharpParser parses C# source code.
+//
+// The source is tokenized by the lexer shared with Java and Kotlin
+// (jvm_lexer.go) and parsed declaration by declaration, following the
+// brace structure of namespaces and types. Classes, structs, records,
+// interfaces, enums and delegates are symbols, as are methods,
+// constructors and properties; properties are methods flagged IsProperty.
+// Partial types are flagged IsPartial so that their parts, declared across
+// files, can be merged. Attributes are recorded as decorators, using
+// directives as imports, and top-level statements are scanned for calls.
+type CSharpParser struct{}
+
+// NewCSharpParser creates a new C# parser.
+func NewCSharpParser() *CSharpParser {
+	return &CSharpParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *CSharpParser) Language() string {
+	return "csharp"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *CSharpParser) SupportsFile(filename string) bool {
+	return strings.HasSuffix(filename, ".cs")
+}
+
+// Parse parses C# source code and extracts symbols, imports, calls, etc.
+func (p *CSharpParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	cp := &csParser{
+		jvParser: newJVMParser(string(content), jvCSharp),
+		locals:   make(map[string]string),
+	}
+	cp.members(len(cp.toks), jvScope{})
+	cp.finish()
+	setDocComments(cp.result, string(content), csharpDocComments)
+	return cp.result, nil
+}
+
+// csModifiers are the modifiers of C# declarations.
+var csModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "internal": true,
+	"static": true, "readonly": true, "sealed": true, "abstract": true,
+	"virtual": true, "override": true, "async": true, "partial": true,
+	"unsafe": true, "extern": true, "new": true, "const": true,
+	"volatile": true, "file": true, "required": true, "ref": true,
+	"event": true,
+}
+
+// csParser parses the token stream of a C# file.
+type csParser struct {
+	*jvParser
+
+	// locals holds the declared types of the variables of top-level
+	// statements
+	locals map[string]string
+}
+
+// members parses the declarations from the current position to the token
+// at index end, which closes their namespace or type body (or the end of
+// the file).
+func (p *csParser) members(end int, scope jvScope) {
+	for p.i < end && p.i < len(p.toks) {
+		if p.is(p.i, ";") {
+			p.i++
+			continue
+		}
+		attributes := p.attributes()
+		if p.i >= end {
+			return
+		}
+		start := p.i
+		if p.is(p.i, "global") && p.is(p.i+1, "using") {
+			p.i++
+		}
+		if p.is(p.i, "using") && scope.class == "" && p.usingDirective() {
+			continue
+		}
+
+		public := scope.iface
+		partial, event := false, false
+		var decorators []string
+		for csModifiers[p.ident(p.i)] && p.ident(p.i+1) != "" {
+			switch p.ident(p.i) {
+			case "public":
+				public = true
+			case "private":
+				public = false
+			case "partial":
+				partial = true
+			case "event":
+				event = true
+			case "override":
+				decorators = append(decorators, "override")
+			}
+			p.i++
+		}
+		decorators = append(attributes, decorators...)
+
+		switch {
+		case p.is(p.i, "namespace") && scope.class == "":
+			p.namespaceDeclaration(scope)
+		case p.is(p.i, "class") || p.is(p.i, "struct") || p.is(p.i, "interface") || p.is(p.i, "enum") ||
+			p.is(p.i, "record") && p.ident(p.i+1) != "":
+			p.typeDeclaration(start, public, partial, decorators)
+		case p.is(p.i, "delegate") && p.ident(p.i+1) != "":
+			p.delegateDeclaration(start, public, decorators)
+		case p.is(p.i, "}"):
+			// A stray closing brace: leave it to the enclosing block
+			p.i++
+		case scope.class == "":
+			p.statement(start)
+		case event:
+			p.skipMember(p.i, scope)
+		default:
+			p.member(start, public, decorators, scope)
+		}
+	}
+}
+
+// attributes skips the attribute sections at the current position and
+// returns the names of their attributes: [Serializable], [HttpGet("{id}"),
+// Authorize]. The Attribute suffix of the class names is dropped, and so
+// are assembly and module attributes, which belong to no declaration.
+func (p *csParser) attributes() []string {
+	var names []string
+	for p.is(p.i, "[") {
+		closeBracket := p.closing(p.i)
+		j := p.i + 1
+		if p.ident(j) != "" && p.is(j+1, ":") {
+			// Targets: [return: NotNull], [assembly: InternalsVisibleTo("Tests")]
+			if p.is(j, "assembly") || p.is(j, "module") {
+				p.i = closeBracket + 1
+				continue
+			}
+			j += 2
+		}
+		for j < closeBracket {
+			name, end := p.qualifiedName(j)
+			if name == "" {
+				break
+			}
+			if trimmed := strings.TrimSuffix(name, "Attribute"); !strings.HasSuffix(trimmed, ".") && trimmed != "" {
+				name = trimmed
+			}
+			names = append(names, name)
+			if p.is(end, "<") {
+				if closeAngle := p.angleEnd(end); closeAngle > 0 {
+					end = closeAngle + 1
+				}
+			}
+			if p.is(end, "(") {
+				end = p.closing(end) + 1
+			}
+			if !p.is(end, ",") {
+				break
+			}
+			j = end + 1
+		}
+		p.i = closeBracket + 1
+	}
+	return names
+}
+
+// usingDirective parses using A.B;, using static A.B.C; or the alias
+// using X = A.B.C; at the current position and reports whether it was one:
+// using statements (using var x = ..., using (...)) are not directives.
+// Static and alias directives name a type or namespace in the module path
+// of their symbol.
+func (p *csParser) usingDirective() bool {
+	line := p.tok(p.i).line
+	j := p.i + 1
+	static := false
+	if p.is(j, "static") {
+		static = true
+		j++
+	}
+	alias := ""
+	if p.ident(j) != "" && p.is(j+1, "=") {
+		alias = p.ident(j)
+		j += 2
+	}
+	name, end := p.qualifiedName(j)
+	if name == "" || !p.is(end, ";") && !p.is(end, "<") {
+		return false
+	}
+
+	imp := ImportStatement{ModulePath: name, Alias: alias, StartLine: line}
+	if static || alias != "" {
+		if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+			imp.ModulePath, imp.Symbols = name[:dot], []string{name[dot+1:]}
+		}
+	}
+	p.result.Imports = append(p.result.Imports, imp)
+	p.skipStatement()
+	return true
+}
+
+// namespaceDeclaration parses the block or file-scoped namespace whose
+// keyword is at the current position. The first namespace of a file is
+// its package.
+func (p *csParser) namespaceDeclaration(scope jvScope) {
+	name, end := p.qualifiedName(p.i + 1)
+	if p.result.Package == "" {
+		p.result.Package = name
+	}
+	if !p.is(end, "{") {
+		// File-scoped namespaces cover the rest of the file
+		p.i = end + 1
+		return
+	}
+	closeBrace := p.closing(end)
+	p.i = end + 1
+	p.members(closeBrace, scope)
+	p.i = closeBrace + 1
+}
+
+// typeDeclaration parses the class, struct, record, interface or enum
+// whose keyword is at the current position.
+func (p *csParser) typeDeclaration(start int, public, partial bool, decorators []string) {
+	keyword := p.i
+	kind := p.ident(keyword)
+	if kind == "record" && (p.is(keyword+1, "class") || p.is(keyword+1, "struct")) {
+		// record class and record struct
+		keyword++
+		if p.is(keyword, "struct") {
+			kind = "struct"
+		}
+	}
+	name := p.ident(keyword + 1)
+	if name == "" {
+		p.i = keyword + 1
+		return
+	}
+	label := graph.NodeClass
+	switch kind {
+	case "interface":
+		label = graph.NodeInterface
+	case "enum":
+		label = graph.NodeEnum
+	}
+
+	// The header runs to the body: type parameters, the primary
+	// constructor, the base list and constraints
+	bodyOpen := keyword + 2
+	for bodyOpen < len(p.toks) && !p.is(bodyOpen, "{") && !p.is(bodyOpen, ";") {
+		if p.is(bodyOpen, "(") || p.is(bodyOpen, "[") {
+			bodyOpen = p.closing(bodyOpen)
+		}
+		bodyOpen++
+	}
+	last := p.closing(bodyOpen)
+	if !p.is(bodyOpen, "{") {
+		last = bodyOpen
+	}
+
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       label,
+		Signature:  p.text(p.i, bodyOpen-1),
+		IsExported: public,
+		Decorators: decorators,
+		IsPartial:  partial,
+	}, start, last)
+
+	p.header(keyword+2, bodyOpen, name, kind)
+	p.i = bodyOpen + 1
+	if !p.is(bodyOpen, "{") {
+		return
+	}
+	if label == graph.NodeEnum {
+		// Enum members are constants; their values may call nameof(...)
+		p.calls(bodyOpen, last, "", name, nil)
+	} else {
+		p.members(last, jvScope{class: name, iface: label == graph.NodeInterface})
+	}
+	p.i = last + 1
+}
+
+// header records the heritage and primary constructor parameters of the
+// type name, declared between the tokens at indices from and to. C# does
+// not tell base classes from interfaces, so the naming convention does:
+// interfaces are named IName, and only the first type of the base list of
+// a class or record may be its base class.
+func (p *csParser) header(from, to int, name, kind string) {
+	j := from
+	if p.is(j, "<") {
+		if end := p.angleEnd(j); end > 0 {
+			j = end + 1
+		}
+	}
+	if p.is(j, "(") {
+		closeParen := p.closing(j)
+		for _, item := range p.list(j, closeParen) {
+			p.primaryParameter(item[0], item[1], name, kind == "record")
+		}
+		j = closeParen + 1
+	}
+	if !p.is(j, ":") {
+		return
+	}
+
+	// The base list runs to the constraints or the body
+	end := j + 1
+	for end < to && !p.is(end, "where") {
+		end++
+	}
+	classes, interfaces := p.supertypes(j+1, end)
+	first := p.typeName(j+1, end)
+
+	var extends, implements []string
+	for _, base := range append(classes, interfaces...) {
+		switch {
+		case kind == "interface":
+			extends = append(extends, base)
+		case kind == "enum":
+			// The underlying type: enum Color : byte
+		case (kind == "class" || kind == "record") && base == first && !csInterfaceName(base):
+			extends = append(extends, base)
+		default:
+			implements = append(implements, base)
+		}
+	}
+	p.addHeritage(name, extends, implements)
+}
+
+// csInterfaceName reports whether name follows the naming convention of
+// interfaces: IRepository, IDisposable.
+func csInterfaceName(name string) bool {
+	return len(name) > 1 && name[0] == 'I' && isUpper(name[1])
+}
+
+// primaryParameter records the primary constructor parameter between the
+// tokens at indices from and to, exclusive. Record parameters are
+// properties; the parameters of classes and structs are captured by their
+// members, so both are fields for resolving call receivers.
+func (p *csParser) primaryParameter(from, to int, class string, record bool) {
+	for p.is(from, "[") {
+		from = p.closing(from) + 1
+	}
+	for k := from; k < to; k++ {
+		if p.is(k, "=") {
+			to = k
+			break
+		}
+	}
+	nameIndex := to - 1
+	if p.ident(nameIndex) == "" || nameIndex == from {
+		return
+	}
+	var typ string
+	if record {
+		typ = p.typeRefs(from, nameIndex, "field", class, "")
+	} else {
+		typ = p.typeRefs(from, nameIndex, "param", class, class)
+	}
+	p.addField(class, p.ident(nameIndex), typ)
+}
+
+// delegateDeclaration parses the delegate type whose keyword is at the
+// current position.
+func (p *csParser) delegateDeclaration(start int, public bool, decorators []string) {
+	keyword := p.i
+	j, nameIndex := keyword+1, -1
+	for j < len(p.toks) && !p.is(j, "(") && !p.is(j, ";") {
+		if p.is(j, "<") {
+			if end := p.angleEnd(j); end > 0 {
+				j = end + 1
+				continue
+			}
+		}
+		if p.ident(j) != "" {
+			nameIndex = j
+		}
+		j++
+	}
+	p.i = j
+	p.skipStatement()
+	if nameIndex < 0 || !p.is(j, "(") {
+		return
+	}
+	p.define(ParsedSymbol{
+		Name:       p.ident(nameIndex),
+		Kind:       graph.NodeTypeAlias,
+		Signature:  p.text(keyword, p.closing(j)),
+		IsExported: public,
+		Decorators: decorators,
+	}, start, p.i-1)
+}
+
+// statement records the calls of the top-level statement starting at index
+// start, which belong to the file.
+func (p *csParser) statement(start int) {
+	p.i = start
+	for p.i < len(p.toks) && !p.is(p.i, ";") && !p.is(p.i, "}") {
+		if p.is(p.i, "{") {
+			// Blocks end their statement: if (...) { .. }
+			p.i = p.closing(p.i)
+			break
+		}
+		if p.is(p.i, "(") || p.is(p.i, "[") {
+			p.i = p.closing(p.i)
+		}
+		p.i++
+	}
+	p.calls(start-1, p.i+1, "", "", p.locals)
+	p.i++
+}
+
+// member parses the method, constructor, property or field declared at the
+// current position in the body of scope.class.
+func (p *csParser) member(start int, public bool, decorators []string, scope jvScope) {
+	typeStart := p.i
+
+	// Find the name and what follows it: parameters, accessors, an
+	// expression body, an initializer or the end
+	j, nameIndex := typeStart, -1
+scan:
+	for j < len(p.toks) {
+		switch {
+		case p.is(j, "<"):
+			if end := p.angleEnd(j); end > 0 {
+				j = end + 1
+				continue
+			}
+		case p.is(j, "(") && nameIndex < 0:
+			// Tuple types: (int, string) Split()
+			j = p.closing(j)
+		case p.is(j, "(") || p.is(j, "=") || p.is(j, "=>") || p.is(j, ";") || p.is(j, "{") || p.is(j, "}") || p.is(j, ","):
+			break scan
+		case p.is(j, "["):
+			j = p.closing(j)
+		case p.ident(j) != "":
+			nameIndex = j
+		}
+		j++
+	}
+	name := ""
+	if nameIndex >= 0 {
+		name = p.ident(nameIndex)
+	}
+
+	switch {
+	case name == "" || name == "this" || name == "operator" || p.is(nameIndex-1, "operator") || p.is(nameIndex-1, "~"):
+		// Indexers, operators and finalizers belong to their class
+		p.skipMember(j, scope)
+	case p.is(j, "("):
+		p.method(start, typeStart, nameIndex, j, public, decorators, scope)
+	case p.is(j, "{") || p.is(j, "=>"):
+		p.property(start, typeStart, nameIndex, j, public, decorators, scope)
+	case p.is(j, "=") || p.is(j, ";") || p.is(j, ","):
+		p.field(typeStart, j, scope)
+	default:
+		p.skipMember(j, scope)
+	}
+}
+
+// memberEnd returns the index of the last token of the member whose
+// declaration continues at index j: the '}' closing its body or accessors,
+// or the ';' ending it, an expression body or a property initializer.
+func (p *csParser) memberEnd(j int) int {
+	expression := false
+	for ; j < len(p.toks); j++ {
+		switch {
+		case p.is(j, "=") || p.is(j, "=>"):
+			expression = true
+		case p.is(j, "{") && !expression:
+			closeBrace := p.closing(j)
+			if !p.is(closeBrace+1, "=") {
+				return closeBrace
+			}
+			// Property initializers: { get; set; } = new();
+			j = closeBrace
+		case p.is(j, "(") || p.is(j, "[") || p.is(j, "{"):
+			j = p.closing(j)
+		case p.is(j, ";"):
+			return j
+		case p.is(j, "}"):
+			return j - 1
+		}
+	}
+	return len(p.toks) - 1
+}
+
+// skipMember moves past the member whose declaration continues at index j;
+// its calls belong to the class.
+func (p *csParser) skipMember(j int, scope jvScope) {
+	last := p.memberEnd(j)
+	p.calls(p.i-1, last+1, "", scope.class, nil)
+	p.i = last + 1
+}
+
+// nameStart returns the index of the qualified name ending at index
+// nameIndex: explicit interface implementations are named after their
+// interface (void IDisposable.Dispose()).
+func (p *csParser) nameStart(nameIndex int) int {
+	for p.is(nameIndex-1, ".") && p.ident(nameIndex-2) != "" {
+		nameIndex -= 2
+	}
+	return nameIndex
+}
+
+// method parses the method or constructor whose parameters open at index
+// open; its return type starts at index typeStart.
+func (p *csParser) method(start, typeStart, nameIndex, open int, public bool, decorators []string, scope jvScope) {
+	name := p.ident(nameIndex)
+	closeParen := p.closing(open)
+	last := p.memberEnd(closeParen + 1)
+
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeMethod,
+		Signature:  p.text(typeStart, closeParen),
+		ClassName:  scope.class,
+		IsExported: public,
+		Decorators: decorators,
+	}, start, last)
+
+	if nameStart := p.nameStart(nameIndex); typeStart < nameStart {
+		p.typeRefs(typeStart, nameStart, "return", name, scope.class)
+	}
+	vars := p.parameters(open, closeParen, name, scope.class)
+
+	// Constructor initializers (: this(...)), constraints and the body
+	p.calls(closeParen, last+1, name, scope.class, vars)
+	p.i = last + 1
+}
+
+// property parses the property whose accessors or expression body start at
+// index j. Properties are methods; their types make them fields for
+// resolving call receivers.
+func (p *csParser) property(start, typeStart, nameIndex, j int, public bool, decorators []string, scope jvScope) {
+	name := p.ident(nameIndex)
+	last := p.memberEnd(j)
+
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeMethod,
+		Signature:  p.text(typeStart, nameIndex),
+		ClassName:  scope.class,
+		IsExported: public,
+		Decorators: decorators,
+		IsProperty: true,
+	}, start, last)
+
+	typ := p.typeRefs(typeStart, p.nameStart(nameIndex), "field", scope.class, "")
+	p.addField(scope.class, name, typ)
+	p.calls(nameIndex, last+1, name, scope.class, nil)
+	p.i = last + 1
+}
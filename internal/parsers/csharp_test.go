@@ -0,0 +1,236 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestCSharpParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ParseClass", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`using System;
+using Acme.Data;
+
+namespace Acme.Orders
+{
+    [ApiController]
+    [RouteAttribute("api/orders")]
+    public partial class OrderService : ServiceBase, IOrderService, IDisposable
+    {
+        private readonly IOrderRepository _repository;
+
+        public OrderService(IOrderRepository repository) : base(repository)
+        {
+            _repository = repository;
+        }
+
+        public string Name { get; set; } = "orders";
+
+        public int Count => _repository.Count();
+
+        [HttpGet("{id}")]
+        public async Task<Order?> GetAsync([FromRoute] string id, int limit = 10)
+        {
+            var order = await _repository.FindAsync(id);
+            Order? cached = Cache.Lookup<Order>(id);
+            return order ?? cached;
+        }
+
+        public override string ToString() => base.ToString();
+
+        void IDisposable.Dispose() { _repository.Dispose(); }
+
+        public Order this[int index] => _repository.At(index);
+    }
+}
+`)
+		result, err := NewCSharpParser().Parse("OrderService.cs", content)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme.Orders", result.Package)
+
+		symbols := symbolsByName(result)
+		service := symbols["OrderService"]
+		assert.Equal(t, graph.NodeClass, service.Kind)
+		assert.True(t, service.IsExported)
+		assert.True(t, service.IsPartial)
+		assert.Equal(t, []string{"ApiController", "Route"}, service.Decorators, "the Attribute suffix is dropped")
+		assert.Equal(t, 8, service.StartLine)
+		assert.Equal(t, 34, service.EndLine)
+
+		require.Contains(t, symbols, "OrderService.OrderService")
+		assert.Equal(t, "OrderService(IOrderRepository repository)", symbols["OrderService.OrderService"].Signature)
+
+		name := symbols["OrderService.Name"]
+		assert.Equal(t, graph.NodeMethod, name.Kind)
+		assert.True(t, name.IsProperty)
+		assert.Equal(t, "string Name", name.Signature)
+		assert.True(t, symbols["OrderService.Count"].IsProperty, "expression-bodied properties")
+
+		get := symbols["OrderService.GetAsync"]
+		assert.Equal(t, graph.NodeMethod, get.Kind)
+		assert.False(t, get.IsProperty)
+		assert.Equal(t, []string{"HttpGet"}, get.Decorators)
+		assert.Equal(t, "Task<Order?> GetAsync([FromRoute] string id, int limit = 10)", get.Signature)
+		assert.Equal(t, 27, get.EndLine)
+
+		assert.Equal(t, []string{"override"}, symbols["OrderService.ToString"].Decorators)
+		assert.Contains(t, symbols, "OrderService.Dispose", "explicit interface implementations")
+		assert.NotContains(t, symbols, "OrderService.this", "indexers are not symbols")
+
+		require.Len(t, result.Heritage, 1)
+		assert.Equal(t, []string{"ServiceBase"}, result.Heritage[0].Extends)
+		assert.Equal(t, []string{"IOrderService", "IDisposable"}, result.Heritage[0].Implements)
+
+		count := findCall(result.Calls, "Count", 19)
+		require.NotNil(t, count)
+		assert.Equal(t, "IOrderRepository", count.Receiver, "field receivers resolve to the field type")
+
+		find := findCall(result.Calls, "FindAsync", 24)
+		require.NotNil(t, find)
+		assert.Equal(t, "IOrderRepository", find.Receiver)
+		assert.Equal(t, "GetAsync", find.Enclosing)
+
+		lookup := findCall(result.Calls, "Lookup", 25)
+		require.NotNil(t, lookup, "generic calls")
+		assert.Equal(t, "Cache", lookup.Receiver)
+
+		toString := findCall(result.Calls, "ToString", 29)
+		require.NotNil(t, toString)
+		assert.Equal(t, "ServiceBase", toString.Receiver, "base refers to the base class")
+
+		at := findCall(result.Calls, "At", 33)
+		require.NotNil(t, at)
+		assert.Equal(t, "OrderService", at.EnclosingClass)
+	})
+
+	t.Run("ParseUsings", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`global using System.Linq;
+using Acme.Data;
+using static System.Math;
+using Json = System.Text.Json.JsonSerializer;
+
+using var scope = Services.CreateScope();
+`)
+		result, err := NewCSharpParser().Parse("Program.cs", content)
+		require.NoError(t, err)
+
+		require.Len(t, result.Imports, 4, "using statements are not directives")
+		imports := importsByModule(result)
+		assert.Contains(t, imports, "System.Linq")
+		assert.Empty(t, imports["Acme.Data"], "namespace imports name no symbols")
+		assert.Equal(t, []string{"Math"}, imports["System"])
+		assert.Equal(t, []string{"JsonSerializer"}, imports["System.Text.Json"])
+		assert.Equal(t, "Json", result.Imports[3].Alias)
+	})
+
+	t.Run("ParseDeclarations", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`namespace Acme.Geometry;
+
+public record Point(int X, Coordinate Y) : Shape(X);
+
+public record struct Pair(string Key, string Value) : IComparable<Pair>;
+
+public interface IShape : IComparable, IEquatable<IShape>
+{
+    double Area();
+    string Name { get; }
+}
+
+internal enum Status : byte { Active = 1, Inactive }
+
+public struct Money : IEquatable<Money>
+{
+    public decimal Amount;
+}
+
+public delegate void ShapeHandler(object sender, IShape shape);
+`)
+		result, err := NewCSharpParser().Parse("Shapes.cs", content)
+		require.NoError(t, err)
+		assert.Equal(t, "Acme.Geometry", result.Package, "file-scoped namespaces")
+
+		symbols := symbolsByName(result)
+		require.Len(t, symbols, 8)
+		assert.Equal(t, graph.NodeClass, symbols["Point"].Kind)
+		assert.Equal(t, graph.NodeClass, symbols["Pair"].Kind)
+		assert.Equal(t, graph.NodeInterface, symbols["IShape"].Kind)
+		assert.True(t, symbols["IShape.Area"].IsExported, "interface members are public")
+		assert.True(t, symbols["IShape.Name"].IsProperty)
+		assert.Equal(t, graph.NodeEnum, symbols["Status"].Kind)
+		assert.False(t, symbols["Status"].IsExported)
+		assert.Equal(t, graph.NodeClass, symbols["Money"].Kind)
+		assert.Equal(t, graph.NodeTypeAlias, symbols["ShapeHandler"].Kind)
+
+		heritage := make(map[string]ClassHeritage)
+		for _, h := range result.Heritage {
+			heritage[h.ClassName] = h
+		}
+		assert.Equal(t, []string{"Shape"}, heritage["Point"].Extends)
+		assert.Equal(t, []string{"IComparable"}, heritage["Pair"].Implements)
+		assert.Equal(t, []string{"IComparable", "IEquatable"}, heritage["IShape"].Extends, "interfaces extend interfaces")
+		assert.Equal(t, []string{"IEquatable"}, heritage["Money"].Implements, "structs only implement")
+		assert.NotContains(t, heritage, "Status", "underlying types of enums are not heritage")
+
+		var fields []string
+		for _, ref := range result.TypeRefs {
+			if ref.Role == "field" {
+				fields = append(fields, ref.Name)
+			}
+		}
+		assert.Equal(t, []string{"Coordinate"}, fields, "record parameters are fields")
+	})
+
+	t.Run("ParseTopLevelStatements", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`var app = WebApplication.Create(args);
+app.MapGet("/orders/{id}", (string id, OrderService orders) => orders.Get(id));
+await app.RunAsync();
+`)
+		result, err := NewCSharpParser().Parse("Program.cs", content)
+		require.NoError(t, err)
+		assert.Empty(t, result.Symbols)
+
+		get := findCall(result.Calls, "Get", 2)
+		require.NotNil(t, get)
+		assert.Equal(t, "OrderService", get.Receiver, "lambda parameters are typed")
+		assert.Empty(t, get.Enclosing)
+		assert.NotNil(t, findCall(result.Calls, "RunAsync", 3))
+	})
+
+	t.Run("ParseEmptyFile", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := NewCSharpParser().Parse("Empty.cs", []byte(""))
+		require.NoError(t, err)
+		assert.Empty(t, result.Symbols)
+		assert.Empty(t, result.Calls)
+	})
+}
+
+func TestCSharpParser_Language(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "csharp", NewCSharpParser().Language())
+}
+
+func TestCSharpParser_FileDetection(t *testing.T) {
+	t.Parallel()
+
+	parser := NewCSharpParser()
+	assert.True(t, parser.SupportsFile("OrderService.cs"))
+	assert.True(t, parser.SupportsFile("src/Acme.Orders/Program.cs"))
+	assert.False(t, parser.SupportsFile("Acme.Orders.csproj"))
+	assert.False(t, parser.SupportsFile("View.cshtml"))
+}
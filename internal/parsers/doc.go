@@ -0,0 +1,136 @@
+package parsers
+
+import "strings"
+
+// commentSyntax describes the comments that document the declaration
+// following them in a language.
+type commentSyntax struct {
+	// line are the prefixes of documenting line comments, longest first
+	line []string
+
+	// block is set when /* */ block comments document declarations
+	block bool
+
+	// attributes are the prefixes of the attribute, annotation or
+	// decorator lines that may stand between a comment and its declaration
+	attributes []string
+}
+
+var (
+	slashDocComments  = commentSyntax{line: []string{"//"}, block: true, attributes: []string{"@"}}
+	csharpDocComments = commentSyntax{line: []string{"///", "//"}, block: true, attributes: []string{"["}}
+	cDocComments      = commentSyntax{line: []string{"///", "//!", "//"}, block: true, attributes: []string{"[["}}
+	rustDocComments   = commentSyntax{line: []string{"///"}, block: true, attributes: []string{"#["}}
+	phpDocComments    = commentSyntax{line: []string{"//", "#"}, block: true, attributes: []string{"#["}}
+	rubyDocComments   = commentSyntax{line: []string{"#"}}
+	protoDocComments  = commentSyntax{line: []string{"//"}, block: true}
+)
+
+// commentDirectives are the prefixes of comments that direct tools rather
+// than document the code.
+var commentDirectives = []string{
+	"eslint-", "@ts-", "prettier-ignore", "istanbul ", "rubocop:", "NOLINT",
+	"#region", "#endregion", "#pragma", "phpcs:", "@phpstan-", "noinspection",
+}
+
+// setDocComments sets the documentation of the symbols of a parse result
+// that have none to the comment right above their declaration, past any
+// attribute lines. A blank line between the comment and the declaration
+// detaches it.
+func setDocComments(result *ParseResult, source string, syntax commentSyntax) {
+	lines := strings.Split(source, "\n")
+	for i := range result.Symbols {
+		if sym := &result.Symbols[i]; sym.Doc == "" && sym.StartLine > 0 {
+			sym.Doc = docComment(lines, sym.StartLine, syntax)
+		}
+	}
+}
+
+// docComment returns the text of the comment ending right above the
+// 1-based line start, or "".
+func docComment(lines []string, start int, syntax commentSyntax) string {
+	i := min(start, len(lines)+1) - 2
+	for i >= 0 && hasAnyPrefix(strings.TrimSpace(lines[i]), syntax.attributes) {
+		i--
+	}
+	if i < 0 {
+		return ""
+	}
+
+	if trimmed := strings.TrimSpace(lines[i]); syntax.block && strings.HasSuffix(trimmed, "*/") {
+		end := i
+		for i >= 0 && !strings.Contains(lines[i], "/*") {
+			i--
+		}
+		if i < 0 || !strings.HasPrefix(strings.TrimSpace(lines[i]), "/*") {
+			return ""
+		}
+		text := make([]string, 0, end-i+1)
+		for k := i; k <= end; k++ {
+			line := strings.TrimSpace(lines[k])
+			if k == i {
+				line = strings.TrimLeft(strings.TrimPrefix(line, "/*"), "*!")
+			}
+			if k == end {
+				line = strings.TrimRight(strings.TrimSuffix(line, "*/"), "*")
+			}
+			if k != i && strings.HasPrefix(line, "*") {
+				line = strings.TrimPrefix(line, "*")
+			}
+			text = append(text, strings.TrimPrefix(line, " "))
+		}
+		return cleanDoc(strings.Join(text, "\n"))
+	}
+
+	var text []string
+	for ; i >= 0; i-- {
+		trimmed := strings.TrimSpace(lines[i])
+		prefix := ""
+		for _, p := range syntax.line {
+			if strings.HasPrefix(trimmed, p) {
+				prefix = p
+				break
+			}
+		}
+		if prefix == "" || hasAnyPrefix(trimmed, syntax.attributes) {
+			break
+		}
+		line := strings.TrimPrefix(strings.TrimPrefix(trimmed, prefix), " ")
+		if !hasAnyPrefix(line, commentDirectives) {
+			text = append([]string{line}, text...)
+		}
+	}
+	return cleanDoc(strings.Join(text, "\n"))
+}
+
+// cleanDoc returns documentation text without the indentation common to
+// its lines after the first, and without leading and trailing blank lines,
+// like Python's inspect.cleandoc.
+func cleanDoc(text string) string {
+	lines := strings.Split(strings.ReplaceAll(text, "\t", "    "), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		if trimmed := strings.TrimLeft(line, " "); trimmed != "" {
+			if n := len(line) - len(trimmed); indent < 0 || n < indent {
+				indent = n
+			}
+		}
+	}
+	lines[0] = strings.TrimSpace(lines[0])
+	for i := 1; i < len(lines); i++ {
+		if len(lines[i]) >= indent && indent > 0 {
+			lines[i] = lines[i][indent:]
+		}
+		lines[i] = strings.TrimRight(lines[i], " \r")
+	}
+	return strings.Trim(strings.Join(lines, "\n"), "\n")
+}
+
+func hasAnyPrefix(s string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(s, prefix) {
+			return true
+		}
+	}
+	return false
+}
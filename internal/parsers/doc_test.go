@@ -0,0 +1,107 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDocComments(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		parser   Parser
+		file     string
+		content  string
+		symbol   string
+		expected string
+	}{
+		{
+			name:   "JavaDoc",
+			parser: NewJavaParser(),
+			file:   "Store.java",
+			content: `/**
+ * Persists records.
+ *
+ * @since 1.0
+ */
+@Service
+public class Store {}
+`,
+			symbol:   "Store",
+			expected: "Persists records.\n\n@since 1.0",
+		},
+		{
+			name:   "RustDocLines",
+			parser: NewRustParser(),
+			file:   "lib.rs",
+			content: `/// Opens the store.
+/// Fails when the path is missing.
+#[inline]
+pub fn open() {}
+`,
+			symbol:   "open",
+			expected: "Opens the store.\nFails when the path is missing.",
+		},
+		{
+			name:   "TypeScriptSkipsDirectives",
+			parser: NewTypeScriptParser(),
+			file:   "store.ts",
+			content: `// Loads a record.
+// eslint-disable-next-line no-unused-vars
+export function load(id: string) {}
+`,
+			symbol:   "load",
+			expected: "Loads a record.",
+		},
+		{
+			name:   "RubyComments",
+			parser: NewRubyParser(),
+			file:   "store.rb",
+			content: `# Saves a record.
+def save(record)
+end
+`,
+			symbol:   "save",
+			expected: "Saves a record.",
+		},
+		{
+			name:   "BlankLineDetaches",
+			parser: NewJavaScriptParser(),
+			file:   "store.js",
+			content: `// Copyright Acme.
+
+function load() {}
+`,
+			symbol:   "load",
+			expected: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			result, err := tt.parser.Parse(tt.file, []byte(tt.content))
+			require.NoError(t, err)
+
+			var found bool
+			for _, sym := range result.Symbols {
+				if sym.Name == tt.symbol {
+					found = true
+					assert.Equal(t, tt.expected, sym.Doc)
+				}
+			}
+			assert.True(t, found, "Should find %s", tt.symbol)
+		})
+	}
+}
+
+func TestCleanDoc(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "Summary.\n\nDetails\n  indented", cleanDoc("Summary.\n\n    Details\n      indented\n    "))
+	assert.Equal(t, "One line", cleanDoc("  One line  "))
+}
@@ -0,0 +1,266 @@
+package parsers
+
+import (
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// DockerfileParser parses Dockerfiles (Dockerfile, Dockerfile.NAME,
+// NAME.Dockerfile and Containerfile).
+//
+// Every build stage is a resource named by its alias, or "stage N" for
+// unnamed stages. A stage refers to the stage it is built FROM and to the
+// stages it copies files from, and records the Go packages its RUN
+// instructions build with go build or go install. The last stage is the
+// image the Dockerfile builds.
+type DockerfileParser struct{}
+
+// NewDockerfileParser creates a new Dockerfile parser.
+func NewDockerfileParser() *DockerfileParser {
+	return &DockerfileParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *DockerfileParser) Language() string {
+	return "dockerfile"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *DockerfileParser) SupportsFile(filename string) bool {
+	return IsDockerfile(filename)
+}
+
+// IsDockerfile reports whether a file name is the name of a Dockerfile.
+func IsDockerfile(filename string) bool {
+	base := strings.ToLower(filepath.Base(filename))
+	return base == "dockerfile" || base == "containerfile" ||
+		strings.HasPrefix(base, "dockerfile.") || strings.HasSuffix(base, ".dockerfile")
+}
+
+// dockerInstruction is an instruction of a Dockerfile, with its line
+// continuations joined.
+type dockerInstruction struct {
+	keyword   string
+	args      string
+	startLine int
+	endLine   int
+}
+
+// Parse parses a Dockerfile and extracts its build stages as resources.
+func (p *DockerfileParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	result := &ParseResult{
+		Symbols:   []ParsedSymbol{},
+		Imports:   []ImportStatement{},
+		Calls:     []CallSite{},
+		TypeRefs:  []TypeAnnotation{},
+		Heritage:  []ClassHeritage{},
+		Resources: []Resource{},
+	}
+
+	lines := strings.Split(string(content), "\n")
+	var stages []*Resource
+	finish := func(endLine int) {
+		if len(stages) == 0 {
+			return
+		}
+		stage := stages[len(stages)-1]
+		for endLine > stage.StartLine && strings.TrimSpace(lines[endLine-1]) == "" {
+			endLine--
+		}
+		stage.EndLine = max(endLine, stage.StartLine)
+		stage.Content = strings.Join(lines[stage.StartLine-1:stage.EndLine], "\n")
+	}
+	stageName := func(ref string) string {
+		if index, err := strconv.Atoi(ref); err == nil && index < len(stages) {
+			return stages[index].Name
+		}
+		for _, stage := range stages {
+			if strings.EqualFold(stage.Name, ref) {
+				return stage.Name
+			}
+		}
+		return ""
+	}
+
+	instructions := dockerInstructions(lines)
+	for i, ins := range instructions {
+		if ins.keyword == "FROM" {
+			if i > 0 {
+				finish(ins.startLine - 1)
+			}
+			words := withoutFlags(strings.Fields(ins.args))
+			if len(words) == 0 {
+				continue
+			}
+			stage := &Resource{
+				Name:       "stage " + strconv.Itoa(len(stages)),
+				Kind:       "stage",
+				Platform:   "docker",
+				StartLine:  ins.startLine,
+				Properties: map[string]any{"image": words[0], "index": len(stages)},
+			}
+			if len(words) >= 3 && strings.EqualFold(words[1], "AS") {
+				stage.Name = words[2]
+			}
+			if base := stageName(words[0]); base != "" {
+				stage.References = append(stage.References, base)
+			}
+			stages = append(stages, stage)
+			continue
+		}
+		if len(stages) == 0 {
+			continue
+		}
+		stage := stages[len(stages)-1]
+		switch ins.keyword {
+		case "COPY", "ADD":
+			for _, word := range strings.Fields(ins.args) {
+				if from, ok := strings.CutPrefix(word, "--from="); ok {
+					if name := stageName(from); name != "" && name != stage.Name {
+						stage.References = append(stage.References, name)
+					}
+				}
+			}
+		case "RUN":
+			stage.Builds = append(stage.Builds, goBuildPackages(ins.args)...)
+		case "ENTRYPOINT", "CMD":
+			stage.Properties[strings.ToLower(ins.keyword)] = ins.args
+		case "EXPOSE":
+			stage.Properties["ports"] = strings.Fields(ins.args)
+		}
+	}
+	finish(len(lines))
+
+	for _, stage := range stages {
+		result.Resources = append(result.Resources, *stage)
+	}
+	return result, nil
+}
+
+// dockerInstructions splits the lines of a Dockerfile into instructions,
+// skipping comments and joining continued lines.
+func dockerInstructions(lines []string) []dockerInstruction {
+	var instructions []dockerInstruction
+	var current *dockerInstruction
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if current == nil {
+			if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+				continue
+			}
+			keyword, args, _ := strings.Cut(trimmed, " ")
+			instructions = append(instructions, dockerInstruction{
+				keyword:   strings.ToUpper(keyword),
+				startLine: i + 1,
+			})
+			current = &instructions[len(instructions)-1]
+			trimmed = strings.TrimSpace(args)
+		} else if strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		current.endLine = i + 1
+		if continued, ok := strings.CutSuffix(trimmed, "\\"); ok {
+			current.args += continued + " "
+			continue
+		}
+		current.args = strings.TrimSpace(current.args + trimmed)
+		current = nil
+	}
+	return instructions
+}
+
+// withoutFlags returns the words of an instruction without its --flags.
+func withoutFlags(words []string) []string {
+	for len(words) > 0 && strings.HasPrefix(words[0], "--") {
+		words = words[1:]
+	}
+	return words
+}
+
+// goValueFlags are the flags of go build and go install that take a value.
+var goValueFlags = map[string]bool{
+	"-o": true, "-ldflags": true, "-gcflags": true, "-asmflags": true,
+	"-tags": true, "-mod": true, "-modfile": true, "-C": true, "-p": true,
+	"-pkgdir": true, "-installsuffix": true, "-buildmode": true,
+	"-compiler": true, "-toolexec": true, "-overlay": true, "-pgo": true,
+	"-coverpkg": true, "-covermode": true,
+}
+
+// goBuildPackages returns the packages built by the go build and go install
+// commands of a shell command line ("." when none is named).
+func goBuildPackages(command string) []string {
+	var packages []string
+	words := shellWords(command)
+	for i := 0; i+1 < len(words); i++ {
+		if words[i] != "go" || words[i+1] != "build" && words[i+1] != "install" {
+			continue
+		}
+		var named []string
+		j := i + 2
+		for ; j < len(words) && !isShellOperator(words[j]); j++ {
+			word := words[j]
+			switch {
+			case strings.HasPrefix(word, "-"):
+				if goValueFlags[word] || goValueFlags[strings.TrimPrefix(word, "-")] {
+					j++
+				}
+			case strings.HasSuffix(word, ".go"):
+				named = append(named, filepath.ToSlash(filepath.Dir(word)))
+			case !strings.Contains(word, "..."):
+				named = append(named, strings.TrimSuffix(strings.SplitN(word, "@", 2)[0], "/"))
+			}
+		}
+		if len(named) == 0 {
+			named = []string{"."}
+		}
+		for _, pkg := range named {
+			if !slices.Contains(packages, pkg) {
+				packages = append(packages, pkg)
+			}
+		}
+		i = j
+	}
+	return packages
+}
+
+// shellWords splits a shell command line into words, keeping quoted words
+// together and the operators apart.
+func shellWords(command string) []string {
+	var words []string
+	var word strings.Builder
+	var quote byte
+	flush := func() {
+		if word.Len() > 0 {
+			words = append(words, word.String())
+			word.Reset()
+		}
+	}
+	for i := 0; i < len(command); i++ {
+		c := command[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				word.WriteByte(c)
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case c == ' ' || c == '\t':
+			flush()
+		case c == ';' || c == '&' || c == '|':
+			flush()
+			words = append(words, string(c))
+		default:
+			word.WriteByte(c)
+		}
+	}
+	flush()
+	return words
+}
+
+func isShellOperator(word string) bool {
+	return word == ";" || word == "&" || word == "|"
+}
@@ -0,0 +1,90 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDockerfileParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(`# syntax=docker/dockerfile:1
+FROM --platform=$BUILDPLATFORM golang:1.22 AS build
+WORKDIR /src
+COPY . .
+RUN go mod download && \
+    CGO_ENABLED=0 go build -trimpath -ldflags "-s -w" \
+      -o /out/api ./cmd/api
+
+FROM build AS tools
+RUN go install github.com/acme/shop/cmd/migrate@latest
+
+FROM gcr.io/distroless/static
+COPY --from=build /out/api /api
+COPY --from=1 /go/bin/migrate /migrate
+EXPOSE 8080 9090
+ENTRYPOINT ["/api"]
+`)
+	result, err := NewDockerfileParser().Parse("Dockerfile", content)
+	require.NoError(t, err)
+	require.Len(t, result.Resources, 3)
+
+	build, tools, image := result.Resources[0], result.Resources[1], result.Resources[2]
+
+	assert.Equal(t, "build", build.Name)
+	assert.Equal(t, "stage", build.Kind)
+	assert.Equal(t, "docker", build.Platform)
+	assert.Equal(t, "golang:1.22", build.Properties["image"])
+	assert.Equal(t, 2, build.StartLine)
+	assert.Equal(t, 7, build.EndLine)
+	assert.Equal(t, []string{"./cmd/api"}, build.Builds, "flag values are not packages")
+	assert.Empty(t, build.References)
+
+	assert.Equal(t, []string{"build"}, tools.References)
+	assert.Equal(t, []string{"github.com/acme/shop/cmd/migrate"}, tools.Builds)
+
+	assert.Equal(t, "stage 2", image.Name)
+	assert.Equal(t, 2, image.Properties["index"])
+	assert.Equal(t, []string{"build", "tools"}, image.References)
+	assert.Equal(t, `["/api"]`, image.Properties["entrypoint"])
+	assert.Equal(t, []string{"8080", "9090"}, image.Properties["ports"])
+	assert.Equal(t, 16, image.EndLine)
+	assert.Empty(t, image.Builds)
+}
+
+func TestGoBuildPackages(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		command  string
+		expected []string
+	}{
+		{"CurrentPackage", "go build -o /app", []string{"."}},
+		{"Files", "go build -o app cmd/server/main.go", []string{"cmd/server"}},
+		{"Several", "go build ./cmd/api; go install ./cmd/worker ./cmd/api", []string{"./cmd/api", "./cmd/worker"}},
+		{"Patterns", "go build ./...", []string{"."}},
+		{"Quoted", `go build -tags "netgo osusergo" ./cmd/api`, []string{"./cmd/api"}},
+		{"NotBuild", "go test ./... && go vet ./...", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, goBuildPackages(tt.command))
+		})
+	}
+}
+
+func TestIsDockerfile(t *testing.T) {
+	t.Parallel()
+
+	for _, name := range []string{"Dockerfile", "build/Dockerfile.api", "api.dockerfile", "Containerfile"} {
+		assert.True(t, IsDockerfile(name), name)
+	}
+	for _, name := range []string{"docker-compose.yml", "Makefile", "dockerfiles"} {
+		assert.False(t, IsDockerfile(name), name)
+	}
+}
@@ -0,0 +1,30 @@
+package parsers
+
+// enclosingSymbol returns the name and class of the innermost symbol whose
+// line range contains line, or empty strings for top-level code.
+func enclosingSymbol(symbols []ParsedSymbol, line int) (name, className string) {
+	var best *ParsedSymbol
+	for i := range symbols {
+		sym := &symbols[i]
+		if sym.StartLine == 0 || sym.StartLine > line || line > sym.EndLine {
+			continue
+		}
+		if best == nil || sym.EndLine-sym.StartLine < best.EndLine-best.StartLine {
+			best = sym
+		}
+	}
+	if best == nil {
+		return "", ""
+	}
+	return best.Name, best.ClassName
+}
+
+// isDeclarationLine reports whether a symbol called name is declared on line.
+func isDeclarationLine(symbols []ParsedSymbol, name string, line int) bool {
+	for _, sym := range symbols {
+		if sym.Name == name && sym.StartLine == line {
+			return true
+		}
+	}
+	return false
+}
@@ -5,6 +5,7 @@ import (
 	"go/ast"
 	"go/parser"
 	"go/token"
+	"go/types"
 	"strings"
 
 	"github.com/Benny93/axon-go/internal/graph"
@@ -43,6 +44,7 @@ func (p *GoParser) Parse(filePath string, content []byte) (*ParseResult, error)
 
 	// Parse imports
 	p.parseImports(file, result)
+	p.parseCgoPreamble(file, fset, result)
 
 	// Parse declarations
 	for _, decl := range file.Decls {
@@ -54,8 +56,13 @@ func (p *GoParser) Parse(filePath string, content []byte) (*ParseResult, error)
 		}
 	}
 
+	p.markEnums(file, result)
+
 	// Parse function calls (walk AST)
 	p.parseCalls(file, fset, content, result)
+	p.parseVarRefs(file, fset, result)
+	p.parseRoutes(file, fset, result)
+	p.parseSQL(file, fset, result)
 
 	return result, nil
 }
@@ -86,11 +93,48 @@ func (p *GoParser) parseImports(file *ast.File, result *ParseResult) {
 	}
 }
 
+// parseCgoPreamble records the quoted #include directives of the cgo
+// preamble, the comment before import "C", as relative imports of the
+// included headers.
+func (p *GoParser) parseCgoPreamble(file *ast.File, fset *token.FileSet, result *ParseResult) {
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			imp, ok := spec.(*ast.ImportSpec)
+			if !ok || imp.Path.Value != `"C"` {
+				continue
+			}
+			doc := imp.Doc
+			if doc == nil && !gen.Lparen.IsValid() {
+				doc = gen.Doc
+			}
+			if doc == nil {
+				continue
+			}
+			for _, comment := range doc.List {
+				text := comment.Text[2:]
+				if strings.HasPrefix(comment.Text, "/*") {
+					text = strings.TrimSuffix(text, "*/")
+				}
+				for _, include := range cIncludes(text, fset.Position(comment.Pos()).Line) {
+					if include.IsRelative {
+						result.Imports = append(result.Imports, include)
+					}
+				}
+			}
+		}
+	}
+}
+
 func (p *GoParser) parseFuncDecl(fn *ast.FuncDecl, fset *token.FileSet, content []byte, result *ParseResult) {
 	sym := ParsedSymbol{
 		Name:       fn.Name.Name,
 		StartLine:  fset.Position(fn.Pos()).Line,
 		EndLine:    fset.Position(fn.End()).Line,
+		Doc:        strings.TrimSpace(fn.Doc.Text()),
 		IsExported: fn.Name.IsExported(),
 	}
 
@@ -98,14 +142,7 @@ func (p *GoParser) parseFuncDecl(fn *ast.FuncDecl, fset *token.FileSet, content
 	if fn.Recv != nil && len(fn.Recv.List) > 0 {
 		// It's a method
 		sym.Kind = graph.NodeMethod
-		recv := fn.Recv.List[0]
-		if typeName, ok := recv.Type.(*ast.StarExpr); ok {
-			if ident, ok := typeName.X.(*ast.Ident); ok {
-				sym.ClassName = ident.Name
-			}
-		} else if ident, ok := recv.Type.(*ast.Ident); ok {
-			sym.ClassName = ident.Name
-		}
+		sym.ClassName = receiverTypeName(fn.Recv.List[0].Type)
 	} else {
 		// It's a function
 		sym.Kind = graph.NodeFunction
@@ -115,6 +152,18 @@ func (p *GoParser) parseFuncDecl(fn *ast.FuncDecl, fset *token.FileSet, content
 	sig := p.buildSignature(fn, fset, content)
 	sym.Signature = sig
 
+	// Parameter and result types are type references of the function
+	if fn.Type.Params != nil {
+		for _, param := range fn.Type.Params.List {
+			p.addTypeRef(param.Type, "param", sym.Name, sym.ClassName, fset, result)
+		}
+	}
+	if fn.Type.Results != nil {
+		for _, ret := range fn.Type.Results.List {
+			p.addTypeRef(ret.Type, "return", sym.Name, sym.ClassName, fset, result)
+		}
+	}
+
 	// Get content
 	start := fset.Position(fn.Pos()).Offset
 	end := fset.Position(fn.End()).Offset
@@ -164,7 +213,117 @@ func (p *GoParser) parseGenDecl(decl *ast.GenDecl, fset *token.FileSet, content
 			}
 		}
 	case token.VAR, token.CONST:
-		// Could extract variables/constants if needed
+		enums := enumTypes(decl)
+		for i, spec := range decl.Specs {
+			if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+				p.parseValueSpec(valueSpec, decl, enums[i], fset, content, result)
+			}
+		}
+	}
+}
+
+// parseValueSpec records the package-level variables or constants declared
+// by one spec of a var or const declaration. Constants enumerating a type
+// belong to it (enum).
+func (p *GoParser) parseValueSpec(spec *ast.ValueSpec, decl *ast.GenDecl, enum string, fset *token.FileSet, content []byte, result *ParseResult) {
+	kind, keyword := graph.NodeVariable, "var "
+	if decl.Tok == token.CONST {
+		kind, keyword = graph.NodeConstant, "const "
+	}
+
+	// An ungrouped declaration includes its keyword
+	var node ast.Node = spec
+	if !decl.Lparen.IsValid() {
+		node = decl
+	}
+	doc := strings.TrimSpace(spec.Doc.Text())
+	if doc == "" && len(decl.Specs) == 1 {
+		doc = strings.TrimSpace(decl.Doc.Text())
+	}
+	if doc == "" {
+		doc = strings.TrimSpace(spec.Comment.Text())
+	}
+	typeText := p.nodeText(spec.Type, fset, content)
+	if typeText == "" {
+		typeText = enum
+	}
+
+	for _, name := range spec.Names {
+		if name.Name == "_" {
+			continue
+		}
+		sym := ParsedSymbol{
+			Name:       name.Name,
+			Kind:       kind,
+			StartLine:  fset.Position(node.Pos()).Line,
+			EndLine:    fset.Position(node.End()).Line,
+			Content:    p.nodeText(node, fset, content),
+			Signature:  strings.TrimSpace(keyword + name.Name + " " + typeText),
+			Doc:        doc,
+			ClassName:  enum,
+			IsExported: name.IsExported(),
+		}
+		result.Symbols = append(result.Symbols, sym)
+	}
+}
+
+// enumTypes returns, for each spec of a declaration, the type its
+// constants enumerate: the declared type of a const group using iota,
+// which the following specs without a type or value repeat. It is "" for
+// other specs.
+func enumTypes(decl *ast.GenDecl) []string {
+	enums := make([]string, len(decl.Specs))
+	if decl.Tok != token.CONST {
+		return enums
+	}
+	enum := ""
+	for i, spec := range decl.Specs {
+		valueSpec, ok := spec.(*ast.ValueSpec)
+		if !ok {
+			continue
+		}
+		if valueSpec.Type != nil || len(valueSpec.Values) > 0 {
+			enum = ""
+			if ident, ok := valueSpec.Type.(*ast.Ident); ok && usesIota(valueSpec.Values) {
+				enum = ident.Name
+			}
+		}
+		enums[i] = enum
+	}
+	return enums
+}
+
+// usesIota reports whether any of the expressions refers to iota.
+func usesIota(exprs []ast.Expr) bool {
+	found := false
+	for _, expr := range exprs {
+		ast.Inspect(expr, func(n ast.Node) bool {
+			if ident, ok := n.(*ast.Ident); ok && ident.Name == "iota" {
+				found = true
+			}
+			return !found
+		})
+	}
+	return found
+}
+
+// markEnums turns the types of the file that a const group using iota
+// enumerates (type Color int; const ( Red Color = iota; ... )) into enums.
+func (p *GoParser) markEnums(file *ast.File, result *ParseResult) {
+	enums := make(map[string]bool)
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok {
+			for _, enum := range enumTypes(gen) {
+				if enum != "" {
+					enums[enum] = true
+				}
+			}
+		}
+	}
+	for i := range result.Symbols {
+		if sym := &result.Symbols[i]; sym.Kind == graph.NodeTypeAlias && enums[sym.Name] {
+			sym.Kind = graph.NodeEnum
+		}
 	}
 }
 
@@ -173,22 +332,23 @@ func (p *GoParser) parseTypeSpec(typeSpec *ast.TypeSpec, decl *ast.GenDecl, fset
 		Name:       typeSpec.Name.Name,
 		StartLine:  fset.Position(decl.Pos()).Line,
 		EndLine:    fset.Position(decl.End()).Line,
+		Doc:        strings.TrimSpace(typeSpec.Doc.Text()),
 		IsExported: typeSpec.Name.IsExported(),
 	}
+	if sym.Doc == "" && len(decl.Specs) == 1 {
+		sym.Doc = strings.TrimSpace(decl.Doc.Text())
+	}
+	var fields []ParsedSymbol
 
 	switch t := typeSpec.Type.(type) {
 	case *ast.StructType:
 		sym.Kind = graph.NodeClass
 		sym.Signature = "type " + typeSpec.Name.Name + " struct"
-		// Extract fields as type references
+		// Extract fields, and their types as type references
 		if t.Fields != nil {
 			for _, field := range t.Fields.List {
-				fieldType := p.nodeText(field.Type, fset, content)
-				result.TypeRefs = append(result.TypeRefs, TypeAnnotation{
-					Name:      fieldType,
-					Role:      "field",
-					StartLine: fset.Position(field.Pos()).Line,
-				})
+				p.addTypeRef(field.Type, "field", sym.Name, "", fset, result)
+				fields = append(fields, p.parseField(field, sym.Name, fset, content)...)
 			}
 		}
 
@@ -198,11 +358,16 @@ func (p *GoParser) parseTypeSpec(typeSpec *ast.TypeSpec, decl *ast.GenDecl, fset
 		// Extract methods
 		if t.Methods != nil {
 			for _, method := range t.Methods.List {
+				if fnType, ok := method.Type.(*ast.FuncType); ok {
+					p.parseInterfaceMethod(method, fnType, typeSpec.Name.Name, fset, content, result)
+					continue
+				}
 				if ident, ok := method.Type.(*ast.Ident); ok {
 					result.TypeRefs = append(result.TypeRefs, TypeAnnotation{
 						Name:      ident.Name,
 						Role:      "method",
 						StartLine: fset.Position(method.Pos()).Line,
+						Enclosing: sym.Name,
 					})
 				}
 			}
@@ -223,23 +388,162 @@ func (p *GoParser) parseTypeSpec(typeSpec *ast.TypeSpec, decl *ast.GenDecl, fset
 	}
 
 	result.Symbols = append(result.Symbols, sym)
+	result.Symbols = append(result.Symbols, fields...)
+}
+
+// parseField returns the fields of a struct declared by one field list
+// entry. An embedded field is named after its type.
+func (p *GoParser) parseField(field *ast.Field, structName string, fset *token.FileSet, content []byte) []ParsedSymbol {
+	names := make([]string, 0, len(field.Names))
+	for _, name := range field.Names {
+		names = append(names, name.Name)
+	}
+	if len(field.Names) == 0 {
+		names = append(names, typeRefName(field.Type))
+	}
+
+	doc := strings.TrimSpace(field.Doc.Text())
+	if doc == "" {
+		doc = strings.TrimSpace(field.Comment.Text())
+	}
+	typeText := p.nodeText(field.Type, fset, content)
+	var fields []ParsedSymbol
+	for _, name := range names {
+		if name == "" || name == "_" {
+			continue
+		}
+		signature := typeText
+		if len(field.Names) > 0 {
+			signature = name + " " + typeText
+		}
+		fields = append(fields, ParsedSymbol{
+			Name:       name,
+			Kind:       graph.NodeField,
+			ClassName:  structName,
+			StartLine:  fset.Position(field.Pos()).Line,
+			EndLine:    fset.Position(field.End()).Line,
+			Content:    p.nodeText(field, fset, content),
+			Signature:  signature,
+			Doc:        doc,
+			IsExported: token.IsExported(name),
+		})
+	}
+	return fields
+}
+
+// parseInterfaceMethod records a method declared in an interface as a method
+// symbol of the interface, so that calls through the interface and the
+// methods implementing it can be linked to it.
+func (p *GoParser) parseInterfaceMethod(method *ast.Field, fnType *ast.FuncType, ifaceName string, fset *token.FileSet, content []byte, result *ParseResult) {
+	for _, name := range method.Names {
+		sym := ParsedSymbol{
+			Name:       name.Name,
+			Kind:       graph.NodeMethod,
+			ClassName:  ifaceName,
+			StartLine:  fset.Position(method.Pos()).Line,
+			EndLine:    fset.Position(method.End()).Line,
+			Content:    p.nodeText(method, fset, content),
+			Signature:  name.Name + strings.TrimPrefix(p.nodeText(fnType, fset, content), "func"),
+			Doc:        strings.TrimSpace(method.Doc.Text()),
+			IsExported: name.IsExported(),
+		}
+		result.Symbols = append(result.Symbols, sym)
+
+		if fnType.Params != nil {
+			for _, param := range fnType.Params.List {
+				p.addTypeRef(param.Type, "param", sym.Name, ifaceName, fset, result)
+			}
+		}
+		if fnType.Results != nil {
+			for _, ret := range fnType.Results.List {
+				p.addTypeRef(ret.Type, "return", sym.Name, ifaceName, fset, result)
+			}
+		}
+	}
 }
 
 func (p *GoParser) parseCalls(file *ast.File, fset *token.FileSet, content []byte, result *ParseResult) {
 	// Build receiver variable map for this file
 	receiverMap := p.buildReceiverMap(file, fset)
 
-	ast.Inspect(file, func(n ast.Node) bool {
-		if callExpr, ok := n.(*ast.CallExpr); ok {
-			call := p.extractCall(callExpr, fset, content, result, receiverMap)
-			if call.Name != "" {
-				result.Calls = append(result.Calls, call)
+	// Walk each declaration separately so that every call is attributed to
+	// the function it appears in (calls in package-level var initializers
+	// have no enclosing symbol).
+	for _, decl := range file.Decls {
+		enclosing, enclosingClass := declName(decl)
+
+		ast.Inspect(decl, func(n ast.Node) bool {
+			if callExpr, ok := n.(*ast.CallExpr); ok {
+				call := p.extractCall(callExpr, fset, content, result, receiverMap)
+				if call.Name != "" {
+					call.Enclosing = enclosing
+					call.EnclosingClass = enclosingClass
+					result.Calls = append(result.Calls, call)
+				}
 			}
-		}
-		return true
+			return true
+		})
+	}
+}
+
+// declName returns the function name and receiver type name of a function
+// declaration, or empty strings for other declarations.
+func declName(decl ast.Decl) (name, className string) {
+	fn, ok := decl.(*ast.FuncDecl)
+	if !ok {
+		return "", ""
+	}
+	if fn.Recv != nil && len(fn.Recv.List) > 0 {
+		className = receiverTypeName(fn.Recv.List[0].Type)
+	}
+	return fn.Name.Name, className
+}
+
+// addTypeRef records a reference to the named type used in expr.
+// Predeclared types (string, error, ...) are skipped.
+func (p *GoParser) addTypeRef(expr ast.Expr, role, enclosing, enclosingClass string, fset *token.FileSet, result *ParseResult) {
+	name := typeRefName(expr)
+	if name == "" || types.Universe.Lookup(name) != nil {
+		return
+	}
+	result.TypeRefs = append(result.TypeRefs, TypeAnnotation{
+		Name:           name,
+		Role:           role,
+		StartLine:      fset.Position(expr.Pos()).Line,
+		Enclosing:      enclosing,
+		EnclosingClass: enclosingClass,
 	})
 }
 
+// typeRefName returns the name of the named type referenced by a type
+// expression, looking through pointers, slices, maps, channels and package
+// qualifiers (e.g. *graph.KnowledgeGraph -> KnowledgeGraph).
+func typeRefName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.Ident:
+		return t.Name
+	case *ast.SelectorExpr:
+		return t.Sel.Name
+	case *ast.StarExpr:
+		return typeRefName(t.X)
+	case *ast.ParenExpr:
+		return typeRefName(t.X)
+	case *ast.ArrayType:
+		return typeRefName(t.Elt)
+	case *ast.Ellipsis:
+		return typeRefName(t.Elt)
+	case *ast.MapType:
+		return typeRefName(t.Value)
+	case *ast.ChanType:
+		return typeRefName(t.Value)
+	case *ast.IndexExpr:
+		return typeRefName(t.X)
+	case *ast.IndexListExpr:
+		return typeRefName(t.X)
+	}
+	return ""
+}
+
 // buildReceiverMap builds a map of receiver variable names to their type names.
 func (p *GoParser) buildReceiverMap(file *ast.File, fset *token.FileSet) map[string]string {
 	receiverMap := make(map[string]string)
@@ -251,16 +555,8 @@ func (p *GoParser) buildReceiverMap(file *ast.File, fset *token.FileSet) map[str
 		}
 
 		// Get receiver type name
-		var typeName string
 		recv := fn.Recv.List[0]
-		if typeNameExpr, ok := recv.Type.(*ast.StarExpr); ok {
-			if ident, ok := typeNameExpr.X.(*ast.Ident); ok {
-				typeName = ident.Name
-			}
-		} else if ident, ok := recv.Type.(*ast.Ident); ok {
-			typeName = ident.Name
-		}
-
+		typeName := receiverTypeName(recv.Type)
 		if typeName == "" {
 			continue
 		}
@@ -274,6 +570,24 @@ func (p *GoParser) buildReceiverMap(file *ast.File, fset *token.FileSet) map[str
 	return receiverMap
 }
 
+// receiverTypeName returns the type name of a method receiver expression,
+// handling pointer receivers and generic receivers such as *Stack[T].
+func receiverTypeName(expr ast.Expr) string {
+	switch t := expr.(type) {
+	case *ast.StarExpr:
+		return receiverTypeName(t.X)
+	case *ast.ParenExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexExpr:
+		return receiverTypeName(t.X)
+	case *ast.IndexListExpr:
+		return receiverTypeName(t.X)
+	case *ast.Ident:
+		return t.Name
+	}
+	return ""
+}
+
 func (p *GoParser) extractCall(callExpr *ast.CallExpr, fset *token.FileSet, content []byte, result *ParseResult, receiverMap map[string]string) CallSite {
 	call := CallSite{
 		StartLine: fset.Position(callExpr.Pos()).Line,
@@ -17,7 +17,7 @@ package cmd
 import "github.com/Benny93/axon-go/internal/ingestion"
 
 func testFunc() {
-	_, result, err := ingestion.RunPipeline(ctx, repoPath, store, false, nil, false)
+	_, result, err := ingestion.RunPipeline(ctx, repoPath, store, false, nil, false, 0)
 }
 `)
 		parser := NewGoParser()
@@ -52,7 +52,7 @@ import (
 )
 
 func testFunc() {
-	ingestion.RunPipeline(ctx, repoPath, store, false, nil, false)
+	ingestion.RunPipeline(ctx, repoPath, store, false, nil, false, 0)
 	storage.NewBadgerBackend()
 }
 `)
@@ -113,7 +113,7 @@ import (
 
 func testFunc() {
 	fmt.Println("hello")
-	ingestion.RunPipeline(ctx, repoPath, store, false, nil, false)
+	ingestion.RunPipeline(ctx, repoPath, store, false, nil, false, 0)
 }
 `)
 		parser := NewGoParser()
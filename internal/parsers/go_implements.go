@@ -0,0 +1,169 @@
+package parsers
+
+import (
+	"go/types"
+	"sort"
+)
+
+// Implementation records that a named type satisfies an interface.
+type Implementation struct {
+	// TypeFile is the repository-relative file declaring the type
+	TypeFile string
+
+	// TypeName is the name of the concrete type
+	TypeName string
+
+	// InterfaceFile is the repository-relative file declaring the interface
+	InterfaceFile string
+
+	// InterfaceName is the name of the interface
+	InterfaceName string
+
+	// PointerReceiver is true when only *T (not T) has all the methods
+	PointerReceiver bool
+
+	// Methods pairs each interface method with the method implementing it
+	Methods []MethodImplementation
+}
+
+// MethodImplementation links an interface method to the concrete method
+// that is invoked when it is called on a given type.
+type MethodImplementation struct {
+	// Interface is the declaration of the interface method
+	Interface *CallTarget
+
+	// Concrete is the declaration of the implementing method (which may
+	// be promoted from an embedded type)
+	Concrete *CallTarget
+}
+
+// goNamedType is a named type declared in the repository.
+type goNamedType struct {
+	file  string
+	named *types.Named
+}
+
+// Implementations computes which named types declared in the repository
+// satisfy which non-empty interfaces declared in the repository, using
+// go/types method sets (so pointer receivers and methods promoted through
+// embedding are taken into account). Generic types are skipped.
+func (p *GoProgram) Implementations() []Implementation {
+	var concrete, interfaces []goNamedType
+	for _, pkg := range p.pkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			tn, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || tn.IsAlias() {
+				continue
+			}
+			named, ok := tn.Type().(*types.Named)
+			if !ok || named.TypeParams().Len() > 0 {
+				continue
+			}
+			file := p.relPath(p.fset.Position(tn.Pos()).Filename)
+			if file == "" {
+				continue
+			}
+
+			if iface, ok := named.Underlying().(*types.Interface); ok {
+				if iface.NumMethods() > 0 {
+					interfaces = append(interfaces, goNamedType{file: file, named: named})
+				}
+				continue
+			}
+			concrete = append(concrete, goNamedType{file: file, named: named})
+		}
+	}
+
+	seen := make(map[string]bool)
+	var impls []Implementation
+	for _, t := range concrete {
+		ptr := types.NewPointer(t.named)
+
+		// Cheap pre-filter on method names before calling types.Implements
+		methodNames := make(map[string]bool)
+		mset := types.NewMethodSet(ptr)
+		for i := 0; i < mset.Len(); i++ {
+			methodNames[mset.At(i).Obj().Name()] = true
+		}
+
+		for _, it := range interfaces {
+			iface := it.named.Underlying().(*types.Interface)
+			if !hasMethodNames(iface, methodNames) {
+				continue
+			}
+
+			var pointerReceiver bool
+			switch {
+			case types.Implements(t.named, iface):
+			case types.Implements(ptr, iface):
+				pointerReceiver = true
+			default:
+				continue
+			}
+
+			impl := Implementation{
+				TypeFile:        t.file,
+				TypeName:        t.named.Obj().Name(),
+				InterfaceFile:   it.file,
+				InterfaceName:   it.named.Obj().Name(),
+				PointerReceiver: pointerReceiver,
+			}
+			key := impl.TypeFile + ":" + impl.TypeName + "->" + impl.InterfaceFile + ":" + impl.InterfaceName
+			if seen[key] {
+				continue
+			}
+			seen[key] = true
+			impl.Methods = p.methodImplementations(mset, iface)
+			impls = append(impls, impl)
+		}
+	}
+
+	sort.Slice(impls, func(i, j int) bool {
+		a, b := impls[i], impls[j]
+		if a.InterfaceFile != b.InterfaceFile {
+			return a.InterfaceFile < b.InterfaceFile
+		}
+		if a.InterfaceName != b.InterfaceName {
+			return a.InterfaceName < b.InterfaceName
+		}
+		if a.TypeFile != b.TypeFile {
+			return a.TypeFile < b.TypeFile
+		}
+		return a.TypeName < b.TypeName
+	})
+
+	return impls
+}
+
+// methodImplementations resolves every method of iface in the method set
+// of a concrete type.
+func (p *GoProgram) methodImplementations(mset *types.MethodSet, iface *types.Interface) []MethodImplementation {
+	var methods []MethodImplementation
+	for i := 0; i < iface.NumMethods(); i++ {
+		ifaceMethod := iface.Method(i)
+		sel := mset.Lookup(ifaceMethod.Pkg(), ifaceMethod.Name())
+		if sel == nil {
+			continue
+		}
+		concrete, ok := sel.Obj().(*types.Func)
+		if !ok {
+			continue
+		}
+		methods = append(methods, MethodImplementation{
+			Interface: p.targetFor(ifaceMethod),
+			Concrete:  p.targetFor(concrete.Origin()),
+		})
+	}
+	return methods
+}
+
+// hasMethodNames reports whether names contains every method of iface.
+func hasMethodNames(iface *types.Interface, names map[string]bool) bool {
+	for i := 0; i < iface.NumMethods(); i++ {
+		if !names[iface.Method(i).Name()] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,102 @@
+package parsers
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGoProgram_Implementations(t *testing.T) {
+	t.Parallel()
+
+	dir := writeGoModule(t, map[string]string{
+		"go.mod": "module example.com/kv\n\ngo 1.22\n",
+		"storage/backend.go": `package storage
+
+type Backend interface {
+	Get(key string) ([]byte, error)
+	Close() error
+}
+
+type Closer interface {
+	Close() error
+}
+
+type Any interface{}
+`,
+		"storage/badger.go": `package storage
+
+type BadgerBackend struct{}
+
+func (b *BadgerBackend) Get(key string) ([]byte, error) { return nil, nil }
+func (b *BadgerBackend) Close() error                    { return nil }
+`,
+		"memory/memory.go": `package memory
+
+type base struct{}
+
+func (base) Close() error { return nil }
+
+// MemoryBackend gets Close from the embedded base type.
+type MemoryBackend struct {
+	base
+}
+
+func (m MemoryBackend) Get(key string) ([]byte, error) { return nil, nil }
+
+type ReadOnly struct{}
+
+func (ReadOnly) Get(key string) ([]byte, error) { return nil, nil }
+`,
+	})
+
+	prog, err := LoadGoProgram(context.Background(), dir)
+	require.NoError(t, err)
+
+	impls := make(map[string]Implementation)
+	for _, impl := range prog.Implementations() {
+		impls[impl.TypeName+"->"+impl.InterfaceName] = impl
+	}
+
+	t.Run("PointerReceiver", func(t *testing.T) {
+		impl, ok := impls["BadgerBackend->Backend"]
+		require.True(t, ok)
+		assert.True(t, impl.PointerReceiver)
+		assert.Equal(t, filepath.Join("storage", "badger.go"), impl.TypeFile)
+		assert.Equal(t, filepath.Join("storage", "backend.go"), impl.InterfaceFile)
+	})
+
+	t.Run("CrossPackageWithEmbedding", func(t *testing.T) {
+		impl, ok := impls["MemoryBackend->Backend"]
+		require.True(t, ok)
+		assert.False(t, impl.PointerReceiver)
+		assert.Equal(t, filepath.Join("memory", "memory.go"), impl.TypeFile)
+	})
+
+	t.Run("PartialMethodSet", func(t *testing.T) {
+		assert.NotContains(t, impls, "ReadOnly->Backend")
+		assert.Contains(t, impls, "base->Closer")
+	})
+
+	t.Run("MethodImplementations", func(t *testing.T) {
+		impl, ok := impls["MemoryBackend->Backend"]
+		require.True(t, ok)
+
+		concrete := make(map[string]*CallTarget)
+		for _, m := range impl.Methods {
+			assert.Equal(t, "Backend", m.Interface.ClassName)
+			assert.Equal(t, filepath.Join("storage", "backend.go"), m.Interface.FilePath)
+			concrete[m.Interface.Name] = m.Concrete
+		}
+		require.Len(t, concrete, 2)
+		assert.Equal(t, "MemoryBackend", concrete["Get"].ClassName)
+		assert.Equal(t, "base", concrete["Close"].ClassName)
+	})
+
+	t.Run("SkipsEmptyInterfaces", func(t *testing.T) {
+		assert.NotContains(t, impls, "BadgerBackend->Any")
+	})
+}
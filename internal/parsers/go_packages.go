@@ -0,0 +1,394 @@
+package parsers
+
+import (
+	"context"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"golang.org/x/tools/go/packages"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// goLoadMode is the go/packages load mode needed for type-checked analysis.
+//
+// Dependencies are type-checked from source (NeedDeps) rather than from
+// compiler export data, which ties the loader to the export data format of
+// the installed toolchain. Function bodies outside the repository are
+// dropped while parsing, which keeps this affordable.
+const goLoadMode = packages.NeedName |
+	packages.NeedFiles |
+	packages.NeedCompiledGoFiles |
+	packages.NeedImports |
+	packages.NeedDeps |
+	packages.NeedSyntax |
+	packages.NeedTypes |
+	packages.NeedTypesInfo
+
+// GoProgram holds the type-checked packages of a Go module.
+//
+// Unlike GoParser, which looks at one file at a time, a GoProgram is loaded
+// with golang.org/x/tools/go/packages so that every identifier can be
+// resolved to the exact types.Object it refers to, across package boundaries.
+type GoProgram struct {
+	fset  *token.FileSet
+	roots []string
+	files map[string]*goTypedFile
+	order []string
+
+	// pkgs are the type-checked packages (and test variants) declared in
+	// the repository.
+	pkgs []*packages.Package
+
+	// fieldOwners maps the fields of the repository's named struct types
+	// to the name of their type.
+	fieldOwners map[*types.Var]string
+}
+
+// goTypedFile pairs a syntax tree with the package it was type-checked in.
+type goTypedFile struct {
+	pkg  *packages.Package
+	file *ast.File
+}
+
+// LoadGoProgram loads and type-checks every package (including tests) below
+// repoPath. It returns an error if the go tool cannot list the packages;
+// packages with type errors are still returned with partial type information.
+func LoadGoProgram(ctx context.Context, repoPath string) (*GoProgram, error) {
+	absRoot, err := filepath.Abs(repoPath)
+	if err != nil {
+		return nil, fmt.Errorf("resolving repo path: %w", err)
+	}
+
+	prog := &GoProgram{
+		fset:  token.NewFileSet(),
+		roots: []string{absRoot},
+		files: make(map[string]*goTypedFile),
+	}
+	if resolved, err := filepath.EvalSymlinks(absRoot); err == nil && resolved != absRoot {
+		prog.roots = append(prog.roots, resolved)
+	}
+
+	cfg := &packages.Config{
+		Context: ctx,
+		Mode:    goLoadMode,
+		Dir:     absRoot,
+		Fset:    prog.fset,
+		Tests:   true,
+		Env:     os.Environ(),
+		ParseFile: func(fset *token.FileSet, filename string, src []byte) (*ast.File, error) {
+			if prog.relPath(filename) != "" {
+				return parser.ParseFile(fset, filename, src, parser.ParseComments)
+			}
+			if filepath.Ext(filename) != ".go" {
+				// cgo translates the files importing "C" into the build
+				// cache; their //line directives point back at the source
+				file, err := parser.ParseFile(fset, filename, src, parser.ParseComments)
+				if file != nil && prog.relPath(fset.Position(file.Package).Filename) == "" {
+					stripFuncBodies(file)
+				}
+				return file, err
+			}
+			file, err := parser.ParseFile(fset, filename, src, parser.SkipObjectResolution)
+			if file != nil {
+				stripFuncBodies(file)
+			}
+			return file, err
+		},
+	}
+
+	pkgs, err := packages.Load(cfg, "./...")
+	if err != nil {
+		return nil, fmt.Errorf("loading Go packages: %w", err)
+	}
+	// Non-test packages go first so that each file is analysed in the
+	// package variant the rest of the module links against; test variants
+	// only contribute their _test.go files.
+	for _, testPass := range []bool{false, true} {
+		for _, pkg := range pkgs {
+			if prog.relPath(pkgDir(pkg)) == "" {
+				continue
+			}
+			if isTestVariant(pkg) != testPass || pkg.TypesInfo == nil {
+				continue
+			}
+			prog.pkgs = append(prog.pkgs, pkg)
+			for _, file := range pkg.Syntax {
+				relPath := prog.relPath(prog.fset.Position(file.Pos()).Filename)
+				if relPath == "" {
+					continue
+				}
+				if _, seen := prog.files[relPath]; seen {
+					continue
+				}
+				prog.files[relPath] = &goTypedFile{pkg: pkg, file: file}
+				prog.order = append(prog.order, relPath)
+			}
+		}
+	}
+
+	if len(prog.files) == 0 {
+		return nil, fmt.Errorf("loading Go packages: no packages found in %s", repoPath)
+	}
+	prog.indexFieldOwners()
+
+	return prog, nil
+}
+
+// stripFuncBodies removes function bodies from a dependency's syntax tree;
+// only its declarations are needed to type-check the repository.
+func stripFuncBodies(file *ast.File) {
+	for _, decl := range file.Decls {
+		if fn, ok := decl.(*ast.FuncDecl); ok {
+			fn.Body = nil
+		}
+	}
+}
+
+// pkgDir returns the directory of a package's first source file.
+func pkgDir(pkg *packages.Package) string {
+	if len(pkg.GoFiles) == 0 {
+		return ""
+	}
+	return filepath.Dir(pkg.GoFiles[0])
+}
+
+// isTestVariant reports whether pkg is a test build of a package, an
+// external _test package or a generated test main.
+func isTestVariant(pkg *packages.Package) bool {
+	return strings.Contains(pkg.ID, " [") ||
+		strings.HasSuffix(pkg.ID, ".test") ||
+		strings.HasSuffix(pkg.PkgPath, "_test")
+}
+
+// Files returns the repository-relative paths of all type-checked files.
+func (p *GoProgram) Files() []string {
+	return p.order
+}
+
+// HasFile reports whether relPath was type-checked.
+func (p *GoProgram) HasFile(relPath string) bool {
+	_, ok := p.files[relPath]
+	return ok
+}
+
+// Calls returns the call sites of a file, resolved through go/types.
+//
+// Every call, method value and reference to a function value is reported.
+// When the callee could be resolved, Target identifies its declaration;
+// Target.FilePath is empty for callees declared outside the repository.
+// Calls through function-typed variables, conversions and builtins are
+// omitted because they do not name a declaration.
+func (p *GoProgram) Calls(relPath string) []CallSite {
+	tf, ok := p.files[relPath]
+	if !ok {
+		return nil
+	}
+
+	// Expressions in call position are reported as calls; everything else
+	// that refers to a function is a reference (function or method value).
+	callees := make(map[ast.Expr]bool)
+	selectors := make(map[*ast.Ident]bool)
+	ast.Inspect(tf.file, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			callees[calleeExpr(n.Fun)] = true
+		case *ast.SelectorExpr:
+			selectors[n.Sel] = true
+		}
+		return true
+	})
+
+	calls := []CallSite{}
+	for _, decl := range tf.file.Decls {
+		enclosing, enclosingClass := declName(decl)
+		for _, call := range p.declCalls(tf, decl, callees, selectors) {
+			call.Enclosing = enclosing
+			call.EnclosingClass = enclosingClass
+			calls = append(calls, call)
+		}
+	}
+
+	return calls
+}
+
+// declCalls returns the call sites within a single declaration.
+func (p *GoProgram) declCalls(tf *goTypedFile, decl ast.Decl, callees map[ast.Expr]bool, selectors map[*ast.Ident]bool) []CallSite {
+	info := tf.pkg.TypesInfo
+
+	var calls []CallSite
+	ast.Inspect(decl, func(n ast.Node) bool {
+		var (
+			expr   ast.Expr
+			report ast.Node
+		)
+		switch n := n.(type) {
+		case *ast.CallExpr:
+			expr, report = calleeExpr(n.Fun), n
+		case *ast.SelectorExpr:
+			if callees[n] {
+				return true
+			}
+			expr, report = n, n
+		case *ast.Ident:
+			if callees[n] || selectors[n] {
+				return true
+			}
+			if _, isFunc := info.Uses[n].(*types.Func); !isFunc {
+				return true
+			}
+			expr, report = n, n
+		default:
+			return true
+		}
+
+		call, ok := p.resolveCall(tf, expr, callees[expr])
+		if !ok {
+			return true
+		}
+		call.StartLine = p.fset.Position(report.Pos()).Line
+		call.EndLine = p.fset.Position(report.End()).Line
+		calls = append(calls, call)
+		return true
+	})
+
+	return calls
+}
+
+// resolveCall builds a CallSite for a callee expression. The second return
+// value is false when expr does not refer to a function or method.
+func (p *GoProgram) resolveCall(tf *goTypedFile, expr ast.Expr, inCallPosition bool) (CallSite, bool) {
+	info := tf.pkg.TypesInfo
+
+	var (
+		obj  types.Object
+		name *ast.Ident
+	)
+	switch e := expr.(type) {
+	case *ast.Ident:
+		name = e
+		obj = info.Uses[e]
+	case *ast.SelectorExpr:
+		name = e.Sel
+		if sel, ok := info.Selections[e]; ok {
+			if sel.Kind() == types.FieldVal {
+				return CallSite{}, false
+			}
+			obj = sel.Obj()
+		} else {
+			obj = info.Uses[e.Sel]
+		}
+	case *ast.FuncLit:
+		return CallSite{}, false
+	default:
+		return CallSite{}, false
+	}
+
+	if obj == nil {
+		// Unresolved (the package has type errors): keep the syntactic
+		// call site so that the name-based resolver can still try.
+		if !inCallPosition {
+			return CallSite{}, false
+		}
+		if sel, ok := expr.(*ast.SelectorExpr); ok {
+			if pkg, ok := sel.X.(*ast.Ident); ok && pkg.Name == "C" {
+				return CallSite{Name: name.Name, Package: "C"}, true
+			}
+		}
+		return CallSite{Name: name.Name}, true
+	}
+
+	fn, ok := obj.(*types.Func)
+	if !ok {
+		return CallSite{}, false
+	}
+	if cName, ok := strings.CutPrefix(fn.Name(), "_Cfunc_"); ok {
+		// cgo rewrites C.name() to a call of a generated _Cfunc_name
+		return CallSite{Name: cName, Package: "C"}, true
+	}
+	fn = fn.Origin()
+
+	target := p.targetFor(fn)
+	call := CallSite{
+		Name:     fn.Name(),
+		Receiver: target.ClassName,
+		Target:   target,
+	}
+	if target.ClassName == "" && fn.Pkg() != nil && fn.Pkg() != tf.pkg.Types {
+		call.Package = fn.Pkg().Path()
+	}
+	return call, true
+}
+
+// targetFor describes the declaration of fn.
+func (p *GoProgram) targetFor(fn *types.Func) *CallTarget {
+	target := &CallTarget{
+		Name: fn.Name(),
+		Kind: graph.NodeFunction,
+	}
+	if sig, ok := fn.Type().(*types.Signature); ok && sig.Recv() != nil {
+		target.Kind = graph.NodeMethod
+		target.ClassName = namedTypeName(sig.Recv().Type())
+	}
+	if fn.Pkg() != nil {
+		target.Package = fn.Pkg().Path()
+	}
+	if fn.Pos().IsValid() {
+		pos := p.fset.Position(fn.Pos())
+		target.FilePath = p.relPath(pos.Filename)
+		if target.FilePath != "" {
+			target.Line = pos.Line
+		}
+	}
+	return target
+}
+
+// relPath converts an absolute file name to a repository-relative path.
+// It returns "" for files outside the repository.
+func (p *GoProgram) relPath(filename string) string {
+	if filename == "" {
+		return ""
+	}
+	for _, root := range p.roots {
+		rel, err := filepath.Rel(root, filename)
+		if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+			continue
+		}
+		return rel
+	}
+	return ""
+}
+
+// calleeExpr strips parentheses and generic instantiation from a call's
+// function expression.
+func calleeExpr(fun ast.Expr) ast.Expr {
+	for {
+		switch e := fun.(type) {
+		case *ast.ParenExpr:
+			fun = e.X
+		case *ast.IndexExpr:
+			fun = e.X
+		case *ast.IndexListExpr:
+			fun = e.X
+		default:
+			return fun
+		}
+	}
+}
+
+// namedTypeName returns the name of the (possibly pointer to) named type t.
+func namedTypeName(t types.Type) string {
+	if ptr, ok := types.Unalias(t).(*types.Pointer); ok {
+		t = ptr.Elem()
+	}
+	if named, ok := types.Unalias(t).(*types.Named); ok {
+		return named.Obj().Name()
+	}
+	return ""
+}
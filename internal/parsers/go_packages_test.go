@@ -0,0 +1,154 @@
+package parsers
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// writeGoModule writes files into a temporary directory and returns its path.
+func writeGoModule(t *testing.T, files map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for path, content := range files {
+		fullPath := filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(fullPath), 0o755))
+		require.NoError(t, os.WriteFile(fullPath, []byte(content), 0o644))
+	}
+	return dir
+}
+
+// findCall returns the first call site with the given name on the given line.
+func findCall(calls []CallSite, name string, line int) *CallSite {
+	for i := range calls {
+		if calls[i].Name == name && calls[i].StartLine == line {
+			return &calls[i]
+		}
+	}
+	return nil
+}
+
+func TestLoadGoProgram(t *testing.T) {
+	t.Parallel()
+
+	dir := writeGoModule(t, map[string]string{
+		"go.mod": "module example.com/shop\n\ngo 1.22\n",
+		"store/store.go": `package store
+
+type Store struct{}
+
+func New() *Store { return &Store{} }
+
+func (s *Store) Close() error { return nil }
+`,
+		"cache/cache.go": `package cache
+
+type Base struct{}
+
+func (b *Base) Close() error { return nil }
+
+type Cache struct {
+	Base
+}
+
+func New() *Cache { return &Cache{} }
+`,
+		"main.go": `package main
+
+import (
+	"fmt"
+
+	"example.com/shop/cache"
+	"example.com/shop/store"
+)
+
+func main() {
+	s := store.New()
+	c := cache.New()
+	closer := s.Close
+	c.Close()
+	fmt.Println(closer())
+	run(helper)
+}
+
+func helper() {}
+
+func run(f func()) { f() }
+`,
+	})
+
+	prog, err := LoadGoProgram(context.Background(), dir)
+	require.NoError(t, err)
+	assert.True(t, prog.HasFile("main.go"))
+	assert.True(t, prog.HasFile(filepath.Join("store", "store.go")))
+
+	calls := prog.Calls("main.go")
+
+	t.Run("CrossPackageCallsResolveToExactPackage", func(t *testing.T) {
+		storeNew := findCall(calls, "New", 11)
+		require.NotNil(t, storeNew)
+		require.NotNil(t, storeNew.Target)
+		assert.Equal(t, filepath.Join("store", "store.go"), storeNew.Target.FilePath)
+		assert.Equal(t, graph.NodeFunction, storeNew.Target.Kind)
+		assert.Equal(t, "example.com/shop/store", storeNew.Package)
+
+		cacheNew := findCall(calls, "New", 12)
+		require.NotNil(t, cacheNew)
+		require.NotNil(t, cacheNew.Target)
+		assert.Equal(t, filepath.Join("cache", "cache.go"), cacheNew.Target.FilePath)
+	})
+
+	t.Run("MethodValue", func(t *testing.T) {
+		closer := findCall(calls, "Close", 13)
+		require.NotNil(t, closer)
+		require.NotNil(t, closer.Target)
+		assert.Equal(t, graph.NodeMethod, closer.Target.Kind)
+		assert.Equal(t, "Store", closer.Target.ClassName)
+	})
+
+	t.Run("EmbeddedMethodResolvesToDeclaringType", func(t *testing.T) {
+		promoted := findCall(calls, "Close", 14)
+		require.NotNil(t, promoted)
+		require.NotNil(t, promoted.Target)
+		assert.Equal(t, "Base", promoted.Target.ClassName)
+		assert.Equal(t, filepath.Join("cache", "cache.go"), promoted.Target.FilePath)
+	})
+
+	t.Run("ExternalCallsHaveNoFilePath", func(t *testing.T) {
+		println := findCall(calls, "Println", 15)
+		require.NotNil(t, println)
+		require.NotNil(t, println.Target)
+		assert.Empty(t, println.Target.FilePath)
+		assert.Equal(t, "fmt", println.Target.Package)
+	})
+
+	t.Run("FunctionValues", func(t *testing.T) {
+		ref := findCall(calls, "helper", 16)
+		require.NotNil(t, ref)
+		require.NotNil(t, ref.Target)
+		assert.Equal(t, "main.go", ref.Target.FilePath)
+	})
+
+	t.Run("SkipsFunctionTypedVariables", func(t *testing.T) {
+		assert.Nil(t, findCall(calls, "f", 21))
+		assert.Nil(t, findCall(calls, "closer", 15))
+	})
+}
+
+func TestLoadGoProgram_NotAModule(t *testing.T) {
+	t.Parallel()
+
+	dir := writeGoModule(t, map[string]string{
+		"README.md": "# not go\n",
+	})
+
+	_, err := LoadGoProgram(context.Background(), dir)
+	assert.Error(t, err)
+}
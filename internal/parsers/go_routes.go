@@ -0,0 +1,277 @@
+package parsers
+
+import (
+	"go/ast"
+	"go/token"
+	"maps"
+	"strconv"
+	"strings"
+)
+
+// goRouterFrameworks maps the import paths of the supported HTTP routers to
+// their framework names.
+var goRouterFrameworks = map[string]string{
+	"github.com/go-chi/chi":    "chi",
+	"github.com/gin-gonic/gin": "gin",
+	"github.com/labstack/echo": "echo",
+}
+
+// goChiMethods are the chi router methods that register a route for one
+// HTTP method.
+var goChiMethods = map[string]bool{
+	"Get": true, "Post": true, "Put": true, "Patch": true, "Delete": true,
+	"Head": true, "Options": true, "Connect": true, "Trace": true,
+}
+
+// goMethods are the gin and echo router methods that register a route for
+// one HTTP method, and Any for all of them.
+var goMethods = map[string]bool{
+	"GET": true, "POST": true, "PUT": true, "PATCH": true, "DELETE": true,
+	"HEAD": true, "OPTIONS": true, "CONNECT": true, "TRACE": true, "Any": true,
+}
+
+// goHTTPWrappers are the net/http functions that wrap a handler, mapped to
+// the index of the wrapped handler among their arguments. The other
+// net/http functions returning a handler (FileServer, NotFoundHandler,
+// RedirectHandler) serve requests without code of the repository.
+var goHTTPWrappers = map[string]int{
+	"HandlerFunc": 0, "StripPrefix": 1, "TimeoutHandler": 0,
+	"MaxBytesHandler": 0, "AllowQuerySemicolons": 0,
+}
+
+// goRouteParser records the HTTP routes registered in a Go file.
+type goRouteParser struct {
+	fset        *token.FileSet
+	result      *ParseResult
+	receiverMap map[string]string
+
+	// framework is the router the file imports, or net/http
+	framework string
+
+	enclosing, enclosingClass string
+}
+
+// parseRoutes records the routes registered with net/http (HandleFunc and
+// Handle, including Go 1.22 "METHOD /path" patterns), chi, gin and echo.
+// Only routes with a literal path are recorded. The prefixes of gin and
+// echo groups, and of chi Route and Group blocks, are tracked within a
+// function.
+func (p *GoParser) parseRoutes(file *ast.File, fset *token.FileSet, result *ParseResult) {
+	rp := &goRouteParser{
+		fset:        fset,
+		result:      result,
+		receiverMap: p.buildReceiverMap(file, fset),
+		framework:   "net/http",
+	}
+	for _, path := range result.PackageImports {
+		for prefix, framework := range goRouterFrameworks {
+			if path == prefix || strings.HasPrefix(path, prefix+"/") {
+				rp.framework = framework
+			}
+		}
+	}
+
+	for _, decl := range file.Decls {
+		rp.enclosing, rp.enclosingClass = declName(decl)
+		rp.walk(decl, make(map[string]string))
+	}
+}
+
+// walk records the routes registered in node. prefixes maps the router
+// variables in scope to their path prefix.
+func (rp *goRouteParser) walk(node ast.Node, prefixes map[string]string) {
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			// v1 := r.Group("/v1")
+			if len(n.Lhs) != 1 || len(n.Rhs) != 1 {
+				return true
+			}
+			target, ok := n.Lhs[0].(*ast.Ident)
+			call, isCall := n.Rhs[0].(*ast.CallExpr)
+			if !ok || !isCall {
+				return true
+			}
+			if sel, ok := call.Fun.(*ast.SelectorExpr); ok && sel.Sel.Name == "Group" && len(call.Args) > 0 {
+				if path, ok := stringLit(call.Args[0]); ok {
+					prefixes[target.Name] = prefixes[rootIdent(sel.X)] + path
+				}
+			}
+		case *ast.CallExpr:
+			sel, ok := n.Fun.(*ast.SelectorExpr)
+			if !ok {
+				return true
+			}
+			prefix := prefixes[rootIdent(sel.X)]
+
+			// r.Route("/users", func(r chi.Router) { ... }) and
+			// r.Group(func(r chi.Router) { ... })
+			if lit, ok := lastArg(n).(*ast.FuncLit); ok && (sel.Sel.Name == "Route" || sel.Sel.Name == "Group") {
+				inner := maps.Clone(prefixes)
+				if sel.Sel.Name == "Route" && len(n.Args) == 2 {
+					path, _ := stringLit(n.Args[0])
+					prefix += path
+				}
+				if params := lit.Type.Params.List; len(params) == 1 && len(params[0].Names) == 1 {
+					inner[params[0].Names[0].Name] = prefix
+				}
+				rp.walk(lit.Body, inner)
+				return false
+			}
+			rp.route(n, sel, prefix)
+		}
+		return true
+	})
+}
+
+// route records the route registered by call, if it is a registration.
+func (rp *goRouteParser) route(call *ast.CallExpr, sel *ast.SelectorExpr, prefix string) {
+	name := sel.Sel.Name
+	route := Route{
+		Framework:      rp.framework,
+		StartLine:      rp.fset.Position(call.Pos()).Line,
+		Enclosing:      rp.enclosing,
+		EnclosingClass: rp.enclosingClass,
+	}
+	var methodArg, pathArg, handler ast.Expr
+	switch {
+	case (name == "HandleFunc" || name == "Handle") && len(call.Args) >= 3:
+		// gin: r.Handle("GET", "/users", h)
+		methodArg, pathArg, handler = call.Args[0], call.Args[1], lastArg(call)
+	case (name == "HandleFunc" || name == "Handle") && len(call.Args) == 2:
+		if x, ok := sel.X.(*ast.Ident); ok && rp.result.PackageImports[x.Name] == "net/http" || rp.framework != "chi" {
+			route.Framework = "net/http"
+		}
+		route.Method = "ANY"
+		pathArg, handler = call.Args[0], call.Args[1]
+	case rp.framework == "chi" && goChiMethods[name] && len(call.Args) == 2:
+		route.Method = name
+		pathArg, handler = call.Args[0], call.Args[1]
+	case rp.framework == "chi" && (name == "Method" || name == "MethodFunc") && len(call.Args) == 3:
+		methodArg, pathArg, handler = call.Args[0], call.Args[1], call.Args[2]
+	case (rp.framework == "gin" || rp.framework == "echo") && goMethods[name] && len(call.Args) >= 2:
+		// gin handlers follow their middleware, echo middleware follows
+		// the handler
+		route.Method = name
+		pathArg, handler = call.Args[0], lastArg(call)
+		if rp.framework == "echo" {
+			handler = call.Args[1]
+		}
+	case rp.framework == "echo" && name == "Add" && len(call.Args) >= 3:
+		methodArg, pathArg, handler = call.Args[0], call.Args[1], call.Args[2]
+	default:
+		return
+	}
+
+	path, ok := stringLit(pathArg)
+	if !ok {
+		return
+	}
+	if methodArg != nil {
+		if route.Method, ok = stringLit(methodArg); !ok {
+			return
+		}
+	}
+	if route.Framework == "net/http" {
+		// Go 1.22 patterns: "GET /users/{id}", "example.com/users"
+		if method, rest, ok := strings.Cut(path, " "); ok {
+			route.Method, path = method, strings.TrimSpace(rest)
+		}
+		if slash := strings.IndexByte(path, '/'); slash > 0 {
+			path = path[slash:]
+		}
+	}
+	route.Method = strings.ToUpper(route.Method)
+	route.Path = prefix + path
+	var inCode bool
+	route.Handler, route.Receiver, inCode = rp.handler(handler)
+	route.NoHandler = !inCode
+	rp.result.Routes = append(rp.result.Routes, route)
+}
+
+// handler returns the name of the function or method a handler expression
+// refers to, and the receiver type of a method. Handler factories
+// (h.List()) refer to the factory, wrappers (http.HandlerFunc(f)) to what
+// they wrap, and values (&Handler{}) to their ServeHTTP method. Function
+// literals are inline handlers, with no name. ok is false for handlers
+// with no code in the repository: the ones net/http provides
+// (http.FileServer, http.NotFound), and expressions it cannot follow.
+func (rp *goRouteParser) handler(expr ast.Expr) (name, receiver string, ok bool) {
+	switch e := expr.(type) {
+	case *ast.Ident:
+		return e.Name, "", true
+	case *ast.SelectorExpr:
+		x, isIdent := e.X.(*ast.Ident)
+		if !isIdent {
+			return e.Sel.Name, "", true
+		}
+		if path, imported := rp.result.PackageImports[x.Name]; imported {
+			return e.Sel.Name, "", path != "net/http"
+		}
+		if typeName, ok := rp.receiverMap[x.Name]; ok {
+			return e.Sel.Name, typeName, true
+		}
+		return e.Sel.Name, x.Name, true
+	case *ast.CallExpr:
+		if sel, isSel := e.Fun.(*ast.SelectorExpr); isSel {
+			if x, isIdent := sel.X.(*ast.Ident); isIdent && rp.result.PackageImports[x.Name] == "net/http" {
+				if arg, wraps := goHTTPWrappers[sel.Sel.Name]; wraps && arg < len(e.Args) {
+					return rp.handler(e.Args[arg])
+				}
+				return "", "", false
+			}
+		}
+		if len(e.Args) == 0 {
+			return rp.handler(e.Fun)
+		}
+		return rp.handler(lastArg(e))
+	case *ast.ParenExpr:
+		return rp.handler(e.X)
+	case *ast.UnaryExpr:
+		return rp.handler(e.X)
+	case *ast.CompositeLit:
+		if typeName := typeRefName(e.Type); typeName != "" {
+			return "ServeHTTP", typeName, true
+		}
+	case *ast.FuncLit:
+		return "", "", true
+	}
+	return "", "", false
+}
+
+// stringLit returns the value of a string literal expression.
+func stringLit(expr ast.Expr) (string, bool) {
+	lit, ok := expr.(*ast.BasicLit)
+	if !ok || lit.Kind != token.STRING {
+		return "", false
+	}
+	value, err := strconv.Unquote(lit.Value)
+	return value, err == nil
+}
+
+// lastArg returns the last argument of a call, or nil.
+func lastArg(call *ast.CallExpr) ast.Expr {
+	if len(call.Args) == 0 {
+		return nil
+	}
+	return call.Args[len(call.Args)-1]
+}
+
+// rootIdent returns the variable a selector or call chain starts from
+// (r in r.With(mw).Get).
+func rootIdent(expr ast.Expr) string {
+	for {
+		switch e := expr.(type) {
+		case *ast.Ident:
+			return e.Name
+		case *ast.SelectorExpr:
+			expr = e.X
+		case *ast.CallExpr:
+			expr = e.Fun
+		case *ast.ParenExpr:
+			expr = e.X
+		default:
+			return ""
+		}
+	}
+}
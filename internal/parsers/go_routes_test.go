@@ -0,0 +1,183 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// routesByName indexes the routes of a parse result by "METHOD path".
+func routesByName(result *ParseResult) map[string]Route {
+	routes := make(map[string]Route, len(result.Routes))
+	for _, route := range result.Routes {
+		routes[route.Method+" "+route.Path] = route
+	}
+	return routes
+}
+
+func TestGoParser_Routes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("NetHTTP", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`package main
+
+import "net/http"
+
+type UserHandler struct{}
+
+func (h *UserHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {}
+
+func (h *UserHandler) routes(mux *http.ServeMux) {
+	mux.HandleFunc("POST /users", h.create)
+}
+
+func (h *UserHandler) create(w http.ResponseWriter, r *http.Request) {}
+
+func health(w http.ResponseWriter, r *http.Request) {}
+
+func main() {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /users/{id}", getUser)
+	mux.Handle("/users/", &UserHandler{})
+	mux.Handle("/healthz", http.HandlerFunc(health))
+	mux.HandleFunc("example.com/{$}", func(w http.ResponseWriter, r *http.Request) {})
+	mux.Handle("/static/", http.StripPrefix("/static/", http.FileServer(http.Dir("."))))
+	mux.Handle("/old", http.RedirectHandler("/new", http.StatusMovedPermanently))
+	http.HandleFunc(prefix+"/dynamic", health)
+}
+`)
+		result, err := NewGoParser().Parse("main.go", content)
+		require.NoError(t, err)
+		routes := routesByName(result)
+
+		getUser := routes["GET /users/{id}"]
+		assert.Equal(t, Route{Method: "GET", Path: "/users/{id}", Handler: "getUser", Framework: "net/http", StartLine: 19, Enclosing: "main"}, getUser)
+
+		create := routes["POST /users"]
+		assert.Equal(t, "create", create.Handler)
+		assert.Equal(t, "UserHandler", create.Receiver, "receiver variables resolve to their type")
+		assert.Equal(t, "UserHandler", create.EnclosingClass)
+
+		assert.Equal(t, "ServeHTTP", routes["ANY /users/"].Handler, "handler values serve through ServeHTTP")
+		assert.Equal(t, "UserHandler", routes["ANY /users/"].Receiver)
+		assert.Equal(t, "health", routes["ANY /healthz"].Handler, "HandlerFunc conversions")
+		assert.Equal(t, "", routes["ANY /{$}"].Handler, "inline handlers and host patterns")
+		assert.False(t, routes["ANY /{$}"].NoHandler, "inline handlers are attributed to the enclosing function")
+		assert.True(t, routes["ANY /static/"].NoHandler, "handlers of the standard library")
+		assert.True(t, routes["ANY /old"].NoHandler)
+		assert.Len(t, result.Routes, 7, "paths must be literals")
+	})
+
+	t.Run("Chi", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`package api
+
+import "github.com/go-chi/chi/v5"
+
+func Routes(h *Handler) chi.Router {
+	r := chi.NewRouter()
+	r.Get("/", h.Index)
+	r.Route("/users", func(r chi.Router) {
+		r.With(auth).Post("/", h.CreateUser)
+		r.Route("/{id}", func(r chi.Router) {
+			r.Get("/", h.GetUser)
+			r.Method("DELETE", "/", h.DeleteUser)
+		})
+	})
+	r.Group(func(r chi.Router) {
+		r.Put("/settings", updateSettings)
+	})
+	r.Handle("/metrics", promhttp.Handler())
+	return r
+}
+`)
+		result, err := NewGoParser().Parse("api/routes.go", content)
+		require.NoError(t, err)
+		routes := routesByName(result)
+
+		assert.Equal(t, "Index", routes["GET /"].Handler)
+		assert.Equal(t, "chi", routes["GET /"].Framework)
+		assert.Equal(t, "CreateUser", routes["POST /users/"].Handler, "Route blocks prefix their routes")
+		assert.Equal(t, "h", routes["POST /users/"].Receiver)
+		assert.Equal(t, "GetUser", routes["GET /users/{id}/"].Handler)
+		assert.Equal(t, "DeleteUser", routes["DELETE /users/{id}/"].Handler)
+		assert.Equal(t, "updateSettings", routes["PUT /settings"].Handler)
+		assert.Equal(t, "Handler", routes["ANY /metrics"].Handler, "handler factories")
+		assert.Equal(t, "chi", routes["ANY /metrics"].Framework)
+		assert.Len(t, result.Routes, 6)
+	})
+
+	t.Run("Gin", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`package main
+
+import "github.com/gin-gonic/gin"
+
+func main() {
+	r := gin.Default()
+	r.GET("/ping", ping)
+	v1 := r.Group("/v1")
+	users := v1.Group("/users")
+	users.POST("", authRequired(), createUser)
+	users.GET("/:id", getUser)
+	r.Any("/proxy/*path", proxy)
+	r.Handle("PATCH", "/v1/users/:id", patchUser)
+}
+`)
+		result, err := NewGoParser().Parse("main.go", content)
+		require.NoError(t, err)
+		routes := routesByName(result)
+
+		assert.Equal(t, "ping", routes["GET /ping"].Handler)
+		assert.Equal(t, "gin", routes["GET /ping"].Framework)
+		assert.Equal(t, "createUser", routes["POST /v1/users"].Handler, "groups prefix their routes, middleware comes first")
+		assert.Equal(t, "getUser", routes["GET /v1/users/:id"].Handler)
+		assert.Equal(t, "proxy", routes["ANY /proxy/*path"].Handler)
+		assert.Equal(t, "patchUser", routes["PATCH /v1/users/:id"].Handler)
+		assert.Len(t, result.Routes, 5)
+	})
+
+	t.Run("Echo", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`package main
+
+import "github.com/labstack/echo/v4"
+
+func main() {
+	e := echo.New()
+	api := e.Group("/api")
+	api.GET("/users/:id", getUser, middleware.Logger())
+	e.Add("DELETE", "/users/:id", deleteUser)
+}
+`)
+		result, err := NewGoParser().Parse("main.go", content)
+		require.NoError(t, err)
+		routes := routesByName(result)
+
+		assert.Equal(t, "getUser", routes["GET /api/users/:id"].Handler, "middleware follows the handler")
+		assert.Equal(t, "echo", routes["GET /api/users/:id"].Framework)
+		assert.Equal(t, "deleteUser", routes["DELETE /users/:id"].Handler)
+		assert.Len(t, result.Routes, 2)
+	})
+
+	t.Run("NoRoutes", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`package cache
+
+func Warm(c *Cache) {
+	c.Get("/key")
+	c.GET("/key", nil)
+}
+`)
+		result, err := NewGoParser().Parse("cache.go", content)
+		require.NoError(t, err)
+		assert.Empty(t, result.Routes, "router methods count only where a router is imported")
+	})
+}
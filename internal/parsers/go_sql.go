@@ -0,0 +1,124 @@
+package parsers
+
+import (
+	"go/ast"
+	"go/token"
+)
+
+// parseSQL records the tables that the SQL string literals of the file
+// create, read and write. A query is attributed to the function it appears
+// in. Queries declared as package-level constants or variables, as sqlc
+// generates them and as hand-written data access code often declares
+// them, are attributed to the functions of the file that use them, or to
+// the file when none does.
+func (p *GoParser) parseSQL(file *ast.File, fset *token.FileSet, result *ParseResult) {
+	named := make(map[string][]TableRef)
+	declared := make(map[ast.Expr]bool)
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok || gen.Tok != token.CONST && gen.Tok != token.VAR {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			valueSpec := spec.(*ast.ValueSpec)
+			for i, value := range valueSpec.Values {
+				if i >= len(valueSpec.Names) {
+					break
+				}
+				tables, refs := p.sqlLiteral(value, fset)
+				if tables == nil && refs == nil {
+					continue
+				}
+				declared[value] = true
+				result.Tables = append(result.Tables, tables...)
+				if len(refs) > 0 {
+					named[valueSpec.Names[i].Name] = refs
+				}
+			}
+		}
+	}
+
+	used := make(map[string]bool)
+	for _, decl := range file.Decls {
+		enclosing, enclosingClass := declName(decl)
+		attribute := func(refs []TableRef, line int) {
+			for _, ref := range refs {
+				ref.Enclosing, ref.EnclosingClass = enclosing, enclosingClass
+				if line > 0 {
+					ref.StartLine = line
+				}
+				result.TableRefs = append(result.TableRefs, ref)
+			}
+		}
+
+		ast.Inspect(decl, func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.BasicLit, *ast.BinaryExpr:
+				expr := n.(ast.Expr)
+				if declared[expr] {
+					return false
+				}
+				if _, ok := goStringValue(expr); !ok {
+					return true
+				}
+				tables, refs := p.sqlLiteral(expr, fset)
+				result.Tables = append(result.Tables, tables...)
+				attribute(refs, 0)
+				return false
+			case *ast.Ident:
+				if refs, ok := named[n.Name]; ok && enclosing != "" {
+					used[n.Name] = true
+					attribute(refs, fset.Position(n.Pos()).Line)
+				}
+			}
+			return true
+		})
+	}
+
+	for _, decl := range file.Decls {
+		gen, ok := decl.(*ast.GenDecl)
+		if !ok {
+			continue
+		}
+		for _, spec := range gen.Specs {
+			if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+				for _, name := range valueSpec.Names {
+					if refs, ok := named[name.Name]; ok && !used[name.Name] {
+						result.TableRefs = append(result.TableRefs, refs...)
+					}
+				}
+			}
+		}
+	}
+}
+
+// sqlLiteral analyzes a string constant expression, if it is SQL.
+func (p *GoParser) sqlLiteral(expr ast.Expr, fset *token.FileSet) ([]TableDef, []TableRef) {
+	value, ok := goStringValue(expr)
+	if !ok {
+		return nil, nil
+	}
+	return analyzeSQLLiteral(value, fset.Position(expr.Pos()).Line)
+}
+
+// goStringValue returns the value of a string literal or of a
+// concatenation of string literals.
+func goStringValue(expr ast.Expr) (string, bool) {
+	switch expr := expr.(type) {
+	case *ast.BasicLit:
+		return stringLit(expr)
+	case *ast.ParenExpr:
+		return goStringValue(expr.X)
+	case *ast.BinaryExpr:
+		if expr.Op != token.ADD {
+			return "", false
+		}
+		x, ok := goStringValue(expr.X)
+		if !ok {
+			return "", false
+		}
+		y, ok := goStringValue(expr.Y)
+		return x + y, ok
+	}
+	return "", false
+}
@@ -107,6 +107,43 @@ type Reader interface {
 		assert.True(t, hasInterface, "Should find Reader interface")
 	})
 
+	t.Run("ParseInterfaceMethods", func(t *testing.T) {
+		content := []byte(`
+package main
+
+type Store interface {
+	Get(key string) (*Item, error)
+	close() error
+}
+`)
+		result, err := parser.Parse("test.go", content)
+		require.NoError(t, err)
+
+		methods := make(map[string]ParsedSymbol)
+		for _, sym := range result.Symbols {
+			if sym.Kind == graph.NodeMethod {
+				methods[sym.Name] = sym
+			}
+		}
+
+		require.Contains(t, methods, "Get")
+		assert.Equal(t, "Store", methods["Get"].ClassName)
+		assert.Equal(t, 5, methods["Get"].StartLine)
+		assert.Equal(t, "Get(key string) (*Item, error)", methods["Get"].Signature)
+		assert.True(t, methods["Get"].IsExported)
+
+		require.Contains(t, methods, "close")
+		assert.False(t, methods["close"].IsExported)
+
+		var itemRef bool
+		for _, ref := range result.TypeRefs {
+			if ref.Name == "Item" && ref.Enclosing == "Get" && ref.EnclosingClass == "Store" {
+				itemRef = true
+			}
+		}
+		assert.True(t, itemRef, "Should attribute Item to Store.Get")
+	})
+
 	t.Run("ParseTypeAlias", func(t *testing.T) {
 		content := []byte(`
 package main
@@ -145,6 +182,41 @@ import (
 		assert.GreaterOrEqual(t, len(result.Imports), 4)
 	})
 
+	t.Run("ParseCgoPreamble", func(t *testing.T) {
+		content := []byte(`package native
+
+/*
+#cgo LDFLAGS: -lcodec
+#include <stdlib.h>
+#include "codec.h"
+*/
+import "C"
+
+// #include "ignored.h"
+import "fmt"
+
+func Encode(v int) int {
+	return int(C.codec_encode(C.int(v)))
+}
+`)
+		result, err := parser.Parse("native/native.go", content)
+		require.NoError(t, err)
+
+		var headers []ImportStatement
+		for _, imp := range result.Imports {
+			if imp.IsRelative {
+				headers = append(headers, imp)
+			}
+		}
+		require.Len(t, headers, 1, "only quoted includes of the cgo preamble")
+		assert.Equal(t, "codec.h", headers[0].ModulePath)
+		assert.Equal(t, 6, headers[0].StartLine)
+
+		call := findCall(result.Calls, "codec_encode", 14)
+		require.NotNil(t, call)
+		assert.Equal(t, "C", call.Package)
+	})
+
 	t.Run("ParseFunctionCalls", func(t *testing.T) {
 		content := []byte(`
 package main
@@ -327,3 +399,218 @@ func main() {
 	assert.True(t, hasCalls, "Should find function calls")
 	assert.Equal(t, "service", result.Package)
 }
+
+func TestGoParser_EnclosingSymbol(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(`
+package main
+
+var defaultStore = newStore()
+
+type Service struct {
+	store *Store
+}
+
+func (s *Service) Run(cfg Config) error {
+	s.store.Open()
+	return nil
+}
+
+func main() {
+	run := func() { helper() }
+	run()
+}
+`)
+	parser := NewGoParser()
+	result, err := parser.Parse("main.go", content)
+	require.NoError(t, err)
+
+	enclosing := make(map[string][2]string)
+	for _, call := range result.Calls {
+		enclosing[call.Name] = [2]string{call.Enclosing, call.EnclosingClass}
+	}
+
+	assert.Equal(t, [2]string{"", ""}, enclosing["newStore"], "package-level initializer has no enclosing symbol")
+	assert.Equal(t, [2]string{"Run", "Service"}, enclosing["Open"])
+	assert.Equal(t, [2]string{"main", ""}, enclosing["helper"], "calls in closures belong to the enclosing function")
+
+	refs := make(map[string]TypeAnnotation)
+	for _, ref := range result.TypeRefs {
+		refs[ref.Name] = ref
+	}
+	require.Contains(t, refs, "Store")
+	assert.Equal(t, "Service", refs["Store"].Enclosing)
+	assert.Equal(t, "field", refs["Store"].Role)
+	require.Contains(t, refs, "Config")
+	assert.Equal(t, "Run", refs["Config"].Enclosing)
+	assert.Equal(t, "Service", refs["Config"].EnclosingClass)
+	assert.NotContains(t, refs, "error", "predeclared types are not type references")
+}
+
+func TestGoParser_DocComments(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(`package store
+
+// Store persists records.
+//
+// It is safe for concurrent use.
+type Store struct{}
+
+// Getter reads records.
+type Getter interface {
+	// Get returns the record stored under key.
+	Get(key string) string
+}
+
+// Open opens the store at path.
+func Open(path string) *Store { return nil }
+
+func undocumented() {}
+`)
+	result, err := NewGoParser().Parse("store.go", content)
+	require.NoError(t, err)
+
+	docs := make(map[string]string)
+	for _, sym := range result.Symbols {
+		docs[sym.Name] = sym.Doc
+	}
+	assert.Equal(t, "Store persists records.\n\nIt is safe for concurrent use.", docs["Store"])
+	assert.Equal(t, "Getter reads records.", docs["Getter"])
+	assert.Equal(t, "Get returns the record stored under key.", docs["Get"])
+	assert.Equal(t, "Open opens the store at path.", docs["Open"])
+	assert.Empty(t, docs["undocumented"])
+}
+
+func TestGoParser_Values(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(`package config
+
+// Default is the default configuration.
+var Default = Config{Timeout: defaultTimeout}
+
+const defaultTimeout = 30
+
+var (
+	mu, cache = newLock(), map[string]string{}
+	_ = register()
+)
+
+type Level int
+
+const (
+	Debug Level = iota // Debug logs everything.
+	Info
+	Warn
+)
+
+const (
+	KB = 1 << (10 * (iota + 1))
+	MB
+)
+
+type Config struct {
+	// Timeout is in seconds.
+	Timeout  int
+	Name, ID string
+	*Store
+}
+`)
+	result, err := NewGoParser().Parse("config.go", content)
+	require.NoError(t, err)
+
+	symbols := make(map[string]ParsedSymbol)
+	for _, sym := range result.Symbols {
+		symbols[sym.Name] = sym
+	}
+
+	assert.Equal(t, graph.NodeVariable, symbols["Default"].Kind)
+	assert.Equal(t, "var Default", symbols["Default"].Signature)
+	assert.Equal(t, "Default is the default configuration.", symbols["Default"].Doc)
+	assert.Equal(t, "var Default = Config{Timeout: defaultTimeout}", symbols["Default"].Content)
+	assert.True(t, symbols["Default"].IsExported)
+
+	assert.Equal(t, graph.NodeConstant, symbols["defaultTimeout"].Kind)
+	assert.False(t, symbols["defaultTimeout"].IsExported)
+	assert.Equal(t, graph.NodeVariable, symbols["mu"].Kind)
+	assert.Equal(t, graph.NodeVariable, symbols["cache"].Kind)
+	assert.NotContains(t, symbols, "_")
+
+	assert.Equal(t, graph.NodeEnum, symbols["Level"].Kind, "a type enumerated by iota constants is an enum")
+	for _, name := range []string{"Debug", "Info", "Warn"} {
+		assert.Equal(t, graph.NodeConstant, symbols[name].Kind)
+		assert.Equal(t, "Level", symbols[name].ClassName)
+		assert.Equal(t, "const "+name+" Level", symbols[name].Signature)
+	}
+	assert.Equal(t, "Debug logs everything.", symbols["Debug"].Doc)
+	assert.Empty(t, symbols["KB"].ClassName, "untyped iota groups enumerate no type")
+	assert.Empty(t, symbols["MB"].ClassName)
+
+	timeout := symbols["Timeout"]
+	assert.Equal(t, graph.NodeField, timeout.Kind)
+	assert.Equal(t, "Config", timeout.ClassName)
+	assert.Equal(t, "Timeout int", timeout.Signature)
+	assert.Equal(t, "Timeout is in seconds.", timeout.Doc)
+	assert.Equal(t, "Config", symbols["ID"].ClassName)
+	assert.Equal(t, "*Store", symbols["Store"].Signature, "embedded fields are named after their type")
+	assert.Equal(t, graph.NodeField, symbols["Store"].Kind)
+}
+
+func TestGoParser_VarRefs(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(`package server
+
+import "example.com/app/config"
+
+var requests int
+
+type Server struct {
+	count int
+	name  string
+}
+
+func (s *Server) Handle(name string) {
+	requests++
+	s.count += 1
+	log(s.name, config.Default, interval)
+	s.store().Save()
+	total := requests
+	_ = total
+	cfg := config.Config{Timeout: 10}
+	config.Default = cfg
+}
+`)
+	result, err := NewGoParser().Parse("server.go", content)
+	require.NoError(t, err)
+
+	type key struct {
+		name, receiver, pkg string
+		write               bool
+	}
+	refs := make(map[key]VariableRef)
+	for _, ref := range result.VarRefs {
+		refs[key{ref.Name, ref.Receiver, ref.Package, ref.Write}] = ref
+	}
+
+	assert.Contains(t, refs, key{"requests", "", "", true})
+	assert.Contains(t, refs, key{"requests", "", "", false})
+	assert.Contains(t, refs, key{"count", "Server", "", true})
+	assert.Contains(t, refs, key{"name", "Server", "", false})
+	assert.Contains(t, refs, key{"Default", "", "example.com/app/config", false})
+	assert.Contains(t, refs, key{"Default", "", "example.com/app/config", true})
+	assert.Contains(t, refs, key{"interval", "", "", false}, "unresolved names may be declared in another file of the package")
+	assert.Contains(t, refs, key{"Timeout", "Config", "", true}, "struct literal keys write the field")
+
+	handle := refs[key{"count", "Server", "", true}]
+	assert.Equal(t, "Handle", handle.Enclosing)
+	assert.Equal(t, "Server", handle.EnclosingClass)
+	assert.Equal(t, 14, handle.StartLine)
+
+	for k := range refs {
+		assert.NotContains(t, []string{"name", "total", "cfg", "log", "store", "Save", "_"}, k.name+k.receiver,
+			"locals, parameters and called functions are not variable references")
+	}
+}
@@ -0,0 +1,342 @@
+package parsers
+
+import (
+	"go/ast"
+	"go/token"
+	"go/types"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// parseVarRefs records the references of the file's functions, and of the
+// initializers of its package-level variables and constants, to
+// package-level variables and constants and to struct fields.
+//
+// Without type information a reference is an identifier that resolves to
+// a package-level declaration of the file or does not resolve within the
+// file (a declaration of another file of the package), a package-qualified
+// name (config.Default), a field selected on the method receiver
+// (s.store), or a key of a composite literal of a named type
+// (Config{Timeout: t}), which writes the field.
+func (p *GoParser) parseVarRefs(file *ast.File, fset *token.FileSet, result *ParseResult) {
+	topLevel := make(map[*ast.ValueSpec]bool)
+	for _, decl := range file.Decls {
+		if gen, ok := decl.(*ast.GenDecl); ok {
+			for _, spec := range gen.Specs {
+				if valueSpec, ok := spec.(*ast.ValueSpec); ok {
+					topLevel[valueSpec] = true
+				}
+			}
+		}
+	}
+
+	seen := make(map[VariableRef]bool)
+	goValueScopes(file, func(node ast.Node, fn *ast.FuncDecl, enclosing, enclosingClass string) {
+		recvName, recvType := "", ""
+		if fn != nil && fn.Recv != nil && len(fn.Recv.List) > 0 && len(fn.Recv.List[0].Names) > 0 {
+			recvName, recvType = fn.Recv.List[0].Names[0].Name, receiverTypeName(fn.Recv.List[0].Type)
+		}
+		written := goWrittenIdents(node)
+
+		add := func(ref VariableRef, pos token.Pos) {
+			ref.Enclosing, ref.EnclosingClass = enclosing, enclosingClass
+			if seen[ref] {
+				return
+			}
+			seen[ref] = true
+			ref.StartLine = fset.Position(pos).Line
+			result.VarRefs = append(result.VarRefs, ref)
+		}
+
+		var visit func(n ast.Node) bool
+		visit = func(n ast.Node) bool {
+			switch n := n.(type) {
+			case *ast.CallExpr:
+				// The called function is not a variable, but its receiver
+				// may be one
+				switch fun := calleeExpr(n.Fun).(type) {
+				case *ast.Ident:
+				case *ast.SelectorExpr:
+					if x, ok := fun.X.(*ast.Ident); !ok || x.Obj != nil || result.PackageImports[x.Name] == "" {
+						ast.Inspect(fun.X, visit)
+					}
+				default:
+					ast.Inspect(n.Fun, visit)
+				}
+				for _, arg := range n.Args {
+					ast.Inspect(arg, visit)
+				}
+				return false
+
+			case *ast.SelectorExpr:
+				if x, ok := n.X.(*ast.Ident); ok {
+					if path, ok := result.PackageImports[x.Name]; ok && x.Obj == nil {
+						add(VariableRef{Name: n.Sel.Name, Package: path, Write: written[n.Sel]}, n.Pos())
+						return false
+					}
+					if recvName != "" && x.Name == recvName {
+						add(VariableRef{Name: n.Sel.Name, Receiver: recvType, Write: written[n.Sel]}, n.Pos())
+						return false
+					}
+				}
+				ast.Inspect(n.X, visit)
+				return false
+
+			case *ast.CompositeLit:
+				typeName := ""
+				switch n.Type.(type) {
+				case *ast.Ident, *ast.SelectorExpr, *ast.IndexExpr, *ast.IndexListExpr:
+					typeName = typeRefName(n.Type)
+				}
+				for _, elt := range n.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok && typeName != "" {
+						if key, ok := kv.Key.(*ast.Ident); ok {
+							add(VariableRef{Name: key.Name, Receiver: typeName, Write: true}, key.Pos())
+							ast.Inspect(kv.Value, visit)
+							continue
+						}
+					}
+					ast.Inspect(elt, visit)
+				}
+				return false
+
+			case *ast.Field:
+				// Only the type of a parameter or struct field refers to
+				// anything (array lengths)
+				ast.Inspect(n.Type, visit)
+				return false
+
+			case *ast.Ident:
+				if goPackageValue(n, topLevel) {
+					add(VariableRef{Name: n.Name, Write: written[n]}, n.Pos())
+				}
+			}
+			return true
+		}
+		ast.Inspect(node, visit)
+	})
+}
+
+// goPackageValue reports whether an identifier may refer to a package-level
+// variable or constant: it resolves to one declared in the file, or does
+// not resolve within the file and is not predeclared.
+func goPackageValue(ident *ast.Ident, topLevel map[*ast.ValueSpec]bool) bool {
+	if ident.Name == "_" {
+		return false
+	}
+	if ident.Obj == nil {
+		return types.Universe.Lookup(ident.Name) == nil
+	}
+	if ident.Obj.Kind != ast.Var && ident.Obj.Kind != ast.Con {
+		return false
+	}
+	spec, ok := ident.Obj.Decl.(*ast.ValueSpec)
+	return ok && topLevel[spec]
+}
+
+// goValueScopes calls visit with every function body of a file and every
+// initializer of its package-level variables and constants, together with
+// the symbol the code belongs to: the function, or the variable or
+// constant being initialized. fn is nil for initializers.
+func goValueScopes(file *ast.File, visit func(node ast.Node, fn *ast.FuncDecl, enclosing, enclosingClass string)) {
+	for _, decl := range file.Decls {
+		switch d := decl.(type) {
+		case *ast.FuncDecl:
+			if d.Body == nil {
+				continue
+			}
+			enclosing, enclosingClass := declName(d)
+			visit(d.Body, d, enclosing, enclosingClass)
+		case *ast.GenDecl:
+			if d.Tok != token.VAR && d.Tok != token.CONST {
+				continue
+			}
+			for _, spec := range d.Specs {
+				valueSpec, ok := spec.(*ast.ValueSpec)
+				if !ok || len(valueSpec.Names) == 0 {
+					continue
+				}
+				for i, value := range valueSpec.Values {
+					name := valueSpec.Names[min(i, len(valueSpec.Names)-1)].Name
+					if name == "_" {
+						name = ""
+					}
+					visit(value, nil, name, "")
+				}
+			}
+		}
+	}
+}
+
+// goWrittenIdents returns the identifiers naming what the statements in
+// node assign to: the left-hand sides of assignments and range clauses,
+// looking through indexing and dereferences (m[k] = v writes m), and the
+// operands of ++ and --. For a selector (s.count++) it is the selected
+// name.
+func goWrittenIdents(node ast.Node) map[*ast.Ident]bool {
+	written := make(map[*ast.Ident]bool)
+	mark := func(expr ast.Expr) {
+		for {
+			switch e := expr.(type) {
+			case *ast.ParenExpr:
+				expr = e.X
+				continue
+			case *ast.IndexExpr:
+				expr = e.X
+				continue
+			case *ast.StarExpr:
+				expr = e.X
+				continue
+			case *ast.Ident:
+				written[e] = true
+			case *ast.SelectorExpr:
+				written[e.Sel] = true
+			}
+			return
+		}
+	}
+
+	ast.Inspect(node, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.AssignStmt:
+			if n.Tok != token.DEFINE {
+				for _, lhs := range n.Lhs {
+					mark(lhs)
+				}
+			}
+		case *ast.RangeStmt:
+			if n.Tok == token.ASSIGN {
+				mark(n.Key)
+				mark(n.Value)
+			}
+		case *ast.IncDecStmt:
+			mark(n.X)
+		}
+		return true
+	})
+	return written
+}
+
+// VariableRefs returns the references of a file to package-level variables
+// and constants and to struct fields declared in the repository, resolved
+// through go/types. Keys of struct literals write the field they name.
+func (p *GoProgram) VariableRefs(relPath string) []VariableRef {
+	tf, ok := p.files[relPath]
+	if !ok {
+		return nil
+	}
+	info := tf.pkg.TypesInfo
+
+	refs := []VariableRef{}
+	seen := make(map[VariableRef]bool)
+	goValueScopes(tf.file, func(node ast.Node, _ *ast.FuncDecl, enclosing, enclosingClass string) {
+		written := goWrittenIdents(node)
+		ast.Inspect(node, func(n ast.Node) bool {
+			if lit, ok := n.(*ast.CompositeLit); ok {
+				for _, elt := range lit.Elts {
+					if kv, ok := elt.(*ast.KeyValueExpr); ok {
+						if key, ok := kv.Key.(*ast.Ident); ok {
+							if v, ok := info.Uses[key].(*types.Var); ok && v.IsField() {
+								written[key] = true
+							}
+						}
+					}
+				}
+				return true
+			}
+
+			ident, ok := n.(*ast.Ident)
+			if !ok {
+				return true
+			}
+			target := p.valueTarget(info.Uses[ident])
+			if target == nil {
+				return true
+			}
+			ref := VariableRef{
+				Name:           target.Name,
+				Receiver:       target.ClassName,
+				Write:          written[ident],
+				Enclosing:      enclosing,
+				EnclosingClass: enclosingClass,
+				Target:         target,
+			}
+			if target.Package != tf.pkg.Types.Path() {
+				ref.Package = target.Package
+			}
+			key := ref
+			key.Target = nil
+			if seen[key] {
+				return true
+			}
+			seen[key] = true
+			ref.StartLine = p.fset.Position(ident.Pos()).Line
+			refs = append(refs, ref)
+			return true
+		})
+	})
+	return refs
+}
+
+// valueTarget describes the declaration of a package-level variable or
+// constant, or of a field of a named struct type, declared in the
+// repository. It returns nil for any other object.
+func (p *GoProgram) valueTarget(obj types.Object) *CallTarget {
+	if obj == nil || obj.Pkg() == nil || obj.Name() == "_" {
+		return nil
+	}
+
+	target := &CallTarget{Name: obj.Name(), Package: obj.Pkg().Path()}
+	switch obj := obj.(type) {
+	case *types.Const:
+		if obj.Parent() != obj.Pkg().Scope() {
+			return nil
+		}
+		target.Kind = graph.NodeConstant
+	case *types.Var:
+		switch {
+		case obj.IsField():
+			target.Kind = graph.NodeField
+			target.ClassName = p.fieldOwners[obj.Origin()]
+			if target.ClassName == "" {
+				return nil
+			}
+		case obj.Parent() == obj.Pkg().Scope():
+			target.Kind = graph.NodeVariable
+		default:
+			return nil
+		}
+	default:
+		return nil
+	}
+
+	pos := p.fset.Position(obj.Pos())
+	target.FilePath = p.relPath(pos.Filename)
+	if target.FilePath == "" {
+		return nil
+	}
+	target.Line = pos.Line
+	return target
+}
+
+// indexFieldOwners records the named struct type declaring each field of
+// the repository's packages.
+func (p *GoProgram) indexFieldOwners() {
+	p.fieldOwners = make(map[*types.Var]string)
+	for _, pkg := range p.pkgs {
+		scope := pkg.Types.Scope()
+		for _, name := range scope.Names() {
+			typeName, ok := scope.Lookup(name).(*types.TypeName)
+			if !ok || typeName.IsAlias() {
+				continue
+			}
+			st, ok := typeName.Type().Underlying().(*types.Struct)
+			if !ok {
+				continue
+			}
+			for i := range st.NumFields() {
+				p.fieldOwners[st.Field(i)] = name
+			}
+		}
+	}
+}
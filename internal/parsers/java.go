@@ -0,0 +1,371 @@
+package parsers
+
+import (
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// JavaParser parses Java source code.
+//
+// The source is tokenized (jvm_lexer.go) and parsed declaration by
+// declaration, following the brace structure of classes. Classes, records,
+// interfaces (including annotation types) and enums are symbols, as are
+// their methods and constructors, whose bodies are scanned for calls.
+// Nested classes are symbols of their own; anonymous and local classes
+// belong to the method they appear in. Annotations are recorded as
+// decorators.
+type JavaParser struct{}
+
+// NewJavaParser creates a new Java parser.
+func NewJavaParser() *JavaParser {
+	return &JavaParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *JavaParser) Language() string {
+	return "java"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *JavaParser) SupportsFile(filename string) bool {
+	return strings.HasSuffix(filename, ".java")
+}
+
+// Parse parses Java source code and extracts symbols, imports, calls, etc.
+func (p *JavaParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	jp := &javaParser{jvParser: newJVMParser(string(content), jvJava)}
+	jp.members(len(jp.toks), jvScope{})
+	jp.finish()
+	setDocComments(jp.result, string(content), slashDocComments)
+	return jp.result, nil
+}
+
+// javaModifiers are the modifiers of Java declarations.
+var javaModifiers = map[string]bool{
+	"public": true, "protected": true, "private": true, "static": true,
+	"final": true, "abstract": true, "native": true, "synchronized": true,
+	"transient": true, "volatile": true, "strictfp": true, "default": true,
+	"sealed": true,
+}
+
+// javaParser parses the token stream of a Java file.
+type javaParser struct {
+	*jvParser
+}
+
+// members parses the declarations from the current position to the token
+// at index end, which closes their class body (or the end of the file).
+func (p *javaParser) members(end int, scope jvScope) {
+	for p.i < end && p.i < len(p.toks) {
+		if p.is(p.i, ";") {
+			p.i++
+			continue
+		}
+		annotations := p.annotations()
+		if p.i >= end {
+			return
+		}
+		start := p.i
+		public := scope.iface
+		for {
+			if p.is(p.i, "@") && !p.is(p.i+1, "interface") {
+				annotations = append(annotations, p.annotations()...)
+				continue
+			}
+			name := p.ident(p.i)
+			switch {
+			case javaModifiers[name]:
+				if name == "public" {
+					public = true
+				}
+				if name == "private" {
+					public = false
+				}
+				p.i++
+				continue
+			case name == "non" && p.is(p.i+1, "-") && p.is(p.i+2, "sealed"):
+				p.i += 3
+				continue
+			}
+			break
+		}
+
+		switch {
+		case p.is(p.i, "package") && scope.class == "":
+			p.result.Package, _ = p.qualifiedName(p.i + 1)
+			p.skipStatement()
+		case p.is(p.i, "import") && scope.class == "":
+			p.importDeclaration()
+		case p.is(p.i, "class") || p.is(p.i, "interface") || p.is(p.i, "enum") ||
+			p.is(p.i, "@") && p.is(p.i+1, "interface") ||
+			p.is(p.i, "record") && p.ident(p.i+1) != "" && (p.is(p.i+2, "(") || p.is(p.i+2, "<")):
+			p.typeDeclaration(start, public, annotations, scope)
+		case p.is(p.i, "{"):
+			// Initializer blocks belong to their class
+			closeBrace := p.closing(p.i)
+			p.calls(p.i, closeBrace, "", scope.class, nil)
+			p.i = closeBrace + 1
+		case p.is(p.i, "}"):
+			// A stray closing brace: leave it to the enclosing block
+			p.i++
+		default:
+			p.member(start, public, annotations, scope)
+		}
+	}
+}
+
+// importDeclaration parses import [static] a.b.C; or import a.b.*;. Static
+// imports refer to members of a class: import static a.b.C.run has module
+// path a.b.C.
+func (p *javaParser) importDeclaration() {
+	line := p.tok(p.i).line
+	j := p.i + 1
+	if p.is(j, "static") {
+		j++
+	}
+	name, end := p.qualifiedName(j)
+	symbol := ""
+	if p.is(end, ".") && p.is(end+1, "*") {
+		symbol = "*"
+	} else if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		name, symbol = name[:dot], name[dot+1:]
+	}
+	if name != "" && symbol != "" {
+		p.result.Imports = append(p.result.Imports, ImportStatement{
+			ModulePath: name,
+			Symbols:    []string{symbol},
+			StartLine:  line,
+		})
+	}
+	p.skipStatement()
+}
+
+// typeDeclaration parses the class, interface, enum, record or annotation
+// type whose keyword is at the current position.
+func (p *javaParser) typeDeclaration(start int, public bool, annotations []string, scope jvScope) {
+	keyword := p.i
+	kind := graph.NodeClass
+	iface := false
+	switch {
+	case p.is(keyword, "@"):
+		keyword++
+		kind, iface = graph.NodeInterface, true
+	case p.is(keyword, "interface"):
+		kind, iface = graph.NodeInterface, true
+	case p.is(keyword, "enum"):
+		kind = graph.NodeEnum
+	}
+	name := p.ident(keyword + 1)
+	if name == "" {
+		p.i = keyword + 1
+		return
+	}
+
+	// The header runs to the body: type parameters, record components,
+	// extends, implements and permits clauses
+	bodyOpen := keyword + 2
+	for bodyOpen < len(p.toks) && !p.is(bodyOpen, "{") && !p.is(bodyOpen, ";") {
+		if p.is(bodyOpen, "(") || p.is(bodyOpen, "[") {
+			bodyOpen = p.closing(bodyOpen)
+		}
+		bodyOpen++
+	}
+	last := p.closing(bodyOpen)
+	if !p.is(bodyOpen, "{") {
+		last = bodyOpen
+	}
+
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       kind,
+		Signature:  p.text(keyword, bodyOpen-1),
+		IsExported: public,
+		Decorators: annotations,
+	}, start, last)
+
+	p.header(keyword+2, bodyOpen, name, iface)
+	p.i = bodyOpen + 1
+	if !p.is(bodyOpen, "{") {
+		return
+	}
+	if kind == graph.NodeEnum {
+		p.enumConstants(last, name)
+	}
+	p.members(last, jvScope{class: name, iface: iface})
+	p.i = last + 1
+}
+
+// header records the heritage and record components of the class name,
+// declared between the tokens at indices from and to.
+func (p *javaParser) header(from, to int, name string, iface bool) {
+	j := from
+	if p.is(j, "<") {
+		if end := p.angleEnd(j); end > 0 {
+			j = end + 1
+		}
+	}
+	if p.is(j, "(") {
+		// Record components are the fields of the record
+		closeParen := p.closing(j)
+		for _, item := range p.list(j, closeParen) {
+			p.component(item[0], item[1], name)
+		}
+		j = closeParen + 1
+	}
+
+	// Each clause runs to the next one or the body
+	var extends, implements []string
+	for j < to {
+		clause := p.ident(j)
+		end := j + 1
+		for end < to && !p.is(end, "extends") && !p.is(end, "implements") && !p.is(end, "permits") {
+			if p.is(end, "<") {
+				// Wildcards: Comparable<? extends Entity>
+				if closeAngle := p.angleEnd(end); closeAngle > 0 {
+					end = closeAngle
+				}
+			}
+			end++
+		}
+		classes, interfaces := p.supertypes(j+1, end)
+		switch clause {
+		case "extends":
+			extends = append(extends, append(classes, interfaces...)...)
+		case "implements":
+			implements = append(implements, append(classes, interfaces...)...)
+		}
+		j = end
+	}
+	if iface {
+		// Interfaces extend interfaces
+		implements = nil
+	}
+	p.addHeritage(name, extends, implements)
+}
+
+// component records the record component or parameter between the tokens
+// at indices from and to, exclusive, as a field of class.
+func (p *javaParser) component(from, to int, class string) {
+	for p.is(from, "@") {
+		_, end := p.qualifiedName(from + 1)
+		from = end
+		if p.is(from, "(") {
+			from = p.closing(from) + 1
+		}
+	}
+	nameIndex := to - 1
+	if p.ident(nameIndex) == "" {
+		return
+	}
+	typ := p.typeRefs(from, nameIndex, "field", class, "")
+	p.addField(class, p.ident(nameIndex), typ)
+}
+
+// enumConstants skips the constants of the enum whose body closes at index
+// last; calls in their arguments and bodies belong to the enum.
+func (p *javaParser) enumConstants(last int, enum string) {
+	for j := p.i; j < last; j++ {
+		switch {
+		case p.is(j, "(") || p.is(j, "{") || p.is(j, "["):
+			closeBracket := p.closing(j)
+			p.calls(j, closeBracket, "", enum, nil)
+			j = closeBracket
+		case p.is(j, ";"):
+			p.i = j + 1
+			return
+		}
+	}
+	p.i = last
+}
+
+// member parses the method, constructor or field declared at the current
+// position in the body of scope.class.
+func (p *javaParser) member(start int, public bool, annotations []string, scope jvScope) {
+	typeStart := p.i
+	if p.is(p.i, "<") {
+		// Generic methods: <T> T first(List<T> items)
+		if end := p.angleEnd(p.i); end > 0 {
+			typeStart = end + 1
+		}
+	}
+
+	// Find what follows the name: parameters, an initializer or the end
+	j := typeStart
+	for j < len(p.toks) {
+		if p.is(j, "<") {
+			if end := p.angleEnd(j); end > 0 {
+				j = end + 1
+				continue
+			}
+		}
+		if p.is(j, "(") || p.is(j, "=") || p.is(j, ";") || p.is(j, "{") || p.is(j, "}") || p.is(j, ",") {
+			break
+		}
+		if p.is(j, "[") {
+			j = p.closing(j)
+		}
+		j++
+	}
+	name := p.ident(j - 1)
+
+	switch {
+	case name != "" && p.is(j, "("):
+		p.method(start, typeStart, j, public, annotations, scope)
+	case name != "" && p.is(j, "{") && name == scope.class && j-1 == typeStart:
+		// Compact record constructors: Point { ... }
+		closeBrace := p.closing(j)
+		p.define(ParsedSymbol{
+			Name:       name,
+			Kind:       graph.NodeMethod,
+			Signature:  name,
+			ClassName:  scope.class,
+			IsExported: public,
+			Decorators: annotations,
+		}, start, closeBrace)
+		p.calls(j, closeBrace, name, scope.class, nil)
+		p.i = closeBrace + 1
+	case name != "" && (p.is(j, "=") || p.is(j, ";") || p.is(j, ",")):
+		p.field(typeStart, j, scope)
+	default:
+		p.skipStatement()
+	}
+}
+
+// method parses the method or constructor whose parameters open at index
+// open; its return type starts at index typeStart.
+func (p *javaParser) method(start, typeStart, open int, public bool, annotations []string, scope jvScope) {
+	name := p.ident(open - 1)
+	closeParen := p.closing(open)
+
+	// throws clauses and annotation defaults run to the body or the ';'
+	bodyOpen := closeParen + 1
+	for bodyOpen < len(p.toks) && !p.is(bodyOpen, "{") && !p.is(bodyOpen, ";") && !p.is(bodyOpen, "}") {
+		if p.is(bodyOpen, "(") || p.is(bodyOpen, "[") {
+			bodyOpen = p.closing(bodyOpen)
+		}
+		bodyOpen++
+	}
+	last := bodyOpen
+	if p.is(bodyOpen, "{") {
+		last = p.closing(bodyOpen)
+	}
+
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeMethod,
+		Signature:  p.text(typeStart, closeParen),
+		ClassName:  scope.class,
+		IsExported: public,
+		Decorators: annotations,
+	}, start, last)
+
+	if typeStart < open-1 {
+		p.typeRefs(typeStart, open-1, "return", name, scope.class)
+	}
+	vars := p.parameters(open, closeParen, name, scope.class)
+	if p.is(bodyOpen, "{") {
+		p.calls(bodyOpen, last, name, scope.class, vars)
+	}
+	p.i = last + 1
+}
@@ -0,0 +1,202 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestJavaParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ParseClass", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`package com.example.users;
+
+import java.util.Optional;
+import static java.util.Objects.requireNonNull;
+import com.example.repo.*;
+
+/** Looks up users. */
+@Service
+public class UserService extends BaseService implements Lookup<User>, Comparable<? extends Entity> {
+    private static final Logger log = LoggerFactory.getLogger(UserService.class);
+    private final UserRepository repository;
+
+    public UserService(UserRepository repository) {
+        this.repository = requireNonNull(repository);
+    }
+
+    @Override
+    @GetMapping("/users/{id}")
+    public Optional<User> find(@PathVariable String id) {
+        User user = repository.findById(id);
+        log.info("found {}", id);
+        return Optional.ofNullable(user);
+    }
+
+    private void notifyAll(List<User> users) {
+        users.forEach(this::notify);
+        new Thread(new Runnable() {
+            public void run() { refresh(); }
+        }).start();
+    }
+
+    void refresh() {}
+}
+`)
+		result, err := NewJavaParser().Parse("UserService.java", content)
+		require.NoError(t, err)
+		assert.Equal(t, "com.example.users", result.Package)
+
+		symbols := symbolsByName(result)
+		service := symbols["UserService"]
+		assert.Equal(t, graph.NodeClass, service.Kind)
+		assert.True(t, service.IsExported)
+		assert.Equal(t, []string{"Service"}, service.Decorators)
+		assert.Equal(t, 9, service.StartLine)
+		assert.Equal(t, 33, service.EndLine)
+
+		require.Contains(t, symbols, "UserService.UserService")
+		assert.Equal(t, "UserService", symbols["UserService.UserService"].ClassName, "constructors are methods named after their class")
+
+		find := symbols["UserService.find"]
+		assert.Equal(t, graph.NodeMethod, find.Kind)
+		assert.Equal(t, "UserService", find.ClassName)
+		assert.Equal(t, []string{"Override", "GetMapping"}, find.Decorators)
+		assert.Equal(t, "Optional<User> find(@PathVariable String id)", find.Signature)
+		assert.True(t, find.IsExported)
+		assert.False(t, symbols["UserService.notifyAll"].IsExported)
+		assert.False(t, symbols["UserService.refresh"].IsExported, "package-private members are not exported")
+		assert.NotContains(t, symbols, "UserService.run", "methods of anonymous classes are not members")
+
+		require.Len(t, result.Heritage, 1)
+		assert.Equal(t, []string{"BaseService"}, result.Heritage[0].Extends)
+		assert.Equal(t, []string{"Lookup", "Comparable"}, result.Heritage[0].Implements)
+
+		findByID := findCall(result.Calls, "findById", 20)
+		require.NotNil(t, findByID)
+		assert.Equal(t, "UserRepository", findByID.Receiver, "field receivers resolve to the field type")
+		assert.Equal(t, "find", findByID.Enclosing)
+
+		info := findCall(result.Calls, "info", 21)
+		require.NotNil(t, info)
+		assert.Equal(t, "Logger", info.Receiver)
+
+		notify := findCall(result.Calls, "notify", 26)
+		require.NotNil(t, notify)
+		assert.Equal(t, "UserService", notify.Receiver, "method references to this")
+
+		refresh := findCall(result.Calls, "refresh", 28)
+		require.NotNil(t, refresh)
+		assert.Equal(t, "notifyAll", refresh.Enclosing)
+		assert.Nil(t, findCall(result.Calls, "run", 28))
+	})
+
+	t.Run("ParseImports", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`package app;
+
+import java.util.List;
+import static org.junit.Assert.assertEquals;
+import com.example.model.*;
+`)
+		result, err := NewJavaParser().Parse("App.java", content)
+		require.NoError(t, err)
+
+		imports := importsByModule(result)
+		assert.Equal(t, []string{"List"}, imports["java.util"])
+		assert.Equal(t, []string{"assertEquals"}, imports["org.junit.Assert"], "static imports name members of a class")
+		assert.Equal(t, []string{"*"}, imports["com.example.model"])
+	})
+
+	t.Run("ParseInterfacesEnumsRecords", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`public interface Repository<T> extends Closeable, AutoCloseable {
+    T find(String id);
+
+    default void close() {
+        flush();
+    }
+}
+
+enum Status implements Labeled {
+    ACTIVE("a") {
+        String label() { return "active"; }
+    },
+    INACTIVE("i");
+
+    private final String code;
+
+    Status(String code) { this.code = code; }
+}
+
+public record Point(int x, Coordinate y) implements Shape {
+    public double length() { return Math.sqrt(x * x); }
+}
+
+@interface Audited {}
+`)
+		result, err := NewJavaParser().Parse("Types.java", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Equal(t, graph.NodeInterface, symbols["Repository"].Kind)
+		assert.True(t, symbols["Repository.find"].IsExported, "interface methods are public")
+		assert.Equal(t, graph.NodeEnum, symbols["Status"].Kind)
+		assert.False(t, symbols["Status"].IsExported)
+		assert.Contains(t, symbols, "Status.Status")
+		assert.NotContains(t, symbols, "Status.label", "bodies of enum constants are not members")
+		assert.Equal(t, graph.NodeClass, symbols["Point"].Kind)
+		assert.Contains(t, symbols, "Point.length")
+		assert.Equal(t, graph.NodeInterface, symbols["Audited"].Kind)
+
+		heritage := make(map[string]ClassHeritage)
+		for _, h := range result.Heritage {
+			heritage[h.ClassName] = h
+		}
+		assert.Equal(t, []string{"Closeable", "AutoCloseable"}, heritage["Repository"].Extends)
+		assert.Empty(t, heritage["Repository"].Implements)
+		assert.Equal(t, []string{"Labeled"}, heritage["Status"].Implements)
+		assert.Equal(t, []string{"Shape"}, heritage["Point"].Implements)
+
+		var fields []string
+		for _, ref := range result.TypeRefs {
+			if ref.Role == "field" {
+				fields = append(fields, ref.Name)
+			}
+		}
+		assert.Equal(t, []string{"Coordinate"}, fields, "record components are fields")
+	})
+
+	t.Run("ParseEmptyFile", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := NewJavaParser().Parse("Empty.java", []byte(""))
+		require.NoError(t, err)
+		assert.Empty(t, result.Symbols)
+		assert.Empty(t, result.Calls)
+	})
+}
+
+func TestJavaParser_Language(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "java", NewJavaParser().Language())
+}
+
+func TestJavaParser_FileDetection(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJavaParser()
+	assert.True(t, parser.SupportsFile("App.java"))
+	assert.True(t, parser.SupportsFile("src/main/java/com/example/App.java"))
+	assert.False(t, parser.SupportsFile("App.kt"))
+	assert.False(t, parser.SupportsFile("App.class"))
+}
@@ -0,0 +1,1275 @@
+package parsers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// JavaScriptParser parses JavaScript source code (.js, .jsx, .mjs, .cjs).
+//
+// Besides ES modules and classes, it understands the CommonJS idioms of
+// Node.js code: require() calls, module.exports/exports assignments, and
+// constructor functions whose methods are assigned to their prototype.
+// Rendering a JSX component (<Button />) is recorded as a call to it.
+type JavaScriptParser struct{}
+
+// NewJavaScriptParser creates a new JavaScript parser.
+func NewJavaScriptParser() *JavaScriptParser {
+	return &JavaScriptParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *JavaScriptParser) Language() string {
+	return "javascript"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *JavaScriptParser) SupportsFile(filename string) bool {
+	for _, ext := range []string{".js", ".jsx", ".mjs", ".cjs"} {
+		if strings.HasSuffix(filename, ext) {
+			return true
+		}
+	}
+	return false
+}
+
+// Parse parses JavaScript source code and extracts symbols, imports, calls, etc.
+func (p *JavaScriptParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	source := string(content)
+	js := newJSParser(source, lexJS(source, true))
+	js.parse()
+	setDocComments(js.result, source, slashDocComments)
+	return js.result, nil
+}
+
+// jsFrameKind is the kind of a brace-delimited block.
+type jsFrameKind int
+
+const (
+	jsBlock jsFrameKind = iota
+	jsFunctionBody
+	jsClassBody
+
+	// jsObjectBody is an object literal whose members are recorded: a
+	// prototype or a top-level constant (className is set), or the
+	// module.exports object
+	jsObjectBody
+)
+
+// jsFrame is an open brace-delimited block.
+type jsFrame struct {
+	kind jsFrameKind
+
+	// symbol is the index of the symbol the block is the body of, or -1
+	symbol int
+
+	// className is the class of the members of a class or object body,
+	// or the qualified name of a namespace body (TypeScript)
+	className string
+
+	// exported is set for the body of an exported object literal
+	exported bool
+
+	// parens is the parenthesis depth outside the block
+	parens int
+}
+
+// jsFunc describes a function value: a function expression or an arrow
+// function.
+type jsFunc struct {
+	params string
+
+	// returns is the declared return type (TypeScript)
+	returns string
+
+	// body is the index of the '{' token opening the body, or -1 for an
+	// arrow function with an expression body that ends with token end
+	body int
+	end  int
+}
+
+// jsParser extracts the declarations of a token stream. It walks the tokens
+// once, keeping a stack of the open blocks: declarations are recorded
+// outside function bodies, and members inside class bodies and recorded
+// object literals.
+type jsParser struct {
+	source string
+	toks   []jsToken
+	result *ParseResult
+
+	frames []jsFrame
+	parens int
+
+	// pending maps the index of a '{' token to the frame it opens
+	pending map[int]jsFrame
+
+	// starts holds the source offset each symbol starts at
+	starts []int
+
+	// skip holds the indices of identifiers that name a declaration and
+	// are therefore not calls
+	skip map[int]bool
+
+	// exports holds the names exported through export lists,
+	// "export default" and CommonJS exports
+	exports map[string]bool
+
+	// protos holds the names of constructor functions with prototype
+	// methods
+	protos map[string]bool
+
+	// thisCalls holds the indices of the calls of methods of this.field,
+	// whose receiver is the field name
+	thisCalls map[int]bool
+
+	// routers holds the names of the variables assigned an Express
+	// application or router
+	routers map[string]bool
+
+	// routeRouters holds the router each route of the result is
+	// registered on, and mounts the routers mounted with use()
+	routeRouters []string
+	mounts       []jsMount
+
+	// The type information of TypeScript sources, recorded by stripTypes
+
+	// implements maps class names to the interfaces they implement
+	implements map[string][]string
+
+	// fields maps class names to the declared types of their fields
+	fields map[string]map[string]string
+
+	// returnTypes maps the offset of the ')' closing a parameter list to
+	// the declared return type
+	returnTypes map[int]string
+
+	// decorators maps the line a decorated declaration starts on to its
+	// decorators
+	decorators map[int][]string
+
+	// namespaces maps the offset of the '{' opening a namespace body to
+	// the namespace name
+	namespaces map[int]string
+
+	// qualifiedNamespaces holds the qualified names of the namespaces
+	// opened
+	qualifiedNamespaces map[string]bool
+}
+
+func newJSParser(source string, toks []jsToken) *jsParser {
+	return &jsParser{
+		source:    source,
+		toks:      toks,
+		pending:   make(map[int]jsFrame),
+		skip:      make(map[int]bool),
+		exports:   make(map[string]bool),
+		protos:    make(map[string]bool),
+		thisCalls: make(map[int]bool),
+		routers:   make(map[string]bool),
+
+		qualifiedNamespaces: make(map[string]bool),
+		result: &ParseResult{
+			Symbols:  []ParsedSymbol{},
+			Imports:  []ImportStatement{},
+			Calls:    []CallSite{},
+			TypeRefs: []TypeAnnotation{},
+			Heritage: []ClassHeritage{},
+		},
+	}
+}
+
+// jsNotCalled are keywords that may be followed by '('.
+var jsNotCalled = map[string]bool{
+	"if": true, "for": true, "while": true, "switch": true, "catch": true,
+	"function": true, "return": true, "typeof": true, "void": true, "delete": true,
+	"await": true, "yield": true, "super": true, "import": true, "require": true,
+	"in": true, "of": true, "instanceof": true, "class": true, "with": true,
+	"new": true, "case": true, "throw": true, "else": true, "do": true,
+}
+
+// jsComponentWrappers are calls that wrap a component function without
+// changing what it is.
+var jsComponentWrappers = map[string]bool{
+	"memo": true, "forwardRef": true,
+}
+
+func (p *jsParser) parse() {
+	for i := 0; i < len(p.toks); i++ {
+		t := p.toks[i]
+		switch t.kind {
+		case jsPunct:
+			switch t.text {
+			case "{":
+				p.open(i)
+			case "}":
+				p.close(i)
+			case "(", "[":
+				p.parens++
+			case ")", "]":
+				p.parens--
+			case "=>":
+				p.anonymousFunction(i)
+			}
+		case jsJSX:
+			p.jsxCall(t)
+		case jsString, jsTemplate:
+			p.sql(t)
+		case jsIdent:
+			p.imports(i)
+			p.declaration(i)
+			p.call(i)
+			p.routerAssignment(i)
+			p.route(i)
+		}
+	}
+
+	// Close the blocks left open by a truncated file
+	for len(p.frames) > 0 {
+		p.close(len(p.toks) - 1)
+	}
+	p.finish()
+}
+
+// tok returns the token at index i, or an EOF token out of range.
+func (p *jsParser) tok(i int) jsToken {
+	if i < 0 || i >= len(p.toks) {
+		return jsToken{kind: jsEOF}
+	}
+	return p.toks[i]
+}
+
+// is reports whether the token at index i is the identifier or punctuator text.
+func (p *jsParser) is(i int, text string) bool {
+	t := p.tok(i)
+	return (t.kind == jsIdent || t.kind == jsPunct) && t.text == text
+}
+
+// ident returns the identifier at index i, or "".
+func (p *jsParser) ident(i int) string {
+	if t := p.tok(i); t.kind == jsIdent {
+		return t.text
+	}
+	return ""
+}
+
+// matching returns the index of the bracket closing the one at index i.
+func (p *jsParser) matching(i int) int {
+	depth := 0
+	for j := i; j < len(p.toks); j++ {
+		t := p.toks[j]
+		if t.kind != jsPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return len(p.toks) - 1
+}
+
+// matchingOpen returns the index of the bracket opening the one closed at
+// index i.
+func (p *jsParser) matchingOpen(i int) int {
+	depth := 0
+	for j := i; j >= 0; j-- {
+		t := p.toks[j]
+		if t.kind != jsPunct {
+			continue
+		}
+		switch t.text {
+		case ")", "]", "}":
+			depth++
+		case "(", "[", "{":
+			depth--
+			if depth == 0 {
+				return j
+			}
+		}
+	}
+	return 0
+}
+
+// text returns the source between the tokens at indices i and j, exclusive.
+// An index j past the last token stands for the end of the source.
+func (p *jsParser) text(i, j int) string {
+	end := len(p.source)
+	if j < len(p.toks) {
+		end = p.toks[j].pos
+	}
+	return strings.Join(strings.Fields(p.source[p.toks[i].end:end]), " ")
+}
+
+func (p *jsParser) open(i int) {
+	frame, ok := p.pending[i]
+	if !ok {
+		frame = jsFrame{kind: jsBlock, symbol: -1}
+		if name, ok := p.namespaces[p.toks[i].pos]; ok {
+			frame.className = qualifiedName(p.namespace(), name)
+			p.qualifiedNamespaces[frame.className] = true
+		}
+	}
+	frame.parens = p.parens
+	p.frames = append(p.frames, frame)
+}
+
+func (p *jsParser) close(i int) {
+	if len(p.frames) == 0 {
+		return
+	}
+	frame := p.frames[len(p.frames)-1]
+	p.frames = p.frames[:len(p.frames)-1]
+	if frame.symbol >= 0 {
+		p.end(frame.symbol, i)
+	}
+}
+
+// top returns the innermost open block, or nil at the top level.
+func (p *jsParser) top() *jsFrame {
+	if len(p.frames) == 0 {
+		return nil
+	}
+	return &p.frames[len(p.frames)-1]
+}
+
+// namespace returns the namespace whose body the current position is
+// directly in, or "".
+func (p *jsParser) namespace() string {
+	if frame := p.top(); frame != nil && frame.kind == jsBlock {
+		return frame.className
+	}
+	return ""
+}
+
+// qualifiedName returns name qualified by the namespace or object it is
+// declared in, if any.
+func qualifiedName(qualifier, name string) string {
+	if qualifier == "" {
+		return name
+	}
+	return qualifier + "." + name
+}
+
+// inFunction reports whether the current position is inside a function body.
+func (p *jsParser) inFunction() bool {
+	for _, frame := range p.frames {
+		if frame.kind == jsFunctionBody {
+			return true
+		}
+	}
+	return false
+}
+
+// statementStart reports whether the token at index i starts a statement.
+func (p *jsParser) statementStart(i int) bool {
+	prev := p.tok(i - 1)
+	switch prev.kind {
+	case jsEOF:
+		return true
+	case jsPunct:
+		if prev.text == ";" || prev.text == "{" || prev.text == "}" {
+			return true
+		}
+		return p.toks[i].nl && (prev.text == ")" || prev.text == "]")
+	case jsIdent:
+		if prev.text == "export" || prev.text == "default" && p.is(i-2, "export") {
+			return true
+		}
+		return p.toks[i].nl && !jsExpressionKeywords[prev.text]
+	default:
+		return p.toks[i].nl
+	}
+}
+
+// statementEnd reports whether the token at index i ends a statement, or
+// starts the next one.
+func (p *jsParser) statementEnd(i int) bool {
+	t := p.tok(i)
+	return t.kind == jsEOF || t.nl || p.is(i, ";") || p.is(i, "}")
+}
+
+// exported reports whether the declaration starting at index i is preceded
+// by "export" or "export default", and returns the index of the first
+// token of the statement.
+func (p *jsParser) exported(i int) (bool, int) {
+	if p.is(i-1, "default") && p.is(i-2, "export") {
+		return true, i - 2
+	}
+	if p.is(i-1, "export") {
+		return true, i - 1
+	}
+	return false, i
+}
+
+// funcValue parses the function value starting at index j.
+func (p *jsParser) funcValue(j int) (jsFunc, bool) {
+	if p.is(j, "async") && (p.is(j+1, "function") || p.is(j+1, "(") || p.ident(j+1) != "" && p.is(j+2, "=>")) {
+		j++
+	}
+
+	switch {
+	case p.is(j, "function"):
+		j++
+		if p.is(j, "*") {
+			j++
+		}
+		if p.ident(j) != "" {
+			p.skip[j] = true
+			j++
+		}
+		if !p.is(j, "(") {
+			return jsFunc{}, false
+		}
+		closeParen := p.matching(j)
+		if !p.is(closeParen+1, "{") {
+			return jsFunc{}, false
+		}
+		return p.function(j, closeParen, closeParen+1), true
+
+	case p.is(j, "("):
+		closeParen := p.matching(j)
+		if !p.is(closeParen+1, "=>") {
+			return jsFunc{}, false
+		}
+		fn := p.arrowBody(p.text(j, closeParen), closeParen+2)
+		fn.returns = p.returnTypes[p.toks[closeParen].pos]
+		return fn, true
+
+	case p.ident(j) != "" && p.is(j+1, "=>"):
+		return p.arrowBody(p.ident(j), j+2), true
+
+	case jsComponentWrappers[p.ident(j)] && p.is(j+1, "("):
+		return p.funcValue(j + 2)
+
+	case p.ident(j) != "" && p.is(j+1, ".") && jsComponentWrappers[p.ident(j+2)] && p.is(j+3, "("):
+		return p.funcValue(j + 4)
+	}
+	return jsFunc{}, false
+}
+
+// function returns the function whose parameters are enclosed by the
+// parentheses at indices open and closeParen and whose body starts at
+// index body.
+func (p *jsParser) function(open, closeParen, body int) jsFunc {
+	return jsFunc{
+		params:  p.text(open, closeParen),
+		returns: p.returnTypes[p.toks[closeParen].pos],
+		body:    body,
+	}
+}
+
+// arrowBody returns the function whose arrow body starts at index k.
+func (p *jsParser) arrowBody(params string, k int) jsFunc {
+	if p.is(k, "{") {
+		return jsFunc{params: params, body: k}
+	}
+	return jsFunc{params: params, body: -1, end: p.expressionEnd(k)}
+}
+
+// expressionEnd returns the index of the last token of the expression
+// starting at index k. The expression ends before a ',' or ';' or an
+// unmatched closing bracket, or at a line break that does not continue it.
+// The tokens embedded in JSX elements and template literals are part of
+// the literal.
+func (p *jsParser) expressionEnd(k int) int {
+	depth := 0
+	last := k
+	for j := k; j < len(p.toks); j++ {
+		t := p.toks[j]
+		if depth == 0 && j > k && t.nl && !p.continues(last, j) {
+			return last
+		}
+		if t.kind == jsPunct {
+			switch t.text {
+			case "(", "[", "{":
+				depth++
+			case ")", "]", "}":
+				if depth == 0 {
+					return last
+				}
+				depth--
+			case ",", ";":
+				if depth == 0 {
+					return last
+				}
+			}
+		}
+		last = j
+		if t.kind == jsJSX || t.kind == jsTemplate {
+			for j+1 < len(p.toks) && p.toks[j+1].pos < t.end {
+				j++
+			}
+		}
+	}
+	return last
+}
+
+// continues reports whether the token at index next, which starts a new
+// line, continues the expression ending with the token at index prev.
+func (p *jsParser) continues(prev, next int) bool {
+	if t := p.toks[prev]; t.kind == jsPunct && t.text != ")" && t.text != "]" && t.text != "}" {
+		return true
+	}
+	if t := p.toks[next]; t.kind == jsPunct {
+		switch t.text {
+		case ".", "?.", "?", ":", "&&", "||", "??", "+", "-", "*", "/", "=", "===", "!==", "==", "!=":
+			return true
+		}
+	}
+	return false
+}
+
+// define records a symbol whose declaration starts at token start and
+// whose body is fn, and returns its index.
+func (p *jsParser) define(sym ParsedSymbol, start int, fn jsFunc) int {
+	sym.StartLine = p.toks[start].line
+	if sym.Decorators == nil {
+		sym.Decorators = p.decorators[sym.StartLine]
+	}
+	index := len(p.result.Symbols)
+	p.result.Symbols = append(p.result.Symbols, sym)
+	p.starts = append(p.starts, p.toks[start].pos)
+
+	if fn.body >= 0 {
+		kind := jsFunctionBody
+		if sym.Kind == graph.NodeClass {
+			kind = jsClassBody
+		}
+		p.pending[fn.body] = jsFrame{kind: kind, symbol: index, className: sym.Name}
+	} else {
+		p.end(index, fn.end)
+	}
+	return index
+}
+
+// end records that the symbol at index ends with the token at index i.
+func (p *jsParser) end(index, i int) {
+	sym := &p.result.Symbols[index]
+	t := p.tok(i)
+	if t.kind == jsEOF {
+		t = p.toks[len(p.toks)-1]
+	}
+	sym.EndLine = t.endLine
+	if end := t.end; end >= p.starts[index] {
+		sym.Content = p.source[p.starts[index]:end]
+	}
+}
+
+// defineFunction records a function (or, with className set, a method)
+// named name whose declaration starts at token start.
+func (p *jsParser) defineFunction(name, className string, start int, fn jsFunc, exported bool) {
+	sym := ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeFunction,
+		ClassName:  className,
+		Signature:  fmt.Sprintf("function %s(%s)", name, fn.params),
+		IsExported: exported,
+	}
+	if className != "" {
+		sym.Kind = graph.NodeMethod
+		sym.Signature = fmt.Sprintf("%s(%s)", name, fn.params)
+	}
+	if fn.returns != "" {
+		sym.Signature += ": " + fn.returns
+	}
+	p.define(sym, start, fn)
+}
+
+// anonymousFunction gives the body of an arrow function that is not part
+// of a declaration a function frame, so that its local declarations are
+// not recorded. Immediately invoked functions keep their declarations.
+func (p *jsParser) anonymousFunction(arrow int) {
+	if _, ok := p.pending[arrow+1]; ok || !p.is(arrow+1, "{") {
+		return
+	}
+	params := arrow - 1
+	if p.is(params, ")") {
+		params = p.matchingOpen(params)
+	}
+	if p.is(params-1, "async") {
+		params--
+	}
+	p.pending[arrow+1] = jsFrame{kind: p.functionKind(params), symbol: -1}
+}
+
+// functionKind returns the frame kind for the body of an anonymous function
+// starting at index i: a block for immediately invoked functions, which
+// wrap module code, and a function body otherwise.
+func (p *jsParser) functionKind(i int) jsFrameKind {
+	if (p.is(i-1, "(") || p.is(i-1, "!")) && p.statementStart(i-1) {
+		return jsBlock
+	}
+	return jsFunctionBody
+}
+
+// declaration records the declaration starting at the identifier at index i.
+func (p *jsParser) declaration(i int) {
+	if frame := p.top(); frame != nil && p.parens == frame.parens {
+		switch frame.kind {
+		case jsClassBody:
+			p.classMember(i, frame)
+			return
+		case jsObjectBody:
+			if p.is(i-1, "{") || p.is(i-1, ",") {
+				p.objectMember(i, frame)
+			}
+			return
+		}
+	}
+
+	name := p.toks[i].text
+	if name == "function" {
+		p.functionDeclaration(i)
+		return
+	}
+	if name == "class" {
+		p.classDeclaration(i)
+		return
+	}
+	if p.inFunction() || !p.statementStart(i) {
+		return
+	}
+
+	switch {
+	case name == "const" || name == "let" || name == "var":
+		p.variableDeclaration(i)
+	case name == "export" && p.is(i+1, "default"):
+		if target := p.ident(i + 2); target != "" && p.statementEnd(i+3) {
+			p.exports[target] = true
+		}
+	case name == "export" && p.is(i+1, "{"):
+		p.exportList(i + 1)
+	case name == "module" && p.is(i+1, ".") && p.is(i+2, "exports"):
+		p.commonJSExport(i, i+3)
+	case name == "exports" && p.is(i+1, "."):
+		p.commonJSExport(i, i+1)
+	case p.is(i+1, ".") && p.is(i+2, "prototype"):
+		p.prototypeAssignment(i)
+	case name == "Object" && p.is(i+1, "."):
+		p.objectPrototypeCall(i)
+	case name == "inherits" && p.is(i+1, "("):
+		p.inheritsCall(i + 1)
+	case name == "util" && p.is(i+1, ".") && p.is(i+2, "inherits") && p.is(i+3, "("):
+		p.inheritsCall(i + 3)
+	}
+}
+
+// functionDeclaration records a function declaration, or gives the body of
+// a function expression a function frame.
+func (p *jsParser) functionDeclaration(i int) {
+	start := i
+	if p.is(i-1, "async") {
+		start = i - 1
+	}
+	fn, ok := p.funcValue(i)
+	if !ok {
+		return
+	}
+	if _, ok := p.pending[fn.body]; ok {
+		// Already recorded as the value of a declaration
+		return
+	}
+
+	name := p.ident(i + 1)
+	if p.is(i+1, "*") {
+		name = p.ident(i + 2)
+	}
+	if name == "" || p.inFunction() || !p.statementStart(start) {
+		p.pending[fn.body] = jsFrame{kind: p.functionKind(start), symbol: -1}
+		return
+	}
+	exported, first := p.exported(start)
+	p.defineFunction(name, p.namespace(), first, fn, exported)
+}
+
+// classDeclaration records a class declaration or class expression.
+func (p *jsParser) classDeclaration(i int) {
+	j := i + 1
+	name := p.ident(j)
+	if name == "extends" {
+		name = ""
+	}
+	if name != "" {
+		j++
+	}
+
+	start := i
+	exported := false
+	if p.is(i-1, "=") {
+		if target, ok := p.commonJSTarget(i - 1); ok && name != "" {
+			// module.exports = class Name
+			start, exported = target, true
+		} else if name == "" && p.ident(i-2) != "" {
+			// Class expressions take the name of the variable they are
+			// assigned to
+			name = p.ident(i - 2)
+			start = i - 2
+			if kw := p.ident(i - 3); kw == "const" || kw == "let" || kw == "var" {
+				start = i - 3
+			}
+		}
+	}
+
+	var base string
+	if p.is(j, "extends") {
+		// The base is the last identifier of a dotted name (React.Component)
+		j++
+		for p.ident(j) != "" && p.is(j+1, ".") {
+			j += 2
+		}
+		base = p.ident(j)
+	}
+	body := j
+	for ; body < len(p.toks) && !p.is(body, "{"); body++ {
+		if p.is(body, "(") || p.is(body, "[") {
+			body = p.matching(body)
+		}
+	}
+	if body >= len(p.toks) {
+		return
+	}
+
+	if name == "" || p.inFunction() || !p.statementStart(start) {
+		p.pending[body] = jsFrame{kind: jsClassBody, symbol: -1}
+		return
+	}
+
+	first := start
+	if !exported {
+		exported, first = p.exported(start)
+	}
+	signature := "class " + name
+	if base != "" {
+		signature += " extends " + base
+		p.addHeritage(name, base)
+	}
+	if interfaces := p.implements[name]; len(interfaces) > 0 {
+		signature += " implements " + strings.Join(interfaces, ", ")
+		h := p.heritage(name)
+		h.Implements = append(h.Implements, interfaces...)
+	}
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeClass,
+		Signature:  signature,
+		IsExported: exported,
+	}, first, jsFunc{body: body})
+}
+
+// classMember records a method (or a field holding a function) declared at
+// the identifier at index i of a class body.
+func (p *jsParser) classMember(i int, frame *jsFrame) {
+	switch p.toks[i].text {
+	case "static", "async", "get", "set":
+		// Modifiers, unless they are the name of the member
+		if !p.is(i+1, "(") && !p.is(i+1, "=") {
+			return
+		}
+	}
+
+	start := i
+	for start > 0 && p.toks[start-1].kind == jsIdent && !p.toks[start].nl {
+		switch p.toks[start-1].text {
+		case "static", "async", "get", "set":
+			start--
+			continue
+		}
+		break
+	}
+	if p.is(start-1, "*") {
+		start--
+	}
+
+	var fn jsFunc
+	switch {
+	case p.is(i+1, "("):
+		closeParen := p.matching(i + 1)
+		if !p.is(closeParen+1, "{") {
+			return
+		}
+		fn = p.function(i+1, closeParen, closeParen+1)
+	case p.is(i+1, "="):
+		var ok bool
+		if fn, ok = p.funcValue(i + 2); !ok {
+			return
+		}
+	default:
+		return
+	}
+
+	p.skip[i] = true
+	if frame.symbol < 0 {
+		p.pending[fn.body] = jsFrame{kind: jsFunctionBody, symbol: -1}
+		return
+	}
+	p.defineFunction(p.toks[i].text, frame.className, start, fn, false)
+}
+
+// objectMember records a member of a prototype or module.exports object
+// literal starting at the identifier at index i.
+func (p *jsParser) objectMember(i int, frame *jsFrame) {
+	name := p.toks[i].text
+	start := i
+	if name == "async" && p.ident(i+1) != "" {
+		i++
+		name = p.toks[i].text
+	}
+
+	var fn jsFunc
+	switch {
+	case p.is(i+1, "("):
+		closeParen := p.matching(i + 1)
+		if !p.is(closeParen+1, "{") {
+			return
+		}
+		fn = p.function(i+1, closeParen, closeParen+1)
+	case p.is(i+1, ":"):
+		var ok bool
+		if fn, ok = p.funcValue(i + 2); !ok {
+			// module.exports = { name: localName }
+			if frame.className == "" && p.ident(i+2) != "" && (p.is(i+3, ",") || p.is(i+3, "}")) {
+				p.exports[p.ident(i+2)] = true
+			}
+			return
+		}
+	case p.is(i+1, ",") || p.is(i+1, "}"):
+		// Shorthand property
+		if frame.className == "" {
+			p.exports[name] = true
+		}
+		return
+	default:
+		return
+	}
+
+	p.skip[i] = true
+	p.defineFunction(name, frame.className, start, fn, frame.exported)
+}
+
+// variableDeclaration records a variable holding a function, and the
+// functions of a variable holding an object literal, which are methods of
+// the variable:
+//
+//	const api = { save: async (x) => {}, load(id) {} }
+func (p *jsParser) variableDeclaration(i int) {
+	name := p.ident(i + 1)
+	if name == "" || !p.is(i+2, "=") {
+		return
+	}
+	exported, first := p.exported(i)
+
+	if fn, ok := p.funcValue(i + 3); ok {
+		p.skip[i+1] = true
+		p.defineFunction(name, p.namespace(), first, fn, exported)
+	} else if p.is(i+3, "{") {
+		p.pending[i+3] = jsFrame{kind: jsObjectBody, symbol: -1, className: qualifiedName(p.namespace(), name), exported: exported}
+	}
+}
+
+// exportList marks the names of "export { a, b as c }" as exported. Lists
+// with a "from" clause are re-exports, which are handled as imports.
+func (p *jsParser) exportList(open int) {
+	closeBrace := p.matching(open)
+	if p.is(closeBrace+1, "from") {
+		return
+	}
+	for _, name := range p.specifiers(open, closeBrace) {
+		p.exports[name] = true
+	}
+}
+
+// specifiers returns the local names of the import or export specifiers
+// between the braces at indices open and closeBrace ("a as b" yields a).
+func (p *jsParser) specifiers(open, closeBrace int) []string {
+	var names []string
+	for j := open + 1; j < closeBrace; j++ {
+		name := p.ident(j)
+		if name == "" || name == "type" && p.ident(j+1) != "" && !p.is(j+1, "as") {
+			continue
+		}
+		names = append(names, name)
+		for j < closeBrace && !p.is(j, ",") {
+			j++
+		}
+	}
+	return names
+}
+
+// commonJSExport records "module.exports = ..." and "exports.name = ..."
+// assignments starting at index start. The token at index next is the '.'
+// or '=' following module.exports or exports.
+func (p *jsParser) commonJSExport(start, next int) {
+	// module.exports.name = ... / exports.name = ...
+	if p.is(next, ".") {
+		name := p.ident(next + 1)
+		if name == "" || !p.is(next+2, "=") {
+			return
+		}
+		value := next + 3
+		if fn, ok := p.funcValue(value); ok {
+			p.skip[next+1] = true
+			p.defineFunction(name, "", start, fn, true)
+		} else if local := p.ident(value); local != "" && p.statementEnd(value+1) {
+			p.exports[local] = true
+		}
+		return
+	}
+
+	if !p.is(next, "=") {
+		return
+	}
+	value := next + 1
+	switch {
+	case p.is(value, "{"):
+		p.pending[value] = jsFrame{kind: jsObjectBody, symbol: -1, exported: true}
+	case p.is(value, "function") || p.is(value, "async") && p.is(value+1, "function"):
+		fnIndex := value
+		if p.is(value, "async") {
+			fnIndex++
+		}
+		if name := p.ident(fnIndex + 1); name != "" {
+			if fn, ok := p.funcValue(value); ok {
+				p.defineFunction(name, "", start, fn, true)
+			}
+		}
+	case p.ident(value) != "" && p.statementEnd(value+1):
+		p.exports[p.ident(value)] = true
+	}
+}
+
+// commonJSTarget returns the index of the "module.exports" or "exports"
+// assigned by the '=' at index eq.
+func (p *jsParser) commonJSTarget(eq int) (int, bool) {
+	if !p.is(eq-1, "exports") {
+		return 0, false
+	}
+	if p.is(eq-2, ".") && p.is(eq-3, "module") {
+		return eq - 3, true
+	}
+	if p.is(eq-2, ".") {
+		return 0, false
+	}
+	return eq - 1, true
+}
+
+// prototypeAssignment records assignments to the prototype of the
+// constructor function named at index i:
+//
+//	Name.prototype.method = function () {}
+//	Name.prototype = { method() {} }
+//	Name.prototype = Object.create(Base.prototype)
+func (p *jsParser) prototypeAssignment(i int) {
+	className := p.toks[i].text
+	switch {
+	case p.is(i+3, ".") && p.ident(i+4) != "" && p.is(i+5, "="):
+		if fn, ok := p.funcValue(i + 6); ok {
+			p.skip[i+4] = true
+			p.protos[className] = true
+			p.defineFunction(p.ident(i+4), className, i, fn, false)
+		}
+	case p.is(i+3, "=") && p.is(i+4, "{"):
+		p.protos[className] = true
+		p.pending[i+4] = jsFrame{kind: jsObjectBody, symbol: -1, className: className}
+	case p.is(i+3, "=") && p.is(i+4, "Object") && p.is(i+5, ".") && p.is(i+6, "create") && p.is(i+7, "("):
+		if base := p.ident(i + 8); base != "" && p.is(i+9, ".") && p.is(i+10, "prototype") {
+			p.addHeritage(className, base)
+		}
+	}
+}
+
+// objectPrototypeCall records Object.assign(Name.prototype, {...}) and
+// Object.setPrototypeOf(Name.prototype, Base.prototype).
+func (p *jsParser) objectPrototypeCall(i int) {
+	if !p.is(i+3, "(") || !p.is(i+5, ".") || !p.is(i+6, "prototype") || !p.is(i+7, ",") {
+		return
+	}
+	className := p.ident(i + 4)
+	if className == "" {
+		return
+	}
+	switch p.ident(i + 2) {
+	case "assign":
+		if p.is(i+8, "{") {
+			p.protos[className] = true
+			p.pending[i+8] = jsFrame{kind: jsObjectBody, symbol: -1, className: className}
+		}
+	case "setPrototypeOf":
+		if base := p.ident(i + 8); base != "" && p.is(i+9, ".") && p.is(i+10, "prototype") {
+			p.addHeritage(className, base)
+		}
+	}
+}
+
+// inheritsCall records inherits(Name, Base) and util.inherits(Name, Base);
+// the token at index open is the '('.
+func (p *jsParser) inheritsCall(open int) {
+	name, base := p.ident(open+1), p.ident(open+3)
+	if name != "" && base != "" && p.is(open+2, ",") {
+		p.addHeritage(name, base)
+	}
+}
+
+// addHeritage records that class name extends base.
+func (p *jsParser) addHeritage(name, base string) {
+	h := p.heritage(name)
+	h.Extends = append(h.Extends, base)
+}
+
+// heritage returns the heritage record of class name, adding it if needed.
+func (p *jsParser) heritage(name string) *ClassHeritage {
+	for i := range p.result.Heritage {
+		if h := &p.result.Heritage[i]; h.ClassName == name {
+			return h
+		}
+	}
+	p.result.Heritage = append(p.result.Heritage, ClassHeritage{ClassName: name})
+	return &p.result.Heritage[len(p.result.Heritage)-1]
+}
+
+// imports records the import at the identifier at index i: ES module
+// imports, re-exports, dynamic import() and require() calls.
+func (p *jsParser) imports(i int) {
+	t := p.toks[i]
+	switch t.text {
+	case "import":
+		if p.is(i-1, ".") || p.is(i+1, ".") {
+			return
+		}
+		if p.is(i+1, "(") {
+			// Dynamic import('module')
+			if spec := p.tok(i + 2); spec.kind == jsString && p.is(i+3, ")") {
+				p.addImport(spec.text, nil, t.line)
+			}
+			return
+		}
+		if spec := p.tok(i + 1); spec.kind == jsString {
+			// Side-effect import 'module'
+			p.addImport(spec.text, nil, t.line)
+			return
+		}
+		p.importClause(i+1, t.line)
+
+	case "export":
+		if p.is(i+1, "*") || p.is(i+1, "{") || p.is(i+1, "type") && p.is(i+2, "{") {
+			p.importClause(i+1, t.line)
+		}
+
+	case "require":
+		if p.is(i-1, ".") || !p.is(i+1, "(") || !p.is(i+3, ")") {
+			return
+		}
+		spec := p.tok(i + 2)
+		if spec.kind != jsString {
+			return
+		}
+		p.addImport(spec.text, p.requireSymbols(i), t.line)
+	}
+}
+
+// importClause records the import or re-export whose clause starts at
+// index j and ends with "from 'module'".
+func (p *jsParser) importClause(j int, line int) {
+	var symbols []string
+	for ; j < len(p.toks) && !p.is(j, "from"); j++ {
+		switch {
+		case p.is(j, ";") || p.is(j, "import") || p.is(j, "export"):
+			return
+		case p.is(j, "{"):
+			closeBrace := p.matching(j)
+			if !p.is(closeBrace+1, "from") && !p.is(closeBrace+1, ",") {
+				// An export list without a module
+				return
+			}
+			symbols = append(symbols, p.specifiers(j, closeBrace)...)
+			j = closeBrace
+		case p.is(j, "as") && p.ident(j+1) != "":
+			// import * as ns
+			symbols = append(symbols, p.ident(j+1))
+			j++
+		case p.ident(j) != "" && p.ident(j) != "type" && !p.is(j-1, "as"):
+			// Default import
+			symbols = append(symbols, p.ident(j))
+		}
+	}
+	if spec := p.tok(j + 1); spec.kind == jsString {
+		p.addImport(spec.text, symbols, line)
+	}
+}
+
+// requireSymbols returns the names bound by the require() call at index i:
+// the variable it is assigned to, the destructured properties, or the
+// property accessed on its result.
+func (p *jsParser) requireSymbols(i int) []string {
+	if p.is(i+4, ".") && p.ident(i+5) != "" {
+		return []string{p.ident(i + 5)}
+	}
+	if !p.is(i-1, "=") {
+		return nil
+	}
+	if name := p.ident(i - 2); name != "" {
+		return []string{name}
+	}
+	if p.is(i-2, "}") {
+		// Destructuring: const { a, b: c } = require('module')
+		open := p.matchingOpen(i - 2)
+		return p.specifiers(open, i-2)
+	}
+	return nil
+}
+
+func (p *jsParser) addImport(module string, symbols []string, line int) {
+	if symbols == nil {
+		symbols = []string{}
+	}
+	p.result.Imports = append(p.result.Imports, ImportStatement{
+		ModulePath: module,
+		Symbols:    symbols,
+		IsRelative: strings.HasPrefix(module, "."),
+		StartLine:  line,
+	})
+}
+
+// call records a call of the identifier at index i.
+func (p *jsParser) call(i int) {
+	t := p.toks[i]
+	if !p.is(i+1, "(") || p.skip[i] || jsNotCalled[t.text] || p.is(i-1, "function") {
+		return
+	}
+	// name(...) { is a method definition, async (...) => an arrow function
+	if next := p.matching(i+1) + 1; p.is(next, "{") || p.is(next, "=>") {
+		return
+	}
+	call := CallSite{Name: t.text, StartLine: t.line, EndLine: t.line}
+	if p.is(i-1, ".") || p.is(i-1, "?.") {
+		call.Receiver = p.ident(i - 2)
+		if call.Receiver != "" && p.is(i-3, ".") && p.is(i-4, "this") {
+			p.thisCalls[len(p.result.Calls)] = true
+		}
+	}
+	p.result.Calls = append(p.result.Calls, call)
+}
+
+// jsxCall records the rendering of a component as a call. Intrinsic
+// elements (lower-case tags such as <div>) are not calls.
+func (p *jsParser) jsxCall(t jsToken) {
+	if t.text == "" || !strings.Contains(t.text, ".") && (t.text[0] < 'A' || t.text[0] > 'Z') {
+		return
+	}
+	call := CallSite{Name: t.text, StartLine: t.line, EndLine: t.line}
+	if dot := strings.LastIndexByte(t.text, '.'); dot >= 0 {
+		call.Receiver = t.text[strings.LastIndexByte(t.text[:dot], '.')+1 : dot]
+		call.Name = t.text[dot+1:]
+	}
+	p.result.Calls = append(p.result.Calls, call)
+}
+
+// finish applies what is only known once the whole file has been read:
+// exports, constructor functions with prototypes, and the symbols calls,
+// route registrations and queries appear in.
+func (p *jsParser) finish() {
+	for i := range p.result.Symbols {
+		sym := &p.result.Symbols[i]
+		if sym.ClassName != "" {
+			continue
+		}
+		if p.exports[sym.Name] {
+			sym.IsExported = true
+		}
+		// A constructor function with prototype methods is a class
+		if sym.Kind == graph.NodeFunction && p.protos[sym.Name] {
+			sym.Kind = graph.NodeClass
+		}
+	}
+
+	// Functions of a namespace call each other without qualifier
+	namespaceMembers := make(map[[2]string]bool)
+	for _, sym := range p.result.Symbols {
+		if p.qualifiedNamespaces[sym.ClassName] {
+			namespaceMembers[[2]string{sym.ClassName, sym.Name}] = true
+		}
+	}
+
+	for i := range p.result.Calls {
+		call := &p.result.Calls[i]
+		call.Enclosing, call.EnclosingClass = enclosingSymbol(p.result.Symbols, call.StartLine)
+		if call.Receiver == "" && namespaceMembers[[2]string{call.EnclosingClass, call.Name}] {
+			call.Receiver = call.EnclosingClass
+			continue
+		}
+
+		// this.method() calls a method of the enclosing class, and
+		// this.field.method() one of the declared type of the field
+		if call.Receiver == "this" {
+			call.Receiver = call.EnclosingClass
+			if call.Receiver == "" && p.protos[call.Enclosing] {
+				call.Receiver = call.Enclosing
+			}
+		} else if p.thisCalls[i] {
+			if typ := p.fields[call.EnclosingClass][call.Receiver]; typ != "" {
+				call.Receiver = typ
+			}
+		}
+	}
+
+	for i := range p.result.TypeRefs {
+		ref := &p.result.TypeRefs[i]
+		ref.Enclosing, ref.EnclosingClass = enclosingSymbol(p.result.Symbols, ref.StartLine)
+	}
+	for i := range p.result.Routes {
+		route := &p.result.Routes[i]
+		route.Enclosing, route.EnclosingClass = enclosingSymbol(p.result.Symbols, route.StartLine)
+		route.Path = joinRoutePath(p.mountPrefix(p.routeRouters[i], 0), route.Path)
+	}
+	p.routeMounts()
+	for i := range p.result.TableRefs {
+		ref := &p.result.TableRefs[i]
+		ref.Enclosing, ref.EnclosingClass = enclosingSymbol(p.result.Symbols, ref.StartLine)
+	}
+
+	p.components()
+}
+
+// components marks the React components: capitalized functions that render
+// JSX, and classes extending Component or PureComponent.
+func (p *jsParser) components() {
+	bases := make(map[string]bool)
+	for _, h := range p.result.Heritage {
+		for _, base := range h.Extends {
+			if base == "Component" || base == "PureComponent" {
+				bases[h.ClassName] = true
+			}
+		}
+	}
+
+	var jsxLines []int
+	for _, t := range p.toks {
+		if t.kind == jsJSX {
+			jsxLines = append(jsxLines, t.line)
+		}
+	}
+
+	for i := range p.result.Symbols {
+		sym := &p.result.Symbols[i]
+		if sym.ClassName != "" || sym.Name == "" || sym.Name[0] < 'A' || sym.Name[0] > 'Z' {
+			continue
+		}
+		switch sym.Kind {
+		case graph.NodeClass:
+			sym.IsComponent = bases[sym.Name]
+		case graph.NodeFunction:
+			for _, line := range jsxLines {
+				if sym.StartLine <= line && line <= sym.EndLine {
+					sym.IsComponent = true
+					break
+				}
+			}
+		}
+	}
+}
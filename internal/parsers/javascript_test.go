@@ -0,0 +1,305 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// symbolsByName indexes parsed symbols by name, qualifying methods with
+// their class.
+func symbolsByName(result *ParseResult) map[string]ParsedSymbol {
+	symbols := make(map[string]ParsedSymbol)
+	for _, sym := range result.Symbols {
+		name := sym.Name
+		if sym.ClassName != "" {
+			name = sym.ClassName + "." + name
+		}
+		symbols[name] = sym
+	}
+	return symbols
+}
+
+// importsByModule indexes parsed imports by module path.
+func importsByModule(result *ParseResult) map[string][]string {
+	imports := make(map[string][]string)
+	for _, imp := range result.Imports {
+		imports[imp.ModulePath] = imp.Symbols
+	}
+	return imports
+}
+
+func TestJavaScriptParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("CommonJS", func(t *testing.T) {
+		content := []byte(`'use strict';
+const express = require('express');
+const { Router, json: toJSON } = require('./router');
+const helper = require('../lib/helper').helper;
+
+function createApp(config) {
+  const app = express();
+  app.use(toJSON());
+  return app;
+}
+
+function unused() {}
+
+const start = async (port) => {
+  createApp({}).listen(port);
+};
+
+exports.handler = async (event) => {
+  return start(event.port);
+};
+
+module.exports = { createApp, run: start };
+`)
+		result, err := NewJavaScriptParser().Parse("app.js", content)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string][]string{
+			"express":       {"express"},
+			"./router":      {"Router", "json"},
+			"../lib/helper": {"helper"},
+		}, importsByModule(result))
+
+		symbols := symbolsByName(result)
+		require.Contains(t, symbols, "createApp")
+		assert.Equal(t, graph.NodeFunction, symbols["createApp"].Kind)
+		assert.Equal(t, "function createApp(config)", symbols["createApp"].Signature)
+		assert.Equal(t, 6, symbols["createApp"].StartLine)
+		assert.Equal(t, 10, symbols["createApp"].EndLine)
+		assert.Contains(t, symbols["createApp"].Content, "app.use(toJSON())")
+
+		assert.True(t, symbols["createApp"].IsExported, "exported through module.exports")
+		assert.True(t, symbols["start"].IsExported, "exported under another name")
+		assert.True(t, symbols["handler"].IsExported, "exports.handler")
+		assert.False(t, symbols["unused"].IsExported)
+		assert.NotContains(t, symbols, "app", "local variables are not symbols")
+	})
+
+	t.Run("ESModules", func(t *testing.T) {
+		content := []byte(`import React, { useState, memo as m } from 'react';
+import * as path from 'path';
+import './styles.css';
+export { default as Button } from './Button';
+export * from './utils';
+
+const lazy = () => import('./Lazy');
+
+function format(value) { return value; }
+export function load() { return format(1); }
+export default class Store {}
+export { format };
+`)
+		result, err := NewJavaScriptParser().Parse("index.mjs", content)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string][]string{
+			"react":        {"React", "useState", "memo"},
+			"path":         {"path"},
+			"./styles.css": {},
+			"./Button":     {"default"},
+			"./utils":      {},
+			"./Lazy":       {},
+		}, importsByModule(result))
+
+		symbols := symbolsByName(result)
+		assert.True(t, symbols["load"].IsExported)
+		assert.True(t, symbols["format"].IsExported, "exported through an export list")
+		assert.True(t, symbols["Store"].IsExported)
+		assert.Equal(t, graph.NodeClass, symbols["Store"].Kind)
+		assert.False(t, symbols["lazy"].IsExported)
+	})
+
+	t.Run("Classes", func(t *testing.T) {
+		content := []byte(`class Service extends Base.Model {
+  static create() { return new Service(); }
+
+  constructor(db) {
+    super();
+    this.db = db;
+  }
+
+  async find(id) {
+    if (id) {
+      return this.query(id);
+    }
+  }
+
+  handle = (req) => {
+    this.find(req.id);
+  };
+
+  get size() { return 1; }
+}
+`)
+		result, err := NewJavaScriptParser().Parse("service.js", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Equal(t, "class Service extends Model", symbols["Service"].Signature)
+		for _, name := range []string{"create", "constructor", "find", "handle", "size"} {
+			require.Contains(t, symbols, "Service."+name)
+			assert.Equal(t, graph.NodeMethod, symbols["Service."+name].Kind)
+		}
+		assert.NotContains(t, symbols, "Service.query", "calls in method bodies are not methods")
+		assert.Equal(t, 9, symbols["Service.find"].StartLine)
+		assert.Equal(t, 13, symbols["Service.find"].EndLine)
+
+		assert.Equal(t, []ClassHeritage{{ClassName: "Service", Extends: []string{"Model"}}}, result.Heritage)
+
+		calls := make(map[string]CallSite)
+		for _, call := range result.Calls {
+			calls[call.Name] = call
+		}
+		assert.Equal(t, "Service", calls["query"].Receiver, "this resolves to the enclosing class")
+		assert.Equal(t, "find", calls["query"].Enclosing)
+		assert.Equal(t, "handle", calls["find"].Enclosing)
+		assert.Equal(t, "create", calls["Service"].Enclosing, "new Service() calls the class")
+	})
+
+	t.Run("PrototypeMethods", func(t *testing.T) {
+		content := []byte(`function Animal(name) {
+  this.name = name;
+  this.init();
+}
+
+Animal.prototype.speak = function () {
+  return this.name;
+};
+
+Animal.prototype.init = function () {};
+
+function Dog(name) {
+  Animal.call(this, name);
+}
+util.inherits(Dog, Animal);
+
+Object.assign(Dog.prototype, {
+  bark() { this.speak(); },
+  fetch: function (item) { return item; },
+});
+`)
+		result, err := NewJavaScriptParser().Parse("animals.js", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Equal(t, graph.NodeClass, symbols["Animal"].Kind, "constructor functions with prototypes are classes")
+		assert.Equal(t, graph.NodeClass, symbols["Dog"].Kind)
+		for _, name := range []string{"Animal.speak", "Animal.init", "Dog.bark", "Dog.fetch"} {
+			require.Contains(t, symbols, name)
+			assert.Equal(t, graph.NodeMethod, symbols[name].Kind)
+		}
+		assert.Equal(t, "fetch(item)", symbols["Dog.fetch"].Signature)
+
+		assert.Equal(t, []ClassHeritage{{ClassName: "Dog", Extends: []string{"Animal"}}}, result.Heritage)
+
+		for _, call := range result.Calls {
+			if call.Name == "init" {
+				assert.Equal(t, "Animal", call.Receiver)
+				assert.Equal(t, "Animal", call.Enclosing)
+			}
+		}
+	})
+
+	t.Run("JSXComponents", func(t *testing.T) {
+		content := []byte(`import React, { useState } from 'react';
+
+const Layout = ({ children }) => <main className="layout">{children}</main>;
+
+export const Item = React.memo(({ label, onClick }) => (
+  <li onClick={onClick}>{label}</li>
+));
+
+export default function App({ items }) {
+  const [selected, select] = useState(null);
+  return (
+    <Layout>
+      {items.map(item => <Item key={item} label={format(item)} onClick={() => select(item)} />)}
+      <ui.Modal open={selected !== null} />
+      <p>Don't {"panic"} / {1 < 2}</p>
+    </Layout>
+  );
+}
+`)
+		result, err := NewJavaScriptParser().Parse("App.jsx", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		require.Contains(t, symbols, "Layout")
+		assert.Equal(t, 3, symbols["Layout"].EndLine)
+		require.Contains(t, symbols, "Item")
+		assert.Equal(t, 7, symbols["Item"].EndLine)
+		assert.True(t, symbols["Item"].IsExported)
+		assert.Equal(t, 9, symbols["App"].StartLine)
+		assert.Equal(t, 18, symbols["App"].EndLine)
+
+		var rendered []string
+		calls := make(map[string]CallSite)
+		for _, call := range result.Calls {
+			calls[call.Name] = call
+			if call.Enclosing == "App" {
+				rendered = append(rendered, call.Name)
+			}
+		}
+		assert.ElementsMatch(t, []string{"useState", "Layout", "map", "Item", "format", "select", "Modal"}, rendered)
+		assert.Equal(t, "ui", calls["Modal"].Receiver)
+	})
+
+	t.Run("Literals", func(t *testing.T) {
+		content := []byte(`const pattern = /[{(]\/}/g;
+const text = ` + "`{ ${render(1)} (`" + `;
+const ratio = total / count / 2;
+
+function after() {
+  return "}";
+}
+`)
+		result, err := NewJavaScriptParser().Parse("literals.js", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		require.Contains(t, symbols, "after", "braces in literals do not unbalance the source")
+		assert.Equal(t, 5, symbols["after"].StartLine)
+		assert.Equal(t, 7, symbols["after"].EndLine)
+
+		require.Len(t, result.Calls, 1)
+		assert.Equal(t, "render", result.Calls[0].Name)
+	})
+
+	t.Run("ImmediatelyInvokedFunction", func(t *testing.T) {
+		content := []byte(`(function (global) {
+  function plugin() {}
+  global.plugin = plugin;
+})(this);
+
+describe('plugin', () => {
+  function fixture() {}
+});
+`)
+		result, err := NewJavaScriptParser().Parse("plugin.js", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Contains(t, symbols, "plugin", "module code wrapped in an IIFE is top level")
+		assert.NotContains(t, symbols, "fixture", "functions local to callbacks are not symbols")
+	})
+}
+
+func TestJavaScriptParser_Language(t *testing.T) {
+	t.Parallel()
+
+	parser := NewJavaScriptParser()
+	assert.Equal(t, "javascript", parser.Language())
+
+	for _, name := range []string{"a.js", "a.jsx", "a.mjs", "a.cjs"} {
+		assert.True(t, parser.SupportsFile(name), name)
+	}
+	assert.False(t, parser.SupportsFile("a.ts"))
+}
@@ -0,0 +1,422 @@
+package parsers
+
+import "strings"
+
+// jsTokenKind is the kind of a JavaScript token.
+type jsTokenKind int
+
+const (
+	jsEOF jsTokenKind = iota
+	jsIdent
+	jsPunct
+	jsString
+	jsTemplate
+	jsNumber
+	jsRegex
+
+	// jsJSX is the start of a JSX element; its text is the tag name ("" for
+	// fragments). The tokens of embedded expressions follow it.
+	jsJSX
+)
+
+// jsToken is a token of JavaScript source.
+type jsToken struct {
+	kind jsTokenKind
+
+	// text is the token text; for strings it is the unquoted value
+	text string
+
+	// pos and end are the byte offsets of the token in the source. For JSX
+	// elements and template literals, end is the end of the whole literal.
+	pos, end int
+
+	// line and endLine are the 1-based lines of pos and end
+	line, endLine int
+
+	// nl is true when a line break precedes the token
+	nl bool
+}
+
+// jsPunctuators are the multi-character punctuators, longest first.
+var jsPunctuators = []string{
+	">>>=", "...", "===", "!==", "**=", "<<=", ">>=", ">>>", "&&=", "||=", "??=",
+	"=>", "==", "!=", "<=", ">=", "&&", "||", "??", "?.", "++", "--",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "**", "<<", ">>",
+}
+
+// jsExpressionKeywords are the keywords after which an expression starts,
+// so that a '/' begins a regular expression and a '<' a JSX element.
+var jsExpressionKeywords = map[string]bool{
+	"return": true, "typeof": true, "instanceof": true, "in": true, "of": true,
+	"new": true, "delete": true, "void": true, "throw": true, "case": true,
+	"do": true, "else": true, "yield": true, "await": true, "default": true,
+}
+
+// jsLexer splits JavaScript source into tokens. Comments are dropped, and
+// string, template and regular expression literals become single tokens, so
+// that braces and parentheses inside them do not affect the structure. The
+// expressions embedded in template literals and JSX are tokenized in place.
+type jsLexer struct {
+	src  string
+	pos  int
+	line int
+	jsx  bool
+
+	toks []jsToken
+	nl   bool
+
+	// afterValue is set when a template literal or JSX element ended last,
+	// which the token list alone does not show
+	afterValue bool
+}
+
+// lexJS tokenizes source. With jsx set, '<' in expression position starts
+// a JSX element.
+func lexJS(source string, jsx bool) []jsToken {
+	l := &jsLexer{src: source, line: 1, jsx: jsx}
+	if strings.HasPrefix(source, "#!") {
+		l.skipLine()
+	}
+	l.run(0)
+	return l.toks
+}
+
+// run tokenizes until the end of the source or, with stop set to '}', until
+// the brace that closes an embedded expression.
+func (l *jsLexer) run(stop byte) {
+	depth := 0
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.nl = true
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			l.pos++
+		case c == '/' && l.peek(1) == '/':
+			l.skipLine()
+		case c == '/' && l.peek(1) == '*':
+			l.skipBlockComment()
+		case c == '"' || c == '\'':
+			l.string(c)
+		case c == '`':
+			l.template()
+		case isJSDigit(c) || (c == '.' && isJSDigit(l.peek(1))):
+			l.scan(jsNumber, func(c byte) bool { return isJSIdentPart(c) || c == '.' })
+		case isJSIdentStart(c):
+			l.scan(jsIdent, isJSIdentPart)
+		case c == '{':
+			depth++
+			l.emit(jsPunct, l.pos, l.pos+1)
+		case c == '}':
+			l.emit(jsPunct, l.pos, l.pos+1)
+			if depth == 0 && stop == '}' {
+				return
+			}
+			depth--
+		case c == '/' && l.expressionStart():
+			l.regex()
+		case c == '<' && l.jsx && l.expressionStart() && (l.peek(1) == '>' || isJSIdentStart(l.peek(1))) && !l.typeParameters():
+			l.jsxElement()
+		default:
+			l.punct()
+		}
+	}
+}
+
+// peek returns the byte n positions ahead, or 0 at the end of the source.
+func (l *jsLexer) peek(n int) byte {
+	if l.pos+n < len(l.src) {
+		return l.src[l.pos+n]
+	}
+	return 0
+}
+
+// emit appends a token spanning src[start:end] and moves past it.
+func (l *jsLexer) emit(kind jsTokenKind, start, end int) {
+	l.toks = append(l.toks, jsToken{
+		kind:    kind,
+		text:    l.src[start:end],
+		pos:     start,
+		end:     end,
+		line:    l.line,
+		endLine: l.line,
+		nl:      l.nl,
+	})
+	l.pos = end
+	l.nl = false
+	l.afterValue = false
+}
+
+// advance moves to offset end, counting line breaks.
+func (l *jsLexer) advance(end int) {
+	if end > len(l.src) {
+		end = len(l.src)
+	}
+	l.line += strings.Count(l.src[l.pos:end], "\n")
+	l.pos = end
+}
+
+func (l *jsLexer) skipLine() {
+	if i := strings.IndexByte(l.src[l.pos:], '\n'); i >= 0 {
+		l.pos += i
+	} else {
+		l.pos = len(l.src)
+	}
+}
+
+func (l *jsLexer) skipBlockComment() {
+	end := len(l.src)
+	if i := strings.Index(l.src[l.pos+2:], "*/"); i >= 0 {
+		end = l.pos + 2 + i + 2
+	}
+	if strings.Contains(l.src[l.pos:end], "\n") {
+		l.nl = true
+	}
+	l.advance(end)
+}
+
+// scan emits a token of the bytes accepted by part.
+func (l *jsLexer) scan(kind jsTokenKind, part func(byte) bool) {
+	end := l.pos + 1
+	for end < len(l.src) && part(l.src[end]) {
+		end++
+	}
+	l.emit(kind, l.pos, end)
+}
+
+// string emits a quoted string literal.
+func (l *jsLexer) string(quote byte) {
+	start := l.pos
+	end := start + 1
+	for end < len(l.src) && l.src[end] != quote && l.src[end] != '\n' {
+		if l.src[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	if end > len(l.src) {
+		end = len(l.src)
+	}
+	l.emit(jsString, start, end)
+	tok := &l.toks[len(l.toks)-1]
+	tok.text = l.src[start+1 : end]
+	if end < len(l.src) {
+		tok.end = end + 1
+		l.pos = end + 1
+	}
+}
+
+// template emits a template literal and tokenizes its substitutions.
+func (l *jsLexer) template() {
+	l.emit(jsTemplate, l.pos, l.pos+1)
+	index := len(l.toks) - 1
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\\':
+			l.advance(l.pos + 2)
+		case c == '`':
+			l.pos++
+			l.finishLiteral(index)
+			return
+		case c == '$' && l.peek(1) == '{':
+			l.embedded(l.pos + 1)
+		case c == '\n':
+			l.line++
+			l.pos++
+		default:
+			l.pos++
+		}
+	}
+	l.finishLiteral(index)
+}
+
+// embedded tokenizes the expression embedded in a template literal or JSX
+// element whose opening brace is at offset open. The braces are emitted, so
+// that the tokens of neighbouring expressions stay apart.
+func (l *jsLexer) embedded(open int) {
+	l.emit(jsPunct, open, open+1)
+	l.run('}')
+}
+
+// finishLiteral records the end of the template or JSX literal whose token
+// is at index.
+func (l *jsLexer) finishLiteral(index int) {
+	l.toks[index].end = l.pos
+	l.toks[index].endLine = l.line
+	l.afterValue = true
+}
+
+// regex emits a regular expression literal.
+func (l *jsLexer) regex() {
+	start := l.pos
+	end := start + 1
+	inClass := false
+	for end < len(l.src) && l.src[end] != '\n' {
+		c := l.src[end]
+		if c == '\\' {
+			end += 2
+			continue
+		}
+		if c == '[' {
+			inClass = true
+		} else if c == ']' {
+			inClass = false
+		} else if c == '/' && !inClass {
+			break
+		}
+		end++
+	}
+	end++
+	for end < len(l.src) && isJSIdentPart(l.src[end]) {
+		end++
+	}
+	if end > len(l.src) {
+		end = len(l.src)
+	}
+	l.emit(jsRegex, start, end)
+}
+
+// punct emits a punctuator.
+func (l *jsLexer) punct() {
+	for _, p := range jsPunctuators {
+		if strings.HasPrefix(l.src[l.pos:], p) {
+			// "?." followed by a digit is a conditional ("a?.5:b")
+			if p == "?." && isJSDigit(l.peek(2)) {
+				continue
+			}
+			l.emit(jsPunct, l.pos, l.pos+len(p))
+			return
+		}
+	}
+	l.emit(jsPunct, l.pos, l.pos+1)
+}
+
+// expressionStart reports whether an expression may start at the current
+// position, which decides between division and a regular expression, and
+// between less-than and a JSX element.
+func (l *jsLexer) expressionStart() bool {
+	if l.afterValue {
+		return false
+	}
+	if len(l.toks) == 0 {
+		return true
+	}
+	prev := l.toks[len(l.toks)-1]
+	switch prev.kind {
+	case jsIdent:
+		return jsExpressionKeywords[prev.text]
+	case jsPunct:
+		switch prev.text {
+		case ")", "]", "}", "++", "--":
+			return false
+		}
+		return true
+	}
+	return false
+}
+
+// typeParameters reports whether the '<' at the current position opens the
+// type parameters of a generic arrow function in TSX, which are written
+// <T,>(x: T) => ... or <T extends U>(x: T) => ... to tell them from JSX.
+func (l *jsLexer) typeParameters() bool {
+	i := l.pos + 1
+	for i < len(l.src) && isJSIdentPart(l.src[i]) {
+		i++
+	}
+	rest := strings.TrimLeft(l.src[i:], " \t")
+	return strings.HasPrefix(rest, ",") || strings.HasPrefix(rest, "extends ")
+}
+
+// jsxElement emits a JSX element and tokenizes its embedded expressions.
+func (l *jsLexer) jsxElement() {
+	start := l.pos
+	l.pos++
+	nameStart := l.pos
+	for l.pos < len(l.src) && (isJSIdentPart(l.src[l.pos]) || l.src[l.pos] == '.' || l.src[l.pos] == ':' || l.src[l.pos] == '-') {
+		l.pos++
+	}
+	l.toks = append(l.toks, jsToken{
+		kind: jsJSX,
+		text: l.src[nameStart:l.pos],
+		pos:  start,
+		line: l.line,
+		nl:   l.nl,
+	})
+	l.nl = false
+	index := len(l.toks) - 1
+
+	// Attributes
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '/' && l.peek(1) == '>':
+			l.pos += 2
+			l.finishLiteral(index)
+			return
+		case c == '>':
+			l.pos++
+			l.jsxChildren()
+			l.finishLiteral(index)
+			return
+		case c == '{':
+			l.embedded(l.pos)
+		case c == '"' || c == '\'':
+			end := strings.IndexByte(l.src[l.pos+1:], c)
+			if end < 0 {
+				l.advance(len(l.src))
+			} else {
+				l.advance(l.pos + 1 + end + 1)
+			}
+		case c == '<':
+			l.jsxElement()
+		case c == '\n':
+			l.line++
+			l.pos++
+		default:
+			l.pos++
+		}
+	}
+	l.finishLiteral(index)
+}
+
+// jsxChildren skips the children of a JSX element up to and including its
+// closing tag, tokenizing nested elements and expressions.
+func (l *jsLexer) jsxChildren() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '<' && l.peek(1) == '/':
+			end := strings.IndexByte(l.src[l.pos:], '>')
+			if end < 0 {
+				l.advance(len(l.src))
+			} else {
+				l.advance(l.pos + end + 1)
+			}
+			return
+		case c == '<':
+			l.jsxElement()
+		case c == '{':
+			l.embedded(l.pos)
+		case c == '\n':
+			l.line++
+			l.pos++
+		default:
+			l.pos++
+		}
+	}
+}
+
+func isJSDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func isJSIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c == '$' || c == '#' || c >= 0x80
+}
+
+func isJSIdentPart(c byte) bool {
+	return isJSIdentStart(c) || isJSDigit(c)
+}
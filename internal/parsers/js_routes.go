@@ -0,0 +1,241 @@
+package parsers
+
+import "strings"
+
+// jsRouteMethods are the Express router methods that register a route, by
+// the HTTP method they register it for.
+var jsRouteMethods = map[string]string{
+	"get": "GET", "post": "POST", "put": "PUT", "patch": "PATCH",
+	"delete": "DELETE", "head": "HEAD", "options": "OPTIONS", "all": "ANY",
+}
+
+// isRouter reports whether name holds an Express application or router:
+// a variable assigned express(), express.Router() or Router(), or one
+// conventionally named app or router.
+func (p *jsParser) isRouter(name string) bool {
+	return p.routers[name] || name == "app" || name == "router" ||
+		strings.HasSuffix(name, "Router") || strings.HasSuffix(name, "router")
+}
+
+// routerAssignment records the variable assigned the express() or Router()
+// call at index i.
+func (p *jsParser) routerAssignment(i int) {
+	t := p.toks[i]
+	if t.text != "express" && t.text != "Router" {
+		return
+	}
+	j := i - 1
+	if p.is(j, ".") && p.ident(j-1) == "express" {
+		j -= 2
+	}
+	if p.is(j, "=") && p.ident(j-1) != "" {
+		p.routers[p.ident(j-1)] = true
+	}
+}
+
+// jsMount is a router mounted under a path prefix by another one:
+// app.use("/api", api).
+type jsMount struct {
+	parent, prefix string
+
+	// router is the name of the mounted router variable, and module the
+	// module it is required or imported from, if any
+	router, module string
+
+	line int
+}
+
+// jsMountDepth bounds the chain of mounts followed to compute the prefix
+// of a router, in case routers mount each other.
+const jsMountDepth = 8
+
+// route records the Express route registered by the call of the method at
+// index i: app.get("/users", list), router.post("/users", auth, create),
+// and app.route("/users").get(list).post(create).
+func (p *jsParser) route(i int) {
+	if p.toks[i].text == "use" {
+		p.mount(i)
+		return
+	}
+	method, ok := jsRouteMethods[p.toks[i].text]
+	if !ok || !p.is(i-1, ".") {
+		return
+	}
+	open := i + 1
+	closeParen := p.matching(open)
+
+	var path, router string
+	first := open + 1
+	if recv := p.ident(i - 2); recv != "" {
+		t := p.tok(first)
+		if !p.isRouter(recv) || t.kind != jsString || !strings.HasPrefix(t.text, "/") || !p.is(first+1, ",") {
+			return
+		}
+		path, router = t.text, recv
+	} else if path, router, ok = p.chainedRoute(i - 2); !ok {
+		return
+	}
+
+	// The handler is the last argument
+	last := open + 1
+	for j := open + 1; j < closeParen; j++ {
+		switch {
+		case p.is(j, "(") || p.is(j, "[") || p.is(j, "{"):
+			j = p.matching(j)
+		case p.is(j, ","):
+			last = j + 1
+		}
+	}
+	handler, receiver := p.handlerRef(last, closeParen)
+	p.result.Routes = append(p.result.Routes, Route{
+		Method:    method,
+		Path:      path,
+		Handler:   handler,
+		Receiver:  receiver,
+		Framework: "express",
+		StartLine: p.toks[i].line,
+	})
+	p.routeRouters = append(p.routeRouters, router)
+}
+
+// chainedRoute returns the path of the app.route("/users") call that the
+// route method calls chained after the ')' at index j hang off, and the
+// router it is called on.
+func (p *jsParser) chainedRoute(j int) (path, router string, ok bool) {
+	for p.is(j, ")") {
+		open := p.matchingOpen(j)
+		callee := p.ident(open - 1)
+		if !p.is(open-2, ".") {
+			return "", "", false
+		}
+		if callee == "route" && p.isRouter(p.ident(open-3)) {
+			if t := p.tok(open + 1); t.kind == jsString && p.is(open+2, ")") {
+				return t.text, p.ident(open - 3), true
+			}
+			return "", "", false
+		}
+		if _, ok := jsRouteMethods[callee]; !ok {
+			return "", "", false
+		}
+		j = open - 3
+	}
+	return "", "", false
+}
+
+// mount records the router mounted by the use() call at index i:
+// app.use("/api", auth, api) or app.use("/api", require("./api")).
+// Middleware without a path prefix mounts nothing.
+func (p *jsParser) mount(i int) {
+	parent := p.ident(i - 2)
+	if !p.is(i-1, ".") || parent == "" || !p.isRouter(parent) || !p.is(i+1, "(") {
+		return
+	}
+	t := p.tok(i + 2)
+	if t.kind != jsString || !strings.HasPrefix(t.text, "/") || !p.is(i+3, ",") {
+		return
+	}
+	closeParen := p.matching(i + 1)
+
+	// The router is the last argument
+	last := i + 4
+	for j := i + 4; j < closeParen; j++ {
+		switch {
+		case p.is(j, "(") || p.is(j, "[") || p.is(j, "{"):
+			j = p.matching(j)
+		case p.is(j, ","):
+			last = j + 1
+		}
+	}
+	m := jsMount{parent: parent, prefix: t.text, line: t.line}
+	switch {
+	case p.ident(last) != "" && last+1 == closeParen:
+		m.router = p.ident(last)
+	case p.is(last, "require") && p.is(last+1, "(") && p.tok(last+2).kind == jsString && p.is(last+3, ")") && last+4 == closeParen:
+		m.module = p.tok(last + 2).text
+	default:
+		return
+	}
+	p.mounts = append(p.mounts, m)
+}
+
+// mountPrefix returns the path prefix of the routes of a router: the
+// prefixes it is mounted under, up to the router that is not mounted.
+func (p *jsParser) mountPrefix(router string, depth int) string {
+	if router == "" || depth == jsMountDepth {
+		return ""
+	}
+	for _, m := range p.mounts {
+		if m.router == router {
+			return joinRoutePath(p.mountPrefix(m.parent, depth+1), m.prefix)
+		}
+	}
+	return ""
+}
+
+// routeMounts records the mounted routers imported from other modules.
+func (p *jsParser) routeMounts() {
+	imported := make(map[string]string)
+	for _, imp := range p.result.Imports {
+		for _, symbol := range imp.Symbols {
+			imported[symbol] = imp.ModulePath
+		}
+		if imp.Alias != "" {
+			imported[imp.Alias] = imp.ModulePath
+		}
+	}
+	for _, m := range p.mounts {
+		module := m.module
+		if module == "" {
+			module = imported[m.router]
+		}
+		if module == "" {
+			continue
+		}
+		p.result.RouteMounts = append(p.result.RouteMounts, RouteMount{
+			Prefix:     joinRoutePath(p.mountPrefix(m.parent, 0), m.prefix),
+			ModulePath: module,
+			StartLine:  m.line,
+		})
+	}
+}
+
+// joinRoutePath returns path mounted under prefix.
+func joinRoutePath(prefix, path string) string {
+	prefix = strings.TrimSuffix(prefix, "/")
+	switch {
+	case prefix == "":
+		return path
+	case path == "" || path == "/":
+		return prefix
+	}
+	return prefix + path
+}
+
+// handlerRef returns the function a handler argument between the tokens at
+// indices from and to, exclusive, refers to: list, users.list, or what a
+// wrapper call such as asyncHandler(list) wraps. Function expressions are
+// inline handlers, with no name.
+func (p *jsParser) handlerRef(from, to int) (name, receiver string) {
+	for j := from; j < to; j++ {
+		if p.is(j, "function") || p.is(j, "=>") {
+			return "", ""
+		}
+	}
+	j := from
+	for p.ident(j) != "" && p.is(j+1, ".") {
+		j += 2
+	}
+	if p.ident(j) == "" {
+		return "", ""
+	}
+	switch {
+	case j+1 == to:
+		if j > from {
+			receiver = p.ident(j - 2)
+		}
+		return p.ident(j), receiver
+	case p.is(j+1, "(") && p.matching(j+1) == to-1:
+		return p.handlerRef(j+2, to-1)
+	}
+	return "", ""
+}
@@ -0,0 +1,94 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestJavaScriptParser_Routes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Express", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`const express = require('express');
+const users = require('./controllers/users');
+const orders = require('./routes/orders');
+
+const app = express();
+const api = express.Router();
+const v1 = express.Router();
+
+function health(req, res) {
+  res.send('ok');
+}
+
+app.get('/health', health);
+api.post('/users', authenticate, users.create);
+api.get('/users/:id', asyncHandler(users.show));
+app.delete('/users/:id', function (req, res) {
+  res.status(204).end();
+});
+app.route('/books')
+  .get(listBooks)
+  .post(createBook);
+app.all('/proxy/*', proxy);
+
+app.use('/api', api);
+api.use('/v1/', authenticate, v1);
+v1.get('/', index);
+v1.use('/orders', orders);
+app.use('/admin', require('./routes/admin'));
+app.use(logger);
+cache.get('/key', value);
+app.get(path, dynamic);
+`)
+		result, err := NewJavaScriptParser().Parse("server.js", content)
+		require.NoError(t, err)
+		routes := routesByName(result)
+
+		assert.Equal(t, Route{Method: "GET", Path: "/health", Handler: "health", Framework: "express", StartLine: 13}, routes["GET /health"])
+		create := routes["POST /api/users"]
+		assert.Equal(t, "create", create.Handler, "the handler follows the middleware and the mount prefix")
+		assert.Equal(t, "users", create.Receiver)
+		assert.Equal(t, "show", routes["GET /api/users/:id"].Handler, "wrappers")
+		assert.Equal(t, "index", routes["GET /api/v1"].Handler, "nested mounts")
+		assert.Equal(t, "", routes["DELETE /users/:id"].Handler, "inline handlers")
+		assert.Equal(t, "listBooks", routes["GET /books"].Handler)
+		assert.Equal(t, "createBook", routes["POST /books"].Handler)
+		assert.Equal(t, "proxy", routes["ANY /proxy/*"].Handler)
+		assert.Len(t, result.Routes, 8, "only routers register routes, with literal paths")
+		assert.Equal(t, []RouteMount{
+			{Prefix: "/api/v1/orders", ModulePath: "./routes/orders", StartLine: 27},
+			{Prefix: "/admin", ModulePath: "./routes/admin", StartLine: 28},
+		}, result.RouteMounts)
+	})
+
+	t.Run("TypeScript", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`import { Router, Request, Response } from 'express';
+
+export const userRouter: Router = Router();
+
+export class UserController {
+  list(req: Request, res: Response): void {}
+
+  register(): void {
+    userRouter.get('/users', (req: Request, res: Response) => this.list(req, res));
+  }
+}
+`)
+		result, err := NewTypeScriptParser().Parse("routes.ts", content)
+		require.NoError(t, err)
+		require.Len(t, result.Routes, 1)
+		route := result.Routes[0]
+		assert.Equal(t, "GET", route.Method)
+		assert.Equal(t, "/users", route.Path)
+		assert.Equal(t, "", route.Handler)
+		assert.Equal(t, "register", route.Enclosing, "inline handlers belong to the enclosing symbol")
+		assert.Equal(t, "UserController", route.EnclosingClass)
+	})
+}
@@ -0,0 +1,45 @@
+package parsers
+
+import "strings"
+
+// sql records the tables created, read and written by a string or template
+// literal, if it is SQL, including the tagged templates of query builders
+// (sql`SELECT ...`). The substitutions of a template are parameters.
+func (p *jsParser) sql(t jsToken) {
+	text := t.text
+	if t.kind == jsTemplate {
+		text = templateText(p.source[t.pos:t.end])
+	}
+	tables, refs := analyzeSQLLiteral(text, t.line)
+	p.result.Tables = append(p.result.Tables, tables...)
+	p.result.TableRefs = append(p.result.TableRefs, refs...)
+}
+
+// templateText returns the text of a template literal, with its
+// substitutions replaced by '?'.
+func templateText(literal string) string {
+	literal = strings.TrimPrefix(literal, "`")
+	literal = strings.TrimSuffix(literal, "`")
+	var b strings.Builder
+	depth := 0
+	for i := 0; i < len(literal); i++ {
+		c := literal[i]
+		switch {
+		case depth == 0 && c == '\\' && i+1 < len(literal):
+			b.WriteByte(c)
+			b.WriteByte(literal[i+1])
+			i++
+		case depth == 0 && c == '$' && i+1 < len(literal) && literal[i+1] == '{':
+			b.WriteByte('?')
+			depth = 1
+			i++
+		case depth > 0 && c == '{':
+			depth++
+		case depth > 0 && c == '}':
+			depth--
+		case depth == 0:
+			b.WriteByte(c)
+		}
+	}
+	return b.String()
+}
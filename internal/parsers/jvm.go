@@ -0,0 +1,787 @@
+package parsers
+
+import (
+	"strings"
+)
+
+// jvParser holds the state shared by the Java, Kotlin and C# parsers: the
+// token stream of a file, its bracket structure, and what is known about
+// the fields of its classes when call receivers are resolved.
+type jvParser struct {
+	source string
+	toks   []jvToken
+	kotlin bool
+	csharp bool
+
+	// match maps the index of each bracket to the index of its partner
+	match []int
+
+	i      int
+	result *ParseResult
+
+	// fields maps class names to the declared types of their fields
+	fields map[string]map[string]string
+
+	// fieldCalls maps the indices of calls whose receiver may be a field of
+	// the enclosing class (this.repo.save() or repo.save()) to the field;
+	// their receivers are resolved once all classes of the file are known
+	fieldCalls map[int]string
+
+	// heritage maps class names to their index in result.Heritage
+	heritage map[string]int
+}
+
+// newJVMParser tokenizes a source file of the given dialect.
+func newJVMParser(source string, dialect jvDialect) *jvParser {
+	p := &jvParser{
+		source:     source,
+		toks:       lexJVM(source, dialect),
+		kotlin:     dialect == jvKotlin,
+		csharp:     dialect == jvCSharp,
+		fields:     make(map[string]map[string]string),
+		fieldCalls: make(map[int]string),
+		heritage:   make(map[string]int),
+		result: &ParseResult{
+			Symbols:  []ParsedSymbol{},
+			Imports:  []ImportStatement{},
+			Calls:    []CallSite{},
+			TypeRefs: []TypeAnnotation{},
+			Heritage: []ClassHeritage{},
+		},
+	}
+	p.matchBrackets()
+	return p
+}
+
+// jvScope describes the class body members are parsed in.
+type jvScope struct {
+	// class is the name of the enclosing class, interface, enum or object
+	class string
+
+	// iface is true in interfaces, whose members are public
+	iface bool
+}
+
+// jvPrimitives are the types that are never declared in a repository.
+var jvPrimitives = map[string]bool{
+	// Java
+	"int": true, "long": true, "short": true, "byte": true, "char": true,
+	"boolean": true, "float": true, "double": true, "void": true, "var": true,
+	// Kotlin
+	"Int": true, "Long": true, "Short": true, "Byte": true, "Char": true,
+	"Boolean": true, "Float": true, "Double": true, "Unit": true, "Any": true,
+	"Nothing": true, "String": true,
+	// C#
+	"string": true, "object": true, "bool": true, "decimal": true, "uint": true,
+	"ulong": true, "ushort": true, "sbyte": true, "dynamic": true, "nint": true,
+	"nuint": true,
+}
+
+// jvTypeKeywords are the keywords and modifiers that appear in types.
+var jvTypeKeywords = map[string]bool{
+	"extends": true, "super": true, "final": true, "out": true, "in": true,
+	"suspend": true, "reified": true, "crossinline": true, "noinline": true,
+	"vararg": true, "val": true, "var": true, "ref": true, "params": true,
+	"this": true, "scoped": true, "readonly": true,
+}
+
+// jvTypeWrappers are the wrappers whose type argument is the type a field
+// or variable holds: Optional<Repository>, Lazy<Repository>, Task<User>.
+var jvTypeWrappers = map[string]bool{
+	"Optional": true, "Provider": true, "Lazy": true, "AtomicReference": true,
+	"WeakReference": true, "SoftReference": true, "ThreadLocal": true,
+	"Task": true, "ValueTask": true, "Nullable": true,
+}
+
+// jvNotCalled are the keywords that precede a parenthesis or, in Kotlin, a
+// lambda without being calls.
+var jvNotCalled = map[string]bool{
+	"if": true, "while": true, "for": true, "switch": true, "catch": true,
+	"synchronized": true, "return": true, "throw": true, "when": true,
+	"try": true, "finally": true, "do": true, "else": true, "super": true,
+	"assert": true, "fun": true, "init": true, "get": true, "set": true,
+	"constructor": true, "object": true, "class": true, "interface": true,
+	"in": true, "is": true, "as": true, "new": true, "instanceof": true,
+	"by": true, "where": true, "yield": true, "case": true, "default": true,
+}
+
+// csNotCalled are the C# keywords that precede a parenthesis without being
+// calls, in addition to jvNotCalled.
+var csNotCalled = map[string]bool{
+	"foreach": true, "using": true, "lock": true, "fixed": true,
+	"typeof": true, "nameof": true, "sizeof": true, "checked": true,
+	"unchecked": true, "base": true, "stackalloc": true, "await": true,
+	"is": true, "not": true, "and": true, "or": true, "with": true,
+	"delegate": true, "operator": true,
+}
+
+// matchBrackets pairs the parentheses, brackets and braces of the file.
+// Unclosed brackets are paired with the end of the file.
+func (p *jvParser) matchBrackets() {
+	p.match = make([]int, len(p.toks))
+	var stack []int
+	for i, t := range p.toks {
+		p.match[i] = -1
+		if t.kind != jvPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			stack = append(stack, i)
+		case ")", "]", "}":
+			if n := len(stack); n > 0 {
+				p.match[stack[n-1]] = i
+				p.match[i] = stack[n-1]
+				stack = stack[:n-1]
+			}
+		}
+	}
+	for _, open := range stack {
+		p.match[open] = len(p.toks)
+	}
+}
+
+func (p *jvParser) tok(i int) jvToken {
+	if i < 0 || i >= len(p.toks) {
+		return jvToken{kind: jvEOF, pos: len(p.source), end: len(p.source)}
+	}
+	return p.toks[i]
+}
+
+// is reports whether the token at index i is the identifier or punctuator text.
+func (p *jvParser) is(i int, text string) bool {
+	t := p.tok(i)
+	return (t.kind == jvIdent || t.kind == jvPunct) && t.text == text
+}
+
+// ident returns the identifier at index i, or "".
+func (p *jvParser) ident(i int) string {
+	if t := p.tok(i); t.kind == jvIdent {
+		return t.text
+	}
+	return ""
+}
+
+// closing returns the index of the bracket closing the one at index i.
+func (p *jvParser) closing(i int) int {
+	if i < 0 || i >= len(p.toks) || p.match[i] < 0 {
+		return i
+	}
+	return p.match[i]
+}
+
+// text returns the source of the tokens at indices i through j, inclusive,
+// with whitespace collapsed.
+func (p *jvParser) text(i, j int) string {
+	start, end := p.tok(i).pos, p.tok(j).end
+	if end <= start {
+		return ""
+	}
+	return strings.Join(strings.Fields(p.source[start:end]), " ")
+}
+
+// angleEnd returns the index of the '>' closing the type parameters or
+// arguments opened at index i, or -1 when the tokens after i are not a type
+// argument list (a < b is a comparison).
+func (p *jvParser) angleEnd(i int) int {
+	depth := 0
+	for j := i; j < len(p.toks); j++ {
+		t := p.toks[j]
+		switch {
+		case p.is(j, "<"):
+			depth++
+		case p.is(j, ">"):
+			depth--
+			if depth == 0 {
+				return j
+			}
+		case p.is(j, "(") || p.is(j, "["):
+			// Annotations and function types: List<@NonNull T>, Flow<(Int) -> Unit>
+			j = p.closing(j)
+		case t.kind == jvIdent, p.is(j, ","), p.is(j, "."), p.is(j, "?"), p.is(j, "*"),
+			p.is(j, "&"), p.is(j, "@"), p.is(j, ":"), p.is(j, "->"), p.is(j, "]"):
+		default:
+			return -1
+		}
+	}
+	return -1
+}
+
+// list returns the index ranges of the comma-separated items between the
+// brackets at indices open and closeBracket; each range ends at the comma or
+// closing bracket after the item.
+func (p *jvParser) list(open, closeBracket int) [][2]int {
+	var items [][2]int
+	start := open + 1
+	for j := open + 1; j < closeBracket; j++ {
+		switch {
+		case p.is(j, "(") || p.is(j, "[") || p.is(j, "{"):
+			j = p.closing(j)
+		case p.is(j, "<"):
+			if end := p.angleEnd(j); end > 0 {
+				j = end
+			}
+		case p.is(j, ","):
+			items = append(items, [2]int{start, j})
+			start = j + 1
+		}
+	}
+	if start < closeBracket {
+		items = append(items, [2]int{start, closeBracket})
+	}
+	return items
+}
+
+// qualifiedName returns the dotted name (a.b.C) starting at index j and the
+// index after it.
+func (p *jvParser) qualifiedName(j int) (string, int) {
+	var parts []string
+	for p.ident(j) != "" {
+		parts = append(parts, p.ident(j))
+		if !p.is(j+1, ".") || p.ident(j+2) == "" {
+			j++
+			break
+		}
+		j += 2
+	}
+	return strings.Join(parts, "."), j
+}
+
+// annotations skips the annotations at the current position and returns
+// their names: @Override, @GetMapping("/users"), @org.junit.Test. Kotlin
+// use-site targets (@field:Inject, @file:JvmName) are dropped from the name.
+func (p *jvParser) annotations() []string {
+	var names []string
+	for p.is(p.i, "@") && !p.is(p.i+1, "interface") {
+		j := p.i + 1
+		if p.ident(j) != "" && p.is(j+1, ":") {
+			j += 2
+		}
+		if p.is(j, "[") {
+			// Kotlin annotation lists: @[Inject Named("x")]
+			closeBracket := p.closing(j)
+			for k := j + 1; k < closeBracket; k++ {
+				if name, end := p.qualifiedName(k); name != "" {
+					names = append(names, name)
+					k = end - 1
+					if p.is(end, "(") {
+						k = p.closing(end)
+					}
+				}
+			}
+			p.i = closeBracket + 1
+			continue
+		}
+		name, end := p.qualifiedName(j)
+		if name == "" {
+			p.i = j
+			return names
+		}
+		names = append(names, name)
+		p.i = end
+		if p.is(p.i, "<") {
+			if closeAngle := p.angleEnd(p.i); closeAngle > 0 {
+				p.i = closeAngle + 1
+			}
+		}
+		if p.is(p.i, "(") && p.tok(p.i).line == p.tok(end-1).line {
+			p.i = p.closing(p.i) + 1
+		}
+	}
+	return names
+}
+
+// define appends a symbol declared from the token at index start to the
+// token at index last.
+func (p *jvParser) define(sym ParsedSymbol, start, last int) {
+	first, end := p.tok(start), p.tok(last)
+	sym.StartLine = first.line
+	sym.EndLine = max(end.endLine, first.line)
+	if end.end > first.pos {
+		sym.Content = p.source[first.pos:end.end]
+	}
+	p.result.Symbols = append(p.result.Symbols, sym)
+}
+
+// addHeritage records that class extends and implements the given types.
+func (p *jvParser) addHeritage(class string, extends, implements []string) {
+	if len(extends) == 0 && len(implements) == 0 {
+		return
+	}
+	index, ok := p.heritage[class]
+	if !ok {
+		index = len(p.result.Heritage)
+		p.heritage[class] = index
+		p.result.Heritage = append(p.result.Heritage, ClassHeritage{ClassName: class})
+	}
+	h := &p.result.Heritage[index]
+	h.Extends = append(h.Extends, extends...)
+	h.Implements = append(h.Implements, implements...)
+}
+
+// superclass returns the class class extends, or "".
+func (p *jvParser) superclass(class string) string {
+	if index, ok := p.heritage[class]; ok && len(p.result.Heritage[index].Extends) > 0 {
+		return p.result.Heritage[index].Extends[0]
+	}
+	return ""
+}
+
+// typeName returns the name of the type between the tokens at indices from
+// and to, exclusive: the last segment of its qualified name, without type
+// arguments (java.util.List<User> is List).
+func (p *jvParser) typeName(from, to int) string {
+	name := ""
+	for j := from; j < to; j++ {
+		switch {
+		case p.is(j, "@"):
+			// Type annotations: @NonNull User
+			_, end := p.qualifiedName(j + 1)
+			j = end - 1
+			if p.is(end, "(") {
+				j = p.closing(end)
+			}
+		case p.is(j, "<"):
+			if end := p.angleEnd(j); end > 0 {
+				return name
+			}
+		case p.ident(j) != "" && !jvTypeKeywords[p.ident(j)]:
+			name = p.ident(j)
+			if !p.is(j+1, ".") {
+				return name
+			}
+		case p.is(j, "."):
+		default:
+			return name
+		}
+	}
+	return name
+}
+
+// typeRefs records the types named between the tokens at indices from and
+// to, exclusive, and returns the main one: the type a field, parameter or
+// variable with the type holds.
+func (p *jvParser) typeRefs(from, to int, role, enclosing, class string) string {
+	main := ""
+	for j := from; j < to && j < len(p.toks); j++ {
+		t := p.toks[j]
+		if p.is(j, "@") {
+			_, end := p.qualifiedName(j + 1)
+			j = end - 1
+			if p.is(end, "(") {
+				j = p.closing(end)
+			}
+			continue
+		}
+		if t.kind != jvIdent || jvPrimitives[t.text] || jvTypeKeywords[t.text] || p.is(j+1, ".") {
+			continue
+		}
+		// Kotlin function type parameter names: (user: User) -> Unit
+		if p.kotlin && p.is(j+1, ":") {
+			continue
+		}
+		p.result.TypeRefs = append(p.result.TypeRefs, TypeAnnotation{
+			Name:           t.text,
+			Role:           role,
+			StartLine:      t.line,
+			Enclosing:      enclosing,
+			EnclosingClass: class,
+		})
+		if main == "" && !jvTypeWrappers[t.text] {
+			main = t.text
+		}
+	}
+	return main
+}
+
+// supertypes returns the names of the types listed between the tokens at
+// indices from and to, exclusive, separated by commas. In Kotlin, the ones
+// followed by constructor arguments are classes; the others are interfaces.
+func (p *jvParser) supertypes(from, to int) (classes, interfaces []string) {
+	start := from
+	for j := from; j <= to; j++ {
+		switch {
+		case j < to && (p.is(j, "(") || p.is(j, "[") || p.is(j, "{")):
+			j = p.closing(j)
+		case j < to && p.is(j, "<"):
+			if end := p.angleEnd(j); end > 0 {
+				j = end
+			}
+		case j == to || p.is(j, ","):
+			if name := p.typeName(start, j); name != "" {
+				// Kotlin delegation: Repository by repo
+				called := false
+				for k := start; k < j; k++ {
+					if p.is(k, "(") {
+						called = true
+					}
+					if p.is(k, "by") {
+						break
+					}
+				}
+				if called {
+					classes = append(classes, name)
+				} else {
+					interfaces = append(interfaces, name)
+				}
+			}
+			start = j + 1
+		}
+	}
+	return classes, interfaces
+}
+
+// addField records the declared type of a field of class.
+func (p *jvParser) addField(class, name, typ string) {
+	if class == "" || name == "" || typ == "" {
+		return
+	}
+	if p.fields[class] == nil {
+		p.fields[class] = make(map[string]string)
+	}
+	p.fields[class][name] = typ
+}
+
+// calls records the calls between the tokens at indices from and to,
+// exclusive, as made by the function enclosing (a method of class). vars
+// holds the declared types of the parameters and is extended by the local
+// variables declared in the body.
+func (p *jvParser) calls(from, to int, enclosing, class string, vars map[string]string) {
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	for j := from + 1; j < to && j < len(p.toks); j++ {
+		p.local(j, vars)
+
+		name := p.ident(j)
+		if name == "" || jvNotCalled[name] || p.csharp && csNotCalled[name] || p.is(j-1, "@") || p.is(j-1, "fun") {
+			continue
+		}
+
+		// Method references: User::find, this::handle, ::main
+		if p.is(j-1, "::") {
+			if p.is(j+1, "(") || name == "class" || name == "new" {
+				continue
+			}
+			call := CallSite{
+				Name:           name,
+				StartLine:      p.tok(j).line,
+				EndLine:        p.tok(j).line,
+				Enclosing:      enclosing,
+				EnclosingClass: class,
+			}
+			if owner := p.ident(j - 2); owner == "this" {
+				call.Receiver = class
+			} else if owner != "" && isUpper(owner[0]) {
+				call.Receiver = owner
+			} else if owner != "" {
+				call.Receiver = p.receiver(j-2, vars, class, len(p.result.Calls))
+			}
+			p.result.Calls = append(p.result.Calls, call)
+			continue
+		}
+
+		open := j + 1
+		if p.is(open, "<") && (p.kotlin || p.csharp || p.is(j-1, "new") || p.is(j-1, ".")) {
+			// Generic calls: new ArrayList<>(), emptyList<String>(), GetService<T>()
+			if end := p.angleEnd(open); end > 0 {
+				open = end + 1
+			}
+		}
+		switch {
+		case p.is(open, "(") && p.declaration(j, open):
+			// Methods of anonymous classes: new Runnable() { public void run() { .. } }
+			continue
+		case p.is(open, "("):
+		case p.kotlin && p.is(open, "{") && p.trailingLambda(j):
+		default:
+			continue
+		}
+
+		call := CallSite{
+			Name:           name,
+			StartLine:      p.tok(j).line,
+			EndLine:        p.tok(p.closing(open)).line,
+			Enclosing:      enclosing,
+			EnclosingClass: class,
+		}
+		switch {
+		case name == "this":
+			// Constructor delegation: this(id, name)
+			if class == "" {
+				continue
+			}
+			call.Name = class
+			if p.kotlin {
+				call.Name = "constructor"
+			}
+			call.Receiver = class
+		case p.is(j-1, ".") || p.is(j-1, "?."):
+			call.Receiver = p.receiver(j-2, vars, class, len(p.result.Calls))
+		}
+		p.result.Calls = append(p.result.Calls, call)
+	}
+}
+
+// declaration reports whether the Java or C# name at index j, followed by
+// the parenthesis at index open, declares a method rather than calling one:
+// the parameters are followed by its body, a throws clause, or, for C#
+// local functions, an expression body or constraints.
+func (p *jvParser) declaration(j, open int) bool {
+	if p.kotlin || p.is(j-1, "new") || p.is(j-1, ".") {
+		return false
+	}
+	after := p.closing(open) + 1
+	if p.csharp {
+		return p.is(after, "{") || p.is(after, "=>") && p.ident(j-1) != "" || p.is(after, "where")
+	}
+	return p.is(after, "{") || p.is(after, "throws")
+}
+
+// trailingLambda reports whether the name at index j, followed by a brace,
+// is a Kotlin call with a trailing lambda (items.forEach { .. },
+// transaction { .. }) rather than a declaration or a block.
+func (p *jvParser) trailingLambda(j int) bool {
+	name := p.ident(j)
+	if name == "" || isUpper(name[0]) {
+		return false
+	}
+	prev := p.tok(j - 1)
+	if prev.kind == jvIdent {
+		return prev.text == "return" || prev.endLine < p.tok(j).line
+	}
+	switch prev.text {
+	case ".", "?.", "=", "(", ",", "->", "{", "}", ";", "?:", "&&", "||":
+		return true
+	}
+	return prev.endLine < p.tok(j).line
+}
+
+// local records the type of the local variable declared at index j:
+// Java's User user = ..., var user = new User(...), and Kotlin's
+// val user: User and val user = User(...).
+func (p *jvParser) local(j int, vars map[string]string) {
+	if p.kotlin {
+		if !p.is(j, "val") && !p.is(j, "var") {
+			return
+		}
+		name := p.ident(j + 1)
+		if name == "" {
+			return
+		}
+		switch {
+		case p.is(j+2, ":"):
+			end := j + 3
+			for end < len(p.toks) && (p.ident(end) != "" || p.is(end, ".") || p.is(end, "?") || p.is(end, "<")) {
+				if p.is(end, "<") {
+					if closeAngle := p.angleEnd(end); closeAngle > 0 {
+						end = closeAngle
+					}
+				}
+				end++
+			}
+			if typ := p.mainType(j+3, end); typ != "" {
+				vars[name] = typ
+			}
+		case p.is(j+2, "="):
+			if typ := p.constructed(j + 3); typ != "" {
+				vars[name] = typ
+			}
+		}
+		return
+	}
+
+	// var user = new User(...)
+	if p.is(j, "var") && p.ident(j+1) != "" && p.is(j+2, "=") {
+		if typ := p.constructed(j + 3); typ != "" {
+			vars[p.ident(j+1)] = typ
+		}
+		return
+	}
+
+	// User user = ..., List<User> users;, for (User user : users)
+	typ := p.ident(j)
+	if typ == "" || !isUpper(typ[0]) || p.is(j-1, ".") || p.is(j-1, "new") {
+		return
+	}
+	k := j + 1
+	if p.is(k, "<") {
+		end := p.angleEnd(k)
+		if end < 0 {
+			return
+		}
+		k = end + 1
+	}
+	for p.is(k, "[") && p.is(k+1, "]") || p.csharp && p.is(k, "?") {
+		// Arrays and C# nullable types: User[] users, User? user
+		if p.is(k, "?") {
+			k++
+			continue
+		}
+		k += 2
+	}
+	name := p.ident(k)
+	if name == "" || jvNotCalled[name] {
+		return
+	}
+	switch {
+	case p.is(k+1, "="), p.is(k+1, ";"), p.is(k+1, ":"), p.is(k+1, ","), p.is(k+1, ")"):
+		if main := p.mainType(j, k); main != "" {
+			vars[name] = main
+		}
+	}
+}
+
+// constructed returns the type of the object the expression at index j
+// creates: new User(...) in Java, User(...) in Kotlin, or "".
+func (p *jvParser) constructed(j int) string {
+	if !p.kotlin {
+		if !p.is(j, "new") {
+			return ""
+		}
+		j++
+	}
+	name, end := p.qualifiedName(j)
+	if name == "" {
+		return ""
+	}
+	last := p.ident(end - 1)
+	if p.is(end, "<") {
+		if closeAngle := p.angleEnd(end); closeAngle > 0 {
+			end = closeAngle + 1
+		}
+	}
+	if !p.is(end, "(") || !isUpper(last[0]) {
+		return ""
+	}
+	return last
+}
+
+// mainType returns the type a variable of the type between the tokens at
+// indices from and to, exclusive, holds.
+func (p *jvParser) mainType(from, to int) string {
+	for j := from; j < to; j++ {
+		name := p.ident(j)
+		if name == "" || jvPrimitives[name] || jvTypeKeywords[name] || p.is(j+1, ".") || jvTypeWrappers[name] {
+			continue
+		}
+		return name
+	}
+	return ""
+}
+
+// receiver returns the type of the receiver expression ending at index j:
+// the class for this, the superclass for super (base in C#), the declared
+// type of a variable, or the last name of the expression. Names that may be
+// fields of the enclosing class are recorded in fieldCalls under the index
+// of the call being built.
+func (p *jvParser) receiver(j int, vars map[string]string, class string, index int) string {
+	name := p.ident(j)
+	if name == "" {
+		return ""
+	}
+	if p.is(j-1, ".") || p.is(j-1, "?.") {
+		// this.repo.save()
+		if p.is(j-2, "this") && !p.is(j-3, ".") {
+			p.fieldCalls[index] = name
+		}
+		return name
+	}
+	switch {
+	case name == "this":
+		return class
+	case name == "super", name == "base" && p.csharp:
+		return p.superclass(class)
+	}
+	if typ := vars[name]; typ != "" {
+		return typ
+	}
+	p.fieldCalls[index] = name
+	return name
+}
+
+// finish resolves the receivers of calls on fields of the enclosing class
+// to the declared types of the fields.
+func (p *jvParser) finish() {
+	for index, field := range p.fieldCalls {
+		call := &p.result.Calls[index]
+		if typ := p.fields[call.EnclosingClass][field]; typ != "" {
+			call.Receiver = typ
+		}
+	}
+}
+
+// skipStatement moves past the ';' ending the statement at the current
+// position.
+func (p *jvParser) skipStatement() {
+	for p.i < len(p.toks) && !p.is(p.i, ";") {
+		if p.is(p.i, "(") || p.is(p.i, "[") || p.is(p.i, "{") {
+			p.i = p.closing(p.i)
+		}
+		p.i++
+	}
+	p.i++
+}
+
+// parameters records the types of the parameters between the parentheses
+// at indices open and closeParen and returns the declared types of the
+// named ones. C# parameters may carry attributes ([FromBody] User user) and
+// default values (int limit = 10).
+func (p *jvParser) parameters(open, closeParen int, method, class string) map[string]string {
+	vars := make(map[string]string)
+	for _, item := range p.list(open, closeParen) {
+		for p.is(item[0], "[") {
+			item[0] = p.closing(item[0]) + 1
+		}
+		for k := item[0]; k < item[1]; k++ {
+			if p.is(k, "=") {
+				item[1] = k
+				break
+			}
+		}
+		nameIndex := item[1] - 1
+		for p.is(nameIndex, "]") {
+			// Old-style array parameters: String args[]
+			nameIndex = p.closing(nameIndex) - 1
+		}
+		name := p.ident(nameIndex)
+		if name == "" || nameIndex == item[0] {
+			continue
+		}
+		if typ := p.typeRefs(item[0], nameIndex, "param", method, class); typ != "" && name != "this" {
+			vars[name] = typ
+		}
+	}
+	return vars
+}
+
+// field parses the field declaration whose type starts at the current
+// position; the token at index j follows the first declared name.
+func (p *jvParser) field(typeStart, j int, scope jvScope) {
+	nameIndex := j - 1
+	for p.is(nameIndex, "]") {
+		nameIndex = p.closing(nameIndex) - 1
+	}
+	typ := p.typeRefs(typeStart, nameIndex, "field", scope.class, "")
+	p.addField(scope.class, p.ident(nameIndex), typ)
+
+	// Further declarators and initializers run to the ';'
+	for p.i = j; p.i < len(p.toks) && !p.is(p.i, ";") && !p.is(p.i, "}"); p.i++ {
+		switch {
+		case p.is(p.i, "="):
+			from := p.i
+			for p.i+1 < len(p.toks) && !p.is(p.i+1, ";") && !p.is(p.i+1, ",") && !p.is(p.i+1, "}") {
+				p.i++
+				if p.is(p.i, "(") || p.is(p.i, "[") || p.is(p.i, "{") {
+					p.i = p.closing(p.i)
+				}
+			}
+			p.calls(from, p.i+1, "", scope.class, nil)
+		case p.is(p.i, ","):
+			p.addField(scope.class, p.ident(p.i+1), typ)
+		}
+	}
+	if p.is(p.i, ";") {
+		p.i++
+	}
+}
@@ -0,0 +1,351 @@
+package parsers
+
+import "strings"
+
+// jvDialect is the language a jvLexer or jvParser handles. C# is close
+// enough to Java to share the tokenizer and the scanning of calls.
+type jvDialect int
+
+const (
+	jvJava jvDialect = iota
+	jvKotlin
+	jvCSharp
+)
+
+// jvTokenKind is the kind of a Java, Kotlin or C# token.
+type jvTokenKind int
+
+const (
+	jvEOF jvTokenKind = iota
+	jvIdent
+	jvPunct
+	jvString
+	jvChar
+	jvNumber
+)
+
+// jvToken is a token of Java, Kotlin or C# source.
+type jvToken struct {
+	kind jvTokenKind
+
+	// text is the token text; Kotlin identifiers in backticks and C#
+	// verbatim identifiers (@class) lose their quoting
+	text string
+
+	// pos and end are the byte offsets of the token in the source
+	pos, end int
+
+	// line and endLine are the 1-based lines of pos and end, which differ
+	// for text blocks and multi-line strings
+	line, endLine int
+}
+
+// jvPunctuators are the multi-character punctuators the parsers need,
+// longest first. As in Rust, angle brackets are always single tokens, so
+// that the '>' closing nested generics (Map<K, List<V>>) is never part of a
+// shift.
+var jvPunctuators = []string{"...", "..<", "===", "!==", "??=", "->", "=>", "::", "?.", "?:", "??", "!!", "..", "==", "!=", "&&", "||"}
+
+// jvLexer splits Java, Kotlin or C# source into tokens, skipping
+// whitespace and comments. Strings cover escapes, Java text blocks, Kotlin
+// and C# raw strings and C# verbatim strings; Kotlin string templates
+// (${...}) and C# interpolations ({...}) may contain strings of their own.
+// Block comments nest in Kotlin only. C# preprocessor directives are
+// skipped like comments.
+type jvLexer struct {
+	src    string
+	pos    int
+	line   int
+	kotlin bool
+	csharp bool
+	toks   []jvToken
+}
+
+// lexJVM tokenizes source of the given dialect.
+func lexJVM(source string, dialect jvDialect) []jvToken {
+	l := &jvLexer{src: source, line: 1, kotlin: dialect == jvKotlin, csharp: dialect == jvCSharp}
+	for l.pos < len(l.src) {
+		l.token()
+	}
+	return l.toks
+}
+
+// emit appends a token spanning src[start:end] and moves past it.
+func (l *jvLexer) emit(kind jvTokenKind, text string, start, end int) {
+	lines := strings.Count(l.src[start:end], "\n")
+	l.toks = append(l.toks, jvToken{kind: kind, text: text, pos: start, end: end, line: l.line, endLine: l.line + lines})
+	l.line += lines
+	l.pos = end
+}
+
+// token lexes the token at the current position.
+func (l *jvLexer) token() {
+	c := l.src[l.pos]
+	rest := l.src[l.pos:]
+	switch {
+	case c == '\n':
+		l.line++
+		l.pos++
+	case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+		l.pos++
+	case strings.HasPrefix(rest, "//"):
+		if i := strings.IndexByte(rest, '\n'); i >= 0 {
+			l.pos += i
+		} else {
+			l.pos = len(l.src)
+		}
+	case strings.HasPrefix(rest, "/*"):
+		l.blockComment()
+	case l.csharp && c == '#' && l.lineStart():
+		if i := strings.IndexByte(rest, '\n'); i >= 0 {
+			l.pos += i
+		} else {
+			l.pos = len(l.src)
+		}
+	case l.csharp && (c == '@' || c == '$'):
+		l.csharpPrefixed()
+	case strings.HasPrefix(rest, `"""`) && l.csharp:
+		end := l.rawString(l.pos)
+		l.emit(jvString, l.src[l.pos:end], l.pos, end)
+	case strings.HasPrefix(rest, `"""`):
+		end := l.textBlock(l.pos + 3)
+		l.emit(jvString, l.src[l.pos+3:max(end-3, l.pos+3)], l.pos, end)
+	case c == '"':
+		end := l.stringEnd(l.pos + 1)
+		l.emit(jvString, l.src[l.pos+1:max(end-1, l.pos+1)], l.pos, end)
+	case c == '\'':
+		end := l.pos + 1
+		for end < len(l.src) && l.src[end] != '\'' && l.src[end] != '\n' {
+			if l.src[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		end = min(end+1, len(l.src))
+		l.emit(jvChar, l.src[l.pos:end], l.pos, end)
+	case c == '`' && l.kotlin:
+		end := strings.IndexByte(rest[1:], '`')
+		if end < 0 || strings.Contains(rest[1:end+1], "\n") {
+			l.emit(jvPunct, "`", l.pos, l.pos+1)
+			return
+		}
+		l.emit(jvIdent, rest[1:end+1], l.pos, l.pos+end+2)
+	case isJSDigit(c) || c == '.' && len(rest) > 1 && isJSDigit(rest[1]) && !l.kotlin:
+		end := l.pos + 1
+		hex := strings.HasPrefix(rest, "0x") || strings.HasPrefix(rest, "0X")
+		for end < len(l.src) {
+			d := l.src[end]
+			if isPyNamePart(d) || (d == '+' || d == '-') && !hex && (l.src[end-1] == 'e' || l.src[end-1] == 'E') {
+				end++
+				continue
+			}
+			// 1.5 is a number, 1..5 a Kotlin range
+			if d == '.' && end+1 < len(l.src) && isJSDigit(l.src[end+1]) && !strings.Contains(l.src[l.pos:end], ".") {
+				end++
+				continue
+			}
+			break
+		}
+		l.emit(jvNumber, l.src[l.pos:end], l.pos, end)
+	case isPyNameStart(c) || c == '$':
+		end := l.pos + 1
+		for end < len(l.src) && (isPyNamePart(l.src[end]) || l.src[end] == '$') {
+			end++
+		}
+		l.emit(jvIdent, l.src[l.pos:end], l.pos, end)
+	default:
+		for _, punct := range jvPunctuators {
+			if strings.HasPrefix(rest, punct) {
+				l.emit(jvPunct, punct, l.pos, l.pos+len(punct))
+				return
+			}
+		}
+		l.emit(jvPunct, rest[:1], l.pos, l.pos+1)
+	}
+}
+
+// lineStart reports whether only whitespace precedes the current position
+// on its line.
+func (l *jvLexer) lineStart() bool {
+	for i := l.pos - 1; i >= 0 && l.src[i] != '\n'; i-- {
+		if l.src[i] != ' ' && l.src[i] != '\t' && l.src[i] != '\r' {
+			return false
+		}
+	}
+	return true
+}
+
+// csharpPrefixed lexes the C# token starting with '@' or '$' at the current
+// position: a verbatim identifier (@class), a verbatim string (@"..."), an
+// interpolated string ($"...", $@"...") or an interpolated raw string
+// ($"""...""").
+func (l *jvLexer) csharpPrefixed() {
+	start := l.pos
+	i := l.pos
+	verbatim, interpolated := false, false
+	for i < len(l.src) && (l.src[i] == '@' || l.src[i] == '$') {
+		verbatim = verbatim || l.src[i] == '@'
+		interpolated = interpolated || l.src[i] == '$'
+		i++
+	}
+	switch {
+	case strings.HasPrefix(l.src[i:], `"""`):
+		end := l.rawString(i)
+		l.emit(jvString, l.src[i:end], start, end)
+	case i < len(l.src) && l.src[i] == '"':
+		end := l.csharpString(i+1, verbatim, interpolated)
+		l.emit(jvString, l.src[i+1:max(end-1, i+1)], start, end)
+	case verbatim && !interpolated && i < len(l.src) && isPyNameStart(l.src[i]):
+		end := i + 1
+		for end < len(l.src) && isPyNamePart(l.src[end]) {
+			end++
+		}
+		l.emit(jvIdent, l.src[i:end], start, end)
+	default:
+		l.emit(jvPunct, l.src[start:start+1], start, start+1)
+	}
+}
+
+// csharpString returns the index after the closing quote of the C# string
+// whose contents start at index i. Verbatim strings have no escapes and
+// double their quotes; interpolations ({...}, but not {{) may contain
+// strings of their own.
+func (l *jvLexer) csharpString(i int, verbatim, interpolated bool) int {
+	for i < len(l.src) {
+		switch c := l.src[i]; {
+		case c == '\\' && !verbatim:
+			i += 2
+		case c == '"' && verbatim && i+1 < len(l.src) && l.src[i+1] == '"':
+			i += 2
+		case c == '"':
+			return i + 1
+		case c == '\n' && !verbatim:
+			return i
+		case c == '{' && interpolated && i+1 < len(l.src) && l.src[i+1] == '{':
+			i += 2
+		case c == '{' && interpolated:
+			i = l.templateEnd(i)
+		default:
+			i++
+		}
+	}
+	return len(l.src)
+}
+
+// rawString returns the index after the C# raw string whose opening quotes
+// start at index i. It closes with as many quotes as it opened with.
+func (l *jvLexer) rawString(i int) int {
+	quotes := 0
+	for i+quotes < len(l.src) && l.src[i+quotes] == '"' {
+		quotes++
+	}
+	closing := strings.Repeat(`"`, quotes)
+	if end := strings.Index(l.src[i+quotes:], closing); end >= 0 {
+		return i + quotes + end + quotes
+	}
+	return len(l.src)
+}
+
+// blockComment skips a block comment, which may contain nested ones in
+// Kotlin.
+func (l *jvLexer) blockComment() {
+	depth := 0
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], "/*") && (depth == 0 || l.kotlin):
+			depth++
+			l.pos += 2
+		case strings.HasPrefix(l.src[l.pos:], "*/"):
+			depth--
+			l.pos += 2
+			if depth == 0 {
+				return
+			}
+		default:
+			if l.src[l.pos] == '\n' {
+				l.line++
+			}
+			l.pos++
+		}
+	}
+}
+
+// stringEnd returns the index after the closing quote of the string whose
+// contents start at index i. Strings end at the end of the line when they
+// are not closed.
+func (l *jvLexer) stringEnd(i int) int {
+	for i < len(l.src) {
+		switch c := l.src[i]; {
+		case c == '\\':
+			i += 2
+		case c == '"':
+			return i + 1
+		case c == '\n':
+			return i
+		case c == '$' && l.kotlin && i+1 < len(l.src) && l.src[i+1] == '{':
+			i = l.templateEnd(i + 1)
+		default:
+			i++
+		}
+	}
+	return len(l.src)
+}
+
+// textBlock returns the index after the closing quotes of the Java text
+// block or Kotlin raw string whose contents start at index i. Kotlin raw
+// strings have no escapes, and a closing """ may be preceded by more
+// quotes that belong to the string.
+func (l *jvLexer) textBlock(i int) int {
+	for i < len(l.src) {
+		switch c := l.src[i]; {
+		case c == '\\' && !l.kotlin:
+			i += 2
+		case strings.HasPrefix(l.src[i:], `"""`):
+			end := i + 3
+			for l.kotlin && end < len(l.src) && l.src[end] == '"' {
+				end++
+			}
+			return end
+		case c == '$' && l.kotlin && i+1 < len(l.src) && l.src[i+1] == '{':
+			i = l.templateEnd(i + 1)
+		default:
+			i++
+		}
+	}
+	return len(l.src)
+}
+
+// templateEnd returns the index after the brace closing the Kotlin template
+// expression or C# interpolation whose opening brace is at index i.
+func (l *jvLexer) templateEnd(i int) int {
+	depth := 0
+	for i < len(l.src) {
+		switch c := l.src[i]; {
+		case c == '{':
+			depth++
+			i++
+		case c == '}':
+			depth--
+			i++
+			if depth == 0 {
+				return i
+			}
+		case strings.HasPrefix(l.src[i:], `"""`):
+			i = l.textBlock(i + 3)
+		case c == '"':
+			i = l.stringEnd(i + 1)
+		case c == '\'':
+			i++
+			for i < len(l.src) && l.src[i] != '\'' && l.src[i] != '\n' {
+				if l.src[i] == '\\' {
+					i++
+				}
+				i++
+			}
+			i++
+		default:
+			i++
+		}
+	}
+	return len(l.src)
+}
@@ -0,0 +1,646 @@
+package parsers
+
+import (
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// KotlinParser parses Kotlin source code and Kotlin scripts.
+//
+// It shares the tokenizer and call scanning with the Java parser. Classes,
+// objects, interfaces, enum classes and type aliases are symbols, as are
+// functions; functions in a class body, including those of its companion
+// object, are methods of the class. Statements end at line breaks the way
+// Kotlin's grammar decides, so expression bodies and properties need no
+// semicolons. The val and var parameters of primary constructors are
+// fields. Supertypes called with constructor arguments are extended;
+// the others are implemented. The override modifier is recorded as a
+// decorator next to the annotations.
+type KotlinParser struct{}
+
+// NewKotlinParser creates a new Kotlin parser.
+func NewKotlinParser() *KotlinParser {
+	return &KotlinParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *KotlinParser) Language() string {
+	return "kotlin"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *KotlinParser) SupportsFile(filename string) bool {
+	return strings.HasSuffix(filename, ".kt") || strings.HasSuffix(filename, ".kts")
+}
+
+// Parse parses Kotlin source code and extracts symbols, imports, calls, etc.
+func (p *KotlinParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	kp := &ktParser{jvParser: newJVMParser(string(content), jvKotlin)}
+	kp.members(len(kp.toks), jvScope{})
+	kp.finish()
+	setDocComments(kp.result, string(content), slashDocComments)
+	return kp.result, nil
+}
+
+// ktModifiers are the modifiers of Kotlin declarations.
+var ktModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "internal": true,
+	"open": true, "final": true, "abstract": true, "sealed": true,
+	"data": true, "enum": true, "annotation": true, "inner": true,
+	"override": true, "lateinit": true, "const": true, "suspend": true,
+	"inline": true, "tailrec": true, "operator": true, "infix": true,
+	"external": true, "expect": true, "actual": true, "value": true,
+	"companion": true, "noinline": true, "crossinline": true, "vararg": true,
+	"reified": true,
+}
+
+// ktContinuations are the tokens that continue an expression on the next
+// line when they end a line (a +) or start the next one (.map { }).
+var (
+	ktContinuesAfter = map[string]bool{
+		"=": true, "+": true, "-": true, "*": true, "/": true, "%": true,
+		"&&": true, "||": true, "?:": true, ".": true, "?.": true, ",": true,
+		"->": true, ":": true, "==": true, "!=": true, "===": true, "!==": true,
+		"..": true, "..<": true, "is": true, "as": true, "in": true, "by": true,
+	}
+	ktContinuesBefore = map[string]bool{
+		".": true, "?.": true, "?:": true, "&&": true, "||": true, "as": true,
+		"else": true, "catch": true, "finally": true, "by": true, "where": true,
+	}
+)
+
+// ktParser parses the token stream of a Kotlin file.
+type ktParser struct {
+	*jvParser
+}
+
+// ktModifierSet holds the modifiers of a declaration.
+type ktModifierSet struct {
+	public    bool
+	override  bool
+	enum      bool
+	companion bool
+	isAnnot   bool
+}
+
+// members parses the declarations from the current position to the token
+// at index end, which closes their body (or the end of the file).
+func (p *ktParser) members(end int, scope jvScope) {
+	for p.i < end && p.i < len(p.toks) {
+		if p.is(p.i, ";") {
+			p.i++
+			continue
+		}
+		annotations := p.annotations()
+		if p.i >= end {
+			return
+		}
+		start := p.i
+		mods := ktModifierSet{public: true}
+		for {
+			if p.is(p.i, "@") {
+				at := p.i
+				annotations = append(annotations, p.annotations()...)
+				if p.i == at {
+					// A stray @ before interface, which Kotlin has no
+					// annotation declaration for
+					p.i++
+				}
+				continue
+			}
+			name := p.ident(p.i)
+			next := p.tok(p.i + 1)
+			if !ktModifiers[name] && !(name == "fun" && p.is(p.i+1, "interface")) || next.kind != jvIdent && !p.is(p.i+1, "@") {
+				break
+			}
+			switch name {
+			case "private", "protected", "internal":
+				mods.public = false
+			case "override":
+				mods.override = true
+			case "enum":
+				mods.enum = true
+			case "companion":
+				mods.companion = true
+			case "annotation":
+				mods.isAnnot = true
+			}
+			p.i++
+		}
+		if mods.override {
+			annotations = append(annotations, "override")
+		}
+		if scope.iface && p.ident(start) != "private" {
+			mods.public = true
+		}
+
+		switch {
+		case p.is(p.i, "package") && scope.class == "":
+			name, next := p.qualifiedName(p.i + 1)
+			p.result.Package = name
+			p.i = next
+		case p.is(p.i, "import") && scope.class == "":
+			p.importDirective()
+		case p.is(p.i, "class") || p.is(p.i, "interface"):
+			p.classDeclaration(start, mods, annotations)
+		case p.is(p.i, "object") && mods.companion:
+			p.companion(scope)
+		case p.is(p.i, "object") && p.ident(p.i+1) != "":
+			p.classDeclaration(start, mods, annotations)
+		case p.is(p.i, "fun"):
+			p.function(start, mods.public, annotations, scope)
+		case p.is(p.i, "val") || p.is(p.i, "var"):
+			p.property(scope)
+		case p.is(p.i, "typealias") && p.ident(p.i+1) != "":
+			p.typeAlias(start, mods.public)
+		case p.is(p.i, "init") && p.is(p.i+1, "{"):
+			closeBrace := p.closing(p.i + 1)
+			p.calls(p.i+1, closeBrace, "", scope.class, nil)
+			p.i = closeBrace + 1
+		case p.is(p.i, "constructor") && p.is(p.i+1, "(") && scope.class != "":
+			p.constructor(start, mods.public, annotations, scope)
+		case p.is(p.i, "}"):
+			// A stray closing brace: leave it to the enclosing block
+			p.i++
+		default:
+			// Statements of scripts and enum entries without members
+			last := p.statementEnd(p.i)
+			p.calls(p.i-1, last+1, "", scope.class, nil)
+			p.i = last + 1
+		}
+	}
+}
+
+// statementEnd returns the index of the last token of the statement or
+// expression starting at index i. It ends at a ';', at the bracket closing
+// the enclosing block, or at a line break that Kotlin does not continue
+// across.
+func (p *ktParser) statementEnd(i int) int {
+	j := i
+	for j < len(p.toks) {
+		if p.is(j, "(") || p.is(j, "[") || p.is(j, "{") {
+			j = p.closing(j)
+		}
+		next := j + 1
+		if next >= len(p.toks) || p.is(next, ";") || p.is(next, "}") || p.is(next, ")") || p.is(next, "]") {
+			return min(j, len(p.toks)-1)
+		}
+		if p.tok(next).line > p.tok(j).endLine && !p.continues(j, next) {
+			return j
+		}
+		j = next
+	}
+	return len(p.toks) - 1
+}
+
+// continues reports whether the expression ending a line with the token at
+// index j continues with the token at index next.
+func (p *ktParser) continues(j, next int) bool {
+	prev, t := p.tok(j), p.tok(next)
+	if prev.kind == jvPunct || prev.kind == jvIdent {
+		if ktContinuesAfter[prev.text] {
+			return true
+		}
+	}
+	return (t.kind == jvPunct || t.kind == jvIdent) && ktContinuesBefore[t.text]
+}
+
+// importDirective parses import a.b.C [as D] and import a.b.*.
+func (p *ktParser) importDirective() {
+	line := p.tok(p.i).line
+	name, end := p.qualifiedName(p.i + 1)
+	symbol := ""
+	if p.is(end, ".") && p.is(end+1, "*") {
+		symbol = "*"
+		end += 2
+	} else if dot := strings.LastIndexByte(name, '.'); dot >= 0 {
+		name, symbol = name[:dot], name[dot+1:]
+	}
+	alias := ""
+	if p.is(end, "as") {
+		alias = p.ident(end + 1)
+		end += 2
+	}
+	if name != "" && symbol != "" {
+		p.result.Imports = append(p.result.Imports, ImportStatement{
+			ModulePath: name,
+			Symbols:    []string{symbol},
+			Alias:      alias,
+			StartLine:  line,
+		})
+	}
+	p.i = end
+}
+
+// classDeclaration parses the class, interface or object whose keyword is
+// at the current position.
+func (p *ktParser) classDeclaration(start int, mods ktModifierSet, annotations []string) {
+	keyword := p.i
+	name := p.ident(keyword + 1)
+	if name == "" {
+		p.i = keyword + 1
+		return
+	}
+	kind := graph.NodeClass
+	iface := p.is(keyword, "interface")
+	switch {
+	case iface || mods.isAnnot:
+		kind = graph.NodeInterface
+	case mods.enum:
+		kind = graph.NodeEnum
+	}
+
+	// The header runs to the body or the end of the declaration
+	j := keyword + 2
+	if p.is(j, "<") {
+		if end := p.angleEnd(j); end > 0 {
+			j = end + 1
+		}
+	}
+	var primary [2]int
+	superStart := -1
+	headerEnd := j - 1
+	for ; j < len(p.toks); j++ {
+		if p.is(j, "{") || p.is(j, "}") || p.is(j, ";") {
+			break
+		}
+		if p.tok(j).line > p.tok(j-1).endLine && !p.continues(j-1, j) && !p.is(j, ":") && !p.is(j, "where") {
+			break
+		}
+		switch {
+		case p.is(j, "(") && superStart < 0 && primary[1] == 0:
+			primary = [2]int{j, p.closing(j)}
+			j = primary[1]
+		case p.is(j, "(") || p.is(j, "["):
+			j = p.closing(j)
+		case p.is(j, "<"):
+			if end := p.angleEnd(j); end > 0 {
+				j = end
+			}
+		case p.is(j, ":") && superStart < 0:
+			superStart = j
+		}
+		headerEnd = j
+	}
+	bodyOpen := -1
+	last := headerEnd
+	if p.is(j, "{") {
+		bodyOpen = j
+		last = p.closing(j)
+	}
+
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       kind,
+		Signature:  p.text(keyword, headerEnd),
+		IsExported: mods.public,
+		Decorators: annotations,
+	}, start, last)
+
+	if primary[1] > 0 {
+		for _, item := range p.list(primary[0], primary[1]) {
+			p.primaryParameter(item[0], item[1], name)
+		}
+	}
+	if superStart >= 0 {
+		superEnd := headerEnd + 1
+		for k := superStart; k <= headerEnd; k++ {
+			if p.is(k, "where") {
+				superEnd = k
+				break
+			}
+		}
+		classes, interfaces := p.supertypes(superStart+1, superEnd)
+		if iface {
+			p.addHeritage(name, append(classes, interfaces...), nil)
+		} else {
+			p.addHeritage(name, classes, interfaces)
+		}
+		p.calls(superStart, superEnd, "", name, nil)
+	}
+
+	p.i = last + 1
+	if bodyOpen < 0 {
+		return
+	}
+	p.i = bodyOpen + 1
+	if kind == graph.NodeEnum {
+		p.enumEntries(last, name)
+	}
+	p.members(last, jvScope{class: name, iface: iface})
+	p.i = last + 1
+}
+
+// primaryParameter records the primary constructor parameter between the
+// tokens at indices from and to, exclusive. val and var parameters are
+// fields of class.
+func (p *ktParser) primaryParameter(from, to int, class string) {
+	property := false
+	for j := from; j < to; j++ {
+		if p.is(j, "val") || p.is(j, "var") {
+			property = true
+		}
+		if !p.is(j, ":") {
+			continue
+		}
+		typeEnd := to
+		for k := j + 1; k < to; k++ {
+			if p.is(k, "=") {
+				typeEnd = k
+				break
+			}
+		}
+		if property {
+			typ := p.typeRefs(j+1, typeEnd, "field", class, "")
+			p.addField(class, p.ident(j-1), typ)
+		} else {
+			p.typeRefs(j+1, typeEnd, "param", class, "")
+		}
+		return
+	}
+}
+
+// enumEntries skips the entries of the enum class whose body closes at
+// index last. They run to the first ';' of the body, which is required
+// before members; calls in their arguments and bodies belong to the enum.
+func (p *ktParser) enumEntries(last int, enum string) {
+	for j := p.i; j < last; j++ {
+		switch {
+		case p.is(j, "(") || p.is(j, "{") || p.is(j, "["):
+			closeBracket := p.closing(j)
+			p.calls(j, closeBracket, "", enum, nil)
+			j = closeBracket
+		case p.is(j, ";"):
+			p.i = j + 1
+			return
+		}
+	}
+	p.i = last
+}
+
+// companion parses a companion object, whose members belong to the
+// enclosing class.
+func (p *ktParser) companion(scope jvScope) {
+	j := p.i + 1
+	for j < len(p.toks) && !p.is(j, "{") && !p.is(j, "}") && p.tok(j).line == p.tok(p.i).line {
+		j++
+	}
+	if !p.is(j, "{") {
+		p.i = j
+		return
+	}
+	closeBrace := p.closing(j)
+	p.i = j + 1
+	p.members(closeBrace, jvScope{class: scope.class})
+	p.i = closeBrace + 1
+}
+
+// function parses the function whose fun keyword is at the current
+// position. Extension functions (fun User.fullName()) keep their receiver
+// type in the signature.
+func (p *ktParser) function(start int, public bool, annotations []string, scope jvScope) {
+	funIndex := p.i
+	j := funIndex + 1
+	if p.is(j, "<") {
+		if end := p.angleEnd(j); end > 0 {
+			j = end + 1
+		}
+	}
+	for j < len(p.toks) && !p.is(j, "(") && !p.is(j, "{") && !p.is(j, "=") && !p.is(j, "}") {
+		if p.is(j, "<") {
+			if end := p.angleEnd(j); end > 0 {
+				j = end
+			}
+		}
+		j++
+	}
+	name := p.ident(j - 1)
+	if name == "" || !p.is(j, "(") {
+		// Anonymous functions are expressions
+		last := p.statementEnd(funIndex)
+		p.calls(funIndex, last+1, "", scope.class, nil)
+		p.i = last + 1
+		return
+	}
+	open := j
+	closeParen := p.closing(open)
+
+	// The return type runs to the body, the '=', a where clause or the end
+	// of the line
+	retEnd := closeParen + 1
+	if p.is(retEnd, ":") {
+		for retEnd++; retEnd < len(p.toks); retEnd++ {
+			if p.is(retEnd, "<") {
+				if end := p.angleEnd(retEnd); end > 0 {
+					retEnd = end
+				}
+				continue
+			}
+			if p.is(retEnd, "(") || p.is(retEnd, "[") {
+				retEnd = p.closing(retEnd)
+				continue
+			}
+			if p.is(retEnd, "{") || p.is(retEnd, "=") || p.is(retEnd, "where") || p.is(retEnd, "}") || p.is(retEnd, ";") ||
+				p.tok(retEnd).line > p.tok(retEnd-1).endLine && !p.continues(retEnd-1, retEnd) {
+				break
+			}
+		}
+	}
+	bodyOpen := retEnd
+	if p.is(bodyOpen, "where") {
+		for bodyOpen < len(p.toks) && !p.is(bodyOpen, "{") && !p.is(bodyOpen, "=") && !p.is(bodyOpen, "}") {
+			bodyOpen++
+		}
+	}
+	last := retEnd - 1
+	switch {
+	case p.is(bodyOpen, "{"):
+		last = p.closing(bodyOpen)
+	case p.is(bodyOpen, "="):
+		last = p.statementEnd(bodyOpen + 1)
+	}
+
+	kind := graph.NodeFunction
+	if scope.class != "" {
+		kind = graph.NodeMethod
+	}
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       kind,
+		Signature:  "fun " + p.text(funIndex+1, retEnd-1),
+		ClassName:  scope.class,
+		IsExported: public,
+		Decorators: annotations,
+	}, start, last)
+
+	vars := p.parameters(open, closeParen, name, scope.class)
+	if p.is(closeParen+1, ":") {
+		p.typeRefs(closeParen+2, retEnd, "return", name, scope.class)
+	}
+	if p.is(bodyOpen, "{") || p.is(bodyOpen, "=") {
+		p.calls(bodyOpen, last+1, name, scope.class, vars)
+	}
+	p.i = last + 1
+}
+
+// constructor parses a secondary constructor, recorded as the method
+// constructor of its class.
+func (p *ktParser) constructor(start int, public bool, annotations []string, scope jvScope) {
+	open := p.i + 1
+	closeParen := p.closing(open)
+	j := closeParen + 1
+	for j < len(p.toks) && !p.is(j, "{") && !p.is(j, "}") && !p.is(j, ";") &&
+		(j == closeParen+1 || p.tok(j).line == p.tok(j-1).endLine || p.continues(j-1, j)) {
+		if p.is(j, "(") {
+			j = p.closing(j)
+		}
+		j++
+	}
+	last := j - 1
+	if p.is(j, "{") {
+		last = p.closing(j)
+	}
+	p.define(ParsedSymbol{
+		Name:       "constructor",
+		Kind:       graph.NodeMethod,
+		Signature:  p.text(p.i, closeParen),
+		ClassName:  scope.class,
+		IsExported: public,
+		Decorators: annotations,
+	}, start, last)
+	vars := p.parameters(open, closeParen, "constructor", scope.class)
+	p.calls(closeParen, last+1, "constructor", scope.class, vars)
+	p.i = last + 1
+}
+
+// parameters records the types of the parameters between the parentheses
+// at indices open and closeParen and returns the declared types of the
+// named ones.
+func (p *ktParser) parameters(open, closeParen int, function, class string) map[string]string {
+	vars := make(map[string]string)
+	for _, item := range p.list(open, closeParen) {
+		for j := item[0]; j < item[1]; j++ {
+			if p.is(j, "@") {
+				_, end := p.qualifiedName(j + 1)
+				j = end - 1
+				if p.is(end, "(") {
+					j = p.closing(end)
+				}
+				continue
+			}
+			if !p.is(j, ":") {
+				continue
+			}
+			typeEnd := item[1]
+			for k := j + 1; k < item[1]; k++ {
+				if p.is(k, "=") {
+					typeEnd = k
+					break
+				}
+			}
+			if typ := p.typeRefs(j+1, typeEnd, "param", function, class); typ != "" && p.ident(j-1) != "" {
+				vars[p.ident(j-1)] = typ
+			}
+			break
+		}
+	}
+	return vars
+}
+
+// property parses the val or var at the current position. Properties of
+// classes are fields; their type is declared or constructed by the
+// initializer. Getters and setters on the following lines belong to the
+// property.
+func (p *ktParser) property(scope jvScope) {
+	keyword := p.i
+	last := p.statementEnd(keyword)
+	j := keyword + 1
+	if p.is(j, "<") {
+		if end := p.angleEnd(j); end > 0 {
+			j = end + 1
+		}
+	}
+	// Extension properties: val User.fullName: String
+	for p.ident(j) != "" && (p.is(j+1, ".") || p.is(j+1, "<")) {
+		if p.is(j+1, "<") {
+			if end := p.angleEnd(j + 1); end > 0 {
+				j = end
+			}
+		}
+		j += 2
+	}
+	name := p.ident(j)
+
+	typ := ""
+	initializer := -1
+	for k := j + 1; k <= last; k++ {
+		switch {
+		case p.is(k, ":") && k == j+1:
+			typeEnd := k + 1
+			for typeEnd <= last && !p.is(typeEnd, "=") && !p.is(typeEnd, "by") {
+				if p.is(typeEnd, "(") || p.is(typeEnd, "[") {
+					typeEnd = p.closing(typeEnd)
+				}
+				typeEnd++
+			}
+			if scope.class != "" {
+				typ = p.typeRefs(k+1, typeEnd, "field", scope.class, "")
+			}
+			k = typeEnd - 1
+		case p.is(k, "=") || p.is(k, "by"):
+			if initializer < 0 {
+				initializer = k
+			}
+		}
+	}
+	if initializer >= 0 {
+		if typ == "" && p.is(initializer, "=") {
+			typ = p.constructed(initializer + 1)
+		}
+		p.calls(initializer, last+1, "", scope.class, nil)
+	}
+	p.addField(scope.class, name, typ)
+
+	// Accessors: get() = ..., private set
+	p.i = last + 1
+	for {
+		k := p.i
+		for p.ident(k) != "" && ktModifiers[p.ident(k)] {
+			k++
+		}
+		if !p.is(k, "get") && !p.is(k, "set") {
+			return
+		}
+		end := k
+		if p.is(k+1, "(") {
+			end = p.closing(k + 1)
+			if p.is(end+1, ":") {
+				end = p.statementEnd(end + 2)
+				for end > k && !p.is(end+1, "{") && !p.is(end+1, "=") && p.is(end, "{") {
+					end--
+				}
+			}
+			switch {
+			case p.is(end+1, "{"):
+				end = p.closing(end + 1)
+			case p.is(end+1, "="):
+				end = p.statementEnd(end + 2)
+			}
+		}
+		p.calls(k, end+1, "", scope.class, nil)
+		p.i = end + 1
+	}
+}
+
+// typeAlias parses typealias Name<T> = Type.
+func (p *ktParser) typeAlias(start int, public bool) {
+	last := p.statementEnd(p.i)
+	p.define(ParsedSymbol{
+		Name:       p.ident(p.i + 1),
+		Kind:       graph.NodeTypeAlias,
+		Signature:  p.text(p.i, last),
+		IsExported: public,
+	}, start, last)
+	p.i = last + 1
+}
@@ -0,0 +1,233 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestKotlinParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ParseClass", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`package com.example.users
+
+import com.example.repo.UserRepository
+import org.slf4j.LoggerFactory as LF
+
+@Service
+open class UserService(
+    private val repo: UserRepository,
+    val audit: AuditLog,
+) : BaseService(), Lookup<User> {
+    var count: Int = 0
+        private set
+
+    init {
+        repo.connect()
+    }
+
+    @GetMapping("/users/{id}")
+    override fun find(id: String): User? {
+        val user = repo.findById(id)
+            ?.let { normalize(it) }
+        audit.record(user)
+        return user
+    }
+
+    internal fun String.shout(): String =
+        uppercase()
+            .trim()
+
+    companion object {
+        fun create(repo: UserRepository): UserService = UserService(repo, AuditLog())
+    }
+}
+
+fun main() {
+    UserService.create(UserRepository()).find("1")
+}
+`)
+		result, err := NewKotlinParser().Parse("UserService.kt", content)
+		require.NoError(t, err)
+		assert.Equal(t, "com.example.users", result.Package)
+
+		symbols := symbolsByName(result)
+		service := symbols["UserService"]
+		assert.Equal(t, graph.NodeClass, service.Kind)
+		assert.Equal(t, []string{"Service"}, service.Decorators)
+		assert.Equal(t, 7, service.StartLine)
+		assert.Equal(t, 33, service.EndLine)
+
+		find := symbols["UserService.find"]
+		assert.Equal(t, graph.NodeMethod, find.Kind)
+		assert.Equal(t, "UserService", find.ClassName)
+		assert.Equal(t, []string{"GetMapping", "override"}, find.Decorators, "override is recorded as a decorator")
+		assert.Equal(t, "fun find(id: String): User?", find.Signature)
+		assert.True(t, find.IsExported)
+
+		shout := symbols["UserService.shout"]
+		assert.Equal(t, "fun String.shout(): String", shout.Signature, "extension receivers stay in the signature")
+		assert.False(t, shout.IsExported, "internal is not exported")
+		assert.Equal(t, 28, shout.EndLine, "expression bodies continue across lines")
+
+		assert.Equal(t, graph.NodeMethod, symbols["UserService.create"].Kind, "companion members belong to the class")
+		assert.Equal(t, graph.NodeFunction, symbols["main"].Kind)
+
+		require.Len(t, result.Heritage, 1)
+		assert.Equal(t, []string{"BaseService"}, result.Heritage[0].Extends, "constructor calls mark superclasses")
+		assert.Equal(t, []string{"Lookup"}, result.Heritage[0].Implements)
+
+		connect := findCall(result.Calls, "connect", 15)
+		require.NotNil(t, connect)
+		assert.Equal(t, "UserRepository", connect.Receiver, "primary constructor properties are fields")
+
+		findByID := findCall(result.Calls, "findById", 20)
+		require.NotNil(t, findByID)
+		assert.Equal(t, "UserRepository", findByID.Receiver)
+		assert.Equal(t, "find", findByID.Enclosing)
+
+		record := findCall(result.Calls, "record", 22)
+		require.NotNil(t, record)
+		assert.Equal(t, "AuditLog", record.Receiver)
+
+		create := findCall(result.Calls, "create", 36)
+		require.NotNil(t, create)
+		assert.Equal(t, "UserService", create.Receiver)
+		assert.Equal(t, "main", create.Enclosing)
+	})
+
+	t.Run("ParseImports", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`@file:JvmName("Main")
+package app
+
+import kotlinx.coroutines.*
+import com.example.model.Order
+import org.slf4j.LoggerFactory as LF
+`)
+		result, err := NewKotlinParser().Parse("Main.kt", content)
+		require.NoError(t, err)
+		assert.Equal(t, "app", result.Package)
+
+		imports := importsByModule(result)
+		assert.Equal(t, []string{"*"}, imports["kotlinx.coroutines"])
+		assert.Equal(t, []string{"Order"}, imports["com.example.model"])
+		require.Contains(t, imports, "org.slf4j")
+		assert.Equal(t, "LF", result.Imports[2].Alias)
+	})
+
+	t.Run("ParseDeclarations", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`data class User(val id: String, var address: Address, age: Int = 0)
+
+interface Lookup<T> : Comparable<T> {
+    fun find(id: String): T?
+    private fun hidden() = Unit
+}
+
+enum class Role(val label: String) : Labeled {
+    ADMIN("admin"),
+    USER("user") {
+        override fun describe() = label.uppercase()
+    };
+
+    open fun describe(): String = label
+}
+
+annotation class Audited
+
+object Registry {
+    fun register(user: User) {}
+}
+
+typealias UserMap = Map<String, User>
+`)
+		result, err := NewKotlinParser().Parse("Types.kt", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		require.Len(t, symbols, 10)
+		assert.Equal(t, graph.NodeClass, symbols["User"].Kind)
+		assert.Equal(t, 1, symbols["User"].EndLine)
+		assert.Equal(t, graph.NodeInterface, symbols["Lookup"].Kind)
+		assert.True(t, symbols["Lookup.find"].IsExported)
+		assert.False(t, symbols["Lookup.hidden"].IsExported)
+		assert.Equal(t, graph.NodeEnum, symbols["Role"].Kind)
+		assert.Contains(t, symbols, "Role.describe")
+		assert.Equal(t, graph.NodeInterface, symbols["Audited"].Kind)
+		assert.Equal(t, graph.NodeClass, symbols["Registry"].Kind)
+		assert.Equal(t, graph.NodeMethod, symbols["Registry.register"].Kind)
+		assert.Equal(t, graph.NodeTypeAlias, symbols["UserMap"].Kind)
+		assert.Nil(t, findCall(result.Calls, "ADMIN", 9), "enum entries are not calls")
+
+		heritage := make(map[string]ClassHeritage)
+		for _, h := range result.Heritage {
+			heritage[h.ClassName] = h
+		}
+		assert.Equal(t, []string{"Comparable"}, heritage["Lookup"].Extends, "interfaces extend interfaces")
+		assert.Equal(t, []string{"Labeled"}, heritage["Role"].Implements)
+
+		var fields []string
+		for _, ref := range result.TypeRefs {
+			if ref.Role == "field" {
+				fields = append(fields, ref.Name)
+			}
+		}
+		assert.Equal(t, []string{"Address"}, fields)
+	})
+
+	t.Run("ParseStrayAnnotation", func(t *testing.T) {
+		t.Parallel()
+
+		for _, source := range []string{
+			"package a\n\n@\ninterface Repo {\n  fun find(): String\n}\n",
+			"class A {\n  @\n  interface Inner\n}\n",
+			"@interface X {}\n",
+		} {
+			_, err := NewKotlinParser().Parse("Repo.kt", []byte(source))
+			require.NoError(t, err, source)
+		}
+
+		result, err := NewKotlinParser().Parse("Repo.kt", []byte("package a\n\n@\ninterface Repo {\n  fun find(): String\n}\n"))
+		require.NoError(t, err)
+		var names []string
+		for _, sym := range result.Symbols {
+			names = append(names, sym.Name)
+		}
+		assert.Contains(t, names, "Repo")
+		assert.Contains(t, names, "find")
+	})
+
+	t.Run("ParseEmptyFile", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := NewKotlinParser().Parse("Empty.kt", []byte(""))
+		require.NoError(t, err)
+		assert.Empty(t, result.Symbols)
+		assert.Empty(t, result.Calls)
+	})
+}
+
+func TestKotlinParser_Language(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "kotlin", NewKotlinParser().Language())
+}
+
+func TestKotlinParser_FileDetection(t *testing.T) {
+	t.Parallel()
+
+	parser := NewKotlinParser()
+	assert.True(t, parser.SupportsFile("App.kt"))
+	assert.True(t, parser.SupportsFile("build.gradle.kts"))
+	assert.False(t, parser.SupportsFile("App.java"))
+	assert.False(t, parser.SupportsFile("App.ktx"))
+}
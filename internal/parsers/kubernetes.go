@@ -0,0 +1,255 @@
+package parsers
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// KubernetesParser parses Kubernetes manifests, including the templates of
+// Helm charts.
+//
+// Every object of a manifest is a resource named by its kind and name
+// (Deployment/api). Workloads record the labels of their pods, the images
+// they run and the ConfigMaps, Secrets, volume claims and service account
+// they use; Services record their selector; Ingresses refer to their
+// backend Services and autoscalers to their target.
+//
+// Helm template actions are blanked out before the manifest is decoded:
+// lines holding only an action ({{- if .Values.enabled }}) are dropped and
+// the other actions read as "helm", so that templated values do not keep
+// the rest of the object from being indexed.
+type KubernetesParser struct{}
+
+// NewKubernetesParser creates a new Kubernetes parser.
+func NewKubernetesParser() *KubernetesParser {
+	return &KubernetesParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *KubernetesParser) Language() string {
+	return "kubernetes"
+}
+
+// SupportsFile checks if this parser can handle the given file. Whether a
+// YAML file is a Kubernetes manifest depends on its content; see
+// IsKubernetesManifest.
+func (p *KubernetesParser) SupportsFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		return true
+	}
+	return false
+}
+
+var (
+	kubernetesAPIVersion = regexp.MustCompile(`(?m)^apiVersion:\s*\S`)
+	kubernetesKind       = regexp.MustCompile(`(?m)^kind:\s*[A-Z]`)
+)
+
+// IsKubernetesManifest reports whether the content of a YAML file holds
+// Kubernetes objects, which have a top-level apiVersion and kind.
+func IsKubernetesManifest(content []byte) bool {
+	return kubernetesAPIVersion.Match(content) && kubernetesKind.Match(content)
+}
+
+// helmAction matches a Helm template action.
+var helmAction = regexp.MustCompile(`\{\{-?.*?-?\}\}`)
+
+// blankHelmActions removes the Helm template actions of a manifest,
+// keeping its lines where they are.
+func blankHelmActions(content []byte) []byte {
+	if !bytes.Contains(content, []byte("{{")) {
+		return content
+	}
+	lines := strings.Split(string(content), "\n")
+	for i, line := range lines {
+		if trimmed := strings.TrimSpace(line); helmAction.ReplaceAllString(trimmed, "") == "" {
+			lines[i] = ""
+			continue
+		}
+		lines[i] = helmAction.ReplaceAllString(line, "helm")
+	}
+	return []byte(strings.Join(lines, "\n"))
+}
+
+// Parse parses a Kubernetes manifest and extracts its objects as resources.
+func (p *KubernetesParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	result := &ParseResult{
+		Symbols:   []ParsedSymbol{},
+		Imports:   []ImportStatement{},
+		Calls:     []CallSite{},
+		TypeRefs:  []TypeAnnotation{},
+		Heritage:  []ClassHeritage{},
+		Resources: []Resource{},
+	}
+
+	lines := strings.Split(string(content), "\n")
+	decoder := yaml.NewDecoder(bytes.NewReader(blankHelmActions(content)))
+	for {
+		var doc yaml.Node
+		err := decoder.Decode(&doc)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing Kubernetes manifest: %w", err)
+		}
+		if len(doc.Content) == 0 || doc.Content[0].Kind != yaml.MappingNode {
+			continue
+		}
+		var object map[string]any
+		if err := doc.Content[0].Decode(&object); err != nil {
+			continue
+		}
+		if r, ok := kubernetesObject(object); ok {
+			r.StartLine = doc.Content[0].Line
+			r.EndLine = max(yamlLastLine(doc.Content[0]), r.StartLine)
+			r.Content = strings.Join(lines[r.StartLine-1:min(r.EndLine, len(lines))], "\n")
+			result.Resources = append(result.Resources, r)
+		}
+	}
+
+	return result, nil
+}
+
+// kubernetesObject returns the resource of a Kubernetes object.
+func kubernetesObject(object map[string]any) (Resource, bool) {
+	kind := yamlString(object, "kind")
+	name := yamlString(object, "metadata", "name")
+	if kind == "" || name == "" {
+		return Resource{}, false
+	}
+	r := Resource{
+		Name:       kind + "/" + name,
+		Kind:       kind,
+		Platform:   "kubernetes",
+		Properties: map[string]any{"api_version": yamlString(object, "apiVersion")},
+	}
+	namespace := yamlString(object, "metadata", "namespace")
+	if namespace != "" {
+		r.Properties["namespace"] = namespace
+	}
+	reference := func(kind, name string) {
+		if name != "" {
+			r.References = append(r.References, kind+"/"+name)
+		}
+	}
+
+	switch kind {
+	case "Service":
+		r.Selector = yamlStringMap(object, "spec", "selector")
+	case "Ingress":
+		reference("Service", yamlString(object, "spec", "defaultBackend", "service", "name"))
+		reference("Service", yamlString(object, "spec", "backend", "serviceName"))
+		for _, rule := range yamlList(object, "spec", "rules") {
+			for _, path := range yamlList(rule, "http", "paths") {
+				reference("Service", yamlString(path, "backend", "service", "name"))
+				reference("Service", yamlString(path, "backend", "serviceName"))
+			}
+		}
+	case "HorizontalPodAutoscaler":
+		reference(yamlString(object, "spec", "scaleTargetRef", "kind"), yamlString(object, "spec", "scaleTargetRef", "name"))
+	}
+
+	// The pod template of a workload
+	var labels, spec map[string]any
+	switch kind {
+	case "Pod":
+		labels, _ = yamlValueAt(object, "metadata", "labels").(map[string]any)
+		spec, _ = object["spec"].(map[string]any)
+	case "CronJob":
+		labels, _ = yamlValueAt(object, "spec", "jobTemplate", "spec", "template", "metadata", "labels").(map[string]any)
+		spec, _ = yamlValueAt(object, "spec", "jobTemplate", "spec", "template", "spec").(map[string]any)
+	default:
+		labels, _ = yamlValueAt(object, "spec", "template", "metadata", "labels").(map[string]any)
+		spec, _ = yamlValueAt(object, "spec", "template", "spec").(map[string]any)
+	}
+	if spec == nil {
+		return r, true
+	}
+	r.Labels = stringMap(labels)
+	reference("ServiceAccount", yamlString(spec, "serviceAccountName"))
+	for _, key := range []string{"initContainers", "containers"} {
+		for _, container := range yamlList(spec, key) {
+			if image := yamlString(container, "image"); image != "" {
+				r.Images = append(r.Images, image)
+			}
+			for _, source := range yamlList(container, "envFrom") {
+				reference("ConfigMap", yamlString(source, "configMapRef", "name"))
+				reference("Secret", yamlString(source, "secretRef", "name"))
+			}
+			for _, env := range yamlList(container, "env") {
+				reference("ConfigMap", yamlString(env, "valueFrom", "configMapKeyRef", "name"))
+				reference("Secret", yamlString(env, "valueFrom", "secretKeyRef", "name"))
+			}
+		}
+	}
+	for _, volume := range yamlList(spec, "volumes") {
+		reference("ConfigMap", yamlString(volume, "configMap", "name"))
+		reference("Secret", yamlString(volume, "secret", "secretName"))
+		reference("PersistentVolumeClaim", yamlString(volume, "persistentVolumeClaim", "claimName"))
+	}
+	return r, true
+}
+
+// yamlValueAt returns the value at a path of keys in a decoded YAML
+// mapping, or nil.
+func yamlValueAt(value any, keys ...string) any {
+	for _, key := range keys {
+		m, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+		value = m[key]
+	}
+	return value
+}
+
+// yamlString returns the string at a path of keys, or "".
+func yamlString(value any, keys ...string) string {
+	s, _ := yamlValueAt(value, keys...).(string)
+	return s
+}
+
+// yamlList returns the list at a path of keys, or nil.
+func yamlList(value any, keys ...string) []any {
+	list, _ := yamlValueAt(value, keys...).([]any)
+	return list
+}
+
+// yamlStringMap returns the string values of the mapping at a path of
+// keys, or nil.
+func yamlStringMap(value any, keys ...string) map[string]string {
+	m, _ := yamlValueAt(value, keys...).(map[string]any)
+	return stringMap(m)
+}
+
+func stringMap(m map[string]any) map[string]string {
+	if len(m) == 0 {
+		return nil
+	}
+	result := make(map[string]string, len(m))
+	for key, value := range m {
+		result[key] = fmt.Sprint(value)
+	}
+	return result
+}
+
+// yamlLastLine returns the last line of a YAML node and its children.
+func yamlLastLine(node *yaml.Node) int {
+	line := node.Line
+	for _, child := range node.Content {
+		line = max(line, yamlLastLine(child))
+	}
+	if node.Kind == yaml.ScalarNode && (node.Style == yaml.LiteralStyle || node.Style == yaml.FoldedStyle) {
+		line += strings.Count(strings.TrimRight(node.Value, "\n"), "\n") + 1
+	}
+	return line
+}
@@ -0,0 +1,165 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKubernetesParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Manifest", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: api
+  namespace: shop
+spec:
+  replicas: 2
+  selector:
+    matchLabels:
+      app: api
+  template:
+    metadata:
+      labels:
+        app: api
+        tier: backend
+    spec:
+      serviceAccountName: api
+      initContainers:
+        - name: migrate
+          image: ghcr.io/acme/migrate:1.4
+      containers:
+        - name: api
+          image: ghcr.io/acme/api:1.4
+          envFrom:
+            - configMapRef:
+                name: api-config
+          env:
+            - name: DB_PASSWORD
+              valueFrom:
+                secretKeyRef:
+                  name: db
+                  key: password
+      volumes:
+        - name: data
+          persistentVolumeClaim:
+            claimName: api-data
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: api
+  namespace: shop
+spec:
+  selector:
+    app: api
+  ports:
+    - port: 80
+      targetPort: 8080
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: api
+spec:
+  rules:
+    - http:
+        paths:
+          - path: /
+            backend:
+              service:
+                name: api
+                port:
+                  number: 80
+---
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: api-config
+data:
+  config.yaml: |
+    listen: :8080
+    debug: false
+`)
+		result, err := NewKubernetesParser().Parse("deploy/api.yaml", content)
+		require.NoError(t, err)
+
+		resources := resourcesByName(result)
+		require.Len(t, result.Resources, 4)
+
+		deployment := resources["Deployment/api"]
+		assert.Equal(t, "Deployment", deployment.Kind)
+		assert.Equal(t, "kubernetes", deployment.Platform)
+		assert.Equal(t, "shop", deployment.Properties["namespace"])
+		assert.Equal(t, "apps/v1", deployment.Properties["api_version"])
+		assert.Equal(t, 1, deployment.StartLine)
+		assert.Equal(t, 36, deployment.EndLine)
+		assert.Equal(t, map[string]string{"app": "api", "tier": "backend"}, deployment.Labels)
+		assert.Equal(t, []string{"ghcr.io/acme/migrate:1.4", "ghcr.io/acme/api:1.4"}, deployment.Images)
+		assert.Equal(t, []string{
+			"ServiceAccount/api", "ConfigMap/api-config", "Secret/db", "PersistentVolumeClaim/api-data",
+		}, deployment.References)
+
+		service := resources["Service/api"]
+		assert.Equal(t, 38, service.StartLine)
+		assert.Equal(t, map[string]string{"app": "api"}, service.Selector)
+		assert.Empty(t, service.References)
+
+		assert.Equal(t, []string{"Service/api"}, resources["Ingress/api"].References)
+
+		configMap := resources["ConfigMap/api-config"]
+		assert.Equal(t, 72, configMap.EndLine, "block scalars span their lines")
+	})
+
+	t.Run("HelmTemplate", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`{{- if .Values.worker.enabled }}
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: {{ include "chart.fullname" . }}-worker
+  labels:
+    {{- include "chart.labels" . | nindent 4 }}
+spec:
+  template:
+    metadata:
+      labels:
+        app: worker
+    spec:
+      containers:
+        - name: worker
+          image: "{{ .Values.image.repository }}:{{ .Values.image.tag }}"
+{{- end }}
+`)
+		result, err := NewKubernetesParser().Parse("chart/templates/worker.yaml", content)
+		require.NoError(t, err)
+		require.Len(t, result.Resources, 1)
+
+		worker := result.Resources[0]
+		assert.Equal(t, "Deployment/helm-worker", worker.Name)
+		assert.Equal(t, 2, worker.StartLine)
+		assert.Equal(t, map[string]string{"app": "worker"}, worker.Labels)
+		assert.Equal(t, []string{"helm:helm"}, worker.Images)
+	})
+
+	t.Run("InvalidYAML", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewKubernetesParser().Parse("deploy/bad.yaml", []byte("apiVersion: v1\nkind: [Service\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestIsKubernetesManifest(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsKubernetesManifest([]byte("# api\napiVersion: v1\nkind: Service\n")))
+	assert.False(t, IsKubernetesManifest([]byte("name: ci\non: push\n")))
+	assert.False(t, IsKubernetesManifest([]byte("apiVersion: 2\nkind: library\n")), "kinds are capitalized")
+}
@@ -0,0 +1,244 @@
+package parsers
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// MarkdownParser parses Markdown documentation (.md and .markdown files).
+//
+// Every heading starts a section that runs to the next heading, whatever
+// its level; the text before the first heading is a section titled after
+// the file. The code spans of a section that look like symbol names
+// (`Parse`, `graph.GenerateID`, `Store.Get()`) are its mentions. Fenced
+// code blocks and front matter are part of the section text but mention
+// nothing.
+type MarkdownParser struct{}
+
+// NewMarkdownParser creates a new Markdown parser.
+func NewMarkdownParser() *MarkdownParser {
+	return &MarkdownParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *MarkdownParser) Language() string {
+	return "markdown"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *MarkdownParser) SupportsFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".md", ".markdown":
+		return true
+	}
+	return false
+}
+
+var (
+	// markdownATXHeading matches a "# Heading" line, with optional closing
+	// hashes.
+	markdownATXHeading = regexp.MustCompile(`^ {0,3}(#{1,6})(?:[ \t]+(.*?))?(?:[ \t]+#+)?[ \t]*$`)
+
+	// markdownSetextUnderline matches the line under a Setext heading.
+	markdownSetextUnderline = regexp.MustCompile(`^ {0,3}(=+|-+)[ \t]*$`)
+
+	// markdownFence matches the opening line of a fenced code block.
+	markdownFence = regexp.MustCompile("^ {0,3}(`{3,}|~{3,})")
+
+	// markdownMention matches a code span that names a symbol: identifiers
+	// joined by ., :: or #, with optional call parentheses.
+	markdownMention = regexp.MustCompile(`^[A-Za-z_$][\w$]*(?:(?:\.|::|#)[A-Za-z_$][\w$]*)*(?:\(.*\))?$`)
+)
+
+// Parse parses a Markdown file and extracts its sections.
+func (p *MarkdownParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	result := &ParseResult{
+		Symbols:  []ParsedSymbol{},
+		Imports:  []ImportStatement{},
+		Calls:    []CallSite{},
+		TypeRefs: []TypeAnnotation{},
+		Heritage: []ClassHeritage{},
+		Sections: []DocSection{},
+	}
+
+	lines := strings.Split(strings.TrimSuffix(string(content), "\n"), "\n")
+	frontMatter := markdownFrontMatter(lines)
+	code := markdownCodeLines(lines, frontMatter)
+
+	type heading struct {
+		title       string
+		level, line int
+	}
+	var headings []heading
+	for i, line := range lines {
+		if code[i] {
+			continue
+		}
+		if m := markdownATXHeading.FindStringSubmatch(line); m != nil {
+			headings = append(headings, heading{title: m[2], level: len(m[1]), line: i})
+			continue
+		}
+		if m := markdownSetextUnderline.FindStringSubmatch(line); m != nil && i > 0 && !code[i-1] &&
+			strings.TrimSpace(lines[i-1]) != "" && (len(headings) == 0 || headings[len(headings)-1].line != i-1) {
+			level := 1
+			if m[1][0] == '-' {
+				level = 2
+			}
+			headings = append(headings, heading{title: strings.TrimSpace(lines[i-1]), level: level, line: i - 1})
+		}
+	}
+
+	var path []string
+	var levels []int
+	add := func(title string, level, start, end int) {
+		for len(levels) > 0 && levels[len(levels)-1] >= level {
+			path, levels = path[:len(path)-1], levels[:len(levels)-1]
+		}
+		title = strings.TrimSpace(strings.ReplaceAll(title, "`", ""))
+		if title == "" {
+			return
+		}
+		if level > 0 {
+			path, levels = append(path, title), append(levels, level)
+		}
+		for end > start && strings.TrimSpace(lines[end]) == "" {
+			end--
+		}
+		result.Sections = append(result.Sections, DocSection{
+			Title:     title,
+			Path:      append([]string{}, path...),
+			Level:     level,
+			StartLine: start + 1,
+			EndLine:   end + 1,
+			Content:   strings.Join(lines[start:end+1], "\n"),
+			Mentions:  markdownMentions(lines[start:end+1], code[start:end+1]),
+		})
+	}
+
+	first := len(lines)
+	if len(headings) > 0 {
+		first = headings[0].line
+	}
+	for i := frontMatter; i < first; i++ {
+		if strings.TrimSpace(lines[i]) != "" {
+			name := filepath.Base(filePath)
+			add(strings.TrimSuffix(name, filepath.Ext(name)), 0, frontMatter, first-1)
+			break
+		}
+	}
+	for k, h := range headings {
+		end := len(lines) - 1
+		if k+1 < len(headings) {
+			end = headings[k+1].line - 1
+		}
+		add(h.title, h.level, h.line, end)
+	}
+
+	return result, nil
+}
+
+// markdownFrontMatter returns the number of lines of the YAML front matter
+// at the start of a file, or 0.
+func markdownFrontMatter(lines []string) int {
+	if len(lines) == 0 || strings.TrimSpace(lines[0]) != "---" {
+		return 0
+	}
+	for i := 1; i < len(lines); i++ {
+		if t := strings.TrimSpace(lines[i]); t == "---" || t == "..." {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// markdownCodeLines reports for each line whether it belongs to the front
+// matter, of frontMatter lines, or to a fenced code block, fences included.
+func markdownCodeLines(lines []string, frontMatter int) []bool {
+	code := make([]bool, len(lines))
+	for i := range frontMatter {
+		code[i] = true
+	}
+
+	fence := ""
+	for i := frontMatter; i < len(lines); i++ {
+		if fence != "" {
+			code[i] = true
+			if t := strings.TrimSpace(lines[i]); strings.HasPrefix(t, fence) && strings.Trim(t, fence[:1]) == "" {
+				fence = ""
+			}
+			continue
+		}
+		if m := markdownFence.FindStringSubmatch(lines[i]); m != nil {
+			code[i] = true
+			fence = m[1]
+		}
+	}
+	return code
+}
+
+// markdownMentions returns the code spans of the lines outside code blocks
+// that name a symbol, in order and without duplicates.
+func markdownMentions(lines []string, code []bool) []string {
+	var mentions []string
+	seen := make(map[string]bool)
+	for i, line := range lines {
+		if code[i] {
+			continue
+		}
+		for _, span := range codeSpans(line) {
+			span = strings.TrimSpace(span)
+			if !markdownMention.MatchString(span) {
+				continue
+			}
+			if open := strings.IndexByte(span, '('); open >= 0 {
+				span = span[:open]
+			}
+			if !seen[span] {
+				seen[span] = true
+				mentions = append(mentions, span)
+			}
+		}
+	}
+	return mentions
+}
+
+// codeSpans returns the text of the code spans of a line: text between
+// runs of backticks of the same length.
+func codeSpans(line string) []string {
+	var spans []string
+	for i := 0; i < len(line); {
+		if line[i] != '`' {
+			i++
+			continue
+		}
+		n := 0
+		for i+n < len(line) && line[i+n] == '`' {
+			n++
+		}
+		open := i + n
+		closing := -1
+		for j := open; j < len(line); {
+			if line[j] != '`' {
+				j++
+				continue
+			}
+			m := 0
+			for j+m < len(line) && line[j+m] == '`' {
+				m++
+			}
+			if m == n {
+				closing = j
+				break
+			}
+			j += m
+		}
+		if closing < 0 {
+			i = open
+			continue
+		}
+		spans = append(spans, line[open:closing])
+		i = closing + n
+	}
+	return spans
+}
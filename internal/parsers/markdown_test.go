@@ -0,0 +1,95 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMarkdownParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	content := []byte("---\n" +
+		"title: Storage\n" +
+		"---\n" +
+		"Notes on `storage.Open`.\n" +
+		"\n" +
+		"# Design\n" +
+		"\n" +
+		"The `Store` wraps a `Backend`; see `Store.Get()` and `Cache::put`.\n" +
+		"\n" +
+		"## Writes `Put`\n" +
+		"\n" +
+		"```go\n" +
+		"# not a heading\n" +
+		"x := `Ignored`\n" +
+		"```\n" +
+		"Calls `flush(ctx)`, never `go test ./...` or `a + b`.\n" +
+		"\n" +
+		"Reads\n" +
+		"-----\n" +
+		"Uses `Store.Get` again.\n" +
+		"\n" +
+		"# Operations #\n" +
+		"\n")
+
+	result, err := NewMarkdownParser().Parse("docs/storage.md", content)
+	require.NoError(t, err)
+	require.Len(t, result.Sections, 5)
+
+	preamble := result.Sections[0]
+	assert.Equal(t, "storage", preamble.Title, "the text before the first heading is titled after the file")
+	assert.Equal(t, 0, preamble.Level)
+	assert.Empty(t, preamble.Path)
+	assert.Equal(t, 4, preamble.StartLine)
+	assert.Equal(t, 4, preamble.EndLine)
+	assert.Equal(t, []string{"storage.Open"}, preamble.Mentions)
+
+	design := result.Sections[1]
+	assert.Equal(t, "Design", design.Title)
+	assert.Equal(t, 1, design.Level)
+	assert.Equal(t, []string{"Design"}, design.Path)
+	assert.Equal(t, 6, design.StartLine)
+	assert.Equal(t, 8, design.EndLine, "trailing blank lines are not part of a section")
+	assert.Equal(t, []string{"Store", "Backend", "Store.Get", "Cache::put"}, design.Mentions)
+
+	writes := result.Sections[2]
+	assert.Equal(t, "Writes Put", writes.Title)
+	assert.Equal(t, []string{"Design", "Writes Put"}, writes.Path)
+	assert.Equal(t, []string{"Put", "flush"}, writes.Mentions, "code blocks and non-identifiers mention nothing")
+
+	reads := result.Sections[3]
+	assert.Equal(t, "Reads", reads.Title)
+	assert.Equal(t, 2, reads.Level)
+	assert.Equal(t, []string{"Design", "Reads"}, reads.Path)
+	assert.Equal(t, 18, reads.StartLine)
+	assert.Equal(t, []string{"Store.Get"}, reads.Mentions)
+
+	operations := result.Sections[4]
+	assert.Equal(t, "Operations", operations.Title)
+	assert.Equal(t, []string{"Operations"}, operations.Path)
+	assert.Equal(t, "# Operations #", operations.Content)
+}
+
+func TestCodeSpans(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		line     string
+		expected []string
+	}{
+		{"Single", "call `Run` first", []string{"Run"}},
+		{"Several", "`a` and `b`", []string{"a", "b"}},
+		{"DoubleBackticks", "``x `y` z`` then `w`", []string{"x `y` z", "w"}},
+		{"Unclosed", "a `b and c", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tt.expected, codeSpans(tt.line))
+		})
+	}
+}
@@ -0,0 +1,134 @@
+package parsers
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// OpenAPIParser parses OpenAPI 3 and Swagger 2 API specifications, in YAML
+// or JSON.
+//
+// Every operation under paths is a route: the method is the operation key,
+// the path is the declared path under the base path of the API (the
+// basePath of Swagger 2, or the path of the first server URL), and the
+// handler is the operationId, which code generators name the handler
+// after.
+type OpenAPIParser struct{}
+
+// NewOpenAPIParser creates a new OpenAPI parser.
+func NewOpenAPIParser() *OpenAPIParser {
+	return &OpenAPIParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *OpenAPIParser) Language() string {
+	return "openapi"
+}
+
+// SupportsFile checks if this parser can handle the given file. Whether a
+// YAML or JSON file is an API specification depends on its content; see
+// IsAPISpec.
+func (p *OpenAPIParser) SupportsFile(filename string) bool {
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml", ".json":
+		return true
+	}
+	return false
+}
+
+// apiSpecVersion matches the top-level openapi or swagger version field of
+// an API specification.
+var apiSpecVersion = regexp.MustCompile(`(?m)(?:^|[{,]\s*)["']?(?:openapi|swagger)["']?\s*:\s*["']?[23]\.`)
+
+// IsAPISpec reports whether the content of a YAML or JSON file is an
+// OpenAPI or Swagger specification.
+func IsAPISpec(content []byte) bool {
+	return apiSpecVersion.Match(content)
+}
+
+// openAPIMethods are the operation keys of a path item.
+var openAPIMethods = map[string]bool{
+	"get": true, "put": true, "post": true, "delete": true,
+	"options": true, "head": true, "patch": true, "trace": true,
+}
+
+// Parse parses an API specification and extracts its operations as
+// routes.
+func (p *OpenAPIParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(content, &doc); err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI spec: %w", err)
+	}
+
+	result := &ParseResult{
+		Symbols:  []ParsedSymbol{},
+		Imports:  []ImportStatement{},
+		Calls:    []CallSite{},
+		TypeRefs: []TypeAnnotation{},
+		Heritage: []ClassHeritage{},
+		Routes:   []Route{},
+	}
+	root := &doc
+	if root.Kind == yaml.DocumentNode && len(root.Content) > 0 {
+		root = root.Content[0]
+	}
+
+	base := ""
+	if basePath := yamlValue(root, "basePath"); basePath != nil {
+		base = basePath.Value
+	}
+	if servers := yamlValue(root, "servers"); servers != nil && servers.Kind == yaml.SequenceNode && len(servers.Content) > 0 {
+		if server := yamlValue(servers.Content[0], "url"); server != nil {
+			if u, err := url.Parse(server.Value); err == nil {
+				base = u.Path
+			}
+		}
+	}
+	base = strings.TrimSuffix(base, "/")
+
+	paths := yamlValue(root, "paths")
+	if paths == nil || paths.Kind != yaml.MappingNode {
+		return result, nil
+	}
+	for i := 0; i+1 < len(paths.Content); i += 2 {
+		path, item := paths.Content[i], paths.Content[i+1]
+		if item.Kind != yaml.MappingNode {
+			continue
+		}
+		for j := 0; j+1 < len(item.Content); j += 2 {
+			method, operation := item.Content[j], item.Content[j+1]
+			if !openAPIMethods[method.Value] {
+				continue
+			}
+			route := Route{
+				Method:    strings.ToUpper(method.Value),
+				Path:      base + path.Value,
+				Framework: "openapi",
+				StartLine: method.Line,
+			}
+			if operationID := yamlValue(operation, "operationId"); operationID != nil {
+				route.Handler = operationID.Value
+			}
+			result.Routes = append(result.Routes, route)
+		}
+	}
+	return result, nil
+}
+
+// yamlValue returns the value of the key of a mapping node, or nil.
+func yamlValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
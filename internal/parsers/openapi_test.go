@@ -0,0 +1,88 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOpenAPIParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("OpenAPI3YAML", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`openapi: 3.0.3
+info:
+  title: Users
+  version: 1.0.0
+servers:
+  - url: https://api.example.com/v1/
+paths:
+  /users:
+    parameters:
+      - in: query
+        name: limit
+    get:
+      operationId: listUsers
+    post:
+      operationId: createUser
+  /users/{id}:
+    delete: {}
+components:
+  schemas:
+    User:
+      type: object
+`)
+		result, err := NewOpenAPIParser().Parse("api/openapi.yaml", content)
+		require.NoError(t, err)
+		routes := routesByName(result)
+
+		assert.Equal(t, Route{Method: "GET", Path: "/v1/users", Handler: "listUsers", Framework: "openapi", StartLine: 12}, routes["GET /v1/users"])
+		assert.Equal(t, "createUser", routes["POST /v1/users"].Handler)
+		assert.Contains(t, routes, "DELETE /v1/users/{id}", "operations without an operationId")
+		assert.Len(t, result.Routes, 3, "parameters are not operations")
+		assert.Empty(t, result.Symbols)
+	})
+
+	t.Run("Swagger2JSON", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`{
+  "swagger": "2.0",
+  "basePath": "/api",
+  "paths": {
+    "/pets/{petId}": {
+      "get": {"operationId": "showPetById"}
+    }
+  }
+}`)
+		result, err := NewOpenAPIParser().Parse("swagger.json", content)
+		require.NoError(t, err)
+		require.Len(t, result.Routes, 1)
+		assert.Equal(t, Route{Method: "GET", Path: "/api/pets/{petId}", Handler: "showPetById", Framework: "openapi", StartLine: 6}, result.Routes[0])
+	})
+
+	t.Run("InvalidSpec", func(t *testing.T) {
+		t.Parallel()
+
+		_, err := NewOpenAPIParser().Parse("broken.yaml", []byte("openapi: 3.0.0\npaths: [\n"))
+		assert.Error(t, err)
+	})
+}
+
+func TestOpenAPIParser_Language(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "openapi", NewOpenAPIParser().Language())
+}
+
+func TestIsAPISpec(t *testing.T) {
+	t.Parallel()
+
+	assert.True(t, IsAPISpec([]byte("openapi: \"3.1.0\"\n")))
+	assert.True(t, IsAPISpec([]byte(`{"swagger":"2.0"}`)))
+	assert.False(t, IsAPISpec([]byte("name: ci\non: [push]\n")))
+	assert.False(t, IsAPISpec([]byte("spec:\n  description: uses openapi: 3.0\n")), "nested keys")
+}
@@ -23,14 +23,39 @@ type ParsedSymbol struct {
 	// Signature is the function/method signature
 	Signature string
 
-	// ClassName is the parent class name (for methods)
+	// Doc is the documentation of the symbol: its doc comment, or its
+	// docstring (Python)
+	Doc string
+
+	// ClassName is the parent class name (for methods and fields), or the
+	// type of an enum constant (Go)
 	ClassName string
 
 	// IsExported indicates if the symbol is exported/public
 	IsExported bool
 
-	// Decorators contains decorator names (Python/TS)
+	// Decorators contains decorator names (Python/TS), attribute names
+	// (Rust, C#, C++) and annotation names (Java/Kotlin)
 	Decorators []string
+
+	// IsComponent indicates a UI component: a React function that renders
+	// JSX or a class extending React.Component (JS/TS)
+	IsComponent bool
+
+	// IsProperty indicates a property, recorded as a method (C#)
+	IsProperty bool
+
+	// IsPartial indicates a partial type, whose declaration may be split
+	// across files (C#)
+	IsPartial bool
+
+	// IsDeclaration indicates a function or method declared without a body,
+	// such as a prototype in a header, whose definition is elsewhere (C/C++)
+	IsDeclaration bool
+
+	// IsEntryPoint indicates a method a framework calls by convention, such
+	// as a Rails controller action (Ruby, PHP)
+	IsEntryPoint bool
 }
 
 // ImportStatement represents an import statement.
@@ -67,6 +92,71 @@ type CallSite struct {
 
 	// EndLine is the ending line number
 	EndLine int
+
+	// Enclosing is the name of the symbol the call appears in
+	// (empty for top-level code)
+	Enclosing string
+
+	// EnclosingClass is the class of the enclosing symbol (for methods)
+	EnclosingClass string
+
+	// Target is the declaration the call resolves to, when it is known
+	// from type checking (nil for purely syntactic call sites)
+	Target *CallTarget
+}
+
+// CallTarget identifies the declaration a call site resolves to.
+type CallTarget struct {
+	// FilePath is the repository-relative file of the declaration
+	// (empty when it is declared outside the repository)
+	FilePath string
+
+	// Name is the function/method name
+	Name string
+
+	// ClassName is the receiver type name (for methods)
+	ClassName string
+
+	// Kind is the symbol kind (function or method, or variable, constant
+	// or field for variable references)
+	Kind graph.NodeLabel
+
+	// Package is the import path of the declaring package
+	Package string
+
+	// Line is the line of the declaration (0 when outside the repository)
+	Line int
+}
+
+// VariableRef represents a reference to a package-level variable or
+// constant, or to a struct field.
+type VariableRef struct {
+	// Name is the variable, constant or field name
+	Name string
+
+	// Receiver is the type whose field is referenced (for fields)
+	Receiver string
+
+	// Package is the package qualifier (for package.Variable references)
+	Package string
+
+	// Write is set when the reference assigns to the variable or field
+	// rather than reading it
+	Write bool
+
+	// StartLine is the line number of the reference
+	StartLine int
+
+	// Enclosing is the name of the symbol the reference appears in
+	// (empty for top-level code)
+	Enclosing string
+
+	// EnclosingClass is the class of the enclosing symbol (for methods)
+	EnclosingClass string
+
+	// Target is the declaration the reference resolves to, when it is
+	// known from type checking
+	Target *CallTarget
 }
 
 // TypeAnnotation represents a type reference.
@@ -79,6 +169,13 @@ type TypeAnnotation struct {
 
 	// StartLine is the line number
 	StartLine int
+
+	// Enclosing is the name of the symbol the reference appears in
+	// (empty for top-level code)
+	Enclosing string
+
+	// EnclosingClass is the class of the enclosing symbol (for methods)
+	EnclosingClass string
 }
 
 // ClassHeritage represents class inheritance information.
@@ -93,6 +190,194 @@ type ClassHeritage struct {
 	Implements []string
 }
 
+// Route represents an HTTP route registered in code or declared in an API
+// specification.
+type Route struct {
+	// Method is the HTTP method (GET, POST, ...), or ANY for routes that
+	// match every method
+	Method string
+
+	// Path is the route path as written, including any group prefix
+	Path string
+
+	// Handler is the name of the handler function or method (empty for
+	// inline handlers, which belong to the enclosing symbol)
+	Handler string
+
+	// Receiver is the type or class of a handler method
+	Receiver string
+
+	// NoHandler is set for routes served by library code, such as
+	// http.FileServer, which no symbol of the repository handles
+	NoHandler bool
+
+	// Framework is the framework the route is registered with (net/http,
+	// chi, gin, echo, flask, fastapi, express, openapi)
+	Framework string
+
+	// StartLine is the line of the registration
+	StartLine int
+
+	// Enclosing is the name of the symbol the registration appears in
+	// (empty for top-level code)
+	Enclosing string
+
+	// EnclosingClass is the class of the enclosing symbol (for methods)
+	EnclosingClass string
+}
+
+// RouteMount is a router imported from another module and mounted under a
+// path prefix: app.use("/api", require("./routes/users")).
+type RouteMount struct {
+	// Prefix is the path the router is mounted under, including the
+	// prefix of the mounting router
+	Prefix string
+
+	// ModulePath is the module the router is imported from
+	ModulePath string
+
+	// StartLine is the line of the mount
+	StartLine int
+}
+
+// TableDef represents a table or view created, or a table altered, by a SQL
+// statement.
+type TableDef struct {
+	// Name is the table name, unquoted and lower-cased, without its schema
+	Name string
+
+	// Schema is the schema qualifier of the name (if any)
+	Schema string
+
+	// Columns are the columns the statement defines or adds
+	Columns []ColumnDef
+
+	// IsAlter indicates an ALTER TABLE statement, which adds columns to a
+	// table created elsewhere
+	IsAlter bool
+
+	// IsView indicates a view
+	IsView bool
+
+	// Content is the statement text
+	Content string
+
+	// StartLine is the starting line number (1-based)
+	StartLine int
+
+	// EndLine is the ending line number (1-based)
+	EndLine int
+}
+
+// ColumnDef represents a column of a table.
+type ColumnDef struct {
+	// Name is the column name, unquoted and lower-cased
+	Name string
+
+	// Type is the declared type, as written
+	Type string
+
+	// StartLine is the line of the column definition
+	StartLine int
+}
+
+// TableRef represents a table read or written by a SQL query.
+type TableRef struct {
+	// Table is the table name, as in TableDef
+	Table string
+
+	// Write indicates that the query inserts into, updates, deletes from,
+	// merges into or truncates the table
+	Write bool
+
+	// StartLine is the line of the table name in the query
+	StartLine int
+
+	// Enclosing is the name of the symbol the query appears in
+	// (empty for top-level code)
+	Enclosing string
+
+	// EnclosingClass is the class of the enclosing symbol (for methods)
+	EnclosingClass string
+}
+
+// DocSection represents a section of a documentation file: a heading and
+// the text up to the next heading.
+type DocSection struct {
+	// Title is the text of the heading, or the file name for the text
+	// before the first heading
+	Title string
+
+	// Path holds the titles of the enclosing sections and this one, empty
+	// for the text before the first heading
+	Path []string
+
+	// Level is the heading level (1-6), or 0 for the text before the first
+	// heading
+	Level int
+
+	// StartLine is the line of the heading (1-based)
+	StartLine int
+
+	// EndLine is the last line of the section (1-based)
+	EndLine int
+
+	// Content is the text of the section, heading included
+	Content string
+
+	// Mentions are the code spans of the section that name a symbol
+	// (`Parse`, `graph.GenerateID`, `Store.Get()`), without call
+	// parentheses
+	Mentions []string
+}
+
+// Resource represents an infrastructure resource: a Terraform block, a
+// Kubernetes object or a Docker build stage.
+type Resource struct {
+	// Name identifies the resource on its platform: the Terraform address
+	// (aws_s3_bucket.logs, module.vpc, var.region), the Kubernetes kind and
+	// name (Deployment/api), or the Docker stage name ("stage 1" for
+	// unnamed stages)
+	Name string
+
+	// Kind is the kind of resource: the Terraform block type (resource,
+	// data, module, variable, output, local), the Kubernetes kind, or stage
+	Kind string
+
+	// Platform is terraform, kubernetes or docker
+	Platform string
+
+	// StartLine is the starting line number (1-based)
+	StartLine int
+
+	// EndLine is the ending line number (1-based)
+	EndLine int
+
+	// Content is the source of the resource
+	Content string
+
+	// Properties are recorded on the resource node: the Terraform resource
+	// type, the Kubernetes namespace, the base image of a stage, ...
+	Properties map[string]any
+
+	// References are the names of the resources of the same platform this
+	// one refers to: Terraform addresses, Kubernetes kinds and names, or
+	// stage names
+	References []string
+
+	// Labels are the labels of the pods of a Kubernetes workload
+	Labels map[string]string
+
+	// Selector is the pod selector of a Kubernetes Service
+	Selector map[string]string
+
+	// Images are the container images a Kubernetes workload runs
+	Images []string
+
+	// Builds are the Go packages a Docker stage builds, as written
+	Builds []string
+}
+
 // ParseResult contains all parsed information from a source file.
 type ParseResult struct {
 	// Package is the package name
@@ -113,8 +398,29 @@ type ParseResult struct {
 	// Type annotations found in the file
 	TypeRefs []TypeAnnotation
 
+	// References to variables, constants and fields found in the file
+	VarRefs []VariableRef
+
 	// Class heritage information
 	Heritage []ClassHeritage
+
+	// HTTP routes registered or declared in the file
+	Routes []Route
+
+	// Routers of other files mounted under a path prefix by the file
+	RouteMounts []RouteMount
+
+	// SQL tables created or altered in the file
+	Tables []TableDef
+
+	// SQL tables read or written by the queries of the file
+	TableRefs []TableRef
+
+	// Infrastructure resources declared in the file
+	Resources []Resource
+
+	// Sections of a documentation file, one per heading
+	Sections []DocSection
 }
 
 // Parser defines the interface for language-specific parsers.
@@ -0,0 +1,765 @@
+package parsers
+
+import (
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// PHPParser parses PHP source code.
+//
+// The source is tokenized (php_lexer.go) and parsed declaration by
+// declaration, following the brace structure of namespaces and classes.
+// Classes, interfaces, traits and enums are symbols, as are methods and
+// functions. A parent class becomes an EXTENDS, and implemented interfaces
+// and used traits IMPLEMENTS. use declarations are imports, and so are the
+// files included with require and include. Attributes are recorded as
+// decorators.
+type PHPParser struct{}
+
+// NewPHPParser creates a new PHP parser.
+func NewPHPParser() *PHPParser {
+	return &PHPParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *PHPParser) Language() string {
+	return "php"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *PHPParser) SupportsFile(filename string) bool {
+	return strings.HasSuffix(filename, ".php")
+}
+
+// Parse parses PHP source code and extracts symbols, imports, calls, etc.
+func (p *PHPParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	source := string(content)
+	pp := &phpParser{
+		source:     source,
+		toks:       lexPHP(source),
+		aliases:    make(map[string]string),
+		fields:     make(map[string]map[string]string),
+		fieldCalls: make(map[int]string),
+		heritage:   make(map[string]int),
+		result: &ParseResult{
+			Symbols:  []ParsedSymbol{},
+			Imports:  []ImportStatement{},
+			Calls:    []CallSite{},
+			TypeRefs: []TypeAnnotation{},
+			Heritage: []ClassHeritage{},
+		},
+	}
+	pp.matchBrackets()
+	pp.statements(0, len(pp.toks))
+	pp.finish()
+	setDocComments(pp.result, source, phpDocComments)
+	return pp.result, nil
+}
+
+// phpModifiers are the modifiers of PHP declarations.
+var phpModifiers = map[string]bool{
+	"public": true, "protected": true, "private": true, "static": true,
+	"abstract": true, "final": true, "readonly": true, "var": true,
+}
+
+// phpNotCalled are the constructs and keywords followed by parentheses
+// that are not function calls.
+var phpNotCalled = map[string]bool{
+	"if": true, "elseif": true, "while": true, "for": true, "foreach": true,
+	"switch": true, "match": true, "catch": true, "function": true, "fn": true,
+	"array": true, "list": true, "isset": true, "empty": true, "unset": true,
+	"echo": true, "print": true, "return": true, "exit": true, "die": true,
+	"eval": true, "declare": true, "use": true, "new": true, "clone": true,
+	"and": true, "or": true, "xor": true, "instanceof": true, "yield": true,
+	"require": true, "require_once": true, "include": true, "include_once": true,
+	"static": true, "self": true, "parent": true,
+}
+
+// phpScalarTypes are the types that are not classes.
+var phpScalarTypes = map[string]bool{
+	"int": true, "float": true, "string": true, "bool": true, "array": true,
+	"object": true, "mixed": true, "void": true, "null": true, "callable": true,
+	"iterable": true, "never": true, "false": true, "true": true,
+	"self": true, "static": true, "parent": true,
+}
+
+// phpScope describes the class members are parsed in.
+type phpScope struct {
+	class string
+
+	// entry tells how a framework calls the methods of the class: "action"
+	// when the public methods are controller actions, "handler" when handle
+	// or __invoke is called by a queue, console or middleware stack
+	entry string
+}
+
+// phpParser parses the token stream of a PHP file.
+type phpParser struct {
+	source string
+	toks   []phpToken
+
+	// match maps the index of each bracket to the index of its partner
+	match []int
+
+	result *ParseResult
+
+	// aliases maps the names imported by use declarations to the short
+	// names of the classes they refer to (use Foo\Bar as Baz)
+	aliases map[string]string
+
+	// fields maps class names to the declared or assigned classes of
+	// their properties
+	fields map[string]map[string]string
+
+	// fieldCalls maps the indices of calls on a property ($this->repo->save())
+	// to the property; their receivers are resolved once all classes of the
+	// file are known
+	fieldCalls map[int]string
+
+	// heritage maps class names to their index in result.Heritage
+	heritage map[string]int
+}
+
+func (p *phpParser) tok(i int) phpToken {
+	if i < 0 || i >= len(p.toks) {
+		return phpToken{kind: phpEOF, pos: len(p.source), end: len(p.source)}
+	}
+	return p.toks[i]
+}
+
+// is reports whether the token at index i is the identifier or punctuator
+// text; identifiers are compared case-insensitively, like PHP keywords.
+func (p *phpParser) is(i int, text string) bool {
+	t := p.tok(i)
+	return t.kind == phpPunct && t.text == text || t.kind == phpIdent && strings.EqualFold(t.text, text)
+}
+
+// ident returns the identifier at index i, or "".
+func (p *phpParser) ident(i int) string {
+	if t := p.tok(i); t.kind == phpIdent {
+		return t.text
+	}
+	return ""
+}
+
+// variable returns the name of the variable at index i, or "".
+func (p *phpParser) variable(i int) string {
+	if t := p.tok(i); t.kind == phpVar {
+		return t.text
+	}
+	return ""
+}
+
+// closing returns the index of the token closing the bracket opened at
+// index i.
+func (p *phpParser) closing(i int) int {
+	if i < 0 || i >= len(p.toks) || p.match[i] < 0 {
+		return i
+	}
+	return p.match[i]
+}
+
+// matchBrackets pairs the brackets of the file; attributes (#[...]) close
+// with ']'. Unclosed brackets are paired with the end of the file.
+func (p *phpParser) matchBrackets() {
+	p.match = make([]int, len(p.toks))
+	var stack []int
+	for i, t := range p.toks {
+		p.match[i] = -1
+		if t.kind != phpPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{", "#[":
+			stack = append(stack, i)
+		case ")", "]", "}":
+			if n := len(stack); n > 0 {
+				p.match[stack[n-1]] = i
+				p.match[i] = stack[n-1]
+				stack = stack[:n-1]
+			}
+		}
+	}
+	for _, open := range stack {
+		p.match[open] = len(p.toks)
+	}
+}
+
+// statementEnd returns the index of the ';' ending the statement starting
+// at index i, or of the '}' closing its block.
+func (p *phpParser) statementEnd(i int) int {
+	for j := i; j < len(p.toks); j++ {
+		switch {
+		case p.is(j, ";"):
+			return j
+		case p.is(j, "(") || p.is(j, "[") || p.is(j, "#["):
+			j = p.closing(j)
+		case p.is(j, "{"):
+			return p.closing(j)
+		case p.is(j, "}"):
+			return j - 1
+		}
+	}
+	return len(p.toks)
+}
+
+// short returns the class a name refers to: its last segment, resolved
+// through the aliases of use declarations.
+func (p *phpParser) short(name string) string {
+	name = name[strings.LastIndexByte(name, '\\')+1:]
+	if class, ok := p.aliases[name]; ok {
+		return class
+	}
+	return name
+}
+
+// text returns the source from the token at index i to the token at
+// index j, exclusive, with whitespace collapsed.
+func (p *phpParser) text(i, j int) string {
+	start, end := p.tok(i).pos, p.tok(j-1).end
+	if end <= start {
+		return ""
+	}
+	return strings.Join(strings.Fields(p.source[start:end]), " ")
+}
+
+// define appends a symbol declared from the token at index start to the
+// token at index last.
+func (p *phpParser) define(sym ParsedSymbol, start, last int) {
+	first, end := p.tok(start), p.tok(min(last, len(p.toks)-1))
+	sym.StartLine = first.line
+	sym.EndLine = max(end.endLine, first.line)
+	if end.end > first.pos {
+		sym.Content = p.source[first.pos:end.end]
+	}
+	p.result.Symbols = append(p.result.Symbols, sym)
+}
+
+// addHeritage records that class extends, implements or uses the given
+// classes, interfaces and traits.
+func (p *phpParser) addHeritage(class string, extends, implements []string) {
+	index, ok := p.heritage[class]
+	if !ok {
+		index = len(p.result.Heritage)
+		p.heritage[class] = index
+		p.result.Heritage = append(p.result.Heritage, ClassHeritage{ClassName: class})
+	}
+	h := &p.result.Heritage[index]
+	h.Extends = append(h.Extends, extends...)
+	h.Implements = append(h.Implements, implements...)
+}
+
+// names returns the names listed from index i (Foo, Bar\Baz), resolved
+// with short, and the index after the list.
+func (p *phpParser) names(i int) ([]string, int) {
+	var names []string
+	for {
+		name := p.ident(i)
+		if name == "" {
+			return names, i
+		}
+		names = append(names, p.short(name))
+		if !p.is(i+1, ",") {
+			return names, i + 1
+		}
+		i += 2
+	}
+}
+
+// attributes returns the names of the attributes in the attribute groups
+// from index i (#[Route('/users'), Deprecated]), and the index after them.
+func (p *phpParser) attributes(i int) ([]string, int) {
+	var names []string
+	for p.is(i, "#[") {
+		end := p.closing(i)
+		for j := i + 1; j < end; j++ {
+			if name := p.ident(j); name != "" && (p.is(j-1, "#[") || p.is(j-1, ",")) {
+				names = append(names, p.short(name))
+			}
+			if p.is(j, "(") || p.is(j, "[") {
+				j = p.closing(j)
+			}
+		}
+		i = end + 1
+	}
+	return names, i
+}
+
+// statements parses the top-level statements between the tokens at
+// indices from, inclusive, and to, exclusive.
+func (p *phpParser) statements(from, to int) {
+	loose := from
+	flush := func(end int) {
+		if loose < end {
+			p.calls(loose-1, end, "", "", make(map[string]string))
+		}
+	}
+
+	for j := from; j < to && j < len(p.toks); {
+		start := j
+		decorators, k := p.attributes(j)
+		for phpModifiers[strings.ToLower(p.ident(k))] {
+			k++
+		}
+		switch {
+		case p.is(k, "namespace") && p.ident(k+1) != "" || p.is(k, "namespace") && p.is(k+1, "{"):
+			flush(start)
+			next := k + 1
+			if name := p.ident(next); name != "" {
+				p.result.Package = strings.TrimPrefix(name, "\\")
+				next++
+			}
+			if p.is(next, "{") {
+				// Braced namespaces: namespace App { ... }
+				end := p.closing(next)
+				p.statements(next+1, end)
+				j = end + 1
+			} else {
+				j = next + 1
+			}
+			loose = j
+		case p.is(k, "use") && k == start:
+			flush(start)
+			j = p.use(k) + 1
+			loose = j
+		case (p.is(k, "class") || p.is(k, "interface") || p.is(k, "trait") || p.is(k, "enum") && p.ident(k+1) != "") &&
+			p.ident(k+1) != "" && !p.is(start-1, "::") && !p.is(start-1, "new"):
+			flush(start)
+			j = p.typeDeclaration(start, k, decorators) + 1
+			loose = j
+		case p.is(k, "function") && p.ident(k+1) != "" || p.is(k, "function") && p.is(k+1, "&") && p.ident(k+2) != "":
+			flush(start)
+			j = p.function(start, k, ParsedSymbol{Kind: graph.NodeFunction, IsExported: true, Decorators: decorators}, phpScope{}) + 1
+			loose = j
+		case p.is(j, "{") || p.is(j, "(") || p.is(j, "["):
+			j = p.closing(j) + 1
+		default:
+			j++
+		}
+	}
+	flush(min(to, len(p.toks)))
+}
+
+// use parses the use declaration at index i as imports and returns the
+// index of its ';'.
+func (p *phpParser) use(i int) int {
+	end := p.statementEnd(i)
+	line := p.tok(i).line
+	j := i + 1
+	if p.is(j, "function") || p.is(j, "const") {
+		j++
+	}
+	add := func(name, alias string) {
+		name = strings.TrimPrefix(name, "\\")
+		symbol := name[strings.LastIndexByte(name, '\\')+1:]
+		if alias != "" {
+			p.aliases[alias] = symbol
+		}
+		p.result.Imports = append(p.result.Imports, ImportStatement{
+			ModulePath: name,
+			Symbols:    []string{symbol},
+			Alias:      alias,
+			StartLine:  line,
+		})
+	}
+	for j < end {
+		name := p.ident(j)
+		if name == "" {
+			j++
+			continue
+		}
+		if p.is(j+1, "\\") && p.is(j+2, "{") {
+			// Group use: use App\Models\{User, Post as Article};
+			group := p.closing(j + 2)
+			for k := j + 3; k < group; k++ {
+				if p.is(k, "function") || p.is(k, "const") {
+					continue
+				}
+				member := p.ident(k)
+				if member == "" {
+					continue
+				}
+				alias := ""
+				if p.is(k+1, "as") {
+					alias = p.ident(k + 2)
+					k += 2
+				}
+				add(name+"\\"+member, alias)
+			}
+			j = group + 1
+			continue
+		}
+		alias := ""
+		if p.is(j+1, "as") {
+			alias = p.ident(j + 2)
+			j += 2
+		}
+		add(name, alias)
+		j++
+	}
+	return end
+}
+
+// typeDeclaration parses the class, interface, trait or enum whose keyword
+// is at index kw and returns the index of its closing brace.
+func (p *phpParser) typeDeclaration(start, kw int, decorators []string) int {
+	keyword := strings.ToLower(p.ident(kw))
+	name := p.ident(kw + 1)
+	open := kw + 2
+	for open < len(p.toks) && !p.is(open, "{") && !p.is(open, ";") {
+		open++
+	}
+	end := p.closing(open)
+
+	var parents, interfaces []string
+	for j := kw + 2; j < open; j++ {
+		switch {
+		case p.is(j, "extends"):
+			parents, j = p.names(j + 1)
+			j--
+		case p.is(j, "implements"):
+			interfaces, j = p.names(j + 1)
+			j--
+		}
+	}
+	if len(parents) > 0 || len(interfaces) > 0 {
+		p.addHeritage(name, parents, interfaces)
+	}
+
+	kind := graph.NodeClass
+	switch keyword {
+	case "interface":
+		kind = graph.NodeInterface
+	case "enum":
+		kind = graph.NodeEnum
+	}
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       kind,
+		Signature:  p.text(start, open),
+		IsExported: true,
+		Decorators: decorators,
+	}, start, end)
+
+	scope := phpScope{class: name}
+	switch {
+	case len(parents) > 0 && strings.HasSuffix(name, "Controller"):
+		scope.entry = "action"
+	case len(parents) > 0 || len(interfaces) > 0:
+		scope.entry = "handler"
+	}
+	p.members(open+1, end, keyword == "interface", scope)
+	return end
+}
+
+// members parses the members of a class body between the tokens at
+// indices from, inclusive, and to, exclusive.
+func (p *phpParser) members(from, to int, iface bool, scope phpScope) {
+	for j := from; j < to && j < len(p.toks); {
+		start := j
+		decorators, k := p.attributes(j)
+		public, static := true, false
+		for ; k < to && phpModifiers[strings.ToLower(p.ident(k))]; k++ {
+			switch strings.ToLower(p.ident(k)) {
+			case "private", "protected":
+				public = false
+			case "static":
+				static = true
+			}
+		}
+		switch {
+		case p.is(k, "use"):
+			// Traits: use Loggable, Cacheable;
+			traits, _ := p.names(k + 1)
+			if len(traits) > 0 {
+				p.addHeritage(scope.class, nil, traits)
+			}
+			j = p.statementEnd(k) + 1
+		case p.is(k, "function"):
+			entry := public && !static && !iface
+			name := p.ident(k + 1)
+			if p.is(k+1, "&") {
+				name = p.ident(k + 2)
+			}
+			switch scope.entry {
+			case "action":
+				entry = entry && !strings.HasPrefix(name, "__")
+			case "handler":
+				entry = entry && (name == "handle" || name == "__invoke")
+			default:
+				entry = false
+			}
+			j = p.function(start, k, ParsedSymbol{
+				Kind:         graph.NodeMethod,
+				ClassName:    scope.class,
+				IsExported:   public,
+				IsEntryPoint: entry,
+				Decorators:   decorators,
+			}, scope) + 1
+		case p.is(k, "const") || p.is(k, "case"):
+			j = p.statementEnd(k) + 1
+		default:
+			// Properties: private ?UserRepository $repo = null;
+			end := p.statementEnd(k)
+			var types []string
+			for m := k; m < end; m++ {
+				if name := p.ident(m); name != "" && !p.is(m-1, "=") {
+					types = append(types, name)
+				}
+				if field := p.variable(m); field != "" {
+					p.field(scope.class, field, types)
+					break
+				}
+			}
+			j = end + 1
+		}
+	}
+}
+
+// field records the declared type of the property field of class, when it
+// is a class.
+func (p *phpParser) field(class, field string, types []string) {
+	for _, typ := range types {
+		if phpScalarTypes[strings.ToLower(typ)] || phpModifiers[strings.ToLower(typ)] {
+			continue
+		}
+		if p.fields[class] == nil {
+			p.fields[class] = make(map[string]string)
+		}
+		p.fields[class][field] = p.short(typ)
+		return
+	}
+}
+
+// function parses the function or method sym declared with the function
+// keyword at index kw, filling in its name, signature and range, and
+// returns the index of the token ending it: its closing brace or the ';'
+// of an abstract method.
+func (p *phpParser) function(start, kw int, sym ParsedSymbol, scope phpScope) int {
+	nameIndex := kw + 1
+	if p.is(nameIndex, "&") {
+		nameIndex++
+	}
+	name := p.ident(nameIndex)
+	open := nameIndex + 1
+	if name == "" || !p.is(open, "(") {
+		return kw
+	}
+	closeParen := p.closing(open)
+
+	// The body, or the ';' of abstract and interface methods
+	body := closeParen + 1
+	for body < len(p.toks) && !p.is(body, "{") && !p.is(body, ";") {
+		body++
+	}
+	last := body
+	if p.is(body, "{") {
+		last = p.closing(body)
+	}
+
+	sym.Name = name
+	sym.Signature = p.text(start, body)
+	p.define(sym, start, last)
+
+	vars := p.parameters(open, closeParen, name == "__construct", scope.class)
+	if p.is(body, "{") {
+		p.calls(body, last, name, scope.class, vars)
+	}
+	return last
+}
+
+// parameters returns the classes of the parameters between the tokens at
+// indices open and closeParen, by variable name. The promoted parameters
+// of a constructor (private UserRepository $repo) are also properties of
+// class.
+func (p *phpParser) parameters(open, closeParen int, constructor bool, class string) map[string]string {
+	vars := make(map[string]string)
+	var types []string
+	promoted := false
+	for j := open + 1; j < closeParen; j++ {
+		switch {
+		case p.is(j, ","):
+			types, promoted = nil, false
+		case p.is(j, "#["):
+			j = p.closing(j)
+		case p.is(j, "="):
+			// Default values
+			for j+1 < closeParen && !p.is(j+1, ",") {
+				j++
+				if p.is(j, "(") || p.is(j, "[") {
+					j = p.closing(j)
+				}
+			}
+		case p.variable(j) != "":
+			name := p.variable(j)
+			vars[name] = ""
+			for _, typ := range types {
+				if !phpScalarTypes[strings.ToLower(typ)] {
+					vars[name] = p.short(typ)
+					break
+				}
+			}
+			if promoted && constructor && class != "" {
+				p.field(class, name, types)
+			}
+		case phpModifiers[strings.ToLower(p.ident(j))]:
+			promoted = true
+		case p.ident(j) != "":
+			types = append(types, p.ident(j))
+		}
+	}
+	return vars
+}
+
+// calls records the calls between the tokens at indices from and to,
+// exclusive, made in the function enclosing of class; vars holds the
+// variables and the classes they hold instances of, when known.
+func (p *phpParser) calls(from, to int, enclosing, class string, vars map[string]string) {
+	for j := from + 1; j < to && j < len(p.toks); j++ {
+		t := p.toks[j]
+		line := t.line
+		call := CallSite{StartLine: line, EndLine: line, Enclosing: enclosing, EnclosingClass: class}
+
+		switch {
+		case t.kind == phpVar && p.is(j+1, "="):
+			// $user = new User(...)
+			if typ := p.constructed(j + 2); typ != "" {
+				vars[t.text] = typ
+			}
+			continue
+		case t.kind == phpVar && t.text == "this" && p.is(j+1, "->") && p.ident(j+2) != "" && p.is(j+3, "="):
+			// $this->repo = new UserRepository()
+			if typ := p.constructed(j + 4); typ != "" && class != "" {
+				p.field(class, p.ident(j+2), []string{typ})
+			}
+			continue
+		case t.kind == phpString && strings.Contains(t.text, "@") && p.routeArgument(j):
+			// Route::get('/users', 'UserController@index')
+			controller, action, _ := strings.Cut(t.text, "@")
+			call.Name, call.Receiver = action, p.short(controller)
+		case p.is(j, "[") && p.ident(j+1) != "" && p.is(j+2, "::") && p.is(j+3, "class") &&
+			p.is(j+4, ",") && p.tok(j+5).kind == phpString && p.is(j+6, "]"):
+			// Callables: [UserController::class, 'index']
+			call.Name, call.Receiver = p.tok(j+5).text, p.short(p.ident(j+1))
+			j += 6
+		case p.is(j, "require") || p.is(j, "require_once") || p.is(j, "include") || p.is(j, "include_once"):
+			p.require(j)
+			continue
+		case p.is(j, "new") && p.ident(j+1) != "" && !p.is(j+1, "class"):
+			// Instantiations call the constructor: new User(...)
+			if typ := strings.ToLower(p.ident(j + 1)); typ == "self" || typ == "static" {
+				call.Name = class
+			} else {
+				call.Name = p.short(p.ident(j + 1))
+			}
+			j++
+		case t.kind != phpIdent || !p.is(j+1, "(") || p.is(j-1, "function") || p.is(j-1, "new"):
+			continue
+		case p.is(j-1, "->") || p.is(j-1, "?->"):
+			call.Name = t.text
+			call.Receiver = p.receiver(j-2, vars, class, len(p.result.Calls))
+		case p.is(j-1, "::"):
+			call.Name = t.text
+			switch receiver := strings.ToLower(p.ident(j - 2)); receiver {
+			case "self", "static":
+				call.Receiver = class
+			case "parent":
+				if index, ok := p.heritage[class]; ok && len(p.result.Heritage[index].Extends) > 0 {
+					call.Receiver = p.result.Heritage[index].Extends[0]
+				}
+			default:
+				call.Receiver = p.short(p.ident(j - 2))
+			}
+		case phpNotCalled[strings.ToLower(t.text)]:
+			continue
+		default:
+			call.Name = p.short(t.text)
+		}
+		if call.Name != "" {
+			p.result.Calls = append(p.result.Calls, call)
+		}
+	}
+}
+
+// routeArgument reports whether the string at index i is an argument of a
+// Laravel route definition (Route::get(...)).
+func (p *phpParser) routeArgument(i int) bool {
+	for j := i - 1; j >= 0 && !p.is(j, ";") && !p.is(j, "{"); j-- {
+		if p.is(j, "(") && p.closing(j) > i {
+			return p.is(j-2, "::") && p.short(p.ident(j-3)) == "Route"
+		}
+	}
+	return false
+}
+
+// constructed returns the class instantiated by the expression at index i
+// (new UserRepository(...)), or "".
+func (p *phpParser) constructed(i int) string {
+	if !p.is(i, "new") || p.ident(i+1) == "" || p.is(i+1, "class") {
+		return ""
+	}
+	return p.short(p.ident(i + 1))
+}
+
+// receiver resolves the receiver of a method call whose receiver
+// expression ends at index j: $this is the class, a variable the class it
+// holds an instance of, and a property of $this the class of the property.
+// index is the index the call will have in result.Calls.
+func (p *phpParser) receiver(j int, vars map[string]string, class string, index int) string {
+	t := p.tok(j)
+	switch {
+	case t.kind == phpVar && t.text == "this":
+		return class
+	case t.kind == phpVar:
+		if typ := vars[t.text]; typ != "" {
+			return typ
+		}
+		return t.text
+	case t.kind == phpIdent && p.is(j-1, "->") && p.variable(j-2) == "this" && class != "":
+		p.fieldCalls[index] = t.text
+		return t.text
+	case t.kind == phpIdent:
+		return t.text
+	}
+	return ""
+}
+
+// require records the require or include at index i as an import when its
+// path is a literal, or a literal appended to __DIR__, which becomes a path
+// starting with "./".
+func (p *phpParser) require(i int) {
+	j := i + 1
+	if p.is(j, "(") {
+		j++
+	}
+	dir := p.is(j, "__DIR__") && p.is(j+1, ".")
+	if dir {
+		j += 2
+	}
+	t := p.tok(j)
+	if t.kind != phpString || t.text == "" || p.is(j+1, ".") {
+		return
+	}
+	path := t.text
+	if dir {
+		// __DIR__ . '/helpers.php' is relative to the file
+		path = "." + path
+	}
+	p.result.Imports = append(p.result.Imports, ImportStatement{
+		ModulePath: path,
+		IsRelative: true,
+		StartLine:  p.tok(i).line,
+	})
+}
+
+// finish resolves the receivers of calls on properties to the classes of
+// the properties.
+func (p *phpParser) finish() {
+	for index, field := range p.fieldCalls {
+		call := &p.result.Calls[index]
+		if typ := p.fields[call.EnclosingClass][field]; typ != "" {
+			call.Receiver = typ
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package parsers
+
+import "strings"
+
+// phpTokenKind is the kind of a PHP token.
+type phpTokenKind int
+
+const (
+	phpEOF phpTokenKind = iota
+	phpIdent
+	phpVar
+	phpPunct
+	phpString
+	phpNumber
+)
+
+// phpToken is a token of PHP source.
+type phpToken struct {
+	kind phpTokenKind
+
+	// text is the token text: a name with its namespace separators
+	// (App\Models\User), a variable without its '$', or the body of a
+	// string literal
+	text string
+
+	// pos and end are the byte offsets of the token in the source
+	pos, end int
+
+	// line and endLine are the 1-based lines of pos and end, which differ
+	// for multi-line strings
+	line, endLine int
+}
+
+// phpPunctuators are the multi-character punctuators the parser needs,
+// longest first.
+var phpPunctuators = []string{"<=>", "===", "!==", "?->", "??=", "...", "**=", "::", "->", "=>", "#[", "??", "==", "!=", "<=", ">=", "&&", "||", "++", "--", ".=", "+=", "-=", "*=", "/=", "**"}
+
+// phpLexer splits PHP source into tokens, skipping whitespace, comments
+// and the inline HTML outside <?php ... ?> tags. Names include their
+// namespace separators. Strings cover escapes, heredocs and nowdocs;
+// interpolated variables are part of the string.
+type phpLexer struct {
+	src  string
+	pos  int
+	line int
+	toks []phpToken
+}
+
+// lexPHP tokenizes source.
+func lexPHP(source string) []phpToken {
+	l := &phpLexer{src: source, line: 1}
+	l.html()
+	for l.pos < len(l.src) {
+		l.token()
+	}
+	return l.toks
+}
+
+// skip moves to index end, counting the lines passed.
+func (l *phpLexer) skip(end int) {
+	end = min(end, len(l.src))
+	l.line += strings.Count(l.src[l.pos:end], "\n")
+	l.pos = end
+}
+
+// emit appends a token spanning src[start:end] and moves past it.
+func (l *phpLexer) emit(kind phpTokenKind, text string, start, end int) {
+	lines := strings.Count(l.src[start:end], "\n")
+	l.toks = append(l.toks, phpToken{kind: kind, text: text, pos: start, end: end, line: l.line, endLine: l.line + lines})
+	l.line += lines
+	l.pos = end
+}
+
+// html skips inline HTML up to and including the next opening tag.
+func (l *phpLexer) html() {
+	rest := l.src[l.pos:]
+	i := strings.Index(rest, "<?")
+	if i < 0 {
+		l.skip(len(l.src))
+		return
+	}
+	tag := 2
+	switch {
+	case strings.HasPrefix(strings.ToLower(rest[i:]), "<?php"):
+		tag = 5
+	case strings.HasPrefix(rest[i:], "<?="):
+		tag = 3
+	}
+	l.skip(l.pos + i + tag)
+}
+
+// token lexes the token at the current position.
+func (l *phpLexer) token() {
+	c := l.src[l.pos]
+	rest := l.src[l.pos:]
+	switch {
+	case c == '\n':
+		l.line++
+		l.pos++
+	case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+		l.pos++
+	case strings.HasPrefix(rest, "?>"):
+		// The closing tag ends the statement
+		l.emit(phpPunct, ";", l.pos, l.pos+2)
+		l.html()
+	case strings.HasPrefix(rest, "//") || c == '#' && !strings.HasPrefix(rest, "#["):
+		end := len(rest)
+		if i := strings.IndexByte(rest, '\n'); i >= 0 {
+			end = i
+		}
+		if i := strings.Index(rest[:end], "?>"); i >= 0 {
+			end = i
+		}
+		l.pos += end
+	case strings.HasPrefix(rest, "/*"):
+		end := strings.Index(rest[2:], "*/")
+		if end < 0 {
+			l.skip(len(l.src))
+		} else {
+			l.skip(l.pos + end + 4)
+		}
+	case strings.HasPrefix(rest, "<<<"):
+		l.heredoc()
+	case c == '"' || c == '\'' || c == '`':
+		l.quoted(c)
+	case isJSDigit(c) || c == '.' && len(rest) > 1 && isJSDigit(rest[1]):
+		end := l.pos + 1
+		for end < len(l.src) && (isPyNamePart(l.src[end]) || l.src[end] == '.' ||
+			(l.src[end] == '+' || l.src[end] == '-') && (l.src[end-1] == 'e' || l.src[end-1] == 'E')) {
+			end++
+		}
+		l.emit(phpNumber, l.src[l.pos:end], l.pos, end)
+	case c == '$' && len(rest) > 1 && isPHPNameStart(rest[1]):
+		end := l.nameEnd(l.pos + 1)
+		l.emit(phpVar, l.src[l.pos+1:end], l.pos, end)
+	case isPHPNameStart(c) || c == '\\' && len(rest) > 1 && isPHPNameStart(rest[1]):
+		// Qualified names: App\Models\User, \strlen
+		end := l.nameEnd(l.pos + 1)
+		for end+1 < len(l.src) && l.src[end] == '\\' && isPHPNameStart(l.src[end+1]) {
+			end = l.nameEnd(end + 1)
+		}
+		l.emit(phpIdent, l.src[l.pos:end], l.pos, end)
+	default:
+		for _, punct := range phpPunctuators {
+			if strings.HasPrefix(rest, punct) {
+				l.emit(phpPunct, punct, l.pos, l.pos+len(punct))
+				return
+			}
+		}
+		l.emit(phpPunct, rest[:1], l.pos, l.pos+1)
+	}
+}
+
+// isPHPNameStart reports whether c can start a PHP name; bytes of
+// multi-byte characters are name characters.
+func isPHPNameStart(c byte) bool {
+	return isPyNameStart(c) || c >= 0x80
+}
+
+// nameEnd returns the index after the name characters from index i.
+func (l *phpLexer) nameEnd(i int) int {
+	for i < len(l.src) && (isPyNamePart(l.src[i]) || l.src[i] >= 0x80) {
+		i++
+	}
+	return i
+}
+
+// quoted lexes the string literal whose quote is at the current position.
+func (l *phpLexer) quoted(quote byte) {
+	i := l.pos + 1
+	for i < len(l.src) && l.src[i] != quote {
+		if l.src[i] == '\\' {
+			i++
+		}
+		i++
+	}
+	bodyEnd := min(i, len(l.src))
+	body := l.src[l.pos+1 : bodyEnd]
+	if quote == '\'' {
+		body = strings.NewReplacer(`\'`, `'`, `\\`, `\`).Replace(body)
+	}
+	l.emit(phpString, body, l.pos, min(bodyEnd+1, len(l.src)))
+}
+
+// heredoc lexes the heredoc (<<<EOT) or nowdoc (<<<'EOT') at the current
+// position. Its body ends at the first line consisting of the identifier,
+// which may be indented and followed by more of the expression.
+func (l *phpLexer) heredoc() {
+	i := l.pos + 3
+	for i < len(l.src) && (l.src[i] == ' ' || l.src[i] == '\t') {
+		i++
+	}
+	quoted := i < len(l.src) && (l.src[i] == '\'' || l.src[i] == '"')
+	if quoted {
+		i++
+	}
+	end := l.nameEnd(i)
+	label := l.src[i:end]
+	newline := strings.IndexByte(l.src[end:], '\n')
+	if label == "" || newline < 0 {
+		l.emit(phpPunct, "<", l.pos, l.pos+1)
+		return
+	}
+
+	bodyStart := end + newline + 1
+	for line := bodyStart; line < len(l.src); {
+		next := strings.IndexByte(l.src[line:], '\n')
+		text := l.src[line:]
+		if next >= 0 {
+			text = text[:next]
+		}
+		trimmed := strings.TrimLeft(text, " \t")
+		if strings.HasPrefix(trimmed, label) {
+			after := line + len(text) - len(trimmed) + len(label)
+			if after >= len(l.src) || !isPyNamePart(l.src[after]) {
+				l.emit(phpString, l.src[bodyStart:max(line-1, bodyStart)], l.pos, after)
+				return
+			}
+		}
+		if next < 0 {
+			break
+		}
+		line += next + 1
+	}
+	l.emit(phpString, l.src[bodyStart:], l.pos, len(l.src))
+}
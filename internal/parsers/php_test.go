@@ -0,0 +1,242 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestPHPParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ParseController", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`<?php
+
+namespace App\Http\Controllers;
+
+use App\Models\User;
+use App\Services\{Billing, Mailer as Postman};
+
+#[Middleware('auth')]
+final class UserController extends Controller implements HasMiddleware
+{
+    use Loggable, Cacheable;
+
+    private ?Billing $billing = null;
+
+    public function __construct(private UserRepository $repo, Postman $mailer)
+    {
+        $this->billing = new Billing();
+        $mailer->boot();
+    }
+
+    #[Get('/users')]
+    public function index(): array
+    {
+        $users = $this->repo->all();
+        $this->billing->charge($users);
+        $user = new User();
+        $user->save();
+        return array_map(fn($u) => $u->name, $users);
+    }
+
+    protected function authorize() { self::check(); parent::authorize(); }
+
+    public static function make(): static { return new static(); }
+}
+`)
+		result, err := NewPHPParser().Parse("app/Http/Controllers/UserController.php", content)
+		require.NoError(t, err)
+		assert.Equal(t, `App\Http\Controllers`, result.Package)
+
+		symbols := symbolsByName(result)
+		controller := symbols["UserController"]
+		assert.Equal(t, graph.NodeClass, controller.Kind)
+		assert.Equal(t, []string{"Middleware"}, controller.Decorators)
+		assert.Equal(t, "#[Middleware('auth')] final class UserController extends Controller implements HasMiddleware", controller.Signature)
+		assert.Equal(t, 8, controller.StartLine)
+		assert.Equal(t, 34, controller.EndLine)
+
+		index := symbols["UserController.index"]
+		assert.Equal(t, graph.NodeMethod, index.Kind)
+		assert.True(t, index.IsExported)
+		assert.True(t, index.IsEntryPoint, "public controller methods are actions")
+		assert.Equal(t, []string{"Get"}, index.Decorators)
+		assert.Equal(t, "#[Get('/users')] public function index(): array", index.Signature)
+		assert.Equal(t, 21, index.StartLine)
+		assert.Equal(t, 29, index.EndLine)
+
+		assert.False(t, symbols["UserController.__construct"].IsEntryPoint)
+		assert.False(t, symbols["UserController.make"].IsEntryPoint, "static methods are not actions")
+		authorize := symbols["UserController.authorize"]
+		assert.False(t, authorize.IsExported)
+		assert.False(t, authorize.IsEntryPoint)
+
+		require.Len(t, result.Heritage, 1)
+		assert.Equal(t, []string{"Controller"}, result.Heritage[0].Extends)
+		assert.Equal(t, []string{"HasMiddleware", "Loggable", "Cacheable"}, result.Heritage[0].Implements, "traits are mixed in")
+
+		assert.Equal(t, map[string][]string{
+			`App\Models\User`:      {"User"},
+			`App\Services\Billing`: {"Billing"},
+			`App\Services\Mailer`:  {"Mailer"},
+		}, importsByModule(result))
+
+		assert.NotNil(t, findCall(result.Calls, "Billing", 17), "new calls the class")
+		boot := findCall(result.Calls, "boot", 18)
+		require.NotNil(t, boot)
+		assert.Equal(t, "Mailer", boot.Receiver, "parameter types resolve through use aliases")
+
+		all := findCall(result.Calls, "all", 24)
+		require.NotNil(t, all)
+		assert.Equal(t, "UserRepository", all.Receiver, "promoted constructor parameters are properties")
+		assert.Equal(t, "index", all.Enclosing)
+
+		charge := findCall(result.Calls, "charge", 25)
+		require.NotNil(t, charge)
+		assert.Equal(t, "Billing", charge.Receiver, "typed properties")
+
+		save := findCall(result.Calls, "save", 27)
+		require.NotNil(t, save)
+		assert.Equal(t, "User", save.Receiver)
+		assert.NotNil(t, findCall(result.Calls, "array_map", 28))
+
+		check := findCall(result.Calls, "check", 31)
+		require.NotNil(t, check)
+		assert.Equal(t, "UserController", check.Receiver)
+		parent := findCall(result.Calls, "authorize", 31)
+		require.NotNil(t, parent)
+		assert.Equal(t, "Controller", parent.Receiver)
+		assert.NotNil(t, findCall(result.Calls, "UserController", 33), "new static calls the class")
+	})
+
+	t.Run("ParseRoutes", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`<?php
+use App\Http\Controllers\UserController;
+use Illuminate\Support\Facades\Route;
+
+Route::get('/users', [UserController::class, 'index']);
+Route::post('/users', 'UserController@store');
+`)
+		result, err := NewPHPParser().Parse("routes/web.php", content)
+		require.NoError(t, err)
+
+		index := findCall(result.Calls, "index", 5)
+		require.NotNil(t, index, "routes call their controller actions")
+		assert.Equal(t, "UserController", index.Receiver)
+		assert.Empty(t, index.Enclosing)
+
+		store := findCall(result.Calls, "store", 6)
+		require.NotNil(t, store)
+		assert.Equal(t, "UserController", store.Receiver)
+
+		get := findCall(result.Calls, "get", 5)
+		require.NotNil(t, get)
+		assert.Equal(t, "Route", get.Receiver)
+	})
+
+	t.Run("ParseDeclarations", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`<html><?php
+require_once __DIR__ . '/bootstrap.php';
+include 'lib/helpers.php';
+
+interface Repository extends Countable, Reader
+{
+    public function find(int $id): ?array;
+}
+
+trait Loggable
+{
+    public function log(string $message): void { error_log($message); }
+}
+
+enum Status: string
+{
+    case Active = 'active';
+    case Closed = 'closed';
+}
+
+class SendReminders extends Command
+{
+    public function handle(): int { return $this->remind(); }
+    private function remind(): int { return 0; }
+}
+
+function &helper(array $items) {
+    $text = <<<EOT
+    class Fake { }
+    EOT;
+    return $items;
+}
+?>
+<p><?= helper([]) ?></p>
+`)
+		result, err := NewPHPParser().Parse("legacy/index.php", content)
+		require.NoError(t, err)
+		assert.Empty(t, result.Package)
+
+		symbols := symbolsByName(result)
+		repository := symbols["Repository"]
+		assert.Equal(t, graph.NodeInterface, repository.Kind)
+		assert.Contains(t, symbols, "Repository.find", "abstract methods")
+		assert.Equal(t, 7, symbols["Repository.find"].EndLine)
+		assert.Equal(t, graph.NodeClass, symbols["Loggable"].Kind, "traits are classes")
+		assert.Equal(t, graph.NodeEnum, symbols["Status"].Kind)
+		assert.NotContains(t, symbols, "Fake", "heredocs are strings")
+
+		assert.True(t, symbols["SendReminders.handle"].IsEntryPoint, "framework handlers are entry points")
+		assert.False(t, symbols["SendReminders.remind"].IsEntryPoint)
+
+		helper := symbols["helper"]
+		assert.Equal(t, graph.NodeFunction, helper.Kind)
+		assert.True(t, helper.IsExported)
+		assert.Equal(t, 32, helper.EndLine)
+
+		require.Len(t, result.Heritage, 2)
+		assert.Equal(t, ClassHeritage{ClassName: "Repository", Extends: []string{"Countable", "Reader"}}, result.Heritage[0])
+
+		require.Len(t, result.Imports, 2)
+		assert.Equal(t, ImportStatement{ModulePath: "./bootstrap.php", IsRelative: true, StartLine: 2}, result.Imports[0])
+		assert.Equal(t, ImportStatement{ModulePath: "lib/helpers.php", IsRelative: true, StartLine: 3}, result.Imports[1])
+
+		assert.NotNil(t, findCall(result.Calls, "error_log", 12))
+		remind := findCall(result.Calls, "remind", 23)
+		require.NotNil(t, remind)
+		assert.Equal(t, "SendReminders", remind.Receiver)
+		assert.NotNil(t, findCall(result.Calls, "helper", 34), "calls in inline echo tags")
+	})
+
+	t.Run("ParseEmptyFile", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := NewPHPParser().Parse("empty.php", []byte("<?php\n"))
+		require.NoError(t, err)
+		assert.Empty(t, result.Symbols)
+		assert.Empty(t, result.Calls)
+	})
+}
+
+func TestPHPParser_Language(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "php", NewPHPParser().Language())
+}
+
+func TestPHPParser_FileDetection(t *testing.T) {
+	t.Parallel()
+
+	parser := NewPHPParser()
+	assert.True(t, parser.SupportsFile("app/Models/User.php"))
+	assert.True(t, parser.SupportsFile("routes/web.php"))
+	assert.False(t, parser.SupportsFile("composer.json"))
+	assert.False(t, parser.SupportsFile("resources/views/welcome.blade"))
+}
@@ -0,0 +1,489 @@
+package parsers
+
+import (
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// ProtoParser parses Protocol Buffers definitions (.proto files).
+//
+// Messages are classes, enums are enums, services are interfaces and their
+// rpcs are methods of the service. Nested messages and enums are named by
+// their path within the file (Order.Item), as other definitions refer to
+// them. Imports are the imported .proto paths, and the message types of
+// fields and of rpc requests and responses are type references; the
+// package qualifier of a type (google.protobuf.Timestamp) is dropped.
+type ProtoParser struct{}
+
+// NewProtoParser creates a new Protocol Buffers parser.
+func NewProtoParser() *ProtoParser {
+	return &ProtoParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *ProtoParser) Language() string {
+	return "proto"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *ProtoParser) SupportsFile(filename string) bool {
+	return strings.HasSuffix(filename, ".proto")
+}
+
+// Parse parses a .proto file and extracts messages, enums, services, rpcs,
+// imports and type references.
+func (p *ProtoParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	source := string(content)
+	pp := &pbParser{
+		source: source,
+		toks:   lexProto(source),
+		result: &ParseResult{
+			Symbols:  []ParsedSymbol{},
+			Imports:  []ImportStatement{},
+			Calls:    []CallSite{},
+			TypeRefs: []TypeAnnotation{},
+			Heritage: []ClassHeritage{},
+		},
+	}
+	for i := 0; i < len(pp.toks); {
+		i = pp.body(i, "")
+		// A stray closing brace at the top level
+		i++
+	}
+	pp.finish()
+	setDocComments(pp.result, source, protoDocComments)
+	return pp.result, nil
+}
+
+// pbTokenKind is the kind of a Protocol Buffers token.
+type pbTokenKind int
+
+const (
+	pbIdent pbTokenKind = iota
+	pbString
+	pbNumber
+	pbPunct
+)
+
+// pbToken is a token of a .proto file.
+type pbToken struct {
+	kind pbTokenKind
+
+	// text is the token text; strings keep their quotes
+	text string
+
+	// pos and end are the byte offsets of the token in the source
+	pos, end int
+
+	// line is the 1-based line of pos
+	line int
+}
+
+// lexProto splits a .proto file into tokens, skipping whitespace and
+// comments. Identifiers include the dots of qualified names (.pkg.Type).
+func lexProto(source string) []pbToken {
+	var toks []pbToken
+	line := 1
+	for pos := 0; pos < len(source); {
+		c := source[pos]
+		start := pos
+		switch {
+		case c == '\n':
+			line++
+			pos++
+			continue
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+			pos++
+			continue
+		case strings.HasPrefix(source[pos:], "//"):
+			for pos < len(source) && source[pos] != '\n' {
+				pos++
+			}
+			continue
+		case strings.HasPrefix(source[pos:], "/*"):
+			end := strings.Index(source[pos+2:], "*/")
+			if end < 0 {
+				end = len(source)
+			} else {
+				end += pos + 4
+			}
+			line += strings.Count(source[pos:end], "\n")
+			pos = end
+			continue
+		case c == '"' || c == '\'':
+			pos++
+			for pos < len(source) && source[pos] != c && source[pos] != '\n' {
+				if source[pos] == '\\' {
+					pos++
+				}
+				pos++
+			}
+			pos = min(pos+1, len(source))
+			toks = append(toks, pbToken{kind: pbString, text: source[start:pos], pos: start, end: pos, line: line})
+			continue
+		case isPbIdentStart(c) || c == '.' && pos+1 < len(source) && isPbIdentStart(source[pos+1]):
+			for pos < len(source) && (isPbIdentStart(source[pos]) || isPbDigit(source[pos]) || source[pos] == '.') {
+				pos++
+			}
+			toks = append(toks, pbToken{kind: pbIdent, text: source[start:pos], pos: start, end: pos, line: line})
+			continue
+		case isPbDigit(c):
+			for pos < len(source) && (isPbIdentStart(source[pos]) || isPbDigit(source[pos]) || source[pos] == '.') {
+				pos++
+			}
+			toks = append(toks, pbToken{kind: pbNumber, text: source[start:pos], pos: start, end: pos, line: line})
+			continue
+		}
+		pos++
+		toks = append(toks, pbToken{kind: pbPunct, text: source[start:pos], pos: start, end: pos, line: line})
+	}
+	return toks
+}
+
+// isPbIdentStart reports whether c may start an identifier.
+func isPbIdentStart(c byte) bool {
+	return c == '_' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z'
+}
+
+// isPbDigit reports whether c is an ASCII digit.
+func isPbDigit(c byte) bool {
+	return '0' <= c && c <= '9'
+}
+
+// pbScalars are the scalar field types, which are not type references.
+var pbScalars = map[string]bool{
+	"double": true, "float": true, "int32": true, "int64": true, "uint32": true,
+	"uint64": true, "sint32": true, "sint64": true, "fixed32": true, "fixed64": true,
+	"sfixed32": true, "sfixed64": true, "bool": true, "string": true, "bytes": true,
+}
+
+// pbParser holds the state of parsing one .proto file.
+type pbParser struct {
+	source string
+	toks   []pbToken
+	result *ParseResult
+}
+
+// tok returns token i, or an empty punctuator past the end.
+func (p *pbParser) tok(i int) pbToken {
+	if i < len(p.toks) {
+		return p.toks[i]
+	}
+	return pbToken{kind: pbPunct, pos: len(p.source), end: len(p.source)}
+}
+
+// is reports whether token i is the punctuator text.
+func (p *pbParser) is(i int, text string) bool {
+	t := p.tok(i)
+	return t.kind == pbPunct && t.text == text
+}
+
+// body parses the statements of the file or of a block, starting at token
+// i, and returns the index of the closing brace (or the end). scope is the
+// full name of the enclosing message, or "" outside messages.
+func (p *pbParser) body(i int, scope string) int {
+	for i < len(p.toks) && !p.is(i, "}") {
+		t := p.tok(i)
+		if t.kind != pbIdent {
+			i = p.skip(i)
+			continue
+		}
+		switch t.text {
+		case "package":
+			if name := p.tok(i + 1); name.kind == pbIdent && scope == "" {
+				p.result.Package = name.text
+			}
+			i = p.skip(i)
+		case "import":
+			j := i + 1
+			if text := p.tok(j).text; text == "public" || text == "weak" {
+				j++
+			}
+			if path := p.tok(j); path.kind == pbString && len(path.text) >= 2 {
+				p.result.Imports = append(p.result.Imports, ImportStatement{
+					ModulePath: path.text[1 : len(path.text)-1],
+					StartLine:  t.line,
+				})
+			}
+			i = p.skip(i)
+		case "message":
+			i = p.message(i, i+1, scope)
+		case "enum":
+			i = p.enum(i, scope)
+		case "service":
+			i = p.service(i)
+		case "syntax", "edition", "option", "reserved", "extensions", "extend":
+			i = p.skip(i)
+		case "oneof":
+			// The fields of a oneof belong to the message
+			if p.is(i+2, "{") {
+				i = p.body(i+3, scope) + 1
+			} else {
+				i = p.skip(i)
+			}
+		default:
+			i = p.field(i, scope)
+		}
+	}
+	return i
+}
+
+// skip returns the index after the statement starting at token i: past its
+// semicolon, or past its block for statements with a body. It stops before
+// the closing brace of the enclosing block.
+func (p *pbParser) skip(i int) int {
+	for ; i < len(p.toks); i++ {
+		switch {
+		case p.is(i, ";"):
+			return i + 1
+		case p.is(i, "{"):
+			i = p.matching(i)
+			if p.is(i+1, ";") {
+				i++
+			}
+			return i + 1
+		case p.is(i, "}"):
+			return i
+		}
+	}
+	return i
+}
+
+// matching returns the index of the brace closing the one at token i, or
+// the last token.
+func (p *pbParser) matching(i int) int {
+	depth := 0
+	for ; i < len(p.toks); i++ {
+		switch {
+		case p.is(i, "{"):
+			depth++
+		case p.is(i, "}"):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(p.toks) - 1
+}
+
+// define appends a symbol declared from token first to token end.
+func (p *pbParser) define(sym ParsedSymbol, first, end int) {
+	sym.StartLine = p.tok(first).line
+	sym.EndLine = p.tok(end).line
+	sym.Content = p.source[p.tok(first).pos:p.tok(end).end]
+	sym.IsExported = true
+	p.result.Symbols = append(p.result.Symbols, sym)
+}
+
+// pbQualify returns the full name of a type declared in scope.
+func pbQualify(scope, name string) string {
+	if scope == "" {
+		return name
+	}
+	return scope + "." + name
+}
+
+// message parses a message, or a proto2 group, declared at token first
+// whose name is token nameIndex, and returns the index after it.
+func (p *pbParser) message(first, nameIndex int, scope string) int {
+	name := p.tok(nameIndex)
+	if name.kind != pbIdent {
+		return p.skip(first)
+	}
+	open := nameIndex + 1
+	for open < len(p.toks) && !p.is(open, "{") && !p.is(open, ";") && !p.is(open, "}") {
+		open++
+	}
+	if !p.is(open, "{") {
+		return p.skip(first)
+	}
+	full := pbQualify(scope, name.text)
+	end := p.body(open+1, full)
+	p.define(ParsedSymbol{
+		Name:      full,
+		Kind:      graph.NodeClass,
+		Signature: "message " + name.text,
+	}, first, min(end, len(p.toks)-1))
+	return end + 1
+}
+
+// enum parses an enum declared at token first and returns the index after
+// it. Its values are not symbols.
+func (p *pbParser) enum(first int, scope string) int {
+	name := p.tok(first + 1)
+	if name.kind != pbIdent || !p.is(first+2, "{") {
+		return p.skip(first)
+	}
+	end := p.matching(first + 2)
+	p.define(ParsedSymbol{
+		Name:      pbQualify(scope, name.text),
+		Kind:      graph.NodeEnum,
+		Signature: "enum " + name.text,
+	}, first, end)
+	return end + 1
+}
+
+// service parses a service declared at token first and its rpcs, and
+// returns the index after it.
+func (p *pbParser) service(first int) int {
+	name := p.tok(first + 1)
+	if name.kind != pbIdent || !p.is(first+2, "{") {
+		return p.skip(first)
+	}
+	i := first + 3
+	for i < len(p.toks) && !p.is(i, "}") {
+		if p.tok(i).text == "rpc" && p.tok(i).kind == pbIdent {
+			i = p.rpc(i, name.text)
+			continue
+		}
+		i = p.skip(i)
+	}
+	end := min(i, len(p.toks)-1)
+	p.define(ParsedSymbol{
+		Name:      name.text,
+		Kind:      graph.NodeInterface,
+		Signature: "service " + name.text,
+	}, first, end)
+	return end + 1
+}
+
+// rpc parses an rpc of service declared at token first and returns the
+// index after it.
+func (p *pbParser) rpc(first int, service string) int {
+	name := p.tok(first + 1)
+	if name.kind != pbIdent {
+		return p.skip(first)
+	}
+
+	// rpc Name ( [stream] Request ) returns ( [stream] Response )
+	i := first + 2
+	var parts []string
+	var types []pbToken
+	for _, role := range []string{"param", "return"} {
+		if role == "return" {
+			if p.tok(i).text != "returns" {
+				return p.skip(first)
+			}
+			i++
+		}
+		if !p.is(i, "(") {
+			return p.skip(first)
+		}
+		i++
+		part := ""
+		if t := p.tok(i); t.kind == pbIdent && t.text == "stream" && p.tok(i+1).kind == pbIdent {
+			part = "stream "
+			i++
+		}
+		typ := p.tok(i)
+		if typ.kind != pbIdent || !p.is(i+1, ")") {
+			return p.skip(first)
+		}
+		types = append(types, typ)
+		parts = append(parts, "("+part+typ.text+")")
+		i += 2
+	}
+
+	p.typeRef(types[0], "param", name.text, service)
+	p.typeRef(types[1], "return", name.text, service)
+
+	end := i - 1
+	if p.is(i, "{") {
+		end = p.matching(i)
+		i = end + 1
+	}
+	if p.is(i, ";") {
+		end = i
+		i++
+	}
+	p.define(ParsedSymbol{
+		Name:      name.text,
+		Kind:      graph.NodeMethod,
+		ClassName: service,
+		Signature: "rpc " + name.text + parts[0] + " returns " + parts[1],
+	}, first, end)
+	return i
+}
+
+// field parses a field of the message scope starting at token i (or any
+// other statement) and returns the index after it.
+func (p *pbParser) field(i int, scope string) int {
+	first := i
+	if text := p.tok(i).text; text == "optional" || text == "required" || text == "repeated" {
+		i++
+	}
+	typ := p.tok(i)
+	switch {
+	case typ.kind == pbIdent && typ.text == "group":
+		return p.message(first, i+1, scope)
+	case typ.kind == pbIdent && typ.text == "map" && p.is(i+1, "<"):
+		// map<Key, Value>: keys are scalars
+		if value := p.tok(i + 4); p.is(i+3, ",") && value.kind == pbIdent {
+			p.typeRef(value, "field", scope, "")
+		}
+	case typ.kind == pbIdent && scope != "" && p.tok(i+1).kind == pbIdent:
+		p.typeRef(typ, "field", scope, "")
+	}
+	return p.skip(first)
+}
+
+// typeRef records a reference to a message or enum type.
+func (p *pbParser) typeRef(t pbToken, role, enclosing, enclosingClass string) {
+	if pbScalars[t.text] {
+		return
+	}
+	p.result.TypeRefs = append(p.result.TypeRefs, TypeAnnotation{
+		Name:           t.text,
+		Role:           role,
+		StartLine:      t.line,
+		Enclosing:      enclosing,
+		EnclosingClass: enclosingClass,
+	})
+}
+
+// finish resolves the type references to the names of their symbols.
+// Types are looked up from the innermost enclosing message outwards, as
+// protoc does; package qualifiers, which are lower case by convention, are
+// dropped.
+func (p *pbParser) finish() {
+	declared := make(map[string]bool)
+	for _, sym := range p.result.Symbols {
+		if sym.Kind != graph.NodeMethod {
+			declared[sym.Name] = true
+		}
+	}
+	for i := range p.result.TypeRefs {
+		ref := &p.result.TypeRefs[i]
+		name := strings.TrimPrefix(ref.Name, ".")
+		qualified := name != ref.Name
+		if pkg := p.result.Package + "."; strings.HasPrefix(name, pkg) {
+			name, qualified = strings.TrimPrefix(name, pkg), true
+		}
+		parts := strings.Split(name, ".")
+		for len(parts) > 1 && parts[0] != "" && 'a' <= parts[0][0] && parts[0][0] <= 'z' {
+			parts, qualified = parts[1:], true
+		}
+		ref.Name = strings.Join(parts, ".")
+		if qualified {
+			continue
+		}
+
+		// Relative names are resolved in the enclosing messages first
+		scope := ref.Enclosing
+		if ref.EnclosingClass != "" {
+			scope = ""
+		}
+		for scope != "" && !declared[scope+"."+ref.Name] {
+			dot := strings.LastIndex(scope, ".")
+			if dot < 0 {
+				dot = 0
+			}
+			scope = scope[:dot]
+		}
+		if scope != "" {
+			ref.Name = scope + "." + ref.Name
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestProtoParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ParseService", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`syntax = "proto3";
+
+package acme.users.v1;
+
+import "google/protobuf/timestamp.proto";
+import public "acme/common/v1/page.proto";
+
+option go_package = "github.com/acme/api/users/v1;usersv1";
+
+// UserService manages users.
+service UserService {
+  rpc GetUser(GetUserRequest) returns (User);
+  rpc WatchUsers(stream WatchRequest) returns (stream .acme.users.v1.User) {
+    option (google.api.http) = { get: "/v1/users:watch" };
+  }
+  option deprecated = false;
+}
+
+message User {
+  string id = 1;
+  repeated Address addresses = 2;
+  google.protobuf.Timestamp created_at = 3;
+  map<string, Role> roles = 4;
+
+  message Address {
+    string city = 1;
+    Kind kind = 2;
+    enum Kind {
+      KIND_UNSPECIFIED = 0;
+      HOME = 1;
+    }
+  }
+
+  oneof contact {
+    string email = 5;
+    Phone phone = 6;
+  }
+  reserved 7, 8;
+}
+
+enum Role {
+  ROLE_UNSPECIFIED = 0;
+  ADMIN = 1 [(acme.label) = "admin"];
+}
+
+message GetUserRequest { string id = 1; }
+`)
+		result, err := NewProtoParser().Parse("proto/acme/users/v1/users.proto", content)
+		require.NoError(t, err)
+		assert.Equal(t, "acme.users.v1", result.Package)
+
+		symbols := symbolsByName(result)
+		service := symbols["UserService"]
+		assert.Equal(t, graph.NodeInterface, service.Kind)
+		assert.Equal(t, "service UserService", service.Signature)
+		assert.Equal(t, 11, service.StartLine)
+		assert.Equal(t, 17, service.EndLine)
+		assert.True(t, service.IsExported)
+
+		getUser := symbols["UserService.GetUser"]
+		assert.Equal(t, graph.NodeMethod, getUser.Kind)
+		assert.Equal(t, "rpc GetUser(GetUserRequest) returns (User)", getUser.Signature)
+		assert.Equal(t, 12, getUser.StartLine)
+		assert.Equal(t, 12, getUser.EndLine)
+
+		watch := symbols["UserService.WatchUsers"]
+		assert.Equal(t, "rpc WatchUsers(stream WatchRequest) returns (stream .acme.users.v1.User)", watch.Signature)
+		assert.Equal(t, 15, watch.EndLine)
+
+		user := symbols["User"]
+		assert.Equal(t, graph.NodeClass, user.Kind)
+		assert.Equal(t, "message User", user.Signature)
+		assert.Equal(t, 19, user.StartLine)
+		assert.Equal(t, 39, user.EndLine)
+
+		assert.Equal(t, graph.NodeClass, symbols["User.Address"].Kind, "nested messages are named by their path")
+		assert.Equal(t, graph.NodeEnum, symbols["User.Address.Kind"].Kind)
+		role := symbols["Role"]
+		assert.Equal(t, graph.NodeEnum, role.Kind)
+		assert.Equal(t, 44, role.EndLine)
+		assert.Contains(t, symbols, "GetUserRequest")
+		assert.Len(t, result.Symbols, 8)
+
+		require.Len(t, result.Imports, 2)
+		assert.Equal(t, ImportStatement{ModulePath: "google/protobuf/timestamp.proto", StartLine: 5}, result.Imports[0])
+		assert.Equal(t, ImportStatement{ModulePath: "acme/common/v1/page.proto", StartLine: 6}, result.Imports[1])
+
+		refs := make(map[string]TypeAnnotation)
+		for _, ref := range result.TypeRefs {
+			refs[ref.Enclosing+":"+ref.Name] = ref
+		}
+		assert.Equal(t, TypeAnnotation{Name: "GetUserRequest", Role: "param", StartLine: 12, Enclosing: "GetUser", EnclosingClass: "UserService"},
+			refs["GetUser:GetUserRequest"])
+		assert.Equal(t, "return", refs["GetUser:User"].Role)
+		assert.Contains(t, refs, "WatchUsers:User", "package qualifiers are dropped")
+		assert.Contains(t, refs, "User:User.Address", "nested types resolve in the enclosing message")
+		assert.Contains(t, refs, "User:Timestamp")
+		assert.Contains(t, refs, "User:Role", "map values")
+		assert.Contains(t, refs, "User:Phone", "oneof fields")
+		assert.Contains(t, refs, "User.Address:User.Address.Kind")
+		assert.Len(t, result.TypeRefs, 9, "scalars are not type references")
+	})
+
+	t.Run("ParseProto2", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`syntax = "proto2";
+package legacy;
+
+message Search {
+  optional group Result = 1 {
+    required string url = 2;
+    optional Page page = 3;
+  }
+  extensions 100 to 199;
+}
+
+extend Search {
+  optional int32 rank = 100;
+}
+
+/* message Hidden {} */
+message Page {}
+`)
+		result, err := NewProtoParser().Parse("legacy.proto", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Equal(t, graph.NodeClass, symbols["Search.Result"].Kind, "groups are messages")
+		assert.Equal(t, 8, symbols["Search.Result"].EndLine)
+		assert.Equal(t, 10, symbols["Search"].EndLine)
+		assert.Contains(t, symbols, "Page")
+		assert.NotContains(t, symbols, "Hidden", "comments are skipped")
+
+		require.Len(t, result.TypeRefs, 1)
+		assert.Equal(t, "Page", result.TypeRefs[0].Name)
+		assert.Equal(t, "Search.Result", result.TypeRefs[0].Enclosing)
+	})
+
+	t.Run("ParseEmptyFile", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := NewProtoParser().Parse("empty.proto", []byte(`syntax = "proto3";`))
+		require.NoError(t, err)
+		assert.Empty(t, result.Symbols)
+		assert.Empty(t, result.Imports)
+	})
+}
+
+func TestProtoParser_Language(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "proto", NewProtoParser().Language())
+}
+
+func TestProtoParser_FileDetection(t *testing.T) {
+	t.Parallel()
+
+	parser := NewProtoParser()
+	assert.True(t, parser.SupportsFile("api/users/v1/users.proto"))
+	assert.False(t, parser.SupportsFile("api/users/v1/users.pb.go"))
+}
@@ -1,31 +1,26 @@
 package parsers
 
 import (
-	"bufio"
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/Benny93/axon-go/internal/graph"
 )
 
-// PythonParser parses Python source code using regex-based approach.
-// Note: This is a simplified implementation. For production, use tree-sitter.
-type PythonParser struct {
-	functionRegex *regexp.Regexp
-	classRegex    *regexp.Regexp
-	importRegex   *regexp.Regexp
-	callRegex     *regexp.Regexp
-}
+// PythonParser parses Python source code.
+//
+// The source is tokenized following Python's lexical rules and its
+// statements are parsed into nested blocks, so symbol ranges follow the
+// indentation structure even across multi-line signatures, decorators and
+// strings. Module-level functions and classes and the methods of those
+// classes are recorded as symbols; functions and classes nested in
+// function bodies belong to their enclosing symbol. When a module defines
+// __all__, it decides which top-level symbols are exported.
+type PythonParser struct{}
 
 // NewPythonParser creates a new Python parser.
 func NewPythonParser() *PythonParser {
-	return &PythonParser{
-		functionRegex: regexp.MustCompile(`^(?P<decorator>@\w+(?:\.\w+)?(?:\(.*\))?)?\s*(?:async\s+)?def\s+(?P<name>\w+)\s*\((?P<params>[^)]*)\)\s*(?:->\s*(?P<return>\S+))?`),
-		classRegex:    regexp.MustCompile(`^class\s+(\w+)(?:\(([^)]+)\))?`),
-		importRegex:   regexp.MustCompile(`^(?:from\s+([\w.]+)\s+)?import\s+(.+)`),
-		callRegex:     regexp.MustCompile(`(\w+)\s*\(([^)]*)\)`),
-	}
+	return &PythonParser{}
 }
 
 // Language returns the language this parser handles.
@@ -35,255 +30,952 @@ func (p *PythonParser) Language() string {
 
 // Parse parses Python source code and extracts symbols, imports, calls, etc.
 func (p *PythonParser) Parse(filePath string, content []byte) (*ParseResult, error) {
-	result := &ParseResult{
-		Symbols:  []ParsedSymbol{},
-		Imports:  []ImportStatement{},
-		Calls:    []CallSite{},
-		TypeRefs: []TypeAnnotation{},
-		Heritage: []ClassHeritage{},
+	source := string(content)
+	py := &pyParser{
+		source: source,
+		toks:   lexPython(source),
+		result: &ParseResult{
+			Symbols:  []ParsedSymbol{},
+			Imports:  []ImportStatement{},
+			Calls:    []CallSite{},
+			TypeRefs: []TypeAnnotation{},
+			Heritage: []ClassHeritage{},
+		},
+		prefixes: make(map[string]string),
 	}
+	py.block(newPyScope(nil, -1, ""), false)
+	py.finish()
+	return py.result, nil
+}
 
-	lines := strings.Split(string(content), "\n")
-	var currentClass string
-	var decorators []string
+// pyScope is a module, class or function body.
+type pyScope struct {
+	parent *pyScope
 
-	for lineNum, line := range lines {
-		trimmed := strings.TrimSpace(line)
+	// symbol is the index of the symbol the scope is the body of, or -1
+	// for the module and for unrecorded nested definitions
+	symbol int
 
-		// Collect decorators
-		if strings.HasPrefix(trimmed, "@") {
-			dec := strings.TrimPrefix(trimmed, "@")
-			if idx := strings.Index(dec, "("); idx > 0 {
-				dec = dec[:idx]
-			}
-			decorators = append(decorators, dec)
-			continue
+	// class is the name of the class for class bodies, and for the bodies
+	// of its methods
+	class string
+	kind  graph.NodeLabel
+
+	// vars maps variable names to their type, where an annotation or a
+	// constructor call shows it
+	vars map[string]string
+
+	// attrs maps the attributes assigned through self in the methods of a
+	// class to their type; it is shared by the class and its methods
+	attrs map[string]string
+
+	// fields holds the annotated fields of a class body, as they appear in
+	// the generated __init__ of a dataclass
+	fields []string
+}
+
+func newPyScope(parent *pyScope, symbol int, class string) *pyScope {
+	return &pyScope{parent: parent, symbol: symbol, class: class, vars: make(map[string]string)}
+}
+
+// enclosing returns the nearest scope that is the body of a symbol.
+func (s *pyScope) enclosing() *pyScope {
+	for ; s != nil; s = s.parent {
+		if s.symbol >= 0 {
+			return s
 		}
+	}
+	return nil
+}
 
-		// Parse functions
-		if matches := p.functionRegex.FindStringSubmatch(trimmed); matches != nil {
-			nameIdx := p.functionRegex.SubexpIndex("name")
-			paramsIdx := p.functionRegex.SubexpIndex("params")
-			returnIdx := p.functionRegex.SubexpIndex("return")
+// varType returns the type of the variable name visible in the scope.
+func (s *pyScope) varType(name string) string {
+	for ; s != nil; s = s.parent {
+		if t, ok := s.vars[name]; ok {
+			return t
+		}
+	}
+	return ""
+}
 
-			kind := graph.NodeFunction
-			className := ""
-			if currentClass != "" {
-				kind = graph.NodeMethod
-				className = currentClass
-			}
+// pyParser parses the statements of a token stream.
+type pyParser struct {
+	source string
+	toks   []pyToken
+	i      int
+	result *ParseResult
 
-			sym := ParsedSymbol{
-				Name:       matches[nameIdx],
-				Kind:       kind,
-				ClassName:  className,
-				StartLine:  lineNum + 1,
-				EndLine:    lineNum + 1,
-				Signature:  trimmed,
-				Content:    trimmed,
-				IsExported: !strings.HasPrefix(matches[nameIdx], "_"),
-				Decorators: decorators,
-			}
+	// all holds the names listed in __all__, which is nil when the module
+	// does not define it
+	all map[string]bool
 
-			if returnIdx >= 0 && matches[returnIdx] != "" {
-				sym.Signature = fmt.Sprintf("%s(%s) -> %s", matches[nameIdx], matches[paramsIdx], matches[returnIdx])
-			}
+	// prefixes maps the variables holding a Flask Blueprint or FastAPI
+	// APIRouter to their path prefix
+	prefixes map[string]string
+}
+
+// pyNotCalled are keywords that may be followed by '('.
+var pyNotCalled = map[string]bool{
+	"if": true, "elif": true, "while": true, "for": true, "in": true, "not": true,
+	"and": true, "or": true, "is": true, "return": true, "yield": true,
+	"assert": true, "del": true, "lambda": true, "await": true, "with": true,
+	"except": true, "import": true, "from": true, "raise": true, "else": true,
+	"def": true, "class": true, "as": true, "case": true,
+}
+
+// pyTypeWrappers are generic types whose arguments name the type of a
+// variable.
+var pyTypeWrappers = map[string]bool{
+	"Optional": true, "Annotated": true, "Final": true, "ClassVar": true,
+	"Union": true, "Type": true, "type": true,
+}
+
+// pyDataclassDecorators are the decorators that generate an __init__ from
+// the annotated fields of a class.
+var pyDataclassDecorators = map[string]bool{
+	"dataclass": true, "dataclasses.dataclass": true, "attr.s": true,
+	"attrs.define": true, "attr.define": true, "define": true,
+}
+
+// pyRouteDecorators are the Flask and FastAPI decorators that register a
+// route, by the HTTP method they register it for ("" when the methods are
+// an argument).
+var pyRouteDecorators = map[string]string{
+	"route": "", "api_route": "", "get": "GET", "post": "POST", "put": "PUT",
+	"patch": "PATCH", "delete": "DELETE", "head": "HEAD", "options": "OPTIONS",
+}
+
+// tok returns the token at index i, or an EOF token out of range.
+func (p *pyParser) tok(i int) pyToken {
+	if i < 0 || i >= len(p.toks) {
+		return pyToken{kind: pyEOF, pos: len(p.source), end: len(p.source)}
+	}
+	return p.toks[i]
+}
 
-			result.Symbols = append(result.Symbols, sym)
-			decorators = nil // Reset decorators
+// is reports whether the token at index i is the name or operator text.
+func (p *pyParser) is(i int, text string) bool {
+	t := p.tok(i)
+	return (t.kind == pyName || t.kind == pyOp) && t.text == text
+}
+
+// name returns the name at index i, or "".
+func (p *pyParser) name(i int) string {
+	if t := p.tok(i); t.kind == pyName {
+		return t.text
+	}
+	return ""
+}
+
+// matching returns the index of the bracket closing the one at index i.
+func (p *pyParser) matching(i int) int {
+	depth := 0
+	for j := i; j < len(p.toks); j++ {
+		t := p.toks[j]
+		if t.kind != pyOp {
 			continue
 		}
-
-		// Parse classes
-		if matches := p.classRegex.FindStringSubmatch(trimmed); matches != nil {
-			className := matches[1]
-			heritage := ""
-			if len(matches) > 2 {
-				heritage = matches[2]
+		switch t.text {
+		case "(", "[", "{":
+			depth++
+		case ")", "]", "}":
+			depth--
+			if depth == 0 {
+				return j
 			}
+		}
+	}
+	return len(p.toks) - 1
+}
 
-			sym := ParsedSymbol{
-				Name:       className,
-				Kind:       graph.NodeClass,
-				StartLine:  lineNum + 1,
-				EndLine:    lineNum + 1,
-				Signature:  trimmed,
-				Content:    trimmed,
-				IsExported: !strings.HasPrefix(className, "_"),
-				Decorators: decorators,
+// matchingOpen returns the index of the bracket opening the one closed at
+// index i.
+func (p *pyParser) matchingOpen(i int) int {
+	depth := 0
+	for j := i; j >= 0; j-- {
+		t := p.toks[j]
+		if t.kind != pyOp {
+			continue
+		}
+		switch t.text {
+		case ")", "]", "}":
+			depth++
+		case "(", "[", "{":
+			depth--
+			if depth == 0 {
+				return j
 			}
-			result.Symbols = append(result.Symbols, sym)
+		}
+	}
+	return 0
+}
 
-			// Parse heritage
-			if heritage != "" {
-				h := ClassHeritage{
-					ClassName: className,
-				}
-				bases := strings.Split(heritage, ",")
-				for _, base := range bases {
-					base = strings.TrimSpace(base)
-					if strings.HasSuffix(base, "Mixin") || strings.HasSuffix(base, "Protocol") {
-						h.Implements = append(h.Implements, base)
-					} else {
-						h.Extends = append(h.Extends, base)
-					}
-				}
-				result.Heritage = append(result.Heritage, h)
-			}
+// text returns the source between the tokens at indices i and j, exclusive,
+// with whitespace collapsed.
+func (p *pyParser) text(i, j int) string {
+	if j <= i+1 {
+		return ""
+	}
+	return strings.Join(strings.Fields(p.source[p.tok(i).end:p.tok(j).pos]), " ")
+}
 
-			currentClass = className
-			decorators = nil
-			continue
+// lineEnd returns the index of the NEWLINE token ending the logical line
+// that contains index i.
+func (p *pyParser) lineEnd(i int) int {
+	for ; i < len(p.toks); i++ {
+		if k := p.toks[i].kind; k == pyNewline || k == pyEOF {
+			return i
 		}
+	}
+	return len(p.toks) - 1
+}
 
-		// Reset class context on dedent (simplified)
-		if trimmed != "" && !strings.HasPrefix(trimmed, " ") && !strings.HasPrefix(trimmed, "\t") {
-			if !strings.HasPrefix(trimmed, "def ") && !strings.HasPrefix(trimmed, "class ") {
-				currentClass = ""
+// split returns the index ranges of the comma-separated items between the
+// tokens at indices from and to, exclusive, outside nested brackets.
+func (p *pyParser) split(from, to int) [][2]int {
+	var items [][2]int
+	start := from + 1
+	for j := from + 1; j < to; j++ {
+		switch {
+		case p.is(j, "(") || p.is(j, "[") || p.is(j, "{"):
+			j = p.matching(j)
+		case p.is(j, ","):
+			items = append(items, [2]int{start, j})
+			start = j + 1
+		}
+	}
+	if start < to {
+		items = append(items, [2]int{start, to})
+	}
+	return items
+}
+
+// find returns the index of the first operator text between the tokens at
+// indices from and to, exclusive, outside nested brackets, or -1.
+func (p *pyParser) find(from, to int, text string) int {
+	for j := from + 1; j < to; j++ {
+		switch {
+		case p.is(j, text):
+			return j
+		case p.is(j, "(") || p.is(j, "[") || p.is(j, "{"):
+			j = p.matching(j)
+		}
+	}
+	return -1
+}
+
+// block parses statements until the end of the file or, when indented, the
+// DEDENT token that ends the block.
+func (p *pyParser) block(scope *pyScope, indented bool) {
+	for p.i < len(p.toks) {
+		switch p.tok(p.i).kind {
+		case pyEOF:
+			return
+		case pyDedent:
+			p.i++
+			if indented {
+				return
 			}
+		case pyNewline:
+			p.i++
+		case pyIndent:
+			p.i++
+			p.block(scope, true)
+		default:
+			p.statement(scope)
 		}
+	}
+}
+
+// body parses the body of a def, class or compound statement whose header
+// ends with the colon at index colon.
+func (p *pyParser) body(colon int, scope *pyScope) {
+	p.i = colon + 1
+	if p.tok(p.i).kind == pyNewline && p.tok(p.i+1).kind == pyIndent {
+		p.i += 2
+		p.block(scope, true)
+		return
+	}
+	// A body on the same line as the header
+	end := p.lineEnd(p.i)
+	p.simple(p.i, end, scope)
+	p.i = end + 1
+}
+
+// lastToken returns the index of the last token before p.i that is part of
+// a statement.
+func (p *pyParser) lastToken() int {
+	j := p.i - 1
+	for j > 0 {
+		if k := p.tok(j).kind; k != pyNewline && k != pyIndent && k != pyDedent && k != pyEOF {
+			break
+		}
+		j--
+	}
+	return j
+}
 
-		// Parse imports
-		if matches := p.importRegex.FindStringSubmatch(trimmed); matches != nil {
-			imp := ImportStatement{
-				StartLine: lineNum + 1,
+// statement parses the statement at p.i.
+func (p *pyParser) statement(scope *pyScope) {
+	var decorators []string
+	var routes []Route
+	for p.is(p.i, "@") {
+		end := p.lineEnd(p.i)
+		j := p.i + 1
+		name := p.name(j)
+		for p.is(j+1, ".") && p.name(j+2) != "" {
+			j += 2
+			name += "." + p.name(j)
+		}
+		if name != "" {
+			decorators = append(decorators, name)
+		}
+		routes = append(routes, p.routes(p.i, end, scope)...)
+		p.calls(p.i, end, scope)
+		p.i = end + 1
+	}
+
+	switch {
+	case p.is(p.i, "def"), p.is(p.i, "async") && p.is(p.i+1, "def"):
+		def := p.i
+		if p.is(def, "async") {
+			def++
+		}
+		for _, route := range routes {
+			route.Handler = p.name(def + 1)
+			if scope.kind == graph.NodeClass {
+				route.Receiver = scope.class
 			}
+			p.result.Routes = append(p.result.Routes, route)
+		}
+		p.function(scope, decorators)
+		return
+	case p.is(p.i, "class"):
+		p.class(scope, decorators)
+		return
+	}
 
-			if matches[1] != "" {
-				// from X import Y
-				imp.ModulePath = matches[1]
-				imp.IsRelative = strings.HasPrefix(matches[1], ".")
-				symbols := strings.Split(matches[2], ",")
-				for _, s := range symbols {
-					s = strings.TrimSpace(s)
-					// Handle "X as Y" aliases
-					if parts := strings.Split(s, " as "); len(parts) > 1 {
-						s = strings.TrimSpace(parts[0])
-					}
-					if s != "" {
-						imp.Symbols = append(imp.Symbols, s)
-					}
-				}
-			} else {
-				// import X
-				parts := strings.Split(matches[2], ",")
-				for _, part := range parts {
-					part = strings.TrimSpace(part)
-					if idx := strings.Index(part, " as "); idx > 0 {
-						part = part[:idx]
-					}
-					imp.ModulePath = strings.TrimSpace(part)
+	end := p.lineEnd(p.i)
+	p.simple(p.i, end, scope)
+	p.i = end + 1
+
+	// The header of a compound statement: if, for, with, try, ...
+	if p.is(end-1, ":") && p.tok(end+1).kind == pyIndent {
+		p.i = end + 2
+		p.block(scope, true)
+	}
+}
+
+// routes returns the routes registered by the decorator between the tokens
+// at indices at and end: @app.get("/users"), @bp.route("/users",
+// methods=["GET", "POST"]). The path includes the prefix of a Blueprint or
+// APIRouter defined in the module.
+func (p *pyParser) routes(at, end int, scope *pyScope) []Route {
+	j := at + 1
+	var chain []string
+	for p.name(j) != "" {
+		chain = append(chain, p.name(j))
+		if !p.is(j+1, ".") {
+			break
+		}
+		j += 2
+	}
+	if len(chain) < 2 || !p.is(j+1, "(") {
+		return nil
+	}
+	decorator := chain[len(chain)-1]
+	method, ok := pyRouteDecorators[decorator]
+	if !ok {
+		return nil
+	}
+	router := strings.Join(chain[:len(chain)-1], ".")
+
+	open := j + 1
+	path, found := "", false
+	var methods []string
+	for _, arg := range p.split(open, min(p.matching(open), end)) {
+		switch {
+		case arg[1] == arg[0]+1 && p.tok(arg[0]).kind == pyString && !found:
+			path, found = p.tok(arg[0]).text, true
+		case p.is(arg[0], "path") && p.is(arg[0]+1, "=") && p.tok(arg[0]+2).kind == pyString:
+			path, found = p.tok(arg[0]+2).text, true
+		case p.is(arg[0], "methods") && p.is(arg[0]+1, "="):
+			for k := arg[0] + 2; k < arg[1]; k++ {
+				if t := p.tok(k); t.kind == pyString {
+					methods = append(methods, strings.ToUpper(t.text))
 				}
 			}
+		}
+	}
+	if !found {
+		return nil
+	}
+	if method != "" {
+		methods = []string{method}
+	} else if len(methods) == 0 {
+		methods = []string{"GET"}
+	}
+
+	framework := ""
+	switch scope.varType(router) {
+	case "Flask", "Blueprint":
+		framework = "flask"
+	case "FastAPI", "APIRouter":
+		framework = "fastapi"
+	}
+	for _, imp := range p.result.Imports {
+		if framework != "" {
+			break
+		}
+		switch root, _, _ := strings.Cut(imp.ModulePath, "."); root {
+		case "flask", "fastapi":
+			framework = root
+		}
+	}
+	if framework == "" {
+		framework = "fastapi"
+		if decorator == "route" {
+			framework = "flask"
+		}
+	}
+
+	enclosing, enclosingClass := p.enclosing(scope)
+	routes := make([]Route, 0, len(methods))
+	for _, method := range methods {
+		routes = append(routes, Route{
+			Method:         method,
+			Path:           p.prefixes[router] + path,
+			Framework:      framework,
+			StartLine:      p.tok(at).line,
+			Enclosing:      enclosing,
+			EnclosingClass: enclosingClass,
+		})
+	}
+	return routes
+}
+
+// function parses the def statement at p.i.
+func (p *pyParser) function(scope *pyScope, decorators []string) {
+	start := p.i
+	def := p.i
+	if p.is(def, "async") {
+		def++
+	}
+	name := p.name(def + 1)
+	open := def + 2
+	if name == "" || !p.is(open, "(") {
+		p.skipStatement(scope)
+		return
+	}
+	closeParen := p.matching(open)
+	end := p.lineEnd(closeParen)
+	colon := p.find(closeParen, end+1, ":")
+	if colon < 0 {
+		colon = end - 1
+	}
+
+	// Methods are recorded for the classes that are recorded themselves
+	index := -1
+	className := ""
+	switch {
+	case scope.parent == nil:
+		index = p.define(ParsedSymbol{Name: name, Kind: graph.NodeFunction}, start)
+	case scope.kind == graph.NodeClass && scope.symbol >= 0:
+		className = scope.class
+		index = p.define(ParsedSymbol{Name: name, Kind: graph.NodeMethod, ClassName: className}, start)
+	case scope.kind == graph.NodeFunction:
+		className = scope.class
+	}
+	fn := newPyScope(scope, index, className)
+	fn.kind = graph.NodeFunction
+	fn.attrs = scope.attrs
+
+	p.parameters(open, closeParen, fn, scope)
+	returns := ""
+	if p.is(closeParen+1, "->") {
+		returns = p.text(closeParen+1, colon)
+		p.annotation(closeParen+1, colon, "return", fn)
+	}
+
+	if index >= 0 {
+		sym := &p.result.Symbols[index]
+		sym.Decorators = decorators
+		sym.Signature = fmt.Sprintf("def %s(%s)", name, p.text(open, closeParen))
+		if def != start {
+			sym.Signature = "async " + sym.Signature
+		}
+		if returns != "" {
+			sym.Signature += " -> " + returns
+		}
+		sym.Doc = p.docstring(colon)
+	}
 
-			if imp.ModulePath != "" {
-				result.Imports = append(result.Imports, imp)
+	p.body(colon, fn)
+	p.end(index, start)
+}
+
+// parameters records the annotations of the parameters between the
+// parentheses at indices open and closeParen in the function scope fn.
+// Default values are evaluated in the outer scope.
+func (p *pyParser) parameters(open, closeParen int, fn, outer *pyScope) {
+	for _, item := range p.split(open, closeParen) {
+		j := item[0]
+		for p.is(j, "*") || p.is(j, "**") {
+			j++
+		}
+		name := p.name(j)
+		eq := p.find(j-1, item[1], "=")
+		if eq < 0 {
+			eq = item[1]
+		} else {
+			p.calls(eq, item[1], outer)
+		}
+		if name != "" && p.is(j+1, ":") {
+			if t := p.annotation(j+1, eq, "param", fn); t != "" {
+				fn.vars[name] = t
 			}
 		}
+	}
+}
 
-		// Parse function calls (simplified)
-		if strings.Contains(trimmed, "(") && !strings.HasPrefix(trimmed, "def ") && !strings.HasPrefix(trimmed, "class ") && !strings.HasPrefix(trimmed, "import ") && !strings.HasPrefix(trimmed, "from ") {
-			calls := p.extractCalls(trimmed, lineNum+1)
-			result.Calls = append(result.Calls, calls...)
+// annotation records the types named by the annotation between the tokens
+// at indices from and to, exclusive, and returns the type a variable with
+// the annotation has.
+func (p *pyParser) annotation(from, to int, role string, scope *pyScope) string {
+	main := ""
+	for j := from + 1; j < to; j++ {
+		t := p.tok(j)
+		var names []string
+		switch {
+		case t.kind == pyName && !p.is(j-1, "."):
+			// The last part of a dotted name: models.User
+			name := t.text
+			for p.is(j+1, ".") && p.name(j+2) != "" {
+				j += 2
+				name = p.tok(j).text
+			}
+			names = append(names, name)
+		case t.kind == pyString:
+			// Forward references: "User" or "Optional[User]"
+			for _, field := range strings.FieldsFunc(t.text, func(r rune) bool {
+				return !(r == '_' || r == '.' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+			}) {
+				names = append(names, field[strings.LastIndexByte(field, '.')+1:])
+			}
 		}
+		for _, name := range names {
+			if name == "None" || name == "" {
+				continue
+			}
+			p.result.TypeRefs = append(p.result.TypeRefs, p.typeRef(name, role, t.line, scope))
+			if main == "" && !pyTypeWrappers[name] {
+				main = name
+			}
+		}
+	}
+	return main
+}
+
+func (p *pyParser) typeRef(name, role string, line int, scope *pyScope) TypeAnnotation {
+	ref := TypeAnnotation{Name: name, Role: role, StartLine: line}
+	ref.Enclosing, ref.EnclosingClass = p.enclosing(scope)
+	return ref
+}
 
-		// Parse type annotations (simplified)
-		if strings.Contains(trimmed, ":") && (strings.HasPrefix(trimmed, "def ") || strings.Contains(trimmed, "->")) {
-			typeRefs := p.extractTypeAnnotations(trimmed, lineNum+1)
-			result.TypeRefs = append(result.TypeRefs, typeRefs...)
+// enclosing returns the name and class of the symbol scope belongs to.
+func (p *pyParser) enclosing(scope *pyScope) (string, string) {
+	if s := scope.enclosing(); s != nil {
+		sym := p.result.Symbols[s.symbol]
+		return sym.Name, sym.ClassName
+	}
+	return "", ""
+}
+
+// class parses the class statement at p.i.
+func (p *pyParser) class(scope *pyScope, decorators []string) {
+	start := p.i
+	name := p.name(start + 1)
+	if name == "" {
+		p.skipStatement(scope)
+		return
+	}
+	end := p.lineEnd(start)
+	colon := p.find(start+1, end, ":")
+	if colon < 0 {
+		colon = end - 1
+	}
+
+	index := -1
+	if scope.parent == nil {
+		index = p.define(ParsedSymbol{Name: name, Kind: graph.NodeClass, Decorators: decorators, Doc: p.docstring(colon)}, start)
+	}
+	cls := newPyScope(scope, index, name)
+	cls.kind = graph.NodeClass
+	cls.attrs = make(map[string]string)
+
+	signature := "class " + name
+	if p.is(start+2, "(") {
+		closeParen := p.matching(start + 2)
+		signature += "(" + p.text(start+2, closeParen) + ")"
+		p.calls(start+2, closeParen, scope)
+		if index >= 0 {
+			p.bases(name, start+2, closeParen)
 		}
 	}
 
-	return result, nil
+	p.body(colon, cls)
+	p.end(index, start)
+	if index < 0 {
+		return
+	}
+
+	sym := &p.result.Symbols[index]
+	sym.Signature = signature
+	for _, dec := range decorators {
+		if pyDataclassDecorators[dec] {
+			sym.Signature = fmt.Sprintf("@%s class %s(%s)", dec, name, strings.Join(cls.fields, ", "))
+			break
+		}
+	}
 }
 
-func (p *PythonParser) extractCalls(line string, lineNum int) []CallSite {
-	var calls []CallSite
+// docstring returns the docstring of the def or class whose header ends
+// with the colon at index colon: a string that is the first statement of
+// its body.
+func (p *pyParser) docstring(colon int) string {
+	i := colon + 1
+	if p.tok(i).kind == pyNewline && p.tok(i+1).kind == pyIndent {
+		i += 2
+	}
+	if t := p.tok(i); t.kind != pyString || p.tok(i+1).kind != pyNewline && p.tok(i+1).kind != pyEOF {
+		return ""
+	}
+	return cleanDoc(p.tok(i).text)
+}
 
-	matches := p.callRegex.FindAllStringSubmatch(line, -1)
-	for _, match := range matches {
-		funcName := match[1]
-		// Skip keywords
-		if funcName == "def" || funcName == "class" || funcName == "if" || funcName == "for" || funcName == "while" || funcName == "with" || funcName == "except" {
+// bases records the base classes listed between the parentheses at indices
+// open and closeParen. Keyword arguments such as metaclass= are skipped.
+func (p *pyParser) bases(className string, open, closeParen int) {
+	h := ClassHeritage{ClassName: className}
+	for _, item := range p.split(open, closeParen) {
+		if p.is(item[0]+1, "=") || p.is(item[0], "*") || p.is(item[0], "**") {
 			continue
 		}
-
-		call := CallSite{
-			Name:      funcName,
-			StartLine: lineNum,
-			EndLine:   lineNum,
-		}
-
-		// Check for method call (obj.method())
-		nameIdx := strings.Index(line, funcName)
-		if nameIdx > 0 {
-			if idx := strings.LastIndex(line[:nameIdx], "."); idx >= 0 {
-				before := line[:idx]
-				parts := strings.Fields(before)
-				if len(parts) > 0 {
-					call.Receiver = strings.TrimSpace(parts[len(parts)-1])
-				}
+		// The last part of a dotted name, without type arguments:
+		// models.Model, Generic[T]
+		base := ""
+		for j := item[0]; j < item[1]; j++ {
+			if p.is(j, "[") || p.is(j, "(") {
+				break
+			}
+			if name := p.name(j); name != "" {
+				base = name
 			}
 		}
+		if base == "" {
+			continue
+		}
+		if strings.HasSuffix(base, "Mixin") || strings.HasSuffix(base, "Protocol") {
+			h.Implements = append(h.Implements, base)
+		} else {
+			h.Extends = append(h.Extends, base)
+		}
+	}
+	if len(h.Extends) > 0 || len(h.Implements) > 0 {
+		p.result.Heritage = append(p.result.Heritage, h)
+	}
+}
+
+// skipStatement parses a malformed statement at p.i as a simple statement.
+func (p *pyParser) skipStatement(scope *pyScope) {
+	end := p.lineEnd(p.i)
+	p.simple(p.i, end, scope)
+	p.i = end + 1
+}
+
+// define records a symbol whose declaration starts at token start and
+// returns its index.
+func (p *pyParser) define(sym ParsedSymbol, start int) int {
+	sym.StartLine = p.tok(start).line
+	sym.IsExported = !strings.HasPrefix(sym.Name, "_")
+	p.result.Symbols = append(p.result.Symbols, sym)
+	return len(p.result.Symbols) - 1
+}
 
-		calls = append(calls, call)
+// end records that the symbol at index, whose declaration starts at token
+// start, ends with the last token parsed.
+func (p *pyParser) end(index, start int) {
+	if index < 0 {
+		return
 	}
+	last := p.tok(p.lastToken())
+	sym := &p.result.Symbols[index]
+	sym.EndLine = max(last.endLine, sym.StartLine)
+	if begin := p.tok(start).pos; last.end >= begin {
+		sym.Content = p.source[begin:last.end]
+	}
+}
 
-	return calls
+// simple parses the simple statement between the tokens at indices from and
+// to (the NEWLINE ending it).
+func (p *pyParser) simple(from, to int, scope *pyScope) {
+	if from >= to {
+		return
+	}
+	switch {
+	case p.is(from, "import"):
+		p.importModules(from, to)
+		return
+	case p.is(from, "from") && p.find(from, to, "import") >= 0:
+		p.importFrom(from, to)
+		return
+	case p.is(from, "__all__") && scope.parent == nil:
+		p.exportList(from, to)
+	}
+	p.assignment(from, to, scope)
+	p.calls(from-1, to, scope)
+}
+
+// importModules parses "import a.b as c, d".
+func (p *pyParser) importModules(from, to int) {
+	for _, item := range p.split(from, to) {
+		module, j := p.dotted(item[0])
+		if module == "" {
+			continue
+		}
+		imp := ImportStatement{ModulePath: module, StartLine: p.tok(item[0]).line}
+		if p.is(j, "as") {
+			imp.Alias = p.name(j + 1)
+		}
+		p.result.Imports = append(p.result.Imports, imp)
+	}
 }
 
-func (p *PythonParser) extractTypeAnnotations(line string, lineNum int) []TypeAnnotation {
-	var refs []TypeAnnotation
+// importFrom parses "from .module import (a, b as c)".
+func (p *pyParser) importFrom(from, to int) {
+	j := from + 1
+	module := ""
+	for p.is(j, ".") || p.is(j, "...") {
+		module += p.tok(j).text
+		j++
+	}
+	next := j
+	if !p.is(j, "import") {
+		var name string
+		name, next = p.dotted(j)
+		module += name
+	}
+	if module == "" || !p.is(next, "import") {
+		return
+	}
 
-	// Extract return type
-	if idx := strings.Index(line, "->"); idx >= 0 {
-		returnType := strings.TrimSpace(line[idx+2:])
-		if idx := strings.Index(returnType, ":"); idx >= 0 {
-			returnType = returnType[:idx]
+	imp := ImportStatement{
+		ModulePath: module,
+		IsRelative: strings.HasPrefix(module, "."),
+		StartLine:  p.tok(from).line,
+	}
+	open, closeParen := next, to
+	if p.is(next+1, "(") {
+		open, closeParen = next+1, p.matching(next+1)
+	}
+	for _, item := range p.split(open, closeParen) {
+		if symbol := p.tok(item[0]).text; p.name(item[0]) != "" || symbol == "*" {
+			imp.Symbols = append(imp.Symbols, symbol)
 		}
-		returnType = strings.TrimSpace(returnType)
-		if returnType != "" {
-			refs = append(refs, TypeAnnotation{
-				Name:      returnType,
-				Role:      "return",
-				StartLine: lineNum,
-			})
+	}
+	p.result.Imports = append(p.result.Imports, imp)
+}
+
+// dotted returns the dotted name starting at index i and the index after
+// it.
+func (p *pyParser) dotted(i int) (string, int) {
+	name := p.name(i)
+	if name == "" {
+		return "", i
+	}
+	j := i + 1
+	for p.is(j, ".") && p.name(j+1) != "" {
+		name += "." + p.name(j+1)
+		j += 2
+	}
+	return name, j
+}
+
+// exportList records the names a statement adds to __all__: assignments,
+// augmented assignments, and extend() or append() calls.
+func (p *pyParser) exportList(from, to int) {
+	if p.all == nil {
+		p.all = make(map[string]bool)
+	}
+	for j := from; j < to; j++ {
+		if t := p.tok(j); t.kind == pyString {
+			p.all[t.text] = true
 		}
 	}
+}
 
-	// Extract parameter types
-	if idx := strings.Index(line, "("); idx >= 0 {
-		endIdx := strings.Index(line, ")")
-		if endIdx > idx {
-			params := line[idx+1 : endIdx]
-			scanner := bufio.NewScanner(strings.NewReader(params))
-			scanner.Split(func(data []byte, atEOF bool) (advance int, token []byte, err error) {
-				if atEOF && len(data) == 0 {
-					return 0, nil, nil
-				}
-				if idx := strings.IndexByte(string(data), ','); idx >= 0 {
-					return idx + 1, data[0:idx], nil
-				}
-				if atEOF {
-					return len(data), data, nil
-				}
-				return 0, nil, nil
-			})
-			for scanner.Scan() {
-				param := strings.TrimSpace(scanner.Text())
-				if colonIdx := strings.Index(param, ":"); colonIdx >= 0 {
-					typeName := strings.TrimSpace(param[colonIdx+1:])
-					if typeName != "" {
-						refs = append(refs, TypeAnnotation{
-							Name:      typeName,
-							Role:      "param",
-							StartLine: lineNum,
-						})
-					}
-				}
+// assignment records the type of a variable or self attribute assigned an
+// annotated value or a constructor call, and the fields of class bodies.
+func (p *pyParser) assignment(from, to int, scope *pyScope) {
+	target, j := p.dotted(from)
+	attr := ""
+	if rest, ok := strings.CutPrefix(target, "self."); ok && !strings.Contains(rest, ".") && scope.attrs != nil && scope.kind == graph.NodeFunction {
+		attr = rest
+	} else if strings.Contains(target, ".") {
+		return
+	}
+	if target == "" {
+		return
+	}
+
+	typ := ""
+	switch {
+	case p.is(j, ":"):
+		eq := p.find(j, to, "=")
+		if eq < 0 {
+			eq = to
+		}
+		typ = p.annotation(j, eq, "variable", scope)
+		if scope.kind == graph.NodeClass && !p.is(j+1, "ClassVar") {
+			field := target + ": " + p.text(j, eq)
+			if eq < to {
+				field += " = " + p.text(eq, to)
+			}
+			scope.fields = append(scope.fields, field)
+		}
+	case p.is(j, "="):
+		// x = Service(...), x = models.Service(...), x = other
+		callee, k := p.dotted(j + 1)
+		callee = callee[strings.LastIndexByte(callee, '.')+1:]
+		switch {
+		case p.is(k, "(") && callee != "" && callee[0] >= 'A' && callee[0] <= 'Z':
+			typ = callee
+		case k == to && callee != "":
+			typ = scope.varType(callee)
+		}
+	}
+	if typ == "" {
+		return
+	}
+	if attr == "" && scope.parent == nil && p.is(j, "=") && p.tok(j+1).kind == pyName {
+		// bp = Blueprint("users", __name__, url_prefix="/users")
+		// router = APIRouter(prefix="/users")
+		if open := p.find(j, to, "("); open >= 0 {
+			switch typ {
+			case "Blueprint":
+				p.prefixes[target] = p.keyword(open, "url_prefix")
+			case "APIRouter":
+				p.prefixes[target] = p.keyword(open, "prefix")
 			}
 		}
 	}
+	if attr != "" {
+		scope.attrs[attr] = typ
+	} else {
+		scope.vars[target] = typ
+	}
+}
+
+// keyword returns the string value of the keyword argument name of the call
+// whose arguments open at index open, or "".
+func (p *pyParser) keyword(open int, name string) string {
+	for _, arg := range p.split(open, p.matching(open)) {
+		if p.is(arg[0], name) && p.is(arg[0]+1, "=") && p.tok(arg[0]+2).kind == pyString {
+			return p.tok(arg[0] + 2).text
+		}
+	}
+	return ""
+}
 
-	return refs
+// calls records the calls, and the SQL queries, between the tokens at
+// indices from and to, exclusive.
+func (p *pyParser) calls(from, to int, scope *pyScope) {
+	for j := from + 1; j < to; j++ {
+		t := p.tok(j)
+		if t.kind == pyString && p.tok(j-1).kind != pyString {
+			p.sql(j, to, scope)
+		}
+		if t.kind != pyName || !p.is(j+1, "(") || pyNotCalled[t.text] {
+			continue
+		}
+		call := CallSite{
+			Name:      t.text,
+			Receiver:  p.receiver(j, scope),
+			StartLine: t.line,
+			EndLine:   t.line,
+		}
+		call.Enclosing, call.EnclosingClass = p.enclosing(scope)
+		p.result.Calls = append(p.result.Calls, call)
+	}
+}
+
+// sql records the tables created, read and written by the string at index
+// i, with the strings adjacent to it, if it is SQL.
+func (p *pyParser) sql(i, to int, scope *pyScope) {
+	text := p.tok(i).text
+	for j := i + 1; j < to && p.tok(j).kind == pyString; j++ {
+		text += p.tok(j).text
+	}
+	tables, refs := analyzeSQLLiteral(text, p.tok(i).line)
+	p.result.Tables = append(p.result.Tables, tables...)
+	enclosing, enclosingClass := p.enclosing(scope)
+	for _, ref := range refs {
+		ref.Enclosing, ref.EnclosingClass = enclosing, enclosingClass
+		p.result.TableRefs = append(p.result.TableRefs, ref)
+	}
+}
+
+// receiver returns the receiver of the call of the name at index i: the
+// class for self and cls, the type of a variable or self attribute where
+// it is known, or else the object the method is looked up on.
+func (p *pyParser) receiver(i int, scope *pyScope) string {
+	if !p.is(i-1, ".") {
+		return ""
+	}
+
+	// Service().method()
+	if p.is(i-2, ")") {
+		callee := p.name(p.matchingOpen(i-2) - 1)
+		if callee != "" && callee[0] >= 'A' && callee[0] <= 'Z' {
+			return callee
+		}
+		return ""
+	}
+
+	var chain []string
+	j := i - 1
+	for p.is(j, ".") && p.name(j-1) != "" {
+		chain = append([]string{p.name(j - 1)}, chain...)
+		j -= 2
+	}
+	if len(chain) == 0 {
+		return ""
+	}
+	switch {
+	case len(chain) == 1 && (chain[0] == "self" || chain[0] == "cls"):
+		if scope.class != "" {
+			return scope.class
+		}
+	case len(chain) == 2 && chain[0] == "self" && scope.attrs != nil:
+		if t := scope.attrs[chain[1]]; t != "" {
+			return t
+		}
+	case len(chain) == 1:
+		if t := scope.varType(chain[0]); t != "" {
+			return t
+		}
+	}
+	return chain[len(chain)-1]
+}
+
+// finish applies __all__ to the top-level symbols.
+func (p *pyParser) finish() {
+	if p.all == nil {
+		return
+	}
+	for i := range p.result.Symbols {
+		if sym := &p.result.Symbols[i]; sym.ClassName == "" {
+			sym.IsExported = p.all[sym.Name]
+		}
+	}
 }
@@ -0,0 +1,320 @@
+package parsers
+
+import "strings"
+
+// pyTokenKind is the kind of a Python token.
+type pyTokenKind int
+
+const (
+	pyEOF pyTokenKind = iota
+	pyName
+	pyOp
+	pyString
+	pyNumber
+
+	// pyNewline ends a logical line
+	pyNewline
+
+	// pyIndent and pyDedent open and close an indented block
+	pyIndent
+	pyDedent
+)
+
+// pyToken is a token of Python source.
+type pyToken struct {
+	kind pyTokenKind
+
+	// text is the token text; for strings it is the value without prefix
+	// and quotes
+	text string
+
+	// pos and end are the byte offsets of the token in the source
+	pos, end int
+
+	// line and endLine are the 1-based lines of pos and end, which differ
+	// for triple-quoted strings
+	line, endLine int
+}
+
+// pyOperators are the multi-character operators, longest first.
+var pyOperators = []string{
+	"**=", "//=", ">>=", "<<=", "...",
+	"->", ":=", "**", "//", "==", "!=", "<=", ">=", "<<", ">>",
+	"+=", "-=", "*=", "/=", "%=", "&=", "|=", "^=", "@=",
+}
+
+// pyLexer splits Python source into tokens following the language's
+// lexical rules: line breaks inside brackets or after a backslash continue
+// the logical line, blank and comment-only lines are ignored, and changes
+// of indentation produce INDENT and DEDENT tokens. The replacement fields
+// of f-strings are tokenized in place, after the string token.
+type pyLexer struct {
+	src  string
+	pos  int
+	line int
+
+	// depth is the bracket nesting depth
+	depth int
+
+	indents     []int
+	atLineStart bool
+	toks        []pyToken
+}
+
+// lexPython tokenizes source.
+func lexPython(source string) []pyToken {
+	l := &pyLexer{src: source, line: 1, indents: []int{0}, atLineStart: true}
+	for l.pos < len(l.src) {
+		if l.atLineStart && l.depth == 0 {
+			if !l.indentation() {
+				continue
+			}
+		}
+		l.token()
+	}
+
+	if n := len(l.toks); n > 0 && l.toks[n-1].kind != pyNewline {
+		l.emitAt(pyNewline, len(l.src), len(l.src))
+	}
+	for len(l.indents) > 1 {
+		l.indents = l.indents[:len(l.indents)-1]
+		l.emitAt(pyDedent, len(l.src), len(l.src))
+	}
+	l.emitAt(pyEOF, len(l.src), len(l.src))
+	return l.toks
+}
+
+// indentation measures the indentation of a new line and emits INDENT or
+// DEDENT tokens. It returns false for blank and comment-only lines, which
+// it skips.
+func (l *pyLexer) indentation() bool {
+	width := 0
+	i := l.pos
+	for ; i < len(l.src); i++ {
+		switch l.src[i] {
+		case ' ':
+			width++
+			continue
+		case '\t':
+			width += 8 - width%8
+			continue
+		case '\f':
+			width = 0
+			continue
+		}
+		break
+	}
+	l.pos = i
+	if i >= len(l.src) || l.src[i] == '\n' || l.src[i] == '\r' || l.src[i] == '#' {
+		if nl := strings.IndexByte(l.src[i:], '\n'); nl >= 0 {
+			l.pos = i + nl + 1
+			l.line++
+		} else {
+			l.pos = len(l.src)
+		}
+		return false
+	}
+
+	l.atLineStart = false
+	if width > l.indents[len(l.indents)-1] {
+		l.indents = append(l.indents, width)
+		l.emitAt(pyIndent, l.pos, l.pos)
+		return true
+	}
+	for len(l.indents) > 1 && width < l.indents[len(l.indents)-1] {
+		l.indents = l.indents[:len(l.indents)-1]
+		l.emitAt(pyDedent, l.pos, l.pos)
+	}
+	return true
+}
+
+// emitAt appends a token spanning src[start:end] without moving.
+func (l *pyLexer) emitAt(kind pyTokenKind, start, end int) {
+	l.toks = append(l.toks, pyToken{
+		kind:    kind,
+		text:    l.src[start:end],
+		pos:     start,
+		end:     end,
+		line:    l.line,
+		endLine: l.line,
+	})
+}
+
+// emit appends a token spanning src[start:end] and moves past it.
+func (l *pyLexer) emit(kind pyTokenKind, start, end int) {
+	l.emitAt(kind, start, end)
+	l.pos = end
+}
+
+// token lexes the token at the current position.
+func (l *pyLexer) token() {
+	c := l.src[l.pos]
+	switch {
+	case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+		l.pos++
+	case c == '#':
+		if i := strings.IndexByte(l.src[l.pos:], '\n'); i >= 0 {
+			l.pos += i
+		} else {
+			l.pos = len(l.src)
+		}
+	case c == '\\' && strings.HasPrefix(l.src[l.pos+1:], "\n"):
+		l.pos += 2
+		l.line++
+	case c == '\\' && strings.HasPrefix(l.src[l.pos+1:], "\r\n"):
+		l.pos += 3
+		l.line++
+	case c == '\n':
+		if l.depth == 0 {
+			if n := len(l.toks); n > 0 && l.toks[n-1].kind != pyNewline {
+				l.emitAt(pyNewline, l.pos, l.pos)
+			}
+			l.atLineStart = true
+		}
+		l.pos++
+		l.line++
+	case c == '"' || c == '\'':
+		l.string(l.pos, "")
+	case isJSDigit(c) || c == '.' && l.pos+1 < len(l.src) && isJSDigit(l.src[l.pos+1]):
+		end := l.pos + 1
+		for end < len(l.src) && (isPyNamePart(l.src[end]) || l.src[end] == '.' ||
+			(l.src[end] == '+' || l.src[end] == '-') && (l.src[end-1] == 'e' || l.src[end-1] == 'E')) {
+			end++
+		}
+		l.emit(pyNumber, l.pos, end)
+	case isPyNameStart(c):
+		end := l.pos + 1
+		for end < len(l.src) && isPyNamePart(l.src[end]) {
+			end++
+		}
+		if end < len(l.src) && (l.src[end] == '"' || l.src[end] == '\'') && isPyStringPrefix(l.src[l.pos:end]) {
+			start := l.pos
+			l.pos = end
+			l.string(start, l.src[start:end])
+			return
+		}
+		l.emit(pyName, l.pos, end)
+	case c == '(' || c == '[' || c == '{':
+		l.depth++
+		l.emit(pyOp, l.pos, l.pos+1)
+	case c == ')' || c == ']' || c == '}':
+		if l.depth > 0 {
+			l.depth--
+		}
+		l.emit(pyOp, l.pos, l.pos+1)
+	default:
+		for _, op := range pyOperators {
+			if strings.HasPrefix(l.src[l.pos:], op) {
+				l.emit(pyOp, l.pos, l.pos+len(op))
+				return
+			}
+		}
+		l.emit(pyOp, l.pos, l.pos+1)
+	}
+}
+
+// string lexes a string literal whose prefix starts at start and whose
+// opening quote is at the current position.
+func (l *pyLexer) string(start int, prefix string) {
+	quote := l.src[l.pos]
+	delim := string(quote)
+	if strings.HasPrefix(l.src[l.pos:], strings.Repeat(delim, 3)) {
+		delim = strings.Repeat(delim, 3)
+	}
+
+	// A backslash keeps the next character from ending the string, even in
+	// raw strings
+	bodyStart := l.pos + len(delim)
+	i := bodyStart
+	for i < len(l.src) {
+		if l.src[i] == '\\' {
+			i += 2
+			continue
+		}
+		if strings.HasPrefix(l.src[i:], delim) {
+			break
+		}
+		if l.src[i] == '\n' && len(delim) == 1 {
+			break
+		}
+		i++
+	}
+	bodyEnd := min(i, len(l.src))
+	end := min(bodyEnd+len(delim), len(l.src))
+	if bodyEnd < len(l.src) && l.src[bodyEnd] == '\n' {
+		end = bodyEnd
+	}
+
+	startLine := l.line
+	l.toks = append(l.toks, pyToken{
+		kind:    pyString,
+		text:    l.src[bodyStart:bodyEnd],
+		pos:     start,
+		end:     end,
+		line:    startLine,
+		endLine: startLine + strings.Count(l.src[start:end], "\n"),
+	})
+	l.pos = end
+	l.line = startLine + strings.Count(l.src[start:end], "\n")
+
+	if strings.ContainsAny(prefix, "fF") {
+		l.replacementFields(bodyStart, bodyEnd, startLine+strings.Count(l.src[start:bodyStart], "\n"))
+	}
+}
+
+// replacementFields tokenizes the expressions in the replacement fields of
+// the f-string body src[start:end], which starts on line.
+func (l *pyLexer) replacementFields(start, end, line int) {
+	for i := start; i < end; i++ {
+		switch {
+		case strings.HasPrefix(l.src[i:], "{{"):
+			i++
+		case l.src[i] == '{':
+			depth := 0
+			j := i
+			for ; j < end; j++ {
+				if l.src[j] == '{' || l.src[j] == '[' || l.src[j] == '(' {
+					depth++
+				} else if l.src[j] == '}' || l.src[j] == ']' || l.src[j] == ')' {
+					depth--
+					if depth == 0 {
+						break
+					}
+				}
+			}
+			l.embedded(i+1, j, line+strings.Count(l.src[start:i], "\n"))
+			i = j
+		}
+	}
+}
+
+// embedded tokenizes the expression src[start:end] starting on line.
+func (l *pyLexer) embedded(start, end, line int) {
+	pos, curLine, depth, atLineStart := l.pos, l.line, l.depth, l.atLineStart
+	l.pos, l.line, l.depth, l.atLineStart = start, line, 1, false
+	for l.pos < end {
+		l.token()
+	}
+	l.pos, l.line, l.depth, l.atLineStart = pos, curLine, depth, atLineStart
+}
+
+func isPyNameStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isPyNamePart(c byte) bool {
+	return isPyNameStart(c) || isJSDigit(c)
+}
+
+// isPyStringPrefix reports whether s is a string prefix such as r, b or f.
+func isPyStringPrefix(s string) bool {
+	if len(s) > 2 {
+		return false
+	}
+	for _, c := range strings.ToLower(s) {
+		if !strings.ContainsRune("rbuf", c) {
+			return false
+		}
+	}
+	return true
+}
@@ -241,3 +241,379 @@ if __name__ == "__main__":
 	assert.True(t, hasCalls, "Should find function calls")
 	// Note: Method parsing inside classes requires tree-sitter for full support
 }
+
+func TestPythonParser_EnclosingSymbol(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(`
+class UserService:
+    def get_user(self, user_id: int) -> User:
+        return self.repo.load(user_id)
+
+
+def handler(request: Request):
+    service = UserService()
+    return service.get_user(request.id)
+
+
+main()
+`)
+	parser := NewPythonParser()
+	result, err := parser.Parse("app.py", content)
+	require.NoError(t, err)
+
+	symbols := make(map[string]ParsedSymbol)
+	for _, sym := range result.Symbols {
+		symbols[sym.Name] = sym
+	}
+	assert.Equal(t, graph.NodeFunction, symbols["handler"].Kind, "top-level def after a class is not a method")
+	assert.Equal(t, 4, symbols["get_user"].EndLine)
+	assert.Equal(t, 9, symbols["handler"].EndLine)
+
+	enclosing := make(map[string][2]string)
+	for _, call := range result.Calls {
+		enclosing[call.Name] = [2]string{call.Enclosing, call.EnclosingClass}
+	}
+	assert.Equal(t, [2]string{"get_user", "UserService"}, enclosing["load"])
+	assert.Equal(t, [2]string{"handler", ""}, enclosing["UserService"])
+	assert.Equal(t, [2]string{"", ""}, enclosing["main"])
+
+	var found bool
+	for _, ref := range result.TypeRefs {
+		if ref.Name == "Request" {
+			found = true
+			assert.Equal(t, "handler", ref.Enclosing)
+			assert.Equal(t, "param", ref.Role)
+		}
+	}
+	assert.True(t, found, "Should find Request annotation")
+}
+
+func TestPythonParser_Structure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("MultiLineDefinitions", func(t *testing.T) {
+		content := []byte(`@app.route(
+    "/users/<id>",
+    methods=["GET"],
+)
+async def get_user(
+    request: Request,
+    user_id: int = parse(DEFAULT),
+) -> Optional[User]:
+    """Load a user.
+
+    def not_a_function():
+    """
+    data = await fetch(user_id)
+    return User(**data)
+
+class Meta: pass
+`)
+		result, err := NewPythonParser().Parse("views.py", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		require.Contains(t, symbols, "get_user")
+		assert.NotContains(t, symbols, "not_a_function", "definitions in strings are not symbols")
+		sym := symbols["get_user"]
+		assert.Equal(t, 5, sym.StartLine)
+		assert.Equal(t, 14, sym.EndLine)
+		assert.Equal(t, "async def get_user(request: Request, user_id: int = parse(DEFAULT),) -> Optional[User]", sym.Signature)
+		assert.Equal(t, []string{"app.route"}, sym.Decorators)
+		assert.Contains(t, sym.Content, "return User(**data)")
+
+		assert.Equal(t, 16, symbols["Meta"].StartLine)
+		assert.Equal(t, 16, symbols["Meta"].EndLine)
+
+		var types []string
+		for _, ref := range result.TypeRefs {
+			types = append(types, ref.Role+" "+ref.Name)
+		}
+		assert.ElementsMatch(t, []string{"param Request", "param int", "return Optional", "return User"}, types)
+
+		enclosing := make(map[string]string)
+		for _, call := range result.Calls {
+			enclosing[call.Name] = call.Enclosing
+		}
+		assert.Equal(t, map[string]string{"route": "", "parse": "", "fetch": "get_user", "User": "get_user"}, enclosing)
+	})
+
+	t.Run("NestedScopes", func(t *testing.T) {
+		content := []byte(`def retry(times):
+    def decorator(fn):
+        def wrapper(*args):
+            return fn(*args)
+        return wrapper
+    return decorator
+
+
+class Order(models.Model, TimestampMixin, metaclass=ModelBase):
+    total = models.DecimalField(max_digits=10)
+
+    class Meta:
+        ordering = ["-created"]
+
+    def save(self, *args, **kwargs):
+        if self.total < 0:
+            raise ValueError("negative total")
+        super().save(*args, **kwargs)
+
+    @property
+    def label(self):
+        return f"order {self.format(self.total)}"
+`)
+		result, err := NewPythonParser().Parse("models.py", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Equal(t, 6, symbols["retry"].EndLine)
+		assert.NotContains(t, symbols, "decorator", "nested functions belong to their enclosing function")
+		assert.NotContains(t, symbols, "wrapper")
+		assert.NotContains(t, symbols, "Meta", "nested classes belong to their enclosing class")
+
+		assert.Equal(t, 9, symbols["Order"].StartLine)
+		assert.Equal(t, 22, symbols["Order"].EndLine)
+		assert.Equal(t, 15, symbols["Order.save"].StartLine)
+		assert.Equal(t, 18, symbols["Order.save"].EndLine)
+		assert.Equal(t, []string{"property"}, symbols["Order.label"].Decorators)
+
+		assert.Equal(t, []ClassHeritage{{
+			ClassName:  "Order",
+			Extends:    []string{"Model"},
+			Implements: []string{"TimestampMixin"},
+		}}, result.Heritage)
+
+		calls := make(map[string]CallSite)
+		for _, call := range result.Calls {
+			calls[call.Name] = call
+		}
+		assert.Equal(t, "retry", calls["fn"].Enclosing)
+		assert.Equal(t, "Order", calls["DecimalField"].Enclosing)
+		assert.Equal(t, "models", calls["DecimalField"].Receiver)
+		assert.Equal(t, "Order", calls["format"].Receiver, "calls in f-strings are recorded")
+		assert.Equal(t, "label", calls["format"].Enclosing)
+		assert.Equal(t, 22, calls["format"].StartLine)
+	})
+
+	t.Run("AttributeReceivers", func(t *testing.T) {
+		content := []byte(`class UserService:
+    def __init__(self, repo: UserRepository, mailer):
+        self.repo = repo
+        self.cache = cache.LRUCache(100)
+        self.mailer = mailer
+
+    def register(self, email):
+        user = User(email)
+        self.repo.save(user)
+        self.cache.clear()
+        self.mailer.send(email)
+        user.activate()
+        Audit().log(user)
+        os.path.join("a", "b")
+`)
+		result, err := NewPythonParser().Parse("services.py", content)
+		require.NoError(t, err)
+
+		receivers := make(map[string]string)
+		for _, call := range result.Calls {
+			receivers[call.Name] = call.Receiver
+		}
+		assert.Equal(t, "UserRepository", receivers["save"], "self attributes take the type assigned to them")
+		assert.Equal(t, "LRUCache", receivers["clear"])
+		assert.Equal(t, "mailer", receivers["send"], "unknown types leave the attribute name")
+		assert.Equal(t, "User", receivers["activate"], "variables take the class they are constructed from")
+		assert.Equal(t, "Audit", receivers["log"])
+		assert.Equal(t, "path", receivers["join"])
+	})
+
+	t.Run("Dataclasses", func(t *testing.T) {
+		content := []byte(`from dataclasses import dataclass, field
+
+
+@dataclass(frozen=True)
+class Point:
+    x: int
+    y: int = 0
+    tags: list[str] = field(default_factory=list)
+    origin: ClassVar["Point"]
+
+    def norm(self) -> float:
+        return (self.x ** 2 + self.y ** 2) ** 0.5
+`)
+		result, err := NewPythonParser().Parse("geometry.py", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Equal(t, "@dataclass class Point(x: int, y: int = 0, tags: list[str] = field(default_factory=list))", symbols["Point"].Signature)
+		assert.Equal(t, []string{"dataclass"}, symbols["Point"].Decorators)
+		assert.Equal(t, graph.NodeMethod, symbols["Point.norm"].Kind)
+
+		var fieldTypes []string
+		for _, ref := range result.TypeRefs {
+			if ref.Role == "variable" {
+				assert.Equal(t, "Point", ref.Enclosing)
+				fieldTypes = append(fieldTypes, ref.Name)
+			}
+		}
+		assert.Equal(t, []string{"int", "int", "list", "str", "ClassVar", "Point"}, fieldTypes)
+	})
+
+	t.Run("Exports", func(t *testing.T) {
+		content := []byte(`from .base import (
+    Base,
+    helper as _helper,
+)
+from . import utils
+import os.path as osp, sys
+
+__all__ = ["Client"]
+__all__ += ("connect",)
+
+
+class Client(Base):
+    def close(self):
+        pass
+
+
+def connect():
+    return Client()
+
+
+def internal():
+    pass
+`)
+		result, err := NewPythonParser().Parse("client/__init__.py", content)
+		require.NoError(t, err)
+
+		assert.Equal(t, map[string][]string{
+			".base":   {"Base", "helper"},
+			".":       {"utils"},
+			"os.path": nil,
+			"sys":     nil,
+		}, importsByModule(result))
+		assert.Equal(t, "osp", result.Imports[2].Alias)
+		assert.True(t, result.Imports[0].IsRelative)
+
+		symbols := symbolsByName(result)
+		assert.True(t, symbols["Client"].IsExported)
+		assert.True(t, symbols["connect"].IsExported)
+		assert.False(t, symbols["internal"].IsExported, "__all__ decides what is exported")
+		assert.True(t, symbols["Client.close"].IsExported)
+	})
+}
+
+func TestPythonParser_Routes(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Flask", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`from flask import Blueprint, Flask
+
+app = Flask(__name__)
+bp = Blueprint("users", __name__, url_prefix="/users")
+
+
+@app.route("/")
+def index():
+    return "ok"
+
+
+@bp.route("/<int:user_id>", methods=["GET", "DELETE"])
+@login_required
+def user(user_id):
+    return str(user_id)
+
+
+@bp.post("/")
+def create_user():
+    pass
+`)
+		result, err := NewPythonParser().Parse("app.py", content)
+		require.NoError(t, err)
+		routes := routesByName(result)
+
+		assert.Equal(t, Route{Method: "GET", Path: "/", Handler: "index", Framework: "flask", StartLine: 7}, routes["GET /"])
+		assert.Equal(t, "user", routes["GET /users/<int:user_id>"].Handler, "blueprint prefixes")
+		assert.Equal(t, "user", routes["DELETE /users/<int:user_id>"].Handler)
+		assert.Equal(t, "create_user", routes["POST /users/"].Handler)
+		assert.Len(t, result.Routes, 4)
+	})
+
+	t.Run("FastAPI", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`from fastapi import APIRouter, FastAPI
+
+app = FastAPI()
+router = APIRouter(prefix="/items", tags=["items"])
+
+
+@router.get("/{item_id}", response_model=Item)
+async def read_item(item_id: int):
+    return {}
+
+
+@app.api_route("/health", methods=["GET", "HEAD"])
+def health():
+    return {}
+
+
+class Views:
+    @router.put(path="/{item_id}")
+    def update(self, item_id: int):
+        pass
+
+
+@app.middleware("http")
+async def timing(request, call_next):
+    return await call_next(request)
+`)
+		result, err := NewPythonParser().Parse("main.py", content)
+		require.NoError(t, err)
+		routes := routesByName(result)
+
+		readItem := routes["GET /items/{item_id}"]
+		assert.Equal(t, "read_item", readItem.Handler, "router prefixes")
+		assert.Equal(t, "fastapi", readItem.Framework)
+		assert.Equal(t, "health", routes["HEAD /health"].Handler)
+		assert.Equal(t, "update", routes["PUT /items/{item_id}"].Handler)
+		assert.Equal(t, "Views", routes["PUT /items/{item_id}"].Receiver)
+		assert.Len(t, result.Routes, 4, "other decorators are not routes")
+	})
+}
+
+func TestPythonParser_Docstrings(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(`
+class Store:
+    """Persist records.
+
+    Safe for concurrent use.
+    """
+
+    def get(self, key):
+        '''Return the record stored under key.'''
+        return self.data[key]
+
+
+def main(): "Run the server."
+
+
+def undocumented():
+    x = "not a docstring"
+`)
+	result, err := NewPythonParser().Parse("store.py", content)
+	require.NoError(t, err)
+
+	docs := make(map[string]string)
+	for _, sym := range result.Symbols {
+		docs[sym.Name] = sym.Doc
+	}
+	assert.Equal(t, "Persist records.\n\nSafe for concurrent use.", docs["Store"])
+	assert.Equal(t, "Return the record stored under key.", docs["get"])
+	assert.Equal(t, "Run the server.", docs["main"], "one-line bodies have docstrings too")
+	assert.Empty(t, docs["undocumented"])
+}
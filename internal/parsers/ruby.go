@@ -0,0 +1,871 @@
+package parsers
+
+import (
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// RubyParser parses Ruby source code.
+//
+// The source is tokenized (ruby_lexer.go), and the keyword blocks closed by
+// end (class, module, def, do, and if or while unless they are modifiers)
+// are matched like brackets. Classes and modules are symbols, and methods
+// belong to the innermost class or module. A superclass becomes an EXTENDS,
+// and the modules mixed in with include, extend and prepend IMPLEMENTS.
+// Ruby calls methods without parentheses, so any name that is not a local
+// variable is a call.
+type RubyParser struct{}
+
+// NewRubyParser creates a new Ruby parser.
+func NewRubyParser() *RubyParser {
+	return &RubyParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *RubyParser) Language() string {
+	return "ruby"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *RubyParser) SupportsFile(filename string) bool {
+	return strings.HasSuffix(filename, ".rb") || strings.HasSuffix(filename, ".rake")
+}
+
+// Parse parses Ruby source code and extracts symbols, imports, calls, etc.
+func (p *RubyParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	source := string(content)
+	rb := &rbParser{
+		source:     source,
+		toks:       lexRuby(source),
+		fields:     make(map[string]map[string]string),
+		fieldCalls: make(map[int]string),
+		heritage:   make(map[string]int),
+		result: &ParseResult{
+			Symbols:  []ParsedSymbol{},
+			Imports:  []ImportStatement{},
+			Calls:    []CallSite{},
+			TypeRefs: []TypeAnnotation{},
+			Heritage: []ClassHeritage{},
+		},
+	}
+	rb.matchBlocks()
+	rb.body(0, len(rb.toks), rbScope{})
+	rb.finish()
+	setDocComments(rb.result, source, rubyDocComments)
+	return rb.result, nil
+}
+
+// rbNotCalled are the methods of Kernel and Module that are called without
+// a receiver and are never defined in the repository.
+var rbNotCalled = map[string]bool{
+	"require": true, "require_relative": true, "load": true, "autoload": true,
+	"raise": true, "fail": true, "puts": true, "print": true, "p": true, "pp": true,
+	"lambda": true, "proc": true, "loop": true, "catch": true, "throw": true,
+	"format": true, "sprintf": true, "sleep": true, "exit": true, "abort": true,
+	"at_exit": true, "block_given?": true, "binding": true, "caller": true,
+	"attr_reader": true, "attr_writer": true, "attr_accessor": true,
+	"private": true, "public": true, "protected": true, "module_function": true,
+	"private_constant": true, "private_class_method": true, "public_class_method": true,
+	"include": true, "extend": true, "prepend": true, "define_method": true,
+	"send": true, "public_send": true, "respond_to?": true, "is_a?": true,
+	"kind_of?": true, "instance_of?": true, "freeze": true, "tap": true, "then": true,
+}
+
+// rbCallbackOptions are the options of callback macros (before_action
+// :load, if: :admin?, rescue_from Error, with: :handler) that name methods.
+var rbCallbackOptions = map[string]bool{"if": true, "unless": true, "with": true}
+
+// rbScope describes the body statements are parsed in.
+type rbScope struct {
+	// class is the innermost class or module
+	class string
+
+	// singleton is true in class << self, whose methods are class methods
+	singleton bool
+
+	// entry tells how a framework calls the methods of the class:
+	// "action" when the public methods are Rails controller actions or
+	// mailer methods, "job" when perform is run by a job queue
+	entry string
+}
+
+// rbParser parses the token stream of a Ruby file.
+type rbParser struct {
+	source string
+	toks   []rbToken
+
+	// match maps the index of each bracket, block keyword and end to the
+	// index of its partner
+	match []int
+
+	result *ParseResult
+
+	// fields maps class names to the classes their instance variables are
+	// assigned instances of (@repo = UserRepository.new)
+	fields map[string]map[string]string
+
+	// fieldCalls maps the indices of calls on an instance variable
+	// (@repo.save) to the variable; their receivers are resolved once all
+	// classes of the file are known
+	fieldCalls map[int]string
+
+	// heritage maps class names to their index in result.Heritage
+	heritage map[string]int
+}
+
+func (p *rbParser) tok(i int) rbToken {
+	if i < 0 || i >= len(p.toks) {
+		return rbToken{kind: rbEOF, pos: len(p.source), end: len(p.source), newline: true}
+	}
+	return p.toks[i]
+}
+
+// is reports whether the token at index i is the identifier or punctuator text.
+func (p *rbParser) is(i int, text string) bool {
+	t := p.tok(i)
+	return (t.kind == rbIdent || t.kind == rbPunct) && t.text == text
+}
+
+// ident returns the identifier at index i, or "".
+func (p *rbParser) ident(i int) string {
+	if t := p.tok(i); t.kind == rbIdent {
+		return t.text
+	}
+	return ""
+}
+
+// closing returns the index of the token closing the bracket or block
+// opened at index i.
+func (p *rbParser) closing(i int) int {
+	if i < 0 || i >= len(p.toks) || p.match[i] < 0 {
+		return i
+	}
+	return p.match[i]
+}
+
+// afterDot reports whether the identifier at index i is the name of a
+// called method (user.class, range.end) rather than a keyword.
+func (p *rbParser) afterDot(i int) bool {
+	return p.is(i-1, ".") || p.is(i-1, "&.") || p.is(i-1, "def")
+}
+
+// lineEnd returns the index of the first token after index i that starts
+// a new line or statement.
+func (p *rbParser) lineEnd(i int) int {
+	for j := i + 1; ; j++ {
+		if t := p.tok(j); t.newline || t.kind == rbEOF || p.is(j, ";") {
+			return j
+		}
+		if p.is(j, "(") || p.is(j, "[") || p.is(j, "{") {
+			j = p.closing(j)
+		}
+	}
+}
+
+// statementStart reports whether the token at index i starts a statement.
+func (p *rbParser) statementStart(i int) bool {
+	return p.tok(i).newline || p.is(i-1, ";")
+}
+
+// value reports whether the token at index i ends an operand.
+func (p *rbParser) value(i int) bool {
+	t := p.tok(i)
+	switch t.kind {
+	case rbIdent:
+		return !rbKeywords[t.text] || rbValueKeywords[t.text] || p.afterDot(i)
+	case rbIVar, rbGVar, rbSymbol, rbString, rbNumber:
+		return true
+	case rbPunct:
+		return t.text == ")" || t.text == "]" || t.text == "}"
+	}
+	return false
+}
+
+// matchBlocks pairs the brackets of the file, then the keywords opening
+// blocks with their end. Unclosed brackets and blocks are paired with the
+// end of the file.
+func (p *rbParser) matchBlocks() {
+	p.match = make([]int, len(p.toks))
+	var stack []int
+	for i, t := range p.toks {
+		p.match[i] = -1
+		if t.kind != rbPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{", "#{":
+			stack = append(stack, i)
+		case ")", "]", "}":
+			if n := len(stack); n > 0 {
+				p.match[stack[n-1]] = i
+				p.match[i] = stack[n-1]
+				stack = stack[:n-1]
+			}
+		}
+	}
+	for _, open := range stack {
+		p.match[open] = len(p.toks)
+	}
+
+	// Loops whose do keyword (while x do) is still to come
+	loops := make(map[int]bool)
+	stack = stack[:0]
+	for i, t := range p.toks {
+		if t.kind != rbIdent || p.afterDot(i) {
+			continue
+		}
+		open := false
+		switch t.text {
+		case "end":
+			if n := len(stack); n > 0 {
+				p.match[stack[n-1]] = i
+				p.match[i] = stack[n-1]
+				stack = stack[:n-1]
+			}
+		case "do":
+			if n := len(stack); n > 0 && loops[stack[n-1]] && p.toks[stack[n-1]].line == t.line {
+				delete(loops, stack[n-1])
+				continue
+			}
+			open = true
+		case "class", "module", "case", "begin":
+			open = true
+		case "for":
+			open, loops[i] = true, true
+		case "def":
+			open = !p.endless(i)
+		case "if", "unless":
+			open = t.newline || p.is(i-1, ";") || !p.value(i-1)
+		case "while", "until":
+			open = t.newline || p.is(i-1, ";") || !p.value(i-1)
+			loops[i] = open
+		}
+		if open {
+			stack = append(stack, i)
+		}
+	}
+	for _, open := range stack {
+		p.match[open] = len(p.toks)
+	}
+}
+
+// methodName returns the name of the method defined at index i (after def
+// and the singleton receiver) and the index after it.
+func (p *rbParser) methodName(i int) (string, int) {
+	t := p.tok(i)
+	switch {
+	case t.kind == rbIdent:
+		// Setters: def name=(value)
+		if next := p.tok(i + 1); p.is(i+1, "=") && next.pos == t.end && p.is(i+2, "(") {
+			return t.text + "=", i + 2
+		}
+		return t.text, i + 1
+	case t.kind == rbLabel:
+		// def name:(...) does not exist; a label here is a keyword named
+		// method followed by a symbol, which the lexer cannot tell apart
+		return t.text, i + 1
+	case p.is(i, "["):
+		name, j := "[]", p.closing(i)+1
+		if p.is(j, "=") && p.tok(j).pos == p.tok(j-1).end {
+			name, j = "[]=", j+1
+		}
+		return name, j
+	case t.kind == rbPunct && t.text != "(" && t.text != ";":
+		return t.text, i + 1
+	}
+	return "", i
+}
+
+// methodStart returns the index of the name of the method defined by the
+// def at index i, and whether it is defined on a receiver (def self.name).
+func (p *rbParser) methodStart(i int) (int, bool) {
+	if (p.tok(i+1).kind == rbIdent) && p.is(i+2, ".") {
+		return i + 3, true
+	}
+	return i + 1, false
+}
+
+// endless reports whether the def at index i defines an endless method
+// (def name(args) = expression), which has no end.
+func (p *rbParser) endless(i int) bool {
+	start, _ := p.methodStart(i)
+	_, j := p.methodName(start)
+	if p.is(j, "(") {
+		j = p.match[j] + 1
+		if j <= 0 {
+			return false
+		}
+	}
+	return p.is(j, "=") && p.tok(j).line == p.tok(i).line
+}
+
+// define appends a symbol declared from the token at index start to the
+// token at index last.
+func (p *rbParser) define(sym ParsedSymbol, start, last int) {
+	first, end := p.tok(start), p.tok(min(last, len(p.toks)-1))
+	sym.StartLine = first.line
+	sym.EndLine = max(end.endLine, first.line)
+	if end.end > first.pos {
+		sym.Content = p.source[first.pos:end.end]
+	}
+	p.result.Symbols = append(p.result.Symbols, sym)
+}
+
+// addHeritage records that class extends or mixes in the given classes
+// and modules.
+func (p *rbParser) addHeritage(class string, extends, implements []string) {
+	index, ok := p.heritage[class]
+	if !ok {
+		index = len(p.result.Heritage)
+		p.heritage[class] = index
+		p.result.Heritage = append(p.result.Heritage, ClassHeritage{ClassName: class})
+	}
+	h := &p.result.Heritage[index]
+	h.Extends = append(h.Extends, extends...)
+	h.Implements = append(h.Implements, implements...)
+}
+
+// constant returns the last name of the constant path (A::B::C) starting
+// at index i, and the index after it.
+func (p *rbParser) constant(i int) (string, int) {
+	if p.is(i, "::") {
+		i++
+	}
+	name := ""
+	for {
+		part := p.ident(i)
+		if part == "" || !isUpper(part[0]) {
+			return name, i
+		}
+		name = part
+		if !p.is(i+1, "::") {
+			return name, i + 1
+		}
+		i += 2
+	}
+}
+
+// body parses the statements between the tokens at indices from and to,
+// exclusive, of a file, class or module.
+func (p *rbParser) body(from, to int, scope rbScope) {
+	firstSymbol := len(p.result.Symbols)
+	public := true
+	next := "" // the visibility of the next def: private def name
+	var private []string
+
+	loose := from
+	flush := func(end int) {
+		if loose < end {
+			p.calls(loose-1, end, "", scope.class, make(map[string]string))
+		}
+	}
+
+	for j := from; j < to && j < len(p.toks); {
+		t := p.toks[j]
+		if t.kind != rbIdent || p.afterDot(j) {
+			j++
+			continue
+		}
+		switch {
+		case (t.text == "class" || t.text == "module") && p.match[j] > j:
+			flush(j)
+			j = p.class(j, scope)
+			loose = j
+			continue
+		case t.text == "def":
+			flush(j)
+			exported := public
+			if next != "" {
+				exported = next == "public"
+				next = ""
+			}
+			j = p.method(j, scope, exported)
+			loose = j
+			continue
+		case scope.class == "" || !p.statementStart(j):
+		case t.text == "private" || t.text == "protected" || t.text == "public" || t.text == "module_function":
+			names, end := p.symbols(j + 1)
+			switch {
+			case len(names) > 0:
+				if t.text != "public" {
+					private = append(private, names...)
+				}
+			case end == j+1:
+				public = t.text != "private" && t.text != "protected"
+			default:
+				// private def name, private attr_reader :name
+				next = t.text
+				if next == "module_function" {
+					next = "public"
+				}
+			}
+		case t.text == "private_class_method":
+			names, _ := p.symbols(j + 1)
+			private = append(private, names...)
+		case t.text == "include" || t.text == "extend" || t.text == "prepend":
+			var mixins []string
+			for k := j + 1; k < p.lineEnd(j); k++ {
+				name, after := p.constant(k)
+				if name != "" && !p.is(after, ".") {
+					mixins = append(mixins, name)
+				}
+				k = after
+			}
+			if len(mixins) > 0 {
+				p.addHeritage(scope.class, nil, mixins)
+			}
+		case t.text == "attr_reader" || t.text == "attr_writer" || t.text == "attr_accessor":
+			exported := public
+			if next != "" {
+				exported, next = next == "public", ""
+			}
+			names, end := p.symbols(j + 1)
+			for _, name := range names {
+				if t.text == "attr_writer" {
+					name += "="
+				}
+				p.define(ParsedSymbol{
+					Name:       name,
+					Kind:       graph.NodeMethod,
+					Signature:  t.text + " :" + strings.TrimSuffix(name, "="),
+					ClassName:  scope.class,
+					IsExported: exported,
+					IsProperty: true,
+				}, j, end-1)
+			}
+		case strings.HasPrefix(t.text, "before_") || strings.HasPrefix(t.text, "after_") ||
+			strings.HasPrefix(t.text, "around_") || t.text == "validate" || t.text == "helper_method":
+			// Callbacks name the methods they call: before_action :authenticate
+			names, _ := p.symbols(j + 1)
+			for _, name := range names {
+				p.result.Calls = append(p.result.Calls, CallSite{
+					Name:           name,
+					Receiver:       scope.class,
+					StartLine:      t.line,
+					EndLine:        t.line,
+					EnclosingClass: scope.class,
+				})
+			}
+		}
+		j++
+	}
+	flush(min(to, len(p.toks)))
+
+	if len(private) == 0 {
+		return
+	}
+	hidden := make(map[string]bool)
+	for _, name := range private {
+		hidden[name] = true
+	}
+	for i := firstSymbol; i < len(p.result.Symbols); i++ {
+		sym := &p.result.Symbols[i]
+		if sym.ClassName == scope.class && hidden[sym.Name] {
+			sym.IsExported = false
+			sym.IsEntryPoint = false
+		}
+	}
+}
+
+// symbols returns the symbols and strings listed after the method called
+// at index i-1 (private :a, :b), and the index after the statement.
+func (p *rbParser) symbols(i int) ([]string, int) {
+	end := p.lineEnd(i - 1)
+	var names []string
+	for j := i; j < end; j++ {
+		t := p.tok(j)
+		if t.kind == rbLabel {
+			// Options follow the names: before_action :load, only: :show
+			break
+		}
+		if t.kind == rbSymbol || t.kind == rbString && !p.is(j+1, "#{") {
+			names = append(names, t.text)
+		}
+	}
+	return names, end
+}
+
+// class parses the class or module whose keyword is at index i and returns
+// the index after its end.
+func (p *rbParser) class(i int, scope rbScope) int {
+	end := p.closing(i)
+	if p.is(i, "class") && p.is(i+1, "<<") {
+		// class << self: the methods are class methods
+		p.body(p.lineEnd(i), end, rbScope{class: scope.class, singleton: true, entry: scope.entry})
+		return end + 1
+	}
+	name, after := p.constant(i + 1)
+	if name == "" {
+		return i + 1
+	}
+
+	superclass := ""
+	if p.is(i, "class") && p.is(after, "<") {
+		base, next := p.constant(after + 1)
+		// Computed superclasses: Struct.new(:a, :b)
+		if base != "" && !p.is(next, ".") && !p.is(next, "(") {
+			superclass = base
+		}
+	}
+	if superclass != "" {
+		p.addHeritage(name, []string{superclass}, nil)
+	}
+
+	lineEnd := p.lineEnd(i)
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeClass,
+		Signature:  p.tok(i).text + " " + p.text(i, lineEnd),
+		IsExported: true,
+	}, i, end)
+
+	entry := ""
+	switch {
+	case superclass != "" && (strings.HasSuffix(name, "Controller") || strings.HasSuffix(name, "Mailer")):
+		entry = "action"
+	case strings.HasSuffix(name, "Job") || strings.HasSuffix(name, "Worker"):
+		entry = "job"
+	}
+	p.body(lineEnd, end, rbScope{class: name, entry: entry})
+	return end + 1
+}
+
+// text returns the source between the tokens at index i, exclusive, and
+// index j, exclusive, with whitespace collapsed.
+func (p *rbParser) text(i, j int) string {
+	start, end := p.tok(i).end, p.tok(j-1).end
+	if end <= start {
+		return ""
+	}
+	return strings.Join(strings.Fields(p.source[start:end]), " ")
+}
+
+// method parses the method defined at index i and returns the index after
+// it.
+func (p *rbParser) method(i int, scope rbScope, public bool) int {
+	nameIndex, onReceiver := p.methodStart(i)
+	name, j := p.methodName(nameIndex)
+	if name == "" {
+		return i + 1
+	}
+	singleton := scope.singleton || onReceiver
+
+	vars := make(map[string]string)
+	sigEnd := j
+	switch {
+	case p.is(j, "("):
+		closeParen := p.closing(j)
+		p.parameters(j, closeParen, vars)
+		sigEnd = closeParen + 1
+	case !p.tok(j).newline && !p.is(j, "=") && !p.is(j, ";"):
+		// Parameters without parentheses: def name a, b
+		sigEnd = p.lineEnd(j - 1)
+		p.parameters(j-1, sigEnd, vars)
+	}
+
+	last := p.match[i]
+	bodyStart := sigEnd - 1
+	if last < 0 {
+		// Endless methods: def name(args) = expression
+		last = p.lineEnd(sigEnd) - 1
+		bodyStart = sigEnd
+	}
+
+	kind := graph.NodeFunction
+	class := ""
+	if scope.class != "" {
+		kind, class = graph.NodeMethod, scope.class
+	}
+	public = public && name != "initialize" && name != "method_missing" && name != "respond_to_missing?"
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       kind,
+		Signature:  "def " + p.text(i, sigEnd),
+		ClassName:  class,
+		IsExported: public,
+		IsEntryPoint: public && !singleton &&
+			(scope.entry == "action" || scope.entry == "job" && name == "perform"),
+	}, i, last)
+
+	p.calls(bodyStart, min(last, len(p.toks)), name, class, vars)
+	return last + 1
+}
+
+// parameters records the names of the parameters between the tokens at
+// indices open and closeParen as local variables.
+func (p *rbParser) parameters(open, closeParen int, vars map[string]string) {
+	for j := open + 1; j < closeParen; j++ {
+		t := p.tok(j)
+		switch {
+		case t.kind == rbLabel:
+			vars[t.text] = ""
+		case t.kind == rbIdent && (j == open+1 || p.is(j-1, ",") || p.is(j-1, "*") || p.is(j-1, "**") || p.is(j-1, "&")):
+			vars[t.text] = ""
+		case p.is(j, "(") || p.is(j, "[") || p.is(j, "{"):
+			j = p.closing(j)
+		}
+	}
+}
+
+// constructed returns the class instantiated by the expression at index i
+// (UserRepository.new, Billing::Invoice.new(...)), or "".
+func (p *rbParser) constructed(i int) string {
+	name, after := p.constant(i)
+	if name != "" && p.is(after, ".") && p.is(after+1, "new") {
+		return name
+	}
+	return ""
+}
+
+// calls records the calls between the tokens at indices from and to,
+// exclusive, made in the method enclosing of class; vars holds the local
+// variables and the classes they hold instances of, when known.
+func (p *rbParser) calls(from, to int, enclosing, class string, vars map[string]string) {
+	for j := from + 1; j < to && j < len(p.toks); j++ {
+		t := p.toks[j]
+		switch t.kind {
+		case rbLabel:
+			p.routeCall(j, enclosing, class)
+			if rbCallbackOptions[t.text] && enclosing == "" && class != "" && p.tok(j+1).kind == rbSymbol {
+				p.result.Calls = append(p.result.Calls, CallSite{
+					Name:           p.tok(j + 1).text,
+					Receiver:       class,
+					StartLine:      t.line,
+					EndLine:        t.line,
+					EnclosingClass: class,
+				})
+			}
+			continue
+		case rbIVar:
+			if p.is(j+1, "=") && class != "" {
+				if typ := p.constructed(j + 2); typ != "" {
+					if p.fields[class] == nil {
+						p.fields[class] = make(map[string]string)
+					}
+					p.fields[class][t.text] = typ
+				}
+			}
+			continue
+		case rbPunct:
+			// Block parameters: do |user, index|
+			if t.text == "|" && (p.is(j-1, "do") || p.is(j-1, "{")) {
+				for j++; j < to && !p.is(j, "|"); j++ {
+					if name := p.ident(j); name != "" {
+						vars[name] = ""
+					}
+				}
+			}
+			continue
+		case rbIdent:
+		default:
+			continue
+		}
+
+		name := t.text
+		prevDot := p.is(j-1, ".") || p.is(j-1, "&.")
+		switch {
+		case p.is(j-1, "def") || isUpper(name[0]):
+			// Constants are receivers (User.find) or instantiated (User.new)
+			continue
+		case !prevDot && rbKeywords[name]:
+			if name == "for" {
+				vars[p.ident(j+1)] = ""
+			}
+			continue
+		case name == "require" || name == "require_relative" || name == "load" || name == "autoload":
+			if !prevDot {
+				p.require(j)
+			}
+			continue
+		}
+
+		call := CallSite{
+			Name:           name,
+			StartLine:      t.line,
+			EndLine:        t.line,
+			Enclosing:      enclosing,
+			EnclosingClass: class,
+		}
+		switch {
+		case prevDot && name == "new":
+			// Instantiation: User.new, Billing::Invoice.new
+			typ, _ := p.constant(p.constantStart(j - 2))
+			if typ == "" {
+				call.Receiver = p.receiver(j-2, vars, class, len(p.result.Calls))
+				break
+			}
+			call.Name = typ
+		case prevDot:
+			call.Receiver = p.receiver(j-2, vars, class, len(p.result.Calls))
+		case p.is(j-1, "::"):
+			// Class methods called with ::, Foo::bar
+			call.Receiver = p.ident(j - 2)
+		default:
+			if p.is(j+1, "=") || p.is(j+1, "||=") || p.is(j+1, "&&=") {
+				// Assignments declare local variables
+				if _, ok := vars[name]; !ok || vars[name] == "" {
+					vars[name] = p.constructed(j + 2)
+				}
+				continue
+			}
+			if names := p.multipleAssignment(j); len(names) > 0 {
+				for _, name := range names {
+					vars[name] = ""
+				}
+				continue
+			}
+			if _, ok := vars[name]; ok && !p.is(j+1, "(") {
+				continue
+			}
+			if p.is(j-1, "=>") && p.rescued(j) {
+				// rescue Error => e
+				vars[name] = ""
+				continue
+			}
+			if rbNotCalled[name] {
+				continue
+			}
+			if name == "new" && class != "" {
+				// new in a class method instantiates the class
+				call.Name = class
+				break
+			}
+			call.Receiver = class
+		}
+		p.result.Calls = append(p.result.Calls, call)
+	}
+}
+
+// constantStart returns the index of the first name of the constant path
+// ending at index i.
+func (p *rbParser) constantStart(i int) int {
+	for p.is(i-1, "::") && p.ident(i-2) != "" {
+		i -= 2
+	}
+	return i
+}
+
+// multipleAssignment returns the variables assigned by the multiple
+// assignment starting at index i (a, b = pair), or nil.
+func (p *rbParser) multipleAssignment(i int) []string {
+	if !p.statementStart(i) || !p.is(i+1, ",") {
+		return nil
+	}
+	var names []string
+	for j := i; ; j += 2 {
+		if p.is(j, "*") {
+			j++
+		}
+		name := p.ident(j)
+		if name == "" {
+			return nil
+		}
+		names = append(names, name)
+		switch {
+		case p.is(j+1, "="):
+			return names
+		case !p.is(j+1, ","):
+			return nil
+		}
+	}
+}
+
+// rescued reports whether the line of the token at index i is a rescue
+// clause.
+func (p *rbParser) rescued(i int) bool {
+	for j := i - 1; j >= 0 && p.tok(j).line == p.tok(i).line; j-- {
+		if p.is(j, "rescue") {
+			return true
+		}
+	}
+	return false
+}
+
+// receiver resolves the receiver of a method call whose receiver
+// expression ends at index j: self is the class, a local variable the
+// class it was assigned an instance of, and a constant the class itself.
+// index is the index the call will have in result.Calls.
+func (p *rbParser) receiver(j int, vars map[string]string, class string, index int) string {
+	t := p.tok(j)
+	switch t.kind {
+	case rbIVar:
+		if class != "" && !strings.HasPrefix(t.text, "@@") {
+			p.fieldCalls[index] = t.text
+		}
+		return strings.TrimLeft(t.text, "@")
+	case rbIdent:
+	default:
+		return ""
+	}
+	switch {
+	case t.text == "self":
+		return class
+	case p.is(j-1, ".") || p.is(j-1, "&."):
+		return t.text
+	}
+	if typ := vars[t.text]; typ != "" {
+		return typ
+	}
+	return t.text
+}
+
+// routeCall records the controller action a Rails route names (get
+// "/users", to: "users#index") as a call to it.
+func (p *rbParser) routeCall(j int, enclosing, class string) {
+	target := p.tok(j + 1)
+	if p.tok(j).text != "to" || target.kind != rbString {
+		return
+	}
+	controller, action, ok := strings.Cut(target.text, "#")
+	if !ok || controller == "" || action == "" {
+		return
+	}
+	var name strings.Builder
+	for _, part := range strings.Split(controller[strings.LastIndexByte(controller, '/')+1:], "_") {
+		if part != "" {
+			name.WriteString(strings.ToUpper(part[:1]) + part[1:])
+		}
+	}
+	p.result.Calls = append(p.result.Calls, CallSite{
+		Name:           action,
+		Receiver:       name.String() + "Controller",
+		StartLine:      target.line,
+		EndLine:        target.line,
+		Enclosing:      enclosing,
+		EnclosingClass: class,
+	})
+}
+
+// require records the require, require_relative, load or autoload at
+// index i as an import.
+func (p *rbParser) require(i int) {
+	j := i + 1
+	if p.is(j, "(") {
+		j++
+	}
+	imp := ImportStatement{StartLine: p.tok(i).line, IsRelative: p.is(i, "require_relative")}
+	if p.is(i, "autoload") {
+		if p.tok(j).kind != rbSymbol || !p.is(j+1, ",") {
+			return
+		}
+		imp.Symbols = []string{p.tok(j).text}
+		j += 2
+	}
+	if t := p.tok(j); t.kind != rbString || p.is(j+1, "#{") {
+		return
+	}
+	imp.ModulePath = p.tok(j).text
+	p.result.Imports = append(p.result.Imports, imp)
+}
+
+// finish resolves the receivers of calls on instance variables to the
+// classes the variables are assigned instances of.
+func (p *rbParser) finish() {
+	for index, field := range p.fieldCalls {
+		call := &p.result.Calls[index]
+		if typ := p.fields[call.EnclosingClass][field]; typ != "" {
+			call.Receiver = typ
+		}
+	}
+}
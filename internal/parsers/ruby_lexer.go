@@ -0,0 +1,556 @@
+package parsers
+
+import "strings"
+
+// rbTokenKind is the kind of a Ruby token.
+type rbTokenKind int
+
+const (
+	rbEOF rbTokenKind = iota
+	rbIdent
+	rbIVar
+	rbGVar
+	rbSymbol
+	rbLabel
+	rbString
+	rbNumber
+	rbPunct
+)
+
+// rbToken is a token of Ruby source.
+type rbToken struct {
+	kind rbTokenKind
+
+	// text is the token text: the body of a string, the name of a symbol
+	// or label without its colon, and instance variables with their '@'
+	text string
+
+	// pos and end are the byte offsets of the token in the source
+	pos, end int
+
+	// line and endLine are the 1-based lines of pos and end
+	line, endLine int
+
+	// spaced is true when whitespace precedes the token
+	spaced bool
+
+	// newline is true for the first token of a line
+	newline bool
+}
+
+// rbKeywords are Ruby's reserved words.
+var rbKeywords = map[string]bool{
+	"alias": true, "and": true, "begin": true, "BEGIN": true, "break": true,
+	"case": true, "class": true, "def": true, "defined?": true, "do": true,
+	"else": true, "elsif": true, "end": true, "END": true, "ensure": true,
+	"false": true, "for": true, "if": true, "in": true, "module": true,
+	"next": true, "nil": true, "not": true, "or": true, "redo": true,
+	"rescue": true, "retry": true, "return": true, "self": true, "super": true,
+	"then": true, "true": true, "undef": true, "unless": true, "until": true,
+	"when": true, "while": true, "yield": true, "__FILE__": true,
+	"__LINE__": true, "__method__": true, "__dir__": true, "__ENCODING__": true,
+}
+
+// rbValueKeywords are the keywords that end an expression, after which an
+// if or while is a modifier and a '/' a division.
+var rbValueKeywords = map[string]bool{
+	"end": true, "self": true, "nil": true, "true": true, "false": true,
+	"return": true, "break": true, "next": true, "redo": true, "retry": true,
+	"yield": true, "super": true, "__FILE__": true, "__LINE__": true,
+	"__method__": true, "__dir__": true, "__ENCODING__": true,
+}
+
+// rbPunctuators are the multi-character punctuators, longest first.
+var rbPunctuators = []string{
+	"**=", "<=>", "===", "...", "<<=", ">>=", "&&=", "||=",
+	"**", "==", "!=", ">=", "<=", "&&", "||", "<<", ">>", "=~", "!~",
+	"::", "..", "->", "=>", "+=", "-=", "*=", "/=", "%=", "|=", "&=", "^=", "&.",
+}
+
+// rbLexer splits Ruby source into tokens, skipping whitespace, comments,
+// =begin/=end blocks and everything after __END__. Strings, symbols,
+// heredocs, regular expressions and %-literals are single tokens; the code
+// interpolated into them (#{...}) follows them as tokens between "#{" and
+// "}", so that calls in it are seen.
+type rbLexer struct {
+	src  string
+	pos  int
+	line int
+	toks []rbToken
+
+	// lineStart is true until the first token of a line, spaced until the
+	// next token after whitespace
+	lineStart, spaced bool
+
+	// heredocs are the heredocs started on the current line, whose bodies
+	// begin on the next
+	heredocs []rbHeredoc
+}
+
+// rbHeredoc is a heredoc whose body has not been read yet.
+type rbHeredoc struct {
+	// token is the index of its string token
+	token int
+
+	// terminator ends the body, on a line of its own
+	terminator string
+
+	// indented is true when the terminator may be indented (<<~ and <<-)
+	indented bool
+
+	interpolated bool
+}
+
+// lexRuby tokenizes source.
+func lexRuby(source string) []rbToken {
+	l := &rbLexer{src: source, line: 1, lineStart: true}
+	l.run()
+	return l.toks
+}
+
+func (l *rbLexer) run() {
+	for l.pos < len(l.src) {
+		l.token()
+	}
+}
+
+// emit appends a token spanning src[start:end] and moves past it.
+func (l *rbLexer) emit(kind rbTokenKind, text string, start, end int) {
+	lines := strings.Count(l.src[start:end], "\n")
+	l.toks = append(l.toks, rbToken{
+		kind: kind, text: text, pos: start, end: end,
+		line: l.line, endLine: l.line + lines,
+		spaced: l.spaced, newline: l.lineStart,
+	})
+	l.line += lines
+	l.pos = end
+	l.lineStart = false
+	l.spaced = false
+}
+
+// afterValue reports whether the previous token ends an operand, so that
+// what follows is an operator rather than the start of a literal.
+func (l *rbLexer) afterValue() bool {
+	if len(l.toks) == 0 {
+		return false
+	}
+	t := l.toks[len(l.toks)-1]
+	switch t.kind {
+	case rbIdent:
+		return !rbKeywords[t.text] || rbValueKeywords[t.text]
+	case rbIVar, rbGVar, rbSymbol, rbString, rbNumber:
+		return true
+	case rbPunct:
+		return t.text == ")" || t.text == "]" || t.text == "}"
+	}
+	return false
+}
+
+// commandArgument reports whether a literal may start at the current
+// position although an identifier precedes it: the first argument of a
+// method called without parentheses (puts /x/, validates %w[a]).
+func (l *rbLexer) commandArgument() bool {
+	if len(l.toks) == 0 || !l.spaced || l.pos+1 >= len(l.src) {
+		return false
+	}
+	t := l.toks[len(l.toks)-1]
+	next := l.src[l.pos+1]
+	return t.kind == rbIdent && !rbKeywords[t.text] && next != ' ' && next != '=' && next != '\n'
+}
+
+// token lexes the token at the current position.
+func (l *rbLexer) token() {
+	c := l.src[l.pos]
+	rest := l.src[l.pos:]
+	if l.lineStart && l.atLineStart() {
+		switch {
+		case rbDirective(rest, "=begin"):
+			l.blockComment()
+			return
+		case rbDirective(rest, "__END__"):
+			l.pos = len(l.src)
+			return
+		}
+	}
+
+	switch {
+	case c == '\n':
+		l.line++
+		l.pos++
+		l.lineStart = true
+		l.spaced = false
+		if len(l.heredocs) > 0 {
+			l.heredocBodies()
+		}
+	case c == ' ' || c == '\t' || c == '\r' || c == '\f' || c == '\v':
+		l.pos++
+		l.spaced = true
+	case strings.HasPrefix(rest, "\\\n") || strings.HasPrefix(rest, "\\\r\n"):
+		l.pos += strings.IndexByte(rest, '\n') + 1
+		l.line++
+		l.spaced = true
+	case c == '#':
+		if i := strings.IndexByte(rest, '\n'); i >= 0 {
+			l.pos += i
+		} else {
+			l.pos = len(l.src)
+		}
+	case c == '"' || c == '`':
+		l.quoted(rbString, l.pos, l.pos+1, c, true)
+	case c == '\'':
+		l.quoted(rbString, l.pos, l.pos+1, c, false)
+	case c == '@':
+		end := l.pos + 1
+		if end < len(l.src) && l.src[end] == '@' {
+			end++
+		}
+		end = l.nameEnd(end)
+		l.emit(rbIVar, l.src[l.pos:end], l.pos, end)
+	case c == '$':
+		end := l.nameEnd(l.pos + 1)
+		if end == l.pos+1 && end < len(l.src) {
+			// Special variables: $!, $0, $~
+			end++
+		}
+		l.emit(rbGVar, l.src[l.pos:end], l.pos, end)
+	case c == ':' && len(rest) > 1 && rest[1] != ':' && (rbNameStart(rest[1]) || rest[1] == '"' || rest[1] == '\''):
+		l.symbol()
+	case isJSDigit(c):
+		l.number()
+	case rbNameStart(c):
+		l.identifier()
+	case c == '%' && (!l.afterValue() || l.commandArgument()) && l.percentLiteral():
+	case c == '/' && (!l.afterValue() || l.commandArgument()):
+		l.quoted(rbString, l.pos, l.pos+1, '/', true)
+	case strings.HasPrefix(rest, "<<") && l.heredocStart():
+	case c == '?' && !l.afterValue() && len(rest) > 2 && rest[1] > ' ' && !rbNamePart(rest[2]):
+		// Character literals: ?a
+		l.emit(rbString, rest[1:2], l.pos, l.pos+2)
+	default:
+		for _, punct := range rbPunctuators {
+			if strings.HasPrefix(rest, punct) {
+				l.emit(rbPunct, punct, l.pos, l.pos+len(punct))
+				return
+			}
+		}
+		l.emit(rbPunct, rest[:1], l.pos, l.pos+1)
+	}
+}
+
+// atLineStart reports whether the current position is the first column.
+func (l *rbLexer) atLineStart() bool {
+	return l.pos == 0 || l.src[l.pos-1] == '\n'
+}
+
+// rbDirective reports whether rest starts with a line-start marker such as
+// =begin or __END__, followed by the end of the word.
+func rbDirective(rest, marker string) bool {
+	return strings.HasPrefix(rest, marker) && (len(rest) == len(marker) || !rbNamePart(rest[len(marker)]))
+}
+
+// blockComment skips an =begin/=end comment.
+func (l *rbLexer) blockComment() {
+	for l.pos < len(l.src) {
+		lineEnd := strings.IndexByte(l.src[l.pos:], '\n')
+		if lineEnd < 0 {
+			l.pos = len(l.src)
+			return
+		}
+		done := rbDirective(l.src[l.pos:], "=end")
+		l.pos += lineEnd
+		if done {
+			return
+		}
+		l.pos++
+		l.line++
+	}
+}
+
+func rbNameStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= 0x80
+}
+
+func rbNamePart(c byte) bool {
+	return rbNameStart(c) || isJSDigit(c)
+}
+
+// nameEnd returns the end of the name starting at index i.
+func (l *rbLexer) nameEnd(i int) int {
+	for i < len(l.src) && rbNamePart(l.src[i]) {
+		i++
+	}
+	return i
+}
+
+// identifier lexes an identifier, keyword or label. Method names may end
+// in '?' or '!' (valid?, save!), and a name directly followed by a single
+// colon is a label (key: value).
+func (l *rbLexer) identifier() {
+	start := l.pos
+	end := l.nameEnd(start)
+	if end < len(l.src) && (l.src[end] == '?' || l.src[end] == '!') {
+		after := byte(0)
+		if end+1 < len(l.src) {
+			after = l.src[end+1]
+		}
+		if after != '=' && (l.src[end] == '!' || !rbNamePart(after) && after != ':') {
+			end++
+		} else if after == '=' && end+2 < len(l.src) && l.src[end+2] == '=' {
+			end++
+		}
+	}
+	word := l.src[start:end]
+	afterDot := false
+	if n := len(l.toks); n > 0 {
+		prev := l.toks[n-1]
+		afterDot = prev.kind == rbPunct && (prev.text == "." || prev.text == "&.")
+	}
+	if end+1 < len(l.src) && l.src[end] == ':' && l.src[end+1] != ':' && !afterDot {
+		if n := len(l.toks); n == 0 || l.toks[n-1].text != "?" {
+			l.emit(rbLabel, word, start, end+1)
+			return
+		}
+	}
+	l.emit(rbIdent, word, start, end)
+}
+
+// symbol lexes a symbol: :name, :name?, :name=, :@ivar or :"quoted".
+func (l *rbLexer) symbol() {
+	start := l.pos
+	if q := l.src[start+1]; q == '"' || q == '\'' {
+		l.quoted(rbSymbol, start, start+2, q, q == '"')
+		return
+	}
+	end := l.nameEnd(start + 1)
+	if end < len(l.src) && (l.src[end] == '?' || l.src[end] == '!' || l.src[end] == '=') &&
+		(end+1 >= len(l.src) || l.src[end+1] != '=' && l.src[end+1] != '>' && l.src[end+1] != '~') {
+		end++
+	}
+	l.emit(rbSymbol, l.src[start+1:end], start, end)
+}
+
+// number lexes a numeric literal: integers with underscores and radix
+// prefixes, floats, exponents and the rational and imaginary suffixes.
+func (l *rbLexer) number() {
+	end := l.pos + 1
+	for end < len(l.src) {
+		d := l.src[end]
+		switch {
+		case rbNamePart(d):
+			end++
+		case d == '.' && end+1 < len(l.src) && isJSDigit(l.src[end+1]):
+			end++
+		case (d == '+' || d == '-') && (l.src[end-1] == 'e' || l.src[end-1] == 'E') && !strings.HasPrefix(l.src[l.pos:], "0x"):
+			end++
+		default:
+			l.emit(rbNumber, l.src[l.pos:end], l.pos, end)
+			return
+		}
+	}
+	l.emit(rbNumber, l.src[l.pos:end], l.pos, end)
+}
+
+// rbClosing maps the opening delimiters of %-literals to their closing ones.
+var rbClosing = map[byte]byte{'(': ')', '[': ']', '{': '}', '<': '>'}
+
+// percentLiteral lexes a %-literal (%w[a b], %i(a b), %q{...}, %r{...})
+// and reports whether there is one at the current position.
+func (l *rbLexer) percentLiteral() bool {
+	i := l.pos + 1
+	interpolated := true
+	if i < len(l.src) && strings.IndexByte("qQwWiIrsx", l.src[i]) >= 0 {
+		interpolated = strings.IndexByte("QWIrx", l.src[i]) >= 0
+		i++
+	}
+	if i >= len(l.src) {
+		return false
+	}
+	open := l.src[i]
+	if rbNamePart(open) || open == ' ' || open == '\n' || open == '\t' || open == '=' {
+		return false
+	}
+	l.quoted(rbString, l.pos, i+1, open, interpolated)
+	return true
+}
+
+// quoted lexes a literal whose body starts at index from, after the opening
+// delimiter open; paired delimiters nest. The code interpolated into it is
+// lexed after it.
+func (l *rbLexer) quoted(kind rbTokenKind, start, from int, open byte, interpolated bool) {
+	closeDelim, paired := rbClosing[open]
+	if !paired {
+		closeDelim = open
+	}
+	var spans [][2]int
+	depth := 0
+	i := from
+	for i < len(l.src) {
+		c := l.src[i]
+		switch {
+		case c == '\\':
+			i += 2
+			continue
+		case interpolated && c == '#' && i+1 < len(l.src) && l.src[i+1] == '{':
+			end := rbInterpolationEnd(l.src, i+2)
+			spans = append(spans, [2]int{i, end})
+			i = end + 1
+			continue
+		case paired && c == open:
+			depth++
+		case c == closeDelim:
+			if depth == 0 {
+				l.finishQuoted(kind, start, from, i, spans)
+				return
+			}
+			depth--
+		}
+		i++
+	}
+	l.finishQuoted(kind, start, from, len(l.src), spans)
+}
+
+// finishQuoted emits a literal whose body ends at index bodyEnd, before
+// its closing delimiter, followed by its interpolations.
+func (l *rbLexer) finishQuoted(kind rbTokenKind, start, from, bodyEnd int, spans [][2]int) {
+	end := min(bodyEnd+1, len(l.src))
+	if l.src[start] == '/' || strings.HasPrefix(l.src[start:], "%r") {
+		// Regular expression flags
+		for end < len(l.src) && l.src[end] >= 'a' && l.src[end] <= 'z' {
+			end++
+		}
+	}
+	startLine := l.line
+	l.emit(kind, l.src[from:min(bodyEnd, len(l.src))], start, end)
+	for _, span := range spans {
+		l.interpolation(span, startLine+strings.Count(l.src[start:span[0]], "\n"))
+	}
+}
+
+// rbInterpolationEnd returns the index of the '}' closing the interpolation
+// whose code starts at index i, skipping nested braces and strings.
+func rbInterpolationEnd(src string, i int) int {
+	depth := 0
+	for i < len(src) {
+		switch c := src[i]; c {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			if depth == 0 {
+				return i
+			}
+			depth--
+		case '"', '\'', '`':
+			for i++; i < len(src) && src[i] != c; i++ {
+				if src[i] == '\\' {
+					i++
+				}
+			}
+		}
+		i++
+	}
+	return len(src)
+}
+
+// interpolation lexes the code of the interpolation spanning src[span[0]:
+// span[1]], from its "#{" to its '}', which starts on the given line.
+func (l *rbLexer) interpolation(span [2]int, line int) {
+	if span[1] > len(l.src) {
+		span[1] = len(l.src)
+	}
+	sub := &rbLexer{src: l.src[:span[1]], pos: span[0] + 2, line: line}
+	sub.toks = append(sub.toks, rbToken{kind: rbPunct, text: "#{", pos: span[0], end: span[0] + 2, line: line, endLine: line})
+	sub.run()
+	l.toks = append(l.toks, sub.toks...)
+	if span[1] < len(l.src) {
+		l.toks = append(l.toks, rbToken{kind: rbPunct, text: "}", pos: span[1], end: span[1] + 1, line: sub.line, endLine: sub.line})
+	}
+}
+
+// heredocStart lexes the start of a heredoc (<<~SQL, <<-EOS, <<'EOS') and
+// reports whether there is one at the current position. Its body is read
+// at the end of the line.
+func (l *rbLexer) heredocStart() bool {
+	i := l.pos + 2
+	indented := false
+	if i < len(l.src) && (l.src[i] == '~' || l.src[i] == '-') {
+		indented = true
+		i++
+	}
+	if i >= len(l.src) {
+		return false
+	}
+	quote := l.src[i]
+	interpolated := quote != '\''
+	var terminator string
+	end := i
+	switch {
+	case quote == '\'' || quote == '"' || quote == '`':
+		closeQuote := strings.IndexByte(l.src[i+1:], quote)
+		if closeQuote < 0 {
+			return false
+		}
+		terminator = l.src[i+1 : i+1+closeQuote]
+		end = i + closeQuote + 2
+	case rbNameStart(quote):
+		end = l.nameEnd(i)
+		terminator = l.src[i:end]
+		// <<NAME without ~ or - must be upper case: arr <<value is a shift
+		if !indented && strings.ToUpper(terminator) != terminator {
+			return false
+		}
+	default:
+		return false
+	}
+	l.heredocs = append(l.heredocs, rbHeredoc{token: len(l.toks), terminator: terminator, indented: indented, interpolated: interpolated})
+	l.emit(rbString, "", l.pos, end)
+	return true
+}
+
+// heredocBodies reads the bodies of the heredocs started on the previous
+// line, which start at the current position.
+func (l *rbLexer) heredocBodies() {
+	heredocs := l.heredocs
+	l.heredocs = nil
+	for _, h := range heredocs {
+		bodyStart := l.pos
+		bodyEnd := len(l.src)
+		next := len(l.src)
+		for i := l.pos; i < len(l.src); {
+			lineEnd := strings.IndexByte(l.src[i:], '\n')
+			if lineEnd < 0 {
+				lineEnd = len(l.src)
+			} else {
+				lineEnd += i
+			}
+			text := strings.TrimRight(l.src[i:lineEnd], "\r")
+			if h.indented {
+				text = strings.TrimLeft(text, " \t")
+			}
+			if text == h.terminator {
+				bodyEnd, next = i, lineEnd
+				break
+			}
+			i = lineEnd + 1
+		}
+		l.toks[h.token].text = l.src[bodyStart:bodyEnd]
+		line := l.line
+		l.line += strings.Count(l.src[bodyStart:next], "\n")
+		l.pos = next
+		if !h.interpolated {
+			continue
+		}
+		for i := bodyStart; i < bodyEnd; i++ {
+			if l.src[i] == '\\' {
+				i++
+				continue
+			}
+			if l.src[i] == '#' && i+1 < bodyEnd && l.src[i+1] == '{' {
+				end := rbInterpolationEnd(l.src[:bodyEnd], i+2)
+				l.interpolation([2]int{i, end}, line+strings.Count(l.src[bodyStart:i], "\n"))
+				i = end
+			}
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestRubyParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ParseController", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`require "json"
+require_relative "../services/billing"
+
+module Admin
+  class UsersController < ApplicationController
+    include Pagination, Auditing
+    before_action :authenticate, only: [:show]
+    after_action :track, if: :tracked?
+
+    def initialize
+      @repo = UserRepository.new
+    end
+
+    def index
+      users = User.where(active: true)
+      users.each do |user|
+        render_user user
+      end
+      @repo.save(users) if users.any?
+    end
+
+    def show = render(plain: "#{name}")
+
+    private
+
+    def authenticate
+      raise NotAuthorized unless current_user
+    end
+
+    def render_user(user)
+      user.to_h
+    end
+  end
+end
+`)
+		result, err := NewRubyParser().Parse("app/controllers/admin/users_controller.rb", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		admin := symbols["Admin"]
+		assert.Equal(t, graph.NodeClass, admin.Kind, "modules are classes")
+		assert.Equal(t, 4, admin.StartLine)
+		assert.Equal(t, 34, admin.EndLine)
+
+		controller := symbols["UsersController"]
+		assert.Equal(t, "class UsersController < ApplicationController", controller.Signature)
+		assert.Equal(t, 5, controller.StartLine)
+		assert.Equal(t, 33, controller.EndLine)
+
+		index := symbols["UsersController.index"]
+		assert.Equal(t, graph.NodeMethod, index.Kind)
+		assert.True(t, index.IsExported)
+		assert.True(t, index.IsEntryPoint, "public controller methods are actions")
+		assert.Equal(t, 14, index.StartLine)
+		assert.Equal(t, 20, index.EndLine)
+
+		show := symbols["UsersController.show"]
+		assert.True(t, show.IsEntryPoint, "endless methods")
+		assert.Equal(t, 22, show.EndLine)
+
+		authenticate := symbols["UsersController.authenticate"]
+		assert.False(t, authenticate.IsExported)
+		assert.False(t, authenticate.IsEntryPoint, "private methods are not actions")
+		assert.False(t, symbols["UsersController.initialize"].IsEntryPoint)
+
+		require.Len(t, result.Heritage, 1)
+		assert.Equal(t, []string{"ApplicationController"}, result.Heritage[0].Extends)
+		assert.Equal(t, []string{"Pagination", "Auditing"}, result.Heritage[0].Implements)
+
+		require.Len(t, result.Imports, 2)
+		assert.Equal(t, ImportStatement{ModulePath: "json", StartLine: 1}, result.Imports[0])
+		assert.Equal(t, ImportStatement{ModulePath: "../services/billing", IsRelative: true, StartLine: 2}, result.Imports[1])
+
+		callback := findCall(result.Calls, "authenticate", 7)
+		require.NotNil(t, callback, "callbacks call the methods they name")
+		assert.Equal(t, "UsersController", callback.Receiver)
+		assert.NotNil(t, findCall(result.Calls, "tracked?", 8), "callback conditions")
+
+		assert.NotNil(t, findCall(result.Calls, "UserRepository", 11), "new instantiates the class")
+
+		where := findCall(result.Calls, "where", 15)
+		require.NotNil(t, where)
+		assert.Equal(t, "User", where.Receiver)
+		assert.Equal(t, "index", where.Enclosing)
+		assert.Equal(t, "UsersController", where.EnclosingClass)
+
+		renderUser := findCall(result.Calls, "render_user", 17)
+		require.NotNil(t, renderUser, "calls without parentheses")
+		assert.Equal(t, "UsersController", renderUser.Receiver)
+		assert.Nil(t, findCall(result.Calls, "user", 17), "block parameters are variables")
+
+		save := findCall(result.Calls, "save", 19)
+		require.NotNil(t, save)
+		assert.Equal(t, "UserRepository", save.Receiver, "instance variable receivers resolve to the assigned class")
+
+		assert.NotNil(t, findCall(result.Calls, "name", 22), "calls in interpolations")
+		assert.NotNil(t, findCall(result.Calls, "current_user", 27))
+		assert.Nil(t, findCall(result.Calls, "raise", 27))
+	})
+
+	t.Run("ParseModule", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`module Billing
+  class Invoice < Struct.new(:amount)
+    attr_reader :lines
+    attr_writer :note
+
+    class << self
+      def build(attrs) = new(attrs)
+    end
+
+    def self.parse(text)
+      text.split(",").map { |part| Line.new(part) }
+    end
+
+    def total
+      lines.sum(&:amount) + tax
+    end
+
+    def ==(other)
+      other.total == total
+    end
+
+    def tax
+      0
+    end
+    private :tax
+  end
+
+  def self.helper; end
+end
+
+def format_money(cents)
+  "%.2f" % (cents / 100.0)
+end
+`)
+		result, err := NewRubyParser().Parse("lib/billing.rb", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Empty(t, result.Heritage, "computed superclasses are skipped")
+
+		lines := symbols["Invoice.lines"]
+		assert.True(t, lines.IsProperty)
+		assert.Equal(t, "attr_reader :lines", lines.Signature)
+		assert.Contains(t, symbols, "Invoice.note=")
+
+		assert.Equal(t, "def build(attrs)", symbols["Invoice.build"].Signature)
+		assert.Equal(t, "def self.parse(text)", symbols["Invoice.parse"].Signature)
+		assert.Equal(t, 12, symbols["Invoice.parse"].EndLine)
+		assert.Equal(t, 16, symbols["Invoice.total"].EndLine)
+		assert.Contains(t, symbols, "Invoice.==")
+		assert.False(t, symbols["Invoice.tax"].IsExported, "private :name")
+		assert.False(t, symbols["Invoice.total"].IsEntryPoint)
+		assert.Contains(t, symbols, "Billing.helper")
+
+		formatMoney := symbols["format_money"]
+		assert.Equal(t, graph.NodeFunction, formatMoney.Kind)
+		assert.True(t, formatMoney.IsExported)
+		assert.Equal(t, 33, formatMoney.EndLine)
+
+		build := findCall(result.Calls, "Invoice", 7)
+		require.NotNil(t, build, "new in a class method instantiates the class")
+		assert.Equal(t, "build", build.Enclosing)
+
+		assert.NotNil(t, findCall(result.Calls, "Line", 11))
+		assert.Nil(t, findCall(result.Calls, "part", 11), "block parameters are variables")
+
+		tax := findCall(result.Calls, "tax", 15)
+		require.NotNil(t, tax)
+		assert.Equal(t, "Invoice", tax.Receiver)
+		assert.NotNil(t, findCall(result.Calls, "lines", 15))
+
+		otherTotal := findCall(result.Calls, "total", 19)
+		require.NotNil(t, otherTotal)
+		assert.Equal(t, "other", otherTotal.Receiver)
+	})
+
+	t.Run("ParseLiterals", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`class Report
+  TEMPLATE = <<~SQL
+    SELECT * FROM users
+    WHERE class = 'end'
+  SQL
+
+  def run
+    names = %w[begin end class]
+    pattern = /def (\w+)/
+    return if names.empty?
+    while ready? do
+      step
+    end
+    emit(pattern, TEMPLATE)
+  end
+end
+=begin
+def hidden
+end
+=end
+`)
+		result, err := NewRubyParser().Parse("report.rb", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		require.Contains(t, symbols, "Report")
+		assert.Equal(t, 16, symbols["Report"].EndLine)
+		run := symbols["Report.run"]
+		assert.Equal(t, 7, run.StartLine)
+		assert.Equal(t, 15, run.EndLine)
+		assert.NotContains(t, symbols, "hidden", "block comments are skipped")
+
+		assert.NotNil(t, findCall(result.Calls, "ready?", 11))
+		assert.NotNil(t, findCall(result.Calls, "step", 12))
+		assert.NotNil(t, findCall(result.Calls, "emit", 14))
+		assert.Nil(t, findCall(result.Calls, "names", 10), "assigned names are variables")
+	})
+
+	t.Run("ParseRoutes", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`Rails.application.routes.draw do
+  get "/users", to: "admin/user_accounts#index"
+end
+`)
+		result, err := NewRubyParser().Parse("config/routes.rb", content)
+		require.NoError(t, err)
+
+		index := findCall(result.Calls, "index", 2)
+		require.NotNil(t, index, "routes call their controller actions")
+		assert.Equal(t, "UserAccountsController", index.Receiver)
+	})
+
+	t.Run("ParseJob", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`class CleanupJob < ApplicationJob
+  def perform(id)
+    purge(id)
+  end
+
+  def purge(id); end
+end
+`)
+		result, err := NewRubyParser().Parse("app/jobs/cleanup_job.rb", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.True(t, symbols["CleanupJob.perform"].IsEntryPoint)
+		assert.False(t, symbols["CleanupJob.purge"].IsEntryPoint)
+	})
+
+	t.Run("ParseEmptyFile", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := NewRubyParser().Parse("empty.rb", []byte(""))
+		require.NoError(t, err)
+		assert.Empty(t, result.Symbols)
+		assert.Empty(t, result.Calls)
+	})
+}
+
+func TestRubyParser_Language(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "ruby", NewRubyParser().Language())
+}
+
+func TestRubyParser_FileDetection(t *testing.T) {
+	t.Parallel()
+
+	parser := NewRubyParser()
+	assert.True(t, parser.SupportsFile("app/models/user.rb"))
+	assert.True(t, parser.SupportsFile("lib/tasks/deploy.rake"))
+	assert.False(t, parser.SupportsFile("Gemfile.lock"))
+	assert.False(t, parser.SupportsFile("app/views/users/index.html.erb"))
+}
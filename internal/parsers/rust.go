@@ -0,0 +1,1078 @@
+package parsers
+
+import (
+	"strings"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// RustParser parses Rust source code.
+//
+// The source is tokenized (rust_lexer.go) and parsed item by item, following
+// the brace structure of modules, impl blocks and traits. Functions and
+// methods are symbols; their bodies are scanned for calls. Methods belong to
+// the type of their impl block, trait methods to the trait, and
+// impl Trait for Type becomes an IMPLEMENTS of Type.
+type RustParser struct{}
+
+// NewRustParser creates a new Rust parser.
+func NewRustParser() *RustParser {
+	return &RustParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *RustParser) Language() string {
+	return "rust"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *RustParser) SupportsFile(filename string) bool {
+	return strings.HasSuffix(filename, ".rs")
+}
+
+// Parse parses Rust source code and extracts symbols, imports, calls, etc.
+func (p *RustParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	source := string(content)
+	rs := &rsParser{
+		source:     source,
+		toks:       lexRust(source),
+		fields:     make(map[string]map[string]string),
+		fieldCalls: make(map[int]string),
+		heritage:   make(map[string]int),
+		result: &ParseResult{
+			Symbols:  []ParsedSymbol{},
+			Imports:  []ImportStatement{},
+			Calls:    []CallSite{},
+			TypeRefs: []TypeAnnotation{},
+			Heritage: []ClassHeritage{},
+		},
+	}
+	rs.matchBrackets()
+	rs.items(len(rs.toks), rsScope{})
+	rs.finish()
+	setDocComments(rs.result, source, rustDocComments)
+	return rs.result, nil
+}
+
+// rsPrimitives are the primitive types, which are never declared.
+var rsPrimitives = map[string]bool{
+	"i8": true, "i16": true, "i32": true, "i64": true, "i128": true, "isize": true,
+	"u8": true, "u16": true, "u32": true, "u64": true, "u128": true, "usize": true,
+	"f32": true, "f64": true, "bool": true, "char": true, "str": true,
+}
+
+// rsTypeKeywords are the keywords that appear in types.
+var rsTypeKeywords = map[string]bool{
+	"dyn": true, "impl": true, "mut": true, "const": true, "for": true,
+	"where": true, "as": true, "fn": true, "unsafe": true, "extern": true,
+}
+
+// rsTypeWrappers are the smart pointers and wrappers whose type argument is
+// the type a field or variable holds: Arc<Mutex<Repository>>.
+var rsTypeWrappers = map[string]bool{
+	"Box": true, "Rc": true, "Arc": true, "Option": true, "RefCell": true,
+	"Cell": true, "Mutex": true, "RwLock": true, "Weak": true,
+}
+
+// rsNotCalled are the keywords and constructors that precede a parenthesis
+// without being calls.
+var rsNotCalled = map[string]bool{
+	"if": true, "while": true, "match": true, "return": true, "for": true,
+	"in": true, "loop": true, "else": true, "let": true, "move": true,
+	"as": true, "break": true, "fn": true, "Some": true, "Ok": true, "Err": true,
+}
+
+// rsScope describes the block items are parsed in.
+type rsScope struct {
+	// typeName is the type of the enclosing impl block or trait
+	typeName string
+
+	// public is true in traits and trait impls, whose items are as visible
+	// as the trait
+	public bool
+}
+
+// rsParser parses the token stream of a Rust file.
+type rsParser struct {
+	source string
+	toks   []rsToken
+
+	// match maps the index of each bracket to the index of its partner
+	match []int
+
+	i      int
+	result *ParseResult
+
+	// fields maps type names to the declared types of their fields
+	fields map[string]map[string]string
+
+	// fieldCalls maps the indices of calls on a field of self
+	// (self.repo.save()) to the field; their receivers are resolved once
+	// all structs of the file are known
+	fieldCalls map[int]string
+
+	// heritage maps type names to their index in result.Heritage
+	heritage map[string]int
+}
+
+// matchBrackets pairs the parentheses, brackets and braces of the file.
+// Unclosed brackets are paired with the end of the file.
+func (p *rsParser) matchBrackets() {
+	p.match = make([]int, len(p.toks))
+	var stack []int
+	for i, t := range p.toks {
+		p.match[i] = -1
+		if t.kind != rsPunct {
+			continue
+		}
+		switch t.text {
+		case "(", "[", "{":
+			stack = append(stack, i)
+		case ")", "]", "}":
+			if n := len(stack); n > 0 {
+				p.match[stack[n-1]] = i
+				p.match[i] = stack[n-1]
+				stack = stack[:n-1]
+			}
+		}
+	}
+	for _, open := range stack {
+		p.match[open] = len(p.toks)
+	}
+}
+
+func (p *rsParser) tok(i int) rsToken {
+	if i < 0 || i >= len(p.toks) {
+		return rsToken{kind: rsEOF, pos: len(p.source), end: len(p.source)}
+	}
+	return p.toks[i]
+}
+
+// is reports whether the token at index i is the identifier or punctuator text.
+func (p *rsParser) is(i int, text string) bool {
+	t := p.tok(i)
+	return (t.kind == rsIdent || t.kind == rsPunct) && t.text == text
+}
+
+// ident returns the identifier at index i, or "".
+func (p *rsParser) ident(i int) string {
+	if t := p.tok(i); t.kind == rsIdent {
+		return t.text
+	}
+	return ""
+}
+
+// closing returns the index of the bracket closing the one at index i.
+func (p *rsParser) closing(i int) int {
+	if i < 0 || i >= len(p.toks) || p.match[i] < 0 {
+		return i
+	}
+	return p.match[i]
+}
+
+// text returns the source between the tokens at indices i and j, exclusive,
+// with whitespace collapsed.
+func (p *rsParser) text(i, j int) string {
+	start, end := p.tok(i).end, p.tok(j).pos
+	if end <= start {
+		return ""
+	}
+	return strings.Join(strings.Fields(p.source[start:end]), " ")
+}
+
+// angleEnd returns the index of the '>' closing the generics opened at
+// index i, or i when it is not closed before the end of the item.
+func (p *rsParser) angleEnd(i int) int {
+	depth := 0
+	for j := i; j < len(p.toks); j++ {
+		switch {
+		case p.is(j, "<"):
+			depth++
+		case p.is(j, ">"):
+			depth--
+			if depth == 0 {
+				return j
+			}
+		case p.is(j, "(") || p.is(j, "["):
+			j = p.closing(j)
+		case p.is(j, ";") || p.is(j, "{") || p.is(j, "}"):
+			return i
+		}
+	}
+	return i
+}
+
+// items parses the items from the current position to the token at index
+// end, which closes their block.
+func (p *rsParser) items(end int, scope rsScope) {
+	for p.i < end && p.i < len(p.toks) {
+		attributes := p.attributes()
+		if p.i >= end {
+			return
+		}
+		start := p.i
+		public := p.visibility() || scope.public
+
+		// Qualifiers of functions, traits and impls
+		for {
+			switch {
+			case p.is(p.i, "async") || p.is(p.i, "unsafe") || p.is(p.i, "default") || p.is(p.i, "auto"):
+				p.i++
+				continue
+			case p.is(p.i, "const") && (p.is(p.i+1, "fn") || p.is(p.i+1, "unsafe") || p.is(p.i+1, "async") || p.is(p.i+1, "extern")):
+				p.i++
+				continue
+			case p.is(p.i, "extern") && p.tok(p.i+1).kind == rsString && p.is(p.i+2, "fn"):
+				p.i += 2
+				continue
+			case p.is(p.i, "extern") && p.is(p.i+1, "fn"):
+				p.i++
+				continue
+			}
+			break
+		}
+
+		switch {
+		case p.is(p.i, "fn"):
+			p.function(start, public, attributes, scope)
+		case (p.is(p.i, "struct") || p.is(p.i, "union")) && p.ident(p.i+1) != "":
+			p.structure(start, public, attributes)
+		case p.is(p.i, "enum") && p.ident(p.i+1) != "":
+			p.enumeration(start, public, attributes)
+		case p.is(p.i, "trait") && p.ident(p.i+1) != "":
+			p.trait(start, public, attributes)
+		case p.is(p.i, "impl"):
+			p.implementation()
+		case p.is(p.i, "type") && p.ident(p.i+1) != "" && scope.typeName == "":
+			p.typeAlias(start, public)
+		case p.is(p.i, "mod") && p.ident(p.i+1) != "":
+			p.module(scope)
+		case p.is(p.i, "use"):
+			p.use()
+		case p.is(p.i, "extern") && p.is(p.i+1, "crate"):
+			p.externCrate()
+		case p.ident(p.i) != "" && p.ident(p.i) != "macro_rules" && p.is(p.i+1, "!") && p.is(p.i+2, "{"):
+			// Items wrapped in a macro invocation: cfg_rt! { ... }
+			closeBrace := p.closing(p.i + 2)
+			p.i += 3
+			p.items(closeBrace, scope)
+			p.i = closeBrace + 1
+		default:
+			p.skipItem()
+		}
+	}
+}
+
+// attributes skips the outer and inner attributes at the current position
+// and returns the paths of the outer ones: #[test], #[tokio::main].
+func (p *rsParser) attributes() []string {
+	var names []string
+	for p.is(p.i, "#") {
+		j := p.i + 1
+		inner := p.is(j, "!")
+		if inner {
+			j++
+		}
+		if !p.is(j, "[") {
+			return names
+		}
+		closeBracket := p.closing(j)
+		if name := p.path(j + 1); name != "" && !inner && name != "doc" {
+			names = append(names, name)
+		}
+		p.i = closeBracket + 1
+	}
+	return names
+}
+
+// path returns the path (a::b::c) starting at index j.
+func (p *rsParser) path(j int) string {
+	var parts []string
+	for p.ident(j) != "" {
+		parts = append(parts, p.ident(j))
+		if !p.is(j+1, "::") {
+			break
+		}
+		j += 2
+	}
+	return strings.Join(parts, "::")
+}
+
+// visibility skips a visibility modifier and reports whether the item is
+// public outside its crate. pub(crate), pub(super) and pub(in path) are not.
+func (p *rsParser) visibility() bool {
+	if !p.is(p.i, "pub") {
+		return false
+	}
+	p.i++
+	if p.is(p.i, "(") && (p.is(p.i+1, "crate") || p.is(p.i+1, "super") || p.is(p.i+1, "self") || p.is(p.i+1, "in")) {
+		p.i = p.closing(p.i) + 1
+		return false
+	}
+	return true
+}
+
+// skipItem moves past an item the parser does not record: a const, static
+// or macro invocation. Calls in it are attributed to the file.
+func (p *rsParser) skipItem() {
+	start := p.i
+	for p.i < len(p.toks) {
+		switch {
+		case p.is(p.i, ";"):
+			p.calls(start, p.i, "", "", nil)
+			p.i++
+			return
+		case p.is(p.i, "{"):
+			closeBrace := p.closing(p.i)
+			p.calls(start, closeBrace, "", "", nil)
+			p.i = closeBrace + 1
+			// Braced statements end at their brace unless they are part of an
+			// expression (static X: T = T { .. };)
+			if !p.is(start, "const") && !p.is(start, "static") && !p.is(start, "let") {
+				if p.is(p.i, ";") {
+					p.i++
+				}
+				return
+			}
+		case p.is(p.i, "(") || p.is(p.i, "["):
+			p.i = p.closing(p.i) + 1
+		case p.is(p.i, "}"):
+			// A stray closing brace: leave it to the enclosing block
+			if p.i == start {
+				p.i++
+			}
+			return
+		default:
+			p.i++
+		}
+	}
+}
+
+// define appends a symbol declared from the token at index start to the
+// token at index last.
+func (p *rsParser) define(sym ParsedSymbol, start, last int) {
+	first, end := p.tok(start), p.tok(last)
+	sym.StartLine = first.line
+	sym.EndLine = max(end.endLine, first.line)
+	if end.end > first.pos {
+		sym.Content = p.source[first.pos:end.end]
+	}
+	p.result.Symbols = append(p.result.Symbols, sym)
+}
+
+// addHeritage records that typeName extends or implements the given traits.
+func (p *rsParser) addHeritage(typeName string, extends, implements []string) {
+	index, ok := p.heritage[typeName]
+	if !ok {
+		index = len(p.result.Heritage)
+		p.heritage[typeName] = index
+		p.result.Heritage = append(p.result.Heritage, ClassHeritage{ClassName: typeName})
+	}
+	h := &p.result.Heritage[index]
+	h.Extends = append(h.Extends, extends...)
+	h.Implements = append(h.Implements, implements...)
+}
+
+// function parses the function whose fn keyword is at the current position.
+// Functions without a body (trait and extern declarations) are recorded
+// too, the way Go interface methods are.
+func (p *rsParser) function(start int, public bool, attributes []string, scope rsScope) {
+	fnIndex := p.i
+	name := p.ident(fnIndex + 1)
+	j := fnIndex + 2
+	if p.is(j, "<") {
+		j = p.angleEnd(j) + 1
+	}
+	if !p.is(j, "(") || name == "" {
+		p.skipItem()
+		return
+	}
+	open := j
+	closeParen := p.closing(open)
+
+	// The return type runs to the body, the where clause or the ';'
+	retEnd := closeParen + 1
+	if p.is(retEnd, "->") {
+		for retEnd++; retEnd < len(p.toks); retEnd++ {
+			if p.is(retEnd, "<") {
+				retEnd = p.angleEnd(retEnd)
+				continue
+			}
+			if p.is(retEnd, "(") || p.is(retEnd, "[") {
+				retEnd = p.closing(retEnd)
+				continue
+			}
+			if p.is(retEnd, "{") || p.is(retEnd, ";") || p.is(retEnd, "where") {
+				break
+			}
+		}
+	}
+	bodyOpen := retEnd
+	for bodyOpen < len(p.toks) && !p.is(bodyOpen, "{") && !p.is(bodyOpen, ";") {
+		if p.is(bodyOpen, "(") || p.is(bodyOpen, "[") {
+			bodyOpen = p.closing(bodyOpen)
+		}
+		bodyOpen++
+	}
+	last := bodyOpen
+	if p.is(bodyOpen, "{") {
+		last = p.closing(bodyOpen)
+	}
+
+	kind := graph.NodeFunction
+	if scope.typeName != "" {
+		kind = graph.NodeMethod
+	}
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       kind,
+		Signature:  "fn " + name + p.text(fnIndex+1, retEnd),
+		ClassName:  scope.typeName,
+		IsExported: public,
+		Decorators: attributes,
+	}, start, last)
+
+	vars := p.parameters(open, closeParen, name, scope.typeName)
+	if p.is(closeParen+1, "->") {
+		p.typeRefs(closeParen+1, retEnd, "return", name, scope.typeName)
+	}
+	if p.is(bodyOpen, "{") {
+		p.calls(bodyOpen, last, name, scope.typeName, vars)
+	}
+	p.i = last + 1
+}
+
+// parameters records the types of the parameters between the parentheses
+// at indices open and closeParen and returns the declared types of the
+// named ones.
+func (p *rsParser) parameters(open, closeParen int, function, class string) map[string]string {
+	vars := make(map[string]string)
+	for _, item := range p.list(open, closeParen) {
+		from, to := item[0], item[1]
+		for p.is(from, "#") && p.is(from+1, "[") {
+			from = p.closing(from+1) + 1
+		}
+		colon := -1
+		for j := from; j < to; j++ {
+			if p.is(j, ":") {
+				colon = j
+				break
+			}
+			if p.is(j, "(") || p.is(j, "[") || p.is(j, "{") {
+				j = p.closing(j)
+			}
+		}
+		if colon < 0 {
+			continue
+		}
+		typ := p.typeRefs(colon, to, "param", function, class)
+		name := p.ident(colon - 1)
+		if name != "" && name != "self" && typ != "" {
+			vars[name] = typ
+		}
+	}
+	return vars
+}
+
+// list returns the index ranges of the comma-separated items between the
+// brackets at indices open and closeBracket; each range ends at the comma or
+// closing bracket after the item.
+func (p *rsParser) list(open, closeBracket int) [][2]int {
+	var items [][2]int
+	start := open + 1
+	depth := 0
+	for j := open + 1; j < closeBracket; j++ {
+		switch {
+		case p.is(j, "(") || p.is(j, "[") || p.is(j, "{"):
+			j = p.closing(j)
+		case p.is(j, "<"):
+			depth++
+		case p.is(j, ">") && !p.is(j-1, "-") && depth > 0:
+			depth--
+		case p.is(j, ",") && depth == 0:
+			items = append(items, [2]int{start, j})
+			start = j + 1
+		}
+	}
+	if start < closeBracket {
+		items = append(items, [2]int{start, closeBracket})
+	}
+	return items
+}
+
+// typeRefs records the types named between the tokens at indices from and
+// to, exclusive, and returns the main one: the type a field, parameter or
+// variable with the type holds.
+func (p *rsParser) typeRefs(from, to int, role, enclosing, class string) string {
+	main := ""
+	for j := from + 1; j < to && j < len(p.toks); j++ {
+		t := p.toks[j]
+		if t.kind != rsIdent || rsPrimitives[t.text] || rsTypeKeywords[t.text] || p.is(j+1, "::") {
+			continue
+		}
+		// Associated type bindings: Iterator<Item = User>
+		if p.is(j+1, "=") {
+			continue
+		}
+		name := t.text
+		if name == "Self" {
+			if class == "" {
+				continue
+			}
+			name = class
+		}
+		p.result.TypeRefs = append(p.result.TypeRefs, TypeAnnotation{
+			Name:           name,
+			Role:           role,
+			StartLine:      t.line,
+			Enclosing:      enclosing,
+			EnclosingClass: class,
+		})
+		if main == "" && !rsTypeWrappers[name] {
+			main = name
+		}
+	}
+	return main
+}
+
+// structure parses a struct or union declaration.
+func (p *rsParser) structure(start int, public bool, attributes []string) {
+	name := p.ident(p.i + 1)
+	j := p.i + 2
+	if p.is(j, "<") {
+		j = p.angleEnd(j) + 1
+	}
+	for j < len(p.toks) && !p.is(j, "{") && !p.is(j, "(") && !p.is(j, ";") {
+		j++
+	}
+	last := j
+	switch {
+	case p.is(j, "{"):
+		last = p.closing(j)
+		p.fieldList(j, last, name)
+	case p.is(j, "("):
+		// Tuple structs: struct Meters(f64);
+		closeParen := p.closing(j)
+		p.typeRefs(j, closeParen, "field", name, "")
+		last = closeParen
+		for last < len(p.toks) && !p.is(last, ";") && !p.is(last, "}") {
+			last++
+		}
+	}
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeClass,
+		Signature:  p.tok(p.i).text + " " + name,
+		IsExported: public,
+		Decorators: attributes,
+	}, start, last)
+	p.i = last + 1
+}
+
+// fieldList records the named fields between the braces at indices open and
+// closeBrace as fields of typeName.
+func (p *rsParser) fieldList(open, closeBrace int, typeName string) {
+	for _, item := range p.list(open, closeBrace) {
+		from := item[0]
+		for p.is(from, "#") && p.is(from+1, "[") {
+			from = p.closing(from+1) + 1
+		}
+		if p.is(from, "pub") {
+			from++
+			if p.is(from, "(") {
+				from = p.closing(from) + 1
+			}
+		}
+		name := p.ident(from)
+		if name == "" || !p.is(from+1, ":") {
+			continue
+		}
+		if typ := p.typeRefs(from+1, item[1], "field", typeName, ""); typ != "" {
+			if p.fields[typeName] == nil {
+				p.fields[typeName] = make(map[string]string)
+			}
+			p.fields[typeName][name] = typ
+		}
+	}
+}
+
+// enumeration parses an enum declaration.
+func (p *rsParser) enumeration(start int, public bool, attributes []string) {
+	name := p.ident(p.i + 1)
+	j := p.i + 2
+	for j < len(p.toks) && !p.is(j, "{") && !p.is(j, ";") {
+		j++
+	}
+	last := j
+	if p.is(j, "{") {
+		last = p.closing(j)
+		p.variants(j, last, name)
+	}
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeEnum,
+		Signature:  "enum " + name,
+		IsExported: public,
+		Decorators: attributes,
+	}, start, last)
+	p.i = last + 1
+}
+
+// variants records the types held by the variants of the enum typeName,
+// declared between the braces at indices open and closeBrace.
+func (p *rsParser) variants(open, closeBrace int, typeName string) {
+	for _, item := range p.list(open, closeBrace) {
+		j := item[0]
+		for p.is(j, "#") && p.is(j+1, "[") {
+			j = p.closing(j+1) + 1
+		}
+		switch {
+		case p.is(j+1, "("):
+			p.typeRefs(j+1, p.closing(j+1), "field", typeName, "")
+		case p.is(j+1, "{"):
+			closeFields := p.closing(j + 1)
+			for _, field := range p.list(j+1, closeFields) {
+				if p.ident(field[0]) != "" && p.is(field[0]+1, ":") {
+					p.typeRefs(field[0]+1, field[1], "field", typeName, "")
+				}
+			}
+		}
+	}
+}
+
+// trait parses a trait declaration: its supertraits extend it, and its
+// associated functions are methods of the trait.
+func (p *rsParser) trait(start int, public bool, attributes []string) {
+	name := p.ident(p.i + 1)
+	j := p.i + 2
+	if p.is(j, "<") {
+		j = p.angleEnd(j) + 1
+	}
+
+	var supertraits []string
+	if p.is(j, ":") {
+		for j++; j < len(p.toks) && !p.is(j, "{") && !p.is(j, "where") && !p.is(j, ";"); j++ {
+			switch {
+			case p.is(j, "<"):
+				j = p.angleEnd(j)
+			case p.ident(j) != "" && !p.is(j+1, "::") && !p.is(j-1, "?"):
+				supertraits = append(supertraits, p.ident(j))
+			}
+		}
+	}
+	for j < len(p.toks) && !p.is(j, "{") && !p.is(j, ";") {
+		j++
+	}
+	last := j
+	if p.is(j, "{") {
+		last = p.closing(j)
+	}
+
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeInterface,
+		Signature:  "trait " + name,
+		IsExported: public,
+		Decorators: attributes,
+	}, start, last)
+	if len(supertraits) > 0 {
+		p.addHeritage(name, supertraits, nil)
+	}
+
+	if p.is(j, "{") {
+		p.i = j + 1
+		p.items(last, rsScope{typeName: name, public: public})
+	}
+	p.i = last + 1
+}
+
+// implementation parses an impl block. Its functions are methods of the
+// implementing type; impl Trait for Type records that Type implements
+// Trait.
+func (p *rsParser) implementation() {
+	j := p.i + 1
+	if p.is(j, "<") {
+		j = p.angleEnd(j) + 1
+	}
+
+	first := j
+	forIndex := -1
+	for ; j < len(p.toks) && !p.is(j, "{") && !p.is(j, "where") && !p.is(j, ";"); j++ {
+		switch {
+		case p.is(j, "<"):
+			j = p.angleEnd(j)
+		case p.is(j, "(") || p.is(j, "["):
+			j = p.closing(j)
+		case p.is(j, "for") && forIndex < 0:
+			forIndex = j
+		}
+	}
+	typeName := p.mainType(first, j)
+	trait := ""
+	if forIndex >= 0 {
+		trait = p.mainType(first, forIndex)
+		typeName = p.mainType(forIndex+1, j)
+	}
+	for j < len(p.toks) && !p.is(j, "{") && !p.is(j, ";") {
+		j++
+	}
+	if !p.is(j, "{") {
+		p.i = j + 1
+		return
+	}
+	closeBrace := p.closing(j)
+
+	if trait != "" && typeName != "" && !p.is(first, "!") {
+		p.addHeritage(typeName, nil, []string{trait})
+	}
+	p.i = j + 1
+	p.items(closeBrace, rsScope{typeName: typeName, public: trait != ""})
+	p.i = closeBrace + 1
+}
+
+// mainType returns the name of the type between the tokens at indices from
+// and to, exclusive: the last path segment outside of generics, so that
+// &'a mut crate::models::User<T> is User.
+func (p *rsParser) mainType(from, to int) string {
+	name := ""
+	for j := from; j < to; j++ {
+		switch {
+		case p.is(j, "<"):
+			j = p.angleEnd(j)
+		case p.is(j, "(") || p.is(j, "["):
+			j = p.closing(j)
+		case p.ident(j) != "" && !rsTypeKeywords[p.ident(j)]:
+			name = p.ident(j)
+		}
+	}
+	return name
+}
+
+// typeAlias parses a type alias declaration.
+func (p *rsParser) typeAlias(start int, public bool) {
+	name := p.ident(p.i + 1)
+	j := p.i + 2
+	for j < len(p.toks) && !p.is(j, "=") && !p.is(j, ";") {
+		j++
+	}
+	last := j
+	for last < len(p.toks) && !p.is(last, ";") && !p.is(last, "}") {
+		if p.is(last, "(") || p.is(last, "[") || p.is(last, "{") {
+			last = p.closing(last)
+		}
+		last++
+	}
+	value := ""
+	if p.is(j, "=") {
+		p.typeRefs(j, last, "variable", name, "")
+		value = " = " + p.text(j, last)
+	}
+	p.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeTypeAlias,
+		Signature:  "type " + name + p.text(p.i+1, j) + value,
+		IsExported: public,
+	}, start, last)
+	p.i = last + 1
+}
+
+// module parses a module declaration. mod name; is recorded as an import of
+// the module's file, and the items of an inline module are parsed in place.
+func (p *rsParser) module(scope rsScope) {
+	name := p.ident(p.i + 1)
+	line := p.tok(p.i).line
+	j := p.i + 2
+	switch {
+	case p.is(j, ";"):
+		p.result.Imports = append(p.result.Imports, ImportStatement{
+			ModulePath: "self::" + name,
+			StartLine:  line,
+		})
+		p.i = j + 1
+	case p.is(j, "{"):
+		closeBrace := p.closing(j)
+		p.i = j + 1
+		p.items(closeBrace, rsScope{})
+		p.i = closeBrace + 1
+	default:
+		p.skipItem()
+	}
+}
+
+// use parses a use declaration, recording one import per module path:
+// use crate::models::{User, Role}; imports User and Role from
+// crate::models.
+func (p *rsParser) use() {
+	line := p.tok(p.i).line
+	end := p.i + 1
+	for end < len(p.toks) && !p.is(end, ";") {
+		if p.is(end, "{") {
+			end = p.closing(end)
+		}
+		end++
+	}
+	p.useTree(p.i+1, end, nil, line)
+	p.i = end + 1
+}
+
+// useTree records the imports of the use tree between the tokens at indices
+// from and to, exclusive, below the path prefix.
+func (p *rsParser) useTree(from, to int, prefix []string, line int) {
+	segments := append([]string(nil), prefix...)
+	j := from
+	if p.is(j, "::") {
+		j++
+	}
+	for j < to {
+		switch {
+		case p.is(j, "{"):
+			closeBrace := p.closing(j)
+			var symbols []string
+			for _, item := range p.list(j, closeBrace) {
+				// Plain names are grouped into one import of the prefix
+				if name := p.ident(item[0]); name != "" && item[1] == item[0]+1 && name != "self" {
+					symbols = append(symbols, name)
+					continue
+				}
+				if p.is(item[0], "*") && item[1] == item[0]+1 {
+					symbols = append(symbols, "*")
+					continue
+				}
+				p.useTree(item[0], item[1], segments, line)
+			}
+			if len(symbols) > 0 {
+				p.addImport(segments, symbols, "", line)
+			}
+			return
+		case p.is(j, "*"):
+			p.addImport(segments, []string{"*"}, "", line)
+			return
+		case p.ident(j) != "":
+			segments = append(segments, p.ident(j))
+			j++
+			if p.is(j, "::") {
+				j++
+				continue
+			}
+			alias := ""
+			if p.is(j, "as") {
+				alias = p.ident(j + 1)
+			}
+			name := segments[len(segments)-1]
+			if name == "self" {
+				// use a::b::{self} imports the module b itself
+				segments = segments[:len(segments)-1]
+				name = segments[len(segments)-1]
+			}
+			if len(segments) == 1 {
+				p.addImport(segments, nil, alias, line)
+			} else {
+				p.addImport(segments[:len(segments)-1], []string{name}, alias, line)
+			}
+			return
+		default:
+			return
+		}
+	}
+}
+
+// addImport records an import of symbols from the module path segments.
+func (p *rsParser) addImport(segments, symbols []string, alias string, line int) {
+	if len(segments) == 0 {
+		return
+	}
+	p.result.Imports = append(p.result.Imports, ImportStatement{
+		ModulePath: strings.Join(segments, "::"),
+		Symbols:    symbols,
+		Alias:      alias,
+		StartLine:  line,
+	})
+}
+
+// externCrate parses extern crate name [as alias];.
+func (p *rsParser) externCrate() {
+	name := p.ident(p.i + 2)
+	alias := ""
+	if p.is(p.i+3, "as") {
+		alias = p.ident(p.i + 4)
+	}
+	if name != "" && name != "self" {
+		p.result.Imports = append(p.result.Imports, ImportStatement{
+			ModulePath: name,
+			Alias:      alias,
+			StartLine:  p.tok(p.i).line,
+		})
+	}
+	p.skipItem()
+}
+
+// calls records the calls between the tokens at indices from and to,
+// exclusive, as made by the function enclosing (a method of class). vars
+// holds the declared types of the parameters and is extended by typed let
+// bindings.
+func (p *rsParser) calls(from, to int, enclosing, class string, vars map[string]string) {
+	if vars == nil {
+		vars = make(map[string]string)
+	}
+	for j := from + 1; j < to && j < len(p.toks); j++ {
+		if p.is(j, "let") {
+			p.binding(j, to, vars, class)
+			continue
+		}
+		name := p.ident(j)
+		if name == "" || p.is(j-1, "fn") || rsNotCalled[name] {
+			continue
+		}
+
+		open := j + 1
+		// Turbofish: collect::<Vec<_>>()
+		if p.is(open, "::") && p.is(open+1, "<") {
+			open = p.angleEnd(open+1) + 1
+		}
+		switch {
+		case p.is(open, "("):
+		case p.is(open, "{") && p.structLiteral(j):
+		default:
+			continue
+		}
+
+		if name == "Self" {
+			if name = class; name == "" {
+				continue
+			}
+		}
+		call := CallSite{
+			Name:           name,
+			StartLine:      p.tok(j).line,
+			EndLine:        p.tok(p.closing(open)).line,
+			Enclosing:      enclosing,
+			EnclosingClass: class,
+		}
+		switch {
+		case p.is(j-1, "."):
+			call.Receiver = p.receiver(j-2, vars, class)
+			if p.is(j-3, ".") && p.is(j-4, "self") && !p.is(j-5, ".") {
+				p.fieldCalls[len(p.result.Calls)] = call.Receiver
+			}
+		case p.is(j-1, "::"):
+			// Associated functions: User::new(), Self::default()
+			if owner := p.ident(j - 2); owner == "Self" {
+				call.Receiver = class
+			} else if owner != "" && isUpper(owner[0]) {
+				call.Receiver = owner
+			}
+		}
+		p.result.Calls = append(p.result.Calls, call)
+	}
+}
+
+// structLiteral reports whether the type name at index j, followed by a
+// brace, starts a struct expression (User { id, name }) rather than a block.
+func (p *rsParser) structLiteral(j int) bool {
+	name := p.ident(j)
+	if name == "" || !isUpper(name[0]) || strings.ToUpper(name) == name {
+		return false
+	}
+	start := j
+	for p.is(start-1, "::") && p.ident(start-2) != "" {
+		start -= 2
+	}
+	prev := p.tok(start - 1)
+	if prev.kind == rsIdent {
+		return prev.text == "return"
+	}
+	switch prev.text {
+	case "=", "(", ",", "[", "=>", ":", "{", ";", "!", "&", "|":
+		return true
+	}
+	return false
+}
+
+// binding records the type of the variable bound by the let at index j:
+// let repo: Repository = ..., let user = User::new(...), let user = User { .. }.
+func (p *rsParser) binding(j, to int, vars map[string]string, class string) {
+	k := j + 1
+	if p.is(k, "mut") {
+		k++
+	}
+	name := p.ident(k)
+	if name == "" {
+		return
+	}
+	k++
+	if p.is(k, ":") {
+		end := k + 1
+		for end < to && !p.is(end, "=") && !p.is(end, ";") {
+			if p.is(end, "<") {
+				end = p.angleEnd(end)
+			}
+			end++
+		}
+		if typ := p.mainType(k+1, end); typ != "" && !rsPrimitives[typ] {
+			vars[name] = typ
+		}
+		return
+	}
+	if !p.is(k, "=") {
+		return
+	}
+	// The type a constructor path or struct expression names
+	typ := ""
+	for v := k + 1; p.ident(v) != ""; v += 2 {
+		segment := p.ident(v)
+		if isUpper(segment[0]) {
+			typ = segment
+		}
+		if !p.is(v+1, "::") {
+			if p.is(v+1, "{") && typ == segment {
+				break
+			}
+			if p.is(v+1, "(") && typ != segment {
+				break
+			}
+			typ = ""
+			break
+		}
+	}
+	if typ == "Self" {
+		typ = class
+	}
+	if typ != "" {
+		vars[name] = typ
+	}
+}
+
+// receiver returns the type of the receiver expression ending at index j:
+// the impl type for self, the declared type of a variable, or the last name
+// of the expression.
+func (p *rsParser) receiver(j int, vars map[string]string, class string) string {
+	name := p.ident(j)
+	if name == "" {
+		return ""
+	}
+	if p.is(j-1, ".") {
+		return name
+	}
+	if name == "self" {
+		return class
+	}
+	if typ := vars[name]; typ != "" {
+		return typ
+	}
+	return name
+}
+
+// finish resolves the receivers of calls on fields of self to the declared
+// types of the fields.
+func (p *rsParser) finish() {
+	for index, field := range p.fieldCalls {
+		call := &p.result.Calls[index]
+		if typ := p.fields[call.EnclosingClass][field]; typ != "" {
+			call.Receiver = typ
+		}
+	}
+}
+
+func isUpper(c byte) bool {
+	return c >= 'A' && c <= 'Z'
+}
@@ -0,0 +1,232 @@
+package parsers
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// rsTokenKind is the kind of a Rust token.
+type rsTokenKind int
+
+const (
+	rsEOF rsTokenKind = iota
+	rsIdent
+	rsPunct
+	rsString
+	rsChar
+	rsNumber
+	rsLifetime
+)
+
+// rsToken is a token of Rust source.
+type rsToken struct {
+	kind rsTokenKind
+
+	// text is the token text; raw identifiers (r#type) lose their prefix
+	text string
+
+	// pos and end are the byte offsets of the token in the source
+	pos, end int
+
+	// line and endLine are the 1-based lines of pos and end, which differ
+	// for multi-line strings
+	line, endLine int
+}
+
+// rsPunctuators are the multi-character punctuators the parser needs,
+// longest first. Angle brackets are always single tokens, so that the '>'
+// closing nested generics (Vec<Vec<u8>>) is never part of a shift.
+var rsPunctuators = []string{"..=", "...", "::", "->", "=>", "..", "==", "!=", "&&", "||"}
+
+// rsLexer splits Rust source into tokens, skipping whitespace and comments
+// (block comments nest). Strings cover escapes, byte and C strings and raw
+// strings with any number of '#'; a quote starts a character literal or a
+// lifetime depending on what follows it.
+type rsLexer struct {
+	src  string
+	pos  int
+	line int
+	toks []rsToken
+}
+
+// lexRust tokenizes source.
+func lexRust(source string) []rsToken {
+	l := &rsLexer{src: source, line: 1}
+	for l.pos < len(l.src) {
+		l.token()
+	}
+	return l.toks
+}
+
+// emit appends a token spanning src[start:end] and moves past it.
+func (l *rsLexer) emit(kind rsTokenKind, text string, start, end int) {
+	lines := strings.Count(l.src[start:end], "\n")
+	l.toks = append(l.toks, rsToken{kind: kind, text: text, pos: start, end: end, line: l.line, endLine: l.line + lines})
+	l.line += lines
+	l.pos = end
+}
+
+// token lexes the token at the current position.
+func (l *rsLexer) token() {
+	c := l.src[l.pos]
+	rest := l.src[l.pos:]
+	switch {
+	case c == '\n':
+		l.line++
+		l.pos++
+	case c == ' ' || c == '\t' || c == '\r':
+		l.pos++
+	case strings.HasPrefix(rest, "//"):
+		if i := strings.IndexByte(rest, '\n'); i >= 0 {
+			l.pos += i
+		} else {
+			l.pos = len(l.src)
+		}
+	case strings.HasPrefix(rest, "/*"):
+		l.blockComment()
+	case c == '"':
+		l.string(l.pos, l.pos)
+	case c == '\'':
+		l.quote(l.pos, l.pos)
+	case isJSDigit(c):
+		end := l.pos + 1
+		for end < len(l.src) {
+			d := l.src[end]
+			if isPyNamePart(d) || (d == '+' || d == '-') && (l.src[end-1] == 'e' || l.src[end-1] == 'E') && !strings.HasPrefix(l.src[l.pos:], "0x") {
+				end++
+				continue
+			}
+			// 1.5 is a number, 1..5 a range and t.0.1 a field access
+			if d == '.' && end+1 < len(l.src) && isJSDigit(l.src[end+1]) && !strings.Contains(l.src[l.pos:end], ".") {
+				end++
+				continue
+			}
+			break
+		}
+		l.emit(rsNumber, l.src[l.pos:end], l.pos, end)
+	case isPyNameStart(c):
+		end := l.pos + 1
+		for end < len(l.src) && isPyNamePart(l.src[end]) {
+			end++
+		}
+		word := l.src[l.pos:end]
+		if end < len(l.src) {
+			switch next := l.src[end]; {
+			case next == '"' && (word == "b" || word == "c" || word == "r" || word == "br" || word == "cr"):
+				l.string(l.pos, end)
+				return
+			case next == '#' && (word == "r" || word == "br" || word == "cr") && end+1 < len(l.src) && (l.src[end+1] == '"' || l.src[end+1] == '#'):
+				l.string(l.pos, end)
+				return
+			case next == '#' && word == "r" && end+1 < len(l.src) && isPyNameStart(l.src[end+1]):
+				// Raw identifiers: r#type
+				start := end + 1
+				end = start + 1
+				for end < len(l.src) && isPyNamePart(l.src[end]) {
+					end++
+				}
+				l.emit(rsIdent, l.src[start:end], l.pos, end)
+				return
+			case next == '\'' && word == "b":
+				l.quote(l.pos, end)
+				return
+			}
+		}
+		l.emit(rsIdent, word, l.pos, end)
+	default:
+		for _, punct := range rsPunctuators {
+			if strings.HasPrefix(rest, punct) {
+				l.emit(rsPunct, punct, l.pos, l.pos+len(punct))
+				return
+			}
+		}
+		l.emit(rsPunct, rest[:1], l.pos, l.pos+1)
+	}
+}
+
+// blockComment skips a block comment, which may contain nested ones.
+func (l *rsLexer) blockComment() {
+	depth := 0
+	for l.pos < len(l.src) {
+		switch {
+		case strings.HasPrefix(l.src[l.pos:], "/*"):
+			depth++
+			l.pos += 2
+		case strings.HasPrefix(l.src[l.pos:], "*/"):
+			depth--
+			l.pos += 2
+			if depth == 0 {
+				return
+			}
+		default:
+			if l.src[l.pos] == '\n' {
+				l.line++
+			}
+			l.pos++
+		}
+	}
+}
+
+// string lexes a string literal whose prefix starts at start and whose
+// quote, or the '#' of a raw string, is at index open.
+func (l *rsLexer) string(start, open int) {
+	raw := strings.ContainsRune(l.src[start:open], 'r')
+	hashes := 0
+	for open+hashes < len(l.src) && l.src[open+hashes] == '#' {
+		hashes++
+	}
+	bodyStart := open + hashes + 1
+	closing := "\"" + strings.Repeat("#", hashes)
+
+	i := bodyStart
+	for i < len(l.src) {
+		if !raw && l.src[i] == '\\' {
+			i += 2
+			continue
+		}
+		if strings.HasPrefix(l.src[i:], closing) {
+			break
+		}
+		i++
+	}
+	bodyEnd := min(i, len(l.src))
+	end := min(bodyEnd+len(closing), len(l.src))
+	l.emit(rsString, l.src[bodyStart:bodyEnd], start, end)
+}
+
+// quote lexes the character literal or lifetime whose quote is at index
+// open; start is the start of a byte prefix.
+func (l *rsLexer) quote(start, open int) {
+	i := open + 1
+	if i >= len(l.src) {
+		l.emit(rsPunct, "'", open, i)
+		return
+	}
+	if l.src[i] == '\\' {
+		// Escaped characters: '\n', '\'', '\u{1F600}'
+		if i+1 >= len(l.src) {
+			// A literal cut off by the end of the file: '\
+			l.emit(rsChar, l.src[i:], start, len(l.src))
+			return
+		}
+		end := strings.IndexByte(l.src[i+2:], '\'')
+		if end < 0 {
+			end = len(l.src) - i - 2
+		}
+		end += i + 3
+		l.emit(rsChar, l.src[open+1:min(end-1, len(l.src))], start, min(end, len(l.src)))
+		return
+	}
+	_, size := utf8.DecodeRuneInString(l.src[i:])
+	if i+size < len(l.src) && l.src[i+size] == '\'' {
+		l.emit(rsChar, l.src[i:i+size], start, i+size+1)
+		return
+	}
+
+	// Lifetimes and labels: 'a, 'static, 'outer:
+	end := i
+	for end < len(l.src) && isPyNamePart(l.src[end]) {
+		end++
+	}
+	l.emit(rsLifetime, l.src[open:end], start, end)
+}
@@ -0,0 +1,284 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestRustParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("ParseFunction", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`/// Greets someone.
+pub fn greet(name: &str) -> String {
+    format!("Hello, {}", name)
+}
+
+fn helper<'a, T: Display>(items: &'a [T]) -> Option<&'a T> {
+    items.first()
+}
+`)
+		result, err := NewRustParser().Parse("lib.rs", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		require.Len(t, symbols, 2)
+
+		greet := symbols["greet"]
+		assert.Equal(t, graph.NodeFunction, greet.Kind)
+		assert.True(t, greet.IsExported)
+		assert.Equal(t, "fn greet(name: &str) -> String", greet.Signature)
+		assert.Equal(t, 2, greet.StartLine)
+		assert.Equal(t, 4, greet.EndLine)
+
+		helper := symbols["helper"]
+		assert.False(t, helper.IsExported)
+		assert.Contains(t, helper.Signature, "items: &'a [T]")
+	})
+
+	t.Run("ParseImplMethods", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`pub struct Counter {
+    count: u64,
+    store: Store,
+}
+
+impl Counter {
+    pub fn new() -> Self {
+        Self { count: 0, store: Store::open() }
+    }
+
+    fn increment(&mut self) {
+        self.count += 1;
+        self.store.save(self.count);
+        self.log();
+    }
+
+    fn log(&self) {}
+}
+`)
+		result, err := NewRustParser().Parse("counter.rs", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Equal(t, graph.NodeClass, symbols["Counter"].Kind)
+		assert.True(t, symbols["Counter"].IsExported)
+
+		require.Contains(t, symbols, "Counter.new")
+		assert.Equal(t, graph.NodeMethod, symbols["Counter.new"].Kind)
+		assert.Equal(t, "Counter", symbols["Counter.new"].ClassName)
+		assert.True(t, symbols["Counter.new"].IsExported)
+		assert.False(t, symbols["Counter.increment"].IsExported)
+
+		open := findCall(result.Calls, "open", 8)
+		require.NotNil(t, open)
+		assert.Equal(t, "Store", open.Receiver)
+		assert.Equal(t, "new", open.Enclosing)
+
+		save := findCall(result.Calls, "save", 13)
+		require.NotNil(t, save)
+		assert.Equal(t, "Store", save.Receiver, "field receivers resolve to the field type")
+		assert.Equal(t, "increment", save.Enclosing)
+
+		log := findCall(result.Calls, "log", 14)
+		require.NotNil(t, log)
+		assert.Equal(t, "Counter", log.Receiver)
+
+		var fields []string
+		for _, ref := range result.TypeRefs {
+			if ref.Role == "field" {
+				fields = append(fields, ref.Name)
+			}
+		}
+		assert.Equal(t, []string{"Store"}, fields)
+	})
+
+	t.Run("ParseTraits", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`pub trait Shape: Debug + Clone {
+    fn area(&self) -> f64;
+
+    fn describe(&self) -> String {
+        format!("{:?}", self)
+    }
+}
+
+#[derive(Debug, Clone)]
+struct Circle {
+    radius: f64,
+}
+
+impl Shape for Circle {
+    fn area(&self) -> f64 {
+        std::f64::consts::PI * self.radius * self.radius
+    }
+}
+
+impl<T: Shape> Shape for Box<T> {
+    fn area(&self) -> f64 {
+        (**self).area()
+    }
+}
+
+impl !Send for Circle {}
+`)
+		result, err := NewRustParser().Parse("shape.rs", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Equal(t, graph.NodeInterface, symbols["Shape"].Kind)
+		assert.Equal(t, graph.NodeMethod, symbols["Shape.area"].Kind, "trait methods belong to the trait")
+		assert.Equal(t, graph.NodeMethod, symbols["Shape.describe"].Kind)
+		assert.True(t, symbols["Shape.area"].IsExported, "trait methods share the trait's visibility")
+		assert.Equal(t, graph.NodeMethod, symbols["Circle.area"].Kind)
+		assert.True(t, symbols["Circle.area"].IsExported, "trait impl methods are public")
+		assert.Equal(t, []string{"derive"}, symbols["Circle"].Decorators)
+
+		heritage := make(map[string]ClassHeritage)
+		for _, h := range result.Heritage {
+			heritage[h.ClassName] = h
+		}
+		require.Len(t, heritage, 3, "negative impls are not heritage")
+		assert.Equal(t, []string{"Debug", "Clone"}, heritage["Shape"].Extends)
+		assert.Equal(t, []string{"Shape"}, heritage["Circle"].Implements)
+		assert.Equal(t, []string{"Shape"}, heritage["Box"].Implements)
+	})
+
+	t.Run("ParseEnums", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`pub enum Message {
+    Quit,
+    Move { x: i32, y: i32 },
+    Write(Payload),
+}
+
+pub(crate) type Result<T> = std::result::Result<T, Error>;
+`)
+		result, err := NewRustParser().Parse("message.rs", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		require.Len(t, symbols, 2)
+		assert.Equal(t, graph.NodeEnum, symbols["Message"].Kind)
+		assert.Equal(t, "enum Message", symbols["Message"].Signature)
+		assert.Equal(t, graph.NodeTypeAlias, symbols["Result"].Kind)
+		assert.False(t, symbols["Result"].IsExported, "pub(crate) is not exported")
+
+		var refs []string
+		for _, ref := range result.TypeRefs {
+			refs = append(refs, ref.Name)
+		}
+		assert.Contains(t, refs, "Payload")
+		assert.NotContains(t, refs, "Quit", "variants are not types")
+	})
+
+	t.Run("ParseImports", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`use std::collections::HashMap;
+use crate::config::{self, Config, load as load_config};
+use super::*;
+use serde;
+extern crate alloc;
+mod handlers;
+`)
+		result, err := NewRustParser().Parse("main.rs", content)
+		require.NoError(t, err)
+
+		imports := importsByModule(result)
+		assert.Equal(t, []string{"HashMap"}, imports["std::collections"])
+		assert.Equal(t, []string{"config"}, imports["crate"], "self imports the module under its parent")
+		assert.Equal(t, []string{"*"}, imports["super"])
+		assert.Contains(t, imports, "serde")
+		assert.Contains(t, imports, "alloc")
+		assert.Contains(t, imports, "self::handlers")
+
+		var config []ImportStatement
+		for _, imp := range result.Imports {
+			if imp.ModulePath == "crate::config" {
+				config = append(config, imp)
+			}
+		}
+		require.Len(t, config, 2, "aliased items are separate imports")
+		assert.Equal(t, []string{"load"}, config[0].Symbols)
+		assert.Equal(t, "load_config", config[0].Alias)
+		assert.Equal(t, []string{"Config"}, config[1].Symbols)
+	})
+
+	t.Run("ParseModules", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`pub mod api {
+    pub fn handler() {}
+}
+
+#[cfg(test)]
+mod tests {
+    use super::*;
+
+    #[test]
+    fn it_works() {
+        api::handler();
+    }
+}
+`)
+		result, err := NewRustParser().Parse("lib.rs", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Contains(t, symbols, "handler")
+		assert.Equal(t, []string{"test"}, symbols["it_works"].Decorators)
+
+		call := findCall(result.Calls, "handler", 11)
+		require.NotNil(t, call)
+		assert.Equal(t, "it_works", call.Enclosing)
+	})
+
+	t.Run("ParseTruncatedLiteral", func(t *testing.T) {
+		t.Parallel()
+
+		for _, source := range []string{"let c = '\\", "let c = '", "let c = '\\n"} {
+			_, err := NewRustParser().Parse("main.rs", []byte(source))
+			require.NoError(t, err, source)
+		}
+
+		result, err := NewRustParser().Parse("main.rs", []byte("fn main() {}\nlet c = '\\"))
+		require.NoError(t, err)
+		require.Len(t, result.Symbols, 1)
+		assert.Equal(t, "main", result.Symbols[0].Name)
+	})
+
+	t.Run("ParseEmptyFile", func(t *testing.T) {
+		t.Parallel()
+
+		result, err := NewRustParser().Parse("empty.rs", []byte(""))
+		require.NoError(t, err)
+		assert.Empty(t, result.Symbols)
+		assert.Empty(t, result.Calls)
+	})
+}
+
+func TestRustParser_Language(t *testing.T) {
+	t.Parallel()
+
+	assert.Equal(t, "rust", NewRustParser().Language())
+}
+
+func TestRustParser_FileDetection(t *testing.T) {
+	t.Parallel()
+
+	parser := NewRustParser()
+	assert.True(t, parser.SupportsFile("lib.rs"))
+	assert.True(t, parser.SupportsFile("src/main.rs"))
+	assert.False(t, parser.SupportsFile("main.go"))
+	assert.False(t, parser.SupportsFile("Cargo.toml"))
+}
@@ -0,0 +1,641 @@
+package parsers
+
+import (
+	"path/filepath"
+	"strings"
+)
+
+// SQLParser parses SQL files: schemas, migrations and query files.
+//
+// CREATE TABLE and CREATE VIEW statements define tables and their columns,
+// and ALTER TABLE ... ADD COLUMN statements add columns to tables created
+// elsewhere, usually by an earlier migration. The other statements of the
+// file (seed data, sqlc query files) are table references of the file.
+type SQLParser struct{}
+
+// NewSQLParser creates a new SQL parser.
+func NewSQLParser() *SQLParser {
+	return &SQLParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *SQLParser) Language() string {
+	return "sql"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *SQLParser) SupportsFile(filename string) bool {
+	return strings.ToLower(filepath.Ext(filename)) == ".sql"
+}
+
+// Parse parses a SQL file and extracts its tables and table references.
+func (p *SQLParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	tables, refs := analyzeSQL(string(content), 1)
+	return &ParseResult{
+		Symbols:   []ParsedSymbol{},
+		Imports:   []ImportStatement{},
+		Calls:     []CallSite{},
+		TypeRefs:  []TypeAnnotation{},
+		Heritage:  []ClassHeritage{},
+		Tables:    tables,
+		TableRefs: refs,
+	}, nil
+}
+
+// sqlStatementKeywords are the keywords a string must start with to be
+// taken for SQL when it appears as a literal in code.
+var sqlStatementKeywords = map[string]bool{
+	"SELECT": true, "INSERT": true, "UPDATE": true, "DELETE": true,
+	"WITH": true, "MERGE": true, "REPLACE": true, "TRUNCATE": true,
+	"CREATE": true, "ALTER": true,
+}
+
+// sqlEscapes replaces the whitespace escapes left in the raw text of
+// string literals.
+var sqlEscapes = strings.NewReplacer(`\n`, " ", `\t`, " ", `\r`, " ")
+
+// analyzeSQLLiteral analyzes a string literal of code that starts at line,
+// if it is SQL. Strings that merely mention a keyword ("select an item")
+// yield nothing, since they access no table.
+func analyzeSQLLiteral(text string, line int) ([]TableDef, []TableRef) {
+	text = sqlEscapes.Replace(text)
+	l := &sqlLexer{src: text, line: line}
+	l.run()
+	if len(l.toks) < 2 || l.toks[0].kind != sqlIdent || !sqlStatementKeywords[strings.ToUpper(l.toks[0].text)] {
+		return nil, nil
+	}
+	a := &sqlAnalyzer{src: text, toks: l.toks}
+	a.run()
+	return a.tables, a.refs
+}
+
+// analyzeSQL analyzes the statements of SQL source that starts at line.
+func analyzeSQL(src string, line int) ([]TableDef, []TableRef) {
+	l := &sqlLexer{src: src, line: line}
+	l.run()
+	a := &sqlAnalyzer{src: src, toks: l.toks}
+	a.run()
+	return a.tables, a.refs
+}
+
+// sqlTokenKind is the kind of a SQL token.
+type sqlTokenKind int
+
+const (
+	sqlIdent sqlTokenKind = iota
+	sqlQuoted
+	sqlString
+	sqlNumber
+	sqlPunct
+)
+
+// sqlToken is a token of SQL source.
+type sqlToken struct {
+	kind sqlTokenKind
+
+	// text is the token text; for quoted identifiers it is the unquoted
+	// name
+	text string
+
+	// pos and end are the byte offsets of the token in the source
+	pos, end int
+
+	// line is the line of pos
+	line int
+}
+
+// is reports whether the token is the given keyword or punctuator.
+func (t sqlToken) is(text string) bool {
+	return (t.kind == sqlIdent || t.kind == sqlPunct) && strings.EqualFold(t.text, text)
+}
+
+// sqlLexer splits SQL source into tokens, skipping comments.
+type sqlLexer struct {
+	src  string
+	pos  int
+	line int
+	toks []sqlToken
+}
+
+func (l *sqlLexer) run() {
+	for l.pos < len(l.src) {
+		c := l.src[l.pos]
+		switch {
+		case c == '\n':
+			l.line++
+			l.pos++
+		case c == ' ' || c == '\t' || c == '\r' || c == '\f':
+			l.pos++
+		case c == '-' && l.peek(1) == '-':
+			for l.pos < len(l.src) && l.src[l.pos] != '\n' {
+				l.pos++
+			}
+		case c == '/' && l.peek(1) == '*':
+			end := strings.Index(l.src[l.pos+2:], "*/")
+			if end < 0 {
+				l.skip(len(l.src))
+			} else {
+				l.skip(l.pos + 2 + end + 2)
+			}
+		case c == '\'':
+			l.quoted(sqlString, '\'')
+		case c == '"' || c == '`':
+			l.quoted(sqlQuoted, c)
+		case c == '[' && isSQLIdentStart(l.peek(1)):
+			end := strings.IndexByte(l.src[l.pos:], ']')
+			if end < 0 || strings.ContainsAny(l.src[l.pos:l.pos+end], " \n,") {
+				l.emit(sqlPunct, l.pos, l.pos+1)
+				l.pos++
+				break
+			}
+			l.emit(sqlQuoted, l.pos, l.pos+end+1)
+			l.toks[len(l.toks)-1].text = l.src[l.pos+1 : l.pos+end]
+			l.pos += end + 1
+		case c == '$' && (isSQLIdentStart(l.peek(1)) || l.peek(1) == '$'):
+			l.dollarQuoted()
+		case isSQLIdentStart(c):
+			start := l.pos
+			for l.pos < len(l.src) && (isSQLIdentStart(l.src[l.pos]) || isDigit(l.src[l.pos]) || l.src[l.pos] == '$') {
+				l.pos++
+			}
+			l.emit(sqlIdent, start, l.pos)
+		case isDigit(c):
+			start := l.pos
+			for l.pos < len(l.src) && (isDigit(l.src[l.pos]) || l.src[l.pos] == '.') {
+				l.pos++
+			}
+			l.emit(sqlNumber, start, l.pos)
+		default:
+			l.emit(sqlPunct, l.pos, l.pos+1)
+			l.pos++
+		}
+	}
+}
+
+func (l *sqlLexer) peek(offset int) byte {
+	if l.pos+offset < len(l.src) {
+		return l.src[l.pos+offset]
+	}
+	return 0
+}
+
+// skip advances to end, counting lines.
+func (l *sqlLexer) skip(end int) {
+	l.line += strings.Count(l.src[l.pos:end], "\n")
+	l.pos = end
+}
+
+func (l *sqlLexer) emit(kind sqlTokenKind, start, end int) {
+	l.toks = append(l.toks, sqlToken{kind: kind, text: l.src[start:end], pos: start, end: end, line: l.line})
+}
+
+// quoted emits a string or quoted identifier; a doubled quote escapes it.
+func (l *sqlLexer) quoted(kind sqlTokenKind, quote byte) {
+	start := l.pos
+	end := start + 1
+	for end < len(l.src) {
+		if l.src[end] == quote {
+			if end+1 < len(l.src) && l.src[end+1] == quote {
+				end += 2
+				continue
+			}
+			break
+		}
+		end++
+	}
+	// An unterminated string runs to the end of the input
+	next := min(end+1, len(l.src))
+	l.emit(kind, start, next)
+	l.toks[len(l.toks)-1].text = strings.ReplaceAll(l.src[start+1:min(end, len(l.src))], string([]byte{quote, quote}), string(quote))
+	l.skip(next)
+}
+
+// dollarQuoted emits a PostgreSQL dollar-quoted string ($$...$$ or
+// $tag$...$tag$), such as the body of a function.
+func (l *sqlLexer) dollarQuoted() {
+	tagEnd := strings.IndexByte(l.src[l.pos+1:], '$')
+	if tagEnd < 0 {
+		l.emit(sqlPunct, l.pos, l.pos+1)
+		l.pos++
+		return
+	}
+	tag := l.src[l.pos : l.pos+1+tagEnd+1]
+	if strings.ContainsAny(tag, " \n") {
+		l.emit(sqlPunct, l.pos, l.pos+1)
+		l.pos++
+		return
+	}
+	start := l.pos
+	end := len(l.src)
+	if i := strings.Index(l.src[start+len(tag):], tag); i >= 0 {
+		end = start + len(tag) + i + len(tag)
+	}
+	l.emit(sqlString, start, end)
+	l.skip(end)
+}
+
+func isSQLIdentStart(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c == '_' || c >= 0x80
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// sqlReserved are the keywords that end a table list and cannot name a
+// table without quotes.
+var sqlReserved = map[string]bool{
+	"SELECT": true, "FROM": true, "WHERE": true, "SET": true, "VALUES": true,
+	"ON": true, "USING": true, "AS": true, "JOIN": true, "INNER": true,
+	"LEFT": true, "RIGHT": true, "FULL": true, "OUTER": true, "CROSS": true,
+	"NATURAL": true, "GROUP": true, "ORDER": true, "HAVING": true,
+	"LIMIT": true, "OFFSET": true, "UNION": true, "INTERSECT": true,
+	"EXCEPT": true, "RETURNING": true, "WINDOW": true, "FOR": true,
+	"INTO": true, "DEFAULT": true, "WITH": true, "AND": true, "OR": true,
+	"NOT": true, "NULL": true, "IS": true, "IN": true, "EXISTS": true,
+	"CASE": true, "WHEN": true, "THEN": true, "ELSE": true, "END": true,
+	"FETCH": true, "STRAIGHT_JOIN": true,
+}
+
+// sqlTableModifiers may precede a table name.
+var sqlTableModifiers = map[string]bool{
+	"ONLY": true, "LATERAL": true, "TABLE": true, "IGNORE": true,
+	"LOW_PRIORITY": true, "DELAYED": true, "HIGH_PRIORITY": true,
+	"QUICK": true,
+}
+
+// sqlFromFunctions are the functions whose arguments use FROM, as in
+// EXTRACT(YEAR FROM created_at).
+var sqlFromFunctions = map[string]bool{
+	"EXTRACT": true, "SUBSTRING": true, "TRIM": true, "POSITION": true,
+	"OVERLAY": true,
+}
+
+// sqlColumnConstraints end the type of a column definition.
+var sqlColumnConstraints = map[string]bool{
+	"NOT": true, "NULL": true, "DEFAULT": true, "PRIMARY": true,
+	"REFERENCES": true, "UNIQUE": true, "CHECK": true, "CONSTRAINT": true,
+	"GENERATED": true, "COLLATE": true, "AUTO_INCREMENT": true,
+	"AUTOINCREMENT": true, "IDENTITY": true, "COMMENT": true, "ON": true,
+}
+
+// sqlTableConstraints start the table constraints of a table definition.
+var sqlTableConstraints = map[string]bool{
+	"CONSTRAINT": true, "PRIMARY": true, "FOREIGN": true, "UNIQUE": true,
+	"CHECK": true, "KEY": true, "INDEX": true, "EXCLUDE": true, "LIKE": true,
+	"FULLTEXT": true, "SPATIAL": true, "PERIOD": true,
+}
+
+// sqlAnalyzer extracts the tables and table references of SQL statements.
+type sqlAnalyzer struct {
+	src    string
+	toks   []sqlToken
+	tables []TableDef
+	refs   []TableRef
+}
+
+func (a *sqlAnalyzer) run() {
+	start := 0
+	for i, t := range a.toks {
+		if t.is(";") {
+			a.statement(a.toks[start:i])
+			start = i + 1
+		}
+	}
+	a.statement(a.toks[start:])
+}
+
+// statement analyzes the tokens of one statement.
+func (a *sqlAnalyzer) statement(toks []sqlToken) {
+	if len(toks) == 0 {
+		return
+	}
+	switch {
+	case toks[0].is("CREATE"):
+		a.create(toks)
+	case toks[0].is("ALTER"):
+		a.alter(toks)
+	default:
+		a.accesses(toks)
+	}
+}
+
+// create analyzes a CREATE statement; only tables and views are of
+// interest.
+func (a *sqlAnalyzer) create(toks []sqlToken) {
+	i := 1
+	for i < len(toks) && (toks[i].is("OR") || toks[i].is("REPLACE") || toks[i].is("TEMP") ||
+		toks[i].is("TEMPORARY") || toks[i].is("UNLOGGED") || toks[i].is("GLOBAL") ||
+		toks[i].is("LOCAL") || toks[i].is("MATERIALIZED") || toks[i].is("RECURSIVE")) {
+		i++
+	}
+	if i >= len(toks) || !toks[i].is("TABLE") && !toks[i].is("VIEW") {
+		return
+	}
+	view := toks[i].is("VIEW")
+	i = skipIfExists(toks, i+1)
+	name, schema, next := tableName(toks, i)
+	if name == "" {
+		return
+	}
+	table := TableDef{
+		Name:      name,
+		Schema:    schema,
+		IsView:    view,
+		Content:   a.text(toks),
+		StartLine: toks[0].line,
+		EndLine:   toks[len(toks)-1].line,
+	}
+	if !view && next < len(toks) && toks[next].is("(") {
+		end := matchingParen(toks, next)
+		for _, item := range splitSQLList(toks[next+1 : end]) {
+			if column, ok := a.column(item); ok {
+				table.Columns = append(table.Columns, column)
+			}
+		}
+		next = end + 1
+	}
+	a.tables = append(a.tables, table)
+
+	// CREATE TABLE ... AS SELECT, CREATE VIEW ... AS SELECT
+	for ; next < len(toks); next++ {
+		if toks[next].is("AS") {
+			a.accesses(toks[next+1:])
+			return
+		}
+	}
+}
+
+// alter analyzes an ALTER TABLE statement for the columns it adds.
+func (a *sqlAnalyzer) alter(toks []sqlToken) {
+	if len(toks) < 3 || !toks[1].is("TABLE") {
+		return
+	}
+	i := skipIfExists(toks, 2)
+	if i < len(toks) && toks[i].is("ONLY") {
+		i++
+	}
+	name, schema, next := tableName(toks, i)
+	if name == "" {
+		return
+	}
+	table := TableDef{
+		Name:      name,
+		Schema:    schema,
+		IsAlter:   true,
+		Content:   a.text(toks),
+		StartLine: toks[0].line,
+		EndLine:   toks[len(toks)-1].line,
+	}
+	for _, action := range splitSQLList(toks[next:]) {
+		if len(action) < 2 || !action[0].is("ADD") {
+			continue
+		}
+		action = action[1:]
+		if action[0].is("COLUMN") {
+			action = action[1:]
+		}
+		action = action[skipIfExists(action, 0):]
+		if column, ok := a.column(action); ok {
+			table.Columns = append(table.Columns, column)
+		}
+	}
+	if len(table.Columns) > 0 {
+		a.tables = append(a.tables, table)
+	}
+}
+
+// column parses a column definition; table constraints are not columns.
+func (a *sqlAnalyzer) column(toks []sqlToken) (ColumnDef, bool) {
+	if len(toks) == 0 || toks[0].kind != sqlIdent && toks[0].kind != sqlQuoted {
+		return ColumnDef{}, false
+	}
+	if toks[0].kind == sqlIdent && sqlTableConstraints[strings.ToUpper(toks[0].text)] {
+		return ColumnDef{}, false
+	}
+	column := ColumnDef{Name: strings.ToLower(toks[0].text), StartLine: toks[0].line}
+	end := 1
+	for end < len(toks) && !(toks[end].kind == sqlIdent && sqlColumnConstraints[strings.ToUpper(toks[end].text)]) {
+		end++
+	}
+	if end > 1 {
+		column.Type = a.text(toks[1:end])
+	}
+	return column, true
+}
+
+// accesses records the tables a query reads and writes. Common table
+// expressions are not tables.
+func (a *sqlAnalyzer) accesses(toks []sqlToken) {
+	verb := ""
+	depth := 0
+	for _, t := range toks {
+		switch {
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+		case depth == 0 && t.kind == sqlIdent && verb == "":
+			switch upper := strings.ToUpper(t.text); upper {
+			case "SELECT", "INSERT", "UPDATE", "DELETE", "MERGE", "REPLACE", "TRUNCATE":
+				verb = upper
+			}
+		}
+	}
+
+	ctes := make(map[string]bool)
+	var parens []string
+	inWith := false
+	deleteTarget := verb == "DELETE"
+	for i, t := range toks {
+		prev := ""
+		if i > 0 && toks[i-1].kind == sqlIdent {
+			prev = strings.ToUpper(toks[i-1].text)
+		}
+		if t.kind == sqlPunct {
+			switch t.text {
+			case "(":
+				parens = append(parens, prev)
+			case ")":
+				if len(parens) > 0 {
+					parens = parens[:len(parens)-1]
+				}
+			}
+			continue
+		}
+		if t.kind != sqlIdent && t.kind != sqlQuoted {
+			continue
+		}
+
+		// The names of the WITH clause, up to the main statement
+		if len(parens) == 0 && inWith {
+			if prev == "WITH" || prev == "RECURSIVE" || i > 0 && toks[i-1].is(",") {
+				ctes[strings.ToLower(t.text)] = true
+				continue
+			}
+			if t.kind == sqlIdent && strings.ToUpper(t.text) == verb {
+				inWith = false
+			}
+		}
+		if t.kind != sqlIdent {
+			continue
+		}
+		if len(parens) == 0 && t.is("WITH") {
+			inWith = true
+			continue
+		}
+
+		switch strings.ToUpper(t.text) {
+		case "FROM":
+			if prev == "DISTINCT" || len(parens) > 0 && sqlFromFunctions[parens[len(parens)-1]] {
+				continue
+			}
+			write := deleteTarget && len(parens) == 0
+			if write {
+				deleteTarget = false
+			}
+			a.tableList(toks, i+1, write, ctes)
+		case "JOIN":
+			a.table(toks, i+1, false, ctes)
+		case "INTO":
+			if verb == "INSERT" || verb == "REPLACE" || verb == "MERGE" {
+				a.table(toks, i+1, true, ctes)
+			}
+		case "UPDATE":
+			// ON CONFLICT DO UPDATE, ON DUPLICATE KEY UPDATE, FOR UPDATE
+			if prev != "DO" && prev != "KEY" && prev != "FOR" {
+				a.table(toks, i+1, true, ctes)
+			}
+		case "TRUNCATE":
+			a.tableList(toks, i+1, true, ctes)
+		case "USING":
+			if verb == "MERGE" || verb == "DELETE" {
+				a.table(toks, i+1, false, ctes)
+			}
+		}
+	}
+}
+
+// tableList records the comma-separated tables starting at i, with their
+// aliases.
+func (a *sqlAnalyzer) tableList(toks []sqlToken, i int, write bool, ctes map[string]bool) {
+	for {
+		next := a.table(toks, i, write, ctes)
+		if next < 0 {
+			return
+		}
+		if next < len(toks) && toks[next].is("AS") {
+			next += 2
+		} else if next < len(toks) && toks[next].kind == sqlIdent && !sqlReserved[strings.ToUpper(toks[next].text)] {
+			next++
+		}
+		if next >= len(toks) || !toks[next].is(",") {
+			return
+		}
+		i = next + 1
+	}
+}
+
+// table records the table named at i and returns the index after its
+// name, or -1 if no table is named there: a subquery, a function or a
+// common table expression.
+func (a *sqlAnalyzer) table(toks []sqlToken, i int, write bool, ctes map[string]bool) int {
+	for i < len(toks) && toks[i].kind == sqlIdent && sqlTableModifiers[strings.ToUpper(toks[i].text)] {
+		i++
+	}
+	name, _, next := tableName(toks, i)
+	if name == "" {
+		return -1
+	}
+	// A parenthesis follows functions, and the column list of an INSERT
+	if !write && next < len(toks) && toks[next].is("(") {
+		return -1
+	}
+	if !ctes[name] {
+		a.refs = append(a.refs, TableRef{Table: name, Write: write, StartLine: toks[i].line})
+	}
+	return next
+}
+
+// text returns the source of a run of tokens, with whitespace collapsed.
+func (a *sqlAnalyzer) text(toks []sqlToken) string {
+	return strings.Join(strings.Fields(a.src[toks[0].pos:toks[len(toks)-1].end]), " ")
+}
+
+// tableName parses a possibly qualified table name at i. It returns the
+// unquoted, lower-cased name and schema, and the index after the name, or
+// an empty name.
+func tableName(toks []sqlToken, i int) (name, schema string, next int) {
+	var parts []string
+	for i < len(toks) {
+		t := toks[i]
+		if t.kind == sqlQuoted || t.kind == sqlIdent && (len(parts) > 0 || !sqlReserved[strings.ToUpper(t.text)]) {
+			parts = append(parts, strings.ToLower(t.text))
+		} else {
+			break
+		}
+		if i+2 < len(toks) && toks[i+1].is(".") {
+			i += 2
+			continue
+		}
+		i++
+		break
+	}
+	if len(parts) == 0 {
+		return "", "", i
+	}
+	if len(parts) > 1 {
+		schema = parts[len(parts)-2]
+	}
+	return parts[len(parts)-1], schema, i
+}
+
+// skipIfExists skips IF EXISTS or IF NOT EXISTS at i.
+func skipIfExists(toks []sqlToken, i int) int {
+	if i < len(toks) && toks[i].is("IF") {
+		i++
+		if i < len(toks) && toks[i].is("NOT") {
+			i++
+		}
+		if i < len(toks) && toks[i].is("EXISTS") {
+			i++
+		}
+	}
+	return i
+}
+
+// matchingParen returns the index of the parenthesis closing the one at
+// open, or len(toks).
+func matchingParen(toks []sqlToken, open int) int {
+	depth := 0
+	for i := open; i < len(toks); i++ {
+		switch {
+		case toks[i].is("("):
+			depth++
+		case toks[i].is(")"):
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+	return len(toks)
+}
+
+// splitSQLList splits tokens at the commas outside parentheses.
+func splitSQLList(toks []sqlToken) [][]sqlToken {
+	var items [][]sqlToken
+	depth, start := 0, 0
+	for i, t := range toks {
+		switch {
+		case t.is("("):
+			depth++
+		case t.is(")"):
+			depth--
+		case t.is(",") && depth == 0:
+			items = append(items, toks[start:i])
+			start = i + 1
+		}
+	}
+	return append(items, toks[start:])
+}
@@ -0,0 +1,266 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tableRefs returns the references of a parse result as "table" for reads
+// and "table!" for writes, in order.
+func tableRefs(result *ParseResult) []string {
+	var refs []string
+	for _, ref := range result.TableRefs {
+		name := ref.Table
+		if ref.Write {
+			name += "!"
+		}
+		refs = append(refs, name)
+	}
+	return refs
+}
+
+func TestSQLParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Migration", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`-- +goose Up
+CREATE TABLE IF NOT EXISTS public."Users" (
+    id          BIGSERIAL PRIMARY KEY,
+    email       VARCHAR(255) NOT NULL UNIQUE,
+    created_at  TIMESTAMP WITH TIME ZONE DEFAULT now(),
+    CONSTRAINT users_email_check CHECK (email <> '')
+);
+
+/* Orders; one per checkout */
+CREATE TABLE orders (
+    id       BIGINT,
+    user_id  BIGINT REFERENCES users (id),
+    PRIMARY KEY (id)
+);
+
+ALTER TABLE orders ADD COLUMN total NUMERIC(10, 2), ADD CONSTRAINT total_positive CHECK (total > 0);
+ALTER TABLE ONLY users ADD IF NOT EXISTS name text;
+CREATE INDEX orders_user_id ON orders (user_id);
+CREATE VIEW recent_orders AS SELECT * FROM orders o JOIN users u ON u.id = o.user_id;
+
+CREATE FUNCTION touch() RETURNS trigger AS $$
+BEGIN
+  UPDATE audit SET at = now();
+END;
+$$ LANGUAGE plpgsql;
+
+INSERT INTO users (email) VALUES ('admin@example.com');
+`)
+		result, err := NewSQLParser().Parse("migrations/001_init.sql", content)
+		require.NoError(t, err)
+		require.Len(t, result.Tables, 5)
+
+		users := result.Tables[0]
+		assert.Equal(t, "users", users.Name, "names are unquoted and lower-cased")
+		assert.Equal(t, "public", users.Schema)
+		assert.Equal(t, 2, users.StartLine)
+		assert.Equal(t, 7, users.EndLine)
+		assert.Equal(t, []ColumnDef{
+			{Name: "id", Type: "BIGSERIAL", StartLine: 3},
+			{Name: "email", Type: "VARCHAR(255)", StartLine: 4},
+			{Name: "created_at", Type: "TIMESTAMP WITH TIME ZONE", StartLine: 5},
+		}, users.Columns, "constraints are not columns")
+
+		orders := result.Tables[1]
+		assert.Equal(t, "orders", orders.Name)
+		assert.Len(t, orders.Columns, 2)
+
+		alter := result.Tables[2]
+		assert.True(t, alter.IsAlter)
+		assert.Equal(t, []ColumnDef{{Name: "total", Type: "NUMERIC(10, 2)", StartLine: 16}}, alter.Columns)
+		assert.Equal(t, "name", result.Tables[3].Columns[0].Name)
+
+		view := result.Tables[4]
+		assert.Equal(t, "recent_orders", view.Name)
+		assert.True(t, view.IsView)
+
+		assert.Equal(t, []string{"orders", "users", "users!"}, tableRefs(result),
+			"views read their tables, function bodies are opaque")
+	})
+
+	t.Run("Queries", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`-- name: GetUser :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: ArchiveOrders :exec
+WITH old AS (
+  SELECT id FROM orders WHERE created_at < now() - interval '1 year'
+)
+INSERT INTO archived_orders SELECT o.* FROM orders o, old WHERE o.id = old.id;
+
+-- name: UpdateTotals :exec
+UPDATE orders SET total = t.sum FROM order_totals t WHERE t.order_id = orders.id;
+
+-- name: DeleteUser :exec
+DELETE FROM users USING banned WHERE users.id = banned.user_id;
+
+-- name: Upsert :exec
+INSERT INTO settings (key, value) VALUES ($1, $2)
+ON CONFLICT (key) DO UPDATE SET value = EXCLUDED.value;
+
+-- name: Years :many
+SELECT EXTRACT(YEAR FROM created_at) FROM events FOR UPDATE;
+TRUNCATE TABLE sessions, tokens;
+`)
+		result, err := NewSQLParser().Parse("queries.sql", content)
+		require.NoError(t, err)
+		assert.Empty(t, result.Tables)
+		assert.Equal(t, []string{
+			"users",
+			"orders", "archived_orders!", "orders",
+			"orders!", "order_totals",
+			"users!", "banned",
+			"settings!",
+			"events",
+			"sessions!", "tokens!",
+		}, tableRefs(result))
+		assert.Equal(t, 2, result.TableRefs[0].StartLine)
+	})
+}
+
+func TestSQLParser_Language(t *testing.T) {
+	t.Parallel()
+
+	parser := NewSQLParser()
+	assert.Equal(t, "sql", parser.Language())
+	assert.True(t, parser.SupportsFile("db/migrations/001_init.up.sql"))
+	assert.False(t, parser.SupportsFile("db.go"))
+}
+
+func TestSQLLiterals(t *testing.T) {
+	t.Parallel()
+
+	t.Run("Go", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`package store
+
+import "database/sql"
+
+const getUser = ` + "`" + `-- name: GetUser :one
+SELECT id, email FROM users
+WHERE id = $1
+` + "`" + `
+
+const unused = "DELETE FROM sessions"
+
+type Queries struct{ db *sql.DB }
+
+func (q *Queries) GetUser(id int64) error {
+	row := q.db.QueryRow(getUser, id)
+	return row.Err()
+}
+
+func (q *Queries) Rename(id int64, name string) error {
+	_, err := q.db.Exec("UPDATE users "+
+		"SET name = ? WHERE id = ?", name, id)
+	return err
+}
+
+func Orders(db *sqlx.DB, userID int64) {
+	db.Select(&orders, "SELECT * FROM orders WHERE user_id = " + strconv.FormatInt(userID, 10))
+	db.Exec("CREATE TABLE IF NOT EXISTS cache (key TEXT)")
+	log.Print("failed to select users")
+}
+`)
+		result, err := NewGoParser().Parse("store/queries.go", content)
+		require.NoError(t, err)
+
+		byEnclosing := make(map[string][]string)
+		for _, ref := range result.TableRefs {
+			name := ref.Table
+			if ref.Write {
+				name += "!"
+			}
+			byEnclosing[ref.EnclosingClass+"."+ref.Enclosing] = append(byEnclosing[ref.EnclosingClass+"."+ref.Enclosing], name)
+		}
+		assert.Equal(t, map[string][]string{
+			"Queries.GetUser": {"users"},
+			"Queries.Rename":  {"users!"},
+			".Orders":         {"orders"},
+			".":               {"sessions!"},
+		}, byEnclosing, "named queries belong to their users, unused ones to the file")
+
+		for _, ref := range result.TableRefs {
+			if ref.Enclosing == "GetUser" {
+				assert.Equal(t, 15, ref.StartLine, "the line of the use")
+			}
+		}
+		require.Len(t, result.Tables, 1)
+		assert.Equal(t, "cache", result.Tables[0].Name)
+	})
+
+	t.Run("Python", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`class UserRepository:
+    def get(self, cursor, user_id):
+        cursor.execute(
+            "SELECT * FROM users "
+            "WHERE id = %s", (user_id,))
+
+    def delete(self, cursor, user_id):
+        cursor.execute("""
+            DELETE FROM users WHERE id = %(id)s
+        """, {"id": user_id})
+`)
+		result, err := NewPythonParser().Parse("repo.py", content)
+		require.NoError(t, err)
+		require.Len(t, result.TableRefs, 2)
+		assert.Equal(t, TableRef{Table: "users", StartLine: 4, Enclosing: "get", EnclosingClass: "UserRepository"}, result.TableRefs[0])
+		assert.Equal(t, TableRef{Table: "users", Write: true, StartLine: 9, Enclosing: "delete", EnclosingClass: "UserRepository"}, result.TableRefs[1])
+	})
+
+	t.Run("TypeScript", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`export async function listOrders(db: Pool, userId: string) {
+  return db.query(` + "`" + `
+    SELECT o.* FROM ${schema}.orders o
+    JOIN users u ON u.id = o.user_id
+    WHERE u.id = ${userId}
+  ` + "`" + `);
+}
+
+export const insertUser = (db: Pool) => db.query('INSERT INTO users (email) VALUES ($1)');
+`)
+		result, err := NewTypeScriptParser().Parse("orders.ts", content)
+		require.NoError(t, err)
+		require.Len(t, result.TableRefs, 2)
+		assert.Equal(t, TableRef{Table: "users", StartLine: 4, Enclosing: "listOrders"}, result.TableRefs[0],
+			"substituted names are unknown")
+		assert.Equal(t, TableRef{Table: "users", Write: true, StartLine: 9, Enclosing: "insertUser"}, result.TableRefs[1])
+	})
+}
+
+func TestSQLLiterals_Malformed(t *testing.T) {
+	t.Parallel()
+
+	// An unterminated string and a bracket that opens no identifier
+	content := []byte(`package store
+
+func Find(db *sql.DB, name string) {
+	db.Query("SELECT id FROM users WHERE name = '" + name + "'")
+	db.Query("SELECT tags[a, b] FROM posts")
+}
+`)
+	result, err := NewGoParser().Parse("store/find.go", content)
+	require.NoError(t, err)
+
+	var tables []string
+	for _, ref := range result.TableRefs {
+		tables = append(tables, ref.Table)
+	}
+	assert.Equal(t, []string{"users", "posts"}, tables)
+}
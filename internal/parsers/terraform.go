@@ -0,0 +1,327 @@
+package parsers
+
+import (
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// TerraformParser parses Terraform configuration (.tf files).
+//
+// Every top-level block of a module is a resource named by its address:
+// resource "aws_s3_bucket" "logs" is aws_s3_bucket.logs, data sources are
+// data.TYPE.NAME, and modules, variables and outputs are module.NAME,
+// var.NAME and output.NAME. Each attribute of a locals block is a
+// local.NAME resource. The references of a block are the addresses its
+// expressions mention.
+type TerraformParser struct{}
+
+// NewTerraformParser creates a new Terraform parser.
+func NewTerraformParser() *TerraformParser {
+	return &TerraformParser{}
+}
+
+// Language returns the language this parser handles.
+func (p *TerraformParser) Language() string {
+	return "terraform"
+}
+
+// SupportsFile checks if this parser can handle the given file.
+func (p *TerraformParser) SupportsFile(filename string) bool {
+	return strings.ToLower(filepath.Ext(filename)) == ".tf"
+}
+
+// hclBlockHeader matches the first line of a block: its type, its labels
+// and the opening brace.
+var hclBlockHeader = regexp.MustCompile(`^\s*([A-Za-z_][\w-]*)((?:\s+(?:"[^"]*"|[A-Za-z_][\w-]*))*)\s*\{`)
+
+// hclBlockLabel matches a label of a block header.
+var hclBlockLabel = regexp.MustCompile(`"([^"]*)"|([A-Za-z_][\w-]*)`)
+
+// hclAttribute matches the first line of an attribute.
+var hclAttribute = regexp.MustCompile(`^\s*([A-Za-z_][\w-]*)\s*=[^=]`)
+
+// hclReference matches the addresses an expression refers to: variables,
+// locals, modules (with an output), data sources and resources, whose
+// types have a provider prefix.
+var hclReference = regexp.MustCompile(`(?:^|[^\w.-])((?:var|local)\.[\w-]+|module\.[\w-]+(?:\.[\w-]+)?|data\.[\w-]+\.[\w-]+|[a-z][a-z0-9]*_[\w-]*\.[A-Za-z_][\w-]*)`)
+
+// Parse parses Terraform configuration and extracts its blocks as
+// resources.
+func (p *TerraformParser) Parse(filePath string, content []byte) (*ParseResult, error) {
+	result := &ParseResult{
+		Symbols:   []ParsedSymbol{},
+		Imports:   []ImportStatement{},
+		Calls:     []CallSite{},
+		TypeRefs:  []TypeAnnotation{},
+		Heritage:  []ClassHeritage{},
+		Resources: []Resource{},
+	}
+
+	source := string(content)
+	lines := strings.Split(source, "\n")
+	code := strings.Split(hclMask(source, false), "\n")
+	structure := strings.Split(hclMask(source, true), "\n")
+
+	var block *Resource
+	var locals []*Resource
+	blockStart, depth := 0, 0
+	for i, line := range lines {
+		lineNum := i + 1
+		if depth == 0 {
+			if m := hclBlockHeader.FindStringSubmatch(structure[i]); m != nil {
+				block = terraformBlock(m[1], hclBlockLabel.FindAllStringSubmatch(hclBlockHeaderLabels(line), -1))
+				blockStart = i
+				if block != nil {
+					block.StartLine = lineNum
+				}
+				locals = nil
+			}
+		} else if depth == 1 && block != nil {
+			if m := hclAttribute.FindStringSubmatch(structure[i]); m != nil {
+				switch block.Kind {
+				case "locals":
+					if len(locals) > 0 {
+						locals[len(locals)-1].EndLine = lineNum - 1
+					}
+					locals = append(locals, &Resource{
+						Name:      "local." + m[1],
+						Kind:      "local",
+						Platform:  "terraform",
+						StartLine: lineNum,
+					})
+				case "module":
+					if m[1] == "source" {
+						if value := hclStringValue(line); value != "" {
+							block.Properties["source"] = value
+						}
+					} else if !terraformMetaArguments[m[1]] {
+						inputs, _ := block.Properties["inputs"].([]string)
+						block.Properties["inputs"] = append(inputs, m[1])
+					}
+				}
+			}
+		}
+
+		depth += strings.Count(structure[i], "{") - strings.Count(structure[i], "}")
+		if depth > 0 || block == nil {
+			depth = max(depth, 0)
+			continue
+		}
+
+		// The block ends on this line
+		if block.Kind == "locals" {
+			for j, local := range locals {
+				if j == len(locals)-1 {
+					local.EndLine = lineNum - 1
+				}
+				local.EndLine = max(local.EndLine, local.StartLine)
+				local.Content = strings.Join(lines[local.StartLine-1:local.EndLine], "\n")
+				local.References = hclReferences(strings.Join(code[local.StartLine-1:local.EndLine], "\n"), local.Name)
+				result.Resources = append(result.Resources, *local)
+			}
+		} else {
+			block.EndLine = lineNum
+			block.Content = strings.Join(lines[blockStart:i+1], "\n")
+			block.References = hclReferences(strings.Join(code[blockStart:i+1], "\n"), block.Name)
+			result.Resources = append(result.Resources, *block)
+		}
+		block, locals = nil, nil
+	}
+
+	return result, nil
+}
+
+// terraformBlock returns the resource of a top-level block, or nil for the
+// blocks that are not resources (terraform, provider, ...).
+func terraformBlock(blockType string, labels [][]string) *Resource {
+	label := func(i int) string {
+		if i >= len(labels) {
+			return ""
+		}
+		return labels[i][1] + labels[i][2]
+	}
+	r := &Resource{Kind: blockType, Platform: "terraform", Properties: map[string]any{}}
+	switch blockType {
+	case "resource":
+		if label(1) == "" {
+			return nil
+		}
+		r.Name = label(0) + "." + label(1)
+		r.Properties["type"] = label(0)
+	case "data":
+		if label(1) == "" {
+			return nil
+		}
+		r.Name = "data." + label(0) + "." + label(1)
+		r.Properties["type"] = label(0)
+	case "module", "output":
+		if label(0) == "" {
+			return nil
+		}
+		r.Name = blockType + "." + label(0)
+	case "variable":
+		if label(0) == "" {
+			return nil
+		}
+		r.Name = "var." + label(0)
+	case "locals":
+		r.Name = "locals"
+	default:
+		return nil
+	}
+	return r
+}
+
+// terraformMetaArguments are the arguments of a module block that are not
+// inputs of the module.
+var terraformMetaArguments = map[string]bool{
+	"source": true, "version": true, "providers": true, "count": true,
+	"for_each": true, "depends_on": true,
+}
+
+// hclBlockHeaderLabels returns the labels part of a block header line.
+func hclBlockHeaderLabels(line string) string {
+	m := hclBlockHeader.FindStringSubmatch(line)
+	if m == nil {
+		return ""
+	}
+	return m[2]
+}
+
+// hclStringValue returns the string value of an attribute line, or "".
+func hclStringValue(line string) string {
+	_, value, _ := strings.Cut(line, "=")
+	value = strings.TrimSpace(value)
+	if len(value) < 2 || value[0] != '"' {
+		return ""
+	}
+	end := strings.IndexByte(value[1:], '"')
+	if end < 0 {
+		return ""
+	}
+	return value[1 : end+1]
+}
+
+// hclReferences returns the addresses an expression refers to, other than
+// self, without the attributes they are followed by.
+func hclReferences(code, self string) []string {
+	var refs []string
+	seen := map[string]bool{self: true}
+	for _, m := range hclReference.FindAllStringSubmatch(code, -1) {
+		if !seen[m[1]] {
+			seen[m[1]] = true
+			refs = append(refs, m[1])
+		}
+	}
+	return refs
+}
+
+// hclMask returns HCL source with its comments blanked out, and also the
+// text of its strings and heredocs when blankStrings is set, so that the
+// braces they contain do not count. Line breaks are kept, so that lines
+// stay aligned with the source.
+func hclMask(src string, blankStrings bool) string {
+	out := []byte(src)
+	blank := func(from, to int) {
+		for k := from; k < to && k < len(out); k++ {
+			if out[k] != '\n' {
+				out[k] = ' '
+			}
+		}
+	}
+	for i := 0; i < len(src); {
+		c := src[i]
+		switch {
+		case c == '#' || c == '/' && i+1 < len(src) && src[i+1] == '/':
+			end := i
+			for end < len(src) && src[end] != '\n' {
+				end++
+			}
+			blank(i, end)
+			i = end
+		case c == '/' && i+1 < len(src) && src[i+1] == '*':
+			end := len(src)
+			if k := indexFrom(src, "*/", i+2); k >= 0 {
+				end = k + 2
+			}
+			blank(i, end)
+			i = end
+		case c == '"':
+			end := hclStringEnd(src, i+1)
+			if blankStrings {
+				blank(i+1, end-1)
+			}
+			i = end
+		case c == '<' && i+1 < len(src) && src[i+1] == '<' && i+2 < len(src) && (src[i+2] == '-' || isSQLIdentStart(src[i+2])):
+			start := i + 2
+			if src[start] == '-' {
+				start++
+			}
+			tagEnd := start
+			for tagEnd < len(src) && (isSQLIdentStart(src[tagEnd]) || isDigit(src[tagEnd])) {
+				tagEnd++
+			}
+			tag := src[start:tagEnd]
+			end := len(src)
+			for k := tagEnd; k < len(src); {
+				next := indexFrom(src, "\n", k)
+				if next < 0 {
+					break
+				}
+				lineEnd := indexFrom(src, "\n", next+1)
+				if lineEnd < 0 {
+					lineEnd = len(src)
+				}
+				if strings.TrimSpace(src[next+1:lineEnd]) == tag {
+					end = lineEnd
+					break
+				}
+				k = next + 1
+			}
+			if blankStrings {
+				blank(tagEnd, end)
+			}
+			i = end
+		default:
+			i++
+		}
+	}
+	return string(out)
+}
+
+// hclStringEnd returns the offset after the closing quote of the string
+// whose text starts at i, skipping escapes and the quotes of interpolations.
+func hclStringEnd(src string, i int) int {
+	depth := 0
+	for i < len(src) {
+		switch c := src[i]; {
+		case c == '\\':
+			i += 2
+			continue
+		case c == '\n' && depth == 0:
+			return i
+		case (c == '$' || c == '%') && i+1 < len(src) && src[i+1] == '{':
+			depth++
+			i += 2
+			continue
+		case c == '}' && depth > 0:
+			depth--
+		case c == '"' && depth == 0:
+			return i + 1
+		}
+		i++
+	}
+	return len(src)
+}
+
+// indexFrom returns the index of substr in s at or after from, or -1.
+func indexFrom(s, substr string, from int) int {
+	if from > len(s) {
+		return -1
+	}
+	if k := strings.Index(s[from:], substr); k >= 0 {
+		return from + k
+	}
+	return -1
+}
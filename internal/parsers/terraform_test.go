@@ -0,0 +1,132 @@
+package parsers
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// resourcesByName returns the resources of a parse result by name.
+func resourcesByName(result *ParseResult) map[string]Resource {
+	resources := make(map[string]Resource, len(result.Resources))
+	for _, r := range result.Resources {
+		resources[r.Name] = r
+	}
+	return resources
+}
+
+func TestTerraformParser_Parse(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(`terraform {
+  required_version = ">= 1.5"
+}
+
+provider "aws" {
+  region = var.region
+}
+
+# The bucket for access logs
+variable "region" {
+  type    = string
+  default = "eu-west-1"
+}
+
+locals {
+  prefix = "${var.env}-api"
+  tags = {
+    Service = local.prefix # not aws_s3_bucket.commented
+  }
+}
+
+resource "aws_s3_bucket" "logs" {
+  bucket = "${local.prefix}-logs"
+  tags   = local.tags
+
+  lifecycle {
+    prevent_destroy = true
+  }
+}
+
+data "aws_iam_policy_document" "logs" {
+  statement {
+    resources = ["${aws_s3_bucket.logs.arn}/*"]
+  }
+}
+
+module "network" {
+  source     = "./modules/network"
+  version    = "1.0.0"
+  cidr_block = "10.0.0.0/16"
+  depends_on = [aws_s3_bucket.logs]
+}
+
+output "vpc_id" {
+  value = module.network.vpc_id
+  description = <<-EOT
+    The VPC of ${var.region}; braces { in heredocs } do not count.
+  EOT
+}
+`)
+	result, err := NewTerraformParser().Parse("infra/main.tf", content)
+	require.NoError(t, err)
+
+	resources := resourcesByName(result)
+	var names []string
+	for _, r := range result.Resources {
+		names = append(names, r.Name)
+	}
+	assert.Equal(t, []string{
+		"var.region", "local.prefix", "local.tags", "aws_s3_bucket.logs",
+		"data.aws_iam_policy_document.logs", "module.network", "output.vpc_id",
+	}, names, "terraform and provider blocks are not resources")
+
+	t.Run("Resource", func(t *testing.T) {
+		t.Parallel()
+
+		bucket := resources["aws_s3_bucket.logs"]
+		assert.Equal(t, "resource", bucket.Kind)
+		assert.Equal(t, "terraform", bucket.Platform)
+		assert.Equal(t, "aws_s3_bucket", bucket.Properties["type"])
+		assert.Equal(t, 22, bucket.StartLine)
+		assert.Equal(t, 29, bucket.EndLine)
+		assert.Equal(t, []string{"local.prefix", "local.tags"}, bucket.References)
+
+		policy := resources["data.aws_iam_policy_document.logs"]
+		assert.Equal(t, []string{"aws_s3_bucket.logs"}, policy.References, "references in interpolations")
+	})
+
+	t.Run("Locals", func(t *testing.T) {
+		t.Parallel()
+
+		prefix := resources["local.prefix"]
+		assert.Equal(t, "local", prefix.Kind)
+		assert.Equal(t, 16, prefix.StartLine)
+		assert.Equal(t, 16, prefix.EndLine)
+		assert.Equal(t, []string{"var.env"}, prefix.References)
+
+		tags := resources["local.tags"]
+		assert.Equal(t, 17, tags.StartLine)
+		assert.Equal(t, 19, tags.EndLine)
+		assert.Equal(t, []string{"local.prefix"}, tags.References, "comments are skipped")
+	})
+
+	t.Run("Module", func(t *testing.T) {
+		t.Parallel()
+
+		network := resources["module.network"]
+		assert.Equal(t, "./modules/network", network.Properties["source"])
+		assert.Equal(t, []string{"cidr_block"}, network.Properties["inputs"], "meta-arguments are not inputs")
+		assert.Equal(t, []string{"aws_s3_bucket.logs"}, network.References)
+	})
+
+	t.Run("Output", func(t *testing.T) {
+		t.Parallel()
+
+		output := resources["output.vpc_id"]
+		assert.Equal(t, 44, output.StartLine)
+		assert.Equal(t, 49, output.EndLine, "heredoc braces do not end the block")
+		assert.Equal(t, []string{"module.network.vpc_id", "var.region"}, output.References)
+	})
+}
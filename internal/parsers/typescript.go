@@ -2,32 +2,25 @@ package parsers
 
 import (
 	"fmt"
-	"regexp"
 	"strings"
 
 	"github.com/Benny93/axon-go/internal/graph"
 )
 
 // TypeScriptParser parses TypeScript/TSX source code.
-type TypeScriptParser struct {
-	functionRegex  *regexp.Regexp
-	classRegex     *regexp.Regexp
-	interfaceRegex *regexp.Regexp
-	typeRegex      *regexp.Regexp
-	importRegex    *regexp.Regexp
-	callRegex      *regexp.Regexp
-}
+//
+// TypeScript is parsed as JavaScript once its type syntax is set aside. A
+// first pass over the tokens records interfaces, type aliases and enums,
+// the types named by annotations, decorators, and the interfaces classes
+// implement, and drops the tokens that only exist for the type checker:
+// annotations, type arguments, modifiers, overload signatures and ambient
+// declarations. The remaining tokens keep their source positions and go
+// through the JavaScript parser.
+type TypeScriptParser struct{}
 
 // NewTypeScriptParser creates a new TypeScript parser.
 func NewTypeScriptParser() *TypeScriptParser {
-	return &TypeScriptParser{
-		functionRegex:  regexp.MustCompile(`(?m)^(?:export\s+)?(?:async\s+)?function\s+(\w+)\s*\(([^)]*)\)(?:\s*:\s*(\S+))?`),
-		classRegex:     regexp.MustCompile(`(?m)^(?:export\s+)?(?:abstract\s+)?class\s+(\w+)(?:\s+extends\s+(\w+))?(?:\s+implements\s+(\w+))?`),
-		interfaceRegex: regexp.MustCompile(`(?m)^(?:export\s+)?interface\s+(\w+)`),
-		typeRegex:      regexp.MustCompile(`(?m)^(?:export\s+)?type\s+(\w+)\s*=`),
-		importRegex:    regexp.MustCompile(`(?m)^import\s+(?:{([^}]+)}|\*\s+as\s+(\w+)|(\w+))\s+from\s+['"]([^'"]+)['"]`),
-		callRegex:      regexp.MustCompile(`(\w+)\s*\(([^)]*)\)`),
-	}
+	return &TypeScriptParser{}
 }
 
 // Language returns the language this parser handles.
@@ -43,408 +36,988 @@ func (p *TypeScriptParser) SupportsFile(filename string) bool {
 // Parse parses TypeScript source code and extracts symbols, imports, calls, etc.
 func (p *TypeScriptParser) Parse(filePath string, content []byte) (*ParseResult, error) {
 	source := string(content)
+	ts := newJSParser(source, lexJS(source, strings.HasSuffix(filePath, ".tsx")))
+	ts.stripTypes()
+	ts.parse()
+	setDocComments(ts.result, source, slashDocComments)
+	return ts.result, nil
+}
 
-	result := &ParseResult{
-		Symbols:  []ParsedSymbol{},
-		Imports:  []ImportStatement{},
-		Calls:    []CallSite{},
-		TypeRefs: []TypeAnnotation{},
-		Heritage: []ClassHeritage{},
-		Package:  "",
-	}
+// tsTypeKeywords are the keywords and primitive types of type annotations,
+// which do not name a declared type.
+var tsTypeKeywords = map[string]bool{
+	"string": true, "number": true, "boolean": true, "any": true, "unknown": true,
+	"void": true, "never": true, "null": true, "undefined": true, "object": true,
+	"symbol": true, "bigint": true, "true": true, "false": true, "this": true,
+	"keyof": true, "typeof": true, "infer": true, "extends": true, "readonly": true,
+	"unique": true, "is": true, "asserts": true, "new": true, "in": true, "as": true,
+	"const": true, "import": true,
+}
 
-	// Parse functions (including arrow functions)
-	p.parseFunctions(source, filePath, result)
+// tsTypeOperators are the keywords that prefix a type.
+var tsTypeOperators = map[string]bool{
+	"keyof": true, "typeof": true, "readonly": true, "unique": true,
+	"infer": true, "asserts": true, "new": true, "abstract": true,
+}
 
-	// Parse classes
-	p.parseClasses(source, filePath, result)
+// tsModifiers are the modifiers of class members and constructor
+// parameters that only exist for the type checker.
+var tsModifiers = map[string]bool{
+	"public": true, "private": true, "protected": true, "readonly": true,
+	"abstract": true, "override": true, "declare": true,
+}
 
-	// Parse interfaces
-	p.parseInterfaces(source, filePath, result)
+// tsTypeWrappers are generic types whose type argument is the type a field
+// holds: Readonly<Repository>.
+var tsTypeWrappers = map[string]bool{
+	"Readonly": true, "Partial": true, "Required": true, "NonNullable": true,
+	"Array": true, "ReadonlyArray": true,
+}
 
-	// Parse types
-	p.parseTypes(source, filePath, result)
+// tsStripper drops the type syntax of a TypeScript token stream.
+type tsStripper struct {
+	*jsParser
 
-	// Parse imports
-	p.parseImports(source, filePath, result)
+	drop []bool
 
-	// Parse function calls
-	p.parseCalls(source, filePath, result)
+	// params holds the indices of the '(' of the parameter lists whose
+	// types are stripped
+	params map[int]bool
 
-	return result, nil
-}
+	// classBodies maps the index of the '{' opening a class body to the
+	// class name
+	classBodies map[int]string
 
-func (p *TypeScriptParser) parseFunctions(source, filePath string, result *ParseResult) {
-	matches := p.functionRegex.FindAllStringSubmatch(source, -1)
-	for _, match := range matches {
-		if len(match) < 2 {
-			continue
-		}
+	// classes holds, for each open brace, the class it is the body of, or ""
+	classes []string
 
-		name := match[1]
-		params := ""
-		returnType := ""
+	// decorators holds the decorators read before the next declaration
+	decorators []string
 
-		if len(match) > 2 {
-			params = strings.TrimSpace(match[2])
+	// member is the index of the class member following decorators
+	member int
+}
+
+// stripTypes records the type information of TypeScript tokens and removes
+// the tokens that are not JavaScript.
+func (p *jsParser) stripTypes() {
+	p.implements = make(map[string][]string)
+	p.fields = make(map[string]map[string]string)
+	p.returnTypes = make(map[int]string)
+	p.decorators = make(map[int][]string)
+	p.namespaces = make(map[int]string)
+
+	s := &tsStripper{
+		jsParser:    p,
+		drop:        make([]bool, len(p.toks)),
+		params:      make(map[int]bool),
+		classBodies: make(map[int]string),
+		member:      -1,
+	}
+	for i := 0; i < len(p.toks); i++ {
+		if !s.drop[i] {
+			i = s.token(i)
 		}
-		if len(match) > 3 && match[3] != "" {
-			returnType = match[3]
+	}
+
+	// A line break before dropped tokens precedes the next kept token
+	kept := make([]jsToken, 0, len(p.toks))
+	nl := false
+	for i, t := range p.toks {
+		if s.drop[i] {
+			nl = nl || t.nl
+			continue
 		}
+		t.nl = t.nl || nl
+		nl = false
+		kept = append(kept, t)
+	}
+	p.toks = kept
+}
 
-		// Find line number
-		lineNum := 1
-		for i := 0; i < strings.Index(source, match[0]); i++ {
-			if source[i] == '\n' {
-				lineNum++
+// token handles the token at index i and returns the index of the last
+// token it handled.
+func (s *tsStripper) token(i int) int {
+	t := s.toks[i]
+	switch t.kind {
+	case jsPunct:
+		switch t.text {
+		case "{":
+			s.classes = append(s.classes, s.classBodies[i])
+		case "}":
+			if n := len(s.classes); n > 0 {
+				s.classes = s.classes[:n-1]
+			}
+		case "(":
+			s.parenthesis(i)
+		case "<":
+			s.typeArguments(i)
+		case "!":
+			s.nonNull(i)
+		case "@":
+			s.decorator(i)
+		case "[":
+			if class := s.class(); class != "" && s.memberStart(i) {
+				s.memberDeclaration(i, class)
 			}
 		}
-
-		signature := fmt.Sprintf("function %s(%s)", name, params)
-		if returnType != "" {
-			signature += fmt.Sprintf(": %s", returnType)
+	case jsIdent:
+		if class := s.class(); class != "" && s.memberStart(i) {
+			s.memberDeclaration(i, class)
+			return i
 		}
+		return s.identifier(i)
+	}
+	return i
+}
 
-		sym := ParsedSymbol{
-			Name:      name,
-			Kind:      graph.NodeFunction,
-			StartLine: lineNum,
-			EndLine:   lineNum,
-			Signature: signature,
-			IsExported: func() bool {
-				idx := strings.Index(source, match[0])
-				return idx >= 0 && strings.Contains(source[:idx], "export")
-			}(),
-		}
+// prev returns the index of the last kept token before index i, or -1.
+func (s *tsStripper) prev(i int) int {
+	for i--; i >= 0 && s.drop[i]; i-- {
+	}
+	return i
+}
 
-		result.Symbols = append(result.Symbols, sym)
+// dropRange drops the tokens from index from to index to, inclusive.
+func (s *tsStripper) dropRange(from, to int) {
+	for j := max(from, 0); j <= to && j < len(s.drop); j++ {
+		s.drop[j] = true
+	}
+}
 
-		// Extract type references from parameters and return type
-		p.extractTypeRefs(params, returnType, filePath, lineNum, result)
+// class returns the class whose body the current position is directly in.
+func (s *tsStripper) class() string {
+	if n := len(s.classes); n > 0 {
+		return s.classes[n-1]
 	}
+	return ""
+}
 
-	// Parse arrow functions assigned to constants
-	arrowRegex := regexp.MustCompile(`(?m)^(?:export\s+)?(?:const|let|var)\s+(\w+)\s*=\s*(?:\(([^)]*)\)|(\w+))\s*=>`)
-	arrowMatches := arrowRegex.FindAllStringSubmatch(source, -1)
-	for _, match := range arrowMatches {
-		if len(match) < 2 {
-			continue
-		}
+// memberStart reports whether the token at index i starts a class member.
+// Dropped modifiers still count: they are part of the member.
+func (s *tsStripper) memberStart(i int) bool {
+	return i == s.member || s.tok(i).nl || s.is(i-1, "{") || s.is(i-1, ";") || s.is(i-1, "}")
+}
 
-		name := match[1]
-		params := ""
-		if len(match) > 2 && match[2] != "" {
-			params = match[2]
-		} else if len(match) > 3 && match[3] != "" {
-			params = match[3]
-		}
+// startsStatement reports whether the token at index i starts a statement.
+func (s *tsStripper) startsStatement(i int) bool {
+	prev := s.prev(i)
+	t := s.tok(prev)
+	switch t.kind {
+	case jsEOF:
+		return true
+	case jsPunct:
+		if t.text == ";" || t.text == "{" || t.text == "}" {
+			return true
+		}
+		return s.tok(i).nl && (t.text == ")" || t.text == "]")
+	case jsIdent:
+		switch t.text {
+		case "export", "declare":
+			return true
+		case "default":
+			return s.is(s.prev(prev), "export")
+		}
+		return s.tok(i).nl && !jsExpressionKeywords[t.text]
+	default:
+		return s.tok(i).nl
+	}
+}
 
-		// Find line number
-		lineNum := 1
-		for i := 0; i < strings.Index(source, match[0]); i++ {
-			if source[i] == '\n' {
-				lineNum++
-			}
+// declarationStart returns the index of the first of the export, default
+// and declare keywords before the declaration keyword at index i.
+func (s *tsStripper) declarationStart(i int) int {
+	for {
+		prev := s.prev(i)
+		switch s.ident(prev) {
+		case "export", "default", "declare":
+			i = prev
+			continue
 		}
+		return i
+	}
+}
 
-		sym := ParsedSymbol{
-			Name: name,
-			Kind: graph.NodeFunction,
+// exportedFrom reports whether the declaration starting at index start is
+// exported.
+func (s *tsStripper) exportedFrom(start int) bool {
+	return s.is(start, "export")
+}
 
-			StartLine: lineNum,
-			EndLine:   lineNum,
-			Signature: fmt.Sprintf("const %s = (%s) => ...", name, params),
-			IsExported: func() bool {
-				idx := strings.Index(source, match[0])
-				return idx >= 0 && strings.Contains(source[:idx], "export")
-			}(),
+// statementEnd returns the index of the last token of the declaration
+// starting at index j: the brace closing its body, a ';', or the last
+// token of its last line.
+func (s *tsStripper) statementEnd(j int) int {
+	for k := j; k < len(s.toks); k++ {
+		switch {
+		case s.is(k, "{"):
+			closeBrace := s.matching(k)
+			// Object types are part of the declaration, not its body
+			if s.is(k-1, ":") || s.is(k-1, "=") || s.is(k-1, "|") || s.is(k-1, "&") ||
+				s.is(k-1, "<") || s.is(k-1, ",") || s.is(k-1, "(") {
+				k = closeBrace
+				continue
+			}
+			return closeBrace
+		case s.is(k, "(") || s.is(k, "["):
+			k = s.matching(k)
+		case s.is(k, ";"):
+			return k
+		case k > j && s.tok(k).nl && !s.continues(k-1, k):
+			return k - 1
 		}
+	}
+	return len(s.toks) - 1
+}
 
-		result.Symbols = append(result.Symbols, sym)
+// identifier handles the identifier at index i and returns the index of
+// the last token it handled.
+func (s *tsStripper) identifier(i int) int {
+	switch s.tok(i).text {
+	case "class":
+		s.classDeclaration(i)
+	case "interface":
+		if s.ident(i+1) != "" && s.startsStatement(i) {
+			s.interfaceDeclaration(i)
+		}
+	case "type":
+		if s.ident(i+1) != "" && (s.is(i+2, "=") || s.is(i+2, "<")) && s.startsStatement(i) {
+			s.typeAlias(i)
+		}
+	case "enum":
+		if s.ident(i+1) != "" && s.is(i+2, "{") && (s.startsStatement(i) || s.is(s.prev(i), "const")) {
+			s.enumDeclaration(i)
+		}
+	case "declare":
+		// Ambient declarations: declare module 'x' {}, declare const x: T
+		if s.ident(i+1) != "" && !s.tok(i+1).nl && s.startsStatement(i) {
+			end := s.statementEnd(i)
+			s.dropRange(s.declarationStart(i), end)
+			return end
+		}
+	case "namespace", "module":
+		if !s.startsStatement(i) || s.tok(i+1).nl {
+			break
+		}
+		if s.tok(i+1).kind == jsString {
+			end := s.statementEnd(i)
+			s.dropRange(s.declarationStart(i), end)
+			return end
+		}
+		// The declarations of a namespace are those of a block, whose
+		// functions are recorded as members of the namespace
+		if name, j := s.dottedName(i + 1); j > i+1 && s.is(j, "{") {
+			s.dropRange(s.declarationStart(i), j-1)
+			s.namespaces[s.toks[j].pos] = name
+		}
+	case "abstract":
+		if s.is(i+1, "class") {
+			s.drop[i] = true
+		}
+	case "import":
+		return s.importDeclaration(i)
+	case "export":
+		return s.exportDeclaration(i)
+	case "const", "let", "var":
+		s.variableDeclaration(i)
+	case "as", "satisfies":
+		s.assertion(i)
 	}
+	return i
 }
 
-func (p *TypeScriptParser) parseClasses(source, filePath string, result *ParseResult) {
-	matches := p.classRegex.FindAllStringSubmatch(source, -1)
-	for _, match := range matches {
-		if len(match) < 2 {
-			continue
-		}
+// dottedName returns the dotted name starting at index j and the index
+// after it.
+func (s *tsStripper) dottedName(j int) (string, int) {
+	name := s.ident(j)
+	if name == "" {
+		return "", j
+	}
+	j++
+	for s.is(j, ".") && s.ident(j+1) != "" {
+		name += "." + s.ident(j+1)
+		j += 2
+	}
+	return name, j
+}
 
-		name := match[1]
-		extends := ""
-		implements := ""
+// classDeclaration strips the type parameters, type arguments and
+// implements clause of the class declared at index i.
+func (s *tsStripper) classDeclaration(i int) {
+	j := i + 1
+	name := s.ident(j)
+	if name == "extends" || name == "implements" {
+		name = ""
+	}
+	if name != "" {
+		j++
+	} else if s.is(s.prev(i), "=") && s.ident(s.prev(s.prev(i))) != "" {
+		name = s.ident(s.prev(s.prev(i)))
+	}
+	s.skipTypeArguments(&j)
 
-		if len(match) > 2 && match[2] != "" {
-			extends = match[2]
-		}
-		if len(match) > 3 && match[3] != "" {
-			implements = match[3]
+	if s.is(j, "extends") {
+		_, j = s.dottedName(j + 1)
+		s.skipTypeArguments(&j)
+		if s.is(j, "(") {
+			// Mixins: extends Timestamped(Base)
+			j = s.matching(j) + 1
 		}
+	}
 
-		// Find line number
-		lineNum := 1
-		for i := 0; i < strings.Index(source, match[0]); i++ {
-			if source[i] == '\n' {
-				lineNum++
+	if s.is(j, "implements") {
+		start := j
+		var interfaces []string
+		for j++; j < len(s.toks) && !s.is(j, "{"); j++ {
+			if s.is(j, "<") {
+				if end := s.typeArgsEnd(j); end >= 0 {
+					j = end
+				}
+				continue
+			}
+			if s.ident(j) != "" && !s.is(j+1, ".") {
+				interfaces = append(interfaces, s.ident(j))
 			}
 		}
-
-		sym := ParsedSymbol{
-			Name: name,
-			Kind: graph.NodeClass,
-
-			StartLine: lineNum,
-			EndLine:   lineNum,
-			Signature: fmt.Sprintf("class %s", name),
-			IsExported: func() bool {
-				idx := strings.Index(source, match[0])
-				return idx >= 0 && strings.Contains(source[:idx], "export")
-			}(),
+		s.dropRange(start, j-1)
+		if name != "" {
+			s.implements[name] = append(s.implements[name], interfaces...)
 		}
+	}
 
-		result.Symbols = append(result.Symbols, sym)
-
-		// Add heritage information
-		if extends != "" || implements != "" {
-			heritage := ClassHeritage{
-				ClassName: name,
-			}
-			if extends != "" {
-				heritage.Extends = []string{extends}
-			}
-			if implements != "" {
-				heritage.Implements = []string{implements}
-			}
-			result.Heritage = append(result.Heritage, heritage)
+	if s.is(j, "{") {
+		if name == "" {
+			name = "default"
 		}
-
-		// Parse methods in class
-		p.parseClassMethods(source, filePath, name, result)
+		s.classBodies[j] = name
 	}
 }
 
-func (p *TypeScriptParser) parseClassMethods(source, filePath, className string, result *ParseResult) {
-	// Simple method regex - looks for methods within class body
-	methodRegex := regexp.MustCompile(`(?m)(?:async\s+)?(?:get|set)?\s*(\w+)\s*\(([^)]*)\)(?:\s*:\s*(\S+))?`)
-
-	// Find class body
-	classBodyRegex := regexp.MustCompile(fmt.Sprintf(`(?m)class\s+%s\s*{([^}]+)}`, className))
-	classMatch := classBodyRegex.FindStringSubmatch(source)
-	if len(classMatch) < 2 {
+// skipTypeArguments drops the type parameters or arguments at index *j and
+// moves past them.
+func (s *tsStripper) skipTypeArguments(j *int) {
+	if !s.is(*j, "<") {
 		return
 	}
+	if end := s.typeArgsEnd(*j); end >= 0 {
+		s.dropRange(*j, end)
+		*j = end + 1
+	}
+}
 
-	classBody := classMatch[1]
-	matches := methodRegex.FindAllStringSubmatch(classBody, -1)
-	for _, match := range matches {
-		if len(match) < 2 {
-			continue
-		}
-
-		name := match[1]
-		// Skip constructor and keywords
-		if name == "constructor" || name == "if" || name == "for" || name == "while" {
-			continue
+// memberDeclaration strips the modifiers and types of the class member
+// starting at index i. Members without a body, such as overload
+// signatures, abstract methods and index signatures, are dropped.
+func (s *tsStripper) memberDeclaration(i int, class string) {
+	j := i
+	for {
+		name := s.ident(j)
+		if !tsModifiers[name] && name != "static" && name != "async" && name != "get" && name != "set" && name != "accessor" {
+			break
 		}
-
-		params := ""
-		returnType := ""
-		if len(match) > 2 {
-			params = match[2]
+		// A modifier is followed by the name of the member
+		if next := s.tok(j + 1); next.nl || next.kind != jsIdent && next.kind != jsString && !s.is(j+1, "[") && !s.is(j+1, "*") {
+			break
 		}
-		if len(match) > 3 && match[3] != "" {
-			returnType = match[3]
+		if tsModifiers[name] {
+			s.drop[j] = true
 		}
+		j++
+	}
+	if s.is(j, "*") {
+		j++
+	}
 
-		signature := fmt.Sprintf("%s(%s)", name, params)
-		if returnType != "" {
-			signature += fmt.Sprintf(": %s", returnType)
-		}
+	// Index signatures: [key: string]: T
+	if s.is(j, "[") && s.ident(j+1) != "" && s.is(j+2, ":") {
+		s.dropRange(i, s.statementEnd(j))
+		return
+	}
 
-		sym := ParsedSymbol{
-			Name:      name,
-			Kind:      graph.NodeMethod,
-			ClassName: className,
+	name := s.ident(j)
+	nameEnd := j
+	if s.is(j, "[") {
+		nameEnd = s.matching(j)
+	}
+	k := nameEnd + 1
+	if s.is(k, "?") || s.is(k, "!") {
+		s.drop[k] = true
+		k++
+	}
+	s.skipTypeArguments(&k)
 
-			Signature:  signature,
-			IsExported: false, // Methods inherit export status from class
+	switch {
+	case s.is(k, "("):
+		property := ""
+		if name == "constructor" {
+			property = class
+		}
+		if after := s.signature(k, property); !s.is(after, "{") {
+			s.dropRange(i, s.statementEnd(i))
 		}
+	case s.is(k, ":"):
+		end := s.typeEnd(k + 1)
+		if typ := s.typeRefs(k, end, "variable"); typ != "" && name != "" {
+			s.field(class, name, typ)
+		}
+		s.dropRange(k, end-1)
+	}
+}
 
-		result.Symbols = append(result.Symbols, sym)
+// field records that a field of class holds the type typ.
+func (s *tsStripper) field(class, name, typ string) {
+	if s.fields[class] == nil {
+		s.fields[class] = make(map[string]string)
 	}
+	s.fields[class][name] = typ
 }
 
-func (p *TypeScriptParser) parseInterfaces(source, filePath string, result *ParseResult) {
-	matches := p.interfaceRegex.FindAllStringSubmatch(source, -1)
-	for _, match := range matches {
-		if len(match) < 2 {
+// signature strips the types of the parameter list opened at index open
+// and the return type after it, and returns the index of the token
+// following them. With class set, the parameter properties of a
+// constructor (private repo: Repository) declare fields of the class.
+func (s *tsStripper) signature(open int, class string) int {
+	s.params[open] = true
+	closeParen := s.matching(open)
+	for _, item := range s.items(open, closeParen) {
+		j := item[0]
+		for s.is(j, "@") {
+			_, j = s.dottedName(j + 1)
+			if s.is(j, "(") {
+				j = s.matching(j) + 1
+			}
+		}
+		property := false
+		for tsModifiers[s.ident(j)] && (s.ident(j+1) != "" || s.is(j+1, "{") || s.is(j+1, "[")) {
+			s.drop[j] = true
+			property = true
+			j++
+		}
+		if s.is(j, "...") {
+			j++
+		}
+
+		name := s.ident(j)
+		if name == "this" && s.is(j+1, ":") {
+			// The declared type of this: f(this: Window, ...)
+			end := item[1]
+			if !s.is(end, ",") {
+				end--
+			}
+			s.dropRange(item[0], end)
 			continue
 		}
+		if s.is(j, "{") || s.is(j, "[") {
+			j = s.matching(j)
+		}
+		j++
+		if s.is(j, "?") {
+			s.drop[j] = true
+			j++
+		}
+		if s.is(j, ":") {
+			end := s.typeEnd(j + 1)
+			typ := s.typeRefs(j, end, "param")
+			s.dropRange(j, end-1)
+			if property && class != "" && name != "" && typ != "" {
+				s.field(class, name, typ)
+			}
+		}
+	}
 
-		name := match[1]
+	k := closeParen + 1
+	if s.is(k, ":") {
+		end := s.typeEnd(k + 1)
+		s.typeRefs(k, end, "return")
+		s.returnTypes[s.tok(closeParen).pos] = s.text(k, end)
+		s.dropRange(k, end-1)
+		k = end
+	}
+	return k
+}
 
-		// Find line number
-		lineNum := 1
-		for i := 0; i < strings.Index(source, match[0]); i++ {
-			if source[i] == '\n' {
-				lineNum++
+// items returns the index ranges of the comma-separated items between the
+// brackets at indices open and closeBracket; each range ends at the comma
+// or closing bracket after the item.
+func (s *tsStripper) items(open, closeBracket int) [][2]int {
+	var items [][2]int
+	start := open + 1
+	for j := open + 1; j < closeBracket; j++ {
+		switch {
+		case s.is(j, "(") || s.is(j, "[") || s.is(j, "{"):
+			j = s.matching(j)
+		case s.is(j, "<"):
+			if end := s.typeArgsEnd(j); end >= 0 {
+				j = end
 			}
+		case s.is(j, ","):
+			items = append(items, [2]int{start, j})
+			start = j + 1
 		}
+	}
+	if start < closeBracket {
+		items = append(items, [2]int{start, closeBracket})
+	}
+	return items
+}
 
-		sym := ParsedSymbol{
-			Name: name,
-			Kind: graph.NodeInterface,
+// parenthesis strips the types of the parameter list opened at index i, if
+// it is one: the parameters of a function, an arrow function, an object
+// literal method or a catch clause.
+func (s *tsStripper) parenthesis(i int) {
+	if s.params[i] {
+		return
+	}
+	prev := s.prev(i)
+	closeParen := s.matching(i)
+	afterReturn := closeParen + 1
+	if s.is(afterReturn, ":") {
+		afterReturn = s.typeEnd(afterReturn + 1)
+	}
 
-			StartLine: lineNum,
-			EndLine:   lineNum,
-			Signature: fmt.Sprintf("interface %s", name),
-			IsExported: func() bool {
-				idx := strings.Index(source, match[0])
-				return idx >= 0 && strings.Contains(source[:idx], "export")
-			}(),
-		}
+	name := s.ident(prev)
+	before := s.prev(prev)
+	switch {
+	case name == "catch", name == "function":
+	case name != "" && s.is(before, "function"):
+	case name != "" && s.is(before, "*") && s.is(s.prev(before), "function"):
+	case s.is(afterReturn, "=>"):
+	case name != "" && !jsNotCalled[name] && !s.is(before, "?") && !s.is(before, ".") && s.is(afterReturn, "{"):
+		// Object literal methods: name(params): T { ... }
+	default:
+		return
+	}
+	s.signature(i, "")
+}
 
-		result.Symbols = append(result.Symbols, sym)
+// typeArguments drops the type arguments of generic calls (f<T>(),
+// new Map<K, V>()) and the type parameters of generic arrow functions and
+// type assertions (<T>(x: T) => x, <T>value) at index i.
+func (s *tsStripper) typeArguments(i int) {
+	end := s.typeArgsEnd(i)
+	if end < 0 {
+		return
 	}
+	prev := s.tok(s.prev(i))
+	next := s.tok(end + 1)
+	switch {
+	case prev.kind == jsIdent && !jsExpressionKeywords[prev.text] && !s.tok(i).nl:
+		if !s.is(end+1, "(") && next.kind != jsTemplate {
+			return
+		}
+	case prev.kind == jsEOF || prev.kind == jsPunct && prev.text != ")" && prev.text != "]" && prev.text != "}" ||
+		prev.kind == jsIdent && jsExpressionKeywords[prev.text]:
+		if !s.is(end+1, "(") && next.kind != jsIdent {
+			return
+		}
+	default:
+		return
+	}
+	s.dropRange(i, end)
 }
 
-func (p *TypeScriptParser) parseTypes(source, filePath string, result *ParseResult) {
-	matches := p.typeRegex.FindAllStringSubmatch(source, -1)
-	for _, match := range matches {
-		if len(match) < 2 {
-			continue
-		}
+// nonNull drops the non-null assertion at index i (value!.field).
+func (s *tsStripper) nonNull(i int) {
+	prev := s.tok(i - 1)
+	if s.drop[max(i-1, 0)] || prev.end != s.tok(i).pos {
+		return
+	}
+	if prev.kind == jsIdent && !jsExpressionKeywords[prev.text] || s.is(i-1, ")") || s.is(i-1, "]") {
+		s.drop[i] = true
+	}
+}
 
-		name := match[1]
+// decorator records the decorator at index i for the declaration it
+// precedes. The decorator expression itself is kept, so that calls in it
+// are recorded. Parameter decorators are not recorded.
+func (s *tsStripper) decorator(i int) {
+	name, end := s.dottedName(i + 1)
+	if name == "" || s.is(s.prev(i), "(") || s.is(s.prev(i), ",") {
+		return
+	}
+	if s.is(end, "(") {
+		end = s.matching(end) + 1
+	}
+	s.decorators = append(s.decorators, name)
+	if s.is(end, "@") {
+		return
+	}
+	line := s.tok(end).line
+	s.jsParser.decorators[line] = append(s.jsParser.decorators[line], s.decorators...)
+	s.decorators = nil
+	s.member = end
+}
 
-		// Find line number
-		lineNum := 1
-		for i := 0; i < strings.Index(source, match[0]); i++ {
-			if source[i] == '\n' {
-				lineNum++
+// interfaceDeclaration records and drops the interface declared at index i.
+func (s *tsStripper) interfaceDeclaration(i int) {
+	start := s.declarationStart(i)
+	name := s.ident(i + 1)
+	j := i + 2
+	if s.is(j, "<") {
+		if j = s.typeArgsEnd(j) + 1; j == 0 {
+			return
+		}
+	}
+	var bases []string
+	if s.is(j, "extends") {
+		for j++; j < len(s.toks) && !s.is(j, "{"); j++ {
+			if s.is(j, "<") {
+				if end := s.typeArgsEnd(j); end >= 0 {
+					j = end
+				}
+				continue
+			}
+			if s.ident(j) != "" && !s.is(j+1, ".") {
+				bases = append(bases, s.ident(j))
 			}
 		}
+	}
+	if !s.is(j, "{") {
+		return
+	}
+	closeBrace := s.matching(j)
+	s.typeRefs(j, closeBrace, "variable")
+
+	signature := "interface " + name
+	if len(bases) > 0 {
+		signature += " extends " + strings.Join(bases, ", ")
+		h := s.heritage(name)
+		h.Extends = append(h.Extends, bases...)
+	}
+	s.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeInterface,
+		Signature:  signature,
+		IsExported: s.exportedFrom(start),
+	}, start, jsFunc{body: -1, end: closeBrace})
+	s.dropRange(start, closeBrace)
+}
 
-		sym := ParsedSymbol{
-			Name: name,
-			Kind: graph.NodeTypeAlias,
-
-			StartLine: lineNum,
-			EndLine:   lineNum,
-			Signature: fmt.Sprintf("type %s = ...", name),
-			IsExported: func() bool {
-				idx := strings.Index(source, match[0])
-				return idx >= 0 && strings.Contains(source[:idx], "export")
-			}(),
+// typeAlias records and drops the type alias declared at index i.
+func (s *tsStripper) typeAlias(i int) {
+	start := s.declarationStart(i)
+	name := s.ident(i + 1)
+	j := i + 2
+	if s.is(j, "<") {
+		if j = s.typeArgsEnd(j) + 1; j == 0 {
+			return
 		}
+	}
+	if !s.is(j, "=") {
+		return
+	}
+	end := s.typeEnd(j + 1)
+	s.typeRefs(j, end, "variable")
 
-		result.Symbols = append(result.Symbols, sym)
+	value := s.text(j, end)
+	if len(value) > 80 {
+		value = "..."
 	}
+	s.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeTypeAlias,
+		Signature:  fmt.Sprintf("type %s = %s", name, value),
+		IsExported: s.exportedFrom(start),
+	}, start, jsFunc{body: -1, end: end - 1})
+
+	last := end - 1
+	if s.is(end, ";") {
+		last = end
+	}
+	s.dropRange(start, last)
 }
 
-func (p *TypeScriptParser) parseImports(source, filePath string, result *ParseResult) {
-	matches := p.importRegex.FindAllStringSubmatch(source, -1)
-	for _, match := range matches {
-		if len(match) < 5 {
-			continue
-		}
-
-		modulePath := match[4]
-		symbols := []string{}
+// enumDeclaration records and drops the enum declared at index i.
+func (s *tsStripper) enumDeclaration(i int) {
+	start := i
+	if prev := s.prev(i); s.is(prev, "const") {
+		start = prev
+	}
+	start = s.declarationStart(start)
+	closeBrace := s.matching(i + 2)
+	name := s.ident(i + 1)
+	s.define(ParsedSymbol{
+		Name:       name,
+		Kind:       graph.NodeEnum,
+		Signature:  "enum " + name,
+		IsExported: s.exportedFrom(start),
+	}, start, jsFunc{body: -1, end: closeBrace})
+	s.dropRange(start, closeBrace)
+}
 
-		// Named imports: { User, Post }
-		if match[1] != "" {
-			parts := strings.Split(match[1], ",")
-			for _, part := range parts {
-				symbols = append(symbols, strings.TrimSpace(part))
-			}
-		}
+// importDeclaration strips the type-only parts of the import at index i and
+// returns the index of its last token.
+func (s *tsStripper) importDeclaration(i int) int {
+	if s.is(i+1, "(") || s.is(i+1, ".") || s.is(i-1, ".") {
+		return i
+	}
+	j := i + 1
+	if s.is(j, "type") && (s.is(j+1, "{") || s.is(j+1, "*") || s.ident(j+1) != "" && !s.is(j+1, "from")) {
+		s.drop[j] = true
+		j++
+	}
 
-		// Namespace import: * as db
-		if match[2] != "" {
-			symbols = append(symbols, match[2])
+	// import fs = require('fs'), import Alias = Namespace.Member
+	if name := s.ident(j); name != "" && s.is(j+1, "=") {
+		end := s.statementEnd(j)
+		if s.is(j+2, "require") && s.is(j+3, "(") && s.tok(j+4).kind == jsString {
+			s.addImport(s.tok(j+4).text, []string{name}, s.tok(i).line)
 		}
+		s.dropRange(s.declarationStart(i), end)
+		return end
+	}
+	return s.clause(i, j)
+}
 
-		// Default import: express
-		if match[3] != "" {
-			symbols = append(symbols, match[3])
-		}
+// exportDeclaration strips the type-only parts of the export at index i and
+// returns the index of its last token.
+func (s *tsStripper) exportDeclaration(i int) int {
+	j := i + 1
+	switch {
+	case s.is(j, "="):
+		// export = name
+		if name := s.ident(j + 1); name != "" {
+			s.exports[name] = true
+		}
+	case s.is(j, "as") && s.is(j+1, "namespace"), s.is(j, "import"):
+	case s.is(j, "type") && (s.is(j+1, "{") || s.is(j+1, "*")):
+		s.drop[j] = true
+		return s.clause(i, j+1)
+	case s.is(j, "{") || s.is(j, "*"):
+		return s.clause(i, j)
+	default:
+		return i
+	}
+	end := s.statementEnd(j)
+	s.dropRange(i, end)
+	return end
+}
 
-		// Find line number
-		lineNum := 1
-		for i := 0; i < strings.Index(source, match[0]); i++ {
-			if source[i] == '\n' {
-				lineNum++
+// clause drops the type-only specifiers of the import or export clause of
+// the statement at index i, which starts at index j, and returns the index
+// of its last token. The names in the clause are never types to strip.
+func (s *tsStripper) clause(i, j int) int {
+	for ; j < len(s.toks); j++ {
+		switch {
+		case s.is(j, "{"):
+			closeBrace := s.matching(j)
+			for k := j + 1; k < closeBrace; k++ {
+				if s.is(k, "type") && s.ident(k+1) != "" && !s.is(k+1, "as") {
+					s.drop[k] = true
+				}
 			}
+			j = closeBrace
+		case s.tok(j).kind == jsString || s.is(j, ";"):
+			return j
+		case j > i+1 && s.tok(j).nl && !s.continues(j-1, j) && !s.is(j, "from"):
+			return j - 1
 		}
+	}
+	return j
+}
 
-		imp := ImportStatement{
-			ModulePath: modulePath,
-			Symbols:    symbols,
-			IsRelative: strings.HasPrefix(modulePath, "."),
-			StartLine:  lineNum,
-		}
+// variableDeclaration strips the type annotation of the variable declared
+// at index i.
+func (s *tsStripper) variableDeclaration(i int) {
+	j := i + 1
+	switch {
+	case s.is(j, "{") || s.is(j, "["):
+		j = s.matching(j)
+	case s.ident(j) == "":
+		return
+	}
+	j++
+	if s.is(j, "!") {
+		s.drop[j] = true
+		j++
+	}
+	if s.is(j, ":") {
+		end := s.typeEnd(j + 1)
+		s.typeRefs(j, end, "variable")
+		s.dropRange(j, end-1)
+	}
+}
 
-		result.Imports = append(result.Imports, imp)
+// assertion drops the type assertion or satisfies clause at index i
+// (value as T, value satisfies T).
+func (s *tsStripper) assertion(i int) {
+	prev := s.tok(s.prev(i))
+	switch prev.kind {
+	case jsIdent:
+		if jsExpressionKeywords[prev.text] {
+			return
+		}
+	case jsPunct:
+		if prev.text != ")" && prev.text != "]" && prev.text != "}" {
+			return
+		}
+	case jsEOF:
+		return
+	}
+	if end := s.typeEnd(i + 1); end > i+1 {
+		s.dropRange(i, end-1)
 	}
 }
 
-func (p *TypeScriptParser) parseCalls(source, filePath string, result *ParseResult) {
-	matches := p.callRegex.FindAllStringSubmatch(source, -1)
-	for _, match := range matches {
-		if len(match) < 2 {
+// typeRefs records the types named between the tokens at indices from and
+// to, exclusive, and returns the main one: the type a field or variable
+// with the annotation holds.
+func (s *tsStripper) typeRefs(from, to int, role string) string {
+	main := ""
+	for j := from + 1; j < to && j < len(s.toks); j++ {
+		t := s.toks[j]
+		if t.kind != jsIdent || tsTypeKeywords[t.text] || s.is(j+1, ".") {
 			continue
 		}
-
-		name := match[1]
-
-		// Skip keywords and common non-function calls
-		if name == "if" || name == "for" || name == "while" || name == "switch" ||
-			name == "catch" || name == "function" || name == "class" || name == "return" {
+		// Member and parameter names: { name: T; find(id: K): V }, x is T
+		if s.is(j+1, ":") || s.is(j+1, "?") || s.is(j+1, "(") || s.is(j+1, "is") {
 			continue
 		}
-
-		// Find line number
-		lineNum := 1
-		for i := 0; i < strings.Index(source, match[0]); i++ {
-			if source[i] == '\n' {
-				lineNum++
-			}
+		s.result.TypeRefs = append(s.result.TypeRefs, TypeAnnotation{Name: t.text, Role: role, StartLine: t.line})
+		if main == "" && !tsTypeWrappers[t.text] {
+			main = t.text
 		}
+	}
+	return main
+}
 
-		call := CallSite{
-			Name:      name,
-			StartLine: lineNum,
-			EndLine:   lineNum,
+// typeEnd returns the index of the first token after the type starting at
+// index j.
+func (s *tsStripper) typeEnd(j int) int {
+	for {
+		for s.is(j, "|") || s.is(j, "&") || tsTypeOperators[s.ident(j)] && s.startsType(j+1) {
+			j++
+		}
+		j = s.primaryTypeEnd(j)
+		// Array types and indexed access types: T[], T["key"]
+		for s.is(j, "[") && !s.tok(j).nl {
+			j = s.matching(j) + 1
+		}
+		switch {
+		case s.is(j, "|") || s.is(j, "&"):
+			j++
+			continue
+		case s.is(j, "is"):
+			// Type predicates: value is T
+			j++
+			continue
+		case s.is(j, "extends") && !s.tok(j).nl:
+			// Conditional types: T extends U ? X : Y
+			j = s.typeEnd(j + 1)
+			if s.is(j, "?") {
+				j = s.typeEnd(j + 1)
+				if s.is(j, ":") {
+					j = s.typeEnd(j + 1)
+				}
+			}
 		}
+		return j
+	}
+}
 
-		result.Calls = append(result.Calls, call)
+// startsType reports whether a type can start at index j.
+func (s *tsStripper) startsType(j int) bool {
+	switch t := s.tok(j); t.kind {
+	case jsIdent, jsString, jsNumber, jsTemplate:
+		return true
+	case jsPunct:
+		return t.text == "(" || t.text == "[" || t.text == "{" || t.text == "<" || t.text == "-"
 	}
+	return false
 }
 
-func (p *TypeScriptParser) extractTypeRefs(params, returnType, filePath string, lineNum int, result *ParseResult) {
-	// Extract type references from parameters
-	if params != "" {
-		typeRegex := regexp.MustCompile(`(\w+)\s*:\s*(\w+)`)
-		matches := typeRegex.FindAllStringSubmatch(params, -1)
-		for _, match := range matches {
-			if len(match) > 2 {
-				result.TypeRefs = append(result.TypeRefs, TypeAnnotation{
-					Name:      match[2],
-					Role:      "param",
-					StartLine: lineNum,
-				})
+// primaryTypeEnd returns the index of the first token after the type
+// starting at index j, not counting union, intersection, array and
+// conditional types.
+func (s *tsStripper) primaryTypeEnd(j int) int {
+	t := s.tok(j)
+	switch {
+	case s.is(j, "("):
+		// Parenthesized and function types: (a: A) => B
+		closeParen := s.matching(j)
+		if s.is(closeParen+1, "=>") {
+			return s.typeEnd(closeParen + 2)
+		}
+		return closeParen + 1
+	case s.is(j, "<"):
+		// Generic function types: <T>(x: T) => T
+		if end := s.typeArgsEnd(j); end >= 0 && s.is(end+1, "(") {
+			return s.primaryTypeEnd(end + 1)
+		}
+		return j
+	case s.is(j, "{") || s.is(j, "["):
+		return s.matching(j) + 1
+	case s.is(j, "-") && s.tok(j+1).kind == jsNumber:
+		return j + 2
+	case t.kind == jsString || t.kind == jsNumber:
+		return j + 1
+	case t.kind == jsTemplate:
+		k := j + 1
+		for k < len(s.toks) && s.toks[k].pos < t.end {
+			k++
+		}
+		return k
+	case t.kind == jsIdent:
+		k := j + 1
+		if t.text == "import" && s.is(k, "(") {
+			// import('./module').Type
+			k = s.matching(k) + 1
+			if s.is(k, ".") {
+				_, k = s.dottedName(k + 1)
+			}
+		}
+		for s.is(k, ".") && s.ident(k+1) != "" {
+			k += 2
+		}
+		if s.is(k, "<") && !s.tok(k).nl {
+			if end := s.typeArgsEnd(k); end >= 0 {
+				k = end + 1
 			}
 		}
+		return k
 	}
+	return j
+}
 
-	// Extract type reference from return type
-	if returnType != "" {
-		result.TypeRefs = append(result.TypeRefs, TypeAnnotation{
-			Name:      returnType,
-			Role:      "return",
-			StartLine: lineNum,
-		})
+// typeArgsEnd returns the index of the '>' closing the type parameters or
+// arguments opened at index i, or -1 when the '<' is not one.
+func (s *tsStripper) typeArgsEnd(i int) int {
+	depth := 0
+	for j := i; j < len(s.toks); j++ {
+		t := s.toks[j]
+		switch t.kind {
+		case jsIdent, jsString, jsNumber:
+			continue
+		case jsTemplate:
+			for j+1 < len(s.toks) && s.toks[j+1].pos < t.end {
+				j++
+			}
+			continue
+		case jsPunct:
+		default:
+			return -1
+		}
+		switch t.text {
+		case "<":
+			depth++
+		case ">":
+			depth--
+		case ">>":
+			depth -= 2
+		case ">>>":
+			depth -= 3
+		case "(", "[", "{":
+			j = s.matching(j)
+		case ",", ".", "|", "&", "?", ":", "=>", "=", "...", "-":
+		default:
+			return -1
+		}
+		if depth < 0 {
+			return -1
+		}
+		if depth == 0 {
+			return j
+		}
 	}
+	return -1
 }
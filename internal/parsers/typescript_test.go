@@ -40,9 +40,9 @@ const add = (a: number, b: number): number => a + b;
 		result, err := parser.Parse("test.ts", content)
 		require.NoError(t, err)
 
-		// Arrow functions may not be detected by simple regex
-		// At least verify the parser doesn't crash
-		assert.NotNil(t, result)
+		require.Len(t, result.Symbols, 1)
+		assert.Equal(t, "add", result.Symbols[0].Name)
+		assert.Equal(t, graph.NodeFunction, result.Symbols[0].Kind)
 	})
 
 	t.Run("ParseClass", func(t *testing.T) {
@@ -218,3 +218,272 @@ func TestTypeScriptParser_FileDetection(t *testing.T) {
 		})
 	}
 }
+
+func TestTypeScriptParser_EnclosingSymbol(t *testing.T) {
+	t.Parallel()
+
+	content := []byte(`
+export class UserService {
+    getUser(id: number): User {
+        return this.db.find(id);
+    }
+
+    deleteUser(id: number): void {
+        audit("delete");
+        this.db.remove(id);
+    }
+}
+
+function bootstrap(): void {
+    const service = createService();
+}
+
+bootstrap();
+`)
+	parser := NewTypeScriptParser()
+	result, err := parser.Parse("service.ts", content)
+	require.NoError(t, err)
+
+	methods := make(map[string]ParsedSymbol)
+	for _, sym := range result.Symbols {
+		if sym.Kind == graph.NodeMethod {
+			methods[sym.Name] = sym
+		}
+	}
+	require.Contains(t, methods, "getUser")
+	require.Contains(t, methods, "deleteUser", "methods after the first method body are found")
+	assert.NotContains(t, methods, "find", "calls in method bodies are not methods")
+	assert.Equal(t, 3, methods["getUser"].StartLine)
+	assert.Equal(t, 5, methods["getUser"].EndLine)
+
+	enclosing := make(map[string][2]string)
+	for _, call := range result.Calls {
+		enclosing[call.Name] = [2]string{call.Enclosing, call.EnclosingClass}
+	}
+	assert.Equal(t, [2]string{"getUser", "UserService"}, enclosing["find"])
+	assert.Equal(t, [2]string{"deleteUser", "UserService"}, enclosing["audit"])
+	assert.Equal(t, [2]string{"bootstrap", ""}, enclosing["createService"])
+
+	var topLevel bool
+	for _, call := range result.Calls {
+		if call.Name == "bootstrap" && call.StartLine == 17 {
+			topLevel = call.Enclosing == ""
+		}
+	}
+	assert.True(t, topLevel, "top-level call has no enclosing symbol")
+}
+
+func TestTypeScriptParser_Structure(t *testing.T) {
+	t.Parallel()
+
+	t.Run("TypeDeclarations", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`export interface User extends Entity<string>, Named {
+    id: UserID;
+    find(id: string): Promise<User>;
+}
+
+export type UserID = string | number;
+
+export enum Color {
+    Red = "RED",
+}
+
+declare module "legacy" {
+    export function old(): void;
+}
+`)
+		result, err := NewTypeScriptParser().Parse("types.ts", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		require.Len(t, symbols, 3, "ambient declarations are not symbols")
+		assert.Equal(t, graph.NodeInterface, symbols["User"].Kind)
+		assert.Equal(t, 1, symbols["User"].StartLine)
+		assert.Equal(t, 4, symbols["User"].EndLine)
+		assert.Equal(t, graph.NodeTypeAlias, symbols["UserID"].Kind)
+		assert.Equal(t, "type UserID = string | number", symbols["UserID"].Signature)
+		assert.Equal(t, graph.NodeEnum, symbols["Color"].Kind)
+		assert.Equal(t, 10, symbols["Color"].EndLine)
+
+		require.Len(t, result.Heritage, 1)
+		assert.Equal(t, []string{"Entity", "Named"}, result.Heritage[0].Extends)
+	})
+
+	t.Run("NamespacesAndOverloads", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`export namespace Utils.Strings {
+    export function trim(s: string): string {
+        return strip(s);
+    }
+    function strip(s: string): string { return s; }
+}
+
+export class Parser {
+    parse(input: string): Node;
+    parse(input: Buffer): Node;
+    parse(input: string | Buffer): Node {
+        return build(input);
+    }
+
+    abstract reset(): void;
+}
+`)
+		result, err := NewTypeScriptParser().Parse("utils.ts", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		require.Contains(t, symbols, "Utils.Strings.trim", "namespace functions are qualified by the namespace")
+		assert.Equal(t, graph.NodeMethod, symbols["Utils.Strings.trim"].Kind)
+		assert.True(t, symbols["Utils.Strings.trim"].IsExported)
+		assert.Equal(t, 2, symbols["Utils.Strings.trim"].StartLine)
+		assert.False(t, symbols["Utils.Strings.strip"].IsExported)
+		require.Contains(t, symbols, "Parser.parse")
+		assert.Equal(t, 11, symbols["Parser.parse"].StartLine, "overload signatures are not the implementation")
+		assert.Equal(t, 13, symbols["Parser.parse"].EndLine)
+		assert.NotContains(t, symbols, "Parser.reset", "abstract methods have no body")
+
+		calls := make(map[string]CallSite)
+		for _, call := range result.Calls {
+			calls[call.Name] = call
+		}
+		assert.Equal(t, "trim", calls["strip"].Enclosing)
+		assert.Equal(t, "Utils.Strings", calls["strip"].Receiver, "calls within a namespace are qualified")
+		assert.Equal(t, "parse", calls["build"].Enclosing)
+	})
+
+	t.Run("ObjectLiteralMethods", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`export const api = {
+    save: async (x: Item) => {
+        await post("/items", x);
+    },
+    load(id: string) {
+        return get(id);
+    },
+    retries: 3,
+};
+
+const config = { nested: { f() {} } };
+`)
+		result, err := NewTypeScriptParser().Parse("api.ts", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		require.Len(t, symbols, 2, "only functions are members")
+		require.Contains(t, symbols, "api.save")
+		assert.Equal(t, graph.NodeMethod, symbols["api.save"].Kind)
+		assert.Equal(t, "api", symbols["api.save"].ClassName)
+		assert.True(t, symbols["api.save"].IsExported)
+		assert.Equal(t, 2, symbols["api.save"].StartLine)
+		assert.Equal(t, 4, symbols["api.save"].EndLine)
+		assert.Equal(t, 5, symbols["api.load"].StartLine)
+
+		enclosing := make(map[string][2]string)
+		for _, call := range result.Calls {
+			enclosing[call.Name] = [2]string{call.Enclosing, call.EnclosingClass}
+		}
+		assert.Equal(t, [2]string{"save", "api"}, enclosing["post"])
+		assert.Equal(t, [2]string{"load", "api"}, enclosing["get"])
+	})
+
+	t.Run("GenericsAndDecorators", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`@Injectable({ providedIn: "root" })
+export class UserService<T extends object = {}> extends Base<Map<string, Array<number>>> implements IService {
+    private readonly cache: Map<string, User> = new Map<string, User>();
+
+    constructor(private readonly repo: Repository) {
+        super();
+    }
+
+    @Get(":id")
+    public async find(id: string): Promise<User | undefined> {
+        const user = this.repo.load<User>(id as string);
+        return this.cache.get(user!.id);
+    }
+}
+`)
+		result, err := NewTypeScriptParser().Parse("service.ts", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.Equal(t, []string{"Injectable"}, symbols["UserService"].Decorators)
+		assert.Equal(t, 14, symbols["UserService"].EndLine)
+		assert.Equal(t, []string{"Get"}, symbols["UserService.find"].Decorators)
+		assert.Equal(t, 10, symbols["UserService.find"].StartLine)
+		assert.Contains(t, symbols["UserService.find"].Signature, ": Promise<User | undefined>")
+
+		require.Len(t, result.Heritage, 1)
+		assert.Equal(t, []string{"Base"}, result.Heritage[0].Extends)
+		assert.Equal(t, []string{"IService"}, result.Heritage[0].Implements)
+
+		receivers := make(map[string]string)
+		for _, call := range result.Calls {
+			receivers[call.Name] = call.Receiver
+		}
+		assert.Equal(t, "Repository", receivers["load"], "constructor parameter properties are typed fields")
+		assert.Equal(t, "Map", receivers["get"])
+	})
+
+	t.Run("ExportsAndReExports", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`import type { Config } from "./config";
+import fs = require("fs");
+export * from "./models";
+export { helper as h } from "./helpers";
+
+export default function main(args: string[]): void {
+    run(args);
+}
+`)
+		result, err := NewTypeScriptParser().Parse("index.ts", content)
+		require.NoError(t, err)
+
+		imports := importsByModule(result)
+		assert.Equal(t, []string{"Config"}, imports["./config"])
+		assert.Equal(t, []string{"fs"}, imports["fs"])
+		assert.Contains(t, imports, "./models")
+		assert.Equal(t, []string{"helper"}, imports["./helpers"])
+
+		symbols := symbolsByName(result)
+		assert.True(t, symbols["main"].IsExported)
+	})
+
+	t.Run("Components", func(t *testing.T) {
+		t.Parallel()
+
+		content := []byte(`export const List = <T,>({ items }: { items: T[] }) => {
+    return <ul>{items.map((item) => <li>{render(item)}</li>)}</ul>;
+};
+
+export default function App({ title }: Props): JSX.Element {
+    return <List items={[title]} />;
+}
+
+export class Legacy extends React.Component<Props> {
+    render() {
+        return <span>{this.props.title as string}</span>;
+    }
+}
+
+function format(x: number): string {
+    return String(x);
+}
+`)
+		result, err := NewTypeScriptParser().Parse("app.tsx", content)
+		require.NoError(t, err)
+
+		symbols := symbolsByName(result)
+		assert.True(t, symbols["List"].IsComponent, "generic arrow components are parsed in TSX")
+		assert.Equal(t, 3, symbols["List"].EndLine)
+		assert.True(t, symbols["App"].IsComponent)
+		assert.True(t, symbols["Legacy"].IsComponent)
+		assert.False(t, symbols["format"].IsComponent)
+	})
+}
@@ -61,6 +61,75 @@ type HybridSearchResult struct {
 	Snippet string
 }
 
+// FileRecord is the stored state of an indexed file, which lets the next
+// run skip parsing the file when its content has not changed.
+type FileRecord struct {
+	// Path is the file path relative to the repository root.
+	Path string `json:"path"`
+
+	// SHA256 is the hash of the content the file was parsed from.
+	SHA256 string `json:"sha256"`
+
+	// Version identifies the format of Data; records of another version
+	// are ignored.
+	Version int `json:"version"`
+
+	// Data is the serialized parse result of the file.
+	Data []byte `json:"data"`
+
+	// GoTypes is the serialized result of type-checking a Go file, kept so
+	// that a run in which no Go file changed need not load the packages.
+	GoTypes []byte `json:"go_types,omitempty"`
+}
+
+// Properties set by Traverse on the nodes it returns.
+const (
+	// PropTraversalDepth is the number of calls between a node and the
+	// start of the traversal (int).
+	PropTraversalDepth = "traversal_depth"
+
+	// PropConfidence is the product of the confidences of the
+	// relationships on the path to a node (float64).
+	PropConfidence = "confidence"
+
+	// PropViaInterfaceDispatch is true for nodes that are only reached
+	// through a call to an interface method (bool).
+	PropViaInterfaceDispatch = "via_interface_dispatch"
+)
+
+// GroupByTraversal splits the result of Traverse into the nodes reached
+// through direct calls, grouped by traversal depth, and the nodes only
+// reached through interface dispatch. Nodes without a recorded depth are
+// treated as direct neighbours.
+func GroupByTraversal(nodes []*graph.GraphNode) (byDepth map[int][]*graph.GraphNode, viaDispatch []*graph.GraphNode) {
+	byDepth = make(map[int][]*graph.GraphNode)
+	for _, node := range nodes {
+		if virtual, _ := node.Properties[PropViaInterfaceDispatch].(bool); virtual {
+			viaDispatch = append(viaDispatch, node)
+			continue
+		}
+		d := TraversalDepth(node)
+		byDepth[d] = append(byDepth[d], node)
+	}
+	return byDepth, viaDispatch
+}
+
+// TraversalDepth returns the depth at which Traverse reached a node.
+func TraversalDepth(node *graph.GraphNode) int {
+	if d, ok := node.Properties[PropTraversalDepth].(int); ok && d > 0 {
+		return d
+	}
+	return 1
+}
+
+// TraversalConfidence returns the path confidence Traverse recorded for a node.
+func TraversalConfidence(node *graph.GraphNode) float64 {
+	if c, ok := node.Properties[PropConfidence].(float64); ok {
+		return c
+	}
+	return 1.0
+}
+
 // StorageBackend defines the interface for storage implementations.
 //
 // Implementations must be thread-safe and support concurrent access.
@@ -76,9 +145,25 @@ type StorageBackend interface {
 
 	// Bulk operations
 
-	// BulkLoad replaces the entire store with the contents of the graph.
+	// BulkLoad replaces the stored graph with the contents of g. Nodes
+	// and relationships that g does not contain are dropped, together
+	// with their indexes and embeddings; file records are kept.
 	BulkLoad(ctx context.Context, g *graph.KnowledgeGraph) error
 
+	// UpdateGraph replaces the stored graph with the contents of g, like
+	// BulkLoad, but only writes the nodes and relationships that differ
+	// from the stored ones and deletes the ones g no longer contains.
+	UpdateGraph(ctx context.Context, g *graph.KnowledgeGraph) error
+
+	// File records
+
+	// FileRecords returns the records of the indexed files, keyed by path.
+	FileRecords(ctx context.Context) (map[string]FileRecord, error)
+
+	// ReplaceFileRecords replaces the stored file records with the given
+	// ones.
+	ReplaceFileRecords(ctx context.Context, records []FileRecord) error
+
 	// Node operations
 
 	// AddNodes inserts nodes into the storage.
@@ -107,8 +192,14 @@ type StorageBackend interface {
 	// GetCallees returns nodes called by the given node.
 	GetCallees(ctx context.Context, nodeID string) ([]*graph.GraphNode, error)
 
-	// Traverse performs BFS traversal through CALLS edges.
-	// Direction should be "callers" or "callees".
+	// GetDocuments returns the document sections that DOCUMENT the given
+	// node.
+	GetDocuments(ctx context.Context, nodeID string) ([]*graph.GraphNode, error)
+
+	// Traverse performs BFS traversal through CALLS edges, following
+	// interface dispatch through method-level IMPLEMENTS edges.
+	// Direction should be "callers" or "callees". Returned nodes carry
+	// PropTraversalDepth, PropConfidence and PropViaInterfaceDispatch.
 	Traverse(ctx context.Context, startID string, depth int, direction string) ([]*graph.GraphNode, error)
 
 	// Search
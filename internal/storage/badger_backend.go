@@ -2,8 +2,10 @@
 package storage
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"sort"
@@ -22,6 +24,7 @@ const (
 	prefixIncoming  = "i:in:"  // incoming relationships
 	prefixOutgoing  = "i:out:" // outgoing relationships
 	prefixEmbedding = "e:"     // embedding data
+	prefixFile      = "f:"     // indexed file records
 )
 
 // BadgerBackend is a BadgerDB-backed storage implementation.
@@ -118,58 +121,418 @@ func (b *BadgerBackend) Close() error {
 	return err
 }
 
-// BulkLoad replaces the entire store with the contents of the graph.
+// graphPrefixes are the key prefixes of the stored graph, which BulkLoad
+// replaces; the file records are kept.
+var graphPrefixes = []string{prefixNode, prefixRel, prefixIncoming, prefixOutgoing, prefixEmbedding}
+
+// BulkLoad replaces the stored graph with the contents of g: the nodes,
+// relationships, adjacency indexes and embeddings stored before are
+// deleted, and the space they took is reclaimed afterwards.
+//
+// Nothing is deleted before g is written: the new graph is written next to
+// the stored one, and the keys it does not overwrite are deleted once it is
+// flushed. If ctx is canceled first or g cannot be written, the stored
+// graph stays in place, possibly with part of g written over it.
 func (b *BadgerBackend) BulkLoad(ctx context.Context, g *graph.KnowledgeGraph) error {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	wb := b.db.NewWriteBatch()
 	defer wb.Cancel()
 
-	// Reset counts
-	b.nodeCount = 0
-	b.relationshipCount = 0
-	b.ftsIndex = make(map[string][]string)
+	written := make(map[string]bool)
+	set := func(key, value []byte) error {
+		written[string(key)] = true
+		return wb.Set(key, value)
+	}
 
 	// Add nodes
+	nodeCount := 0
 	for node := range g.IterNodes() {
 		data, err := json.Marshal(node)
 		if err != nil {
 			return fmt.Errorf("marshaling node: %w", err)
 		}
-		if err := wb.Set(b.nodeKey(node.ID), data); err != nil {
+		if err := set(b.nodeKey(node.ID), data); err != nil {
 			return fmt.Errorf("setting node: %w", err)
 		}
-		b.nodeCount++
-
-		// Build FTS index for this node
-		b.indexNodeForFTS(node)
+		nodeCount++
 	}
 
 	// Add relationships
+	relationshipCount := 0
 	for rel := range g.IterRelationships() {
 		data, err := json.Marshal(rel)
 		if err != nil {
 			return fmt.Errorf("marshaling relationship: %w", err)
 		}
-		if err := wb.Set(b.relKey(rel.ID), data); err != nil {
+		if err := set(b.relKey(rel.ID), data); err != nil {
 			return fmt.Errorf("setting relationship: %w", err)
 		}
-		b.relationshipCount++
+		relationshipCount++
 
 		// Index for adjacency lists
 		if err := b.indexRelationshipWB(wb, rel); err != nil {
 			return err
 		}
+		outKey, inKey := relIndexKeys(rel)
+		written[string(outKey)] = true
+		written[string(inKey)] = true
 	}
 
+	stale, err := b.keysExcept(graphPrefixes, written)
+	if err != nil {
+		return err
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
 	if err := wb.Flush(); err != nil {
+		return fmt.Errorf("writing graph: %w", err)
+	}
+
+	// The new graph is stored; the rest of the old one can go
+	if len(stale) > 0 {
+		del := b.db.NewWriteBatch()
+		defer del.Cancel()
+		for _, key := range stale {
+			if err := del.Delete(key); err != nil {
+				return fmt.Errorf("deleting %s: %w", key, err)
+			}
+		}
+		if err := del.Flush(); err != nil {
+			return fmt.Errorf("deleting stored graph: %w", err)
+		}
+	}
+
+	b.nodeCount = nodeCount
+	b.relationshipCount = relationshipCount
+	b.ftsIndex = make(map[string][]string)
+	for node := range g.IterNodes() {
+		b.indexNodeForFTS(node)
+	}
+
+	return b.collectGarbage()
+}
+
+// keysExcept returns the stored keys under the given prefixes that are not
+// in keep.
+func (b *BadgerBackend) keysExcept(prefixes []string, keep map[string]bool) ([][]byte, error) {
+	var keys [][]byte
+	err := b.db.View(func(txn *badger.Txn) error {
+		for _, prefix := range prefixes {
+			opts := badger.DefaultIteratorOptions
+			opts.Prefix = []byte(prefix)
+			opts.PrefetchValues = false
+			it := txn.NewIterator(opts)
+			for it.Rewind(); it.Valid(); it.Next() {
+				if key := it.Item().Key(); !keep[string(key)] {
+					keys = append(keys, it.Item().KeyCopy(nil))
+				}
+			}
+			it.Close()
+		}
+		return nil
+	})
+	return keys, err
+}
+
+// CopyGraph writes the stored nodes, relationships, adjacency indexes and
+// embeddings into dst as they are, inconsistent entries included. File
+// records are not copied.
+func (b *BadgerBackend) CopyGraph(ctx context.Context, dst *BadgerBackend) error {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	dst.mu.Lock()
+	defer dst.mu.Unlock()
+
+	wb := dst.db.NewWriteBatch()
+	defer wb.Cancel()
+
+	err := b.db.View(func(txn *badger.Txn) error {
+		for _, prefix := range graphPrefixes {
+			if err := iteratePrefix(txn, prefix, func(key string, value []byte) error {
+				if err := ctx.Err(); err != nil {
+					return err
+				}
+				return wb.Set([]byte(key), value)
+			}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("copying graph: %w", err)
+	}
+	if err := wb.Flush(); err != nil {
+		return fmt.Errorf("writing graph: %w", err)
+	}
+
+	dst.rebuildFTSIndexFromDB()
+	return nil
+}
+
+// collectGarbage rewrites the value log files in which at least half of
+// the values are deleted or overwritten, until there are none left.
+func (b *BadgerBackend) collectGarbage() error {
+	for {
+		err := b.db.RunValueLogGC(0.5)
+		if errors.Is(err, badger.ErrNoRewrite) || errors.Is(err, badger.ErrRejected) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("collecting value log garbage: %w", err)
+		}
+	}
+}
+
+// UpdateGraph replaces the stored graph with the contents of g, writing
+// only the nodes and relationships that differ from the stored ones and
+// deleting the ones g no longer contains, together with their indexes and
+// embeddings. The changes are written in a single transaction, unless they
+// are larger than Badger allows in one.
+func (b *BadgerBackend) UpdateGraph(ctx context.Context, g *graph.KnowledgeGraph) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	nodes := make(map[string][]byte)
+	for node := range g.IterNodes() {
+		data, err := json.Marshal(node)
+		if err != nil {
+			return fmt.Errorf("marshaling node: %w", err)
+		}
+		nodes[string(b.nodeKey(node.ID))] = data
+	}
+	rels := make(map[string][]byte)
+	relsByKey := make(map[string]*graph.GraphRelationship)
+	for rel := range g.IterRelationships() {
+		data, err := json.Marshal(rel)
+		if err != nil {
+			return fmt.Errorf("marshaling relationship: %w", err)
+		}
+		key := string(b.relKey(rel.ID))
+		rels[key] = data
+		relsByKey[key] = rel
+	}
+
+	oldNodes, err := b.diffPrefix(prefixNode, nodes)
+	if err != nil {
+		return err
+	}
+	oldRels, err := b.diffPrefix(prefixRel, rels)
+	if err != nil {
 		return err
 	}
 
+	txn := newUpdateTxn(b.db)
+	defer txn.discard()
+
+	for key := range oldNodes {
+		if _, ok := nodes[key]; ok {
+			continue
+		}
+		nodeID := strings.TrimPrefix(key, prefixNode)
+		if err := txn.delete([]byte(key)); err != nil {
+			return fmt.Errorf("deleting node: %w", err)
+		}
+		if err := txn.delete([]byte(prefixEmbedding + nodeID)); err != nil {
+			return fmt.Errorf("deleting embedding: %w", err)
+		}
+	}
+	for key, data := range nodes {
+		if err := txn.set([]byte(key), data); err != nil {
+			return fmt.Errorf("setting node: %w", err)
+		}
+	}
+
+	// A changed relationship may connect other nodes than before, so the
+	// indexes of every old version are dropped
+	for key, data := range oldRels {
+		var rel graph.GraphRelationship
+		if err := json.Unmarshal(data, &rel); err != nil {
+			return fmt.Errorf("unmarshaling relationship: %w", err)
+		}
+		outKey, inKey := relIndexKeys(&rel)
+		if err := txn.delete(outKey); err != nil {
+			return fmt.Errorf("deleting outgoing index: %w", err)
+		}
+		if err := txn.delete(inKey); err != nil {
+			return fmt.Errorf("deleting incoming index: %w", err)
+		}
+		if _, ok := rels[key]; !ok {
+			if err := txn.delete([]byte(key)); err != nil {
+				return fmt.Errorf("deleting relationship: %w", err)
+			}
+		}
+	}
+	for key, data := range rels {
+		if err := txn.set([]byte(key), data); err != nil {
+			return fmt.Errorf("setting relationship: %w", err)
+		}
+		outKey, inKey := relIndexKeys(relsByKey[key])
+		if err := txn.set(outKey, []byte(relsByKey[key].ID)); err != nil {
+			return fmt.Errorf("setting outgoing index: %w", err)
+		}
+		if err := txn.set(inKey, []byte(relsByKey[key].ID)); err != nil {
+			return fmt.Errorf("setting incoming index: %w", err)
+		}
+	}
+
+	if err := txn.commit(); err != nil {
+		return err
+	}
+
+	b.rebuildFTSIndexFromDB()
 	return nil
 }
 
+// diffPrefix compares the values stored under a key prefix with the wanted
+// ones. It removes the entries that are stored unchanged from want, and
+// returns the stored values of the keys that are not wanted or differ.
+func (b *BadgerBackend) diffPrefix(prefix string, want map[string][]byte) (map[string][]byte, error) {
+	old := make(map[string][]byte)
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefix)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			key := string(item.Key())
+			value, err := item.ValueCopy(nil)
+			if err != nil {
+				return fmt.Errorf("reading %s: %w", key, err)
+			}
+			if data, ok := want[key]; ok && bytes.Equal(data, value) {
+				delete(want, key)
+				continue
+			}
+			old[key] = value
+		}
+		return nil
+	})
+	return old, err
+}
+
+// updateTxn is a read-write transaction that is committed and renewed when
+// it grows larger than Badger allows, so that a large update is split
+// into as few transactions as possible.
+type updateTxn struct {
+	db  *badger.DB
+	txn *badger.Txn
+}
+
+// newUpdateTxn starts an update of db.
+func newUpdateTxn(db *badger.DB) *updateTxn {
+	return &updateTxn{db: db, txn: db.NewTransaction(true)}
+}
+
+// set writes a key, renewing the transaction if it is full.
+func (u *updateTxn) set(key, value []byte) error {
+	return u.apply(func(txn *badger.Txn) error { return txn.Set(key, value) })
+}
+
+// delete removes a key, renewing the transaction if it is full.
+func (u *updateTxn) delete(key []byte) error {
+	return u.apply(func(txn *badger.Txn) error { return txn.Delete(key) })
+}
+
+func (u *updateTxn) apply(op func(txn *badger.Txn) error) error {
+	err := op(u.txn)
+	if !errors.Is(err, badger.ErrTxnTooBig) {
+		return err
+	}
+	if err := u.txn.Commit(); err != nil {
+		return err
+	}
+	u.txn = u.db.NewTransaction(true)
+	return op(u.txn)
+}
+
+// commit commits the pending writes.
+func (u *updateTxn) commit() error {
+	return u.txn.Commit()
+}
+
+// discard releases the transaction; it is a no-op after commit.
+func (u *updateTxn) discard() {
+	u.txn.Discard()
+}
+
+// FileRecords returns the records of the indexed files, keyed by path.
+func (b *BadgerBackend) FileRecords(ctx context.Context) (map[string]FileRecord, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	records := make(map[string]FileRecord)
+	err := b.db.View(func(txn *badger.Txn) error {
+		opts := badger.DefaultIteratorOptions
+		opts.Prefix = []byte(prefixFile)
+		it := txn.NewIterator(opts)
+		defer it.Close()
+
+		for it.Rewind(); it.Valid(); it.Next() {
+			var record FileRecord
+			if err := it.Item().Value(func(val []byte) error {
+				return json.Unmarshal(val, &record)
+			}); err != nil {
+				return fmt.Errorf("unmarshaling file record: %w", err)
+			}
+			records[record.Path] = record
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return records, nil
+}
+
+// ReplaceFileRecords replaces the stored file records with the given ones,
+// writing only the records that changed.
+func (b *BadgerBackend) ReplaceFileRecords(ctx context.Context, records []FileRecord) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	want := make(map[string][]byte, len(records))
+	for _, record := range records {
+		data, err := json.Marshal(record)
+		if err != nil {
+			return fmt.Errorf("marshaling file record: %w", err)
+		}
+		want[prefixFile+record.Path] = data
+	}
+
+	old, err := b.diffPrefix(prefixFile, want)
+	if err != nil {
+		return err
+	}
+
+	txn := newUpdateTxn(b.db)
+	defer txn.discard()
+
+	for key := range old {
+		if _, ok := want[key]; ok {
+			continue
+		}
+		if err := txn.delete([]byte(key)); err != nil {
+			return fmt.Errorf("deleting file record: %w", err)
+		}
+	}
+	for key, data := range want {
+		if err := txn.set([]byte(key), data); err != nil {
+			return fmt.Errorf("setting file record: %w", err)
+		}
+	}
+	return txn.commit()
+}
+
 // indexNodeForFTS adds a node to the FTS index.
 func (b *BadgerBackend) indexNodeForFTS(node *graph.GraphNode) {
 	// Index by name
@@ -197,6 +560,18 @@ func (b *BadgerBackend) indexNodeForFTS(node *graph.GraphNode) {
 			b.ftsIndex[token] = append(b.ftsIndex[token], node.ID)
 		}
 	}
+
+	// Index by documentation (first 500 chars)
+	if len(node.Doc) > 0 {
+		doc := node.Doc
+		if len(doc) > 500 {
+			doc = doc[:500]
+		}
+		tokens = tokenizeForFTS(doc)
+		for _, token := range tokens {
+			b.ftsIndex[token] = append(b.ftsIndex[token], node.ID)
+		}
+	}
 }
 
 // tokenizeForFTS splits text into searchable tokens.
@@ -237,81 +612,104 @@ func (b *BadgerBackend) AddNodes(ctx context.Context, nodes []*graph.GraphNode)
 	return txn.Commit()
 }
 
-// RemoveNodesByFile deletes all nodes whose file path matches.
+// RemoveNodesByFile deletes all nodes whose file path matches, with their
+// relationships of every type, the adjacency index entries of those and
+// their embeddings.
 func (b *BadgerBackend) RemoveNodesByFile(ctx context.Context, filePath string) (int, error) {
 	b.mu.Lock()
 	defer b.mu.Unlock()
 
-	count := 0
-	txn := b.db.NewTransaction(true)
-	defer txn.Discard()
-
-	// Find all nodes with this file path
-	opts := badger.DefaultIteratorOptions
-	opts.Prefix = []byte(prefixNode)
-	it := txn.NewIterator(opts)
-
-	var keysToDelete [][]byte
-	var relIDsToDelete [][]byte
-
-	for it.Rewind(); it.Valid(); it.Next() {
-		item := it.Item()
-		var node graph.GraphNode
-		if err := item.Value(func(val []byte) error {
-			return json.Unmarshal(val, &node)
+	// Collect the nodes of the file, and the relationships of every type
+	// that connect them through the adjacency index
+	var nodeIDs []string
+	rels := make(map[string]*graph.GraphRelationship)
+	err := b.db.View(func(txn *badger.Txn) error {
+		if err := iteratePrefix(txn, prefixNode, func(_ string, value []byte) error {
+			var node graph.GraphNode
+			if err := json.Unmarshal(value, &node); err != nil {
+				return fmt.Errorf("unmarshaling node: %w", err)
+			}
+			if node.FilePath == filePath {
+				nodeIDs = append(nodeIDs, node.ID)
+			}
+			return nil
 		}); err != nil {
-			it.Close()
-			return 0, fmt.Errorf("unmarshaling node: %w", err)
+			return err
 		}
 
-		if node.FilePath == filePath {
-			keysToDelete = append(keysToDelete, item.Key())
-			count++
-
-			// Also mark relationships for deletion
-			// We'll find them by scanning relationship indexes
-			inPrefix := []byte(fmt.Sprintf("%s%s:%s", prefixIncoming, node.ID, graph.RelCalls))
-			b.collectRelationshipIDs(txn, inPrefix, &relIDsToDelete)
-
-			outPrefix := []byte(fmt.Sprintf("%s%s:%s", prefixOutgoing, node.ID, graph.RelCalls))
-			b.collectRelationshipIDs(txn, outPrefix, &relIDsToDelete)
+		for _, nodeID := range nodeIDs {
+			for _, prefix := range []string{prefixIncoming, prefixOutgoing} {
+				if err := iteratePrefix(txn, prefix+nodeID+":", func(_ string, value []byte) error {
+					relID := string(value)
+					if _, ok := rels[relID]; ok {
+						return nil
+					}
+					rel, err := b.getRelationship(txn, relID)
+					if err != nil || rel == nil {
+						return err
+					}
+					// Another node's ID may extend this one
+					if rel.Source == nodeID || rel.Target == nodeID {
+						rels[relID] = rel
+					}
+					return nil
+				}); err != nil {
+					return err
+				}
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(nodeIDs) == 0 {
+		return 0, nil
 	}
-	it.Close()
 
-	// Delete nodes
-	for _, key := range keysToDelete {
-		if err := txn.Delete(key); err != nil {
-			return count, fmt.Errorf("deleting node: %w", err)
+	txn := newUpdateTxn(b.db)
+	defer txn.discard()
+
+	for _, nodeID := range nodeIDs {
+		if err := txn.delete(b.nodeKey(nodeID)); err != nil {
+			return 0, fmt.Errorf("deleting node: %w", err)
+		}
+		if err := txn.delete([]byte(prefixEmbedding + nodeID)); err != nil {
+			return 0, fmt.Errorf("deleting embedding: %w", err)
 		}
 	}
-
-	// Delete relationships
-	for _, relKey := range relIDsToDelete {
-		if err := txn.Delete(relKey); err != nil {
-			return count, fmt.Errorf("deleting relationship: %w", err)
+	for relID, rel := range rels {
+		outKey, inKey := relIndexKeys(rel)
+		for _, key := range [][]byte{b.relKey(relID), outKey, inKey} {
+			if err := txn.delete(key); err != nil {
+				return 0, fmt.Errorf("deleting relationship: %w", err)
+			}
 		}
 	}
+	if err := txn.commit(); err != nil {
+		return 0, fmt.Errorf("removing nodes of %s: %w", filePath, err)
+	}
+	b.rebuildFTSIndexFromDB()
 
-	return count, txn.Commit()
+	return len(nodeIDs), nil
 }
 
-// collectRelationshipIDs collects relationship IDs from an index prefix.
-func (b *BadgerBackend) collectRelationshipIDs(txn *badger.Txn, prefix []byte, ids *[][]byte) {
-	opts := badger.DefaultIteratorOptions
-	opts.Prefix = prefix
-	it := txn.NewIterator(opts)
-	defer it.Close()
-
-	for it.Rewind(); it.Valid(); it.Next() {
-		item := it.Item()
-		_ = item.Value(func(val []byte) error {
-			// val contains relationship ID
-			relKey := b.relKey(string(val))
-			*ids = append(*ids, relKey)
-			return nil
-		})
+// getRelationship returns a relationship by ID, or nil if not found.
+func (b *BadgerBackend) getRelationship(txn *badger.Txn, relID string) (*graph.GraphRelationship, error) {
+	item, err := txn.Get(b.relKey(relID))
+	if err == badger.ErrKeyNotFound {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("getting relationship: %w", err)
 	}
+	var rel graph.GraphRelationship
+	if err := item.Value(func(val []byte) error {
+		return json.Unmarshal(val, &rel)
+	}); err != nil {
+		return nil, fmt.Errorf("unmarshaling relationship: %w", err)
+	}
+	return &rel, nil
 }
 
 // GetNode returns a single node by ID, or nil if not found.
@@ -398,17 +796,22 @@ func (b *BadgerBackend) AddRelationships(ctx context.Context, rels []*graph.Grap
 	return txn.Commit()
 }
 
+// relIndexKeys returns the adjacency list keys of a relationship:
+// source -> rel_type -> rel.ID and target -> rel_type -> rel.ID (unique
+// keys per relationship).
+func relIndexKeys(rel *graph.GraphRelationship) (outKey, inKey []byte) {
+	outKey = []byte(fmt.Sprintf("%s%s:%s:%s", prefixOutgoing, rel.Source, rel.Type, rel.ID))
+	inKey = []byte(fmt.Sprintf("%s%s:%s:%s", prefixIncoming, rel.Target, rel.Type, rel.ID))
+	return outKey, inKey
+}
+
 // indexRelationship creates adjacency list indexes for a relationship.
 func (b *BadgerBackend) indexRelationship(txn *badger.Txn, rel *graph.GraphRelationship) error {
-	// Outgoing: source -> rel_type -> rel.ID (unique key per relationship)
-	outKey := fmt.Sprintf("%s%s:%s:%s", prefixOutgoing, rel.Source, rel.Type, rel.ID)
-	if err := txn.Set([]byte(outKey), []byte(rel.ID)); err != nil {
+	outKey, inKey := relIndexKeys(rel)
+	if err := txn.Set(outKey, []byte(rel.ID)); err != nil {
 		return fmt.Errorf("setting outgoing index: %w", err)
 	}
-
-	// Incoming: target -> rel_type -> rel.ID (unique key per relationship)
-	inKey := fmt.Sprintf("%s%s:%s:%s", prefixIncoming, rel.Target, rel.Type, rel.ID)
-	if err := txn.Set([]byte(inKey), []byte(rel.ID)); err != nil {
+	if err := txn.Set(inKey, []byte(rel.ID)); err != nil {
 		return fmt.Errorf("setting incoming index: %w", err)
 	}
 
@@ -417,13 +820,11 @@ func (b *BadgerBackend) indexRelationship(txn *badger.Txn, rel *graph.GraphRelat
 
 // indexRelationshipWB creates adjacency list indexes for a relationship in a write batch.
 func (b *BadgerBackend) indexRelationshipWB(wb *badger.WriteBatch, rel *graph.GraphRelationship) error {
-	outKey := fmt.Sprintf("%s%s:%s:%s", prefixOutgoing, rel.Source, rel.Type, rel.ID)
-	if err := wb.Set([]byte(outKey), []byte(rel.ID)); err != nil {
+	outKey, inKey := relIndexKeys(rel)
+	if err := wb.Set(outKey, []byte(rel.ID)); err != nil {
 		return fmt.Errorf("setting outgoing index: %w", err)
 	}
-
-	inKey := fmt.Sprintf("%s%s:%s:%s", prefixIncoming, rel.Target, rel.Type, rel.ID)
-	if err := wb.Set([]byte(inKey), []byte(rel.ID)); err != nil {
+	if err := wb.Set(inKey, []byte(rel.ID)); err != nil {
 		return fmt.Errorf("setting incoming index: %w", err)
 	}
 
@@ -435,58 +836,14 @@ func (b *BadgerBackend) GetCallers(ctx context.Context, nodeID string) ([]*graph
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	var callers []*graph.GraphNode
-
 	txn := b.db.NewTransaction(false)
 	defer txn.Discard()
 
-	// Get incoming CALLS relationships
-	prefix := fmt.Sprintf("%s%s:%s", prefixIncoming, nodeID, graph.RelCalls)
-	opts := badger.DefaultIteratorOptions
-	opts.Prefix = []byte(prefix)
-	it := txn.NewIterator(opts)
-	defer it.Close()
-
-	for it.Rewind(); it.Valid(); it.Next() {
-		item := it.Item()
-		var relID string
-		if err := item.Value(func(val []byte) error {
-			relID = string(val)
-			return nil
-		}); err != nil {
-			return nil, fmt.Errorf("reading rel ID: %w", err)
-		}
-
-		// Get relationship
-		relItem, err := txn.Get(b.relKey(relID))
-		if err != nil {
-			continue // Skip if relationship not found
-		}
-
-		var rel graph.GraphRelationship
-		if err := relItem.Value(func(val []byte) error {
-			return json.Unmarshal(val, &rel)
-		}); err != nil {
-			continue
-		}
-
-		// Get source node (caller)
-		callerItem, err := txn.Get(b.nodeKey(rel.Source))
-		if err != nil {
-			continue // Skip if caller not found
-		}
-
-		var caller graph.GraphNode
-		if err := callerItem.Value(func(val []byte) error {
-			return json.Unmarshal(val, &caller)
-		}); err != nil {
-			continue
-		}
-
-		callers = append(callers, &caller)
+	edges, err := b.neighbors(txn, nodeID, graph.RelCalls, prefixIncoming)
+	if err != nil {
+		return nil, err
 	}
-
-	return callers, nil
+	return edgeNodes(edges), nil
 }
 
 // GetCallees returns nodes called by the given node.
@@ -494,22 +851,61 @@ func (b *BadgerBackend) GetCallees(ctx context.Context, nodeID string) ([]*graph
 	b.mu.RLock()
 	defer b.mu.RUnlock()
 
-	var callees []*graph.GraphNode
+	txn := b.db.NewTransaction(false)
+	defer txn.Discard()
+
+	edges, err := b.neighbors(txn, nodeID, graph.RelCalls, prefixOutgoing)
+	if err != nil {
+		return nil, err
+	}
+	return edgeNodes(edges), nil
+}
+
+// GetDocuments returns the document sections that mention the given node.
+func (b *BadgerBackend) GetDocuments(ctx context.Context, nodeID string) ([]*graph.GraphNode, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
 
 	txn := b.db.NewTransaction(false)
 	defer txn.Discard()
 
-	// Get outgoing CALLS relationships
-	prefix := fmt.Sprintf("%s%s:%s", prefixOutgoing, nodeID, graph.RelCalls)
+	edges, err := b.neighbors(txn, nodeID, graph.RelDocuments, prefixIncoming)
+	if err != nil {
+		return nil, err
+	}
+	return edgeNodes(edges), nil
+}
+
+// edge is a relationship together with the node at its other end.
+type edge struct {
+	rel  *graph.GraphRelationship
+	node *graph.GraphNode
+}
+
+// edgeNodes returns the nodes at the far end of edges.
+func edgeNodes(edges []edge) []*graph.GraphNode {
+	var nodes []*graph.GraphNode
+	for _, e := range edges {
+		nodes = append(nodes, e.node)
+	}
+	return nodes
+}
+
+// neighbors returns the relationships of the given type entering
+// (prefixIncoming) or leaving (prefixOutgoing) a node, together with the
+// node at their other end. Dangling relationships are skipped.
+func (b *BadgerBackend) neighbors(txn *badger.Txn, nodeID string, relType graph.RelType, indexPrefix string) ([]edge, error) {
+	var edges []edge
+
+	prefix := fmt.Sprintf("%s%s:%s:", indexPrefix, nodeID, relType)
 	opts := badger.DefaultIteratorOptions
 	opts.Prefix = []byte(prefix)
 	it := txn.NewIterator(opts)
 	defer it.Close()
 
 	for it.Rewind(); it.Valid(); it.Next() {
-		item := it.Item()
 		var relID string
-		if err := item.Value(func(val []byte) error {
+		if err := it.Item().Value(func(val []byte) error {
 			relID = string(val)
 			return nil
 		}); err != nil {
@@ -519,7 +915,7 @@ func (b *BadgerBackend) GetCallees(ctx context.Context, nodeID string) ([]*graph
 		// Get relationship
 		relItem, err := txn.Get(b.relKey(relID))
 		if err != nil {
-			continue
+			continue // Skip if relationship not found
 		}
 
 		var rel graph.GraphRelationship
@@ -529,86 +925,211 @@ func (b *BadgerBackend) GetCallees(ctx context.Context, nodeID string) ([]*graph
 			continue
 		}
 
-		// Get target node (callee)
-		calleeItem, err := txn.Get(b.nodeKey(rel.Target))
+		// Get the node at the other end
+		otherID := rel.Target
+		if indexPrefix == prefixIncoming {
+			otherID = rel.Source
+		}
+		nodeItem, err := txn.Get(b.nodeKey(otherID))
 		if err != nil {
-			continue
+			continue // Skip if node not found
 		}
 
-		var callee graph.GraphNode
-		if err := calleeItem.Value(func(val []byte) error {
-			return json.Unmarshal(val, &callee)
+		var node graph.GraphNode
+		if err := nodeItem.Value(func(val []byte) error {
+			return json.Unmarshal(val, &node)
 		}); err != nil {
 			continue
 		}
 
-		callees = append(callees, &callee)
+		edges = append(edges, edge{rel: &rel, node: &node})
 	}
 
-	return callees, nil
+	return edges, nil
 }
 
 // Traverse performs BFS traversal through CALLS edges.
+//
+// Calls through an interface are followed as well: walking callers, a
+// method's callers include the callers of the interface methods it
+// implements; walking callees, a call to an interface method also reaches
+// its implementations. Nodes reached this way are virtual calls and carry
+// PropViaInterfaceDispatch.
+//
+// Every returned node has PropTraversalDepth set to the number of calls
+// between it and the start node, and PropConfidence set to the product of
+// the confidences of the relationships on the path.
 func (b *BadgerBackend) Traverse(ctx context.Context, startID string, depth int, direction string) ([]*graph.GraphNode, error) {
 	if depth > 10 {
 		depth = 10 // Safety limit
 	}
 
-	visited := make(map[string]bool)
-	var result []*graph.GraphNode
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	txn := b.db.NewTransaction(false)
+	defer txn.Discard()
 
 	type traversalItem struct {
-		nodeID string
-		depth  int
+		nodeID     string
+		confidence float64
+		virtual    bool
 	}
 
-	queue := []traversalItem{{nodeID: startID, depth: 0}}
-
-	for len(queue) > 0 {
-		current := queue[0]
-		queue = queue[1:]
+	visited := map[string]bool{startID: true}
+	var result []*graph.GraphNode
 
-		if visited[current.nodeID] {
-			continue
+	frontier := []traversalItem{{nodeID: startID, confidence: 1.0}}
+	for level := 1; level <= depth && len(frontier) > 0; level++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
 		}
-		visited[current.nodeID] = true
 
-		if current.nodeID != startID {
-			node, err := b.getNode(current.nodeID)
+		// Collect the best path to every node of the next level: direct
+		// calls win over interface dispatch, then higher confidence.
+		next := make(map[string]*graph.GraphNode)
+		var order []string
+		for _, current := range frontier {
+			hops, err := b.traversalHops(txn, current.nodeID, direction)
 			if err != nil {
-				continue
+				return nil, err
 			}
-			if node != nil {
-				result = append(result, node)
+			for _, hop := range hops {
+				if visited[hop.node.ID] {
+					continue
+				}
+				confidence := current.confidence * hop.confidence
+				virtual := current.virtual || hop.virtual
+
+				if existing, ok := next[hop.node.ID]; ok {
+					existingVirtual, _ := existing.Properties[PropViaInterfaceDispatch].(bool)
+					existingConfidence, _ := existing.Properties[PropConfidence].(float64)
+					if virtual && !existingVirtual || virtual == existingVirtual && confidence <= existingConfidence {
+						continue
+					}
+				} else {
+					order = append(order, hop.node.ID)
+				}
+
+				node := hop.node
+				if node.Properties == nil {
+					node.Properties = make(map[string]any)
+				}
+				node.Properties[PropTraversalDepth] = level
+				node.Properties[PropConfidence] = confidence
+				node.Properties[PropViaInterfaceDispatch] = virtual
+				next[node.ID] = node
 			}
 		}
 
-		if current.depth < depth {
-			var neighbors []*graph.GraphNode
-			var err error
+		frontier = frontier[:0]
+		for _, nodeID := range order {
+			node := next[nodeID]
+			visited[nodeID] = true
+			result = append(result, node)
 
-			if direction == "callers" {
-				neighbors, err = b.GetCallers(ctx, current.nodeID)
-			} else {
-				neighbors, err = b.GetCallees(ctx, current.nodeID)
-			}
+			virtual, _ := node.Properties[PropViaInterfaceDispatch].(bool)
+			confidence, _ := node.Properties[PropConfidence].(float64)
+			frontier = append(frontier, traversalItem{nodeID: nodeID, confidence: confidence, virtual: virtual})
+		}
+	}
+
+	return result, nil
+}
+
+// traversalHop is a node reachable from another one by a single call.
+type traversalHop struct {
+	node       *graph.GraphNode
+	confidence float64
+	virtual    bool
+}
+
+// traversalHops returns the nodes one hop away from nodeID in the given
+// direction: along CALLS, READS_TABLE, WRITES_TABLE, READS, WRITES and
+// REFERENCES, and through interface dispatch (IMPLEMENTS, including rpc
+// handlers when walking callers).
+func (b *BadgerBackend) traversalHops(txn *badger.Txn, nodeID, direction string) ([]traversalHop, error) {
+	callIndex, dispatchIndex := prefixOutgoing, prefixIncoming
+	if direction == "callers" {
+		callIndex, dispatchIndex = prefixIncoming, prefixOutgoing
+	}
+
+	calls, err := b.neighbors(txn, nodeID, graph.RelCalls, callIndex)
+	if err != nil {
+		return nil, err
+	}
 
+	var hops []traversalHop
+	for _, call := range calls {
+		hops = append(hops, traversalHop{node: call.node, confidence: relConfidence(call.rel)})
+	}
+	for _, relType := range []graph.RelType{graph.RelReadsTable, graph.RelWritesTable, graph.RelReads, graph.RelWrites, graph.RelReferences} {
+		accesses, err := b.neighbors(txn, nodeID, relType, callIndex)
+		if err != nil {
+			return nil, err
+		}
+		for _, access := range accesses {
+			hops = append(hops, traversalHop{node: access.node, confidence: relConfidence(access.rel)})
+		}
+	}
+
+	if direction == "callers" {
+		// A method's callers include everyone calling the interface
+		// methods it implements.
+		ifaceMethods, err := b.neighbors(txn, nodeID, graph.RelImplements, dispatchIndex)
+		if err != nil {
+			return nil, err
+		}
+		for _, impl := range ifaceMethods {
+			callers, err := b.neighbors(txn, impl.node.ID, graph.RelCalls, callIndex)
 			if err != nil {
-				continue
+				return nil, err
 			}
+			for _, call := range callers {
+				hops = append(hops, traversalHop{
+					node:       call.node,
+					confidence: relConfidence(impl.rel) * relConfidence(call.rel),
+					virtual:    true,
+				})
+			}
+		}
 
-			for _, neighbor := range neighbors {
-				if !visited[neighbor.ID] {
-					queue = append(queue, traversalItem{
-						nodeID: neighbor.ID,
-						depth:  current.depth + 1,
-					})
-				}
+		handlers, err := b.neighbors(txn, nodeID, graph.RelImplements, callIndex)
+		if err != nil {
+			return nil, err
+		}
+		for _, handler := range handlers {
+			if rpc, _ := handler.rel.Properties["rpc"].(bool); rpc {
+				hops = append(hops, traversalHop{node: handler.node, confidence: relConfidence(handler.rel), virtual: true})
 			}
 		}
+		return hops, nil
 	}
 
-	return result, nil
+	// A call to an interface method reaches all of its implementations.
+	for _, call := range calls {
+		impls, err := b.neighbors(txn, call.node.ID, graph.RelImplements, dispatchIndex)
+		if err != nil {
+			return nil, err
+		}
+		for _, impl := range impls {
+			hops = append(hops, traversalHop{
+				node:       impl.node,
+				confidence: relConfidence(call.rel) * relConfidence(impl.rel),
+				virtual:    true,
+			})
+		}
+	}
+	return hops, nil
+}
+
+// relConfidence returns the confidence of a relationship, defaulting to 1.0
+// for relationships that do not record one.
+func relConfidence(rel *graph.GraphRelationship) float64 {
+	if confidence, ok := rel.Properties["confidence"].(float64); ok {
+		return confidence
+	}
+	return 1.0
 }
 
 // getNode is a helper that gets a node without locking (caller must hold lock).
@@ -801,6 +1322,31 @@ func (b *BadgerBackend) StoreEmbeddings(ctx context.Context, embeddings []NodeEm
 	return txn.Commit()
 }
 
+// Embeddings returns every stored node embedding.
+func (b *BadgerBackend) Embeddings(ctx context.Context) ([]NodeEmbedding, error) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+
+	var embeddings []NodeEmbedding
+	err := b.db.View(func(txn *badger.Txn) error {
+		return iteratePrefix(txn, prefixEmbedding, func(key string, value []byte) error {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			emb := NodeEmbedding{NodeID: strings.TrimPrefix(key, prefixEmbedding)}
+			if err := json.Unmarshal(value, &emb.Embedding); err != nil {
+				return fmt.Errorf("unmarshaling embedding %s: %w", key, err)
+			}
+			embeddings = append(embeddings, emb)
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return embeddings, nil
+}
+
 // cosineSimilarity computes the cosine similarity between two vectors.
 func cosineSimilarity(a, b []float32) float32 {
 	if len(a) != len(b) || len(a) == 0 {
@@ -424,6 +424,92 @@ func TestBadgerBackend_Traverse(t *testing.T) {
 	})
 }
 
+func TestBadgerBackend_TraverseInterfaceDispatch(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	backend, cleanup := setupTestBadgerBackend(t)
+	defer cleanup()
+
+	// handler -> Store.Get (interface) <- BadgerStore.Get -> decode
+	// direct -> BadgerStore.Get
+	nodes := []*graph.GraphNode{
+		{ID: "function:api.go:handler", Label: graph.NodeFunction, Name: "handler", FilePath: "api.go"},
+		{ID: "function:api.go:direct", Label: graph.NodeFunction, Name: "direct", FilePath: "api.go"},
+		{ID: "method:store.go:Store.Get", Label: graph.NodeMethod, Name: "Get", FilePath: "store.go", ClassName: "Store"},
+		{ID: "method:badger.go:BadgerStore.Get", Label: graph.NodeMethod, Name: "Get", FilePath: "badger.go", ClassName: "BadgerStore"},
+		{ID: "function:badger.go:decode", Label: graph.NodeFunction, Name: "decode", FilePath: "badger.go"},
+	}
+	require.NoError(t, backend.AddNodes(ctx, nodes))
+
+	rels := []*graph.GraphRelationship{
+		{ID: "calls:1", Type: graph.RelCalls, Source: "function:api.go:handler", Target: "method:store.go:Store.Get",
+			Properties: map[string]any{"confidence": 1.0}},
+		{ID: "calls:2", Type: graph.RelCalls, Source: "function:api.go:direct", Target: "method:badger.go:BadgerStore.Get",
+			Properties: map[string]any{"confidence": 1.0}},
+		{ID: "calls:3", Type: graph.RelCalls, Source: "method:badger.go:BadgerStore.Get", Target: "function:badger.go:decode",
+			Properties: map[string]any{"confidence": 1.0}},
+		{ID: "implements:1", Type: graph.RelImplements, Source: "method:badger.go:BadgerStore.Get", Target: "method:store.go:Store.Get",
+			Properties: map[string]any{"confidence": 0.7}},
+	}
+	require.NoError(t, backend.AddRelationships(ctx, rels))
+
+	byID := func(nodes []*graph.GraphNode) map[string]*graph.GraphNode {
+		m := make(map[string]*graph.GraphNode)
+		for _, n := range nodes {
+			m[n.ID] = n
+		}
+		return m
+	}
+
+	t.Run("CallersThroughInterface", func(t *testing.T) {
+		nodes, err := backend.Traverse(ctx, "method:badger.go:BadgerStore.Get", 1, "callers")
+		require.NoError(t, err)
+		found := byID(nodes)
+		require.Len(t, found, 2)
+
+		direct := found["function:api.go:direct"]
+		require.NotNil(t, direct)
+		assert.Equal(t, false, direct.Properties[PropViaInterfaceDispatch])
+		assert.Equal(t, 1.0, direct.Properties[PropConfidence])
+		assert.Equal(t, 1, direct.Properties[PropTraversalDepth])
+
+		handler := found["function:api.go:handler"]
+		require.NotNil(t, handler)
+		assert.Equal(t, true, handler.Properties[PropViaInterfaceDispatch])
+		assert.Equal(t, 0.7, handler.Properties[PropConfidence])
+		assert.Equal(t, 1, handler.Properties[PropTraversalDepth])
+	})
+
+	t.Run("CalleesThroughInterface", func(t *testing.T) {
+		nodes, err := backend.Traverse(ctx, "function:api.go:handler", 2, "callees")
+		require.NoError(t, err)
+		found := byID(nodes)
+
+		iface := found["method:store.go:Store.Get"]
+		require.NotNil(t, iface)
+		assert.Equal(t, false, iface.Properties[PropViaInterfaceDispatch])
+
+		impl := found["method:badger.go:BadgerStore.Get"]
+		require.NotNil(t, impl)
+		assert.Equal(t, true, impl.Properties[PropViaInterfaceDispatch])
+		assert.Equal(t, 1, impl.Properties[PropTraversalDepth])
+
+		decode := found["function:badger.go:decode"]
+		require.NotNil(t, decode)
+		assert.Equal(t, true, decode.Properties[PropViaInterfaceDispatch])
+		assert.Equal(t, 2, decode.Properties[PropTraversalDepth])
+	})
+
+	t.Run("InterfaceMethodHasOnlyDirectCallers", func(t *testing.T) {
+		nodes, err := backend.Traverse(ctx, "method:store.go:Store.Get", 1, "callers")
+		require.NoError(t, err)
+		require.Len(t, nodes, 1)
+		assert.Equal(t, "handler", nodes[0].Name)
+		assert.Equal(t, false, nodes[0].Properties[PropViaInterfaceDispatch])
+	})
+}
+
 func TestBadgerBackend_BulkLoad(t *testing.T) {
 	t.Parallel()
 
@@ -449,6 +535,178 @@ func TestBadgerBackend_BulkLoad(t *testing.T) {
 	node2, err := backend.GetNode(ctx, "node2")
 	assert.NoError(t, err)
 	assert.NotNil(t, node2)
+
+	t.Run("ReplacesStoredGraph", func(t *testing.T) {
+		require.NoError(t, backend.ReplaceFileRecords(ctx, []FileRecord{{Path: "test.py", SHA256: "abc"}}))
+		require.NoError(t, backend.StoreEmbeddings(ctx, []NodeEmbedding{{NodeID: "node2", Embedding: []float32{1}}}))
+
+		renamed := graph.NewKnowledgeGraph()
+		renamed.AddNode(&graph.GraphNode{ID: "node1", Label: graph.NodeFunction, Name: "foo", FilePath: "test.py"})
+		renamed.AddNode(&graph.GraphNode{ID: "node3", Label: graph.NodeClass, Name: "Baz", FilePath: "test.py"})
+		require.NoError(t, backend.BulkLoad(ctx, renamed))
+
+		node2, err := backend.GetNode(ctx, "node2")
+		require.NoError(t, err)
+		assert.Nil(t, node2, "nodes missing from the graph are dropped")
+		assert.Equal(t, 2, backend.NodeCount())
+		assert.Zero(t, backend.RelationshipCount())
+
+		report, err := backend.Check(ctx, false)
+		require.NoError(t, err)
+		assert.Zero(t, report.Problems(), "no stale relationships, index entries or embeddings remain")
+
+		records, err := backend.FileRecords(ctx)
+		require.NoError(t, err)
+		assert.Len(t, records, 1, "file records are kept")
+	})
+
+	t.Run("CanceledKeepsStoredGraph", func(t *testing.T) {
+		canceled, cancel := context.WithCancel(ctx)
+		cancel()
+		require.ErrorIs(t, backend.BulkLoad(canceled, graph.NewKnowledgeGraph()), context.Canceled)
+
+		node1, err := backend.GetNode(ctx, "node1")
+		require.NoError(t, err)
+		assert.NotNil(t, node1, "the stored graph is not dropped")
+		assert.Equal(t, 2, backend.NodeCount())
+	})
+}
+
+func TestBadgerBackend_CopyGraph(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	src, cleanupSrc := setupTestBadgerBackend(t)
+	defer cleanupSrc()
+	dst, cleanupDst := setupTestBadgerBackend(t)
+	defer cleanupDst()
+
+	g := graph.NewKnowledgeGraph()
+	g.AddNode(&graph.GraphNode{ID: "node1", Label: graph.NodeFunction, Name: "foo", FilePath: "test.py"})
+	require.NoError(t, src.BulkLoad(ctx, g))
+	require.NoError(t, src.AddRelationships(ctx, []*graph.GraphRelationship{{
+		ID: "rel1", Type: graph.RelCalls, Source: "node1", Target: "missing",
+	}}))
+	require.NoError(t, src.StoreEmbeddings(ctx, []NodeEmbedding{{NodeID: "node1", Embedding: []float32{1}}}))
+	require.NoError(t, src.ReplaceFileRecords(ctx, []FileRecord{{Path: "test.py", SHA256: "abc"}}))
+
+	require.NoError(t, src.CopyGraph(ctx, dst))
+
+	assert.Equal(t, 1, dst.NodeCount())
+	assert.Equal(t, 1, dst.RelationshipCount())
+	results, err := dst.FTSSearch(ctx, "foo", 10)
+	require.NoError(t, err)
+	assert.Len(t, results, 1)
+	embeddings, err := dst.Embeddings(ctx)
+	require.NoError(t, err)
+	assert.Len(t, embeddings, 1)
+
+	report, err := dst.Check(ctx, false)
+	require.NoError(t, err)
+	assert.Len(t, report.OrphanedRelationships, 1, "inconsistent entries are copied as they are")
+
+	records, err := dst.FileRecords(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records, "file records are not copied")
+}
+
+func TestBadgerBackend_UpdateGraph(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	backend, cleanup := setupTestBadgerBackend(t)
+	defer cleanup()
+
+	g := graph.NewKnowledgeGraph()
+	g.AddNode(&graph.GraphNode{ID: "function:a.go:main", Label: graph.NodeFunction, Name: "main", FilePath: "a.go"})
+	g.AddNode(&graph.GraphNode{ID: "function:b.go:old", Label: graph.NodeFunction, Name: "old", FilePath: "b.go"})
+	g.AddRelationship(&graph.GraphRelationship{
+		ID: "calls:main:old", Type: graph.RelCalls, Source: "function:a.go:main", Target: "function:b.go:old",
+	})
+	require.NoError(t, backend.BulkLoad(ctx, g))
+	require.NoError(t, backend.StoreEmbeddings(ctx, []NodeEmbedding{{NodeID: "function:b.go:old", Embedding: []float32{1}}}))
+
+	updated := graph.NewKnowledgeGraph()
+	updated.AddNode(&graph.GraphNode{ID: "function:a.go:main", Label: graph.NodeFunction, Name: "main", FilePath: "a.go", IsEntryPoint: true})
+	updated.AddNode(&graph.GraphNode{ID: "function:b.go:renamed", Label: graph.NodeFunction, Name: "renamed", FilePath: "b.go"})
+	updated.AddRelationship(&graph.GraphRelationship{
+		ID: "calls:main:renamed", Type: graph.RelCalls, Source: "function:a.go:main", Target: "function:b.go:renamed",
+	})
+	require.NoError(t, backend.UpdateGraph(ctx, updated))
+
+	old, err := backend.GetNode(ctx, "function:b.go:old")
+	require.NoError(t, err)
+	assert.Nil(t, old, "nodes missing from the graph are deleted")
+
+	main, err := backend.GetNode(ctx, "function:a.go:main")
+	require.NoError(t, err)
+	require.NotNil(t, main)
+	assert.True(t, main.IsEntryPoint, "changed nodes are rewritten")
+
+	callees, err := backend.GetCallees(ctx, "function:a.go:main")
+	require.NoError(t, err)
+	require.Len(t, callees, 1)
+	assert.Equal(t, "function:b.go:renamed", callees[0].ID)
+
+	callers, err := backend.GetCallers(ctx, "function:b.go:old")
+	require.NoError(t, err)
+	assert.Empty(t, callers)
+
+	results, err := backend.VectorSearch(ctx, []float32{1}, 10)
+	require.NoError(t, err)
+	assert.Empty(t, results, "embeddings of deleted nodes are deleted")
+
+	assert.Equal(t, 2, backend.NodeCount())
+	assert.Equal(t, 1, backend.RelationshipCount())
+}
+
+func TestBadgerBackend_FileRecords(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	backend, cleanup := setupTestBadgerBackend(t)
+	defer cleanup()
+
+	records, err := backend.FileRecords(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, records)
+
+	require.NoError(t, backend.ReplaceFileRecords(ctx, []FileRecord{
+		{Path: "a.go", SHA256: "aaa", Version: 1, Data: []byte("a")},
+		{Path: "b.go", SHA256: "bbb", Version: 1, Data: []byte("b")},
+	}))
+	require.NoError(t, backend.ReplaceFileRecords(ctx, []FileRecord{
+		{Path: "a.go", SHA256: "aaa", Version: 1, Data: []byte("a")},
+		{Path: "c.go", SHA256: "ccc", Version: 1, Data: []byte("c")},
+	}))
+
+	records, err = backend.FileRecords(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]FileRecord{
+		"a.go": {Path: "a.go", SHA256: "aaa", Version: 1, Data: []byte("a")},
+		"c.go": {Path: "c.go", SHA256: "ccc", Version: 1, Data: []byte("c")},
+	}, records)
+}
+
+func TestBadgerBackend_Embeddings(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	backend, cleanup := setupTestBadgerBackend(t)
+	defer cleanup()
+
+	embeddings, err := backend.Embeddings(ctx)
+	require.NoError(t, err)
+	assert.Empty(t, embeddings)
+
+	stored := []NodeEmbedding{
+		{NodeID: "function:a.go:a", Embedding: []float32{1, 0}},
+		{NodeID: "function:b.go:b", Embedding: []float32{0, 1}},
+	}
+	require.NoError(t, backend.StoreEmbeddings(ctx, stored))
+	embeddings, err = backend.Embeddings(ctx)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, stored, embeddings)
 }
 
 func TestBadgerBackend_Close(t *testing.T) {
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/dgraph-io/badger/v4"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+// CheckReport lists the inconsistencies Check found in a store.
+type CheckReport struct {
+	// Nodes, Relationships and IndexEntries are the numbers of entries
+	// checked.
+	Nodes         int
+	Relationships int
+	IndexEntries  int
+
+	// OrphanedRelationships are the IDs of the relationships whose
+	// source or target node is missing.
+	OrphanedRelationships []string
+
+	// DanglingIndexEntries are the adjacency index keys that point at a
+	// missing node or relationship, or at a relationship that no longer
+	// connects the node they belong to.
+	DanglingIndexEntries []string
+
+	// OrphanedEmbeddings are the IDs of the missing nodes that still have
+	// an embedding.
+	OrphanedEmbeddings []string
+
+	// Repaired is true if the inconsistent entries have been deleted.
+	Repaired bool
+}
+
+// Problems returns the number of inconsistencies found.
+func (r *CheckReport) Problems() int {
+	return len(r.OrphanedRelationships) + len(r.DanglingIndexEntries) + len(r.OrphanedEmbeddings)
+}
+
+// Check verifies that every relationship connects stored nodes and that
+// every adjacency index entry belongs to a stored relationship between
+// stored nodes. If repair is set, the inconsistent entries are deleted,
+// which requires the store to be open for writing.
+func (b *BadgerBackend) Check(ctx context.Context, repair bool) (*CheckReport, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	report := &CheckReport{}
+	var deleteKeys []string
+	err := b.db.View(func(txn *badger.Txn) error {
+		nodes := make(map[string]bool)
+		if err := iteratePrefix(txn, prefixNode, func(key string, _ []byte) error {
+			nodes[strings.TrimPrefix(key, prefixNode)] = true
+			return nil
+		}); err != nil {
+			return err
+		}
+		report.Nodes = len(nodes)
+
+		rels := make(map[string]*graph.GraphRelationship)
+		if err := iteratePrefix(txn, prefixRel, func(key string, value []byte) error {
+			var rel graph.GraphRelationship
+			if err := json.Unmarshal(value, &rel); err != nil {
+				return fmt.Errorf("unmarshaling relationship %s: %w", key, err)
+			}
+			report.Relationships++
+			if !nodes[rel.Source] || !nodes[rel.Target] {
+				report.OrphanedRelationships = append(report.OrphanedRelationships, rel.ID)
+				deleteKeys = append(deleteKeys, key)
+				return nil
+			}
+			rels[rel.ID] = &rel
+			return nil
+		}); err != nil {
+			return err
+		}
+
+		// An index entry is valid only if it is one of the two keys of a
+		// relationship kept above, whose nodes therefore exist
+		for _, prefix := range []string{prefixIncoming, prefixOutgoing} {
+			if err := iteratePrefix(txn, prefix, func(key string, value []byte) error {
+				if ctx.Err() != nil {
+					return ctx.Err()
+				}
+				report.IndexEntries++
+				if rel, ok := rels[string(value)]; ok {
+					outKey, inKey := relIndexKeys(rel)
+					if key == string(outKey) || key == string(inKey) {
+						return nil
+					}
+				}
+				report.DanglingIndexEntries = append(report.DanglingIndexEntries, key)
+				deleteKeys = append(deleteKeys, key)
+				return nil
+			}); err != nil {
+				return err
+			}
+		}
+
+		return iteratePrefix(txn, prefixEmbedding, func(key string, _ []byte) error {
+			if nodeID := strings.TrimPrefix(key, prefixEmbedding); !nodes[nodeID] {
+				report.OrphanedEmbeddings = append(report.OrphanedEmbeddings, nodeID)
+				deleteKeys = append(deleteKeys, key)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !repair || len(deleteKeys) == 0 {
+		return report, nil
+	}
+
+	txn := newUpdateTxn(b.db)
+	defer txn.discard()
+	for _, key := range deleteKeys {
+		if err := txn.delete([]byte(key)); err != nil {
+			return nil, fmt.Errorf("deleting %s: %w", key, err)
+		}
+	}
+	if err := txn.commit(); err != nil {
+		return nil, fmt.Errorf("repairing store: %w", err)
+	}
+	b.rebuildFTSIndexFromDB()
+	report.Repaired = true
+
+	return report, b.collectGarbage()
+}
+
+// iteratePrefix calls fn with every key under prefix and its value.
+func iteratePrefix(txn *badger.Txn, prefix string, fn func(key string, value []byte) error) error {
+	opts := badger.DefaultIteratorOptions
+	opts.Prefix = []byte(prefix)
+	it := txn.NewIterator(opts)
+	defer it.Close()
+
+	for it.Rewind(); it.Valid(); it.Next() {
+		item := it.Item()
+		value, err := item.ValueCopy(nil)
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", item.Key(), err)
+		}
+		if err := fn(string(item.Key()), value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestBadgerBackend_Check(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	backend, cleanup := setupTestBadgerBackend(t)
+	defer cleanup()
+
+	require.NoError(t, backend.AddNodes(ctx, []*graph.GraphNode{
+		{ID: "function:a.go:main", Label: graph.NodeFunction, Name: "main", FilePath: "a.go"},
+		{ID: "function:b.go:helper", Label: graph.NodeFunction, Name: "helper", FilePath: "b.go"},
+		{ID: "variable:b.go:config", Label: graph.NodeVariable, Name: "config", FilePath: "b.go"},
+	}))
+	require.NoError(t, backend.AddRelationships(ctx, []*graph.GraphRelationship{
+		{ID: "calls:main:helper", Type: graph.RelCalls, Source: "function:a.go:main", Target: "function:b.go:helper"},
+		{ID: "reads:main:config", Type: graph.RelReads, Source: "function:a.go:main", Target: "variable:b.go:config"},
+		{ID: "calls:main:gone", Type: graph.RelCalls, Source: "function:a.go:main", Target: "function:c.go:gone"},
+	}))
+	require.NoError(t, backend.StoreEmbeddings(ctx, []NodeEmbedding{
+		{NodeID: "function:b.go:helper", Embedding: []float32{1}},
+		{NodeID: "function:c.go:gone", Embedding: []float32{1}},
+	}))
+
+	report, err := backend.Check(ctx, false)
+	require.NoError(t, err)
+	assert.Equal(t, 3, report.Nodes)
+	assert.Equal(t, []string{"calls:main:gone"}, report.OrphanedRelationships)
+	assert.ElementsMatch(t, []string{
+		"i:in:function:c.go:gone:calls:calls:main:gone",
+		"i:out:function:a.go:main:calls:calls:main:gone",
+	}, report.DanglingIndexEntries)
+	assert.Equal(t, []string{"function:c.go:gone"}, report.OrphanedEmbeddings)
+	assert.Equal(t, 4, report.Problems())
+	assert.False(t, report.Repaired)
+
+	report, err = backend.Check(ctx, true)
+	require.NoError(t, err)
+	assert.True(t, report.Repaired)
+
+	report, err = backend.Check(ctx, false)
+	require.NoError(t, err)
+	assert.Zero(t, report.Problems())
+	assert.Equal(t, 2, backend.RelationshipCount())
+
+	// Removing a file deletes its relationships of every type with their
+	// index entries, and its embeddings
+	removed, err := backend.RemoveNodesByFile(ctx, "b.go")
+	require.NoError(t, err)
+	assert.Equal(t, 2, removed)
+
+	report, err = backend.Check(ctx, false)
+	require.NoError(t, err)
+	assert.Zero(t, report.Problems())
+	assert.Equal(t, 1, report.Nodes)
+	assert.Zero(t, report.IndexEntries)
+	assert.Zero(t, backend.RelationshipCount())
+}
@@ -0,0 +1,372 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Index generations: every index build writes a complete Badger store into
+// a directory of its own and, once the store is closed, makes it the
+// current generation by atomically replacing the CURRENT file. Readers open
+// the generation CURRENT names and never see a store that is being written.
+const (
+	generationsDir  = "generations"
+	currentFile     = "CURRENT"
+	buildingSuffix  = ".tmp"
+	legacyStoreDir  = "badger"
+	badgerPIDFile   = "LOCK"
+	generationWidth = 6
+
+	// DefaultKeepGenerations is the number of previous generations kept
+	// for rollback.
+	DefaultKeepGenerations = 2
+)
+
+// ErrNoIndex is returned when a repository has no current generation.
+var ErrNoIndex = errors.New("no index found")
+
+// Generation is a complete index, stored in its own directory.
+type Generation struct {
+	Name      string
+	Path      string
+	CreatedAt time.Time
+}
+
+// Generations manages the index generations in an .axon directory.
+type Generations struct {
+	root string
+	keep int
+}
+
+// NewGenerations returns the generations in the .axon directory root, of
+// which Prune keeps the keep newest previous ones.
+func NewGenerations(root string, keep int) *Generations {
+	return &Generations{root: root, keep: max(keep, 0)}
+}
+
+// Current returns the current generation. An index written before
+// generations existed, in .axon/badger, is current until the first
+// generation is published.
+func (g *Generations) Current() (Generation, error) {
+	data, err := os.ReadFile(filepath.Join(g.root, currentFile))
+	if errors.Is(err, os.ErrNotExist) {
+		legacy := filepath.Join(g.root, legacyStoreDir)
+		if info, err := os.Stat(legacy); err == nil && info.IsDir() {
+			return Generation{Name: legacyStoreDir, Path: legacy, CreatedAt: info.ModTime()}, nil
+		}
+		return Generation{}, ErrNoIndex
+	}
+	if err != nil {
+		return Generation{}, fmt.Errorf("reading current generation: %w", err)
+	}
+	return g.generation(strings.TrimSpace(string(data)))
+}
+
+// List returns the published generations, oldest first.
+func (g *Generations) List() ([]Generation, error) {
+	entries, err := os.ReadDir(filepath.Join(g.root, generationsDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("listing generations: %w", err)
+	}
+
+	var generations []Generation
+	for _, entry := range entries {
+		if !entry.IsDir() || generationNumber(entry.Name()) < 0 {
+			continue
+		}
+		gen, err := g.generation(entry.Name())
+		if err != nil {
+			return nil, err
+		}
+		generations = append(generations, gen)
+	}
+	slices.SortFunc(generations, func(a, b Generation) int {
+		return generationNumber(a.Name) - generationNumber(b.Name)
+	})
+	return generations, nil
+}
+
+// OpenCurrent opens the current generation read-only.
+func (g *Generations) OpenCurrent() (*BadgerBackend, Generation, error) {
+	gen, err := g.Current()
+	if err != nil {
+		return nil, Generation{}, err
+	}
+	store := NewBadgerBackend()
+	if err := store.Initialize(gen.Path, true); err != nil {
+		return nil, Generation{}, fmt.Errorf("opening generation %s: %w", gen.Name, err)
+	}
+	return store, gen, nil
+}
+
+// Begin creates the directory of a new generation and opens a store in it.
+// Unless full is set, the store starts with the file records of the current
+// generation, so that an incremental pipeline run only parses the files
+// that changed since. The generation must be published with Publish or
+// dropped with Discard once the store is closed.
+func (g *Generations) Begin(ctx context.Context, full bool) (*BadgerBackend, Generation, error) {
+	var records map[string]FileRecord
+	if !full {
+		current, _, err := g.OpenCurrent()
+		switch {
+		case errors.Is(err, ErrNoIndex):
+		case err != nil:
+			return nil, Generation{}, err
+		default:
+			records, err = current.FileRecords(ctx)
+			_ = current.Close()
+			if err != nil {
+				return nil, Generation{}, fmt.Errorf("reading file records: %w", err)
+			}
+		}
+	}
+
+	gen, err := g.create()
+	if err != nil {
+		return nil, Generation{}, err
+	}
+	store := NewBadgerBackend()
+	if err := store.Initialize(gen.Path, false); err != nil {
+		_ = os.RemoveAll(gen.Path)
+		return nil, Generation{}, fmt.Errorf("initializing generation %s: %w", gen.Name, err)
+	}
+	if len(records) > 0 {
+		seed := make([]FileRecord, 0, len(records))
+		for _, record := range records {
+			seed = append(seed, record)
+		}
+		if err := store.ReplaceFileRecords(ctx, seed); err != nil {
+			_ = store.Close()
+			_ = os.RemoveAll(gen.Path)
+			return nil, Generation{}, fmt.Errorf("copying file records: %w", err)
+		}
+	}
+	return store, gen, nil
+}
+
+// create makes the directory of the next generation, numbered after the
+// highest existing one, published or not.
+func (g *Generations) create() (Generation, error) {
+	dir := filepath.Join(g.root, generationsDir)
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return Generation{}, fmt.Errorf("creating generations directory: %w", err)
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return Generation{}, fmt.Errorf("listing generations: %w", err)
+	}
+	next := 1
+	for _, entry := range entries {
+		next = max(next, generationNumber(strings.TrimSuffix(entry.Name(), buildingSuffix))+1)
+	}
+
+	// Another process may create the same number concurrently
+	for ; ; next++ {
+		name := fmt.Sprintf("%0*d", generationWidth, next)
+		path := filepath.Join(dir, name+buildingSuffix)
+		err := os.Mkdir(path, 0o755)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return Generation{}, fmt.Errorf("creating generation: %w", err)
+		}
+		return Generation{Name: name, Path: path, CreatedAt: time.Now()}, nil
+	}
+}
+
+// Publish makes a generation created by Begin, whose store has been
+// closed, the current one.
+func (g *Generations) Publish(gen Generation) (Generation, error) {
+	path := filepath.Join(g.root, generationsDir, gen.Name)
+	if err := os.Rename(gen.Path, path); err != nil {
+		return Generation{}, fmt.Errorf("completing generation %s: %w", gen.Name, err)
+	}
+	gen.Path = path
+	if err := g.setCurrent(gen.Name); err != nil {
+		return Generation{}, err
+	}
+	return gen, nil
+}
+
+// Discard removes a generation created by Begin that is not published.
+func (g *Generations) Discard(gen Generation) error {
+	if !strings.HasSuffix(gen.Path, buildingSuffix) {
+		return fmt.Errorf("generation %s is published", gen.Name)
+	}
+	return os.RemoveAll(gen.Path)
+}
+
+// Activate makes an existing generation the current one, for rollback.
+func (g *Generations) Activate(name string) (Generation, error) {
+	gen, err := g.generation(name)
+	if err != nil {
+		return Generation{}, err
+	}
+	return gen, g.setCurrent(gen.Name)
+}
+
+// generation returns the published generation of the given name.
+func (g *Generations) generation(name string) (Generation, error) {
+	if generationNumber(name) < 0 {
+		return Generation{}, fmt.Errorf("invalid generation %q", name)
+	}
+	path := filepath.Join(g.root, generationsDir, name)
+	info, err := os.Stat(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return Generation{}, fmt.Errorf("generation %s: %w", name, ErrNoIndex)
+	}
+	if err != nil {
+		return Generation{}, fmt.Errorf("reading generation %s: %w", name, err)
+	}
+	return Generation{Name: name, Path: path, CreatedAt: info.ModTime()}, nil
+}
+
+// setCurrent atomically replaces the CURRENT file: it is written next to
+// it and renamed over it, so readers see either the old or the new name.
+func (g *Generations) setCurrent(name string) error {
+	tmp, err := os.CreateTemp(g.root, currentFile+"-*")
+	if err != nil {
+		return fmt.Errorf("writing current generation: %w", err)
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.WriteString(name + "\n"); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing current generation: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		return fmt.Errorf("writing current generation: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("writing current generation: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), filepath.Join(g.root, currentFile)); err != nil {
+		return fmt.Errorf("switching current generation: %w", err)
+	}
+	return nil
+}
+
+// Prune removes the published generations other than the current one and
+// the keep newest of the others, and the store of earlier versions. A
+// generation a store still has open, such as the one a server answers from
+// until it switches to the new one, is left in place for a later Prune.
+// Prune also removes the generations of builds that were killed before
+// they published or discarded them.
+func (g *Generations) Prune() error {
+	current, err := g.Current()
+	if err != nil {
+		return err
+	}
+	generations, err := g.List()
+	if err != nil {
+		return err
+	}
+	var previous []Generation
+	for _, gen := range generations {
+		if gen.Name != current.Name {
+			previous = append(previous, gen)
+		}
+	}
+
+	var errs []error
+	for _, gen := range previous[:max(len(previous)-g.keep, 0)] {
+		unlock, unused, err := lockUnused(gen.Path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("locking generation %s: %w", gen.Name, err))
+			continue
+		}
+		if !unused {
+			continue
+		}
+		if err := os.RemoveAll(gen.Path); err != nil {
+			errs = append(errs, fmt.Errorf("removing generation %s: %w", gen.Name, err))
+		}
+		unlock()
+	}
+	errs = append(errs, g.removeAbandoned()...)
+	if current.Name == legacyStoreDir {
+		return errors.Join(errs...)
+	}
+	if err := os.RemoveAll(filepath.Join(g.root, legacyStoreDir)); err != nil {
+		errs = append(errs, fmt.Errorf("removing %s: %w", legacyStoreDir, err))
+	}
+	return errors.Join(errs...)
+}
+
+// removeAbandoned removes the unpublished generations whose build was
+// killed. Badger removes the pid file of a store when it closes it, so a
+// generation with a pid file that no store has open was left by a build
+// that exited with its store open. Builds that have not opened their store
+// yet, or have closed it and are about to publish it, have no pid file.
+func (g *Generations) removeAbandoned() []error {
+	if !detectsOpenStores {
+		return nil
+	}
+	entries, err := os.ReadDir(filepath.Join(g.root, generationsDir))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return []error{fmt.Errorf("listing generations: %w", err)}
+	}
+
+	var errs []error
+	for _, entry := range entries {
+		name, building := strings.CutSuffix(entry.Name(), buildingSuffix)
+		if !entry.IsDir() || !building || generationNumber(name) < 0 {
+			continue
+		}
+		path := filepath.Join(g.root, generationsDir, entry.Name())
+		if !hasPIDFile(path) {
+			continue
+		}
+		unlock, unused, err := lockUnused(path)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("locking generation %s: %w", name, err))
+			continue
+		}
+		if !unused {
+			continue
+		}
+		// The build may have closed its store since the first look
+		if hasPIDFile(path) {
+			if err := os.RemoveAll(path); err != nil {
+				errs = append(errs, fmt.Errorf("removing generation %s: %w", name, err))
+			}
+		}
+		unlock()
+	}
+	return errs
+}
+
+// hasPIDFile reports whether the store at path has the pid file Badger
+// writes when it opens a store for writing.
+func hasPIDFile(path string) bool {
+	_, err := os.Stat(filepath.Join(path, badgerPIDFile))
+	return err == nil
+}
+
+// generationNumber returns the number of a generation directory name, or
+// -1 if it is not one.
+func generationNumber(name string) int {
+	if len(name) != generationWidth {
+		return -1
+	}
+	n, err := strconv.Atoi(name)
+	if err != nil || n < 0 {
+		return -1
+	}
+	return n
+}
@@ -0,0 +1,14 @@
+//go:build windows || plan9 || js || wasip1 || aix
+
+package storage
+
+// detectsOpenStores is false if lockUnused cannot tell open generations
+// apart, in which case Prune leaves unpublished generations alone.
+const detectsOpenStores = false
+
+// lockUnused reports every generation as unused: Badger does not lock the
+// directory of its stores with flock on these systems, so open generations
+// cannot be detected, and Prune relies on the generations it keeps.
+func lockUnused(path string) (unlock func(), ok bool, err error) {
+	return func() {}, true, nil
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/Benny93/axon-go/internal/graph"
+)
+
+func TestGenerations(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+
+	// build indexes a graph with a single function into a new generation
+	build := func(t *testing.T, gens *Generations, name string) Generation {
+		t.Helper()
+		store, gen, err := gens.Begin(ctx, false)
+		require.NoError(t, err)
+
+		g := graph.NewKnowledgeGraph()
+		g.AddNode(&graph.GraphNode{ID: "function:main.go:" + name, Label: graph.NodeFunction, Name: name, FilePath: "main.go"})
+		require.NoError(t, store.BulkLoad(ctx, g))
+		require.NoError(t, store.ReplaceFileRecords(ctx, []FileRecord{{Path: "main.go", SHA256: name}}))
+		require.NoError(t, store.Close())
+
+		gen, err = gens.Publish(gen)
+		require.NoError(t, err)
+		require.NoError(t, gens.Prune())
+		return gen
+	}
+	names := func(t *testing.T, gens *Generations) []string {
+		t.Helper()
+		generations, err := gens.List()
+		require.NoError(t, err)
+		var names []string
+		for _, gen := range generations {
+			names = append(names, gen.Name)
+		}
+		return names
+	}
+
+	t.Run("NoIndex", func(t *testing.T) {
+		gens := NewGenerations(t.TempDir(), 2)
+		_, err := gens.Current()
+		assert.ErrorIs(t, err, ErrNoIndex)
+		_, _, err = gens.OpenCurrent()
+		assert.ErrorIs(t, err, ErrNoIndex)
+	})
+
+	t.Run("PublishAndPrune", func(t *testing.T) {
+		gens := NewGenerations(t.TempDir(), 2)
+		for _, name := range []string{"a", "b", "c", "d"} {
+			build(t, gens, name)
+		}
+
+		current, err := gens.Current()
+		require.NoError(t, err)
+		assert.Equal(t, "000004", current.Name)
+		assert.Equal(t, []string{"000002", "000003", "000004"}, names(t, gens), "two previous generations are kept")
+
+		store, _, err := gens.OpenCurrent()
+		require.NoError(t, err)
+		defer store.Close()
+		node, err := store.GetNode(ctx, "function:main.go:d")
+		require.NoError(t, err)
+		assert.NotNil(t, node)
+	})
+
+	t.Run("PruneSkipsOpenGenerations", func(t *testing.T) {
+		gens := NewGenerations(t.TempDir(), 0)
+		build(t, gens, "a")
+		first, err := gens.Current()
+		require.NoError(t, err)
+
+		store, _, err := gens.OpenCurrent()
+		require.NoError(t, err)
+		build(t, gens, "b")
+		assert.Equal(t, []string{"000001", "000002"}, names(t, gens), "a reader still has the first generation open")
+		node, err := store.GetNode(ctx, "function:main.go:a")
+		require.NoError(t, err)
+		assert.NotNil(t, node)
+
+		require.NoError(t, store.Close())
+		require.NoError(t, gens.Prune())
+		assert.Equal(t, []string{"000002"}, names(t, gens))
+		_, err = os.Stat(first.Path)
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("PruneRemovesAbandonedBuilds", func(t *testing.T) {
+		if !detectsOpenStores {
+			t.Skip("open generations cannot be detected on this system")
+		}
+		gens := NewGenerations(t.TempDir(), 2)
+		build(t, gens, "a")
+
+		running, runningGen, err := gens.Begin(ctx, false)
+		require.NoError(t, err)
+		defer running.Close()
+		closed, closedGen, err := gens.Begin(ctx, false)
+		require.NoError(t, err)
+		require.NoError(t, closed.Close())
+
+		// A build killed with its store open leaves Badger's pid file
+		killed := filepath.Join(filepath.Dir(runningGen.Path), "000009"+buildingSuffix)
+		require.NoError(t, os.Mkdir(killed, 0o755))
+		require.NoError(t, os.WriteFile(filepath.Join(killed, badgerPIDFile), []byte("4242\n"), 0o644))
+		require.NoError(t, os.WriteFile(filepath.Join(killed, "000001.vlog"), []byte("data"), 0o644))
+
+		require.NoError(t, gens.Prune())
+		_, err = os.Stat(killed)
+		assert.ErrorIs(t, err, os.ErrNotExist, "the killed build is removed")
+		assert.DirExists(t, runningGen.Path, "a running build is kept")
+		assert.DirExists(t, closedGen.Path, "a build about to publish is kept")
+
+		published, err := gens.Publish(closedGen)
+		require.NoError(t, err)
+		assert.Equal(t, closedGen.Name, published.Name)
+	})
+
+	t.Run("BeginCopiesFileRecords", func(t *testing.T) {
+		gens := NewGenerations(t.TempDir(), 2)
+		build(t, gens, "a")
+
+		store, gen, err := gens.Begin(ctx, false)
+		require.NoError(t, err)
+		records, err := store.FileRecords(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "a", records["main.go"].SHA256)
+		node, err := store.GetNode(ctx, "function:main.go:a")
+		require.NoError(t, err)
+		assert.Nil(t, node, "a new generation starts without a graph")
+		require.NoError(t, store.Close())
+		require.NoError(t, gens.Discard(gen))
+
+		store, gen, err = gens.Begin(ctx, true)
+		require.NoError(t, err)
+		records, err = store.FileRecords(ctx)
+		require.NoError(t, err)
+		assert.Empty(t, records, "a full build parses every file")
+		require.NoError(t, store.Close())
+		require.NoError(t, gens.Discard(gen))
+	})
+
+	t.Run("UnpublishedGenerationIsInvisible", func(t *testing.T) {
+		gens := NewGenerations(t.TempDir(), 2)
+		first := build(t, gens, "a")
+
+		store, gen, err := gens.Begin(ctx, false)
+		require.NoError(t, err)
+		defer func() { _ = gens.Discard(gen) }()
+		defer store.Close()
+
+		current, err := gens.Current()
+		require.NoError(t, err)
+		assert.Equal(t, first.Name, current.Name)
+		assert.Equal(t, []string{first.Name}, names(t, gens))
+
+		// Numbers are not reused while a generation is being built
+		assert.Equal(t, "000003", build(t, gens, "b").Name)
+	})
+
+	t.Run("Activate", func(t *testing.T) {
+		gens := NewGenerations(t.TempDir(), 2)
+		first := build(t, gens, "a")
+		build(t, gens, "b")
+
+		_, err := gens.Activate(first.Name)
+		require.NoError(t, err)
+		current, err := gens.Current()
+		require.NoError(t, err)
+		assert.Equal(t, first.Name, current.Name)
+
+		_, err = gens.Activate("000009")
+		assert.ErrorIs(t, err, ErrNoIndex)
+	})
+
+	t.Run("LegacyStore", func(t *testing.T) {
+		root := t.TempDir()
+		legacy := NewBadgerBackend()
+		require.NoError(t, legacy.Initialize(filepath.Join(root, "badger"), false))
+		require.NoError(t, legacy.ReplaceFileRecords(ctx, []FileRecord{{Path: "main.go", SHA256: "legacy"}}))
+		require.NoError(t, legacy.Close())
+
+		gens := NewGenerations(root, 2)
+		current, err := gens.Current()
+		require.NoError(t, err)
+		assert.Equal(t, "badger", current.Name)
+
+		store, gen, err := gens.Begin(ctx, false)
+		require.NoError(t, err)
+		records, err := store.FileRecords(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "legacy", records["main.go"].SHA256)
+		require.NoError(t, store.Close())
+
+		_, err = gens.Publish(gen)
+		require.NoError(t, err)
+		require.NoError(t, gens.Prune())
+		_, err = os.Stat(filepath.Join(root, "badger"))
+		assert.ErrorIs(t, err, os.ErrNotExist, "the legacy store is replaced")
+	})
+}
@@ -0,0 +1,33 @@
+//go:build !windows && !plan9 && !js && !wasip1 && !aix
+
+package storage
+
+import (
+	"errors"
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// detectsOpenStores is true if lockUnused tells open generations apart.
+const detectsOpenStores = true
+
+// lockUnused takes the lock Badger takes on the directory of a store it
+// opens, exclusively, so that no store can open the generation at path
+// while it is removed. It reports false if a store, in this process or
+// another, has the generation open. The returned function releases the
+// lock; a lock of a process that exits is released by the system.
+func lockUnused(path string) (unlock func(), ok bool, err error) {
+	dir, err := os.Open(path)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := unix.Flock(int(dir.Fd()), unix.LOCK_EX|unix.LOCK_NB); err != nil {
+		_ = dir.Close()
+		if errors.Is(err, unix.EWOULDBLOCK) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+	return func() { _ = dir.Close() }, true, nil
+}
@@ -13,6 +13,7 @@ type MemoryBackend struct {
 	mu         sync.RWMutex
 	nodes      map[string]*graph.GraphNode
 	embeddings map[string][]float32
+	files      map[string]FileRecord
 	indexed    bool
 	ftsIndexed bool
 }
@@ -22,6 +23,7 @@ func NewMemoryBackend() *MemoryBackend {
 	return &MemoryBackend{
 		nodes:      make(map[string]*graph.GraphNode),
 		embeddings: make(map[string][]float32),
+		files:      make(map[string]FileRecord),
 	}
 }
 
@@ -61,6 +63,8 @@ func (m *MemoryBackend) BulkLoad(ctx context.Context, g *graph.KnowledgeGraph) e
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
+	m.nodes = make(map[string]*graph.GraphNode)
+	m.embeddings = make(map[string][]float32)
 	for node := range g.IterNodes() {
 		m.nodes[node.ID] = node
 	}
@@ -68,6 +72,43 @@ func (m *MemoryBackend) BulkLoad(ctx context.Context, g *graph.KnowledgeGraph) e
 	return nil
 }
 
+// UpdateGraph implements StorageBackend.
+func (m *MemoryBackend) UpdateGraph(ctx context.Context, g *graph.KnowledgeGraph) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.nodes = make(map[string]*graph.GraphNode)
+	for node := range g.IterNodes() {
+		m.nodes[node.ID] = node
+	}
+	m.indexed = true
+	return nil
+}
+
+// FileRecords implements StorageBackend.
+func (m *MemoryBackend) FileRecords(ctx context.Context) (map[string]FileRecord, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	records := make(map[string]FileRecord, len(m.files))
+	for path, record := range m.files {
+		records[path] = record
+	}
+	return records, nil
+}
+
+// ReplaceFileRecords implements StorageBackend.
+func (m *MemoryBackend) ReplaceFileRecords(ctx context.Context, records []FileRecord) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.files = make(map[string]FileRecord, len(records))
+	for _, record := range records {
+		m.files[record.Path] = record
+	}
+	return nil
+}
+
 // AddNodes implements StorageBackend.
 func (m *MemoryBackend) AddNodes(ctx context.Context, nodes []*graph.GraphNode) error {
 	m.mu.Lock()
@@ -131,6 +172,11 @@ func (m *MemoryBackend) GetCallees(ctx context.Context, nodeID string) ([]*graph
 	return nil, nil
 }
 
+// GetDocuments implements StorageBackend.
+func (m *MemoryBackend) GetDocuments(ctx context.Context, nodeID string) ([]*graph.GraphNode, error) {
+	return nil, nil
+}
+
 // Traverse implements StorageBackend.
 func (m *MemoryBackend) Traverse(ctx context.Context, startID string, depth int, direction string) ([]*graph.GraphNode, error) {
 	return nil, nil
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"strings"
+	"sync"
 
 	"github.com/google/jsonschema-go/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -18,6 +19,9 @@ import (
 
 // Server represents the MCP server.
 type Server struct {
+	// mu is held for reading while a request uses storage, so that every
+	// answer comes from a single index generation
+	mu      sync.RWMutex
 	storage StorageBackend
 	server  *mcp.Server
 }
@@ -27,6 +31,8 @@ type StorageBackend interface {
 	FTSSearch(ctx context.Context, query string, limit int) ([]storage.SearchResult, error)
 	GetCallers(ctx context.Context, nodeID string) ([]*graph.GraphNode, error)
 	GetCallees(ctx context.Context, nodeID string) ([]*graph.GraphNode, error)
+	GetDocuments(ctx context.Context, nodeID string) ([]*graph.GraphNode, error)
+	GetNode(ctx context.Context, nodeID string) (*graph.GraphNode, error)
 	Traverse(ctx context.Context, startID string, depth int, direction string) ([]*graph.GraphNode, error)
 	NodeCount() int
 	RelationshipCount() int
@@ -90,6 +96,26 @@ func NewServer(storage StorageBackend) *Server {
 	return s
 }
 
+// SwapStorage makes the server answer from store, typically a newly
+// published index generation. It waits for the requests using the old
+// store to finish, then closes it.
+func (s *Server) SwapStorage(store StorageBackend) error {
+	s.mu.Lock()
+	old := s.storage
+	s.storage = store
+	s.mu.Unlock()
+
+	if old == nil {
+		return nil
+	}
+	return old.Close()
+}
+
+// Close closes the storage the server answers from.
+func (s *Server) Close() error {
+	return s.SwapStorage(nil)
+}
+
 // ListTools returns all registered tools.
 func (s *Server) ListTools() []Tool {
 	return []Tool{
@@ -107,7 +133,7 @@ func (s *Server) ListTools() []Tool {
 		},
 		{
 			Name:        "axon_context",
-			Description: "Get a 360-degree view of a symbol: callers, callees, type references, and community membership.",
+			Description: "Get a 360-degree view of a symbol: its documentation, callers, callees, and the design docs that mention it.",
 			InputSchema: &jsonschema.Schema{
 				Type: "object",
 				Properties: map[string]*jsonschema.Schema{
@@ -199,6 +225,9 @@ func (s *Server) ListResources() []Resource {
 
 // CallTool executes a tool with the given arguments.
 func (s *Server) CallTool(ctx context.Context, name string, args map[string]any) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	switch name {
 	case "axon_list_repos":
 		return handleListRepos()
@@ -240,6 +269,9 @@ func (s *Server) CallTool(ctx context.Context, name string, args map[string]any)
 
 // ReadResource reads a resource by URI.
 func (s *Server) ReadResource(ctx context.Context, uri string) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
 	switch uri {
 	case "axon://overview":
 		return getOverview(s.storage), nil
@@ -523,9 +555,10 @@ func resolveSymbolToNodeID(storage StorageBackend, symbol string) (string, error
 		return "", err
 	}
 
-	// Look for exact match first
+	// Look for exact match first; a document section titled after the
+	// symbol is not the symbol
 	for _, result := range results {
-		if result.NodeName == symbol {
+		if result.NodeName == symbol && result.Label != string(graph.NodeDocument) {
 			return result.NodeID, nil
 		}
 	}
@@ -552,6 +585,11 @@ func handleContext(storage StorageBackend, symbol string) (string, error) {
 	var sb strings.Builder
 	fmt.Fprintf(&sb, "Context for symbol: **%s**\n\n", symbol)
 
+	// The symbol's own documentation
+	if node, _ := storage.GetNode(context.Background(), nodeID); node != nil && node.Doc != "" {
+		fmt.Fprintf(&sb, "## Documentation\n%s\n\n", node.Doc)
+	}
+
 	// Get callers using node ID
 	callers, _ := storage.GetCallers(context.Background(), nodeID)
 	if len(callers) > 0 {
@@ -576,18 +614,36 @@ func handleContext(storage StorageBackend, symbol string) (string, error) {
 		fmt.Fprint(&sb, "No connections found. Symbol may be isolated or not yet indexed.\n")
 	}
 
+	// Design docs that mention the symbol
+	documents, _ := storage.GetDocuments(context.Background(), nodeID)
+	if len(documents) > 0 {
+		fmt.Fprintf(&sb, "\n## Design Docs (%d)\n", len(documents))
+		for _, d := range documents {
+			fmt.Fprintf(&sb, "- %s in %s:%d\n", documentPath(d), d.FilePath, d.StartLine)
+		}
+	}
+
 	fmt.Fprint(&sb, "\nNext: Use `axon_impact` if planning changes to this symbol.")
 
 	return sb.String(), nil
 }
 
-func handleImpact(storage StorageBackend, symbol string, depth int) (string, error) {
+// documentPath returns the heading path of a document section
+// (Design > Storage), or its title.
+func documentPath(d *graph.GraphNode) string {
+	if path, _ := d.Properties["path"].(string); path != "" {
+		return path
+	}
+	return d.Name
+}
+
+func handleImpact(store StorageBackend, symbol string, depth int) (string, error) {
 	if symbol == "" {
 		return "No symbol provided", nil
 	}
 
 	// Resolve symbol name to node ID
-	nodeID, err := resolveSymbolToNodeID(storage, symbol)
+	nodeID, err := resolveSymbolToNodeID(store, symbol)
 	if err != nil {
 		return fmt.Sprintf("Symbol '%s' not found in index", symbol), nil
 	}
@@ -596,19 +652,14 @@ func handleImpact(storage StorageBackend, symbol string, depth int) (string, err
 	fmt.Fprintf(&sb, "Impact analysis for: **%s** (depth: %d)\n\n", symbol, depth)
 
 	// Traverse callers (blast radius) using node ID
-	affected, _ := storage.Traverse(context.Background(), nodeID, depth, "callers")
+	affected, _ := store.Traverse(context.Background(), nodeID, depth, "callers")
 
 	if len(affected) == 0 {
 		fmt.Fprint(&sb, "No affected symbols found. This symbol appears to be isolated.\n")
 	} else {
 		fmt.Fprintf(&sb, "## Affected Symbols (%d)\n\n", len(affected))
 
-		// Group by depth
-		byDepth := make(map[int][]*graph.GraphNode)
-		for i, node := range affected {
-			d := (i % depth) + 1
-			byDepth[d] = append(byDepth[d], node)
-		}
+		byDepth, viaDispatch := storage.GroupByTraversal(affected)
 
 		for d := 1; d <= depth; d++ {
 			nodes := byDepth[d]
@@ -629,6 +680,15 @@ func handleImpact(storage StorageBackend, symbol string, depth int) (string, err
 			}
 			fmt.Fprintln(&sb)
 		}
+
+		if len(viaDispatch) > 0 {
+			fmt.Fprintf(&sb, "### Via Interface Dispatch (%d)\n", len(viaDispatch))
+			for _, n := range viaDispatch {
+				fmt.Fprintf(&sb, "- %s (%s) in %s [depth %d, confidence %.2f]\n",
+					n.Name, n.Label, n.FilePath, storage.TraversalDepth(n), storage.TraversalConfidence(n))
+			}
+			fmt.Fprintln(&sb)
+		}
 	}
 
 	sb.WriteString("\nTip: Review each affected symbol before making changes.")
@@ -751,6 +811,15 @@ func getSchema() string {
 	sb.WriteString("| `method` | Method | name, class_name |\n")
 	sb.WriteString("| `interface` | Interface | name |\n")
 	sb.WriteString("| `type_alias` | Type alias | name, underlying_type |\n")
+	sb.WriteString("| `enum` | Enum (Go: type enumerated by an iota const group) | name |\n")
+	sb.WriteString("| `variable` | Package-level variable | name, signature |\n")
+	sb.WriteString("| `constant` | Package-level constant | name, class_name (enum), signature |\n")
+	sb.WriteString("| `field` | Struct field | name, class_name (struct), signature |\n")
+	sb.WriteString("| `endpoint` | HTTP route (METHOD /path) | method, path, route, framework |\n")
+	sb.WriteString("| `table` | SQL table or view | name, schema, view |\n")
+	sb.WriteString("| `column` | Table column | name, class_name (table), signature |\n")
+	sb.WriteString("| `resource` | Terraform block, Kubernetes object or Docker stage | name, kind, platform |\n")
+	sb.WriteString("| `document` | Section of a Markdown file | name, level, path |\n")
 	sb.WriteString("\n## Relationship Types\n\n")
 	sb.WriteString("| Type | Source → Target | Properties |\n")
 	sb.WriteString("|------|-----------------|------------|\n")
@@ -761,6 +830,14 @@ func getSchema() string {
 	sb.WriteString("| `extends` | Class → Class | - |\n")
 	sb.WriteString("| `implements` | Class → Interface | - |\n")
 	sb.WriteString("| `uses_type` | Symbol → Type | role |\n")
+	sb.WriteString("| `declares` | Declaration → Definition | - |\n")
+	sb.WriteString("| `handles` | Endpoint → Handler | - |\n")
+	sb.WriteString("| `reads_table` | Symbol/File → Table | - |\n")
+	sb.WriteString("| `writes_table` | Symbol/File → Table | - |\n")
+	sb.WriteString("| `reads` | Symbol → Variable/Constant/Field | confidence |\n")
+	sb.WriteString("| `writes` | Symbol → Variable/Field | confidence |\n")
+	sb.WriteString("| `references` | Resource → Resource/main | - |\n")
+	sb.WriteString("| `documents` | Document → Symbol | - |\n")
 
 	return sb.String()
 }
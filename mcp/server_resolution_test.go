@@ -167,6 +167,62 @@ func TestMCPSymbolResolution(t *testing.T) {
 		assert.Contains(t, result, "AnalyzeCmd")
 	})
 
+	t.Run("HandleImpactReportsInterfaceDispatch", func(t *testing.T) {
+		store := storage.NewBadgerBackend()
+		tmpDir := t.TempDir()
+		err := store.Initialize(tmpDir, false)
+		require.NoError(t, err)
+		defer store.Close()
+
+		g := graph.NewKnowledgeGraph()
+		g.AddNode(&graph.GraphNode{
+			ID:       "function:api.go:serve",
+			Label:    graph.NodeFunction,
+			Name:     "serve",
+			FilePath: "api.go",
+		})
+		g.AddNode(&graph.GraphNode{
+			ID:        "method:store.go:Store.Save",
+			Label:     graph.NodeMethod,
+			Name:      "Save",
+			FilePath:  "store.go",
+			ClassName: "Store",
+		})
+		g.AddNode(&graph.GraphNode{
+			ID:        "method:badger.go:BadgerStore.Save",
+			Label:     graph.NodeMethod,
+			Name:      "Save",
+			FilePath:  "badger.go",
+			ClassName: "BadgerStore",
+			Content:   "func (b *BadgerStore) Save() error",
+		})
+
+		// serve calls the interface method; BadgerStore.Save implements it
+		g.AddRelationship(&graph.GraphRelationship{
+			ID:     "calls:1",
+			Type:   graph.RelCalls,
+			Source: "function:api.go:serve",
+			Target: "method:store.go:Store.Save",
+		})
+		g.AddRelationship(&graph.GraphRelationship{
+			ID:         "implements:1",
+			Type:       graph.RelImplements,
+			Source:     "method:badger.go:BadgerStore.Save",
+			Target:     "method:store.go:Store.Save",
+			Properties: map[string]any{"confidence": 0.7},
+		})
+
+		err = store.BulkLoad(t.Context(), g)
+		require.NoError(t, err)
+
+		result, err := handleImpact(store, "BadgerStore", 3)
+		assert.NoError(t, err)
+		assert.Contains(t, result, "## Affected Symbols (1)")
+		assert.NotContains(t, result, "### Depth 1")
+		assert.Contains(t, result, "### Via Interface Dispatch (1)")
+		assert.Contains(t, result, "- serve (function) in api.go [depth 1, confidence 0.70]")
+	})
+
 	t.Run("HandleContextNotFound", func(t *testing.T) {
 		store := storage.NewBadgerBackend()
 		tmpDir := t.TempDir()
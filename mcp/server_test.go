@@ -18,6 +18,9 @@ type mockStorage struct {
 	callers       []*graph.GraphNode
 	callees       []*graph.GraphNode
 	traverseNodes []*graph.GraphNode
+	documents     []*graph.GraphNode
+	nodesByID     []*graph.GraphNode
+	closed        bool
 }
 
 func (m *mockStorage) FTSSearch(ctx context.Context, query string, limit int) ([]storage.SearchResult, error) {
@@ -37,6 +40,19 @@ func (m *mockStorage) GetCallees(ctx context.Context, nodeID string) ([]*graph.G
 	return m.callees, nil
 }
 
+func (m *mockStorage) GetDocuments(ctx context.Context, nodeID string) ([]*graph.GraphNode, error) {
+	return m.documents, nil
+}
+
+func (m *mockStorage) GetNode(ctx context.Context, nodeID string) (*graph.GraphNode, error) {
+	for _, node := range m.nodesByID {
+		if node.ID == nodeID {
+			return node, nil
+		}
+	}
+	return nil, nil
+}
+
 func (m *mockStorage) Traverse(ctx context.Context, startID string, depth int, direction string) ([]*graph.GraphNode, error) {
 	return m.traverseNodes, nil
 }
@@ -50,6 +66,7 @@ func (m *mockStorage) RelationshipCount() int {
 }
 
 func (m *mockStorage) Close() error {
+	m.closed = true
 	return nil
 }
 
@@ -171,6 +188,26 @@ func TestServer_HandleToolCalls(t *testing.T) {
 	})
 }
 
+func TestServer_SwapStorage(t *testing.T) {
+	t.Parallel()
+
+	ctx := context.Background()
+	old := newMockStorage()
+	server := NewServer(old)
+
+	current := newMockStorage()
+	current.nodes = 42
+	assert.NoError(t, server.SwapStorage(current))
+	assert.True(t, old.closed, "the replaced storage is closed")
+
+	overview, err := server.ReadResource(ctx, "axon://overview")
+	assert.NoError(t, err)
+	assert.Contains(t, overview, "**Nodes:** 42")
+
+	assert.NoError(t, server.Close())
+	assert.True(t, current.closed)
+}
+
 func TestServer_Resources(t *testing.T) {
 	t.Parallel()
 
@@ -276,6 +313,26 @@ func TestToolHandlers(t *testing.T) {
 		assert.NotNil(t, result)
 	})
 
+	t.Run("HandleContextDocs", func(t *testing.T) {
+		store := newMockStorage()
+		store.searchResults = []storage.SearchResult{
+			{NodeID: "doc:docs/design.md:Design", NodeName: "Open", Label: string(graph.NodeDocument)},
+			{NodeID: "func:store.go:Open", NodeName: "Open", Label: string(graph.NodeFunction)},
+		}
+		store.nodesByID = []*graph.GraphNode{
+			{ID: "func:store.go:Open", Label: graph.NodeFunction, Name: "Open", Doc: "Open opens the store."},
+		}
+		store.documents = []*graph.GraphNode{
+			{ID: "doc:docs/design.md:Design > Storage", Label: graph.NodeDocument, Name: "Storage",
+				FilePath: "docs/design.md", StartLine: 12, Properties: map[string]any{"path": "Design > Storage"}},
+		}
+
+		result, err := handleContext(store, "Open")
+		assert.NoError(t, err)
+		assert.Contains(t, result, "## Documentation\nOpen opens the store.")
+		assert.Contains(t, result, "## Design Docs (1)\n- Design > Storage in docs/design.md:12")
+	})
+
 	t.Run("HandleImpact", func(t *testing.T) {
 		result, err := handleImpact(store, "SomeSymbol", 3)
 		assert.NoError(t, err)